@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	acpapi "github.com/chelof100/acp-framework/acp-go/pkg/api"
+	"github.com/chelof100/acp-framework/acp-go/pkg/govevents"
+	"github.com/chelof100/acp-framework/acp-go/pkg/keyring"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/chelof100/acp-framework/acp-go/pkg/replica"
+)
+
+// ─── Follower mode ────────────────────────────────────────────────────────────
+//
+// With ACP_FOLLOW set, the server replicates the audit ledger of the primary
+// at that URL (GET /acp/v1/audit/stream) and serves it read-only: audit
+// queries, HIST endpoints, Merkle proofs and the stream itself, so followers
+// can be chained. Every event is verified against the institution keyring
+// before it is applied; the keyring follows the primary's rotations through
+// the trust_anchor_rotated events in the stream. A follower never signs: its
+// responses and tree heads are unsigned, and ACP_INSTITUTION_PRIVATE_KEY is
+// ignored.
+
+// runFollower serves the follower of primary on addr. It does not return.
+func runFollower(primary, institutionID string, pub ed25519.PublicKey, addr string) {
+	keys, err := keyring.New(institutionID, os.Getenv("ACP_INSTITUTION_KEY_ID"), pub, nil, 0)
+	if err != nil {
+		log.Fatalf("[ACP] failed to initialize keyring: %v", err)
+	}
+	if os.Getenv("ACP_INSTITUTION_PRIVATE_KEY") != "" {
+		log.Printf("[ACP/REPLICA] follower mode: ACP_INSTITUTION_PRIVATE_KEY ignored, responses are not signed")
+	}
+
+	auditLedger := ledger.NewReplica(institutionID, keys)
+	auditLedger.SetSchemaMode(ledgerSchemaMode())
+	srv := &server{
+		auditLedger:   auditLedger,
+		institutionID: institutionID,
+		keys:          keys,
+		addr:          addr,
+	}
+	srv.follower = replica.NewFollower(primary, auditLedger, replica.FollowerConfig{OnEvent: srv.applyKeyRotation})
+
+	mux := http.NewServeMux()
+	// ── ACP-LEDGER-1.0 audit, read-only ──
+	mux.HandleFunc("POST /acp/v1/audit/query", srv.handleAuditQuery)
+	mux.HandleFunc("GET /acp/v1/audit/verify/{event_id}", srv.handleAuditVerify)
+	mux.HandleFunc("GET /acp/v1/audit/tree-head", srv.handleAuditTreeHead)
+	mux.HandleFunc("GET /acp/v1/audit/proof/inclusion", srv.handleAuditInclusionProof)
+	mux.HandleFunc("GET /acp/v1/audit/proof/consistency", srv.handleAuditConsistencyProof)
+	mux.Handle("GET "+replica.StreamPath, replica.NewStreamer(auditLedger, 0))
+
+	// ── ACP-HIST-1.0 ──
+	mux.HandleFunc("GET /acp/v1/audit/query", srv.handleHistQuery)
+	mux.HandleFunc("GET /acp/v1/audit/events/{event_id}", srv.handleHistEvent)
+	mux.HandleFunc("GET /acp/v1/audit/agents/{agent_id}/history", srv.handleHistAgentHistory)
+
+	mux.HandleFunc("GET /.well-known/acp-keys", srv.handleWellKnownKeys)
+	mux.HandleFunc("GET /acp/v1/health", srv.handleFollowerHealth)
+
+	go func() {
+		if err := srv.follower.Run(context.Background()); err != nil {
+			log.Printf("[ACP/REPLICA] replication stopped: %v", err)
+		}
+	}()
+
+	log.Printf("[ACP/REPLICA] following %s (read-only) on %s", primary, addr)
+	if err := http.ListenAndServe(addr, acpapi.Middleware(mux)); err != nil {
+		log.Fatalf("[ACP] server error: %v", err)
+	}
+}
+
+// applyKeyRotation records in the verify-only keyring the institutional key
+// rotations endorsed in the replicated ledger, so that the events signed by
+// the new key verify. The endorsement itself was verified against the old
+// key when it was applied. The new key is valid from the event timestamp.
+func (s *server) applyKeyRotation(ev ledger.Event) {
+	if ev.EventType != ledger.EventGovernance {
+		return
+	}
+	var gov struct {
+		EventType string                              `json:"event_type"`
+		Payload   govevents.TrustAnchorRotatedPayload `json:"payload"`
+	}
+	raw, err := json.Marshal(ev.Payload)
+	if err != nil || json.Unmarshal(raw, &gov) != nil || gov.EventType != govevents.TypeTrustAnchorRotated {
+		return
+	}
+	pub, err := base64.RawURLEncoding.DecodeString(gov.Payload.NewPublicKey)
+	if err != nil {
+		log.Printf("[ACP/REPLICA] rotation %s: malformed new_public_key", ev.EventID)
+		return
+	}
+	k, err := s.keys.RotatePublic(pub, ev.Timestamp, gov.Payload.OverlapPeriod, ev.EventID)
+	if err != nil {
+		log.Printf("[ACP/REPLICA] rotation %s: %v", ev.EventID, err)
+		return
+	}
+	log.Printf("[ACP/REPLICA] institution key rotated → %s (endorsement=%s)", k.KID, ev.EventID)
+}
+
+// handleFollowerHealth reports the replication state of a follower.
+// GET /acp/v1/health
+//
+// The audit ledger is "replicating" while the stream is open, "connecting"
+// while the follower retries, and "diverged" once the primary's history
+// stopped extending the replica's (replication has stopped).
+func (s *server) handleFollowerHealth(w http.ResponseWriter, r *http.Request) {
+	st := s.follower.Status()
+	status, ledgerStatus := "operational", "replicating"
+	switch st.State {
+	case replica.StateConnecting:
+		status, ledgerStatus = "degraded", "connecting"
+	case replica.StateDiverged:
+		status, ledgerStatus = "degraded", "diverged"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"acp_version": "1.0",
+		"status":      status,
+		"role":        "follower",
+		"timestamp":   time.Now().Unix(),
+		"components": map[string]string{
+			"audit_ledger": ledgerStatus,
+		},
+		"replication": st,
+		"_counters": map[string]interface{}{
+			"ledger_events": s.auditLedger.Size(),
+			"keys":          s.keys.Size(),
+		},
+	})
+}
@@ -1,53 +1,122 @@
 // cmd/acp-server — ACP Reference Server
 // Protocols: ACP-HP-1.0 + ACP-CT-1.0 + ACP-REV-1.0 + ACP-REP-1.1 + ACP-API-1.0 + ACP-EXEC-1.0 + ACP-LEDGER-1.0
+//            + ACP-LIA-1.0 + ACP-PSN-1.0 + ACP-CROSS-ORG-1.1 + ACP-PAY-1.0
 //
 // Environment variables:
 //   ACP_INSTITUTION_PUBLIC_KEY   base64url-encoded Ed25519 public key (required)
 //   ACP_INSTITUTION_PRIVATE_KEY  base64url-encoded Ed25519 private key (optional; enables response signing)
+//   ACP_INSTITUTION_KEY_ID       kid of the initial institution key (default: derived from the public key)
 //   ACP_INSTITUTION_ID           institution identifier for audit ledger (default: org.acp.server)
 //   ACP_ADDR                     listen address (default :8080)
 //   ACP_LOG_LEVEL                log level (default info)
+//   ACP_FOLLOW                   base URL of a primary server; runs as a read-only ledger follower
+//   ACP_REDACT_SENSITIVE         true: seal sensitive ledger payload fields under per-subject keys (crypto-shredding)
+//   ACP_SHRED_KEYS_DIR           directory persisting the subject keys (default: in memory)
+//   ACP_ARCHIVE_DIR              directory of archived ledger segments (enables segment archival)
+//   ACP_ARCHIVE_RETENTION        age after which events are archived, e.g. "24h" (default: on demand only)
+//   ACP_RATE_LIMITS              per-autonomy-level request quotas, "level=rate/burst,..." or "off" (default: ratelimit.DefaultLimits)
+//   ACP_REQUEST_ID_TTL           how long /authorize request_ids are de-duplicated, e.g. "10m" (default: 5m)
 package main
 
 import (
+	"bytes"
+	"context"
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	acpapi "github.com/chelof100/acp-framework/acp-go/pkg/api"
+	"github.com/chelof100/acp-framework/acp-go/pkg/budget"
+	"github.com/chelof100/acp-framework/acp-go/pkg/bulk"
+	"github.com/chelof100/acp-framework/acp-go/pkg/crossorg"
+	"github.com/chelof100/acp-framework/acp-go/pkg/delegation"
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
 	"github.com/chelof100/acp-framework/acp-go/pkg/execution"
+	"github.com/chelof100/acp-framework/acp-go/pkg/govevents"
 	"github.com/chelof100/acp-framework/acp-go/pkg/handshake"
+	"github.com/chelof100/acp-framework/acp-go/pkg/hist"
+	"github.com/chelof100/acp-framework/acp-go/pkg/idempotency"
+	"github.com/chelof100/acp-framework/acp-go/pkg/keyring"
 	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/chelof100/acp-framework/acp-go/pkg/merkle"
+	"github.com/chelof100/acp-framework/acp-go/pkg/lia"
+	"github.com/chelof100/acp-framework/acp-go/pkg/lifecycle"
+	"github.com/chelof100/acp-framework/acp-go/pkg/pay"
+	"github.com/chelof100/acp-framework/acp-go/pkg/provenance"
+	"github.com/chelof100/acp-framework/acp-go/pkg/psn"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ratelimit"
 	"github.com/chelof100/acp-framework/acp-go/pkg/registry"
+	"github.com/chelof100/acp-framework/acp-go/pkg/replica"
 	"github.com/chelof100/acp-framework/acp-go/pkg/reputation"
 	"github.com/chelof100/acp-framework/acp-go/pkg/revocation"
 	"github.com/chelof100/acp-framework/acp-go/pkg/risk"
+	"github.com/chelof100/acp-framework/acp-go/pkg/shred"
 	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
 )
 
 // ─── Server State ─────────────────────────────────────────────────────────────
 
+// nonceStore is the replay-prevention store used by /verify, plus the
+// maintenance hooks the server needs (pruning, health).
+type nonceStore interface {
+	tokens.NonceStore
+	Prune() int
+	Size() int
+}
+
 type server struct {
 	challenges         *handshake.ChallengeStore
 	registry           *registry.InMemoryRegistry
 	revStore           revocation.RevocationStore
 	revChecker         tokens.RevocationChecker
 	repEngine          *reputation.Engine
-	nonceStore         *tokens.InMemoryNonceStore
+	lifecycle          *lifecycle.Service            // single agent state machine (registry + reputation)
+	nonceStore         nonceStore                    // ACP-CT-1.0 replay prevention
 	etRegistry         *execution.InMemoryETRegistry // ACP-EXEC-1.0
+	targets            *execution.InMemoryTargetRegistry // ACP-EXEC-1.0 §8 ET consumers
 	auditLedger        *ledger.InMemoryLedger        // ACP-LEDGER-1.0
+	liaStore           *lia.InMemoryLiabilityStore   // ACP-LIA-1.0
+	ledgerIdx          *ledgerIndex                  // tokens and escalations for liability records
+	provStore          *provenance.InMemoryProvenanceStore // ACP-PROVENANCE-1.0
+	anomaly            *risk.InMemoryQuerier         // ACP-RISK-2.0 F_anom state
+	anomalyMu          sync.Mutex                    // orders score → record of anomaly state
+	riskPolicy         risk.PolicyConfig             // F_anom rule thresholds
+	rateLimiter        *ratelimit.Limiter            // per-agent quotas (ACP_RATE_LIMITS) and the ACP-BULK-1.0 §4 limit
+	requestIDs         *idempotency.Store            // /authorize request_id de-duplication (AUTH-004)
+	psnStore           *psn.InMemorySnapshotStore    // ACP-PSN-1.0
+	budgets            *budget.Tracker               // cumulative spend against snapshot budgets
+	budgetMu           sync.Mutex                    // orders budget check → reservation
+	crossPeers         *crossorg.PeerRegistry        // ACP-CROSS-ORG-1.1 federated institutions
+	crossStore         *crossorg.InMemoryCrossOrgStore
+	crossRecv          *crossorg.Receiver // inbound bundles → ACKs
+	outbox             *crossorg.Outbox   // outbound bundles, delivered with retries
+	witness            *crossorg.WitnessService // co-signs the checkpoints of peers
+	checkpoints        *crossorg.Checkpointer   // publishes this ledger's checkpoints to witnesses
+	follower           *replica.Follower        // non-nil in follower mode (ACP_FOLLOW)
+	shredStore         *shred.Store             // subject keys; nil unless ACP_REDACT_SENSITIVE
+	archiveRetention   time.Duration            // events older than this are archived; 0 = on demand only
+	payProviders       *pay.ProviderRegistry // ACP-PAY-1.0 §4 settlement providers
+	payStore           *pay.InMemoryPayStore // verified proofs, hash-chained
 	institutionID      string
-	institutionPubKey  ed25519.PublicKey
-	institutionPrivKey ed25519.PrivateKey // nil if ACP_INSTITUTION_PRIVATE_KEY not set
+	keys               *keyring.Keyring // institution keyring; private key nil if ACP_INSTITUTION_PRIVATE_KEY not set
+	didResolver        did.Multi        // did:key, did:web, did:acpd
+	trustedDIDs        map[string]bool  // DID token issuers trusted besides did:acpd (ACP_TRUSTED_DIDS)
+	rotateMu           sync.Mutex       // serialises key rotations
 	addr               string
 }
 
@@ -90,18 +159,155 @@ func main() {
 		log.Printf("[ACP] ACP_INSTITUTION_ID not set — using default: %s", institutionID)
 	}
 
+	// 3b. Build the institution keyring. The initial key is valid from the
+	// epoch so artifacts signed before the keyring existed remain verifiable.
+	keys, err := keyring.New(institutionID, os.Getenv("ACP_INSTITUTION_KEY_ID"),
+		ed25519.PublicKey(pubKeyBytes), institutionPrivKey, 0)
+	if err != nil {
+		log.Fatalf("[ACP] failed to initialize keyring: %v", err)
+	}
+	activeKID, _ := keys.Active()
+	log.Printf("[ACP/KEYS] keyring initialized (active kid=%s)", activeKID)
+
 	// 4. Determine listen address.
 	addr := os.Getenv("ACP_ADDR")
 	if addr == "" {
 		addr = ":8080"
 	}
 
+	// 4b. Follower mode: replicate the audit ledger of the primary at
+	// ACP_FOLLOW and serve it read-only (follower.go).
+	if primary := os.Getenv("ACP_FOLLOW"); primary != "" {
+		runFollower(primary, institutionID, ed25519.PublicKey(pubKeyBytes), addr)
+		return
+	}
+
 	// 5. Initialise audit ledger (ACP-LEDGER-1.0).
-	auditLedger, err := ledger.NewInMemoryLedger(institutionID, institutionPrivKey)
+	auditLedger, err := ledger.NewInMemoryLedgerWithKeyID(institutionID, activeKID, institutionPrivKey)
 	if err != nil {
 		log.Fatalf("[ACP] failed to initialize audit ledger: %v", err)
 	}
+	auditLedger.SetKeyResolver(keys)
+	// ACP_LEDGER_SCHEMA=strict rejects appends whose payload misses a §5
+	// field and reports such events in Verify; the lenient default keeps
+	// ledgers written before the schemas were enforced verifiable.
+	auditLedger.SetSchemaMode(ledgerSchemaMode())
 	log.Printf("[ACP/LEDGER] initialized (genesis seq=1 institution=%s)", institutionID)
+	// Durable backend: every append is fsynced to ACP_LEDGER_PATH before the
+	// decision it records is released. The events of previous runs restore
+	// the risk history (step 6a).
+	var priorEvents []ledger.Event
+	if path := os.Getenv("ACP_LEDGER_PATH"); path != "" {
+		if priorEvents, err = ledger.ReadFile(path); err != nil {
+			log.Printf("[ACP/LEDGER] previous runs partially read: %v", err)
+		}
+		if err := auditLedger.SetBackend(ledger.NewFileBackend(path)); err != nil {
+			log.Fatalf("[ACP] failed to open ledger file: %v", err)
+		}
+		log.Printf("[ACP/LEDGER] events persisted at %s", path)
+	}
+	// Segment archival: events older than ACP_ARCHIVE_RETENTION are sealed
+	// into segments under ACP_ARCHIVE_DIR and dropped from memory.
+	var archiveRetention time.Duration
+	if dir := os.Getenv("ACP_ARCHIVE_DIR"); dir != "" {
+		archive, err := ledger.NewDirArchive(dir)
+		if err != nil {
+			log.Fatalf("[ACP] failed to open ledger archive: %v", err)
+		}
+		auditLedger.SetArchive(archive)
+		if v := os.Getenv("ACP_ARCHIVE_RETENTION"); v != "" {
+			if archiveRetention, err = time.ParseDuration(v); err != nil || archiveRetention <= 0 {
+				log.Fatalf("[ACP] invalid ACP_ARCHIVE_RETENTION %q", v)
+			}
+		}
+		log.Printf("[ACP/LEDGER] segments archived at %s (retention %v)", dir, archiveRetention)
+	}
+	// 5a. Crypto-shredding: sensitive payload fields are sealed under keys of
+	// their subject, kept outside the ledger, before they are hashed.
+	var shredStore *shred.Store
+	if on, _ := strconv.ParseBool(os.Getenv("ACP_REDACT_SENSITIVE")); on {
+		shredStore = shred.NewStore()
+		if dir := os.Getenv("ACP_SHRED_KEYS_DIR"); dir != "" {
+			if shredStore, err = shred.OpenFileStore(dir); err != nil {
+				log.Fatalf("[ACP] failed to open shred key store: %v", err)
+			}
+			log.Printf("[ACP/LEDGER] subject keys persisted at %s (%d subjects)", dir, shredStore.Size())
+		} else if os.Getenv("ACP_LEDGER_PATH") != "" {
+			log.Printf("[ACP/LEDGER] subject keys in memory: sealed fields of persisted events are lost on restart")
+		}
+		auditLedger.SetRedactor(shredStore)
+		log.Printf("[ACP/LEDGER] sensitive payload fields sealed (crypto-shredding enabled)")
+	}
+
+	// 5b. Bootstrap the active policy snapshot (ACP-PSN-1.0) from the built-in
+	// autonomy-level thresholds so liability records can reference it.
+	psnStore := psn.NewInMemorySnapshotStore()
+	snap, err := psn.Create(psn.CreateRequest{
+		InstitutionID: institutionID,
+		PolicyVersion: "builtin-1",
+		Thresholds:    builtinThresholds(),
+		CreatedBy:     institutionID,
+	}, institutionPrivKey)
+	if err != nil {
+		log.Fatalf("[ACP] failed to create policy snapshot: %v", err)
+	}
+	if err := psnStore.Activate(snap); err != nil {
+		log.Fatalf("[ACP] failed to activate policy snapshot: %v", err)
+	}
+	if _, err := auditLedger.Append(ledger.EventPolicySnapshotCreated, map[string]interface{}{
+		"snapshot_id":          snap.SnapshotID,
+		"policy_version":       snap.PolicyVersion,
+		"effective_from":       snap.EffectiveFrom,
+		"previous_snapshot_id": nil,
+		"created_by":           snap.CreatedBy,
+	}); err != nil {
+		log.Fatalf("[ACP] failed to record policy snapshot: %v", err)
+	}
+	log.Printf("[ACP/PSN] active policy snapshot %s (%s)", snap.SnapshotID, snap.PolicyVersion)
+
+	// 5c. Nonce store: persistent when ACP_NONCE_STORE_PATH is set, so that
+	// tokens already verified stay rejected across restarts.
+	var nonces nonceStore = tokens.NewInMemoryNonceStore()
+	if path := os.Getenv("ACP_NONCE_STORE_PATH"); path != "" {
+		fns, err := tokens.OpenFileNonceStore(path)
+		if err != nil {
+			log.Fatalf("[ACP] failed to open nonce store: %v", err)
+		}
+		nonces = fns
+		log.Printf("[ACP] nonce store persisted at %s (%d live nonces)", path, fns.Size())
+	}
+
+	// 5d. Per-agent rate limits on /authorize, /authorize/batch and /verify,
+	// by autonomy level. "off" leaves every level unlimited; the bulk limit
+	// of ACP-BULK-1.0 §4 applies either way.
+	var limits map[int]ratelimit.Limit
+	if spec := os.Getenv("ACP_RATE_LIMITS"); spec != "off" {
+		limits = ratelimit.DefaultLimits
+		if spec != "" {
+			parsed, err := ratelimit.ParseLimits(spec)
+			if err != nil {
+				log.Fatalf("[ACP] invalid ACP_RATE_LIMITS: %v", err)
+			}
+			limits = make(map[int]ratelimit.Limit)
+			for lvl, lim := range ratelimit.DefaultLimits {
+				limits[lvl] = lim
+			}
+			for lvl, lim := range parsed {
+				limits[lvl] = lim
+			}
+		}
+		log.Printf("[ACP] rate limits per autonomy level: %s", ratelimit.FormatLimits(limits))
+	}
+	rateLimiter := ratelimit.New(ratelimit.NewMemoryStore(), limits)
+
+	// 5e. /authorize request_id de-duplication window (ACP-API-1.0 AUTH-004).
+	requestIDTTL := idempotency.DefaultTTL
+	if v := os.Getenv("ACP_REQUEST_ID_TTL"); v != "" {
+		var err error
+		if requestIDTTL, err = time.ParseDuration(v); err != nil || requestIDTTL <= 0 {
+			log.Fatalf("[ACP] invalid ACP_REQUEST_ID_TTL %q", v)
+		}
+	}
 
 	// 6. Initialise server components.
 	revStore := revocation.NewInMemoryRevocationStore()
@@ -111,14 +317,50 @@ func main() {
 		revStore:           revStore,
 		revChecker:         revocation.NewStoreRevocationChecker(revStore),
 		repEngine:          reputation.NewDefaultEngine(reputation.NewInMemoryReputationStore()),
-		nonceStore:         tokens.NewInMemoryNonceStore(),
+		nonceStore:         nonces,
 		etRegistry:         execution.NewInMemoryETRegistry(),
+		targets:            execution.NewInMemoryTargetRegistry(),
 		auditLedger:        auditLedger,
+		liaStore:           lia.NewInMemoryLiabilityStore(),
+		ledgerIdx:          newLedgerIndex(),
+		provStore:          provenance.NewInMemoryProvenanceStore(),
+		anomaly:            risk.NewInMemoryQuerier(),
+		riskPolicy:         risk.DefaultPolicyConfig(),
+		rateLimiter:        rateLimiter,
+		requestIDs:         idempotency.NewStore(requestIDTTL),
+		psnStore:           psnStore,
+		budgets:            budget.NewTracker(),
+		payProviders:       pay.NewProviderRegistry(),
+		payStore:           pay.NewInMemoryPayStore(),
+		shredStore:         shredStore,
+		archiveRetention:   archiveRetention,
 		institutionID:      institutionID,
-		institutionPubKey:  ed25519.PublicKey(pubKeyBytes),
-		institutionPrivKey: institutionPrivKey,
+		keys:               keys,
 		addr:               addr,
 	}
+	srv.lifecycle = lifecycle.NewService(institutionID, srv.registry, srv.repEngine, auditLedger, keys.Active)
+	srv.lifecycle.OnRecordError = func(err error) { log.Printf("[ACP/AGENTS] %v", err) }
+	srv.didResolver = newDIDResolver(srv)
+	srv.trustedDIDs = make(map[string]bool)
+	for _, id := range strings.Split(os.Getenv("ACP_TRUSTED_DIDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			srv.trustedDIDs[id] = true
+		}
+	}
+	srv.crossPeers = crossorg.NewPeerRegistry()
+	srv.crossPeers.SetResolver(srv.didResolver)
+	srv.crossStore = crossorg.NewInMemoryCrossOrgStore()
+	srv.crossRecv = crossorg.NewReceiver(institutionID, keys.Active, srv.crossPeers, srv.crossStore, auditLedger)
+	srv.outbox = crossorg.NewOutbox(institutionID, srv.crossPeers, srv.crossStore, auditLedger, crossorg.OutboxConfig{})
+	srv.witness = crossorg.NewWitnessService(institutionID, keys.Active, srv.crossPeers)
+	var checkpointInterval time.Duration
+	srv.checkpoints, checkpointInterval = newCheckpointer(srv)
+
+	// 6a. Risk history (F_hist, F_anom) of previous runs, from the ledger file.
+	if len(priorEvents) > 0 {
+		n := srv.restoreRiskHistory(priorEvents, time.Now())
+		log.Printf("[ACP/RISK] risk history restored: %d decisions from %d ledger events", n, len(priorEvents))
+	}
 
 	// 7. Build mux and apply ACP-API-1.0 middleware.
 	mux := http.NewServeMux()
@@ -134,21 +376,68 @@ func main() {
 	mux.HandleFunc("POST /acp/v1/agents",                    srv.handleAgentRegister)
 	mux.HandleFunc("GET /acp/v1/agents/{agent_id}",          srv.handleAgentGet)
 	mux.HandleFunc("POST /acp/v1/agents/{agent_id}/state",   srv.handleAgentState)
+	mux.HandleFunc("GET /acp/v1/agents/{agent_id}/keys",     srv.handleAgentKeys)
+	mux.HandleFunc("POST /acp/v1/agents/{agent_id}/keys/rotate", srv.handleAgentKeyRotate)
+
+	// ── ACP-D §4: DID resolution ─────────────────────────────────────────────
+	mux.HandleFunc("GET /acp/v1/did/{did}", srv.handleDIDResolve)
 
 	// ── ACP-API-1.0 §5: Authorization ────────────────────────────────────────
 	mux.HandleFunc("POST /acp/v1/authorize",                                           srv.handleAuthorize)
 	mux.HandleFunc("POST /acp/v1/authorize/escalations/{escalation_id}/resolve",       srv.handleEscalationResolve)
 
+	// ── Spend budgets (per policy snapshot) ──────────────────────────────────
+	mux.HandleFunc("POST /acp/v1/policy/budgets", srv.handleBudgetsSet)
+	mux.HandleFunc("GET /acp/v1/budgets/usage",   srv.handleBudgetUsage)
+
+	// ── ACP-BULK-1.0: Batch operations ───────────────────────────────────────
+	mux.HandleFunc("POST /acp/v1/authorize/batch",       srv.handleAuthorizeBatch)
+	mux.HandleFunc("POST /acp/v1/bulk/authorize",        srv.handleAuthorizeBatch) // spec path (§2.1)
+	mux.HandleFunc("POST /acp/v1/liability/query",       srv.handleLiabilityQuery)
+	mux.HandleFunc("POST /acp/v1/bulk/liability-query",  srv.handleLiabilityQuery) // spec path (§3.1)
+
 	// ── ACP-API-1.0 §6: Capability Tokens (stub) ─────────────────────────────
 	mux.HandleFunc("POST /acp/v1/tokens", srv.handleTokensIssue)
 
 	// ── ACP-API-1.0 §7: Audit — ACP-LEDGER-1.0 ───────────────────────────────
 	mux.HandleFunc("POST /acp/v1/audit/query",               srv.handleAuditQuery)
 	mux.HandleFunc("GET /acp/v1/audit/verify/{event_id}",    srv.handleAuditVerify)
+	mux.HandleFunc("GET /acp/v1/audit/tree-head",            srv.handleAuditTreeHead)
+	mux.HandleFunc("GET /acp/v1/audit/proof/inclusion",      srv.handleAuditInclusionProof)
+	mux.HandleFunc("GET /acp/v1/audit/proof/consistency",    srv.handleAuditConsistencyProof)
+	mux.HandleFunc("POST /acp/v1/audit/checkpoints",         srv.handleCheckpointPublish)
+	mux.HandleFunc("GET /acp/v1/audit/checkpoints/latest",   srv.handleCheckpointLatest)
+	mux.Handle("GET "+replica.StreamPath,                     replica.NewStreamer(auditLedger, 0))
+	mux.HandleFunc("POST /acp/v1/audit/subjects/{subject_id}/shred", srv.handleSubjectShred)
+	mux.HandleFunc("GET /acp/v1/audit/segments",             srv.handleSegmentList)
+	mux.HandleFunc("POST /acp/v1/audit/segments",            srv.handleSegmentArchive)
+
+	// ── ACP-HIST-1.0: History Query API ──────────────────────────────────────
+	mux.HandleFunc("GET /acp/v1/audit/query",                      srv.handleHistQuery)
+	mux.HandleFunc("GET /acp/v1/audit/events/{event_id}",          srv.handleHistEvent)
+	mux.HandleFunc("GET /acp/v1/audit/agents/{agent_id}/history",  srv.handleHistAgentHistory)
 
 	// ── ACP-EXEC-1.0 §9: Execution Tokens ────────────────────────────────────
 	mux.HandleFunc("POST /acp/v1/exec-tokens/{et_id}/consume", srv.handleExecTokenConsume)
 	mux.HandleFunc("GET /acp/v1/exec-tokens/{et_id}/status",   srv.handleExecTokenStatus)
+	mux.HandleFunc("POST /acp/v1/target-systems",              srv.handleTargetSystemRegister)
+	mux.HandleFunc("GET /acp/v1/target-systems/{system_id}",   srv.handleTargetSystemGet)
+
+	// ── ACP-LIA-1.0 §9: Liability Traceability ───────────────────────────────
+	mux.HandleFunc("GET /acp/v1/liability/by-et/{et_id}",       srv.handleLiabilityByET)
+	mux.HandleFunc("GET /acp/v1/liability/by-agent/{agent_id}", srv.handleLiabilityByAgent)
+	mux.HandleFunc("GET /acp/v1/liability/{liability_id}",      srv.handleLiabilityGet)
+
+	// ── ACP-PROVENANCE-1.0 §7: Authority Provenance ──────────────────────────
+	mux.HandleFunc("GET /acp/v1/provenance/by-et/{et_id}", srv.handleProvenanceByET)
+
+	// ── ACP-PAY-1.0: Settlement Proof Verification ───────────────────────────
+	mux.HandleFunc("POST /acp/v1/pay/providers",          srv.handlePayProviderRegister)
+	mux.HandleFunc("POST /acp/v1/pay/verify",             srv.handlePayVerify)
+	mux.HandleFunc("POST /acp/v1/payment/verify",         srv.handlePayVerify) // spec path (§5.1)
+	mux.HandleFunc("GET /acp/v1/pay/proofs",              srv.handlePayProofList)
+	mux.HandleFunc("GET /acp/v1/pay/proofs/{proof_id}",   srv.handlePayProofGet)
+	mux.HandleFunc("GET /acp/v1/payment/{proof_id}",      srv.handlePayProofGet) // spec path (§5.2)
 
 	// ── ACP-REV-1.0 ──────────────────────────────────────────────────────────
 	mux.HandleFunc("GET /acp/v1/rev/check",   srv.handleRevCheck)
@@ -158,6 +447,20 @@ func main() {
 	mux.HandleFunc("GET /acp/v1/rep/{agent_id}",         srv.handleRepGet)
 	mux.HandleFunc("GET /acp/v1/rep/{agent_id}/events",  srv.handleRepEvents)
 	mux.HandleFunc("POST /acp/v1/rep/{agent_id}/state",  srv.handleRepState)
+	mux.HandleFunc("POST /acp/v1/rep/{agent_id}/import", srv.handleRepImport)
+
+	// ── Institution keyring ──────────────────────────────────────────────────
+	// ── ACP-CROSS-ORG-1.1: Cross-Organizational Bundles ───────────────────────
+	mux.Handle("POST "+crossorg.BundlesPath,                             srv.crossRecv)
+	mux.HandleFunc("POST /acp/v1/crossorg/peers",                        srv.handleCrossOrgPeerRegister)
+	mux.HandleFunc("GET /acp/v1/crossorg/peers/{institution_id}/bundles", srv.handleCrossOrgPeerBundles)
+	mux.HandleFunc("POST /acp/v1/crossorg/outbox",                       srv.handleCrossOrgSend)
+	mux.HandleFunc("GET /acp/v1/crossorg/outbox/{bundle_id}",            srv.handleCrossOrgOutboxGet)
+	mux.HandleFunc("POST "+crossorg.WitnessCosignPath,                   srv.witness.ServeCosign)
+	mux.HandleFunc("GET "+crossorg.WitnessCheckpointsPath+"{institution_id}", srv.witness.ServeLatest)
+
+	mux.HandleFunc("GET /.well-known/acp-keys",   srv.handleWellKnownKeys)
+	mux.HandleFunc("POST /acp/v1/keys/rotate",    srv.handleKeyRotate)
 
 	// ── Health ────────────────────────────────────────────────────────────────
 	mux.HandleFunc("GET /acp/v1/health", srv.handleHealth)
@@ -181,9 +484,32 @@ func main() {
 			if n := srv.etRegistry.Prune(); n > 0 {
 				log.Printf("[ACP/EXEC] pruned %d expired/used ETs", n)
 			}
+			srv.rateLimiter.Prune(time.Now())
+			srv.requestIDs.Prune(time.Now())
+			// HistoryWindow (30 days) is the longest window F_hist or F_anom reads.
+			if n := srv.anomaly.Prune(time.Now().Add(-risk.HistoryWindow)); n > 0 {
+				log.Printf("[ACP/RISK] pruned %d anomaly entries", n)
+			}
+			if srv.archiveRetention > 0 {
+				cp, err := srv.auditLedger.ArchiveBefore(time.Now().Add(-srv.archiveRetention).Unix())
+				switch {
+				case err == nil:
+					log.Printf("[ACP/LEDGER] archived events %d-%d", cp.FirstSequence, cp.LastSequence)
+				case !errors.Is(err, ledger.ErrNothingToArchive):
+					log.Printf("[ACP/LEDGER] archival failed: %v", err)
+				}
+			}
 		}
 	}()
 
+	// 9b. Cross-org outbox worker (ACP-CROSS-ORG-1.1 §8).
+	go srv.outbox.Run(context.Background())
+
+	// 9c. Periodic witness checkpoints, when ACP_CHECKPOINT_INTERVAL is set.
+	if checkpointInterval > 0 {
+		go srv.checkpoints.Run(context.Background(), func(err error) { log.Printf("[ACP/WITNESS] checkpoint: %v", err) })
+	}
+
 	// 10. Start server.
 	log.Printf("[ACP] server listening on %s", addr)
 	log.Printf("[ACP] institution pubkey: %s...", pubKeyB64[:min(16, len(pubKeyB64))])
@@ -273,11 +599,11 @@ func (s *server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
 	})
 
 	log.Printf("[ACP/AGENTS] registered agent %s (autonomy=%d domain=%s)", req.AgentID, req.AutonomyLevel, req.AuthorityDomain)
-	acpapi.WriteSuccess(w, r, http.StatusCreated, map[string]interface{}{
+	s.writeSuccess(w, r, http.StatusCreated, map[string]interface{}{
 		"agent_id":      req.AgentID,
 		"status":        string(registry.StatusActive),
 		"registered_at": now,
-	}, s.institutionPrivKey)
+	})
 }
 
 // handleAgentGet returns the current state of an agent.
@@ -305,7 +631,7 @@ func (s *server) handleAgentGet(w http.ResponseWriter, r *http.Request) {
 		trustScore = *repRec.Score
 	}
 
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
 		"agent_id":         rec.AgentID,
 		"status":           string(rec.Status),
 		"autonomy_level":   rec.AutonomyLevel,
@@ -314,7 +640,115 @@ func (s *server) handleAgentGet(w http.ResponseWriter, r *http.Request) {
 		"registered_at":    rec.RegisteredAt,
 		"last_active_at":   rec.LastActiveAt,
 		"trust_score":      trustScore,
-	}, s.institutionPrivKey)
+		"keys":             rec.Keys,
+	})
+}
+
+// handleAgentKeys returns an agent's key history with validity windows.
+// GET /acp/v1/agents/{agent_id}/keys
+//
+// Response 200: data.{agent_id, current_kid, keys[{kid, public_key, valid_from, valid_until?}]}
+func (s *server) handleAgentKeys(w http.ResponseWriter, r *http.Request) {
+	agentID := r.PathValue("agent_id")
+
+	keys, err := s.registry.KeyHistory(agentID)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrAGENT005, fmt.Sprintf("agent %q not found", agentID))
+		return
+	}
+	currentKID := ""
+	if len(keys) > 0 {
+		currentKID = keys[len(keys)-1].KID
+	}
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"agent_id":    agentID,
+		"current_kid": currentKID,
+		"keys":        keys,
+	})
+}
+
+// rotationMaxSkew bounds |now − statement.rotated_at| in seconds, so a
+// rotation cannot be back-dated to revalidate old signatures.
+const rotationMaxSkew = 300
+
+// handleAgentKeyRotate rotates an agent's key. The agent_id is unchanged.
+// POST /acp/v1/agents/{agent_id}/keys/rotate
+//
+// Body: {statement: {ver, agent_id, old_kid, new_public_key, rotated_at, overlap_period}, sig, new_key_sig}
+//   sig         — current key over SHA-256(JCS(statement))
+//   new_key_sig — new key over the same digest (proof of possession)
+// Response 200: data.{agent_id, old_kid, new_kid, valid_from, old_key_valid_until, ledger_event_id}
+//
+// The old key stays valid until rotated_at + overlap_period, so tokens and
+// PoP signatures made with it during the overlap still verify.
+func (s *server) handleAgentKeyRotate(w http.ResponseWriter, r *http.Request) {
+	agentID := r.PathValue("agent_id")
+
+	var req struct {
+		Statement registry.RotationStatement `json:"statement"`
+		Sig       string                     `json:"sig"`
+		NewKeySig string                     `json:"new_key_sig"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	st := req.Statement
+	if st.AgentID != agentID {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "statement.agent_id does not match path")
+		return
+	}
+	if d := time.Now().Unix() - st.RotatedAt; d > rotationMaxSkew || d < -rotationMaxSkew {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004,
+			fmt.Sprintf("statement.rotated_at must be within %ds of server time", rotationMaxSkew))
+		return
+	}
+
+	oldKID := st.OldKID
+	next, err := s.registry.RotateKey(st, req.Sig, req.NewKeySig)
+	if err != nil {
+		switch {
+		case errors.Is(err, registry.ErrAgentNotFound):
+			acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrAGENT005, fmt.Sprintf("agent %q not found", agentID))
+		case errors.Is(err, registry.ErrAgentRevoked):
+			acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrSTATE002, "agent is revoked — irreversible state")
+		case errors.Is(err, registry.ErrRotationNotEndorsed), errors.Is(err, registry.ErrRotationPossession):
+			acpapi.WriteError(w, r, http.StatusForbidden, acpapi.ErrAGENT006, err.Error())
+		case errors.Is(err, registry.ErrKeyReused):
+			acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrAGENT007, err.Error())
+		default:
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+		}
+		return
+	}
+	oldValidUntil := st.RotatedAt + st.OverlapPeriod
+
+	ev, err := s.auditLedger.Append(ledger.EventAgentKeyRotated, map[string]interface{}{
+		"agent_id":            agentID,
+		"old_kid":             oldKID,
+		"new_kid":             next.KID,
+		"new_public_key":      next.PublicKeyB64,
+		"rotated_at":          st.RotatedAt,
+		"overlap_period":      st.OverlapPeriod,
+		"old_key_valid_until": oldValidUntil,
+		"sig":                 req.Sig,
+		"new_key_sig":         req.NewKeySig,
+	})
+	if err != nil {
+		log.Printf("[ACP/AGENTS] key rotation for %s applied but not recorded: %v", agentID, err)
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003, "key rotated but audit ledger unavailable")
+		return
+	}
+
+	log.Printf("[ACP/AGENTS] key rotated for %s: %s → %s (overlap %ds)", agentID, oldKID, next.KID, st.OverlapPeriod)
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"agent_id":            agentID,
+		"old_kid":             oldKID,
+		"new_kid":             next.KID,
+		"valid_from":          next.ValidFrom,
+		"old_key_valid_until": oldValidUntil,
+		"ledger_event_id":     ev.EventID,
+	})
 }
 
 // handleAgentState transitions an agent to a new status.
@@ -347,44 +781,83 @@ func (s *server) handleAgentState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Capture previous state for ledger before update.
-	var prevState string
-	if existing, err := s.registry.GetRecord(agentID); err == nil {
-		prevState = string(existing.Status)
+	if _, err := s.registry.GetRecord(agentID); err != nil {
+		acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrAGENT005, fmt.Sprintf("agent %q not found", agentID))
+		return
 	}
 
-	if err := s.registry.UpdateStatus(agentID, newStatus); err != nil {
-		if errors.Is(err, registry.ErrAgentNotFound) {
-			acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrAGENT005, fmt.Sprintf("agent %q not found", agentID))
-			return
-		}
-		if errors.Is(err, registry.ErrAgentRevoked) {
-			acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrSTATE002, "agent is revoked — irreversible state")
+	// The lifecycle service updates registry and reputation state and emits
+	// AGENT_STATE_CHANGE plus agent_suspended / agent_reinstated.
+	source := lifecycle.SourceRegistryAdmin
+	if newStatus == registry.StatusRevoked {
+		source = lifecycle.SourceRevocation
+	}
+	change, err := s.lifecycle.Transition(lifecycle.Request{
+		AgentID:          agentID,
+		Status:           newStatus,
+		Reason:           req.Reason,
+		AuthorizedBy:     req.AuthorizedBy,
+		Source:           source,
+		AuthorizationRef: acpapi.GetRequestID(r),
+	})
+	if err != nil {
+		s.writeTransitionError(w, r, err)
+		return
+	}
+
+	log.Printf("[ACP/AGENTS] state change %s → %s (by %s: %s)", agentID, newStatus, req.AuthorizedBy, req.Reason)
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"agent_id":         agentID,
+		"state":            string(newStatus),
+		"previous_state":   string(change.Previous),
+		"reputation_state": string(change.ReputationState),
+	})
+}
+
+// writeTransitionError maps a lifecycle.Transition error to an API error.
+func (s *server) writeTransitionError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, registry.ErrAgentRevoked), errors.Is(err, reputation.ErrAgentBanned):
+		acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrSTATE002, "agent is revoked — irreversible state")
+	case errors.Is(err, registry.ErrInvalidTransition):
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSTATE001, err.Error())
+	default:
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+	}
+}
+
+// ─── ACP-D §4: DID Resolution Handler ─────────────────────────────────────────
+
+// handleDIDResolve resolves a DID to its document (did:key, did:web, did:acpd).
+// GET /acp/v1/did/{did}[?at=<unix>]
+//
+// at resolves did:acpd as of a past time (keys retired by a rotation).
+// Response 200: data = DID document
+// Response 400: DID-001; 404: DID-002
+func (s *server) handleDIDResolve(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("did")
+
+	var doc *did.Document
+	var err error
+	if at := r.URL.Query().Get("at"); at != "" {
+		ts, perr := strconv.ParseInt(at, 10, 64)
+		if perr != nil {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "at must be a Unix timestamp")
 			return
 		}
-		if errors.Is(err, registry.ErrInvalidTransition) {
-			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSTATE001, err.Error())
+		doc, err = s.didResolver.ResolveAt(id, ts)
+	} else {
+		doc, err = s.didResolver.Resolve(id)
+	}
+	if err != nil {
+		if errors.Is(err, did.ErrInvalidDID) || errors.Is(err, did.ErrUnsupportedMethod) {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrDID001, err.Error())
 			return
 		}
-		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+		acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrDID002, err.Error())
 		return
 	}
-
-	// ACP-LEDGER-1.0: emit AGENT_STATE_CHANGE event.
-	s.emitLedgerEvent(ledger.EventAgentStateChange, map[string]interface{}{
-		"agent_id":          agentID,
-		"previous_state":    prevState,
-		"new_state":         string(newStatus),
-		"reason_code":       req.Reason,
-		"authorized_by":     req.AuthorizedBy,
-		"authorization_ref": acpapi.GetRequestID(r),
-	})
-
-	log.Printf("[ACP/AGENTS] state change %s → %s (by %s: %s)", agentID, newStatus, req.AuthorizedBy, req.Reason)
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
-		"agent_id": agentID,
-		"state":    string(newStatus),
-	}, s.institutionPrivKey)
+	s.writeSuccess(w, r, http.StatusOK, doc)
 }
 
 // ─── ACP-API-1.0 §5: Authorization Handler ───────────────────────────────────
@@ -392,8 +865,22 @@ func (s *server) handleAgentState(w http.ResponseWriter, r *http.Request) {
 // handleAuthorize evaluates an authorization request (ACP-API-1.0 §5).
 // POST /acp/v1/authorize
 //
-// Body: {request_id, agent_id, capability, resource, action_parameters, context, sig}
+// Body: {request_id, agent_id, capability, resource, action_parameters, context,
+//        target_system, delegation_chain, sig}
 // Response 200: {decision: APPROVED|DENIED|ESCALATED, risk_score, ...}
+// Response 400: AUTH-004 — the agent already used request_id for a different request
+// Response 403: delegation_chain does not verify (AUTH-001, AUTH-002, AUTH-006)
+// Response 429: BULK-002 with Retry-After — the agent exceeded its rate limit
+// Response 503: SYS-003 — the decision could not be recorded in the audit ledger
+//
+// request_id (optional) makes the request idempotent for ACP_REQUEST_ID_TTL:
+// a retry with the same body gets the original signed response back, header
+// X-ACP-Idempotent-Replay: true, instead of a second decision. Request IDs
+// are scoped to the agent_id, so agents cannot collide with each other.
+//
+// delegation_chain (optional) is the ACP-CT-1.0 token chain, root first, by
+// which the agent holds the capability. An APPROVED decision records it as
+// the AuthorityProvenance of the issued ET (ACP-PROVENANCE-1.0).
 //
 // Processing order per §5:
 //  1. Validate request JSON
@@ -401,17 +888,9 @@ func (s *server) handleAgentState(w http.ResponseWriter, r *http.Request) {
 //  3. autonomy_level == 0 → DENIED (AUTH-008)
 //  4. Run ACP-RISK-1.0
 //  5. Apply thresholds by autonomy_level → decision
-//  6. Return decision + emit ledger events
+//  6. Record the decision in the ledger, then return it (fail closed)
 func (s *server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		RequestID        string                 `json:"request_id"`
-		AgentID          string                 `json:"agent_id"`
-		Capability       string                 `json:"capability"`
-		Resource         string                 `json:"resource"`
-		ActionParameters map[string]interface{} `json:"action_parameters"`
-		Context          map[string]interface{} `json:"context"`
-		Sig              string                 `json:"sig"`
-	}
+	var req authzRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
 		return
@@ -420,51 +899,158 @@ func (s *server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
 		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "agent_id, capability, and resource are required")
 		return
 	}
+	// A verified delegation_chain authenticates the agent; the quota is
+	// applied once it is known whether it did.
+	var chain delegation.Chain
+	if len(req.DelegationChain) > 0 {
+		var err error
+		if chain, err = s.verifyDelegationChain(req); err != nil {
+			code := acpapi.ErrAUTH002
+			switch {
+			case errors.Is(err, tokens.ErrCT010TokenRevoked):
+				code = acpapi.ErrAUTH006
+			case errors.Is(err, tokens.ErrCT002InvalidSignature), errors.Is(err, tokens.ErrCT003TokenExpired),
+				errors.Is(err, tokens.ErrCT004TokenNotYetValid):
+				code = acpapi.ErrAUTH001
+			}
+			acpapi.WriteError(w, r, http.StatusForbidden, code, err.Error())
+			return
+		}
+	}
+	if s.rateLimited(w, r, req.AgentID, "authorize", chain != nil) {
+		return
+	}
 
-	// Step 2: Check agent status.
-	rec, err := s.registry.GetRecord(req.AgentID)
-	if err != nil {
-		// Agent may be registered via legacy path — treat as active with level 2.
-		rec = registry.AgentRecord{AgentID: req.AgentID, AutonomyLevel: 2, Status: registry.StatusActive}
+	// request_id de-duplication (AUTH-004), per agent: an identical retry
+	// gets the original response; the ID is released if no decision is
+	// recorded.
+	requestKey := idempotency.ScopedID(req.AgentID, req.RequestID)
+	recorded := false
+	if req.RequestID != "" {
+		fingerprint, err := idempotency.Fingerprint(req)
+		if err != nil {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+			return
+		}
+		prev, replay, err := s.requestIDs.Begin(requestKey, fingerprint, time.Now())
+		if err != nil {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrAUTH004, fmt.Sprintf("request_id %q: %v", req.RequestID, err))
+			return
+		}
+		if replay {
+			s.replayAuthorization(w, r, req, prev)
+			return
+		}
+		defer func() {
+			if !recorded {
+				s.requestIDs.Abort(requestKey)
+			}
+		}()
 	}
-	if rec.Status == registry.StatusSuspended || rec.Status == registry.StatusRevoked {
-		// ACP-LEDGER-1.0: DENIED must be recorded (§5.2).
-		s.emitLedgerEvent(ledger.EventAuthorization, map[string]interface{}{
-			"request_id": req.RequestID,
-			"agent_id":   req.AgentID,
-			"capability": req.Capability,
-			"resource":   req.Resource,
-			"decision":   "DENIED",
-			"risk_score": 100,
-		})
-		acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
-			"decision":    "DENIED",
-			"risk_score":  100,
-			"reason_code": acpapi.ErrAUTH005,
-			"message":     fmt.Sprintf("agent %s is %s", req.AgentID, rec.Status),
-		}, s.institutionPrivKey)
+
+	if err := s.bindTarget(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusForbidden, "EXEC-009", err.Error())
 		return
 	}
 
-	// Step 3: autonomy_level == 0 → DENIED (AUTH-008).
-	if rec.AutonomyLevel == 0 {
-		// ACP-LEDGER-1.0: DENIED must be recorded (§5.2).
-		s.emitLedgerEvent(ledger.EventAuthorization, map[string]interface{}{
-			"request_id": req.RequestID,
-			"agent_id":   req.AgentID,
-			"capability": req.Capability,
-			"resource":   req.Resource,
-			"decision":   "DENIED",
-			"risk_score": 100,
+	p := s.prepareAuthorization(req)
+	p.chain = chain
+	out := s.commitAuthorization(p, randUUID(), "")
+	if out.err != nil {
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003,
+			"audit ledger unavailable: decision not recorded, nothing issued")
+		return
+	}
+	kid, priv := s.keys.Active()
+	body := acpapi.SignedSuccessBody(r, out.data, kid, priv)
+	if req.RequestID != "" {
+		s.requestIDs.Complete(requestKey, idempotency.Record{
+			Status:         http.StatusOK,
+			Body:           body,
+			Decision:       out.decision,
+			LedgerEventID:  out.event.EventID,
+			LedgerSequence: out.event.Sequence,
 		})
-		acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
-			"decision":    "DENIED",
-			"risk_score":  100,
-			"reason_code": acpapi.ErrAUTH008,
-			"message":     "agent has no execution autonomy (level 0)",
-		}, s.institutionPrivKey)
+		recorded = true
+	}
+	acpapi.WriteRaw(w, http.StatusOK, body)
+}
+
+// replayAuthorization answers an identical retry of a completed /authorize
+// request with the original signed response: no new decision, ET or anomaly
+// state. The replay is recorded in the ledger with a reference to the
+// original AUTHORIZATION event, and fails closed like the decision itself.
+func (s *server) replayAuthorization(w http.ResponseWriter, r *http.Request, req authzRequest, prev idempotency.Record) {
+	if _, err := s.auditLedger.Append(ledger.EventAuthorizationReplayed, map[string]interface{}{
+		"request_id":        req.RequestID,
+		"agent_id":          req.AgentID,
+		"decision":          prev.Decision,
+		"original_event_id": prev.LedgerEventID,
+		"original_sequence": prev.LedgerSequence,
+	}); err != nil {
+		log.Printf("[ACP/LEDGER] replay of %s (agent=%s) not recorded, withheld: %v", req.RequestID, req.AgentID, err)
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003,
+			"audit ledger unavailable: replay not recorded")
 		return
 	}
+	w.Header().Set("X-ACP-Idempotent-Replay", "true")
+	acpapi.WriteRaw(w, prev.Status, prev.Body)
+}
+
+// authzRequest is one authorization request — the /authorize body or one
+// item of a batch.
+type authzRequest struct {
+	RequestID        string                 `json:"request_id"`
+	AgentID          string                 `json:"agent_id"`
+	Capability       string                 `json:"capability"`
+	Resource         string                 `json:"resource"`
+	ActionParameters map[string]interface{} `json:"action_parameters"`
+	Context          map[string]interface{} `json:"context"`
+	TargetSystem     string                 `json:"target_system"` // intended ET consumer (optional)
+	DelegationChain  []json.RawMessage      `json:"delegation_chain"` // ACP-CT-1.0 tokens, root first (optional)
+	Sig              string                 `json:"sig"`
+}
+
+// authzPrep holds the state-independent part of an evaluation (steps 2 and 4
+// without F_anom). It reads but never mutates server state, so batch items
+// can be prepared concurrently.
+type authzPrep struct {
+	req        authzRequest
+	rec        registry.AgentRecord
+	riskReq    risk.Request
+	currency   string // action_parameters.currency, for budgets
+	hasAmount  bool   // action_parameters carries an amount, numeric or not
+	assessment risk.Assessment
+	chain      delegation.Chain // verified delegation_chain, for provenance
+	anomaly    bool             // add F_anom (ACP-RISK-2.0 §3.4); batch items only
+}
+
+// authzOutcome is the committed decision and the /authorize response body.
+// err is set when the decision could not be recorded in the audit ledger;
+// nothing of it was released and the other fields are empty.
+type authzOutcome struct {
+	event        ledger.Event // the AUTHORIZATION event recording the decision
+	decision     string
+	score        int
+	reasonCode   string
+	et           *execution.Token
+	escalationID string
+	data         map[string]interface{}
+	err          error
+}
+
+// prepareAuthorization runs the registry lookup and ACP-RISK-1.0 base assessment.
+func (s *server) prepareAuthorization(req authzRequest) authzPrep {
+	// Step 2: Check agent status.
+	rec, err := s.registry.GetRecord(req.AgentID)
+	if err != nil {
+		// Agent may be registered via legacy path — treat as active with level 2.
+		rec = registry.AgentRecord{AgentID: req.AgentID, AutonomyLevel: 2, Status: registry.StatusActive}
+	}
+	// A score-driven suspension or ban applies even to legacy agents.
+	if status, err := s.lifecycle.Status(req.AgentID); err == nil {
+		rec.Status = status
+	}
 
 	// Step 4: ACP-RISK-1.0 assessment.
 	riskReq := risk.Request{
@@ -473,156 +1059,881 @@ func (s *server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
 		Resource:   req.Resource,
 	}
 	// Extract amount from action_parameters if present.
-	if amt, ok := req.ActionParameters["amount"]; ok {
+	amt, hasAmount := req.ActionParameters["amount"]
+	if hasAmount {
 		if amtFloat, ok := toFloat64(amt); ok {
 			riskReq.Amount = &amtFloat
 		}
 	}
-	assessment := risk.Assess(riskReq)
+	currency, _ := req.ActionParameters["currency"].(string)
+	return authzPrep{req: req, rec: rec, riskReq: riskReq, currency: currency, hasAmount: hasAmount, assessment: risk.Assess(riskReq)}
+}
+
+// commitAuthorization decides a prepared request and applies every state
+// change: anomaly state, reputation, ET issuance and ledger events.
+//
+// The audit is fail-closed: the events recording the decision are appended
+// in one atomic ledger write (see recordDecision), and the ET, provenance,
+// escalation and budget reservation are released only once it commits. If
+// it fails, an ET already registered is withdrawn and the outcome carries
+// the error instead of a decision. Anomaly state keeps the attempt.
+//
+// Anomaly state is updated under s.anomalyMu right after the request is
+// scored, so requests committed in sequence observe each other in that
+// order. Batch items see the same state single requests in item order would
+// see, but only batch items (p.anomaly) add F_anom to their score, so an item
+// can score higher than the same request sent alone to /authorize.
+//
+// escalationID is used if the decision is ESCALATED; batchID (optional) is
+// recorded in the ledger for correlation (ACP-BULK-1.0 §8).
+func (s *server) commitAuthorization(p authzPrep, escalationID, batchID string) authzOutcome {
+	req, rec, assessment := p.req, p.rec, p.assessment
+	policy := s.activePolicyRef()
+	authzPayload := func(decision string, score int, extra map[string]interface{}) map[string]interface{} {
+		m := map[string]interface{}{
+			"request_id":          req.RequestID,
+			"agent_id":            req.AgentID,
+			"capability":          req.Capability,
+			"resource":            req.Resource,
+			"decision":            decision,
+			"risk_score":          score,
+			"policy_snapshot_ref": policy.snapshotID,
+			"policy_version":      policy.version,
+		}
+		for k, v := range extra {
+			m[k] = v
+		}
+		if batchID != "" {
+			m["batch_id"] = batchID
+		}
+		return m
+	}
+
+	if rec.Status == registry.StatusSuspended || rec.Status == registry.StatusRevoked {
+		s.recordAnomaly(req, risk.DENIED)
+		// ACP-LEDGER-1.0: DENIED must be recorded (§5.2).
+		ev, err := s.recordDecision(req, ledger.Entry{EventType: ledger.EventAuthorization, Payload: authzPayload("DENIED", 100, nil)})
+		if err != nil {
+			return authzOutcome{err: err}
+		}
+		return authzOutcome{
+			decision: "DENIED", score: 100, reasonCode: acpapi.ErrAUTH005, event: ev,
+			data: map[string]interface{}{
+				"decision":    "DENIED",
+				"risk_score":  100,
+				"reason_code": acpapi.ErrAUTH005,
+				"message":     fmt.Sprintf("agent %s is %s", req.AgentID, rec.Status),
+			},
+		}
+	}
+
+	// Step 3: autonomy_level == 0 → DENIED (AUTH-008).
+	if rec.AutonomyLevel == 0 {
+		s.recordAnomaly(req, risk.DENIED)
+		// ACP-LEDGER-1.0: DENIED must be recorded (§5.2).
+		ev, err := s.recordDecision(req, ledger.Entry{EventType: ledger.EventAuthorization, Payload: authzPayload("DENIED", 100, nil)})
+		if err != nil {
+			return authzOutcome{err: err}
+		}
+		return authzOutcome{
+			decision: "DENIED", score: 100, reasonCode: acpapi.ErrAUTH008, event: ev,
+			data: map[string]interface{}{
+				"decision":    "DENIED",
+				"risk_score":  100,
+				"reason_code": acpapi.ErrAUTH008,
+				"message":     "agent has no execution autonomy (level 0)",
+			},
+		}
+	}
+
+	// Step 3b: cumulative budgets of the active policy snapshot. A request
+	// that would exceed one, or whose currency none of a scope's budgets
+	// counts, is DENIED without scoring (RISK-010); one whose amount is not a
+	// positive number is DENIED as malformed (SYS-004), so it can neither
+	// slip past the budgets nor lower their usage. A request that brings a
+	// budget near its limit sets F_hist AmountNearLimit. budgetMu is held
+	// until the amount is reserved so concurrent requests cannot both pass
+	// the same remaining budget.
+	var spend *budget.Spend
+	var budgets []budget.Budget
+	var budgetCheck budget.Check
+	if snap, err := s.psnStore.GetActive(); err == nil && len(snap.Budgets) > 0 && p.hasAmount {
+		s.budgetMu.Lock()
+		defer s.budgetMu.Unlock()
+		now := time.Now().Unix()
+		budgets = snap.Budgets
+		spend = &budget.Spend{
+			AgentID:   req.AgentID,
+			Delegator: s.rootDelegator(req.AgentID, now),
+			Resource:  req.Resource,
+			Amount:    math.NaN(), // a non-numeric amount is invalid
+			Currency:  p.currency,
+			At:        now,
+		}
+		if p.riskReq.Amount != nil {
+			spend.Amount = *p.riskReq.Amount
+		}
+		var budgetErr error
+		budgetCheck, budgetErr = s.budgets.Check(budgets, *spend)
+		if budgetErr != nil {
+			reason, message := reasonBudgetExceeded, budgetErr.Error()
+			detail := map[string]interface{}{"reason_code": reason}
+			if e := budgetCheck.Exceeded; e != nil {
+				message = fmt.Sprintf("budget %s for %s %s would be exceeded", e.BudgetID, e.Scope, e.Subject)
+				detail["budget"] = e
+			}
+			if errors.Is(budgetErr, budget.ErrInvalidSpend) {
+				reason, message = acpapi.ErrSYS004, "action_parameters.amount must be a positive number"
+				detail["reason_code"] = reason
+			}
+			s.recordAnomaly(req, risk.DENIED)
+			ev, err := s.recordDecision(req, ledger.Entry{EventType: ledger.EventAuthorization, Payload: authzPayload("DENIED", 100, detail)})
+			if err != nil {
+				return authzOutcome{err: err}
+			}
+			data := map[string]interface{}{
+				"decision":      "DENIED",
+				"risk_score":    100,
+				"reason_code":   reason,
+				"message":       message,
+				"retry_allowed": false,
+			}
+			if e := budgetCheck.Exceeded; e != nil {
+				data["budget"] = e
+			}
+			return authzOutcome{decision: "DENIED", score: 100, reasonCode: reason, event: ev, data: data}
+		}
+	}
 
+	// Step 4b: F_hist derived from anomaly state plus pending escalations,
+	// F_anom from the same state (batch items) without the rules F_hist
+	// already scored, F_rep from the agent's reputation record; then record
+	// this request. Anomaly state records every request, so batch items
+	// observe single requests too. If the state cannot be read the request
+	// is DENIED unscored (RISK-008, fail-closed).
 	// Step 5: Apply thresholds by autonomy_level.
 	//   Level 1: approve < 25, escalate 25–89, deny ≥ 90
 	//   Level 2: approve < 60, escalate 60–89, deny ≥ 90
 	//   Level 3+: approve < 90, deny ≥ 90
-	decision := decisionByLevel(rec.AutonomyLevel, assessment.Score)
+	s.anomalyMu.Lock()
+	repIn := s.reputationInput(req.AgentID)
+	history, err := risk.DeriveHistory(risk.HistoryInput{AgentID: req.AgentID, Reputation: repIn}, s.anomaly)
+	var anomalyDetail risk.AnomalyDetail
+	if err == nil && p.anomaly {
+		_, anomalyDetail, err = risk.ScoreAnomaly(risk.EvalRequest{
+			AgentID:    req.AgentID,
+			Capability: req.Capability,
+			Resource:   req.Resource,
+			Policy:     s.riskPolicy,
+		}, s.anomaly)
+		anomalyDetail = risk.DedupAnomaly(anomalyDetail, history)
+	}
+	if err != nil {
+		s.anomalyMu.Unlock()
+		log.Printf("[ACP/RISK] request %s (agent=%s) denied: %v", req.RequestID, req.AgentID, err)
+		ev, err := s.recordDecision(req, ledger.Entry{EventType: ledger.EventAuthorization, Payload: authzPayload("DENIED", 100, map[string]interface{}{
+			"reason_code": reasonRiskUnavailable,
+		})})
+		if err != nil {
+			return authzOutcome{err: err}
+		}
+		return authzOutcome{
+			decision: "DENIED", score: 100, reasonCode: reasonRiskUnavailable, event: ev,
+			data: map[string]interface{}{
+				"decision":      "DENIED",
+				"risk_score":    100,
+				"reason_code":   reasonRiskUnavailable,
+				"message":       "risk history unavailable",
+				"retry_allowed": true,
+			},
+		}
+	}
+	fAnom := risk.SumItems(risk.AnomalyItems(anomalyDetail))
+	history.AmountNearLimit = budgetCheck.NearLimit
+	histItems, repItems := risk.HistoryItems(history), risk.ReputationItems(repIn)
+	fHist, fRep := risk.SumItems(histItems), risk.SumItems(repItems)
+	factors := risk.AssessItems(p.riskReq)
+	factors = append(factors, histItems...)
+	factors = append(factors, risk.AnomalyItems(anomalyDetail)...)
+	factors = append(factors, repItems...)
+	score := assessment.Score + fAnom + fHist + fRep
+	if score > 100 {
+		score = 100
+	}
+	if score < 0 {
+		score = 0
+	}
+	decision := decisionByLevel(rec.AutonomyLevel, score)
+	s.recordAnomaly(req, risk.Decision(decision))
+	s.anomalyMu.Unlock()
 
 	// Update reputation + last active.
 	s.registry.TouchLastActive(req.AgentID)
 
-	// ACP-LEDGER-1.0: emit RISK_EVALUATION event (§5.3).
+	// ACP-LEDGER-1.0: RISK_EVALUATION event (§5.3), recorded with the decision.
 	evalID := randUUID()
-	s.emitLedgerEvent(ledger.EventRiskEvaluation, map[string]interface{}{
-		"eval_id":    evalID,
-		"request_id": req.RequestID,
-		"agent_id":   req.AgentID,
-		"capability": req.Capability,
-		"rs_final":   assessment.Score,
-		"decision":   decision,
-	})
-
+	entries := []ledger.Entry{{EventType: ledger.EventRiskEvaluation, Payload: map[string]interface{}{
+		"eval_id":        evalID,
+		"request_id":     req.RequestID,
+		"agent_id":       req.AgentID,
+		"capability":     req.Capability,
+		"rs_final":       score,
+		"f_anom":         fAnom,
+		"f_hist":         fHist,
+		"f_rep":          fRep,
+		"history":        history,
+		"anomaly_detail": anomalyDetail,
+		"risk_factors":   factors,
+		"decision":       decision,
+
+		"policy_snapshot_ref": policy.snapshotID,
+		"policy_hash":         policy.hash,
+	}}}
+
+	out := authzOutcome{decision: decision, score: score}
 	switch decision {
 	case "APPROVED":
-		// ACP-EXEC-1.0 §7: Issue Execution Token from APPROVED authorization.
+		// ACP-EXEC-1.0 §7: Issue Execution Token from APPROVED authorization,
+		// bound to the consumer chosen by bindTarget.
+		signKID, signPriv := s.keys.Active()
 		etReq := execution.IssueRequest{
 			AgentID:          req.AgentID,
 			AuthorizationID:  req.RequestID,
 			Capability:       req.Capability,
 			Resource:         req.Resource,
 			ActionParameters: req.ActionParameters,
+			TargetSystem:     req.TargetSystem,
+			KeyID:            signKID,
 		}
-		et, etErr := execution.Issue(etReq, s.institutionPrivKey)
+		et, etErr := execution.Issue(etReq, signPriv)
 		var etData interface{}
 		etIssued := false
 		if etErr == nil {
 			if regErr := s.etRegistry.Register(et); regErr == nil {
 				etData = et
 				etIssued = true
-				log.Printf("[ACP/EXEC] issued ET %s for agent=%s cap=%s", et.ETID, req.AgentID, req.Capability)
+				out.et = &et
 			} else {
 				log.Printf("[ACP/EXEC] register ET failed: %v", regErr)
 			}
 		} else {
 			log.Printf("[ACP/EXEC] issue ET failed: %v", etErr)
 		}
+		var ap *provenance.AuthorityProvenance
+		var provenanceID string
+		if etIssued {
+			if built, err := s.buildProvenance(et, p.chain); err == nil {
+				ap, provenanceID = &built, built.ProvenanceID
+				entries = append(entries, ledger.Entry{EventType: ledger.EventProvenance, Payload: built})
+			} else {
+				log.Printf("[ACP/PROV] provenance for ET %s failed: %v", et.ETID, err)
+			}
+		}
 
-		// ACP-LEDGER-1.0: emit AUTHORIZATION (§5.2) + EXECUTION_TOKEN_ISSUED (§5.6).
-		s.emitLedgerEvent(ledger.EventAuthorization, map[string]interface{}{
-			"request_id":   req.RequestID,
-			"agent_id":     req.AgentID,
-			"capability":   req.Capability,
-			"resource":     req.Resource,
-			"decision":     "APPROVED",
+		// ACP-LEDGER-1.0: AUTHORIZATION (§5.2) + EXECUTION_TOKEN_ISSUED (§5.6).
+		entries = append(entries, ledger.Entry{EventType: ledger.EventAuthorization, Payload: authzPayload("APPROVED", score, map[string]interface{}{
 			"risk_eval_id": evalID,
-			"risk_score":   assessment.Score,
-		})
+		})})
 		if etIssued {
-			s.emitLedgerEvent(ledger.EventExecutionTokenIssued, map[string]interface{}{
+			etPayload := map[string]interface{}{
 				"et_id":            et.ETID,
 				"authorization_id": req.RequestID,
 				"agent_id":         req.AgentID,
 				"capability":       req.Capability,
 				"resource":         req.Resource,
 				"expires_at":       et.ExpiresAt,
-			})
+			}
+			if et.TargetSystem != "" {
+				etPayload["target_system"] = et.TargetSystem
+			}
+			if provenanceID != "" {
+				etPayload["provenance_id"] = provenanceID
+			}
+			entries = append(entries, ledger.Entry{EventType: ledger.EventExecutionTokenIssued, Payload: etPayload})
+		}
+		ev, err := s.recordDecision(req, entries...)
+		if err != nil {
+			if etIssued {
+				s.etRegistry.Withdraw(et.ETID)
+			}
+			return authzOutcome{err: err}
+		}
+		out.event = ev
+
+		// Committed: release the ET, its provenance and budget reservation.
+		if etIssued {
+			log.Printf("[ACP/EXEC] issued ET %s for agent=%s cap=%s", et.ETID, req.AgentID, req.Capability)
+		}
+		if ap != nil {
+			s.storeProvenance(*ap)
+		}
+		if spend != nil {
+			spend.ID = escalationID
+			if etIssued {
+				spend.ID = et.ETID
+			}
+			s.reserveSpend(budgets, *spend)
 		}
 
-		acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+		out.data = map[string]interface{}{
 			"decision":        "APPROVED",
-			"risk_score":      assessment.Score,
+			"risk_score":      score,
 			"risk_level":      assessment.Level.String(),
+			"risk_factors":    factors,
 			"execution_token": etData,
-		}, s.institutionPrivKey)
+		}
+		if provenanceID != "" {
+			out.data["provenance_id"] = provenanceID
+		}
 
 	case "DENIED":
-		// ACP-LEDGER-1.0: emit AUTHORIZATION — DENIED must be recorded (§5.2).
-		s.emitLedgerEvent(ledger.EventAuthorization, map[string]interface{}{
-			"request_id":   req.RequestID,
-			"agent_id":     req.AgentID,
-			"capability":   req.Capability,
-			"resource":     req.Resource,
-			"decision":     "DENIED",
+		// ACP-LEDGER-1.0: AUTHORIZATION — DENIED must be recorded (§5.2).
+		entries = append(entries, ledger.Entry{EventType: ledger.EventAuthorization, Payload: authzPayload("DENIED", score, map[string]interface{}{
 			"risk_eval_id": evalID,
-			"risk_score":   assessment.Score,
-		})
+		})})
+		ev, err := s.recordDecision(req, entries...)
+		if err != nil {
+			return authzOutcome{err: err}
+		}
+		out.event = ev
 
-		acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+		out.reasonCode = "RISK-005"
+		out.data = map[string]interface{}{
 			"decision":      "DENIED",
-			"risk_score":    assessment.Score,
+			"risk_score":    score,
 			"risk_level":    assessment.Level.String(),
+			"risk_factors":  factors,
 			"reason_code":   "RISK-005",
 			"retry_allowed": false,
-		}, s.institutionPrivKey)
+		}
 
 	case "ESCALATED":
-		escalationID := acpapi.GetRequestID(r)
 		expiresAt := time.Now().Add(1 * time.Hour).Unix()
 
-		// ACP-LEDGER-1.0: emit AUTHORIZATION (§5.2) + ESCALATION_CREATED (§5.10).
-		s.emitLedgerEvent(ledger.EventAuthorization, map[string]interface{}{
-			"request_id":   req.RequestID,
-			"agent_id":     req.AgentID,
-			"capability":   req.Capability,
-			"resource":     req.Resource,
-			"decision":     "ESCALATED",
-			"risk_eval_id": evalID,
-			"risk_score":   assessment.Score,
-		})
-		s.emitLedgerEvent(ledger.EventEscalationCreated, map[string]interface{}{
-			"escalation_id": escalationID,
-			"request_id":    req.RequestID,
-			"agent_id":      req.AgentID,
-			"capability":    req.Capability,
-			"risk_score":    assessment.Score,
-			"escalated_to":  "review_queue",
-			"expires_at":    expiresAt,
-		})
+		// ACP-LEDGER-1.0: AUTHORIZATION (§5.2) + ESCALATION_CREATED (§5.10).
+		entries = append(entries,
+			ledger.Entry{EventType: ledger.EventAuthorization, Payload: authzPayload("ESCALATED", score, map[string]interface{}{
+				"risk_eval_id": evalID,
+			})},
+			ledger.Entry{EventType: ledger.EventEscalationCreated, Payload: map[string]interface{}{
+				"escalation_id": escalationID,
+				"request_id":    req.RequestID,
+				"agent_id":      req.AgentID,
+				"capability":    req.Capability,
+				"risk_score":    score,
+				"escalated_to":  "review_queue",
+				"expires_at":    expiresAt,
+			}})
+		ev, err := s.recordDecision(req, entries...)
+		if err != nil {
+			return authzOutcome{err: err}
+		}
+		out.event = ev
+
+		s.anomaly.AddEscalation(req.AgentID, escalationID, time.Unix(expiresAt, 0))
+		if spend != nil {
+			spend.ID = escalationID
+			s.reserveSpend(budgets, *spend)
+		}
 
-		acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+		out.escalationID = escalationID
+		out.data = map[string]interface{}{
 			"decision":      "ESCALATED",
-			"risk_score":    assessment.Score,
+			"risk_score":    score,
 			"risk_level":    assessment.Level.String(),
+			"risk_factors":  factors,
 			"escalation_id": escalationID,
 			"escalated_to":  "review_queue",
 			"expires_at":    expiresAt,
-		}, s.institutionPrivKey)
+		}
 	}
 
 	log.Printf("[ACP/AUTH] %s agent=%s cap=%s score=%d decision=%s",
-		req.RequestID, req.AgentID, req.Capability, assessment.Score, decision)
+		req.RequestID, req.AgentID, req.Capability, score, decision)
+	return out
+}
+
+// reputationInput returns the agent's ACP-REP score and state for F_rep and
+// NoHistory. An agent without a local score falls back to its imported prior.
+func (s *server) reputationInput(agentID string) risk.ReputationIn {
+	rec, err := s.repEngine.GetRecord(agentID)
+	if err != nil {
+		return risk.ReputationIn{}
+	}
+	in := risk.ReputationIn{Score: rec.Score, State: string(rec.State)}
+	if in.Score == nil {
+		in.Score = rec.Prior
+	}
+	return in
+}
+
+// recordAnomaly adds a decided request to the anomaly state (F_anom inputs).
+func (s *server) recordAnomaly(req authzRequest, decision risk.Decision) {
+	s.anomaly.Record(req.AgentID, req.Capability, req.Resource, decision, time.Now())
+}
+
+// restoreRiskHistory rebuilds the anomaly state behind F_hist and F_anom from
+// ledger events of previous runs, so that risk history survives a restart:
+// the decisions recorded in the anomaly state (scored or over budget) within
+// risk.HistoryWindow, and the escalations neither resolved nor expired.
+// Returns the number of decisions restored.
+func (s *server) restoreRiskHistory(events []ledger.Event, now time.Time) int {
+	cutoff := now.Add(-risk.HistoryWindow).Unix()
+	n := 0
+	for _, ev := range events {
+		switch ev.EventType {
+		case ledger.EventAuthorization, ledger.EventEscalationCreated, ledger.EventEscalationResolved:
+		default:
+			continue
+		}
+		m, ok := s.auditLedger.Reveal(ev).Payload.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		str := func(k string) string { v, _ := m[k].(string); return v }
+		switch ev.EventType {
+		case ledger.EventAuthorization:
+			if ev.Timestamp < cutoff || (m["risk_eval_id"] == nil && str("reason_code") != reasonBudgetExceeded) {
+				continue
+			}
+			s.anomaly.Record(str("agent_id"), str("capability"), str("resource"),
+				risk.Decision(str("decision")), time.Unix(ev.Timestamp, 0))
+			n++
+		case ledger.EventEscalationCreated:
+			if exp, _ := toFloat64(m["expires_at"]); int64(exp) > now.Unix() {
+				s.anomaly.AddEscalation(str("agent_id"), str("escalation_id"), time.Unix(int64(exp), 0))
+			}
+		case ledger.EventEscalationResolved:
+			s.anomaly.ResolveEscalation(str("escalation_id"))
+		}
+	}
+	return n
+}
+
+// ─── Spend Budgets (ACP-PSN-1.0 snapshot budgets) ────────────────────────────
+
+// handleBudgetsSet replaces the budgets of the active policy snapshot by
+// transitioning to a new snapshot with the same thresholds (ACP-PSN-1.0 §7).
+// POST /acp/v1/policy/budgets
+//
+// Body: {budgets[{budget_id, scope, subject?, currency?, limit, window_seconds, near_limit?}], created_by}
+// Response 200: data.{snapshot_id, superseded_snapshot_id, budgets}
+// Response 400: PSN-008
+func (s *server) handleBudgetsSet(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Budgets   []budget.Budget `json:"budgets"`
+		CreatedBy string          `json:"created_by"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = s.institutionID
+	}
+	active, err := s.psnStore.GetActive()
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS002, err.Error())
+		return
+	}
+
+	_, signPriv := s.keys.Active()
+	res, err := psn.Transition(s.psnStore, psn.TransitionRequest{
+		PolicyVersion:       active.PolicyVersion,
+		Thresholds:          active.Thresholds,
+		CapabilityBaselines: active.CapabilityBaselines,
+		ContextFactors:      active.ContextFactors,
+		ResourceFactors:     active.ResourceFactors,
+		CustomFactors:       active.CustomFactors,
+		Budgets:             req.Budgets,
+		CreatedBy:           req.CreatedBy,
+	}, signPriv)
+	if err != nil {
+		if errors.Is(err, psn.ErrInvalidBudgets) {
+			acpapi.WriteError(w, r, http.StatusBadRequest, "PSN-008", err.Error())
+			return
+		}
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS002, err.Error())
+		return
+	}
+	snap := res.NewSnapshot
+	s.emitLedgerEvent(ledger.EventPolicySnapshotCreated, map[string]interface{}{
+		"snapshot_id":          snap.SnapshotID,
+		"policy_version":       snap.PolicyVersion,
+		"effective_from":       snap.EffectiveFrom,
+		"previous_snapshot_id": res.SupersededSnapshot.SnapshotID,
+		"created_by":           snap.CreatedBy,
+		"budgets":              snap.Budgets,
+	})
+
+	log.Printf("[ACP/BUDGET] snapshot %s active with %d budgets", snap.SnapshotID, len(snap.Budgets))
+	budgets := snap.Budgets
+	if budgets == nil {
+		budgets = []budget.Budget{}
+	}
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"snapshot_id":            snap.SnapshotID,
+		"superseded_snapshot_id": res.SupersededSnapshot.SnapshotID,
+		"budgets":                budgets,
+	})
+}
+
+// handleBudgetUsage reports the budgets of the active snapshot that apply to
+// a subject, with the amounts approved and consumed in their windows.
+// GET /acp/v1/budgets/usage?scope=agent|delegator|resource&subject=<id>
+//
+// Response 200: data.{scope, subject, usage[{budget_id, limit, approved, consumed, remaining}]}
+func (s *server) handleBudgetUsage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	scope := budget.Scope(q.Get("scope"))
+	if scope == "" {
+		scope = budget.ScopeAgent
+	}
+	subject := q.Get("subject")
+	if subject == "" {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "subject query parameter is required")
+		return
+	}
+	var budgets []budget.Budget
+	if snap, err := s.psnStore.GetActive(); err == nil {
+		budgets = snap.Budgets
+	}
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"scope":   scope,
+		"subject": subject,
+		"usage":   s.budgets.Usage(budgets, scope, subject, time.Now().Unix()),
+	})
+}
+
+// ─── ACP-BULK-1.0: Batch Operations ───────────────────────────────────────────
+
+// handleAuthorizeBatch evaluates up to bulk.MaxBatchItems authorization
+// requests in one call (ACP-BULK-1.0 §2).
+// POST /acp/v1/authorize/batch (alias: POST /acp/v1/bulk/authorize)
+//
+// Body: {batch_id, items[{request_id, agent_id, action_type, resource, context}]}
+//   action_type is the capability; context.amount feeds the amount factor.
+// Response 200: {batch_id, processed, partial_failure, results[]}
+// Response 207: same body, when at least one item was DENIED (BULK-003)
+// Response 400: BULK-001 (>100 items), BULK-005 (empty), SYS-004
+// Response 429: BULK-002 with Retry-After
+//
+// Each item takes a token from its agent's /authorize quota (ACP_RATE_LIMITS);
+// an item over the quota is reported DENIED with BULK-002.
+// Items are evaluated with the same ledger contract as /authorize (§8). An
+// item whose decision cannot be recorded is reported DENIED with SYS-003.
+// The state-independent part of every item is computed concurrently; the
+// decisions are then committed strictly in item order, so anomaly state
+// evolves as if the items had been submitted one by one. Unlike /authorize,
+// items add F_anom (ACP-RISK-2.0 §3.4) to their score.
+func (s *server) handleAuthorizeBatch(w http.ResponseWriter, r *http.Request) {
+	if s.bulkLimited(w, r) {
+		return
+	}
+
+	var req bulk.BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	if err := bulk.ValidateBatchRequest(req); err != nil {
+		code := "BULK-005"
+		if errors.Is(err, bulk.ErrBatchTooLarge) {
+			code = "BULK-001"
+		}
+		acpapi.WriteError(w, r, http.StatusBadRequest, code,
+			fmt.Sprintf("%v (batch_id=%s, items=%d)", err, req.BatchID, len(req.Items)))
+		return
+	}
+	seen := make(map[string]bool, len(req.Items))
+	for _, it := range req.Items {
+		if it.RequestID == "" || seen[it.RequestID] {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004,
+				fmt.Sprintf("request_id %q missing or duplicated within batch", it.RequestID))
+			return
+		}
+		seen[it.RequestID] = true
+	}
+	if req.BatchID == "" {
+		req.BatchID = randUUID()
+	}
+
+	// Phase 1: concurrent, state-independent preparation.
+	preps := make([]*authzPrep, len(req.Items))
+	var wg sync.WaitGroup
+	for i, it := range req.Items {
+		if it.AgentID == "" || it.ActionType == "" || it.Resource == "" {
+			continue // item-level failure, reported below
+		}
+		wg.Add(1)
+		go func(i int, it bulk.BatchItem) {
+			defer wg.Done()
+			p := s.prepareAuthorization(authzRequest{
+				RequestID:        it.RequestID,
+				AgentID:          it.AgentID,
+				Capability:       it.ActionType,
+				Resource:         it.Resource,
+				ActionParameters: it.Context,
+				Context:          it.Context,
+			})
+			p.anomaly = true
+			preps[i] = &p
+		}(i, it)
+	}
+	wg.Wait()
+
+	// Phase 2: commit decisions in item order.
+	results := make([]bulk.ItemResult, len(req.Items))
+	for i, it := range req.Items {
+		if preps[i] == nil {
+			results[i] = bulk.ItemResult{
+				RequestID:  it.RequestID,
+				Decision:   "DENIED",
+				ReasonCode: acpapi.ErrSYS004,
+			}
+			continue
+		}
+		// Items only claim their agent_id: they share the unauthenticated
+		// /authorize quota of that agent_id from the caller's address.
+		if _, err := s.takeAgentToken(it.AgentID, "authorize", clientAddr(r)); err != nil {
+			results[i] = bulk.ItemResult{
+				RequestID:  it.RequestID,
+				Decision:   "DENIED",
+				ReasonCode: "BULK-002",
+			}
+			continue
+		}
+		if err := s.bindTarget(&preps[i].req); err != nil {
+			results[i] = bulk.ItemResult{
+				RequestID:  it.RequestID,
+				Decision:   "DENIED",
+				ReasonCode: "EXEC-009",
+			}
+			continue
+		}
+		out := s.commitAuthorization(*preps[i], randUUID(), req.BatchID)
+		if out.err != nil {
+			// Not recorded, so not decided: nothing was issued for this item.
+			results[i] = bulk.ItemResult{
+				RequestID:  it.RequestID,
+				Decision:   "DENIED",
+				ReasonCode: acpapi.ErrSYS003,
+			}
+			continue
+		}
+		score := float64(out.score)
+		res := bulk.ItemResult{
+			RequestID:    it.RequestID,
+			Decision:     out.decision,
+			RiskScore:    &score,
+			ReasonCode:   out.reasonCode,
+			EscalationID: out.escalationID,
+		}
+		if out.et != nil {
+			res.ExecutionToken = out.et
+		}
+		results[i] = res
+	}
+
+	resp := bulk.NewBatchResponse(req, results)
+	status := http.StatusOK
+	if resp.PartialFailure {
+		status = http.StatusMultiStatus
+	}
+	log.Printf("[ACP/BULK] batch %s: %d items processed (partial_failure=%v)", resp.BatchID, resp.Processed, resp.PartialFailure)
+	s.writeSuccess(w, r, status, resp)
+}
+
+// handleLiabilityQuery returns LIABILITY_RECORDs for several agents with
+// cursor pagination (ACP-BULK-1.0 §3, §7).
+// POST /acp/v1/liability/query (alias: POST /acp/v1/bulk/liability-query)
+//
+// Body: {query_id, agent_ids[], from_ts, to_ts, limit (default/max 1000), cursor}
+// Response 200: {query_id, total, next_cursor ("" on the last page), records[]}
+// Response 400: BULK-004 (limit > 1000), BULK-006 (bad cursor), SYS-004
+func (s *server) handleLiabilityQuery(w http.ResponseWriter, r *http.Request) {
+	if s.bulkLimited(w, r) {
+		return
+	}
+
+	var req bulk.LiabilityQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	if len(req.AgentIDs) == 0 {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "agent_ids must not be empty")
+		return
+	}
+	if err := bulk.ValidateLiabilityQuery(req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, "BULK-004", err.Error())
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = bulk.MaxLiabilityPageSize
+	}
+	now := time.Now()
+	afterSeq, err := bulk.DecodeCursor(req, now)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, "BULK-006", err.Error())
+		return
+	}
+	if req.QueryID == "" {
+		req.QueryID = randUUID()
+	}
+
+	items, total := s.liaStore.QueryAgents(req.AgentIDs, req.FromTS, req.ToTS, afterSeq, req.Limit)
+	resp := bulk.LiabilityQueryResponse{
+		QueryID: req.QueryID,
+		Total:   total,
+		Records: make([]bulk.LiabilityRecord, 0, len(items)),
+	}
+	for _, it := range items {
+		resp.Records = append(resp.Records, bulk.LiabilityRecord{
+			LiabilityID:       it.LiabilityID,
+			ETID:              it.ETID,
+			AgentID:           it.AgentID,
+			Capability:        it.Capability,
+			Resource:          it.Resource,
+			ExecutionResult:   it.ExecutionResult,
+			ExecutedAt:        it.ExecutedAt,
+			LiabilityAssignee: it.LiabilityAssignee,
+		})
+	}
+	if len(items) == req.Limit {
+		last := items[len(items)-1].LedgerSequence
+		if more, _ := s.liaStore.QueryAgents(req.AgentIDs, req.FromTS, req.ToTS, last, 1); len(more) > 0 {
+			resp.NextCursor = bulk.EncodeCursor(req, last, now)
+		}
+	}
+	s.writeSuccess(w, r, http.StatusOK, resp)
+}
+
+// rateLimited applies the agent's quota on route (ACP_RATE_LIMITS) to a
+// request. When the quota is exhausted it writes 429 BULK-002 with
+// Retry-After and returns true. See takeAgentToken for authenticated.
+func (s *server) rateLimited(w http.ResponseWriter, r *http.Request, agentID, route string, authenticated bool) bool {
+	client := ""
+	if !authenticated {
+		client = clientAddr(r)
+	}
+	retry, err := s.takeAgentToken(agentID, route, client)
+	if err == nil {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(retry)))
+	acpapi.WriteError(w, r, http.StatusTooManyRequests, "BULK-002", err.Error())
+	return true
+}
+
+// takeAgentToken takes a token from the agent's bucket on route and returns
+// ratelimit.ErrRateLimitExceeded when it is empty. client is the caller's
+// address if the request merely claims the agent_id, and empty if it
+// authenticated the agent. Only an authenticated request uses (and can
+// exhaust) the agent's own bucket, and only its rejection counts as a policy
+// violation against the agent's reputation, at most once per
+// ratelimit.PenaltyInterval. A claimed agent_id gets a separate bucket per
+// caller address and is never penalized, so a third party cannot lock an
+// agent out, drain the quota of its legacy clients, or suspend it. If the
+// limiter state is unavailable the request goes through.
+func (s *server) takeAgentToken(agentID, route, client string) (time.Duration, error) {
+	if s.rateLimiter == nil {
+		return 0, nil
+	}
+	// Unregistered agents are treated as legacy, level 2 (prepareAuthorization).
+	level := 2
+	if rec, err := s.registry.GetRecord(agentID); err == nil {
+		level = rec.AutonomyLevel
+	}
+	authenticated := client == ""
+	if !authenticated {
+		route += "/unauthenticated"
+	}
+	key := ratelimit.Key{Institution: s.institutionID, Agent: agentID, Client: client, Route: route}
+	now := time.Now()
+	retry, err := s.rateLimiter.Allow(key, level, now)
+	switch {
+	case err == nil:
+		return 0, nil
+	case !errors.Is(err, ratelimit.ErrRateLimitExceeded):
+		log.Printf("[ACP] rate limiter unavailable, request allowed: %v", err)
+		return 0, nil
+	}
+	if authenticated && s.rateLimiter.Penalize(key, now) {
+		log.Printf("[ACP] agent %s rate limited on %s", agentID, route)
+		s.emitRepEvent(agentID, reputation.EvtPolicyViolation)
+	}
+	return retry, err
+}
+
+// clientAddr returns the IP address of the peer that sent r. Forwarding
+// headers are not trusted: any caller can set them.
+func clientAddr(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// bulkLimited applies the ACP-BULK-1.0 §4 limit of the institution to a bulk
+// request. When it is reached it writes 429 BULK-002 with Retry-After and
+// returns true.
+func (s *server) bulkLimited(w http.ResponseWriter, r *http.Request) bool {
+	if s.rateLimiter == nil {
+		return false
+	}
+	key := ratelimit.Key{Institution: s.institutionID, Route: "bulk"}
+	retry, err := s.rateLimiter.AllowLimit(key, ratelimit.BulkLimit, time.Now())
+	switch {
+	case err == nil:
+		return false
+	case !errors.Is(err, ratelimit.ErrRateLimitExceeded):
+		log.Printf("[ACP/BULK] rate limiter unavailable, request allowed: %v", err)
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(retry)))
+	acpapi.WriteError(w, r, http.StatusTooManyRequests, "BULK-002", err.Error())
+	return true
+}
+
+// retryAfterSeconds rounds a wait duration up to whole seconds (minimum 1)
+// for the Retry-After header.
+func retryAfterSeconds(d time.Duration) int {
+	secs := int((d + time.Second - 1) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
 }
 
 // handleEscalationResolve resolves an escalated authorization.
 // POST /acp/v1/authorize/escalations/{escalation_id}/resolve
-// Capability required: acp:cap:agent.modify with autonomy_level ≥ 3.
+// Capability required: acp:cap:agent.modify, granted by the institution in
+// the bearer token; a registered agent also needs autonomy_level ≥ 3.
 //
-// Body: {resolution: "APPROVED"|"DENIED", resolved_by, sig}
+// Body: {resolution: "APPROVED"|"DENIED", resolver_type?}
+// resolver_type is human (default), agent or system (ACP-LEDGER-1.3 §5.11).
+// The resolution is attributed to the token subject.
+// Response 200: data.{escalation_id, resolution, resolved_by, resolved_at}
+// Response 401/403: AUTH-001, AUTH-006 (see requireAdmin)
+// Response 404: SYS-004 — unknown escalation_id
+// Response 503: SYS-003 — the resolution could not be recorded; nothing changed
 func (s *server) handleEscalationResolve(w http.ResponseWriter, r *http.Request) {
 	escalationID := r.PathValue("escalation_id")
 
+	resolver, ok := s.requireCapability(w, r, resolverCapability)
+	if !ok {
+		return
+	}
+	if rec, err := s.registry.GetRecord(resolver); err == nil && rec.AutonomyLevel < 3 {
+		acpapi.WriteError(w, r, http.StatusForbidden, acpapi.ErrAUTH001,
+			fmt.Sprintf("agent %q has autonomy_level %d; resolving escalations requires 3", resolver, rec.AutonomyLevel))
+		return
+	}
+
 	var req struct {
-		Resolution string `json:"resolution"`
-		ResolvedBy string `json:"resolved_by"`
-		Sig        string `json:"sig"`
+		Resolution   string `json:"resolution"`
+		ResolverType string `json:"resolver_type"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
@@ -634,25 +1945,52 @@ func (s *server) handleEscalationResolve(w http.ResponseWriter, r *http.Request)
 			"resolution must be APPROVED or DENIED")
 		return
 	}
+	switch req.ResolverType {
+	case "":
+		req.ResolverType = "human"
+	case "human", "agent", "system":
+	default:
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004,
+			"resolver_type must be human, agent or system")
+		return
+	}
 
-	resolvedAt := time.Now().Unix()
+	s.ledgerIdx.catchUp(s.auditLedger)
+	held, ok := s.ledgerIdx.escalationRequest(escalationID)
+	if !ok {
+		acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrSYS004, fmt.Sprintf("escalation %q not found", escalationID))
+		return
+	}
 
-	// ACP-LEDGER-1.0: emit ESCALATION_RESOLVED event (§5.11).
-	s.emitLedgerEvent(ledger.EventEscalationResolved, map[string]interface{}{
+	// ACP-LEDGER-1.0: ESCALATION_RESOLVED (§5.11). The resolution takes
+	// effect only once recorded.
+	resolvedAt := time.Now().Unix()
+	if _, err := s.auditLedger.AppendAll(ledger.Entry{EventType: ledger.EventEscalationResolved, Payload: map[string]interface{}{
 		"escalation_id":       escalationID,
-		"original_request_id": escalationID,
+		"agent_id":            held.agentID,
+		"original_request_id": held.requestID,
 		"resolution":          req.Resolution,
-		"resolved_by":         req.ResolvedBy,
+		"resolver_type":       req.ResolverType,
+		"resolved_by":         resolver,
 		"resolved_at":         resolvedAt,
-	})
+	}}); err != nil {
+		log.Printf("[ACP/LEDGER] resolution of escalation %s not recorded: %v", escalationID, err)
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003,
+			"audit ledger unavailable: resolution not recorded")
+		return
+	}
+	s.anomaly.ResolveEscalation(escalationID)
+	if req.Resolution == "DENIED" {
+		s.budgets.Release(escalationID) // no-op for escalations without an amount
+	}
 
-	log.Printf("[ACP/AUTH] escalation %s resolved as %s by %s", escalationID, req.Resolution, req.ResolvedBy)
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	log.Printf("[ACP/AUTH] escalation %s resolved as %s by %s", escalationID, req.Resolution, resolver)
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
 		"escalation_id": escalationID,
 		"resolution":    req.Resolution,
-		"resolved_by":   req.ResolvedBy,
+		"resolved_by":   resolver,
 		"resolved_at":   resolvedAt,
-	}, s.institutionPrivKey)
+	})
 }
 
 // ─── ACP-API-1.0 §6: Token Issuance (stub) ───────────────────────────────────
@@ -718,11 +2056,12 @@ func (s *server) handleTokensIssue(w http.ResponseWriter, r *http.Request) {
 		ctPayload["action_parameters"] = req.ActionParameters
 	}
 
-	// Institution signs the CT (ACP-CT-1.0 §4.2).
+	// Institution signs the CT (ACP-CT-1.0 §4.2) with the active keyring key.
 	var ctSig string
-	if s.institutionPrivKey != nil {
+	if signKID, signPriv := s.keys.Active(); signPriv != nil {
+		ctPayload["kid"] = signKID
 		payloadBytes, _ := json.Marshal(ctPayload)
-		sigBytes := ed25519.Sign(s.institutionPrivKey, payloadBytes)
+		sigBytes := ed25519.Sign(signPriv, payloadBytes)
 		ctSig = base64.RawURLEncoding.EncodeToString(sigBytes)
 	}
 	ctPayload["sig"] = ctSig
@@ -739,7 +2078,7 @@ func (s *server) handleTokensIssue(w http.ResponseWriter, r *http.Request) {
 	})
 
 	log.Printf("[ACP/CT] issued CT %s issuer=%s subject=%s caps=%v", tokenID, req.IssuerID, req.SubjectAgentID, req.Capabilities)
-	acpapi.WriteSuccess(w, r, http.StatusCreated, ctPayload, s.institutionPrivKey)
+	s.writeSuccess(w, r, http.StatusCreated, ctPayload)
 }
 
 // ─── ACP-API-1.0 §7: Audit — ACP-LEDGER-1.0 ─────────────────────────────────
@@ -786,9 +2125,10 @@ func (s *server) handleAuditQuery(w http.ResponseWriter, r *http.Request) {
 		if req.EventType != "" && ev.EventType != req.EventType {
 			continue
 		}
-		// Filter by agent_id in common payload keys.
+		// Filter by agent_id in common payload keys, sealed ones revealed;
+		// the events are returned as stored.
 		if req.AgentID != "" {
-			if m, ok := ev.Payload.(map[string]interface{}); ok {
+			if m, ok := s.auditLedger.Reveal(ev).Payload.(map[string]interface{}); ok {
 				found := false
 				for _, key := range []string{"agent_id", "issuer_id", "subject_agent_id", "revoked_by"} {
 					if v, ok := m[key]; ok && fmt.Sprintf("%v", v) == req.AgentID {
@@ -832,12 +2172,12 @@ func (s *server) handleAuditQuery(w http.ResponseWriter, r *http.Request) {
 	errs := s.auditLedger.Verify()
 	chainValid := len(errs) == 0
 
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
 		"events":      filtered,
 		"count":       len(filtered),
 		"total":       total,
 		"chain_valid": chainValid,
-	}, s.institutionPrivKey)
+	})
 }
 
 // handleAuditVerify verifies the integrity of a single audit event.
@@ -856,126 +2196,1245 @@ func (s *server) handleAuditVerify(w http.ResponseWriter, r *http.Request) {
 	}
 
 	chainValid := len(errs) == 0
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
 		"event":       event,
 		"chain_valid": chainValid,
 		"errors":      errs,
-	}, s.institutionPrivKey)
+	})
 }
 
-// ─── ACP-EXEC-1.0 §9: Execution Token Handlers ───────────────────────────────
+// ─── Audit: Merkle transparency log (RFC 6962) ────────────────────────────────
 
-// handleExecTokenConsume reports ET consumption by a target system (ACP-EXEC-1.0 §9).
-// POST /acp/v1/exec-tokens/{et_id}/consume
+// handleAuditTreeHead returns the signed head of the ledger's Merkle tree.
+// GET /acp/v1/audit/tree-head?tree_size=
 //
-// Body: {et_id, consumed_at, execution_result, sig}
-// Response 200: {et_id, state, consumed_at, consumed_by, execution_result}
-func (s *server) handleExecTokenConsume(w http.ResponseWriter, r *http.Request) {
-	etID := r.PathValue("et_id")
-
-	var req execution.ConsumeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+// tree_size selects a past size (default: every event).
+// Response 200: data = {ver, institution_id, tree_size, root_hash, timestamp, kid, sig}
+func (s *server) handleAuditTreeHead(w http.ResponseWriter, r *http.Request) {
+	size, ok := treeSizeParam(w, r, "tree_size")
+	if !ok {
 		return
 	}
-
-	// Use provided consumed_at or default to now.
-	consumedAt := req.ConsumedAt
-	if consumedAt == 0 {
-		consumedAt = time.Now().Unix()
-	}
-
-	// Identify the consuming system via header (preferred) or "unknown".
-	consumerSystem := r.Header.Get("X-ACP-Agent-ID")
-	if consumerSystem == "" {
-		consumerSystem = "unknown"
-	}
-
-	// Look up ET entry for ledger audit (before consume changes state).
-	etEntry, etLookupErr := s.etRegistry.Get(etID)
-
-	if err := s.etRegistry.Consume(etID, consumerSystem, consumedAt); err != nil {
-		switch {
-		case errors.Is(err, execution.ErrTokenNotFound):
-			acpapi.WriteError(w, r, http.StatusNotFound, "EXEC-008", "execution token not found")
-		case errors.Is(err, execution.ErrTokenAlreadyConsumed):
-			acpapi.WriteError(w, r, http.StatusConflict, "EXEC-004", "token already consumed")
-		case errors.Is(err, execution.ErrTokenExpired):
-			acpapi.WriteError(w, r, http.StatusGone, "EXEC-003", "token expired")
-		default:
-			acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
-		}
+	th, err := s.auditLedger.TreeHead(size)
+	if err != nil {
+		writeTreeError(w, r, err)
 		return
 	}
-
-	// ACP-LEDGER-1.0: emit EXECUTION_TOKEN_CONSUMED event (§5.7).
-	if etLookupErr == nil {
-		s.emitLedgerEvent(ledger.EventExecutionTokenConsumed, map[string]interface{}{
-			"et_id":              etID,
-			"authorization_id":   etEntry.AuthorizationID,
-			"agent_id":           etEntry.AgentID,
-			"consumed_at":        consumedAt,
-			"consumed_by_system": consumerSystem,
-			"execution_result":   req.ExecutionResult,
-		})
-	}
-
-	log.Printf("[ACP/EXEC] consumed ET %s by %s (result: %s)", etID, consumerSystem, req.ExecutionResult)
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
-		"et_id":            etID,
-		"state":            string(execution.StateUsed),
-		"consumed_at":      consumedAt,
-		"consumed_by":      consumerSystem,
-		"execution_result": req.ExecutionResult,
-	}, s.institutionPrivKey)
+	s.writeSuccess(w, r, http.StatusOK, th)
 }
 
-// handleExecTokenStatus returns the current state of an Execution Token (ACP-EXEC-1.0 §9).
-// GET /acp/v1/exec-tokens/{et_id}/status
+// handleAuditInclusionProof proves that one event is in the ledger.
+// GET /acp/v1/audit/proof/inclusion?event_id=|sequence=&tree_size=
 //
-// Response 200: {et_id, authorization_id, agent_id, capability, resource,
-//               issued_at, expires_at, state[, consumed_at, consumed_by_system]}
-func (s *server) handleExecTokenStatus(w http.ResponseWriter, r *http.Request) {
-	etID := r.PathValue("et_id")
-
-	entry, err := s.etRegistry.Get(etID)
-	if err != nil {
-		if errors.Is(err, execution.ErrTokenNotFound) {
-			acpapi.WriteError(w, r, http.StatusNotFound, "EXEC-008", "execution token not found")
+// Response 200: data = {event_id, sequence, leaf_index, leaf_hash, tree_size,
+// audit_path[], tree_head}. Verify offline with ledger.VerifyInclusion and
+// merkle.VerifyTreeHead.
+// Response 404: LEDGER-008 (event not found)
+func (s *server) handleAuditInclusionProof(w http.ResponseWriter, r *http.Request) {
+	var ev ledger.Event
+	var found bool
+	if id := r.URL.Query().Get("event_id"); id != "" {
+		ev, found = s.auditLedger.Get(id)
+	} else {
+		seq, ok := treeSizeParam(w, r, "sequence")
+		if !ok {
 			return
 		}
-		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+		if seq == 0 {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "event_id or sequence is required")
+			return
+		}
+		ev, found = s.auditLedger.GetBySequence(seq)
+	}
+	if !found {
+		acpapi.WriteError(w, r, http.StatusNotFound, "LEDGER-008", "event not found")
 		return
 	}
-
-	data := map[string]interface{}{
-		"et_id":            entry.ETID,
-		"authorization_id": entry.AuthorizationID,
-		"agent_id":         entry.AgentID,
-		"capability":       entry.Capability,
-		"resource":         entry.Resource,
-		"issued_at":        entry.IssuedAt,
-		"expires_at":       entry.ExpiresAt,
-		"state":            string(entry.State),
+	size, ok := treeSizeParam(w, r, "tree_size")
+	if !ok {
+		return
 	}
-	if entry.ConsumedAt != nil {
-		data["consumed_at"] = *entry.ConsumedAt
+	th, err := s.auditLedger.TreeHead(size)
+	if err != nil {
+		writeTreeError(w, r, err)
+		return
 	}
-	if entry.ConsumedBySystem != nil {
-		data["consumed_by_system"] = *entry.ConsumedBySystem
+	path, err := s.auditLedger.InclusionProof(ev.Sequence, th.TreeSize)
+	if err != nil {
+		writeTreeError(w, r, err)
+		return
 	}
-
-	acpapi.WriteSuccess(w, r, http.StatusOK, data, s.institutionPrivKey)
+	leaf, err := ledger.LeafHash(ev)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"event_id":   ev.EventID,
+		"sequence":   ev.Sequence,
+		"leaf_index": ev.Sequence - 1,
+		"leaf_hash":  leaf,
+		"tree_size":  th.TreeSize,
+		"audit_path": path,
+		"tree_head":  th,
+	})
 }
 
-// ─── ACP-API-1.0 §9: Health ───────────────────────────────────────────────────
-
-// handleHealth returns server health in ACP-API-1.0 §9 format.
-// GET /acp/v1/health — no authentication required.
-func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	// Determine overall status: operational unless a component is degraded.
-	status := "operational"
-
+// handleAuditConsistencyProof proves that the current (or second) tree
+// extends the tree of first events, i.e. that no earlier event was rewritten.
+// GET /acp/v1/audit/proof/consistency?first=&second=
+//
+// Response 200: data = {first, second, proof[], tree_head} where tree_head
+// commits to second. Verify offline with merkle.VerifyConsistency against the
+// root of a previously obtained head of size first.
+func (s *server) handleAuditConsistencyProof(w http.ResponseWriter, r *http.Request) {
+	first, ok := treeSizeParam(w, r, "first")
+	if !ok {
+		return
+	}
+	second, ok := treeSizeParam(w, r, "second")
+	if !ok {
+		return
+	}
+	if first == 0 {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "first is required")
+		return
+	}
+	th, err := s.auditLedger.TreeHead(second)
+	if err != nil {
+		writeTreeError(w, r, err)
+		return
+	}
+	proof, err := s.auditLedger.ConsistencyProof(first, th.TreeSize)
+	if err != nil {
+		writeTreeError(w, r, err)
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"first":     first,
+		"second":    th.TreeSize,
+		"proof":     proof,
+		"tree_head": th,
+	})
+}
+
+// treeSizeParam parses the optional non-negative integer query parameter
+// name (0 when absent). On error it writes a 400 and returns false.
+func treeSizeParam(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 1 {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004,
+			fmt.Sprintf("%s must be a positive integer", name))
+		return 0, false
+	}
+	return n, true
+}
+
+// writeTreeError maps a Merkle tree error to its response.
+func writeTreeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, merkle.ErrTreeSize) {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+		return
+	}
+	acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+}
+
+// ─── ACP-HIST-1.0: History Query API ──────────────────────────────────────────
+
+// handleHistQuery is the filtered, cursor-paginated ledger query (ACP-HIST-1.0 §4).
+// GET /acp/v1/audit/query?event_type=&agent_id=&capability=&from_ts=&to_ts=
+//                        &from_seq=&to_seq=&cursor=&limit=&verify_chain=
+//
+// event_type accepts several comma-separated types.
+// Response 200: data = {ver, institution_id, events[], pagination, integrity}
+// Response 400: HIST-E001 (malformed parameter), HIST-E002, HIST-E003, HIST-E005
+func (s *server) handleHistQuery(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := hist.QueryFilter{
+		AgentID:    q.Get("agent_id"),
+		Capability: q.Get("capability"),
+		Cursor:     q.Get("cursor"),
+	}
+	if v := q.Get("event_type"); v != "" {
+		f.EventTypes = strings.Split(v, ",")
+	}
+	var err error
+	for _, p := range []struct {
+		name string
+		dst  *int64
+	}{{"from_ts", &f.FromTS}, {"to_ts", &f.ToTS}, {"from_seq", &f.FromSeq}, {"to_seq", &f.ToSeq}} {
+		if *p.dst, err = histIntParam(q, p.name); err != nil {
+			writeHistError(w, r, err)
+			return
+		}
+	}
+	if f.Limit, err = histLimitParam(q); err != nil {
+		writeHistError(w, r, err)
+		return
+	}
+	if v := q.Get("verify_chain"); v != "" {
+		if f.VerifyChain, err = strconv.ParseBool(v); err != nil {
+			writeHistError(w, r, fmt.Errorf("%w: verify_chain must be true or false", hist.ErrInvalidFilter))
+			return
+		}
+	}
+	resp, err := hist.Query(s.auditLedger, s.institutionID, f)
+	if err != nil {
+		writeHistError(w, r, err)
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, resp)
+}
+
+// handleHistEvent returns one event with its hash and signature checked (ACP-HIST-1.0 §5).
+// GET /acp/v1/audit/events/{event_id}
+//
+// Response 200: data = {ver, event, integrity: {hash_valid, sig_valid}}
+// Response 404: HIST-E010
+func (s *server) handleHistEvent(w http.ResponseWriter, r *http.Request) {
+	resp, err := hist.GetEvent(s.auditLedger, r.PathValue("event_id"))
+	if err != nil {
+		writeHistError(w, r, err)
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, resp)
+}
+
+// handleHistAgentHistory returns the events of one agent with a computed
+// summary (ACP-HIST-1.0 §6).
+// GET /acp/v1/audit/agents/{agent_id}/history?from_ts=&to_ts=&cursor=&limit=&include_types=
+//
+// Response 200: data = {ver, agent_id, institution_id, events[], summary, pagination, integrity}
+// Response 400: HIST-E001 (malformed parameter), HIST-E002
+func (s *server) handleHistAgentHistory(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := hist.AgentHistoryFilter{Cursor: q.Get("cursor")}
+	if v := q.Get("include_types"); v != "" {
+		f.IncludeTypes = strings.Split(v, ",")
+	}
+	var err error
+	if f.FromTS, err = histIntParam(q, "from_ts"); err != nil {
+		writeHistError(w, r, err)
+		return
+	}
+	if f.ToTS, err = histIntParam(q, "to_ts"); err != nil {
+		writeHistError(w, r, err)
+		return
+	}
+	if f.Limit, err = histLimitParam(q); err != nil {
+		writeHistError(w, r, err)
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, hist.AgentHistory(s.auditLedger, s.institutionID, r.PathValue("agent_id"), f))
+}
+
+// histIntParam parses the optional non-negative integer query parameter name.
+func histIntParam(q url.Values, name string) (int64, error) {
+	v := q.Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s must be a non-negative integer", hist.ErrInvalidFilter, name)
+	}
+	return n, nil
+}
+
+// histLimitParam parses limit: 1..100, 0 when absent (default 20).
+func histLimitParam(q url.Values) (int, error) {
+	v := q.Get("limit")
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > 100 {
+		return 0, hist.ErrLimitOutOfRange
+	}
+	return n, nil
+}
+
+// writeHistError maps an ACP-HIST-1.0 error to its response (§4, §5).
+func writeHistError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadRequest
+	switch {
+	case errors.Is(err, hist.ErrEventNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, hist.ErrChainVerifyFailed):
+		status = http.StatusInternalServerError
+	}
+	code, _, _ := strings.Cut(err.Error(), ":")
+	acpapi.WriteError(w, r, status, code, err.Error())
+}
+
+// ─── Audit: Crypto-shredding of personal data ─────────────────────────────────
+
+// handleSubjectShred destroys the key sealing the sensitive payload fields of
+// a subject (ACP_REDACT_SENSITIVE). The fields become unrecoverable and read
+// as "[redacted]"; the ledger still verifies, since only their commitments
+// are hashed. The erasure is recorded as SUBJECT_SHREDDED, which names the
+// key ID, not the subject, and the admin who requested it.
+// POST /acp/v1/audit/subjects/{subject_id}/shred
+// Capability required: acp:cap:institution.admin
+//
+// Body: {reason}
+// Response 200: data = {key_id, sealed_values, ledger_sequence}
+// Response 400: SYS-004 (redaction not enabled)
+// Response 401/403: AUTH-001, AUTH-006 (see requireAdmin)
+// Response 404: AGENT-005 (no sealed data for the subject)
+// Response 503: SYS-003 — the key is destroyed but the erasure is not recorded
+func (s *server) handleSubjectShred(w http.ResponseWriter, r *http.Request) {
+	admin, ok := s.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	if s.shredStore == nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "redaction not enabled (ACP_REDACT_SENSITIVE)")
+		return
+	}
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+
+	kid, sealed, err := s.shredStore.Shred(r.PathValue("subject_id"))
+	switch {
+	case errors.Is(err, shred.ErrUnknownSubject):
+		acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrAGENT005, "no sealed data for subject")
+		return
+	case err != nil:
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+		return
+	}
+
+	ev, err := s.auditLedger.Append(ledger.EventSubjectShredded, ledger.SubjectShreddedPayload{
+		KeyID:        kid,
+		SealedValues: sealed,
+		RequestedBy:  admin,
+		Reason:       req.Reason,
+	})
+	if err != nil {
+		log.Printf("[ACP/LEDGER] subject key %s shredded but not recorded: %v", kid, err)
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003, "subject shredded but audit ledger unavailable")
+		return
+	}
+
+	log.Printf("[ACP/LEDGER] subject key %s shredded (%d sealed values) by %s", kid, sealed, admin)
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"key_id":          kid,
+		"sealed_values":   sealed,
+		"ledger_sequence": ev.Sequence,
+	})
+}
+
+// ─── Audit: Segment archival ──────────────────────────────────────────────────
+
+// handleSegmentList lists the signed checkpoints of the archived ledger
+// segments, oldest first. The events they cover are still served by the
+// audit and history endpoints.
+// GET /acp/v1/audit/segments
+// Capability required: acp:cap:institution.admin
+//
+// Response 200: data = {segments: [SegmentCheckpoint], live_from_sequence, ledger_size}
+// Response 401/403: AUTH-001, AUTH-006 (see requireAdmin)
+func (s *server) handleSegmentList(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireAdmin(w, r); !ok {
+		return
+	}
+	segs := s.auditLedger.Segments()
+	liveFrom := int64(1)
+	if len(segs) > 0 {
+		liveFrom = segs[len(segs)-1].LastSequence + 1
+	}
+	if segs == nil {
+		segs = []ledger.SegmentCheckpoint{}
+	}
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"segments":           segs,
+		"live_from_sequence": liveFrom,
+		"ledger_size":        s.auditLedger.Size(),
+	})
+}
+
+// handleSegmentArchive archives the events older than before_ts into a new
+// segment (ACP_ARCHIVE_DIR), as the periodic archival does with
+// ACP_ARCHIVE_RETENTION. The newest event always stays live.
+// POST /acp/v1/audit/segments
+// Capability required: acp:cap:institution.admin
+//
+// Body: {before_ts}  (default: now)
+// Response 201: data = SegmentCheckpoint
+// Response 400: SYS-004 (archival not enabled, nothing to archive)
+// Response 401/403: AUTH-001, AUTH-006 (see requireAdmin)
+// Response 503: SYS-003 (segment could not be written)
+func (s *server) handleSegmentArchive(w http.ResponseWriter, r *http.Request) {
+	admin, ok := s.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		BeforeTS int64 `json:"before_ts"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+			return
+		}
+	}
+	if req.BeforeTS <= 0 {
+		req.BeforeTS = time.Now().Unix()
+	}
+
+	cp, err := s.auditLedger.ArchiveBefore(req.BeforeTS)
+	switch {
+	case errors.Is(err, ledger.ErrNoArchive):
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "segment archival not enabled (ACP_ARCHIVE_DIR)")
+		return
+	case errors.Is(err, ledger.ErrNothingToArchive):
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "no events to archive before before_ts")
+		return
+	case errors.Is(err, ledger.ErrSegmentUnavailable):
+		log.Printf("[ACP/LEDGER] archival failed: %v", err)
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003, "archive store unavailable")
+		return
+	case err != nil:
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+		return
+	}
+
+	log.Printf("[ACP/LEDGER] archived events %d-%d by %s", cp.FirstSequence, cp.LastSequence, admin)
+	s.writeSuccess(w, r, http.StatusCreated, cp)
+}
+
+// ─── Audit: Witness co-signed checkpoints ─────────────────────────────────────
+
+// newCheckpointer builds the checkpoint publisher from the environment:
+//
+//	ACP_WITNESSES            comma-separated peer institution IDs
+//	ACP_WITNESS_QUORUM       co-signatures required (default: every witness)
+//	ACP_CHECKPOINT_INTERVAL  publication period, e.g. "10m" (default: on demand only)
+//
+// Witnesses are resolved in the peer registry at publication time, so they
+// may be registered after startup. The returned interval is 0 when periodic
+// publication is disabled.
+func newCheckpointer(s *server) (*crossorg.Checkpointer, time.Duration) {
+	var witnesses []crossorg.Witness
+	for _, id := range strings.Split(os.Getenv("ACP_WITNESSES"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			witnesses = append(witnesses, crossorg.NewPeerWitness(id, s.crossPeers, nil))
+		}
+	}
+	var cfg crossorg.CheckpointerConfig
+	if v := os.Getenv("ACP_WITNESS_QUORUM"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > len(witnesses) {
+			log.Fatalf("[ACP] ACP_WITNESS_QUORUM must be between 1 and the number of ACP_WITNESSES (%d), got %q", len(witnesses), v)
+		}
+		cfg.Quorum = n
+	}
+	var interval time.Duration
+	if v := os.Getenv("ACP_CHECKPOINT_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			log.Fatalf("[ACP] invalid ACP_CHECKPOINT_INTERVAL %q", v)
+		}
+		interval, cfg.Interval = d, d
+	}
+	cp := crossorg.NewCheckpointer(s.institutionID, s.keys.Active, s.crossPeers, s.auditLedger, witnesses, cfg)
+	if len(witnesses) > 0 {
+		log.Printf("[ACP/WITNESS] checkpoints co-signed by %d of %v", cp.Quorum(), cp.WitnessIDs())
+	}
+	return cp, interval
+}
+
+// handleCheckpointPublish signs a checkpoint of the ledger, collects witness
+// co-signatures and records it as CHECKPOINT_COSIGNED once quorum is reached.
+// POST /acp/v1/audit/checkpoints
+// Capability required: acp:cap:institution.admin (publication fans out to
+// every witness)
+//
+// If nothing was appended since the last checkpoint, that checkpoint is
+// returned unchanged.
+// Response 200: data = {checkpoint, cosignatures[], quorum, ledger_sequence}
+// Response 401/403: AUTH-001, AUTH-006 (see requireAdmin)
+// Response 503: CROSS-015 (fewer valid co-signatures than ACP_WITNESS_QUORUM)
+func (s *server) handleCheckpointPublish(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.requireAdmin(w, r); !ok {
+		return
+	}
+	cc, err := s.checkpoints.Publish(r.Context())
+	if err != nil {
+		status, code := crossorg.ErrorStatus(err)
+		acpapi.WriteError(w, r, status, code, err.Error())
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, cc)
+}
+
+// handleCheckpointLatest returns the last co-signed checkpoint.
+// GET /acp/v1/audit/checkpoints/latest
+//
+// Response 404: no checkpoint recorded since startup
+func (s *server) handleCheckpointLatest(w http.ResponseWriter, r *http.Request) {
+	cc, ok := s.checkpoints.Latest()
+	if !ok {
+		acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrSYS004, "no co-signed checkpoint")
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, cc)
+}
+
+// ─── ACP-EXEC-1.0 §9: Execution Token Handlers ───────────────────────────────
+
+// handleExecTokenConsume reports ET consumption by a target system (ACP-EXEC-1.0 §9).
+// POST /acp/v1/exec-tokens/{et_id}/consume
+//
+// Body: {et_id, system_id, consumed_at, execution_result, action_parameters, sig}
+//   execution_result: "success" | "failure" | "unknown" (default "unknown")
+//   action_parameters: the parameters actually executed (checked against the ET hash)
+//   sig: the target system's signature over the body (execution.SignConsumeRequest)
+// Response 200: {et_id, state, consumed_at, consumed_by, execution_result, liability_id}
+//
+// Only the registered target system the ET is bound to may consume it.
+// Consumption emits EXECUTION_TOKEN_CONSUMED followed by the LIABILITY_RECORD
+// for the execution (ACP-LIA-1.0 §8), in one atomic ledger write. If it
+// fails the consumption is undone — the ET stays issued and its budget
+// reservation unsettled — and the response is 503 SYS-003.
+func (s *server) handleExecTokenConsume(w http.ResponseWriter, r *http.Request) {
+	etID := r.PathValue("et_id")
+
+	var req execution.ConsumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	if req.ETID != etID {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "body et_id does not match path")
+		return
+	}
+
+	execResult := req.ExecutionResult
+	if execResult == "" {
+		execResult = lia.ResultUnknown
+	}
+	if !lia.ValidExecutionResult(execResult) {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004,
+			fmt.Sprintf("invalid execution_result %q; valid: success, failure, unknown", req.ExecutionResult))
+		return
+	}
+
+	// Use provided consumed_at or default to now.
+	consumedAt := req.ConsumedAt
+	if consumedAt == 0 {
+		consumedAt = time.Now().Unix()
+	}
+
+	// Look up ET entry (before consume changes state).
+	etEntry, err := s.etRegistry.Get(etID)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusNotFound, "EXEC-008", "execution token not found")
+		return
+	}
+
+	// ACP-EXEC-1.0 §11 EXEC-009: authenticate and authorize the consumer.
+	target, err := s.targets.Get(req.SystemID)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusForbidden, "EXEC-009",
+			fmt.Sprintf("system_id %q is not a registered target system", req.SystemID))
+		return
+	}
+	if err := execution.VerifyConsumeRequest(req, target.PublicKey); err != nil {
+		acpapi.WriteError(w, r, http.StatusForbidden, "EXEC-009", err.Error())
+		return
+	}
+	if err := execution.AuthorizeConsumer(etEntry, target); err != nil {
+		acpapi.WriteError(w, r, http.StatusForbidden, "EXEC-009", err.Error())
+		return
+	}
+	// ACP-EXEC-1.0 §8 step 8: executed parameters must match the authorized ones.
+	if err := execution.CheckActionParameters(etEntry, req.ActionParameters); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, "EXEC-007", "action_parameters do not match action_parameters_hash")
+		return
+	}
+	consumerSystem := target.SystemID
+
+	if err := s.etRegistry.Consume(etID, consumerSystem, consumedAt); err != nil {
+		switch {
+		case errors.Is(err, execution.ErrTokenNotFound):
+			acpapi.WriteError(w, r, http.StatusNotFound, "EXEC-008", "execution token not found")
+		case errors.Is(err, execution.ErrTokenAlreadyConsumed):
+			acpapi.WriteError(w, r, http.StatusConflict, "EXEC-004", "token already consumed")
+		case errors.Is(err, execution.ErrTokenExpired):
+			acpapi.WriteError(w, r, http.StatusGone, "EXEC-003", "token expired")
+		case errors.Is(err, execution.ErrUnauthorizedConsumer):
+			acpapi.WriteError(w, r, http.StatusForbidden, "EXEC-009", err.Error())
+		default:
+			acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+		}
+		return
+	}
+
+	// ACP-LEDGER-1.0: EXECUTION_TOKEN_CONSUMED event (§5.7), then
+	// ACP-LIA-1.0 §8: one LIABILITY_RECORD per consumed ET.
+	entries := []ledger.Entry{{EventType: ledger.EventExecutionTokenConsumed, Payload: map[string]interface{}{
+		"et_id":              etID,
+		"authorization_id":   etEntry.AuthorizationID,
+		"agent_id":           etEntry.AgentID,
+		"consumed_at":        consumedAt,
+		"consumed_by_system": consumerSystem,
+		"execution_result":   execResult,
+	}}}
+	rec, err := s.buildLiabilityRecord(etEntry, consumedAt, consumerSystem, execResult)
+	if err != nil {
+		if rerr := s.etRegistry.RevertConsume(etID); rerr != nil {
+			log.Printf("[ACP/EXEC] revert consumption of ET %s: %v", etID, rerr)
+		}
+		log.Printf("[ACP/LIA] liability record for ET %s cannot be built, consumption reverted: %v", etID, err)
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001,
+			"liability record cannot be built: consumption not recorded, token remains issued")
+		return
+	}
+	entries = append(entries, ledger.Entry{EventType: ledger.EventLiabilityRecord, Payload: rec})
+	evs, err := s.auditLedger.AppendAll(entries...)
+	if err != nil {
+		if rerr := s.etRegistry.RevertConsume(etID); rerr != nil {
+			log.Printf("[ACP/EXEC] revert consumption of ET %s: %v", etID, rerr)
+		}
+		log.Printf("[ACP/LEDGER] consumption of ET %s not recorded, reverted: %v", etID, err)
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003,
+			"audit ledger unavailable: consumption not recorded, token remains issued")
+		return
+	}
+
+	// A failed execution releases its budget reservation; otherwise it is
+	// accounted as consumed. ETs without an amount have no reservation.
+	if execResult == lia.ResultFailure {
+		s.budgets.Release(etID)
+	} else {
+		s.budgets.Consume(etID)
+	}
+
+	s.storeLiabilityRecord(rec, evs[1])
+
+	log.Printf("[ACP/EXEC] consumed ET %s by %s (result: %s)", etID, consumerSystem, execResult)
+	data := map[string]interface{}{
+		"et_id":            etID,
+		"state":            string(execution.StateUsed),
+		"consumed_at":      consumedAt,
+		"consumed_by":      consumerSystem,
+		"execution_result": execResult,
+		"liability_id":     rec.LiabilityID,
+	}
+	s.writeSuccess(w, r, http.StatusOK, data)
+}
+
+// handleExecTokenStatus returns the current state of an Execution Token (ACP-EXEC-1.0 §9).
+// GET /acp/v1/exec-tokens/{et_id}/status
+//
+// Response 200: {et_id, authorization_id, agent_id, capability, resource,
+//               issued_at, expires_at, state[, consumed_at, consumed_by_system, target_system]}
+func (s *server) handleExecTokenStatus(w http.ResponseWriter, r *http.Request) {
+	etID := r.PathValue("et_id")
+
+	entry, err := s.etRegistry.Get(etID)
+	if err != nil {
+		if errors.Is(err, execution.ErrTokenNotFound) {
+			acpapi.WriteError(w, r, http.StatusNotFound, "EXEC-008", "execution token not found")
+			return
+		}
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+		return
+	}
+
+	data := map[string]interface{}{
+		"et_id":            entry.ETID,
+		"authorization_id": entry.AuthorizationID,
+		"agent_id":         entry.AgentID,
+		"capability":       entry.Capability,
+		"resource":         entry.Resource,
+		"issued_at":        entry.IssuedAt,
+		"expires_at":       entry.ExpiresAt,
+		"state":            string(entry.State),
+	}
+	if entry.ConsumedAt != nil {
+		data["consumed_at"] = *entry.ConsumedAt
+	}
+	if entry.ConsumedBySystem != nil {
+		data["consumed_by_system"] = *entry.ConsumedBySystem
+	}
+	if entry.TargetSystem != "" {
+		data["target_system"] = entry.TargetSystem
+	}
+
+	s.writeSuccess(w, r, http.StatusOK, data)
+}
+
+// targetSystemView is the JSON form of a registered target system.
+func targetSystemView(t execution.TargetSystem) map[string]interface{} {
+	return map[string]interface{}{
+		"system_id":            t.SystemID,
+		"public_key":           base64.RawURLEncoding.EncodeToString(t.PublicKey),
+		"allowed_capabilities": t.AllowedCapabilities,
+		"allowed_resources":    t.AllowedResources,
+		"registered_at":        t.RegisteredAt,
+	}
+}
+
+// handleTargetSystemRegister registers a system allowed to consume ETs (ACP-EXEC-1.0 §8).
+// POST /acp/v1/target-systems
+// Capability required: acp:cap:institution.admin
+//
+// Body: {system_id, public_key (base64url), allowed_capabilities[], allowed_resources[]}
+// Response 201: the registered target system.
+// Response 401/403: AUTH-001, AUTH-006 (see requireAdmin)
+func (s *server) handleTargetSystemRegister(w http.ResponseWriter, r *http.Request) {
+	admin, ok := s.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		SystemID            string   `json:"system_id"`
+		PublicKey           string   `json:"public_key"` // base64url
+		AllowedCapabilities []string `json:"allowed_capabilities"`
+		AllowedResources    []string `json:"allowed_resources"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	pubKeyBytes, err := base64.RawURLEncoding.DecodeString(req.PublicKey)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "public_key must be base64url-encoded")
+		return
+	}
+
+	target := execution.TargetSystem{
+		SystemID:            req.SystemID,
+		PublicKey:           ed25519.PublicKey(pubKeyBytes),
+		AllowedCapabilities: req.AllowedCapabilities,
+		AllowedResources:    req.AllowedResources,
+	}
+	if err := s.targets.Register(target); err != nil {
+		if errors.Is(err, execution.ErrTargetExists) {
+			acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrSYS004, fmt.Sprintf("target system %q already registered", req.SystemID))
+			return
+		}
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+		return
+	}
+	target, _ = s.targets.Get(req.SystemID)
+
+	log.Printf("[ACP/EXEC] registered target system %s (caps=%v res=%v) by %s", target.SystemID, target.AllowedCapabilities, target.AllowedResources, admin)
+	s.writeSuccess(w, r, http.StatusCreated, targetSystemView(target))
+}
+
+// handleTargetSystemGet returns a registered target system.
+// GET /acp/v1/target-systems/{system_id}
+func (s *server) handleTargetSystemGet(w http.ResponseWriter, r *http.Request) {
+	target, err := s.targets.Get(r.PathValue("system_id"))
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusNotFound, "EXEC-009", err.Error())
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, targetSystemView(target))
+}
+
+// ─── ACP-LIA-1.0 §9: Liability Handlers ──────────────────────────────────────
+
+// handleLiabilityGet returns a LIABILITY_RECORD by liability_id (ACP-LIA-1.0 §9.1).
+// GET /acp/v1/liability/{liability_id}
+//
+// Response 200: LiabilityRecord + {ledger_event_id, ledger_sequence}
+// Response 404: LIA-001
+func (s *server) handleLiabilityGet(w http.ResponseWriter, r *http.Request) {
+	rec, ok := s.liaStore.GetByLiabilityID(r.PathValue("liability_id"))
+	if !ok {
+		acpapi.WriteError(w, r, http.StatusNotFound, "LIA-001", "liability record not found")
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, rec)
+}
+
+// handleLiabilityByET returns the LIABILITY_RECORD for a consumed ET (ACP-LIA-1.0 §9.2).
+// GET /acp/v1/liability/by-et/{et_id}
+//
+// Response 200: same schema as §9.1
+// Response 202: LIA-007 — ET consumed but no record yet
+// Response 404: LIA-001
+func (s *server) handleLiabilityByET(w http.ResponseWriter, r *http.Request) {
+	etID := r.PathValue("et_id")
+	if rec, ok := s.liaStore.GetByETID(etID); ok {
+		s.writeSuccess(w, r, http.StatusOK, rec)
+		return
+	}
+	if entry, err := s.etRegistry.Get(etID); err == nil && entry.State == execution.StateUsed {
+		acpapi.WriteError(w, r, http.StatusAccepted, "LIA-007",
+			"execution token consumed, LIABILITY_RECORD emission in progress")
+		return
+	}
+	acpapi.WriteError(w, r, http.StatusNotFound, "LIA-001", "liability record not found")
+}
+
+// handleLiabilityByAgent lists LIABILITY_RECORDs for an agent (ACP-LIA-1.0 §9.3).
+// GET /acp/v1/liability/by-agent/{agent_id}?role=&from=&to=&limit=
+//
+// role: executor | assignee | any (default any)
+// Response 200: {items[], total_count}
+func (s *server) handleLiabilityByAgent(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f := lia.AgentQueryFilter{Role: q.Get("role")}
+	switch f.Role {
+	case "", "any", "executor", "assignee":
+	default:
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004,
+			fmt.Sprintf("invalid role %q; valid: executor, assignee, any", f.Role))
+		return
+	}
+	for name, dst := range map[string]*int64{"from": &f.FromTS, "to": &f.ToTS} {
+		if v := q.Get(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004,
+					fmt.Sprintf("%s must be a unix timestamp", name))
+				return
+			}
+			*dst = n
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "limit must be an integer")
+			return
+		}
+		f.Limit = n
+	}
+
+	res := s.liaStore.GetByAgentID(r.PathValue("agent_id"), f)
+	items := res.Items
+	if items == nil {
+		items = []lia.LedgerRecord{}
+	}
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"items":       items,
+		"total_count": res.TotalCount,
+	})
+}
+
+// ─── ACP-PROVENANCE-1.0 §7: Provenance Handlers ──────────────────────────────
+
+// handleProvenanceByET returns the AuthorityProvenance bound to an execution
+// token (ACP-PROVENANCE-1.0 §7).
+// GET /acp/v1/provenance/by-et/{et_id}
+//
+// Response 200: AuthorityProvenance
+// Response 404: PROV-007 (no provenance bound to this ET)
+func (s *server) handleProvenanceByET(w http.ResponseWriter, r *http.Request) {
+	etID := r.PathValue("et_id")
+	ap, ok := s.provStore.GetByExecutionID(etID)
+	if !ok {
+		acpapi.WriteError(w, r, http.StatusNotFound, "PROV-007",
+			fmt.Sprintf("no provenance bound to execution token %s", etID))
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, ap)
+}
+
+// ─── ACP-PAY-1.0: Payment Handlers ────────────────────────────────────────────
+
+// handlePayProviderRegister registers a settlement provider key for a proof
+// type (ACP-PAY-1.0 §4.2). Requires an institution admin token (see
+// requireAdmin). The registration is recorded as PAYMENT_PROVIDER_REGISTERED;
+// a registered provider_id is never re-keyed.
+// POST /acp/v1/pay/providers
+//
+// Body: {provider_id, type (on-chain|off-chain-channel|corporate-ledger), public_key (base64url)}
+// Response 201: data.{provider_id, type, public_key, ledger_event_id}
+// Response 401/403: AUTH-001 — missing or rejected admin token
+// Response 409: SYS-004 — provider_id already registered
+// Response 503: SYS-003 — the registration could not be recorded
+func (s *server) handlePayProviderRegister(w http.ResponseWriter, r *http.Request) {
+	admin, ok := s.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		ProviderID string `json:"provider_id"`
+		Type       string `json:"type"`
+		PublicKey  string `json:"public_key"` // base64url
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	pubKeyBytes, err := base64.RawURLEncoding.DecodeString(req.PublicKey)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "public_key must be base64url-encoded")
+		return
+	}
+	provider := pay.Provider{ProviderID: req.ProviderID, Type: req.Type, PublicKey: ed25519.PublicKey(pubKeyBytes)}
+	var lev ledger.Event
+	var ledgerErr error
+	err = s.payProviders.Register(provider, func(p pay.Provider) error {
+		lev, ledgerErr = s.auditLedger.Append(ledger.EventPaymentProviderRegistered, ledger.PaymentProviderRegisteredPayload{
+			ProviderID:   p.ProviderID,
+			Type:         p.Type,
+			PublicKey:    req.PublicKey,
+			RegisteredBy: admin,
+		})
+		return ledgerErr
+	})
+	switch {
+	case ledgerErr != nil:
+		log.Printf("[ACP/PAY] settlement provider %s not registered: %v", provider.ProviderID, ledgerErr)
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003, "audit ledger unavailable; provider not registered")
+		return
+	case errors.Is(err, pay.ErrProviderExists):
+		acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrSYS004, err.Error())
+		return
+	case err != nil:
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+		return
+	}
+
+	log.Printf("[ACP/PAY] settlement provider %s registered for %s by %s", provider.ProviderID, provider.Type, admin)
+	s.writeSuccess(w, r, http.StatusCreated, map[string]interface{}{
+		"provider_id":     provider.ProviderID,
+		"type":            provider.Type,
+		"public_key":      req.PublicKey,
+		"ledger_event_id": lev.EventID,
+	})
+}
+
+// handlePayVerify verifies an ACP-PAY token and records PAYMENT_VERIFIED
+// (ACP-PAY-1.0 §5.1, §7).
+// POST /acp/v1/pay/verify
+//
+// Body: {token{capability_claim, payment_condition, proof}, agent_id, resource, capability_id}
+// Response 200: data.{status, proof_id, event, ledger_event_id, verified_at}
+// Response 400 PAY-001, 402 PAY-002/PAY-003, 409 PAY-005, 410 PAY-004, 503 PAY-006/SYS-003
+func (s *server) handlePayVerify(w http.ResponseWriter, r *http.Request) {
+	var req pay.VerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	if req.AgentID == "" || req.Resource == "" {
+		acpapi.WriteError(w, r, http.StatusBadRequest, "PAY-001", "agent_id and resource are required")
+		return
+	}
+	req.InstitutionID = s.institutionID
+
+	// The proof is marked spent only once PAYMENT_VERIFIED is in the ledger.
+	_, privKey := s.keys.Active()
+	var lev ledger.Event
+	var ledgerErr error
+	ev, err := pay.VerifyToken(req, time.Now().Unix(), s.payProviders, s.payStore, privKey, func(ev pay.PaymentVerifiedEvent) error {
+		lev, ledgerErr = s.auditLedger.Append(ledger.EventPaymentVerified, ev)
+		return ledgerErr
+	})
+	if ledgerErr != nil {
+		log.Printf("[ACP/PAY] proof %s not verified: %v", req.Token.Proof.ProofID, ledgerErr)
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003, "audit ledger unavailable; payment not recorded")
+		return
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, pay.ErrInvalidSettlementProof), errors.Is(err, pay.ErrInsufficientAmount):
+			status = http.StatusPaymentRequired
+		case errors.Is(err, pay.ErrPaymentConditionExpired):
+			status = http.StatusGone
+		case errors.Is(err, pay.ErrDoubleSpend):
+			status = http.StatusConflict
+		case errors.Is(err, pay.ErrPaymentSystemUnavailable):
+			status = http.StatusServiceUnavailable
+		case !errors.Is(err, pay.ErrMalformedPaymentCondition):
+			acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+			return
+		}
+		code, msg, _ := strings.Cut(err.Error(), ": ")
+		acpapi.WriteError(w, r, status, code, msg)
+		return
+	}
+
+	log.Printf("[ACP/PAY] proof %s verified for %s (%.2f %s)", ev.ProofID, ev.AgentID, ev.Amount, ev.Currency)
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"status":          "verified",
+		"proof_id":        ev.ProofID,
+		"event":           ev,
+		"ledger_event_id": lev.EventID,
+		"verified_at":     ev.Timestamp,
+	})
+}
+
+// payProofView is the lookup representation of a verified proof (ACP-PAY-1.0 §5.2).
+func payProofView(ev pay.PaymentVerifiedEvent, proof pay.SettlementProof) map[string]interface{} {
+	return map[string]interface{}{
+		"proof_id":    proof.ProofID,
+		"status":      "verified",
+		"type":        proof.Type,
+		"amount":      proof.Amount,
+		"currency":    proof.Currency,
+		"recipient":   proof.Recipient,
+		"timestamp":   proof.Timestamp,
+		"verified_at": ev.Timestamp,
+		"agent_id":    ev.AgentID,
+		"resource":    ev.Resource,
+		"event_id":    ev.EventID,
+		"prev_hash":   ev.PrevHash,
+	}
+}
+
+// handlePayProofGet returns a verified settlement proof.
+// GET /acp/v1/pay/proofs/{proof_id}
+//
+// Response 200: payProofView
+// Response 404: PAY-007
+func (s *server) handlePayProofGet(w http.ResponseWriter, r *http.Request) {
+	proofID := r.PathValue("proof_id")
+	ev, ok := s.payStore.GetEvent(proofID)
+	proof, _ := pay.GetProof(s.payStore, proofID)
+	if !ok {
+		acpapi.WriteError(w, r, http.StatusNotFound, "PAY-007", fmt.Sprintf("proof_id %q not found", proofID))
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, payProofView(ev, proof))
+}
+
+// handlePayProofList lists verified proofs of an agent, in verification order.
+// GET /acp/v1/pay/proofs?agent_id=<AgentID>
+//
+// Response 200: data.{agent_id, proofs[payProofView]}
+func (s *server) handlePayProofList(w http.ResponseWriter, r *http.Request) {
+	agentID := r.URL.Query().Get("agent_id")
+	if agentID == "" {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "agent_id query parameter is required")
+		return
+	}
+	proofs := make([]map[string]interface{}, 0)
+	for _, ev := range s.payStore.ListByAgent(agentID) {
+		proof, _ := pay.GetProof(s.payStore, ev.ProofID)
+		proofs = append(proofs, payProofView(ev, proof))
+	}
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"agent_id": agentID,
+		"proofs":   proofs,
+	})
+}
+
+// ─── ACP-CROSS-ORG-1.1: Cross-Org Handlers ───────────────────────────────────
+
+// handleCrossOrgPeerRegister adds or replaces a federated peer institution.
+// Requires an institution admin token (see requireAdmin). The registration
+// is recorded as CROSS_ORG_PEER_REGISTERED; a registered peer is re-keyed or
+// moved only with replace: true.
+// POST /acp/v1/crossorg/peers
+//
+// Body: {institution_id, endpoint (base URL), public_key (base64url) | did, replace?}
+// With did, the peer's keys are resolved by DID on every verification.
+// Response 201: data.{institution_id, endpoint, public_key, did?, ledger_event_id}
+// Response 401/403: AUTH-001, AUTH-006 (see requireAdmin)
+// Response 409: SYS-004 — institution_id already registered and replace not set
+// Response 503: SYS-003 — the registration could not be recorded
+func (s *server) handleCrossOrgPeerRegister(w http.ResponseWriter, r *http.Request) {
+	admin, ok := s.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		InstitutionID string `json:"institution_id"`
+		Endpoint      string `json:"endpoint"`
+		PublicKey     string `json:"public_key"` // base64url
+		DID           string `json:"did"`
+		Replace       bool   `json:"replace"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	if !strings.HasPrefix(req.Endpoint, "http://") && !strings.HasPrefix(req.Endpoint, "https://") {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "endpoint must be an http(s) base URL")
+		return
+	}
+	endpoint := strings.TrimSuffix(req.Endpoint, "/")
+
+	var peer crossorg.Peer
+	if req.DID != "" {
+		var err error
+		peer, err = s.crossPeers.ResolveDID(req.InstitutionID, endpoint, req.DID)
+		if err != nil {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrDID002, err.Error())
+			return
+		}
+	} else {
+		pubKeyBytes, err := base64.RawURLEncoding.DecodeString(req.PublicKey)
+		if err != nil {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "public_key must be base64url-encoded")
+			return
+		}
+		peer = crossorg.Peer{
+			InstitutionID: req.InstitutionID,
+			Endpoint:      endpoint,
+			PublicKey:     ed25519.PublicKey(pubKeyBytes),
+		}
+	}
+	pubKey := base64.RawURLEncoding.EncodeToString(peer.PublicKey)
+
+	var lev ledger.Event
+	var ledgerErr error
+	err := s.crossPeers.Register(peer, req.Replace, func(p crossorg.Peer, replaced bool) error {
+		lev, ledgerErr = s.auditLedger.Append(ledger.EventCrossOrgPeerRegistered, ledger.CrossOrgPeerRegisteredPayload{
+			InstitutionID: p.InstitutionID,
+			Endpoint:      p.Endpoint,
+			PublicKey:     pubKey,
+			DID:           p.DID,
+			Replaced:      replaced,
+			RegisteredBy:  admin,
+		})
+		return ledgerErr
+	})
+	switch {
+	case ledgerErr != nil:
+		log.Printf("[ACP/CROSS] peer %s not registered: %v", peer.InstitutionID, ledgerErr)
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003, "audit ledger unavailable; peer not registered")
+		return
+	case errors.Is(err, crossorg.ErrPeerExists):
+		acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrSYS004, err.Error()+"; set replace to re-key it")
+		return
+	case err != nil:
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+		return
+	}
+
+	log.Printf("[ACP/CROSS] peer %s registered at %s by %s", peer.InstitutionID, peer.Endpoint, admin)
+	data := map[string]interface{}{
+		"institution_id":  peer.InstitutionID,
+		"endpoint":        peer.Endpoint,
+		"public_key":      pubKey,
+		"ledger_event_id": lev.EventID,
+	}
+	if peer.DID != "" {
+		data["did"] = peer.DID
+	}
+	s.writeSuccess(w, r, http.StatusCreated, data)
+}
+
+// handleCrossOrgSend builds a bundle for a peer, signs it with the active
+// institution key and queues it for delivery (ACP-CROSS-ORG-1.1 §7.1).
+// POST /acp/v1/crossorg/outbox
+//
+// Capability required: acp:cap:institution.admin
+//
+// Body: {target_institution_id, events[CrossOrgInteraction], evidence{}}
+// Response 202: OutboxEntry (status "pending")
+// Response 401/403: AUTH-001, AUTH-006 (see requireAdmin)
+// Response 403: CROSS-004 (target is not a registered peer)
+func (s *server) handleCrossOrgSend(w http.ResponseWriter, r *http.Request) {
+	admin, ok := s.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		TargetInstitutionID string                         `json:"target_institution_id"`
+		Events              []crossorg.CrossOrgInteraction `json:"events"`
+		Evidence            map[string]interface{}         `json:"evidence"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	_, priv := s.keys.Active()
+	if priv == nil {
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003, "bundle signing requires ACP_INSTITUTION_PRIVATE_KEY")
+		return
+	}
+
+	bundle, err := crossorg.NewBundle(s.institutionID, req.TargetInstitutionID, req.Events, req.Evidence)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS003, err.Error())
+		return
+	}
+	for i := range bundle.Events {
+		if err := crossorg.SignInteraction(&bundle.Events[i], priv); err != nil {
+			acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS003, err.Error())
+			return
+		}
+	}
+	if err := crossorg.SignBundle(&bundle, priv); err != nil {
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS003, err.Error())
+		return
+	}
+	entry, err := s.outbox.Enqueue(bundle)
+	if err != nil {
+		status, code := crossorg.ErrorStatus(err)
+		acpapi.WriteError(w, r, status, code, err.Error())
+		return
+	}
+
+	log.Printf("[ACP/CROSS] bundle %s queued for %s (%d events) by %s", bundle.BundleID, bundle.TargetInstitutionID, len(bundle.Events), admin)
+	s.writeSuccess(w, r, http.StatusAccepted, entry)
+}
+
+// handleCrossOrgOutboxGet returns the delivery state of an outbound bundle.
+// GET /acp/v1/crossorg/outbox/{bundle_id}
+func (s *server) handleCrossOrgOutboxGet(w http.ResponseWriter, r *http.Request) {
+	entry, ok := s.outbox.Get(r.PathValue("bundle_id"))
+	if !ok {
+		acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrSYS004, "bundle not found in outbox")
+		return
+	}
+	s.writeSuccess(w, r, http.StatusOK, entry)
+}
+
+// handleCrossOrgPeerBundles lists the bundles exchanged with one peer:
+// outbound delivery state and inbound bundles with the ACKs issued for them.
+// GET /acp/v1/crossorg/peers/{institution_id}/bundles
+func (s *server) handleCrossOrgPeerBundles(w http.ResponseWriter, r *http.Request) {
+	peerID := r.PathValue("institution_id")
+	if _, err := s.crossPeers.Get(peerID); err != nil {
+		acpapi.WriteError(w, r, http.StatusNotFound, "CROSS-004", err.Error())
+		return
+	}
+	received := s.crossStore.ListBySource(peerID)
+	sort.Slice(received, func(i, j int) bool { return received[i].CreatedAt < received[j].CreatedAt })
+	inbound := []map[string]interface{}{}
+	for _, b := range received {
+		inbound = append(inbound, map[string]interface{}{
+			"bundle_id":  b.BundleID,
+			"created_at": b.CreatedAt,
+			"events":     len(b.Events),
+			"acks":       s.crossStore.AcksForBundle(b.BundleID),
+		})
+	}
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"institution_id": peerID,
+		"outbound":       s.outbox.ListByPeer(peerID),
+		"inbound":        inbound,
+	})
+}
+
+// ─── ACP-API-1.0 §9: Health ───────────────────────────────────────────────────
+
+// handleHealth returns server health in ACP-API-1.0 §9 format.
+// GET /acp/v1/health — no authentication required.
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	// Determine overall status: operational unless a component is degraded.
+	// The audit ledger is unavailable while its last commit failed; decisions
+	// are withheld (SYS-003) until one succeeds.
+	status := "operational"
+	ledgerStatus := "operational"
+	if s.auditLedger.BackendErr() != nil {
+		status, ledgerStatus = "degraded", "unavailable"
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
 		"acp_version": "1.0",
@@ -983,7 +3442,7 @@ func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		"timestamp":   time.Now().Unix(),
 		"components": map[string]string{
 			"policy_engine":  "operational",
-			"audit_ledger":   "operational",
+			"audit_ledger":   ledgerStatus,
 			"agent_registry": "operational",
 			"rev_endpoint":   "operational",
 			"rep_engine":     "operational",
@@ -993,8 +3452,16 @@ func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
 			"agents":        s.registry.Size(),
 			"challenges":    s.challenges.Size(),
 			"nonces":        s.nonceStore.Size(),
+			"target_systems": s.targets.Size(),
 			"revoked":       s.revStore.Size(),
 			"ledger_events": s.auditLedger.Size(),
+			"ledger_segments": len(s.auditLedger.Segments()),
+			"ledger_verified_through": s.auditLedger.VerifiedThrough(),
+			"liability":     s.liaStore.Size(),
+			"keys":          s.keys.Size(),
+			"crossorg_peers":   s.crossPeers.Size(),
+			"crossorg_bundles": s.crossStore.Size(),
+			"crossorg_pending": s.outbox.Pending(),
 		},
 	})
 }
@@ -1014,9 +3481,9 @@ func (s *server) handleChallenge(w http.ResponseWriter, r *http.Request) {
 		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS002, "challenge generation failed")
 		return
 	}
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]string{
+	s.writeSuccess(w, r, http.StatusOK, map[string]string{
 		"challenge": challenge,
-	}, s.institutionPrivKey)
+	})
 }
 
 // handleVerify verifies a Capability Token + Proof-of-Possession.
@@ -1028,6 +3495,12 @@ func (s *server) handleChallenge(w http.ResponseWriter, r *http.Request) {
 //	X-ACP-Agent-ID:   <agentID>
 //	X-ACP-Challenge:  <challenge>
 //	X-ACP-Signature:  <pop_signature>
+//
+// Body (optional): {delegation_chain: [token...]} — the ancestors, root first,
+// of a token issued by an agent. Without it the token must be issued by this
+// institution.
+//
+// Response 429: BULK-002 with Retry-After — the agent exceeded its rate limit
 func (s *server) handleVerify(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		acpapi.WriteError(w, r, http.StatusMethodNotAllowed, acpapi.ErrSYS004, "method not allowed")
@@ -1048,22 +3521,48 @@ func (s *server) handleVerify(w http.ResponseWriter, r *http.Request) {
 		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrHP004, "missing ACP headers (X-ACP-Agent-ID, X-ACP-Challenge, X-ACP-Signature)")
 		return
 	}
-
-	agentPubKey, err := s.registry.GetPublicKey(agentID)
+	// Any key valid now: during a rotation overlap both old and new key sign PoPs.
+	agentKeys, err := s.registry.KeysAt(agentID, time.Now().Unix())
 	if err != nil {
 		acpapi.WriteError(w, r, http.StatusUnauthorized, acpapi.ErrAGENT005, fmt.Sprintf("agent not registered: %v", err))
 		return
 	}
 
-	if err := handshake.VerifyProofOfPossession(r, s.challenges, agentPubKey); err != nil {
+	if err := handshake.VerifyProofOfPossessionKeys(r, s.challenges, agentKeys); err != nil {
 		acpapi.WriteError(w, r, http.StatusUnauthorized, acpapi.ErrHP009, fmt.Sprintf("PoP verification failed: %v", err))
 		return
 	}
+	// The PoP authenticated the agent: its quota applies from here.
+	if s.rateLimited(w, r, agentID, "verify", true) {
+		return
+	}
 
-	token, err := tokens.ParseAndVerify([]byte(tokenJSON), s.institutionPubKey, tokens.VerificationRequest{
+	// The token must be issued by this institution. A token issued by an
+	// agent (delegation) is accepted only as the leaf of an institution-rooted
+	// chain whose ancestors, root first, are sent in the body.
+	var body struct {
+		DelegationChain []json.RawMessage `json:"delegation_chain"`
+	}
+	if raw, _ := io.ReadAll(r.Body); len(bytes.TrimSpace(raw)) > 0 {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+			return
+		}
+	}
+	vreq := tokens.VerificationRequest{
 		RevocationChecker: s.revChecker,
 		NonceStore:        s.nonceStore,
-	})
+	}
+	var token *tokens.CapabilityToken
+	if len(body.DelegationChain) == 0 {
+		token, err = s.verifyInstitutionToken([]byte(tokenJSON), vreq)
+	} else {
+		var chain delegation.Chain
+		chain, err = s.verifyChain(append(body.DelegationChain, json.RawMessage(tokenJSON)), vreq)
+		if err == nil {
+			token = chain[len(chain)-1]
+		}
+	}
 	if err != nil {
 		s.emitRepEvent(agentID, repEventFromTokenError(err))
 		code := acpapi.ErrAUTH001
@@ -1074,7 +3573,7 @@ func (s *server) handleVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if token.Subject != agentID {
+	if token.Subject != agentID && token.Subject != "did:acpd:"+agentID {
 		acpapi.WriteError(w, r, http.StatusForbidden, acpapi.ErrHP010,
 			fmt.Sprintf("token subject %q does not match agent %q", token.Subject, agentID))
 		return
@@ -1083,13 +3582,13 @@ func (s *server) handleVerify(w http.ResponseWriter, r *http.Request) {
 	s.emitRepEvent(agentID, reputation.EvtVerifyOK)
 	s.registry.TouchLastActive(agentID)
 
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
 		"ok":           true,
 		"agent_id":     agentID,
 		"capabilities": token.Cap,
 		"resource":     token.Resource,
 		"expires":      token.Expiration,
-	}, s.institutionPrivKey)
+	})
 }
 
 // ─── ACP-REV-1.0 Handlers ─────────────────────────────────────────────────────
@@ -1114,19 +3613,24 @@ func (s *server) handleRevCheck(w http.ResponseWriter, r *http.Request) {
 	if revoked {
 		status = "revoked"
 	}
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
 		"token_id":   tokenID,
 		"status":     status,
 		"checked_at": time.Now().Unix(),
-	}, s.institutionPrivKey)
+	})
 }
 
 // handleRevRevoke emits a revocation for a token (ACP-REV-1.0 §5).
 // POST /acp/v1/rev/revoke
-// Body: {token_id, reason_code, revoked_by, revoke_descendants, sig}
+// Body: {token_id, reason_code, revoked_by, revoke_descendants, agent_id, sig}
+//
+// With agent_id and reason REV-004 (decommissioned) or REV-008 (emergency),
+// the agent itself is revoked through the lifecycle state machine; if that
+// fails, the token is not revoked either and the lifecycle error is returned.
 func (s *server) handleRevRevoke(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		TokenID           string `json:"token_id"`
+		AgentID           string `json:"agent_id"`
 		ReasonCode        string `json:"reason_code"`
 		RevokedBy         string `json:"revoked_by"`
 		RevokeDescendants bool   `json:"revoke_descendants"`
@@ -1145,6 +3649,26 @@ func (s *server) handleRevRevoke(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The agent is revoked first: if that fails, nothing has changed and the
+	// request can be retried. An agent already revoked is not an error.
+	revokeAgent := req.AgentID != "" &&
+		(req.ReasonCode == revocation.ReasonAgentDecommissioned || req.ReasonCode == revocation.ReasonEmergency)
+	if revokeAgent {
+		_, err := s.lifecycle.Transition(lifecycle.Request{
+			AgentID:          req.AgentID,
+			Status:           registry.StatusRevoked,
+			Reason:           req.ReasonCode,
+			AuthorizedBy:     req.RevokedBy,
+			Source:           lifecycle.SourceRevocation,
+			AuthorizationRef: acpapi.GetRequestID(r),
+		})
+		if err != nil && !errors.Is(err, registry.ErrAgentRevoked) {
+			log.Printf("[ACP/REV] agent %s not revoked: %v", req.AgentID, err)
+			s.writeTransitionError(w, r, err)
+			return
+		}
+	}
+
 	now := time.Now().Unix()
 	record := revocation.RevocationRecord{
 		TokenID:    req.TokenID,
@@ -1177,12 +3701,17 @@ func (s *server) handleRevRevoke(w http.ResponseWriter, r *http.Request) {
 		"descendant_count":    0, // descendant tracking not yet implemented
 	})
 
-	log.Printf("[ACP/REV] revoked token %s by %s (reason: %s)", req.TokenID, req.RevokedBy, req.ReasonCode)
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	resp := map[string]interface{}{
 		"ok":         true,
 		"token_id":   req.TokenID,
 		"revoked_at": now,
-	}, s.institutionPrivKey)
+	}
+	if revokeAgent {
+		resp["agent_state"] = string(registry.StatusRevoked)
+	}
+
+	log.Printf("[ACP/REV] revoked token %s by %s (reason: %s)", req.TokenID, req.RevokedBy, req.ReasonCode)
+	s.writeSuccess(w, r, http.StatusOK, resp)
 }
 
 // ─── ACP-REP-1.1 Handlers ─────────────────────────────────────────────────────
@@ -1196,7 +3725,7 @@ func (s *server) handleRepGet(w http.ResponseWriter, r *http.Request) {
 		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, "internal store error")
 		return
 	}
-	acpapi.WriteSuccess(w, r, http.StatusOK, record, s.institutionPrivKey)
+	s.writeSuccess(w, r, http.StatusOK, record)
 }
 
 // handleRepEvents returns paginated reputation events for an agent.
@@ -1218,17 +3747,18 @@ func (s *server) handleRepEvents(w http.ResponseWriter, r *http.Request) {
 		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, "internal store error")
 		return
 	}
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
 		"events": events,
 		"total":  total,
 		"limit":  limit,
 		"offset": offset,
-	}, s.institutionPrivKey)
+	})
 }
 
 // handleRepState manually sets the administrative state of an agent (ACP-REP-1.1 §7).
 // POST /acp/v1/rep/{agent_id}/state
 // Body: {new_state, reason, authorized_by}
+// Errors: 400 STATE-001 (transition not allowed), 409 STATE-002 (BANNED).
 func (s *server) handleRepState(w http.ResponseWriter, r *http.Request) {
 	agentID := r.PathValue("agent_id")
 
@@ -1253,21 +3783,115 @@ func (s *server) handleRepState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.repEngine.SetState(agentID, targetState, req.Reason, req.AuthorizedBy); err != nil {
-		if errors.Is(err, reputation.ErrAgentBanned) {
+	// Same state machine as /agents/{id}/state: the registry status follows.
+	source := lifecycle.SourceReputationAdmin
+	if targetState == reputation.StateBanned {
+		source = lifecycle.SourceRevocation
+	}
+	if _, err := s.lifecycle.Transition(lifecycle.Request{
+		AgentID:          agentID,
+		Status:           lifecycle.FromReputation(targetState),
+		Reason:           req.Reason,
+		AuthorizedBy:     req.AuthorizedBy,
+		Source:           source,
+		AuthorizationRef: acpapi.GetRequestID(r),
+	}); err != nil {
+		if errors.Is(err, registry.ErrAgentRevoked) || errors.Is(err, reputation.ErrAgentBanned) {
 			acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrSTATE002, "agent is BANNED — terminal state")
 			return
 		}
-		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+		s.writeTransitionError(w, r, err)
 		return
 	}
 
 	log.Printf("[ACP/REP] state change for %s → %s (by %s: %s)", agentID, targetState, req.AuthorizedBy, req.Reason)
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
 		"ok":       true,
 		"agent_id": agentID,
 		"state":    string(targetState),
-	}, s.institutionPrivKey)
+	})
+}
+
+// handleRepImport imports a peer institution's ReputationSnapshot as an
+// external prior for the agent (ACP-REP-PORTABILITY-1.1 §8). The issuer must
+// be a registered cross-org peer; its key verifies the snapshot signature.
+// POST /acp/v1/rep/{agent_id}/import
+//
+// Body: {snapshot: ReputationSnapshot, weight (optional, 0–1)}
+// Response 200: data.{agent_id, prior, local_score, divergence, warnings[]}
+//   warnings contains REP-WARN-002 when local and imported scores diverge.
+// Errors: 403 CROSS-004 (untrusted issuer), 409 AUTH-007 (rep_id replayed),
+//   410 REP-011 (expired), 422 REP-001/002/004/010.
+func (s *server) handleRepImport(w http.ResponseWriter, r *http.Request) {
+	agentID := r.PathValue("agent_id")
+
+	var req struct {
+		Snapshot *reputation.ReputationSnapshot `json:"snapshot"`
+		Weight   float64                        `json:"weight"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Snapshot == nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "body must contain a snapshot")
+		return
+	}
+	if req.Snapshot.SubjectID != agentID {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004,
+			fmt.Sprintf("snapshot subject_id %q does not match agent %q", req.Snapshot.SubjectID, agentID))
+		return
+	}
+
+	issuers := reputation.IssuerKeyFunc(func(issuer string) (ed25519.PublicKey, error) {
+		peer, err := s.crossPeers.Get(issuer)
+		if err != nil {
+			return nil, err
+		}
+		return peer.PublicKey, nil
+	})
+	res, err := s.repEngine.ImportSnapshot(req.Snapshot, issuers, reputation.ImportOptions{Weight: req.Weight})
+	if err != nil {
+		status, code := http.StatusUnprocessableEntity, acpapi.ErrSYS004
+		switch {
+		case errors.Is(err, reputation.ErrUntrustedIssuer):
+			status, code = http.StatusForbidden, "CROSS-004"
+		case errors.Is(err, reputation.ErrSnapshotReplayed):
+			status, code = http.StatusConflict, acpapi.ErrAUTH007
+		case errors.Is(err, reputation.ErrAgentBanned):
+			status, code = http.StatusConflict, acpapi.ErrSTATE002
+		case errors.Is(err, reputation.ErrExpired):
+			status, code = http.StatusGone, "REP-011"
+		case errors.Is(err, reputation.ErrInvalidTemporalOrder):
+			code = "REP-001"
+		case errors.Is(err, reputation.ErrScoreOutOfBounds):
+			code = "REP-002"
+		case errors.Is(err, reputation.ErrIssuerMissing):
+			code = "REP-004"
+		case errors.Is(err, reputation.ErrInvalidSignature):
+			code = "REP-010"
+		default:
+			status = http.StatusBadRequest
+		}
+		acpapi.WriteError(w, r, status, code, err.Error())
+		return
+	}
+
+	// ACP-LEDGER-1.3 §5.14: the import is auditable like any score update.
+	newScore := res.LocalScore
+	if rec, err := s.repEngine.GetRecord(agentID); err == nil && rec.Score == nil {
+		newScore = rec.Prior
+	}
+	s.emitLedgerEvent(ledger.EventReputationUpdated, map[string]interface{}{
+		"update_id":          randUUID(),
+		"agent_id":           agentID,
+		"previous_score":     res.LocalScore,
+		"new_score":          newScore,
+		"trigger_event_id":   res.Prior.RepID,
+		"trigger_event_type": "REPUTATION_SNAPSHOT",
+		"delta_reason":       "external_prior_imported",
+		"issuer":             res.Prior.Issuer,
+		"warnings":           res.Warnings,
+	})
+
+	log.Printf("[ACP/REP] imported snapshot %s from %s for %s (warnings=%v)", res.Prior.RepID, res.Prior.Issuer, agentID, res.Warnings)
+	s.writeSuccess(w, r, http.StatusOK, res)
 }
 
 // ─── Legacy Register (backward compat for SDKs) ───────────────────────────────
@@ -1302,12 +3926,328 @@ func (s *server) handleRegisterLegacy(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("[ACP] registered agent %s (legacy path)", req.AgentID)
-	acpapi.WriteSuccess(w, r, http.StatusOK, map[string]interface{}{
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
 		"ok":       true,
 		"agent_id": req.AgentID,
-	}, s.institutionPrivKey)
+	})
+}
+
+// ─── Institution Keyring Handlers ─────────────────────────────────────────────
+
+// handleWellKnownKeys publishes the institution keyring.
+// GET /.well-known/acp-keys — no authentication required.
+//
+// Response 200 (raw JSON, not enveloped): {institution_id, active_kid,
+//   keys[{kty, crv, kid, x, use, valid_from, valid_until, status, endorsement_ref}]}
+func (s *server) handleWellKnownKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = json.NewEncoder(w).Encode(s.keys.Document())
+}
+
+// handleKeyRotate rotates the institution signing key.
+// POST /acp/v1/keys/rotate
+// Capability required: acp:cap:institution.admin
+//
+// Body: {rotation_type: "scheduled"|"emergency", overlap_period (seconds), reason}
+// Response 200: data.{old_kid, new_kid, new_public_key, valid_from, endorsement_ref}
+// Response 401/403: AUTH-001, AUTH-006 (see requireAdmin)
+//
+// The new key is generated by the server: a key supplied by the caller
+// would be known outside the institution from the moment it signs.
+// The rotation is attributed to the admin token's subject.
+//
+// Procedure:
+//  1. The old key signs a trust_anchor_rotated governance event endorsing the new key
+//  2. The event is appended to the ledger (GOVERNANCE), still signed by the old key
+//  3. The keyring and ledger switch to the new key
+func (s *server) handleKeyRotate(w http.ResponseWriter, r *http.Request) {
+	admin, ok := s.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		RotationType  string `json:"rotation_type"`
+		OverlapPeriod int64  `json:"overlap_period"`
+		Reason        string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	switch req.RotationType {
+	case "":
+		req.RotationType = keyring.RotationScheduled
+	case keyring.RotationScheduled:
+	case keyring.RotationEmergency:
+		req.OverlapPeriod = 0 // a compromised key gets no grace period
+	default:
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004,
+			fmt.Sprintf("invalid rotation_type %q; valid: scheduled, emergency", req.RotationType))
+		return
+	}
+	if req.OverlapPeriod < 0 {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "overlap_period must be >= 0")
+		return
+	}
+
+	_, newPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, "key generation failed")
+		return
+	}
+
+	s.rotateMu.Lock()
+	defer s.rotateMu.Unlock()
+
+	oldKID, oldPriv := s.keys.Active()
+	if oldPriv == nil {
+		acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrSYS004,
+			"key rotation requires ACP_INSTITUTION_PRIVATE_KEY (old key must endorse the new one)")
+		return
+	}
+	newPub := newPriv.Public().(ed25519.PublicKey)
+	newKID := keyring.DeriveKID(newPub)
+	if _, exists := s.keys.Lookup(newKID); exists {
+		acpapi.WriteError(w, r, http.StatusConflict, acpapi.ErrSYS004, fmt.Sprintf("kid %s already in keyring", newKID))
+		return
+	}
+
+	// Step 1: old key endorses the new key (ACP-GOV-EVENTS-1.0 §5.9).
+	endorsement, err := govevents.Emit(govevents.EmitRequest{
+		EventType:     govevents.TypeTrustAnchorRotated,
+		InstitutionID: s.institutionID,
+		TriggeredBy:   admin,
+		Reason:        req.Reason,
+		Payload: govevents.TrustAnchorRotatedPayload{
+			OldKeyID:      oldKID,
+			NewKeyID:      newKID,
+			RotationType:  req.RotationType,
+			OverlapPeriod: req.OverlapPeriod,
+			NewPublicKey:  base64.RawURLEncoding.EncodeToString(newPub),
+		},
+	}, oldPriv)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+		return
+	}
+
+	// Step 2: record the endorsement in the ledger, signed by the old key.
+	ev, err := s.auditLedger.Append(ledger.EventGovernance, endorsement)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusServiceUnavailable, acpapi.ErrSYS003,
+			fmt.Sprintf("cannot record rotation in audit ledger: %v", err))
+		return
+	}
+
+	// Step 3: activate the new key, with ledger appends blocked so that no
+	// event is signed by the old key after its window closes. rotatedAt is
+	// taken after the append so every event signed by the old key falls
+	// inside its window.
+	var newKey keyring.Key
+	err = s.auditLedger.RotateSigningKey(func() (string, ed25519.PrivateKey, error) {
+		var err error
+		newKey, err = s.keys.Rotate(newPriv, time.Now().Unix(), req.OverlapPeriod, ev.EventID)
+		return newKey.KID, newPriv, err
+	})
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, err.Error())
+		return
+	}
+
+	log.Printf("[ACP/KEYS] rotated institution key %s → %s (%s, overlap=%ds, endorsement=%s) by %s",
+		oldKID, newKey.KID, req.RotationType, req.OverlapPeriod, ev.EventID, admin)
+	s.writeSuccess(w, r, http.StatusOK, map[string]interface{}{
+		"old_kid":         oldKID,
+		"new_kid":         newKey.KID,
+		"new_public_key":  base64.RawURLEncoding.EncodeToString(newPub),
+		"valid_from":      newKey.ValidFrom,
+		"rotation_type":   req.RotationType,
+		"endorsement_ref": ev.EventID,
+	})
+}
+
+// ─── Signing helpers ───────────────────────────────────────────────────────────
+
+// writeSuccess writes a success envelope signed with the active keyring key.
+func (s *server) writeSuccess(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	kid, priv := s.keys.Active()
+	acpapi.WriteSignedSuccess(w, r, status, data, kid, priv)
+}
+
+// tokenIssuerKeys selects the key(s) that may have signed a capability token.
+// Tokens issued by a registered agent (delegation) use the agent's keys valid
+// at iat, so tokens signed before a key rotation keep verifying. Otherwise
+// the institution key is selected by kid (absent on pre-keyring tokens) and iat.
+func (s *server) tokenIssuerKeys(tokenJSON []byte) ([]ed25519.PublicKey, error) {
+	var hdr struct {
+		ISS string `json:"iss"`
+		KID string `json:"kid"`
+		IAT int64  `json:"iat"`
+	}
+	if err := json.Unmarshal(tokenJSON, &hdr); err != nil {
+		return nil, fmt.Errorf("malformed token: %v", err)
+	}
+	if did.IsDID(hdr.ISS) {
+		if !s.trustedIssuerDID(hdr.ISS) {
+			return nil, fmt.Errorf("issuer %q is not a trusted DID", hdr.ISS)
+		}
+		return did.ResolveKeysAt(s.didResolver, hdr.ISS, hdr.IAT)
+	}
+	if hdr.ISS != "" && hdr.ISS != s.institutionID {
+		if _, err := s.registry.GetRecord(hdr.ISS); err == nil {
+			return s.registry.KeysAt(hdr.ISS, hdr.IAT)
+		}
+	}
+	// An empty kid selects the key valid at iat (legacy, pre-keyring tokens).
+	key, err := s.keys.KeyAt(hdr.KID, hdr.IAT)
+	if err != nil {
+		return nil, err
+	}
+	return []ed25519.PublicKey{key}, nil
+}
+
+// trustedIssuerDID reports whether tokens issued by the DID id may be
+// verified: the did:acpd of this institution or of a registered agent, or a
+// DID listed in ACP_TRUSTED_DIDS. Any other DID (e.g. a did:key anyone can
+// mint) is not a trust anchor.
+func (s *server) trustedIssuerDID(id string) bool {
+	if s.isInstitution(id) || s.trustedDIDs[id] {
+		return true
+	}
+	if agentID, ok := strings.CutPrefix(id, "did:acpd:"); ok {
+		_, err := s.registry.GetRecord(agentID)
+		return err == nil
+	}
+	return false
+}
+
+// isInstitution reports whether id names this institution, by ID or did:acpd.
+func (s *server) isInstitution(id string) bool {
+	return id == s.institutionID || id == "did:acpd:"+s.institutionID
+}
+
+// verifyInstitutionToken verifies a capability token issued by this
+// institution with the keyring key named by its kid, or valid at its iat.
+func (s *server) verifyInstitutionToken(tokenJSON []byte, vreq tokens.VerificationRequest) (*tokens.CapabilityToken, error) {
+	var hdr struct {
+		ISS string `json:"iss"`
+		KID string `json:"kid"`
+		IAT int64  `json:"iat"`
+	}
+	if err := json.Unmarshal(tokenJSON, &hdr); err != nil {
+		return nil, fmt.Errorf("malformed token: %v", err)
+	}
+	if !s.isInstitution(hdr.ISS) {
+		return nil, fmt.Errorf("token issued by %q, not this institution", hdr.ISS)
+	}
+	key, err := s.keys.KeyAt(hdr.KID, hdr.IAT)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", tokens.ErrCT002InvalidSignature, err)
+	}
+	return tokens.ParseAndVerify(tokenJSON, key, vreq)
+}
+
+// adminCapability is the capability the administrative endpoints require.
+const adminCapability = "acp:cap:institution.admin"
+
+// resolverCapability is the capability resolving an escalation requires.
+const resolverCapability = "acp:cap:agent.modify"
+
+// requireAdmin authenticates an administrative request: its bearer token
+// must be a capability token issued by this institution granting
+// acp:cap:institution.admin. The token nonce is claimed, so a token
+// authorizes a single request. On failure the error (401/403 AUTH-001,
+// AUTH-006 if revoked) is written and ok is false; otherwise admin is the
+// token subject.
+func (s *server) requireAdmin(w http.ResponseWriter, r *http.Request) (admin string, ok bool) {
+	return s.requireCapability(w, r, adminCapability)
+}
+
+// requireCapability is requireAdmin for any capability granted by the
+// institution.
+func (s *server) requireCapability(w http.ResponseWriter, r *http.Request, capability string) (subject string, ok bool) {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		acpapi.WriteError(w, r, http.StatusUnauthorized, acpapi.ErrAUTH001, "missing or invalid Authorization header")
+		return "", false
+	}
+	tok, err := s.verifyInstitutionToken([]byte(strings.TrimPrefix(authHeader, "Bearer ")), tokens.VerificationRequest{
+		RequestedCapability: capability,
+		RevocationChecker:   s.revChecker,
+		NonceStore:          s.nonceStore,
+	})
+	if err != nil {
+		code := acpapi.ErrAUTH001
+		if errors.Is(err, tokens.ErrCT010TokenRevoked) {
+			code = acpapi.ErrAUTH006
+		}
+		what := "admin"
+		if capability != adminCapability {
+			what = capability
+		}
+		acpapi.WriteError(w, r, http.StatusForbidden, code, fmt.Sprintf("%s token rejected: %v", what, err))
+		return "", false
+	}
+	return tok.Subject, true
+}
+
+// bindTarget sets the ET consumer of req (ACP-EXEC-1.0 §8): the requested
+// target_system, which must be registered for the capability and resource,
+// or else the only registered system that is. When several are, the request
+// must name one; when none is, the ET is issued unbound and not consumable.
+func (s *server) bindTarget(req *authzRequest) error {
+	if req.TargetSystem != "" {
+		target, err := s.targets.Get(req.TargetSystem)
+		if err != nil || !target.Authorizes(req.Capability, req.Resource) {
+			return fmt.Errorf("target system %q is not registered for %s on %s", req.TargetSystem, req.Capability, req.Resource)
+		}
+		return nil
+	}
+	id, err := s.targets.Resolve(req.Capability, req.Resource)
+	if err != nil {
+		return fmt.Errorf("%s on %s: %w", req.Capability, req.Resource, err)
+	}
+	req.TargetSystem = id
+	return nil
+}
+
+// newDIDResolver builds the DID resolver. did:web documents are read from
+// ACP_DID_WEB_DIR (<dir>/<host>/<path>/did.json) when set; otherwise they are
+// fetched over HTTPS only from the hosts in ACP_DID_WEB_HOSTS, and cached for
+// didWebCacheTTL. With neither, did:web is not resolved. did:acpd resolves
+// registered agents (with key history) and this institution's keyring.
+func newDIDResolver(s *server) did.Multi {
+	var fetch did.FetchFunc
+	if dir := os.Getenv("ACP_DID_WEB_DIR"); dir != "" {
+		fetch = did.DirFetch(dir)
+		log.Printf("[ACP/DID] did:web documents served from %s", dir)
+	} else if spec := os.Getenv("ACP_DID_WEB_HOSTS"); spec != "" {
+		var hosts []string
+		for _, h := range strings.Split(spec, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				hosts = append(hosts, h)
+			}
+		}
+		fetch = did.CachedFetch(did.AllowHosts(did.HTTPFetch(nil), hosts...), didWebCacheTTL)
+		log.Printf("[ACP/DID] did:web documents fetched from %s", strings.Join(hosts, ", "))
+	}
+	institution := did.KeySourceFunc(func(id string, ts int64) ([]ed25519.PublicKey, error) {
+		if id != s.institutionID {
+			return nil, fmt.Errorf("%w: %s", did.ErrNotFound, id)
+		}
+		return s.keys.KeysAt(ts), nil
+	})
+	return did.Multi{
+		"key":  did.KeyResolver{},
+		"web":  did.NewWebResolver(fetch),
+		"acpd": &did.ACPDResolver{Sources: []did.KeySource{s.registry, institution}},
+	}
 }
 
+// didWebCacheTTL is how long a fetched did:web document is reused.
+const didWebCacheTTL = 5 * time.Minute
+
 // ─── Ledger helpers ────────────────────────────────────────────────────────────
 
 // emitLedgerEvent appends an event to the audit ledger.
@@ -1318,6 +4258,247 @@ func (s *server) emitLedgerEvent(eventType string, payload interface{}) {
 	}
 }
 
+// recordDecision appends the events recording an authorization decision in
+// one atomic ledger write and returns the AUTHORIZATION event among them.
+// Unlike emitLedgerEvent it fails closed: on error the caller must not
+// release anything the decision grants.
+func (s *server) recordDecision(req authzRequest, entries ...ledger.Entry) (ledger.Event, error) {
+	evs, err := s.auditLedger.AppendAll(entries...)
+	if err != nil {
+		log.Printf("[ACP/LEDGER] decision for %s (agent=%s) not recorded, withheld: %v",
+			req.RequestID, req.AgentID, err)
+		return ledger.Event{}, err
+	}
+	for _, ev := range evs {
+		if ev.EventType == ledger.EventAuthorization {
+			return ev, nil
+		}
+	}
+	return ledger.Event{}, nil
+}
+
+// ─── Liability helpers ─────────────────────────────────────────────────────────
+
+// buildLiabilityRecord builds the LIABILITY_RECORD for a consumed ET
+// (ACP-LIA-1.0 §8.2). The caller appends it to the ledger together with
+// EXECUTION_TOKEN_CONSUMED and then stores it with storeLiabilityRecord.
+//
+// The delegation chain is reconstructed from TOKEN_ISSUED ledger events; when
+// the institutional root cannot be reached the record is still emitted with
+// chain_incomplete=true (audited degradation).
+func (s *server) buildLiabilityRecord(et execution.RegistryEntry, executedAt int64, consumedBy, result string) (lia.LiabilityRecord, error) {
+	// Steps 1–3: read the ledger and reconstruct the chain (§7).
+	s.ledgerIdx.catchUp(s.auditLedger)
+	chain, incomplete := s.ledgerIdx.tokens.ReconstructChain(et.AgentID, s.institutionID, et.IssuedAt)
+	resolver := s.ledgerIdx.escalationResolver(et.AgentID, et.AuthorizationID)
+
+	// Step 4: policy snapshot active at executed_at (ACP-PSN-1.0).
+	var snapshotRef string
+	if snap, err := s.psnStore.GetAtTime(executedAt); err == nil {
+		snapshotRef = snap.SnapshotID
+	} else {
+		log.Printf("[ACP/LIA] %v (et=%s executed_at=%d)", lia.ErrPolicySnapshotMissing, et.ETID, executedAt)
+	}
+
+	// Step 5 inputs: executor autonomy and immediate supervisor (§6 Rule 2).
+	autonomy := 2 // legacy-registered agents are treated as level 2 (see handleAuthorize)
+	if rec, err := s.registry.GetRecord(et.AgentID); err == nil {
+		autonomy = rec.AutonomyLevel
+	}
+	var supervisor string
+	if len(chain) >= 2 {
+		supervisor = chain[len(chain)-2].AgentID
+	}
+
+	// Step 6: build the payload.
+	rec, err := lia.Emit(lia.EmitRequest{
+		ETID:                      et.ETID,
+		AuthorizationID:           et.AuthorizationID,
+		AgentID:                   et.AgentID,
+		Capability:                et.Capability,
+		Resource:                  et.Resource,
+		DelegationChain:           chain,
+		PolicySnapshotRef:         snapshotRef,
+		ExecutionResult:           result,
+		ExecutedAt:                executedAt,
+		ConsumedBySystem:          consumedBy,
+		ChainIncomplete:           incomplete,
+		EscalationResolverAgentID: resolver,
+		SupervisorAgentID:         supervisor,
+		SupervisorAutonomy:        autonomy,
+	})
+	if err != nil {
+		return lia.LiabilityRecord{}, err
+	}
+	return rec, nil
+}
+
+// storeLiabilityRecord stores a LIABILITY_RECORD appended as ev for §9
+// queries. Steps 7–9 (hash, sign, append) were performed by the ledger.
+func (s *server) storeLiabilityRecord(rec lia.LiabilityRecord, ev ledger.Event) {
+	stored := lia.LedgerRecord{
+		LiabilityRecord: rec,
+		LedgerEventID:   ev.EventID,
+		LedgerSequence:  ev.Sequence,
+	}
+	if err := s.liaStore.Store(stored); err != nil {
+		log.Printf("[ACP/LIA] store liability %s: %v", rec.LiabilityID, err)
+		return
+	}
+	log.Printf("[ACP/LIA] liability %s for ET %s → assignee=%s (chain_incomplete=%v)",
+		rec.LiabilityID, rec.ETID, rec.LiabilityAssignee, rec.ChainIncomplete)
+}
+
+// builtinThresholds mirrors decisionByLevel as ACP-PSN-1.0 thresholds.
+func builtinThresholds() psn.Thresholds {
+	return psn.Thresholds{
+		Default: psn.ThresholdBand{ApprovedMax: 59, EscalatedMax: 89},
+		ByAutonomyLevel: map[string]psn.ThresholdBand{
+			"0": {ApprovedMax: -1, EscalatedMax: -1},
+			"1": {ApprovedMax: 24, EscalatedMax: 89},
+			"2": {ApprovedMax: 59, EscalatedMax: 89},
+			"3": {ApprovedMax: 89, EscalatedMax: 89},
+		},
+	}
+}
+
+// ─── Provenance helpers ────────────────────────────────────────────────────────
+
+// verifyDelegationChain verifies the delegation_chain presented with an
+// authorization request (root first). Every token must verify under its
+// issuer's keys valid at iat (see tokenIssuerKeys) and not be revoked; the
+// root must be issued by this institution, each delegated token by its
+// parent's subject, and the links must satisfy ACP-CT-1.0 §7. The leaf must
+// name the requesting agent and grant the requested capability on the
+// resource. Nonces are not claimed: the chain is evidence of authority, not
+// a token presentation.
+func (s *server) verifyDelegationChain(req authzRequest) (delegation.Chain, error) {
+	chain, err := s.verifyChain(req.DelegationChain, tokens.VerificationRequest{
+		RequestedCapability: req.Capability,
+		RequestedResource:   req.Resource,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if leaf := chain[len(chain)-1]; leaf.Subject != req.AgentID && leaf.Subject != "did:acpd:"+req.AgentID {
+		return nil, fmt.Errorf("delegation_chain leaf subject %q is not agent %q", leaf.Subject, req.AgentID)
+	}
+	return chain, nil
+}
+
+// verifyChain verifies an institution-rooted delegation chain (root first)
+// as described for verifyDelegationChain. leaf holds the checks specific to
+// the last token; every token is checked for revocation.
+func (s *server) verifyChain(raws []json.RawMessage, leaf tokens.VerificationRequest) (delegation.Chain, error) {
+	if len(raws) == 0 {
+		return nil, errors.New("delegation_chain is empty")
+	}
+	if len(raws) > tokens.MaxDelegationDepth+1 {
+		return nil, delegation.ErrChainTooLong
+	}
+	chain := make(delegation.Chain, len(raws))
+	for i, raw := range raws {
+		vreq := tokens.VerificationRequest{RevocationChecker: s.revChecker}
+		if i == len(raws)-1 {
+			vreq = leaf
+			vreq.RevocationChecker = s.revChecker
+		}
+		keys, err := s.tokenIssuerKeys(raw)
+		if err != nil {
+			return nil, fmt.Errorf("delegation_chain token %d: %w: %v", i, tokens.ErrCT002InvalidSignature, err)
+		}
+		var tok *tokens.CapabilityToken
+		for _, key := range keys {
+			tok, err = tokens.ParseAndVerify(raw, key, vreq)
+			if !errors.Is(err, tokens.ErrCT002InvalidSignature) {
+				break
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("delegation_chain token %d: %w", i, err)
+		}
+		if i == 0 && !s.isInstitution(tok.Issuer) {
+			return nil, fmt.Errorf("delegation_chain root issued by %q, not this institution", tok.Issuer)
+		}
+		if i > 0 && tok.Issuer != chain[i-1].Subject {
+			return nil, fmt.Errorf("delegation_chain link %d→%d: %w", i-1, i, delegation.ErrIssuerNotParentSubject)
+		}
+		chain[i] = tok
+	}
+	if err := delegation.Validate(chain, nil); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// buildProvenance issues the AuthorityProvenance of an issued ET from the
+// verified delegation chain and the active policy snapshot (ACP-PROVENANCE-1.0
+// §7). The caller records it as a PROVENANCE ledger event with the decision
+// and stores it once that commits. Without a chain the provenance is minimal
+// (§9): the institution authorized the agent directly.
+func (s *server) buildProvenance(et execution.Token, chain delegation.Chain) (provenance.AuthorityProvenance, error) {
+	preq := provenance.IssueRequest{
+		ExecutionID:    et.ETID,
+		Principal:      s.institutionID,
+		Executor:       et.AgentID,
+		AuthorityScope: et.Capability,
+		Chain:          provenance.StepsFromTokens(chain),
+	}
+	if len(chain) > 0 {
+		preq.Principal = chain[0].Issuer
+		preq.Executor = chain[len(chain)-1].Subject
+	}
+	snap, err := s.psnStore.GetActive()
+	if err != nil {
+		return provenance.AuthorityProvenance{}, err
+	}
+	preq.PolicyRef = snap.SnapshotID + ":" + snap.PolicyVersion
+	if preq.PolicyHash, err = psn.Hash(snap); err != nil {
+		return provenance.AuthorityProvenance{}, err
+	}
+
+	_, signPriv := s.keys.Active()
+	ap, err := provenance.Issue(preq, signPriv)
+	if err != nil {
+		return provenance.AuthorityProvenance{}, err
+	}
+	if err := provenance.ValidateChain(ap, ap.CapturedAt); err != nil {
+		return provenance.AuthorityProvenance{}, err
+	}
+	return ap, nil
+}
+
+// policyRef identifies the policy snapshot a decision was taken under.
+type policyRef struct {
+	snapshotID, version, hash string
+}
+
+// activePolicyRef returns the active policy snapshot for the
+// policy_snapshot_ref, policy_version and policy_hash fields of AUTHORIZATION
+// and RISK_EVALUATION events (ACP-LEDGER-1.3 §5.2, §5.3). The fields are
+// present but empty if no snapshot is active.
+func (s *server) activePolicyRef() policyRef {
+	snap, err := s.psnStore.GetActive()
+	if err != nil {
+		return policyRef{}
+	}
+	ref := policyRef{snapshotID: snap.SnapshotID, version: snap.PolicyVersion}
+	if ref.hash, err = psn.Hash(snap); err != nil {
+		log.Printf("[ACP/PSN] hash snapshot %s: %v", snap.SnapshotID, err)
+	}
+	return ref
+}
+
+// storeProvenance makes a recorded AuthorityProvenance queryable.
+func (s *server) storeProvenance(ap provenance.AuthorityProvenance) {
+	if err := s.provStore.Store(ap); err != nil {
+		log.Printf("[ACP/PROV] store provenance %s: %v", ap.ProvenanceID, err)
+		return
+	}
+	log.Printf("[ACP/PROV] provenance %s for ET %s (principal=%s, %d steps)",
+		ap.ProvenanceID, ap.ExecutionID, ap.Principal, len(ap.Chain))
+}
+
 // ─── Reputation helpers ────────────────────────────────────────────────────────
 
 func (s *server) emitRepEvent(agentID, eventType string) {
@@ -1360,6 +4541,138 @@ func decisionByLevel(level, score int) string {
 	}
 }
 
+// reasonBudgetExceeded is the reason_code of a budget denial.
+const reasonBudgetExceeded = "RISK-010"
+
+// reasonRiskUnavailable is the reason_code of a request denied because its
+// risk history could not be read (fail-closed).
+const reasonRiskUnavailable = "RISK-008"
+
+// ledgerSchemaMode returns the payload schema mode selected by
+// ACP_LEDGER_SCHEMA: "lenient" (default) or "strict".
+func ledgerSchemaMode() ledger.SchemaMode {
+	switch v := os.Getenv("ACP_LEDGER_SCHEMA"); v {
+	case "", "lenient":
+		return ledger.SchemaLenient
+	case "strict":
+		return ledger.SchemaStrict
+	default:
+		log.Fatalf("[ACP] ACP_LEDGER_SCHEMA must be \"strict\" or \"lenient\", got %q", v)
+		return ledger.SchemaLenient
+	}
+}
+
+// reserveSpend records an authorized amount against the budgets. Callers hold
+// s.budgetMu and have already checked the spend, so a failure here means the
+// tracker and the decision disagree; it is logged rather than reverting the
+// decision.
+func (s *server) reserveSpend(budgets []budget.Budget, spend budget.Spend) {
+	if _, err := s.budgets.Reserve(budgets, spend); err != nil {
+		log.Printf("[ACP/BUDGET] reservation %s for agent %s failed: %v", spend.ID, spend.AgentID, err)
+	}
+}
+
+// rootDelegator returns the first agent the institution delegated to on
+// agentID's delegation chain as of at, reconstructed from TOKEN_ISSUED
+// events. An agent without a delegation chain is its own root.
+func (s *server) rootDelegator(agentID string, at int64) string {
+	s.ledgerIdx.catchUp(s.auditLedger)
+	chain, _ := s.ledgerIdx.tokens.ReconstructChain(agentID, s.institutionID, at)
+	if len(chain) == 0 {
+		return agentID
+	}
+	return chain[0].AgentID
+}
+
+// ledgerIndex indexes the ledger events that liability records and budget
+// scopes are derived from, so that they do not rescan (and reveal) the
+// whole ledger: capability tokens by subject, and escalations by the
+// authorization they hold. catchUp reads only the events appended since
+// the previous call.
+type ledgerIndex struct {
+	mu          sync.Mutex
+	through     int64                  // last sequence indexed
+	tokens      *lia.TokenIndex        // TOKEN_ISSUED by subject
+	escalations map[string]string      // ScopedID(agent_id, request_id) → escalation_id
+	requests    map[string]heldRequest // escalation_id → authorization it holds
+	resolvers   map[string]string      // escalation_id → resolved_by, if APPROVED
+}
+
+func newLedgerIndex() *ledgerIndex {
+	return &ledgerIndex{
+		tokens:      lia.NewTokenIndex(),
+		escalations: make(map[string]string),
+		requests:    make(map[string]heldRequest),
+		resolvers:   make(map[string]string),
+	}
+}
+
+// heldRequest is the authorization an escalation holds. Like the idempotency
+// store, request_ids are unique per agent only.
+type heldRequest struct {
+	agentID   string
+	requestID string
+}
+
+// catchUp indexes the events appended to l since the previous call.
+func (x *ledgerIndex) catchUp(l *ledger.InMemoryLedger) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, ev := range l.List(x.through+1, 0) {
+		x.through = ev.Sequence
+		switch ev.EventType {
+		case ledger.EventTokenIssued, ledger.EventEscalationCreated, ledger.EventEscalationResolved:
+		default:
+			continue
+		}
+		m, ok := l.Reveal(ev).Payload.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch ev.EventType {
+		case ledger.EventTokenIssued:
+			x.tokens.Add(lia.IssuedToken{
+				TokenID:        fmt.Sprintf("%v", m["token_id"]),
+				IssuerID:       fmt.Sprintf("%v", m["issuer_id"]),
+				SubjectAgentID: fmt.Sprintf("%v", m["subject_agent_id"]),
+				IssuedAt:       ev.Timestamp,
+			})
+		case ledger.EventEscalationCreated:
+			if reqID, _ := m["request_id"].(string); reqID != "" {
+				escID, _ := m["escalation_id"].(string)
+				agentID, _ := m["agent_id"].(string)
+				x.escalations[idempotency.ScopedID(agentID, reqID)] = escID
+				x.requests[escID] = heldRequest{agentID: agentID, requestID: reqID}
+			}
+		case ledger.EventEscalationResolved:
+			if escID, _ := m["escalation_id"].(string); escID != "" && m["resolution"] == "APPROVED" {
+				x.resolvers[escID], _ = m["resolved_by"].(string)
+			}
+		}
+	}
+}
+
+// escalationResolver returns who approved the escalation of agentID's
+// authorization, if it was escalated and approved. request_ids are unique
+// per agent only, as in the idempotency store.
+func (x *ledgerIndex) escalationResolver(agentID, authorizationID string) string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if escID := x.escalations[idempotency.ScopedID(agentID, authorizationID)]; escID != "" {
+		return x.resolvers[escID]
+	}
+	return ""
+}
+
+// escalationRequest returns the authorization held by an escalation, and
+// false if the escalation is unknown.
+func (x *ledgerIndex) escalationRequest(escalationID string) (heldRequest, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	held, ok := x.requests[escalationID]
+	return held, ok
+}
+
 // toFloat64 converts an interface{} to float64 (for JSON numbers).
 func toFloat64(v interface{}) (float64, bool) {
 	switch n := v.(type) {
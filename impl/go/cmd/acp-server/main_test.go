@@ -3,19 +3,36 @@ package main_test
 import (
 	"bytes"
 	"crypto/ed25519"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/gowebpki/jcs"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/crossorg"
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
+	"github.com/chelof100/acp-framework/acp-go/pkg/execution"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/chelof100/acp-framework/acp-go/pkg/merkle"
+	"github.com/chelof100/acp-framework/acp-go/pkg/pay"
+	"github.com/chelof100/acp-framework/acp-go/pkg/provenance"
+	"github.com/chelof100/acp-framework/acp-go/pkg/registry"
+	"github.com/chelof100/acp-framework/acp-go/pkg/reputation"
+	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
 )
 
 // ─── One-time binary build via TestMain ───────────────────────────────────────
@@ -62,6 +79,12 @@ func testKeyPair() (ed25519.PublicKey, ed25519.PrivateKey) {
 
 // startServer launches acp-server on a free port and returns its base URL.
 func startServer(t *testing.T) string {
+	t.Helper()
+	return startServerEnv(t)
+}
+
+// startServerEnv starts the server with extra environment variables (KEY=value).
+func startServerEnv(t *testing.T, env ...string) string {
 	t.Helper()
 	pub, priv := testKeyPair()
 	pubB64 := base64.RawURLEncoding.EncodeToString(pub)
@@ -82,6 +105,7 @@ func startServer(t *testing.T) string {
 		"ACP_ADDR="+addr,
 		"ACP_LOG_LEVEL=error",
 	)
+	cmd.Env = append(cmd.Env, env...)
 
 	if err := cmd.Start(); err != nil {
 		t.Fatalf("start acp-server: %v", err)
@@ -113,6 +137,75 @@ func waitForHealth(t *testing.T, base string) {
 // doJSON sends a JSON request and returns (statusCode, top-level body, data-field body).
 // The ACP server wraps success responses in {"data": {...}}.
 func doJSON(t *testing.T, method, url string, body interface{}) (int, map[string]interface{}, map[string]interface{}) {
+	t.Helper()
+	return doJSONHeaders(t, method, url, nil, body)
+}
+
+// doAdmin is doJSON authenticated with a fresh institution admin token.
+func doAdmin(t *testing.T, method, url string, body interface{}) (int, map[string]interface{}, map[string]interface{}) {
+	t.Helper()
+	return doAdminOf(t, "org.acp.server", method, url, body)
+}
+
+// doAdminOf is doAdmin against a server started with ACP_INSTITUTION_ID.
+func doAdminOf(t *testing.T, institutionID, method, url string, body interface{}) (int, map[string]interface{}, map[string]interface{}) {
+	t.Helper()
+	return doJSONHeaders(t, method, url, map[string]string{"Authorization": "Bearer " + string(adminTokenOf(t, institutionID))}, body)
+}
+
+// adminToken returns a single-use capability token granting
+// acp:cap:institution.admin, signed with the institution key.
+func adminToken(t *testing.T) json.RawMessage {
+	t.Helper()
+	return adminTokenOf(t, "org.acp.server")
+}
+
+// adminTokenOf is adminToken issued by institutionID.
+func adminTokenOf(t *testing.T, institutionID string) json.RawMessage {
+	t.Helper()
+	return institutionToken(t, institutionID, "admin@"+institutionID, "acp:cap:institution.admin")
+}
+
+// doResolver is doJSON authenticated with a fresh token granting
+// reviewer@org.acp.server the escalation resolver capability.
+func doResolver(t *testing.T, method, url string, body interface{}) (int, map[string]interface{}, map[string]interface{}) {
+	t.Helper()
+	tok := institutionToken(t, "org.acp.server", "reviewer@org.acp.server", "acp:cap:agent.modify")
+	return doJSONHeaders(t, method, url, map[string]string{"Authorization": "Bearer " + string(tok)}, body)
+}
+
+// institutionToken returns a single-use capability token granting subject
+// capability, signed with the key of institutionID.
+func institutionToken(t *testing.T, institutionID, subject, capability string) json.RawMessage {
+	t.Helper()
+	_, priv := testKeyPair()
+	now := time.Now().Unix()
+	return signCT(t, tokens.CapabilityToken{
+		Version: "1.0", Issuer: institutionID, Subject: subject,
+		Cap: []string{capability}, Resource: institutionID,
+		IssuedAt: now, Expiration: now + 300, Nonce: "inst-" + strconv.FormatInt(time.Now().UnixNano(), 36),
+	}, priv)
+}
+
+// escalate sends an authorization that is escalated for review and returns
+// its escalation_id.
+func escalate(t *testing.T, base, agentID, requestID string) string {
+	t.Helper()
+	_, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+		"request_id": requestID,
+		"agent_id":   agentID,
+		"capability": "acp:cap:financial.payment",
+		"resource":   "accounts/ops",
+	})
+	escID, _ := data["escalation_id"].(string)
+	if data["decision"] != "ESCALATED" || escID == "" {
+		t.Fatalf("authorize %s: data=%v, want ESCALATED", requestID, data)
+	}
+	return escID
+}
+
+// doJSONHeaders is doJSON with extra request headers.
+func doJSONHeaders(t *testing.T, method, url string, headers map[string]string, body interface{}) (int, map[string]interface{}, map[string]interface{}) {
 	t.Helper()
 	var r io.Reader
 	if body != nil {
@@ -129,6 +222,9 @@ func doJSON(t *testing.T, method, url string, body interface{}) (int, map[string
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		t.Fatalf("%s %s: %v", method, url, err)
@@ -275,6 +371,57 @@ func TestServer_AgentState_InvalidTransition(t *testing.T) {
 	}
 }
 
+func TestServer_AgentLifecycle_RepStateReachesRegistry(t *testing.T) {
+	base := startServer(t)
+	_, pubB64 := agentKey(0x78)
+	agentID := "lifecycle-agent"
+	doJSON(t, http.MethodPost, base+"/acp/v1/agents", map[string]interface{}{
+		"agent_id":   agentID,
+		"public_key": pubB64,
+	})
+
+	status, _, _ := doJSON(t, http.MethodPost, base+"/acp/v1/rep/"+agentID+"/state", map[string]interface{}{
+		"new_state": "SUSPENDED", "reason": "fraud review", "authorized_by": "admin",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("rep state: status=%d", status)
+	}
+	if _, _, rec := doJSON(t, http.MethodGet, base+"/acp/v1/agents/"+agentID, nil); rec["status"] != "suspended" {
+		t.Errorf("registry status = %v, want suspended", rec["status"])
+	}
+	_, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+		"request_id": "req-lc-1",
+		"agent_id":   agentID,
+		"capability": "acp:cap:data.read",
+		"resource":   "metrics/public",
+	})
+	if data["decision"] != "DENIED" || data["reason_code"] != "AUTH-005" {
+		t.Errorf("authorize suspended agent = %v, want DENIED AUTH-005", data)
+	}
+
+	status, _, data = doJSON(t, http.MethodPost, base+"/acp/v1/agents/"+agentID+"/state", map[string]interface{}{
+		"state": "active", "reason": "cleared", "authorized_by": "admin",
+	})
+	if status != http.StatusOK || data["reputation_state"] != "ACTIVE" {
+		t.Fatalf("reinstate: status=%d data=%v", status, data)
+	}
+	if _, _, rep := doJSON(t, http.MethodGet, base+"/acp/v1/rep/"+agentID, nil); rep["state"] != "ACTIVE" {
+		t.Errorf("reputation state = %v, want ACTIVE", rep["state"])
+	}
+
+	_, _, q := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{
+		"event_type": "GOVERNANCE",
+	})
+	var types []string
+	for _, e := range q["events"].([]interface{}) {
+		payload := e.(map[string]interface{})["payload"].(map[string]interface{})
+		types = append(types, payload["event_type"].(string))
+	}
+	if len(types) != 2 || types[0] != "agent_suspended" || types[1] != "agent_reinstated" {
+		t.Errorf("governance events = %v, want [agent_suspended agent_reinstated]", types)
+	}
+}
+
 // ─── Counterfactual Endpoint ──────────────────────────────────────────────────
 
 // TestServer_Counterfactual_StructuralMutation verifies that a structural
@@ -530,3 +677,2257 @@ func TestServer_Counterfactual_UnknownResourceClass(t *testing.T) {
 	}
 	_ = strings.ToUpper(rc) // use strings package to avoid import error
 }
+
+// ─── Institution keyring ──────────────────────────────────────────────────────
+
+// TestServer_WellKnownKeys publishes the initial institution key.
+func TestServer_WellKnownKeys(t *testing.T) {
+	base := startServer(t)
+	status, doc, _ := doJSON(t, http.MethodGet, base+"/.well-known/acp-keys", nil)
+	if status != http.StatusOK {
+		t.Fatalf("got %d, want 200", status)
+	}
+	keys, _ := doc["keys"].([]interface{})
+	if len(keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(keys))
+	}
+	k0 := keys[0].(map[string]interface{})
+	pub, _ := testKeyPair()
+	if k0["x"] != base64.RawURLEncoding.EncodeToString(pub) {
+		t.Errorf("x=%v does not match institution public key", k0["x"])
+	}
+	if k0["kid"] != doc["active_kid"] || k0["status"] != "active" {
+		t.Errorf("unexpected key entry: %v (active_kid=%v)", k0, doc["active_kid"])
+	}
+}
+
+// TestServer_KeyRotate rotates the institution key and checks that the
+// endorsement event (signed by the old key) still verifies afterwards.
+func TestServer_KeyRotate(t *testing.T) {
+	base := startServer(t)
+	_, _, before := doJSON(t, http.MethodGet, base+"/.well-known/acp-keys", nil)
+	oldKID := before["active_kid"]
+
+	status, env, data := doAdmin(t, http.MethodPost, base+"/acp/v1/keys/rotate", map[string]interface{}{
+		"rotation_type":  "scheduled",
+		"overlap_period": 3600,
+		"reason":         "annual rotation",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("rotate: got %d, want 200 (%v)", status, env)
+	}
+	if data["old_kid"] != oldKID || data["new_kid"] == oldKID {
+		t.Fatalf("unexpected rotation result: %v", data)
+	}
+	// The rotation response itself is signed by the new key.
+	if env["kid"] != data["new_kid"] {
+		t.Errorf("response kid=%v, want new kid %v", env["kid"], data["new_kid"])
+	}
+
+	_, _, after := doJSON(t, http.MethodGet, base+"/.well-known/acp-keys", nil)
+	keys, _ := after["keys"].([]interface{})
+	if len(keys) != 2 || after["active_kid"] != data["new_kid"] {
+		t.Fatalf("keyring after rotation: %v", after)
+	}
+	retired := keys[0].(map[string]interface{})
+	if retired["status"] != "retired" || retired["valid_until"] == nil {
+		t.Errorf("old key not retired: %v", retired)
+	}
+
+	ref, _ := data["endorsement_ref"].(string)
+	status, _, v := doJSON(t, http.MethodGet, base+"/acp/v1/audit/verify/"+ref, nil)
+	if status != http.StatusOK {
+		t.Fatalf("audit verify: got %d", status)
+	}
+	if ev, _ := v["event"].(map[string]interface{}); ev["kid"] != oldKID {
+		t.Errorf("endorsement kid=%v, want old kid %v", ev["kid"], oldKID)
+	}
+	if v["chain_valid"] != true {
+		t.Errorf("chain_valid=%v errors=%v", v["chain_valid"], v["errors"])
+	}
+	if payload, _ := v["event"].(map[string]interface{})["payload"].(map[string]interface{}); payload["triggered_by"] != "admin@org.acp.server" {
+		t.Errorf("endorsement triggered_by=%v, want the admin", payload["triggered_by"])
+	}
+
+	// A pre-keyring token (no kid) issued before the rotation still verifies
+	// against the key that was valid at its iat.
+	_, instPriv := testKeyPair()
+	now := time.Now().Unix()
+	legacy := signCT(t, tokens.CapabilityToken{
+		Version: "1.0", Issuer: "org.acp.server", Subject: "rotate-agent",
+		Cap: []string{"acp:cap:data.read"}, Resource: "metrics",
+		IssuedAt: now - 60, Expiration: now + 3600, Nonce: "rotate-legacy",
+	}, instPriv)
+	status, env, data = doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+		"request_id": "req-rotate-legacy", "agent_id": "rotate-agent",
+		"capability": "acp:cap:data.read", "resource": "metrics/public",
+		"delegation_chain": []json.RawMessage{legacy},
+	})
+	if status != http.StatusOK || data["decision"] != "APPROVED" {
+		t.Errorf("legacy token after rotation: status=%d env=%v", status, env)
+	}
+}
+
+// TestServer_KeyRotate_InvalidType rejects unknown rotation types.
+func TestServer_KeyRotate_InvalidType(t *testing.T) {
+	base := startServer(t)
+	status, _, _ := doAdmin(t, http.MethodPost, base+"/acp/v1/keys/rotate", map[string]interface{}{
+		"rotation_type": "whenever",
+	})
+	if status != http.StatusBadRequest {
+		t.Fatalf("got %d, want 400", status)
+	}
+}
+
+// TestServer_KeyRotate_RequiresAdmin rejects rotations without an admin
+// token and leaves the active key in place.
+func TestServer_KeyRotate_RequiresAdmin(t *testing.T) {
+	base := startServer(t)
+	_, _, before := doJSON(t, http.MethodGet, base+"/.well-known/acp-keys", nil)
+	status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/keys/rotate", map[string]interface{}{
+		"rotation_type": "emergency",
+	})
+	if status != http.StatusUnauthorized || env["error"].(map[string]interface{})["code"] != "AUTH-001" {
+		t.Errorf("rotate without admin token: status=%d env=%v", status, env)
+	}
+	if _, _, after := doJSON(t, http.MethodGet, base+"/.well-known/acp-keys", nil); after["active_kid"] != before["active_kid"] {
+		t.Errorf("active_kid changed to %v", after["active_kid"])
+	}
+}
+
+// ─── ACP-RISK-2.0: derived history and reputation factor ─────────────────────
+
+// riskFactors returns the risk_factors breakdown of an /authorize response
+// and checks that it adds up to risk_score.
+func riskFactors(t *testing.T, data map[string]interface{}) map[string]float64 {
+	t.Helper()
+	items, _ := data["risk_factors"].([]interface{})
+	got := map[string]float64{}
+	sum := 0.0
+	for _, it := range items {
+		m := it.(map[string]interface{})
+		got[m["factor"].(string)+"/"+m["signal"].(string)] = m["points"].(float64)
+		sum += m["points"].(float64)
+	}
+	if sum != data["risk_score"] {
+		t.Errorf("risk_factors sum to %v, risk_score=%v (%v)", sum, data["risk_score"], items)
+	}
+	return got
+}
+
+func TestServer_Authorize_RiskFactorsFromReputation(t *testing.T) {
+	base := startServer(t)
+	authorize := func(requestID string) map[string]interface{} {
+		status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+			"request_id": requestID,
+			"agent_id":   "agent-probation",
+			"capability": "acp:cap:data.read",
+			"resource":   "metrics/public",
+		})
+		if status != http.StatusOK {
+			t.Fatalf("authorize: status=%d data=%v", status, data)
+		}
+		return data
+	}
+
+	first := authorize("req-rf-1")
+	if f := riskFactors(t, first); f["history/no_history"] != 5 {
+		t.Errorf("first request factors=%v, want history/no_history=5", f)
+	}
+
+	status, _, _ := doJSON(t, http.MethodPost, base+"/acp/v1/rep/agent-probation/state", map[string]interface{}{
+		"new_state": "PROBATION", "reason": "test", "authorized_by": "admin",
+	})
+	if status != http.StatusOK {
+		t.Fatalf("set PROBATION: status=%d", status)
+	}
+	second := authorize("req-rf-2")
+	f := riskFactors(t, second)
+	if f["reputation/probation"] != 15 {
+		t.Errorf("PROBATION factors=%v, want reputation/probation=15", f)
+	}
+	if _, ok := f["history/no_history"]; ok {
+		t.Errorf("no_history still set after a prior request: %v", f)
+	}
+	// −5 (no_history gone) +15 (probation)
+	if second["risk_score"].(float64)-first["risk_score"].(float64) != 10 {
+		t.Errorf("risk_score %v → %v, want +10", first["risk_score"], second["risk_score"])
+	}
+}
+
+// TestServer_RiskHistory_SurvivesRestart checks that risk history is
+// restored from the ledger file written by a previous run.
+func TestServer_RiskHistory_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	authorize := func(base, requestID, agentID string) map[string]float64 {
+		t.Helper()
+		status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+			"request_id": requestID,
+			"agent_id":   agentID,
+			"capability": "acp:cap:data.read",
+			"resource":   "metrics/public",
+		})
+		if status != http.StatusOK {
+			t.Fatalf("authorize: status=%d data=%v", status, data)
+		}
+		return riskFactors(t, data)
+	}
+
+	first := startServerEnv(t, "ACP_LEDGER_PATH="+path)
+	if f := authorize(first, "req-rh-1", "agent-restart"); f["history/no_history"] != 5 {
+		t.Fatalf("first run factors=%v, want history/no_history=5", f)
+	}
+
+	second := startServerEnv(t, "ACP_LEDGER_PATH="+path)
+	if f := authorize(second, "req-rh-2", "agent-restart"); f["history/no_history"] != 0 {
+		t.Errorf("after restart factors=%v, want no history/no_history", f)
+	}
+	if f := authorize(second, "req-rh-3", "agent-unseen"); f["history/no_history"] != 5 {
+		t.Errorf("unseen agent factors=%v, want history/no_history=5", f)
+	}
+}
+
+// ─── ACP-LIA-1.0: Liability records ───────────────────────────────────────────
+
+// approveET runs /authorize for a low-risk action and returns the issued et_id.
+func approveET(t *testing.T, base, agentID string) string {
+	t.Helper()
+	status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+		"request_id": "req-" + agentID,
+		"agent_id":   agentID,
+		"capability": "acp:cap:data.read",
+		"resource":   "metrics/public",
+	})
+	if status != http.StatusOK || data["decision"] != "APPROVED" {
+		t.Fatalf("authorize: status=%d data=%v", status, data)
+	}
+	et, _ := data["execution_token"].(map[string]interface{})
+	etID, _ := et["et_id"].(string)
+	if etID == "" {
+		t.Fatalf("no execution token in %v", data)
+	}
+	return etID
+}
+
+// registerTarget registers a target system for data.read on metrics/ and
+// returns its signing key.
+func registerTarget(t *testing.T, base, systemID string, seed byte) ed25519.PrivateKey {
+	t.Helper()
+	s := make([]byte, 32)
+	s[0] = seed
+	priv := ed25519.NewKeyFromSeed(s)
+	status, _, data := doAdmin(t, http.MethodPost, base+"/acp/v1/target-systems", map[string]interface{}{
+		"system_id":            systemID,
+		"public_key":           base64.RawURLEncoding.EncodeToString(priv.Public().(ed25519.PublicKey)),
+		"allowed_capabilities": []string{"acp:cap:data.*"},
+		"allowed_resources":    []string{"metrics"},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("register target: status=%d data=%v", status, data)
+	}
+	return priv
+}
+
+// consumeET signs req as systemID and reports consumption of etID.
+func consumeET(t *testing.T, base, etID, systemID string, priv ed25519.PrivateKey, req execution.ConsumeRequest) (int, map[string]interface{}) {
+	t.Helper()
+	req.ETID = etID
+	req.SystemID = systemID
+	sig, err := execution.SignConsumeRequest(req, priv)
+	if err != nil {
+		t.Fatalf("sign consume: %v", err)
+	}
+	req.Sig = sig
+	status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/exec-tokens/"+etID+"/consume", req)
+	return status, data
+}
+
+// TestServer_Liability_EmittedOnConsume checks that consuming an ET produces a
+// LIABILITY_RECORD queryable by id, by ET and by agent.
+func TestServer_Liability_EmittedOnConsume(t *testing.T) {
+	base := startServer(t)
+	agentID := "lia-agent"
+
+	// Institution-issued CT → chain reconstructible to the root.
+	status, _, _ := doJSON(t, http.MethodPost, base+"/acp/v1/tokens", map[string]interface{}{
+		"issuer_id":        "org.acp.server",
+		"subject_agent_id": agentID,
+		"capabilities":     []string{"acp:cap:data.read"},
+		"resource":         "metrics/public",
+	})
+	if status != http.StatusOK && status != http.StatusCreated {
+		t.Fatalf("token issue: got %d", status)
+	}
+
+	priv := registerTarget(t, base, "sys-metrics", 0x50)
+	etID := approveET(t, base, agentID)
+	status, consumed := consumeET(t, base, etID, "sys-metrics", priv, execution.ConsumeRequest{ExecutionResult: "success"})
+	if status != http.StatusOK {
+		t.Fatalf("consume: got %d", status)
+	}
+	liabilityID, _ := consumed["liability_id"].(string)
+	if liabilityID == "" {
+		t.Fatalf("consume response missing liability_id: %v", consumed)
+	}
+
+	status, _, rec := doJSON(t, http.MethodGet, base+"/acp/v1/liability/"+liabilityID, nil)
+	if status != http.StatusOK {
+		t.Fatalf("get liability: got %d", status)
+	}
+	if rec["et_id"] != etID || rec["liability_assignee"] != agentID || rec["execution_result"] != "success" {
+		t.Errorf("unexpected record: %v", rec)
+	}
+	if rec["chain_incomplete"] != false {
+		t.Errorf("chain_incomplete=%v, want false", rec["chain_incomplete"])
+	}
+	if ref, _ := rec["policy_snapshot_ref"].(string); ref == "" {
+		t.Error("policy_snapshot_ref is empty")
+	}
+	if rec["ledger_event_id"] == nil {
+		t.Error("ledger_event_id missing")
+	}
+
+	status, _, byET := doJSON(t, http.MethodGet, base+"/acp/v1/liability/by-et/"+etID, nil)
+	if status != http.StatusOK || byET["liability_id"] != liabilityID {
+		t.Errorf("by-et: status=%d data=%v", status, byET)
+	}
+
+	status, _, byAgent := doJSON(t, http.MethodGet, base+"/acp/v1/liability/by-agent/"+agentID+"?role=executor", nil)
+	items, _ := byAgent["items"].([]interface{})
+	if status != http.StatusOK || len(items) != 1 {
+		t.Errorf("by-agent: status=%d items=%d", status, len(items))
+	}
+}
+
+// TestServer_Liability_ChainIncomplete records audited degradation when the
+// executor holds no ledger-recorded capability token.
+func TestServer_Liability_ChainIncomplete(t *testing.T) {
+	base := startServer(t)
+	priv := registerTarget(t, base, "sys-metrics", 0x50)
+	etID := approveET(t, base, "lia-orphan")
+	status, consumed := consumeET(t, base, etID, "sys-metrics", priv, execution.ConsumeRequest{})
+	if status != http.StatusOK {
+		t.Fatalf("consume: got %d", status)
+	}
+	if consumed["execution_result"] != "unknown" {
+		t.Errorf("execution_result=%v, want unknown (default)", consumed["execution_result"])
+	}
+	_, _, rec := doJSON(t, http.MethodGet, base+"/acp/v1/liability/by-et/"+etID, nil)
+	if rec["chain_incomplete"] != true {
+		t.Errorf("chain_incomplete=%v, want true", rec["chain_incomplete"])
+	}
+}
+
+// TestServer_Liability_InvalidResult rejects execution_result outside the enum
+// without consuming the ET.
+func TestServer_Liability_InvalidResult(t *testing.T) {
+	base := startServer(t)
+	priv := registerTarget(t, base, "sys-metrics", 0x50)
+	etID := approveET(t, base, "lia-bad-result")
+	status, _ := consumeET(t, base, etID, "sys-metrics", priv, execution.ConsumeRequest{ExecutionResult: "partial"})
+	if status != http.StatusBadRequest {
+		t.Fatalf("got %d, want 400", status)
+	}
+	status, _, _ = doJSON(t, http.MethodGet, base+"/acp/v1/liability/by-et/"+etID, nil)
+	if status != http.StatusNotFound {
+		t.Errorf("by-et: got %d, want 404", status)
+	}
+}
+
+// TestServer_Liability_EscalationScopedByAgent checks that an approved
+// escalation is attributed only to the agent that was escalated: another agent
+// reusing the same request_id is not assigned to the resolver.
+func TestServer_Liability_EscalationScopedByAgent(t *testing.T) {
+	base := startServer(t)
+	escID := escalate(t, base, "lia-escalated", "req-lia-shared")
+	if status, env, _ := doResolver(t, http.MethodPost, base+"/acp/v1/authorize/escalations/"+escID+"/resolve", map[string]interface{}{
+		"resolution": "APPROVED",
+	}); status != http.StatusOK {
+		t.Fatalf("resolve: status=%d env=%v", status, env)
+	}
+
+	priv := registerTarget(t, base, "sys-metrics", 0x50)
+	etID := approveET(t, base, "lia-shared") // request_id req-lia-shared
+	if status, data := consumeET(t, base, etID, "sys-metrics", priv, execution.ConsumeRequest{ExecutionResult: "success"}); status != http.StatusOK {
+		t.Fatalf("consume: status=%d data=%v", status, data)
+	}
+	_, _, rec := doJSON(t, http.MethodGet, base+"/acp/v1/liability/by-et/"+etID, nil)
+	if rec["liability_assignee"] != "lia-shared" {
+		t.Errorf("liability_assignee=%v, want lia-shared (not the resolver of another agent's escalation)", rec["liability_assignee"])
+	}
+}
+
+// ─── ACP-BULK-1.0: Batch authorization + bulk liability query ─────────────────
+
+// TestServer_AuthorizeBatch_OrderedAnomalyState submits identical items: the
+// F_anom repeated-pattern rule must trigger from the fourth item on, exactly
+// as if the requests had been sent one at a time in item order.
+func TestServer_AuthorizeBatch_OrderedAnomalyState(t *testing.T) {
+	base := startServer(t)
+	items := make([]interface{}, 5)
+	for i := range items {
+		items[i] = map[string]interface{}{
+			"request_id":  fmt.Sprintf("b-%d", i),
+			"agent_id":    "bulk-agent",
+			"action_type": "acp:cap:data.read",
+			"resource":    "metrics/public",
+		}
+	}
+	status, env, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize/batch", map[string]interface{}{
+		"batch_id": "bat-ordered",
+		"items":    items,
+	})
+	if status != http.StatusOK {
+		t.Fatalf("got %d, want 200 (%v)", status, env)
+	}
+	if data["batch_id"] != "bat-ordered" || data["processed"] != float64(5) {
+		t.Fatalf("unexpected batch response: %v", data)
+	}
+	results, _ := data["results"].([]interface{})
+	// b-0 is the agent's first request: F_hist NoHistory adds 5.
+	want := []float64{25, 20, 20, 35, 35}
+	for i, rr := range results {
+		rm := rr.(map[string]interface{})
+		if rm["request_id"] != fmt.Sprintf("b-%d", i) {
+			t.Errorf("result[%d] request_id=%v", i, rm["request_id"])
+		}
+		if rm["risk_score"] != want[i] {
+			t.Errorf("result[%d] risk_score=%v, want %v", i, rm["risk_score"], want[i])
+		}
+		if rm["decision"] != "APPROVED" || rm["execution_token"] == nil {
+			t.Errorf("result[%d] = %v, want APPROVED with execution_token", i, rm)
+		}
+	}
+
+	// Same ledger contract as /authorize: one AUTHORIZATION per item, tagged with batch_id.
+	_, _, q := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{
+		"event_type": "AUTHORIZATION",
+		"agent_id":   "bulk-agent",
+	})
+	events, _ := q["events"].([]interface{})
+	if len(events) != 5 {
+		t.Fatalf("AUTHORIZATION events = %d, want 5", len(events))
+	}
+	for i, e := range events {
+		payload := e.(map[string]interface{})["payload"].(map[string]interface{})
+		if payload["batch_id"] != "bat-ordered" || payload["request_id"] != fmt.Sprintf("b-%d", i) {
+			t.Errorf("event[%d] payload=%v", i, payload)
+		}
+	}
+}
+
+// TestServer_Authorize_NoAnomalyScore checks that single requests are scored
+// without F_anom: the requests of the batch above, sent one at a time, do
+// not pick up the repeated-pattern points.
+func TestServer_Authorize_NoAnomalyScore(t *testing.T) {
+	base := startServer(t)
+	want := []float64{25, 20, 20, 20, 20}
+	for i := range want {
+		_, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+			"request_id": fmt.Sprintf("s-%d", i),
+			"agent_id":   "single-agent",
+			"capability": "acp:cap:data.read",
+			"resource":   "metrics/public",
+		})
+		if data["risk_score"] != want[i] {
+			t.Errorf("request %d risk_score=%v, want %v", i, data["risk_score"], want[i])
+		}
+	}
+}
+
+// TestServer_AuthorizeBatch_PartialFailure returns 207 when an item is DENIED.
+func TestServer_AuthorizeBatch_PartialFailure(t *testing.T) {
+	base := startServer(t)
+	status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize/batch", map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"request_id": "ok", "agent_id": "pf-agent", "action_type": "acp:cap:data.read", "resource": "metrics/public"},
+			map[string]interface{}{"request_id": "big", "agent_id": "pf-agent", "action_type": "acp:cap:financial.payment", "resource": "bank", "context": map[string]interface{}{"amount": 250000}},
+			map[string]interface{}{"request_id": "bad", "agent_id": "pf-agent"},
+		},
+	})
+	if status != http.StatusMultiStatus {
+		t.Fatalf("got %d, want 207", status)
+	}
+	if data["partial_failure"] != true {
+		t.Errorf("partial_failure=%v", data["partial_failure"])
+	}
+	results, _ := data["results"].([]interface{})
+	decisions := []string{"APPROVED", "DENIED", "DENIED"}
+	codes := []string{"", "RISK-005", "SYS-004"}
+	for i, rr := range results {
+		rm := rr.(map[string]interface{})
+		if rm["decision"] != decisions[i] || rm["reason_code"] != codes[i] {
+			t.Errorf("result[%d] = %v, want %s/%s", i, rm, decisions[i], codes[i])
+		}
+	}
+}
+
+// TestServer_AuthorizeBatch_Limits checks BULK-001 and BULK-005.
+func TestServer_AuthorizeBatch_Limits(t *testing.T) {
+	base := startServer(t)
+	status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/authorize/batch", map[string]interface{}{"items": []interface{}{}})
+	if status != http.StatusBadRequest || env["error"].(map[string]interface{})["code"] != "BULK-005" {
+		t.Errorf("empty batch: status=%d body=%v", status, env)
+	}
+	items := make([]interface{}, 101)
+	for i := range items {
+		items[i] = map[string]interface{}{"request_id": fmt.Sprintf("r%d", i)}
+	}
+	status, env, _ = doJSON(t, http.MethodPost, base+"/acp/v1/authorize/batch", map[string]interface{}{"items": items})
+	if status != http.StatusBadRequest || env["error"].(map[string]interface{})["code"] != "BULK-001" {
+		t.Errorf("oversized batch: status=%d body=%v", status, env)
+	}
+}
+
+// TestServer_Bulk_RateLimit rejects bursts above 10 bulk requests per second.
+func TestServer_Bulk_RateLimit(t *testing.T) {
+	base := startServer(t)
+	limited := false
+	for i := 0; i < 15 && !limited; i++ {
+		resp, err := http.Post(base+"/acp/v1/liability/query", "application/json", strings.NewReader(`{"agent_ids":["x"]}`))
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusTooManyRequests {
+			limited = true
+			if resp.Header.Get("Retry-After") == "" {
+				t.Error("429 without Retry-After header")
+			}
+		}
+	}
+	if !limited {
+		t.Fatal("no request was rate limited")
+	}
+}
+
+// TestServer_LiabilityQuery_CursorPaging pages through liability records.
+func TestServer_LiabilityQuery_CursorPaging(t *testing.T) {
+	base := startServer(t)
+	priv := registerTarget(t, base, "sys-metrics", 0x50)
+	for _, agent := range []string{"pg-a", "pg-b", "pg-c"} {
+		etID := approveET(t, base, agent)
+		if status, _ := consumeET(t, base, etID, "sys-metrics", priv,
+			execution.ConsumeRequest{ExecutionResult: "success"}); status != http.StatusOK {
+			t.Fatalf("consume: %d", status)
+		}
+	}
+
+	query := map[string]interface{}{"query_id": "q-1", "agent_ids": []string{"pg-a", "pg-b", "pg-c"}, "limit": 2}
+	status, _, page1 := doJSON(t, http.MethodPost, base+"/acp/v1/liability/query", query)
+	if status != http.StatusOK {
+		t.Fatalf("page 1: got %d", status)
+	}
+	recs1, _ := page1["records"].([]interface{})
+	cursor, _ := page1["next_cursor"].(string)
+	if page1["total"] != float64(3) || len(recs1) != 2 || cursor == "" {
+		t.Fatalf("page 1 = %v", page1)
+	}
+
+	query["cursor"] = cursor
+	_, _, page2 := doJSON(t, http.MethodPost, base+"/acp/v1/liability/query", query)
+	recs2, _ := page2["records"].([]interface{})
+	if len(recs2) != 1 || page2["next_cursor"] != "" {
+		t.Fatalf("page 2 = %v", page2)
+	}
+	if recs2[0].(map[string]interface{})["agent_id"] != "pg-c" {
+		t.Errorf("page 2 record = %v, want pg-c", recs2[0])
+	}
+
+	// Cursor reuse with different filters is rejected.
+	query["agent_ids"] = []string{"pg-a"}
+	status, _, _ = doJSON(t, http.MethodPost, base+"/acp/v1/liability/query", query)
+	if status != http.StatusBadRequest {
+		t.Errorf("changed filters: got %d, want 400", status)
+	}
+}
+
+// ─── ACP-EXEC-1.0: Consumer-bound execution tokens ───────────────────────────
+
+// TestServer_ExecToken_BoundConsumer binds the ET to the only registered
+// system for its capability; other systems and bad signatures get EXEC-009.
+func TestServer_ExecToken_BoundConsumer(t *testing.T) {
+	base := startServer(t)
+	priv := registerTarget(t, base, "sys-bound", 0x51)
+	etID := approveET(t, base, "exec-bound")
+
+	status, _, st := doJSON(t, http.MethodGet, base+"/acp/v1/exec-tokens/"+etID+"/status", nil)
+	if status != http.StatusOK || st["target_system"] != "sys-bound" {
+		t.Fatalf("status: %d %v, want target_system=sys-bound", status, st)
+	}
+
+	// A second system registered later is allowed for the capability but the
+	// ET is bound to sys-bound.
+	otherPriv := registerTarget(t, base, "sys-other", 0x52)
+	if status, data := consumeET(t, base, etID, "sys-other", otherPriv, execution.ConsumeRequest{}); status != http.StatusForbidden {
+		t.Errorf("other system: got %d %v, want 403", status, data)
+	}
+	// Correct system_id, wrong key.
+	if status, _ := consumeET(t, base, etID, "sys-bound", otherPriv, execution.ConsumeRequest{}); status != http.StatusForbidden {
+		t.Errorf("forged signature: got %d, want 403", status)
+	}
+	// Unsigned report.
+	status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/exec-tokens/"+etID+"/consume",
+		map[string]interface{}{"et_id": etID, "system_id": "sys-bound"})
+	if status != http.StatusForbidden || !strings.Contains(fmt.Sprint(env), "EXEC-009") {
+		t.Errorf("unsigned: got %d %v, want 403 EXEC-009", status, env)
+	}
+
+	if status, data := consumeET(t, base, etID, "sys-bound", priv, execution.ConsumeRequest{}); status != http.StatusOK || data["consumed_by"] != "sys-bound" {
+		t.Errorf("bound system: got %d %v", status, data)
+	}
+}
+
+// TestServer_TargetSystems_FailClosed requires an institution admin token to
+// register a target system and never issues an ET that any of several
+// systems could consume.
+func TestServer_TargetSystems_FailClosed(t *testing.T) {
+	base := startServer(t)
+	_, pubB64 := agentKey(0x55)
+	reg := map[string]interface{}{
+		"system_id":            "sys-any",
+		"public_key":           pubB64,
+		"allowed_capabilities": []string{"*"},
+		"allowed_resources":    []string{"*"},
+	}
+	if status, _, _ := doJSON(t, http.MethodPost, base+"/acp/v1/target-systems", reg); status != http.StatusUnauthorized {
+		t.Errorf("no token: got %d, want 401", status)
+	}
+	_, instPriv := testKeyPair()
+	now := time.Now().Unix()
+	notAdmin := signCT(t, tokens.CapabilityToken{
+		Version: "1.0", Issuer: "org.acp.server", Subject: "ops",
+		Cap: []string{"acp:cap:data.read"}, Resource: "org.acp.server",
+		IssuedAt: now, Expiration: now + 300, Nonce: "not-admin",
+	}, instPriv)
+	status, env, _ := doJSONHeaders(t, http.MethodPost, base+"/acp/v1/target-systems",
+		map[string]string{"Authorization": "Bearer " + string(notAdmin)}, reg)
+	if status != http.StatusForbidden || !strings.Contains(fmt.Sprint(env), "AUTH-001") {
+		t.Errorf("token without admin capability: got %d %v, want 403 AUTH-001", status, env)
+	}
+	admin := adminToken(t)
+	if status, _, _ := doJSONHeaders(t, http.MethodPost, base+"/acp/v1/target-systems",
+		map[string]string{"Authorization": "Bearer " + string(admin)}, reg); status != http.StatusCreated {
+		t.Fatalf("admin: got %d, want 201", status)
+	}
+	// Admin tokens are single-use.
+	reg["system_id"] = "sys-replayed"
+	if status, _, _ := doJSONHeaders(t, http.MethodPost, base+"/acp/v1/target-systems",
+		map[string]string{"Authorization": "Bearer " + string(admin)}, reg); status != http.StatusForbidden {
+		t.Errorf("replayed admin token: got %d, want 403", status)
+	}
+
+	// sys-any and sys-metrics both cover data.read on metrics/public.
+	priv := registerTarget(t, base, "sys-metrics", 0x56)
+	authorize := func(requestID, target string) (int, map[string]interface{}, map[string]interface{}) {
+		return doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+			"request_id":    requestID,
+			"agent_id":      "exec-ambiguous",
+			"capability":    "acp:cap:data.read",
+			"resource":      "metrics/public",
+			"target_system": target,
+		})
+	}
+	if status, env, _ := authorize("req-amb-1", ""); status != http.StatusForbidden || !strings.Contains(fmt.Sprint(env), "EXEC-009") {
+		t.Errorf("ambiguous target: got %d %v, want 403 EXEC-009", status, env)
+	}
+	status, _, data := authorize("req-amb-2", "sys-metrics")
+	if status != http.StatusOK || data["decision"] != "APPROVED" {
+		t.Fatalf("explicit target: status=%d data=%v", status, data)
+	}
+	etID, _ := data["execution_token"].(map[string]interface{})["et_id"].(string)
+	if status, data := consumeET(t, base, etID, "sys-metrics", priv, execution.ConsumeRequest{}); status != http.StatusOK {
+		t.Errorf("consume by bound system: got %d %v", status, data)
+	}
+}
+
+// TestServer_ExecToken_UnregisteredConsumer rejects consumption when no
+// registered system may consume the ET, including systems registered after
+// an unbound ET was issued.
+func TestServer_ExecToken_UnregisteredConsumer(t *testing.T) {
+	base := startServer(t)
+	etID := approveET(t, base, "exec-unreg")
+	s := make([]byte, 32)
+	s[0] = 0x53
+	priv := ed25519.NewKeyFromSeed(s)
+	if status, _ := consumeET(t, base, etID, "sys-ghost", priv, execution.ConsumeRequest{}); status != http.StatusForbidden {
+		t.Errorf("got %d, want 403", status)
+	}
+	latePriv := registerTarget(t, base, "sys-late", 0x57)
+	if status, _ := consumeET(t, base, etID, "sys-late", latePriv, execution.ConsumeRequest{}); status != http.StatusForbidden {
+		t.Errorf("system registered after issuance: got %d, want 403", status)
+	}
+}
+
+// TestServer_ExecToken_ParamsHashMismatch requires the executed parameters to
+// match the authorized action_parameters_hash (EXEC-007).
+func TestServer_ExecToken_ParamsHashMismatch(t *testing.T) {
+	base := startServer(t)
+	priv := registerTarget(t, base, "sys-params", 0x54)
+	status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+		"request_id":        "req-params",
+		"agent_id":          "exec-params",
+		"capability":        "acp:cap:data.read",
+		"resource":          "metrics/public",
+		"action_parameters": map[string]interface{}{"rows": 10},
+		"target_system":     "sys-params",
+	})
+	if status != http.StatusOK || data["decision"] != "APPROVED" {
+		t.Fatalf("authorize: %d %v", status, data)
+	}
+	etID := data["execution_token"].(map[string]interface{})["et_id"].(string)
+
+	tampered := execution.ConsumeRequest{ActionParameters: map[string]interface{}{"rows": 10000}}
+	if status, _ := consumeET(t, base, etID, "sys-params", priv, tampered); status != http.StatusBadRequest {
+		t.Errorf("tampered params: got %d, want 400", status)
+	}
+	exact := execution.ConsumeRequest{ActionParameters: map[string]interface{}{"rows": 10}}
+	if status, data := consumeET(t, base, etID, "sys-params", priv, exact); status != http.StatusOK {
+		t.Errorf("exact params: got %d %v", status, data)
+	}
+}
+
+// TestServer_Authorize_UnknownTargetSystem rejects an explicit target_system
+// that is not registered for the capability.
+func TestServer_Authorize_UnknownTargetSystem(t *testing.T) {
+	base := startServer(t)
+	status, _, _ := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+		"request_id":    "req-no-target",
+		"agent_id":      "exec-no-target",
+		"capability":    "acp:cap:data.read",
+		"resource":      "metrics/public",
+		"target_system": "sys-missing",
+	})
+	if status != http.StatusForbidden {
+		t.Errorf("got %d, want 403", status)
+	}
+}
+
+// ─── ACP-CROSS-ORG-1.1 ────────────────────────────────────────────────────────
+
+// TestServer_CrossOrg_BundleDeliveryAndAck federates two servers, sends a
+// bundle from A to B through A's outbox and checks that B's ACK is anchored
+// to the CROSS_ORG_INTERACTION B recorded.
+func TestServer_CrossOrg_BundleDeliveryAndAck(t *testing.T) {
+	baseA := startServerEnv(t, "ACP_INSTITUTION_ID=org.a")
+	baseB := startServerEnv(t, "ACP_INSTITUTION_ID=org.b")
+	pub, _ := testKeyPair()
+	pubB64 := base64.RawURLEncoding.EncodeToString(pub)
+	for _, p := range []struct{ base, id, peerID, peerURL string }{
+		{baseA, "org.a", "org.b", baseB},
+		{baseB, "org.b", "org.a", baseA},
+	} {
+		status, _, _ := doAdminOf(t, p.id, "POST", p.base+"/acp/v1/crossorg/peers", map[string]interface{}{
+			"institution_id": p.peerID, "endpoint": p.peerURL, "public_key": pubB64,
+		})
+		if status != 201 {
+			t.Fatalf("register peer %s: status=%d", p.peerID, status)
+		}
+	}
+
+	status, _, data := doAdminOf(t, "org.a", "POST", baseA+"/acp/v1/crossorg/outbox", map[string]interface{}{
+		"target_institution_id": "org.b",
+		"events": []map[string]interface{}{{
+			"action_type":      "data_share",
+			"payload_hash":     strings.Repeat("ab", 32),
+			"delegation_chain": []string{"agent-1"},
+			"authorization_id": "auth-1",
+			"ack_required":     true,
+		}},
+	})
+	if status != 202 {
+		t.Fatalf("send: status=%d data=%v", status, data)
+	}
+	bundleID := data["bundle_id"].(string)
+
+	var entry map[string]interface{}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, _, entry = doJSON(t, "GET", baseA+"/acp/v1/crossorg/outbox/"+bundleID, nil)
+		if entry["status"] == "delivered" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("bundle not delivered: %v", entry)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	acks := entry["acks"].([]interface{})
+	if len(acks) != 1 {
+		t.Fatalf("acks=%v, want 1", acks)
+	}
+	ack := acks[0].(map[string]interface{})
+	seq := int64(ack["ledger_sequence"].(float64))
+
+	// The anchored sequence on B is the recorded inbound interaction.
+	status, _, q := doJSON(t, "POST", baseB+"/acp/v1/audit/query", map[string]interface{}{
+		"event_type": "CROSS_ORG_INTERACTION",
+	})
+	if status != 200 {
+		t.Fatalf("audit query: status=%d", status)
+	}
+	events := q["events"].([]interface{})
+	if len(events) != 1 || int64(events[0].(map[string]interface{})["sequence"].(float64)) != seq {
+		t.Errorf("B CROSS_ORG_INTERACTION events=%v, want one at sequence %d", events, seq)
+	}
+
+	// Per-peer listing on both sides.
+	_, _, lb := doJSON(t, "GET", baseB+"/acp/v1/crossorg/peers/org.a/bundles", nil)
+	if in := lb["inbound"].([]interface{}); len(in) != 1 || in[0].(map[string]interface{})["bundle_id"] != bundleID {
+		t.Errorf("B inbound=%v", lb["inbound"])
+	}
+	_, _, la := doJSON(t, "GET", baseA+"/acp/v1/crossorg/peers/org.b/bundles", nil)
+	if out := la["outbound"].([]interface{}); len(out) != 1 {
+		t.Errorf("A outbound=%v", la["outbound"])
+	}
+}
+
+func TestServer_CrossOrg_UnknownPeer(t *testing.T) {
+	base := startServer(t)
+	status, env, _ := doAdmin(t, "POST", base+"/acp/v1/crossorg/outbox", map[string]interface{}{
+		"target_institution_id": "org.nowhere",
+		"events": []map[string]interface{}{{
+			"action_type": "data_share", "payload_hash": strings.Repeat("ab", 32), "delegation_chain": []string{"a"},
+		}},
+	})
+	if status != 403 || env["error"].(map[string]interface{})["code"] != "CROSS-004" {
+		t.Errorf("status=%d env=%v, want 403 CROSS-004", status, env)
+	}
+}
+
+// TestServer_CrossOrg_PeerRegistration checks that peers are registered by
+// an admin only, recorded in the ledger, and re-keyed only on request, and
+// that bundles are sent by an admin only.
+func TestServer_CrossOrg_PeerRegistration(t *testing.T) {
+	base := startServer(t)
+	_, pubB64 := agentKey(0x53)
+	_, otherB64 := agentKey(0x54)
+	peer := map[string]interface{}{"institution_id": "org.peer", "endpoint": "http://peer.invalid", "public_key": pubB64}
+
+	if status, env, _ := doJSON(t, "POST", base+"/acp/v1/crossorg/peers", peer); status != http.StatusUnauthorized || env["error"].(map[string]interface{})["code"] != "AUTH-001" {
+		t.Errorf("register without admin token: status=%d env=%v", status, env)
+	}
+	if status, env, _ := doJSON(t, "POST", base+"/acp/v1/crossorg/outbox", map[string]interface{}{
+		"target_institution_id": "org.peer",
+		"events": []map[string]interface{}{{
+			"action_type": "data_share", "payload_hash": strings.Repeat("ab", 32), "delegation_chain": []string{"a"},
+		}},
+	}); status != http.StatusUnauthorized || env["error"].(map[string]interface{})["code"] != "AUTH-001" {
+		t.Errorf("send without admin token: status=%d env=%v", status, env)
+	}
+
+	if status, _, data := doAdmin(t, "POST", base+"/acp/v1/crossorg/peers", peer); status != http.StatusCreated || data["ledger_event_id"] == "" {
+		t.Fatalf("register: status=%d data=%v", status, data)
+	}
+	rekey := map[string]interface{}{"institution_id": "org.peer", "endpoint": "http://elsewhere.invalid", "public_key": otherB64}
+	if status, env, _ := doAdmin(t, "POST", base+"/acp/v1/crossorg/peers", rekey); status != http.StatusConflict {
+		t.Errorf("re-key without replace: status=%d env=%v, want 409", status, env)
+	}
+	rekey["replace"] = true
+	if status, _, data := doAdmin(t, "POST", base+"/acp/v1/crossorg/peers", rekey); status != http.StatusCreated || data["public_key"] != otherB64 {
+		t.Errorf("re-key with replace: status=%d data=%v", status, data)
+	}
+
+	_, _, q := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{"event_type": ledger.EventCrossOrgPeerRegistered})
+	events, _ := q["events"].([]interface{})
+	if len(events) != 2 {
+		t.Fatalf("CROSS_ORG_PEER_REGISTERED events = %d, want 2", len(events))
+	}
+	for i, ev := range events {
+		payload := ev.(map[string]interface{})["payload"].(map[string]interface{})
+		if payload["registered_by"] != "admin@org.acp.server" || (payload["replaced"] == true) != (i == 1) {
+			t.Errorf("event %d payload = %v", i, payload)
+		}
+	}
+}
+
+func TestServer_WitnessCosignedCheckpoints(t *testing.T) {
+	baseA := startServerEnv(t, "ACP_INSTITUTION_ID=org.a", "ACP_WITNESSES=org.b,org.c", "ACP_WITNESS_QUORUM=2")
+	baseB := startServerEnv(t, "ACP_INSTITUTION_ID=org.b")
+	baseC := startServerEnv(t, "ACP_INSTITUTION_ID=org.c")
+	pub, _ := testKeyPair()
+	pubB64 := base64.RawURLEncoding.EncodeToString(pub)
+	register := func(base, id, peerID, peerURL string) {
+		status, _, _ := doAdminOf(t, id, "POST", base+"/acp/v1/crossorg/peers", map[string]interface{}{
+			"institution_id": peerID, "endpoint": peerURL, "public_key": pubB64,
+		})
+		if status != 201 {
+			t.Fatalf("register peer %s: status=%d", peerID, status)
+		}
+	}
+	register(baseA, "org.a", "org.b", baseB)
+	register(baseB, "org.b", "org.a", baseA)
+
+	// Publication fans out to the witnesses: it takes an admin token.
+	if status, env, _ := doJSON(t, "POST", baseA+"/acp/v1/audit/checkpoints", nil); status != http.StatusUnauthorized || env["error"].(map[string]interface{})["code"] != "AUTH-001" {
+		t.Errorf("publish without admin token: status=%d env=%v", status, env)
+	}
+
+	// org.c is not registered yet: one co-signature of two.
+	status, env, _ := doAdminOf(t, "org.a", "POST", baseA+"/acp/v1/audit/checkpoints", nil)
+	if status != http.StatusServiceUnavailable || env["error"].(map[string]interface{})["code"] != "CROSS-015" {
+		t.Fatalf("without quorum: status=%d env=%v, want 503 CROSS-015", status, env)
+	}
+	if status, _, _ := doJSON(t, "GET", baseA+"/acp/v1/audit/checkpoints/latest", nil); status != http.StatusNotFound {
+		t.Errorf("latest before any checkpoint: status=%d, want 404", status)
+	}
+
+	register(baseA, "org.a", "org.c", baseC)
+	register(baseC, "org.c", "org.a", baseA)
+	status, _, data := doAdminOf(t, "org.a", "POST", baseA+"/acp/v1/audit/checkpoints", nil)
+	if status != http.StatusOK {
+		t.Fatalf("publish: status=%d data=%v", status, data)
+	}
+	var cc crossorg.CosignedCheckpoint
+	remarshal(t, data, &cc)
+	if len(cc.Cosignatures) != 2 || cc.Quorum != 2 {
+		t.Fatalf("checkpoint = %+v", cc)
+	}
+	peers := crossorg.NewPeerRegistry()
+	for _, id := range []string{"org.b", "org.c"} {
+		if err := peers.Put(crossorg.Peer{InstitutionID: id, Endpoint: "http://unused", PublicKey: pub}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := crossorg.VerifyCosignedCheckpoint(cc, []ed25519.PublicKey{pub}, peers, 2); err != nil {
+		t.Errorf("VerifyCosignedCheckpoint: %v", err)
+	}
+
+	// Recorded in the ledger and served as the latest checkpoint.
+	_, _, q := doJSON(t, "POST", baseA+"/acp/v1/audit/query", map[string]interface{}{"event_type": "CHECKPOINT_COSIGNED"})
+	if events := q["events"].([]interface{}); len(events) != 1 ||
+		int64(events[0].(map[string]interface{})["sequence"].(float64)) != cc.LedgerSequence {
+		t.Errorf("CHECKPOINT_COSIGNED events=%v, want one at sequence %d", q["events"], cc.LedgerSequence)
+	}
+	_, _, latest := doJSON(t, "GET", baseA+"/acp/v1/audit/checkpoints/latest", nil)
+	if int64(latest["ledger_sequence"].(float64)) != cc.LedgerSequence {
+		t.Errorf("latest=%v", latest)
+	}
+
+	// Each witness serves the checkpoint it co-signed.
+	_, _, seen := doJSON(t, "GET", baseB+crossorg.WitnessCheckpointsPath+"org.a", nil)
+	if int64(seen["tree_size"].(float64)) != cc.Checkpoint.TreeSize {
+		t.Errorf("witness org.b last checkpoint=%v", seen)
+	}
+}
+
+// ─── ACP-REP-PORTABILITY-1.1 ──────────────────────────────────────────────────
+
+func TestServer_RepImport(t *testing.T) {
+	base := startServer(t)
+	seed := make([]byte, 32)
+	seed[0] = 0x51
+	peerPriv := ed25519.NewKeyFromSeed(seed)
+	_, peerPubB64 := agentKey(0x51)
+	status, _, _ := doAdmin(t, "POST", base+"/acp/v1/crossorg/peers", map[string]interface{}{
+		"institution_id": "org.peer", "endpoint": "http://peer.invalid", "public_key": peerPubB64,
+	})
+	if status != 201 {
+		t.Fatalf("register peer: status=%d", status)
+	}
+	capture := func(issuer string) *reputation.ReputationSnapshot {
+		rep, err := reputation.Capture(reputation.CaptureRequest{
+			SubjectID: "agent-imported", Issuer: issuer, Score: 80, Scale: "0-100",
+			ModelID: "peer-1", ValidFor: 5 * time.Minute,
+		}, peerPriv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rep
+	}
+	url := base + "/acp/v1/rep/agent-imported/import"
+
+	rep := capture("org.peer")
+	status, _, data := doJSON(t, "POST", url, map[string]interface{}{"snapshot": rep})
+	if status != 200 {
+		t.Fatalf("import: status=%d data=%v", status, data)
+	}
+	if prior := data["prior"].(map[string]interface{}); prior["score"].(float64) != 0.8 {
+		t.Errorf("prior=%v, want normalized score 0.8", prior)
+	}
+
+	_, _, rec := doJSON(t, "GET", base+"/acp/v1/rep/agent-imported", nil)
+	if rec["score"] != nil || rec["prior"] == nil {
+		t.Errorf("record=%v, want score null and prior set", rec)
+	}
+
+	status, env, _ := doJSON(t, "POST", url, map[string]interface{}{"snapshot": rep})
+	if status != 409 || env["error"].(map[string]interface{})["code"] != "AUTH-007" {
+		t.Errorf("replay: status=%d env=%v, want 409 AUTH-007", status, env)
+	}
+	status, env, _ = doJSON(t, "POST", url, map[string]interface{}{"snapshot": capture("org.unknown")})
+	if status != 403 || env["error"].(map[string]interface{})["code"] != "CROSS-004" {
+		t.Errorf("unknown issuer: status=%d env=%v, want 403 CROSS-004", status, env)
+	}
+	forged := capture("org.peer")
+	forged.Score = 99
+	status, env, _ = doJSON(t, "POST", url, map[string]interface{}{"snapshot": forged})
+	if status != 422 || env["error"].(map[string]interface{})["code"] != "REP-010" {
+		t.Errorf("forged: status=%d env=%v, want 422 REP-010", status, env)
+	}
+}
+
+// ─── Agent key rotation ───────────────────────────────────────────────────────
+
+func TestServer_AgentKeyRotation(t *testing.T) {
+	base := startServer(t)
+	agentID := "rotating-agent"
+	seed := func(b byte) ed25519.PrivateKey {
+		s := make([]byte, 32)
+		s[0] = b
+		return ed25519.NewKeyFromSeed(s)
+	}
+	oldPriv, newPriv := seed(0x91), seed(0x92)
+	_, oldB64 := agentKey(0x91)
+	_, newB64 := agentKey(0x92)
+	doJSON(t, http.MethodPost, base+"/acp/v1/agents", map[string]interface{}{
+		"agent_id": agentID, "public_key": oldB64,
+	})
+	_, _, keys := doJSON(t, http.MethodGet, base+"/acp/v1/agents/"+agentID+"/keys", nil)
+	oldKID, _ := keys["current_kid"].(string)
+
+	st := registry.RotationStatement{
+		Ver: registry.RotationStatementVersion, AgentID: agentID, OldKID: oldKID,
+		NewPublicKey: newB64, RotatedAt: time.Now().Unix(), OverlapPeriod: 600,
+	}
+	oldSig, _ := registry.SignRotation(st, oldPriv)
+	newSig, _ := registry.SignRotation(st, newPriv)
+	body := map[string]interface{}{"statement": st, "sig": oldSig, "new_key_sig": newSig}
+
+	status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/agents/"+agentID+"/keys/rotate", body)
+	if status != http.StatusOK {
+		t.Fatalf("rotate: status=%d data=%v", status, data)
+	}
+	newKID, _ := data["new_kid"].(string)
+	if newKID == "" || newKID == oldKID || data["old_key_valid_until"] != float64(st.RotatedAt+600) {
+		t.Errorf("rotate response = %v", data)
+	}
+
+	// Replaying the same statement names a retired key.
+	if status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/agents/"+agentID+"/keys/rotate", body); status != http.StatusForbidden || env["error"].(map[string]interface{})["code"] != "AGENT-006" {
+		t.Errorf("replay: status=%d env=%v", status, env)
+	}
+
+	_, _, rec := doJSON(t, http.MethodGet, base+"/acp/v1/agents/"+agentID, nil)
+	if hist, _ := rec["keys"].([]interface{}); len(hist) != 2 {
+		t.Errorf("agent keys = %v, want 2 entries", rec["keys"])
+	}
+
+	_, _, q := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{
+		"event_type": "AGENT_KEY_ROTATED",
+	})
+	events, _ := q["events"].([]interface{})
+	if len(events) != 1 {
+		t.Fatalf("AGENT_KEY_ROTATED events = %d, want 1", len(events))
+	}
+	payload := events[0].(map[string]interface{})["payload"].(map[string]interface{})
+	if payload["old_kid"] != oldKID || payload["new_kid"] != newKID || payload["sig"] != oldSig {
+		t.Errorf("ledger payload = %v", payload)
+	}
+}
+
+// ─── DID resolution ───────────────────────────────────────────────────────────
+
+func TestServer_DIDResolve(t *testing.T) {
+	base := startServer(t)
+	_, pubB64 := agentKey(0x93)
+	doJSON(t, http.MethodPost, base+"/acp/v1/agents", map[string]interface{}{
+		"agent_id": "did-agent", "public_key": pubB64,
+	})
+
+	status, _, doc := doJSON(t, http.MethodGet, base+"/acp/v1/did/did:acpd:did-agent", nil)
+	if status != http.StatusOK || doc["id"] != "did:acpd:did-agent" {
+		t.Fatalf("resolve did:acpd: status=%d doc=%v", status, doc)
+	}
+	if vms, _ := doc["verificationMethod"].([]interface{}); len(vms) != 1 {
+		t.Errorf("verificationMethod = %v, want one key", doc["verificationMethod"])
+	}
+
+	// The institution resolves through its keyring.
+	if status, _, _ := doJSON(t, http.MethodGet, base+"/acp/v1/did/did:acpd:org.acp.server", nil); status != http.StatusOK {
+		t.Errorf("resolve institution DID: status=%d", status)
+	}
+	if status, env, _ := doJSON(t, http.MethodGet, base+"/acp/v1/did/did:acpd:nobody", nil); status != http.StatusNotFound || env["error"].(map[string]interface{})["code"] != "DID-002" {
+		t.Errorf("unknown did:acpd: status=%d env=%v", status, env)
+	}
+	if status, env, _ := doJSON(t, http.MethodGet, base+"/acp/v1/did/did:example:1", nil); status != http.StatusBadRequest || env["error"].(map[string]interface{})["code"] != "DID-001" {
+		t.Errorf("unsupported method: status=%d env=%v", status, env)
+	}
+}
+
+// TestServer_DIDWeb_NoFetchByDefault checks that did:web documents are not
+// fetched unless their host is allowlisted.
+func TestServer_DIDWeb_NoFetchByDefault(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	var mu sync.Mutex
+	conns := 0
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns++
+			mu.Unlock()
+			c.Close()
+		}
+	}()
+	id := "did:web:" + strings.ReplaceAll(ln.Addr().String(), ":", "%3A")
+
+	for _, base := range []string{
+		startServer(t),
+		startServerEnv(t, "ACP_DID_WEB_HOSTS=example.com"),
+	} {
+		if status, env, _ := doJSON(t, http.MethodGet, base+"/acp/v1/did/"+url.PathEscape(id), nil); status != http.StatusNotFound || env["error"].(map[string]interface{})["code"] != "DID-002" {
+			t.Errorf("resolve %s: status=%d env=%v", id, status, env)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 0 {
+		t.Errorf("%d connections to a host outside the allowlist", conns)
+	}
+}
+
+// TestServer_DIDIssuer_TrustAnchor checks that a token issued by a did:key
+// verifies only if that DID is listed in ACP_TRUSTED_DIDS.
+func TestServer_DIDIssuer_TrustAnchor(t *testing.T) {
+	_, instPriv := testKeyPair()
+	seed := make([]byte, 32)
+	seed[0] = 0x95
+	keyPriv := ed25519.NewKeyFromSeed(seed)
+	issuer := did.EncodeKey(keyPriv.Public().(ed25519.PublicKey))
+
+	now := time.Now().Unix()
+	root := tokens.CapabilityToken{
+		Version: "1.0", Issuer: "org.acp.server", Subject: issuer,
+		Cap: []string{"acp:cap:data.read"}, Resource: "metrics",
+		IssuedAt: now, Expiration: now + 3600, Nonce: "did-root",
+		Deleg: tokens.Delegation{Allowed: true, MaxDepth: 2},
+	}
+	rootHash, _ := tokens.ComputeTokenHash(&root)
+	leaf := tokens.CapabilityToken{
+		Version: "1.0", Issuer: issuer, Subject: "did-leaf-agent",
+		Cap: []string{"acp:cap:data.read"}, Resource: "metrics/public",
+		IssuedAt: now, Expiration: now + 1800, Nonce: "did-leaf",
+		ParentHash: &rootHash,
+	}
+	chain := []json.RawMessage{signCT(t, root, instPriv), signCT(t, leaf, keyPriv)}
+	authorize := func(base string) (int, map[string]interface{}, map[string]interface{}) {
+		return doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+			"agent_id": "did-leaf-agent", "capability": "acp:cap:data.read",
+			"resource": "metrics/public", "delegation_chain": chain,
+		})
+	}
+
+	if status, env, _ := authorize(startServer(t)); status != http.StatusForbidden || env["error"].(map[string]interface{})["code"] != "AUTH-001" {
+		t.Errorf("untrusted did:key issuer: status=%d env=%v", status, env)
+	}
+	if status, _, data := authorize(startServerEnv(t, "ACP_TRUSTED_DIDS="+issuer)); status != http.StatusOK || data["decision"] != "APPROVED" {
+		t.Errorf("trusted did:key issuer: status=%d data=%v", status, data)
+	}
+}
+
+func TestServer_PayVerify(t *testing.T) {
+	base := startServer(t)
+	providerPub, _ := agentKey(0x94)
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = 0x94
+	providerPriv := ed25519.NewKeyFromSeed(seed)
+
+	provider := map[string]interface{}{
+		"provider_id": "bank-ledger", "type": pay.ProofCorporateLedger,
+		"public_key": base64.RawURLEncoding.EncodeToString(providerPub),
+	}
+	if status, _, _ := doJSON(t, http.MethodPost, base+"/acp/v1/pay/providers", provider); status != http.StatusUnauthorized {
+		t.Errorf("register provider without admin token: status=%d, want 401", status)
+	}
+	if status, _, data := doAdmin(t, http.MethodPost, base+"/acp/v1/pay/providers", provider); status != http.StatusCreated || data["ledger_event_id"] == "" {
+		t.Fatalf("register provider: status=%d data=%v", status, data)
+	}
+	// The provider cannot be re-keyed.
+	otherPub, _ := agentKey(0x96)
+	provider["public_key"] = base64.RawURLEncoding.EncodeToString(otherPub)
+	if status, _, _ := doAdmin(t, http.MethodPost, base+"/acp/v1/pay/providers", provider); status != http.StatusConflict {
+		t.Errorf("re-register provider: status=%d, want 409", status)
+	}
+	_, _, q := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{"event_type": "PAYMENT_PROVIDER_REGISTERED"})
+	if events, _ := q["events"].([]interface{}); len(events) != 1 {
+		t.Errorf("PAYMENT_PROVIDER_REGISTERED events = %d, want 1", len(events))
+	}
+
+	now := time.Now().Unix()
+	payReq := func(proofID string, amount float64) map[string]interface{} {
+		proof := pay.SettlementProof{
+			ProofID: proofID, Type: pay.ProofCorporateLedger, ProviderID: "bank-ledger", Amount: amount,
+			Currency: "USD", Recipient: "agent-seller", Timestamp: now,
+		}
+		proof.Sig, _ = pay.SignProof(proof, providerPriv)
+		return map[string]interface{}{
+			"token": pay.ACPPayToken{
+				CapabilityClaim:  "cap-1",
+				PaymentCondition: pay.PaymentCondition{Amount: 100, Currency: "USD", Recipient: "agent-seller", ExpiresAt: now + 600},
+				Proof:            proof,
+			},
+			"agent_id": "agent-buyer", "resource": "org.example/report", "capability_id": "cap-1",
+		}
+	}
+
+	status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/pay/verify", payReq("proof-1", 100))
+	if status != http.StatusOK || data["status"] != "verified" || data["ledger_event_id"] == "" {
+		t.Fatalf("verify: status=%d data=%v", status, data)
+	}
+	if ev, _ := data["event"].(map[string]interface{}); ev["prev_hash"] != pay.GenesisHash {
+		t.Errorf("first event prev_hash = %v", ev["prev_hash"])
+	}
+
+	status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/payment/verify", payReq("proof-1", 100))
+	if status != http.StatusConflict || env["error"].(map[string]interface{})["code"] != "PAY-005" {
+		t.Errorf("double spend: status=%d env=%v", status, env)
+	}
+	status, env, _ = doJSON(t, http.MethodPost, base+"/acp/v1/pay/verify", payReq("proof-2", 50))
+	if status != http.StatusPaymentRequired || env["error"].(map[string]interface{})["code"] != "PAY-003" {
+		t.Errorf("underpaid: status=%d env=%v", status, env)
+	}
+	forged := payReq("proof-3", 100)
+	tok := forged["token"].(pay.ACPPayToken)
+	tok.Proof.Recipient = "agent-buyer"
+	forged["token"] = tok
+	status, env, _ = doJSON(t, http.MethodPost, base+"/acp/v1/pay/verify", forged)
+	if status != http.StatusPaymentRequired || env["error"].(map[string]interface{})["code"] != "PAY-002" {
+		t.Errorf("tampered proof: status=%d env=%v", status, env)
+	}
+
+	status, _, data = doJSON(t, http.MethodGet, base+"/acp/v1/pay/proofs/proof-1", nil)
+	if status != http.StatusOK || data["recipient"] != "agent-seller" || data["type"] != pay.ProofCorporateLedger {
+		t.Errorf("proof lookup: status=%d data=%v", status, data)
+	}
+	if status, _, _ := doJSON(t, http.MethodGet, base+"/acp/v1/payment/proof-2", nil); status != http.StatusNotFound {
+		t.Errorf("rejected proof lookup: status=%d, want 404", status)
+	}
+	_, _, data = doJSON(t, http.MethodGet, base+"/acp/v1/pay/proofs?agent_id=agent-buyer", nil)
+	if proofs, _ := data["proofs"].([]interface{}); len(proofs) != 1 {
+		t.Errorf("proofs by agent = %v, want one", data["proofs"])
+	}
+}
+
+func TestServer_SpendBudgets(t *testing.T) {
+	base := startServer(t)
+	systemPriv := registerTarget(t, base, "sys-budget", 0x95)
+
+	status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/policy/budgets", map[string]interface{}{
+		"budgets": []map[string]interface{}{{"budget_id": "b", "scope": "tenant", "limit": 1, "window_seconds": 1}},
+	})
+	if status != http.StatusBadRequest || env["error"].(map[string]interface{})["code"] != "PSN-008" {
+		t.Errorf("invalid budget: status=%d env=%v", status, env)
+	}
+	status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/policy/budgets", map[string]interface{}{
+		"budgets": []map[string]interface{}{{
+			"budget_id": "agent-hourly", "scope": "agent", "currency": "USD", "limit": 2000, "window_seconds": 3600,
+		}},
+	})
+	if status != http.StatusOK || data["snapshot_id"] == data["superseded_snapshot_id"] {
+		t.Fatalf("set budgets: status=%d data=%v", status, data)
+	}
+
+	authorize := func(n int) map[string]interface{} {
+		_, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+			"request_id":        fmt.Sprintf("budget-%d", n),
+			"agent_id":          "budget-agent",
+			"capability":        "acp:cap:data.read",
+			"resource":          "metrics/public",
+			"action_parameters": map[string]interface{}{"amount": 900, "currency": "USD"},
+		})
+		return data
+	}
+	nearLimit := func(data map[string]interface{}) bool {
+		_, ok := riskFactors(t, data)["history/amount_near_limit"]
+		return ok
+	}
+
+	first := authorize(1)
+	if first["decision"] != "APPROVED" || nearLimit(first) {
+		t.Fatalf("first: %v", first)
+	}
+	second := authorize(2)
+	if second["decision"] != "APPROVED" || !nearLimit(second) {
+		t.Errorf("second (1800 of 2000): want APPROVED with amount_near_limit, got %v", second)
+	}
+	third := authorize(3)
+	if third["decision"] != "DENIED" || third["reason_code"] != "RISK-010" {
+		t.Errorf("third (2700 of 2000): want DENIED RISK-010, got %v", third)
+	}
+
+	_, _, data = doJSON(t, http.MethodGet, base+"/acp/v1/budgets/usage?scope=agent&subject=budget-agent", nil)
+	usage, _ := data["usage"].([]interface{})
+	if len(usage) != 1 || usage[0].(map[string]interface{})["approved"] != 1800.0 {
+		t.Errorf("usage = %v", data["usage"])
+	}
+
+	// Amounts that are not positive numbers and currencies the budgets do not
+	// count are denied; a currency code in another case is the same currency.
+	for i, params := range []map[string]interface{}{
+		{"amount": -5000, "currency": "USD"},
+		{"amount": 0, "currency": "USD"},
+		{"amount": "900", "currency": "USD"},
+		{"amount": 100, "currency": "EUR"},
+		{"amount": 100},
+		{"amount": 900, "currency": "usd"},
+	} {
+		_, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+			"request_id":        fmt.Sprintf("budget-invalid-%d", i),
+			"agent_id":          "budget-agent",
+			"capability":        "acp:cap:data.read",
+			"resource":          "metrics/public",
+			"action_parameters": params,
+		})
+		want := "RISK-010"
+		if i < 3 {
+			want = "SYS-004"
+		}
+		// Only the lower-case USD request is counted, and so exceeds the budget.
+		if data["decision"] != "DENIED" || data["reason_code"] != want || (data["budget"] != nil) != (i == 5) {
+			t.Errorf("%v: want DENIED %s, got %v", params, want, data)
+		}
+	}
+
+	// A failed execution releases its reservation.
+	etID := first["execution_token"].(map[string]interface{})["et_id"].(string)
+	if status, data := consumeET(t, base, etID, "sys-budget", systemPriv, execution.ConsumeRequest{
+		ExecutionResult:  "failure",
+		ActionParameters: map[string]interface{}{"amount": 900, "currency": "USD"},
+	}); status != http.StatusOK {
+		t.Fatalf("consume: status=%d data=%v", status, data)
+	}
+	// The earlier denial now weighs on F_hist, so only the budget outcome is checked.
+	if fourth := authorize(4); fourth["reason_code"] == "RISK-010" {
+		t.Errorf("after release: still over budget: %v", fourth)
+	}
+}
+
+// ─── ACP-PROVENANCE-1.0: Authority provenance ─────────────────────────────────
+
+// signCT returns tok signed by priv as an ACP-CT-1.0 token (JSON).
+func signCT(t *testing.T, tok tokens.CapabilityToken, priv ed25519.PrivateKey) json.RawMessage {
+	t.Helper()
+	tok.Signature = ""
+	raw, _ := json.Marshal(tok)
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(canonical)
+	tok.Signature = base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, digest[:]))
+	out, _ := json.Marshal(tok)
+	return out
+}
+
+// TestServer_Provenance_CapturedOnApproval checks that an approved request
+// with a delegation chain yields a signed AuthorityProvenance bound to its ET
+// and recorded in the ledger, and that a request without one yields a
+// minimal provenance.
+func TestServer_Provenance_CapturedOnApproval(t *testing.T) {
+	base := startServer(t)
+	instPub, instPriv := testKeyPair()
+	_, delegB64 := agentKey(0xA1)
+	delegSeed := make([]byte, 32)
+	delegSeed[0] = 0xA1
+	delegPriv := ed25519.NewKeyFromSeed(delegSeed)
+	doJSON(t, http.MethodPost, base+"/acp/v1/agents", map[string]interface{}{
+		"agent_id": "prov-delegator", "public_key": delegB64,
+	})
+
+	now := time.Now().Unix()
+	root := tokens.CapabilityToken{
+		Version: "1.0", Issuer: "org.acp.server", Subject: "prov-delegator",
+		Cap: []string{"acp:cap:data.read"}, Resource: "metrics",
+		IssuedAt: now, Expiration: now + 3600, Nonce: "prov-root",
+		Deleg: tokens.Delegation{Allowed: true, MaxDepth: 2},
+	}
+	rootHash, _ := tokens.ComputeTokenHash(&root)
+	leaf := tokens.CapabilityToken{
+		Version: "1.0", Issuer: "prov-delegator", Subject: "prov-agent",
+		Cap: []string{"acp:cap:data.read"}, Resource: "metrics/public",
+		IssuedAt: now, Expiration: now + 1800, Nonce: "prov-leaf",
+		ParentHash: &rootHash,
+	}
+	authorize := func(requestID string, chain ...json.RawMessage) (int, map[string]interface{}) {
+		status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+			"request_id":       requestID,
+			"agent_id":         "prov-agent",
+			"capability":       "acp:cap:data.read",
+			"resource":         "metrics/public",
+			"delegation_chain": chain,
+		})
+		return status, data
+	}
+
+	status, data := authorize("req-prov-1", signCT(t, root, instPriv), signCT(t, leaf, delegPriv))
+	if status != http.StatusOK || data["decision"] != "APPROVED" {
+		t.Fatalf("authorize: status=%d data=%v", status, data)
+	}
+	etID, _ := data["execution_token"].(map[string]interface{})["et_id"].(string)
+
+	status, _, got := doJSON(t, http.MethodGet, base+"/acp/v1/provenance/by-et/"+etID, nil)
+	if status != http.StatusOK {
+		t.Fatalf("provenance by ET: status=%d data=%v", status, got)
+	}
+	raw, _ := json.Marshal(got)
+	var ap provenance.AuthorityProvenance
+	if err := json.Unmarshal(raw, &ap); err != nil {
+		t.Fatal(err)
+	}
+	if ap.ProvenanceID != data["provenance_id"] || ap.ExecutionID != etID {
+		t.Errorf("provenance %s for %s, want %v for %s", ap.ProvenanceID, ap.ExecutionID, data["provenance_id"], etID)
+	}
+	if ap.Principal != "org.acp.server" || ap.Executor != "prov-agent" || len(ap.Chain) != 2 ||
+		ap.Chain[1].Delegator != "prov-delegator" || ap.Chain[1].DelegationID != "prov-leaf" {
+		t.Errorf("provenance chain = %+v", ap)
+	}
+	if ap.PolicyRef == "" || len(ap.PolicyHash) != 64 {
+		t.Errorf("policy_ref=%q policy_hash=%q", ap.PolicyRef, ap.PolicyHash)
+	}
+	if err := provenance.VerifySig(ap, instPub); err != nil {
+		t.Errorf("VerifySig: %v", err)
+	}
+
+	// Without a chain: minimal provenance, institution as direct principal.
+	plainET := approveET(t, base, "prov-plain")
+	_, _, minimal := doJSON(t, http.MethodGet, base+"/acp/v1/provenance/by-et/"+plainET, nil)
+	if chain, ok := minimal["chain"].([]interface{}); !ok || len(chain) != 0 || minimal["principal"] != "org.acp.server" {
+		t.Errorf("minimal provenance = %v", minimal)
+	}
+
+	_, _, q := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{"event_type": "PROVENANCE"})
+	if events, _ := q["events"].([]interface{}); len(events) != 2 {
+		t.Errorf("PROVENANCE events = %d, want 2", len(events))
+	}
+}
+
+func TestServer_Provenance_InvalidChainRejected(t *testing.T) {
+	base := startServer(t)
+	_, instPriv := testKeyPair()
+	now := time.Now().Unix()
+	root := tokens.CapabilityToken{
+		Version: "1.0", Issuer: "org.acp.server", Subject: "prov-agent",
+		Cap: []string{"acp:cap:data.read"}, Resource: "metrics",
+		IssuedAt: now, Expiration: now + 3600, Nonce: "prov-bad",
+	}
+	cases := []struct {
+		name  string
+		chain json.RawMessage
+		cap   string
+		code  string
+	}{
+		{"forged root", signCT(t, root, ed25519.NewKeyFromSeed(make([]byte, 32))), "acp:cap:data.read", "AUTH-001"},
+		{"capability not granted", signCT(t, root, instPriv), "acp:cap:data.write", "AUTH-002"},
+	}
+	for _, tc := range cases {
+		status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+			"request_id": "req-" + tc.name, "agent_id": "prov-agent",
+			"capability": tc.cap, "resource": "metrics/public",
+			"delegation_chain": []json.RawMessage{tc.chain},
+		})
+		code, _ := env["error"].(map[string]interface{})["code"].(string)
+		if status != http.StatusForbidden || code != tc.code {
+			t.Errorf("%s: status=%d code=%q, want 403 %s", tc.name, status, code, tc.code)
+		}
+	}
+}
+
+// TestServer_Verify_InstitutionRooted checks that /verify accepts tokens
+// issued by the institution, and tokens issued by an agent only as the leaf
+// of an institution-rooted delegation chain.
+func TestServer_Verify_InstitutionRooted(t *testing.T) {
+	base := startServer(t)
+	_, instPriv := testKeyPair()
+	seed := func(b byte) ed25519.PrivateKey {
+		s := make([]byte, 32)
+		s[0] = b
+		return ed25519.NewKeyFromSeed(s)
+	}
+	agentPriv, delegPriv := seed(0xB1), seed(0xB2)
+	_, agentB64 := agentKey(0xB1)
+	_, delegB64 := agentKey(0xB2)
+	doJSON(t, http.MethodPost, base+"/acp/v1/agents", map[string]interface{}{"agent_id": "vf-agent", "public_key": agentB64})
+	doJSON(t, http.MethodPost, base+"/acp/v1/agents", map[string]interface{}{"agent_id": "vf-delegator", "public_key": delegB64})
+
+	verify := func(tok json.RawMessage, chain ...json.RawMessage) (int, string) {
+		t.Helper()
+		_, _, ch := doJSON(t, http.MethodGet, base+"/acp/v1/handshake/challenge", nil)
+		challenge, _ := ch["challenge"].(string)
+		var body interface{}
+		var raw []byte
+		if len(chain) > 0 {
+			body = map[string]interface{}{"delegation_chain": chain}
+			raw, _ = json.Marshal(body)
+		}
+		bodyHash := sha256.Sum256(raw)
+		payload := sha256.Sum256([]byte("POST|/acp/v1/verify|" + challenge + "|" + base64.RawURLEncoding.EncodeToString(bodyHash[:])))
+		status, env, _ := doJSONHeaders(t, http.MethodPost, base+"/acp/v1/verify", map[string]string{
+			"Authorization":   "Bearer " + string(tok),
+			"X-ACP-Agent-ID":  "vf-agent",
+			"X-ACP-Challenge": challenge,
+			"X-ACP-Signature": base64.RawURLEncoding.EncodeToString(ed25519.Sign(agentPriv, payload[:])),
+		}, body)
+		code := ""
+		if e, ok := env["error"].(map[string]interface{}); ok {
+			code, _ = e["code"].(string)
+		}
+		return status, code
+	}
+
+	now := time.Now().Unix()
+	ct := func(iss, sub, nonce string) tokens.CapabilityToken {
+		return tokens.CapabilityToken{
+			Version: "1.0", Issuer: iss, Subject: sub,
+			Cap: []string{"acp:cap:data.read"}, Resource: "metrics",
+			IssuedAt: now, Expiration: now + 600, Nonce: nonce,
+		}
+	}
+
+	if status, code := verify(signCT(t, ct("org.acp.server", "vf-agent", "vf-inst"), instPriv)); status != http.StatusOK {
+		t.Errorf("institution token: status=%d code=%s", status, code)
+	}
+
+	root := ct("org.acp.server", "vf-delegator", "vf-root")
+	root.Deleg = tokens.Delegation{Allowed: true, MaxDepth: 2}
+	rootHash, _ := tokens.ComputeTokenHash(&root)
+	leaf := func(nonce string) json.RawMessage {
+		l := ct("vf-delegator", "vf-agent", nonce)
+		l.ParentHash = &rootHash
+		return signCT(t, l, delegPriv)
+	}
+
+	// An agent-issued token on its own is not accepted.
+	if status, code := verify(leaf("vf-leaf-1")); status != http.StatusForbidden || code != "AUTH-001" {
+		t.Errorf("agent-issued token without chain: status=%d code=%s, want 403 AUTH-001", status, code)
+	}
+	// Nor under a chain rooted at the agent itself.
+	selfRoot := ct("vf-delegator", "vf-delegator", "vf-self")
+	selfRoot.Deleg = tokens.Delegation{Allowed: true, MaxDepth: 2}
+	if status, code := verify(leaf("vf-leaf-2"), signCT(t, selfRoot, delegPriv)); status != http.StatusForbidden || code != "AUTH-001" {
+		t.Errorf("self-rooted chain: status=%d code=%s, want 403 AUTH-001", status, code)
+	}
+	// Under an institution-rooted chain it is.
+	if status, code := verify(leaf("vf-leaf-3"), signCT(t, root, instPriv)); status != http.StatusOK {
+		t.Errorf("institution-rooted chain: status=%d code=%s", status, code)
+	}
+}
+
+// TestServer_FailClosedAudit breaks the ledger file under a running server:
+// decisions and consumptions are withheld with SYS-003 and nothing they
+// would grant is released, until the ledger accepts writes again.
+func TestServer_FailClosedAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	base := startServerEnv(t, "ACP_LEDGER_PATH="+path)
+	priv := registerTarget(t, base, "sys-metrics", 0x50)
+	etID := approveET(t, base, "fc-agent")
+	providerPub, _ := agentKey(0x97)
+	providerSeed := make([]byte, ed25519.SeedSize)
+	providerSeed[0] = 0x97
+	if status, _, _ := doAdmin(t, http.MethodPost, base+"/acp/v1/pay/providers", map[string]interface{}{
+		"provider_id": "fc-bank", "type": pay.ProofCorporateLedger,
+		"public_key": base64.RawURLEncoding.EncodeToString(providerPub),
+	}); status != http.StatusCreated {
+		t.Fatalf("register provider: status=%d", status)
+	}
+	now := time.Now().Unix()
+	proof := pay.SettlementProof{
+		ProofID: "fc-proof", Type: pay.ProofCorporateLedger, ProviderID: "fc-bank", Amount: 10,
+		Currency: "USD", Recipient: "fc-seller", Timestamp: now,
+	}
+	proof.Sig, _ = pay.SignProof(proof, ed25519.NewKeyFromSeed(providerSeed))
+	payReq := map[string]interface{}{
+		"token": pay.ACPPayToken{
+			CapabilityClaim:  "cap-1",
+			PaymentCondition: pay.PaymentCondition{Amount: 10, Currency: "USD", Recipient: "fc-seller", ExpiresAt: now + 600},
+			Proof:            proof,
+		},
+		"agent_id": "fc-agent", "resource": "org.example/report", "capability_id": "cap-1",
+	}
+	escID := escalate(t, base, "fc-escalated-agent", "fc-escalated")
+
+	// A directory in place of the ledger file makes every commit fail.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/authorize", map[string]interface{}{
+		"request_id": "fc-lost",
+		"agent_id":   "fc-agent",
+		"capability": "acp:cap:data.read",
+		"resource":   "metrics/public",
+	})
+	if status != http.StatusServiceUnavailable || env["error"].(map[string]interface{})["code"] != "SYS-003" {
+		t.Fatalf("authorize with ledger down: status=%d env=%v", status, env)
+	}
+	status, env, _ = doJSON(t, http.MethodPost, base+"/acp/v1/authorize/batch", map[string]interface{}{
+		"batch_id": "fc-batch",
+		"items": []map[string]interface{}{
+			{"request_id": "fc-b0", "agent_id": "fc-agent", "action_type": "acp:cap:data.read", "resource": "metrics/public"},
+		},
+	})
+	results, _ := env["data"].(map[string]interface{})["results"].([]interface{})
+	if status != http.StatusMultiStatus || len(results) != 1 ||
+		results[0].(map[string]interface{})["reason_code"] != "SYS-003" ||
+		results[0].(map[string]interface{})["execution_token"] != nil {
+		t.Fatalf("batch with ledger down: status=%d results=%v", status, results)
+	}
+
+	status, _ = consumeET(t, base, etID, "sys-metrics", priv, execution.ConsumeRequest{ExecutionResult: "success"})
+	if status != http.StatusServiceUnavailable {
+		t.Fatalf("consume with ledger down: got %d, want 503", status)
+	}
+	if status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/pay/verify", payReq); status != http.StatusServiceUnavailable || env["error"].(map[string]interface{})["code"] != "SYS-003" {
+		t.Fatalf("pay verify with ledger down: status=%d env=%v", status, env)
+	}
+	if status, env, _ := doResolver(t, http.MethodPost, base+"/acp/v1/authorize/escalations/"+escID+"/resolve", map[string]interface{}{
+		"resolution": "DENIED",
+	}); status != http.StatusServiceUnavailable || env["error"].(map[string]interface{})["code"] != "SYS-003" {
+		t.Fatalf("resolve with ledger down: status=%d env=%v", status, env)
+	}
+	_, _, st := doJSON(t, http.MethodGet, base+"/acp/v1/exec-tokens/"+etID+"/status", nil)
+	if st["state"] != "issued" {
+		t.Errorf("ET state after failed consume = %v, want issued", st["state"])
+	}
+	_, health, _ := doJSON(t, http.MethodGet, base+"/acp/v1/health", nil)
+	if health["status"] != "degraded" || health["components"].(map[string]interface{})["audit_ledger"] != "unavailable" {
+		t.Errorf("health with ledger down = %v", health)
+	}
+
+	// Recovery: the next commit recreates the file.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	status, consumed := consumeET(t, base, etID, "sys-metrics", priv, execution.ConsumeRequest{ExecutionResult: "success"})
+	if status != http.StatusOK || consumed["liability_id"] == nil {
+		t.Fatalf("consume after recovery: status=%d data=%v", status, consumed)
+	}
+	_, _, q := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{
+		"event_type": "AUTHORIZATION",
+		"agent_id":   "fc-agent",
+	})
+	events, _ := q["events"].([]interface{})
+	if len(events) != 1 {
+		t.Errorf("AUTHORIZATION events = %d, want only the one before the outage", len(events))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || strings.Count(string(data), "\n") != 2 {
+		t.Errorf("ledger file after recovery: %d lines, err=%v; want the 2 consumption events",
+			strings.Count(string(data), "\n"), err)
+	}
+	// The proof was not spent by the failed verification.
+	if status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/pay/verify", payReq); status != http.StatusOK {
+		t.Errorf("pay verify after recovery: status=%d data=%v", status, data)
+	}
+}
+
+// ─── ACP-LEDGER-1.3 §5: Payload schemas ──────────────────────────────────────
+
+// TestServer_LedgerPayloadSchemas checks that every event the server records
+// passes strict schema verification, also when strict mode rejects
+// incomplete appends, and that resolver_type is validated.
+func TestServer_LedgerPayloadSchemas(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	base := startServerEnv(t, "ACP_LEDGER_PATH="+path, "ACP_LEDGER_SCHEMA=strict")
+	_, pubB64 := agentKey(0x61)
+	if status, _, _ := doJSON(t, http.MethodPost, base+"/acp/v1/agents", map[string]interface{}{
+		"agent_id":         "schema-agent",
+		"public_key":       pubB64,
+		"autonomy_level":   2,
+		"authority_domain": "finance",
+	}); status != http.StatusCreated {
+		t.Fatalf("register: got %d", status)
+	}
+	priv := registerTarget(t, base, "sys-schema", 0x62)
+	etID := approveET(t, base, "schema-agent")
+	if status, data := consumeET(t, base, etID, "sys-schema", priv, execution.ConsumeRequest{ExecutionResult: "success"}); status != http.StatusOK {
+		t.Fatalf("consume: status=%d data=%v", status, data)
+	}
+	if status, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/policy/budgets", map[string]interface{}{
+		"budgets": []map[string]interface{}{{"budget_id": "b", "scope": "agent", "limit": 100, "window_seconds": 60}},
+	}); status != http.StatusOK {
+		t.Fatalf("set budgets: status=%d data=%v", status, data)
+	}
+	escID := escalate(t, base, "schema-agent", "req-schema-escalated")
+	status, env, _ := doResolver(t, http.MethodPost, base+"/acp/v1/authorize/escalations/"+escID+"/resolve", map[string]interface{}{
+		"resolution": "DENIED", "resolver_type": "oracle",
+	})
+	if status != http.StatusBadRequest || env["error"].(map[string]interface{})["code"] != "SYS-004" {
+		t.Errorf("bad resolver_type: status=%d env=%v", status, env)
+	}
+	if status, _, data := doResolver(t, http.MethodPost, base+"/acp/v1/authorize/escalations/"+escID+"/resolve", map[string]interface{}{
+		"resolution": "DENIED",
+	}); status != http.StatusOK {
+		t.Fatalf("resolve: status=%d data=%v", status, data)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []ledger.Event
+	types := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(raw)), "\n") {
+		var ev ledger.Event
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			t.Fatalf("ledger line %q: %v", line, err)
+		}
+		events = append(events, ev)
+		types[ev.EventType] = true
+	}
+	for _, et := range []string{
+		ledger.EventAgentRegistered, ledger.EventRiskEvaluation, ledger.EventAuthorization,
+		ledger.EventExecutionTokenIssued, ledger.EventExecutionTokenConsumed, ledger.EventLiabilityRecord,
+		ledger.EventPolicySnapshotCreated, ledger.EventEscalationResolved,
+	} {
+		if !types[et] {
+			t.Errorf("no %s event recorded", et)
+		}
+	}
+	if errs := ledger.VerifyEventsWithSchema(events, nil, ledger.SchemaStrict); len(errs) != 0 {
+		t.Errorf("strict verification: %v", errs)
+	}
+}
+
+// ─── Merkle transparency log ──────────────────────────────────────────────────
+
+// remarshal decodes the JSON form of v into dst.
+func remarshal(t *testing.T, v interface{}, dst interface{}) {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+}
+
+// TestServer_TransparencyLogProofs checks that inclusion and consistency
+// proofs served by the API verify offline against signed tree heads.
+func TestServer_TransparencyLogProofs(t *testing.T) {
+	base := startServer(t)
+	pub, _ := testKeyPair()
+
+	_, _, data := doJSON(t, http.MethodGet, base+"/acp/v1/audit/tree-head", nil)
+	var old merkle.TreeHead
+	remarshal(t, data, &old)
+	if err := merkle.VerifyTreeHead(old, pub); err != nil {
+		t.Fatalf("tree head: %v", err)
+	}
+
+	approveET(t, base, "tlog-agent")
+
+	status, _, data := doJSON(t, http.MethodGet, fmt.Sprintf("%s/acp/v1/audit/proof/inclusion?sequence=%d", base, old.TreeSize+1), nil)
+	if status != http.StatusOK {
+		t.Fatalf("inclusion: status=%d data=%v", status, data)
+	}
+	var incl struct {
+		EventID   string          `json:"event_id"`
+		AuditPath []merkle.Hash   `json:"audit_path"`
+		TreeHead  merkle.TreeHead `json:"tree_head"`
+	}
+	remarshal(t, data, &incl)
+	_, _, v := doJSON(t, http.MethodGet, base+"/acp/v1/audit/verify/"+incl.EventID, nil)
+	var ev ledger.Event
+	remarshal(t, v["event"], &ev)
+	if err := merkle.VerifyTreeHead(incl.TreeHead, pub); err != nil {
+		t.Fatalf("inclusion tree head: %v", err)
+	}
+	if err := ledger.VerifyInclusion(ev, incl.AuditPath, incl.TreeHead); err != nil {
+		t.Errorf("inclusion proof: %v", err)
+	}
+
+	status, _, data = doJSON(t, http.MethodGet, fmt.Sprintf("%s/acp/v1/audit/proof/consistency?first=%d", base, old.TreeSize), nil)
+	if status != http.StatusOK {
+		t.Fatalf("consistency: status=%d data=%v", status, data)
+	}
+	var cons struct {
+		Proof    []merkle.Hash   `json:"proof"`
+		TreeHead merkle.TreeHead `json:"tree_head"`
+	}
+	remarshal(t, data, &cons)
+	if cons.TreeHead.TreeSize <= old.TreeSize {
+		t.Fatalf("tree did not grow: %d → %d", old.TreeSize, cons.TreeHead.TreeSize)
+	}
+	if err := merkle.VerifyConsistency(old.TreeSize, cons.TreeHead.TreeSize, old.RootHash, cons.TreeHead.RootHash, cons.Proof); err != nil {
+		t.Errorf("consistency proof: %v", err)
+	}
+
+	status, env, _ := doJSON(t, http.MethodGet, fmt.Sprintf("%s/acp/v1/audit/proof/consistency?first=%d", base, cons.TreeHead.TreeSize+100), nil)
+	if status != http.StatusBadRequest || env["error"].(map[string]interface{})["code"] != "SYS-004" {
+		t.Errorf("first past the end: status=%d env=%v", status, env)
+	}
+	if status, _, _ := doJSON(t, http.MethodGet, base+"/acp/v1/audit/proof/inclusion?event_id=nope", nil); status != http.StatusNotFound {
+		t.Errorf("unknown event: got %d, want 404", status)
+	}
+}
+
+// TestServer_LedgerFollower replicates a primary's ledger, across an
+// institution key rotation, into a read-only follower (ACP_FOLLOW).
+func TestServer_LedgerFollower(t *testing.T) {
+	primary := startServer(t)
+	if status, env, _ := doAdmin(t, http.MethodPost, primary+"/acp/v1/keys/rotate", map[string]interface{}{
+		"rotation_type": "scheduled", "overlap_period": 60,
+	}); status != http.StatusOK {
+		t.Fatalf("rotate: got %d (%v)", status, env)
+	}
+	approveET(t, primary, "replica-agent") // signed by the new key
+
+	follower := startServerEnv(t, "ACP_FOLLOW="+primary)
+	caughtUp := func() merkle.TreeHead {
+		t.Helper()
+		_, _, data := doJSON(t, http.MethodGet, primary+"/acp/v1/audit/tree-head", nil)
+		var want merkle.TreeHead
+		remarshal(t, data, &want)
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			_, _, data := doJSON(t, http.MethodGet, follower+"/acp/v1/audit/tree-head", nil)
+			var got merkle.TreeHead
+			remarshal(t, data, &got)
+			if got.TreeSize == want.TreeSize {
+				if got.RootHash != want.RootHash {
+					t.Fatalf("follower root %x, primary root %x", got.RootHash, want.RootHash)
+				}
+				return got
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("follower at %d events, primary at %d", got.TreeSize, want.TreeSize)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+	caughtUp()
+
+	// New events reach the follower over the open stream.
+	approveET(t, primary, "replica-agent")
+	caughtUp()
+
+	// HIST queries are served from the replica and match the primary.
+	histQuery := "/acp/v1/audit/query?agent_id=replica-agent&limit=100&verify_chain=true"
+	_, _, want := doJSON(t, http.MethodGet, primary+histQuery, nil)
+	status, _, got := doJSON(t, http.MethodGet, follower+histQuery, nil)
+	if status != http.StatusOK {
+		t.Fatalf("follower HIST query: got %d", status)
+	}
+	gotEvents, _ := got["events"].([]interface{})
+	wantEvents, _ := want["events"].([]interface{})
+	if len(gotEvents) == 0 || len(gotEvents) != len(wantEvents) {
+		t.Fatalf("follower returned %d agent events, primary %d", len(gotEvents), len(wantEvents))
+	}
+	if integ, _ := got["integrity"].(map[string]interface{}); integ["chain_valid"] != true {
+		t.Errorf("follower integrity = %v", integ)
+	}
+	var first ledger.Event
+	remarshal(t, gotEvents[0], &first)
+	status, _, ev := doJSON(t, http.MethodGet, follower+"/acp/v1/audit/events/"+first.EventID, nil)
+	if integ, _ := ev["integrity"].(map[string]interface{}); status != http.StatusOK || integ["sig_valid"] != true {
+		t.Errorf("follower event lookup: status=%d data=%v", status, ev)
+	}
+
+	// The follower learned the rotation from the ledger.
+	_, _, keys := doJSON(t, http.MethodGet, follower+"/.well-known/acp-keys", nil)
+	if ks, _ := keys["keys"].([]interface{}); len(ks) != 2 {
+		t.Errorf("follower keyring: %v", keys)
+	}
+	resp, err := http.Get(follower + "/acp/v1/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var health struct {
+		Role        string `json:"role"`
+		Replication struct {
+			State    string `json:"state"`
+			Sequence int64  `json:"sequence"`
+		} `json:"replication"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if health.Role != "follower" || health.Replication.State != "streaming" || health.Replication.Sequence == 0 {
+		t.Errorf("follower health = %+v", health)
+	}
+
+	// The follower is read-only, and HIST errors carry their codes.
+	if status, _, _ := doJSON(t, http.MethodPost, follower+"/acp/v1/authorize", map[string]interface{}{}); status != http.StatusNotFound {
+		t.Errorf("authorize on follower: got %d, want 404", status)
+	}
+	for path, wantCode := range map[string]string{
+		"/acp/v1/audit/query?limit=0":              "HIST-E002",
+		"/acp/v1/audit/query?from_ts=1&from_seq=1": "HIST-E003",
+		"/acp/v1/audit/query?to_seq=x":             "HIST-E001",
+		"/acp/v1/audit/events/nope":                "HIST-E010",
+	} {
+		_, env, _ := doJSON(t, http.MethodGet, follower+path, nil)
+		if e, _ := env["error"].(map[string]interface{}); e["code"] != wantCode {
+			t.Errorf("%s: error = %v, want %s", path, env["error"], wantCode)
+		}
+	}
+}
+
+func TestServer_CryptoShredding(t *testing.T) {
+	base := startServerEnv(t, "ACP_REDACT_SENSITIVE=true", "ACP_SHRED_KEYS_DIR="+t.TempDir())
+	approveET(t, base, "shred-agent")
+	approveET(t, base, "kept-agent")
+
+	history := func(agentID string) []interface{} {
+		t.Helper()
+		status, _, data := doJSON(t, http.MethodGet, base+"/acp/v1/audit/agents/"+agentID+"/history?limit=100", nil)
+		if status != http.StatusOK {
+			t.Fatalf("history %s: got %d", agentID, status)
+		}
+		events, _ := data["events"].([]interface{})
+		return events
+	}
+	fields := func(ev interface{}) map[string]interface{} {
+		p, _ := ev.(map[string]interface{})["payload"].(map[string]interface{})
+		return p
+	}
+
+	// The ledger stores commitments; HIST shows the cleartext.
+	events := history("shred-agent")
+	if len(events) == 0 {
+		t.Fatal("no history for shred-agent")
+	}
+	for _, ev := range events {
+		if p := fields(ev); p["agent_id"] != "shred-agent" {
+			t.Errorf("revealed payload = %v", p)
+		}
+	}
+	_, _, raw := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{"agent_id": "shred-agent"})
+	if b, _ := json.Marshal(raw["events"]); len(b) < 10 || strings.Contains(string(b), `"agent_id":"shred-agent"`) {
+		t.Errorf("stored events leak the subject, or none found: %s", b)
+	}
+
+	// Shredding cannot be undone: it takes an admin token.
+	if status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/audit/subjects/shred-agent/shred", map[string]interface{}{
+		"reason": "erasure request",
+	}); status != http.StatusUnauthorized || env["error"].(map[string]interface{})["code"] != "AUTH-001" {
+		t.Fatalf("shred without admin token: status=%d env=%v", status, env)
+	}
+	if len(history("shred-agent")) == 0 {
+		t.Fatal("rejected shred destroyed the subject key")
+	}
+
+	status, env, data := doAdmin(t, http.MethodPost, base+"/acp/v1/audit/subjects/shred-agent/shred", map[string]interface{}{
+		"reason": "erasure request",
+	})
+	if status != http.StatusOK || data["key_id"] == "" || data["sealed_values"].(float64) < 2 {
+		t.Fatalf("shred: status=%d env=%v", status, env)
+	}
+
+	// Shredded fields read as [redacted]; the subject cannot be found any more.
+	_, _, q := doJSON(t, http.MethodGet, base+"/acp/v1/audit/query?event_type=AUTHORIZATION&limit=100&verify_chain=true", nil)
+	all, _ := q["events"].([]interface{})
+	redacted, kept := 0, 0
+	for _, ev := range all {
+		switch fields(ev)["agent_id"] {
+		case ledger.Redacted:
+			if fields(ev)["resource"] != ledger.Redacted || fields(ev)["capability"] != "acp:cap:data.read" {
+				t.Errorf("shredded payload = %v", fields(ev))
+			}
+			redacted++
+		case "kept-agent":
+			kept++
+		}
+	}
+	if redacted != 1 || kept != 1 {
+		t.Errorf("AUTHORIZATION events: %d redacted, %d kept; want 1, 1", redacted, kept)
+	}
+	if len(history("shred-agent")) != 0 {
+		t.Error("shredded subject still matches a history query")
+	}
+
+	// The chain still verifies: only the commitments were hashed.
+	if integ, _ := q["integrity"].(map[string]interface{}); integ["chain_valid"] != true {
+		t.Errorf("integrity after shred = %v", integ)
+	}
+	seq := int64(data["ledger_sequence"].(float64))
+	_, _, shredded := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{
+		"event_type": ledger.EventSubjectShredded, "from_sequence": seq, "to_sequence": seq,
+	})
+	if b, _ := json.Marshal(shredded); !strings.Contains(string(b), data["key_id"].(string)) ||
+		!strings.Contains(string(b), `"requested_by":"admin@org.acp.server"`) || strings.Contains(string(b), "shred-agent") {
+		t.Errorf("SUBJECT_SHREDDED event = %s", b)
+	}
+
+	if status, env, _ := doAdmin(t, http.MethodPost, base+"/acp/v1/audit/subjects/shred-agent/shred", map[string]interface{}{
+		"reason": "erasure request",
+	}); status != http.StatusNotFound {
+		t.Errorf("second shred: got %d (%v), want 404", status, env)
+	}
+}
+
+func TestServer_SegmentArchival(t *testing.T) {
+	if status, _, _ := doAdmin(t, http.MethodPost, startServer(t)+"/acp/v1/audit/segments", map[string]interface{}{}); status != http.StatusBadRequest {
+		t.Errorf("archival without ACP_ARCHIVE_DIR: got %d, want 400", status)
+	}
+
+	base := startServerEnv(t, "ACP_ARCHIVE_DIR="+t.TempDir())
+	approveET(t, base, "archived-agent")
+	_, _, before := doJSON(t, http.MethodGet, base+"/acp/v1/audit/query?event_type=AUTHORIZATION", nil)
+	authz, _ := before["events"].([]interface{})
+	if len(authz) != 1 {
+		t.Fatalf("AUTHORIZATION events before archival: %v", before)
+	}
+	eventID := authz[0].(map[string]interface{})["event_id"].(string)
+
+	// Listing and archiving take an admin token.
+	if status, env, _ := doJSON(t, http.MethodPost, base+"/acp/v1/audit/segments", map[string]interface{}{}); status != http.StatusUnauthorized || env["error"].(map[string]interface{})["code"] != "AUTH-001" {
+		t.Errorf("archive without admin token: status=%d env=%v", status, env)
+	}
+	if status, env, _ := doJSON(t, http.MethodGet, base+"/acp/v1/audit/segments", nil); status != http.StatusUnauthorized || env["error"].(map[string]interface{})["code"] != "AUTH-001" {
+		t.Errorf("list without admin token: status=%d env=%v", status, env)
+	}
+
+	status, env, cp := doAdmin(t, http.MethodPost, base+"/acp/v1/audit/segments", map[string]interface{}{
+		"before_ts": time.Now().Unix() + 60,
+	})
+	if status != http.StatusCreated || cp["first_sequence"] != float64(1) || cp["sig"] == "" {
+		t.Fatalf("archive: status=%d env=%v", status, env)
+	}
+	if status, _, _ := doAdmin(t, http.MethodPost, base+"/acp/v1/audit/segments", map[string]interface{}{
+		"before_ts": time.Now().Unix() + 60,
+	}); status != http.StatusBadRequest {
+		t.Errorf("nothing left to archive: got %d, want 400", status)
+	}
+	_, _, list := doAdmin(t, http.MethodGet, base+"/acp/v1/audit/segments", nil)
+	if segs, _ := list["segments"].([]interface{}); len(segs) != 1 || list["live_from_sequence"] != cp["last_sequence"].(float64)+1 {
+		t.Errorf("segments = %v", list)
+	}
+
+	// Archived events are still queried, fetched and verified.
+	_, _, q := doJSON(t, http.MethodGet, base+"/acp/v1/audit/query?event_type=AUTHORIZATION&verify_chain=true", nil)
+	if events, _ := q["events"].([]interface{}); len(events) != 1 || events[0].(map[string]interface{})["event_id"] != eventID {
+		t.Errorf("query after archival = %v", q)
+	}
+	if integ, _ := q["integrity"].(map[string]interface{}); integ["chain_valid"] != true {
+		t.Errorf("integrity after archival = %v", integ)
+	}
+	if status, _, _ := doJSON(t, http.MethodGet, base+"/acp/v1/audit/events/"+eventID, nil); status != http.StatusOK {
+		t.Errorf("archived event: got %d", status)
+	}
+	if status, env, data := doJSON(t, http.MethodGet, base+"/acp/v1/audit/verify/"+eventID, nil); status != http.StatusOK || data["chain_valid"] != true {
+		t.Errorf("verify archived event: status=%d env=%v", status, env)
+	}
+}
+
+// TestServer_AgentRateLimit rejects an agent over its quota with 429 and a
+// Retry-After header. Requests that only claim the agent_id cannot exhaust
+// the quota of requests authenticated by a delegation chain or of other
+// caller addresses, nor count against the agent's reputation; batch items
+// share the claimed quota of their caller's address.
+func TestServer_AgentRateLimit(t *testing.T) {
+	base := startServerEnv(t, "ACP_RATE_LIMITS=2=0.01/2")
+	_, instPriv := testKeyPair()
+	now := time.Now().Unix()
+	chain := []json.RawMessage{signCT(t, tokens.CapabilityToken{
+		Version: "1.0", Issuer: "org.acp.server", Subject: "flood-agent",
+		Cap: []string{"acp:cap:data.read"}, Resource: "metrics",
+		IssuedAt: now, Expiration: now + 3600, Nonce: "flood-root",
+	}, instPriv)}
+	authorizeVia := func(client *http.Client, agentID string, n int, chain []json.RawMessage) *http.Response {
+		t.Helper()
+		raw, _ := json.Marshal(map[string]interface{}{
+			"request_id": fmt.Sprintf("req-%s-%d", agentID, n), "agent_id": agentID,
+			"capability": "acp:cap:data.read", "resource": "metrics/public", "delegation_chain": chain,
+		})
+		resp, err := client.Post(base+"/acp/v1/authorize", "application/json", bytes.NewReader(raw))
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	authorize := func(agentID string, n int, chain []json.RawMessage) *http.Response {
+		t.Helper()
+		return authorizeVia(http.DefaultClient, agentID, n, chain)
+	}
+	violations := func() int {
+		_, _, data := doJSON(t, http.MethodGet, base+"/acp/v1/rep/flood-agent/events", nil)
+		events, _ := data["events"].([]interface{})
+		n := 0
+		for _, ev := range events {
+			if ev.(map[string]interface{})["event_type"] == "REP_EVT_POLICY_VIOLATION" {
+				n++
+			}
+		}
+		return n
+	}
+
+	// A third party floods with the agent's ID, unauthenticated.
+	for i := 0; i < 2; i++ {
+		if resp := authorize("flood-agent", i, nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d within burst: got %d", i, resp.StatusCode)
+		}
+	}
+	for i := 2; i < 4; i++ {
+		resp := authorize("flood-agent", i, nil)
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("request %d over burst: got %d, want 429", i, resp.StatusCode)
+		}
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err != nil || secs < 1 {
+			t.Errorf("Retry-After = %q", resp.Header.Get("Retry-After"))
+		}
+	}
+	if resp := authorize("quiet-agent", 0, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("other agent: got %d", resp.StatusCode)
+	}
+	// A legacy client of the agent at another address keeps its own quota.
+	otherAddr := &http.Client{Transport: &http.Transport{DialContext: (&net.Dialer{
+		LocalAddr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 2)},
+	}).DialContext}}
+	if resp := authorizeVia(otherAddr, "flood-agent", 4, nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("same agent_id from another address: got %d", resp.StatusCode)
+	}
+	_, _, data := doJSON(t, http.MethodPost, base+"/acp/v1/authorize/batch", map[string]interface{}{
+		"items": []map[string]interface{}{{
+			"request_id": "batch-flood", "agent_id": "flood-agent", "action_type": "acp:cap:data.read", "resource": "metrics/public",
+		}},
+	})
+	if results, _ := data["results"].([]interface{}); len(results) != 1 || results[0].(map[string]interface{})["reason_code"] != "BULK-002" {
+		t.Errorf("batch item over the quota: %v", data)
+	}
+	if n := violations(); n != 0 {
+		t.Errorf("unauthenticated flood recorded %d policy violations", n)
+	}
+
+	// The agent itself still has its quota, and exceeding it is a violation.
+	for i := 10; i < 12; i++ {
+		if resp := authorize("flood-agent", i, chain); resp.StatusCode != http.StatusOK {
+			t.Fatalf("authenticated request %d: got %d", i, resp.StatusCode)
+		}
+	}
+	for i := 12; i < 14; i++ {
+		if resp := authorize("flood-agent", i, chain); resp.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("authenticated request %d over burst: got %d, want 429", i, resp.StatusCode)
+		}
+	}
+	if n := violations(); n != 1 {
+		t.Errorf("policy violations recorded = %d, want 1", n)
+	}
+}
+
+// TestServer_AuthorizeIdempotent checks request_id de-duplication (AUTH-004):
+// an identical retry replays the original signed response without a second
+// decision, a different request under the same ID is rejected, and another
+// agent can use the same ID.
+func TestServer_AuthorizeIdempotent(t *testing.T) {
+	base := startServer(t)
+	authorizeAs := func(agentID, resource string) (*http.Response, []byte) {
+		t.Helper()
+		body := fmt.Sprintf(`{"request_id":"req-retry","agent_id":%q,"capability":"acp:cap:data.read","resource":%q}`, agentID, resource)
+		resp, err := http.Post(base+"/acp/v1/authorize", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return resp, b
+	}
+	authorize := func(resource string) (*http.Response, []byte) {
+		t.Helper()
+		return authorizeAs("retry-agent", resource)
+	}
+
+	first, original := authorize("metrics/public")
+	if first.StatusCode != http.StatusOK || first.Header.Get("X-ACP-Idempotent-Replay") != "" {
+		t.Fatalf("first request: got %d %s", first.StatusCode, original)
+	}
+	retry, replayed := authorize("metrics/public")
+	if retry.StatusCode != http.StatusOK || retry.Header.Get("X-ACP-Idempotent-Replay") != "true" {
+		t.Fatalf("retry: got %d, replay header %q", retry.StatusCode, retry.Header.Get("X-ACP-Idempotent-Replay"))
+	}
+	if string(replayed) != string(original) {
+		t.Errorf("retry response differs:\n%s\n%s", replayed, original)
+	}
+
+	conflict, body := authorize("metrics/private")
+	if conflict.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), "AUTH-004") {
+		t.Errorf("conflicting retry: got %d %s, want 400 AUTH-004", conflict.StatusCode, body)
+	}
+	other, body := authorizeAs("other-agent", "metrics/private")
+	if other.StatusCode != http.StatusOK || other.Header.Get("X-ACP-Idempotent-Replay") != "" {
+		t.Errorf("same request_id, other agent: got %d %s, want a decision", other.StatusCode, body)
+	}
+
+	// One decision, and the replay references it.
+	_, _, q := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{
+		"event_type": ledger.EventAuthorization, "agent_id": "retry-agent",
+	})
+	decisions, _ := q["events"].([]interface{})
+	if len(decisions) != 1 {
+		t.Fatalf("AUTHORIZATION events = %d, want 1", len(decisions))
+	}
+	_, _, q = doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{
+		"event_type": ledger.EventAuthorizationReplayed,
+	})
+	replays, _ := q["events"].([]interface{})
+	if len(replays) != 1 {
+		t.Fatalf("AUTHORIZATION_REPLAYED events = %d, want 1", len(replays))
+	}
+	payload := replays[0].(map[string]interface{})["payload"].(map[string]interface{})
+	if payload["original_event_id"] != decisions[0].(map[string]interface{})["event_id"] || payload["decision"] != "APPROVED" {
+		t.Errorf("replay payload = %v", payload)
+	}
+}
+
+// TestServer_EscalationResolveRequestID checks that resolving an escalation
+// takes the resolver capability and a known escalation_id, and that
+// ESCALATION_RESOLVED records the agent and request_id of the escalated
+// authorization and the token subject as resolved_by. Another agent's
+// escalation under the same request_id is left pending.
+func TestServer_EscalationResolveRequestID(t *testing.T) {
+	base := startServer(t)
+	escID := escalate(t, base, "escalated-agent", "req-escalated")
+	if otherID := escalate(t, base, "other-escalated-agent", "req-escalated"); otherID == escID {
+		t.Fatalf("two agents' escalations share escalation_id %s", escID)
+	}
+	resolve := base + "/acp/v1/authorize/escalations/" + escID + "/resolve"
+
+	if status, env, _ := doJSON(t, http.MethodPost, resolve, map[string]interface{}{"resolution": "APPROVED"}); status != http.StatusUnauthorized || env["error"].(map[string]interface{})["code"] != "AUTH-001" {
+		t.Errorf("resolve without token: status=%d env=%v", status, env)
+	}
+	if status, env, _ := doAdmin(t, http.MethodPost, resolve, map[string]interface{}{"resolution": "APPROVED"}); status != http.StatusForbidden {
+		t.Errorf("resolve without acp:cap:agent.modify: status=%d env=%v", status, env)
+	}
+	// A registered agent resolves only with autonomy_level ≥ 3.
+	_, pubB64 := agentKey(0x63)
+	if status, _, _ := doJSON(t, http.MethodPost, base+"/acp/v1/agents", map[string]interface{}{
+		"agent_id": "junior-reviewer", "public_key": pubB64, "autonomy_level": 2, "authority_domain": "finance",
+	}); status != http.StatusCreated {
+		t.Fatalf("register: got %d", status)
+	}
+	junior := institutionToken(t, "org.acp.server", "junior-reviewer", "acp:cap:agent.modify")
+	if status, env, _ := doJSONHeaders(t, http.MethodPost, resolve, map[string]string{"Authorization": "Bearer " + string(junior)}, map[string]interface{}{"resolution": "APPROVED"}); status != http.StatusForbidden {
+		t.Errorf("resolve by autonomy_level 2 agent: status=%d env=%v", status, env)
+	}
+	if status, env, _ := doResolver(t, http.MethodPost, base+"/acp/v1/authorize/escalations/esc-unknown/resolve", map[string]interface{}{"resolution": "APPROVED"}); status != http.StatusNotFound {
+		t.Errorf("unknown escalation: status=%d env=%v, want 404", status, env)
+	}
+	if status, _, data := doResolver(t, http.MethodPost, resolve, map[string]interface{}{"resolution": "APPROVED"}); status != http.StatusOK || data["resolved_by"] != "reviewer@org.acp.server" {
+		t.Fatalf("resolve: status=%d data=%v", status, data)
+	}
+
+	_, _, q := doJSON(t, http.MethodPost, base+"/acp/v1/audit/query", map[string]interface{}{
+		"event_type": ledger.EventEscalationResolved,
+	})
+	events, _ := q["events"].([]interface{})
+	if len(events) != 1 {
+		t.Fatalf("ESCALATION_RESOLVED events = %d, want 1", len(events))
+	}
+	payload := events[0].(map[string]interface{})["payload"].(map[string]interface{})
+	if payload["escalation_id"] != escID || payload["agent_id"] != "escalated-agent" ||
+		payload["original_request_id"] != "req-escalated" || payload["resolved_by"] != "reviewer@org.acp.server" {
+		t.Errorf("ESCALATION_RESOLVED payload = %v", payload)
+	}
+}
@@ -1,7 +1,9 @@
 // Command gen-ledger-vectors generates compliance/test-vectors/TS-LEDGER-*.json.
 //
 // Produces 3 positive and 8 negative test vectors covering ACP-LEDGER-1.3
-// chain verification (LEDGER-002 through LEDGER-012).
+// chain verification (LEDGER-002 through LEDGER-012), which a verifier must
+// pass with lenient payload checks, and TS-LEDGER-STRICT-* vectors
+// (schema_mode "strict") covering the §5 payload schemas (LEDGER-009).
 // Uses RFC 8037 test key A (same seed as acp-sign-vectors) to create
 // real Ed25519 signatures and SHA-256 hash chains.
 //
@@ -30,6 +32,14 @@ const testKeySeedHex = "9d61b19deffd59985ba34a442fa1c54cd044c9c565b66f2699171d66
 
 const institutionID = "org.example.banking"
 
+// Policy snapshot referenced by the strict AUTHORIZATION and RISK_EVALUATION
+// payloads.
+const (
+	policySnapshotRef = "policy:v2.1:abc123"
+	policyVersion     = "v2.1"
+	policyHash        = "3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b"
+)
+
 // ─── Test Vector Schema ───────────────────────────────────────────────────────
 
 // TestVector is the on-disk representation of a LEDGER compliance test.
@@ -51,8 +61,11 @@ type VectorMeta struct {
 
 // VectorInput holds the institution public key and the event chain to verify.
 // Events are stored as raw JSON so negative vectors can carry tampered fields.
+// SchemaMode "strict" asks the verifier to check every payload against its
+// §5 schema; absent, payloads are checked leniently.
 type VectorInput struct {
 	InstitutionPublicKey string            `json:"institution_public_key"`
+	SchemaMode           string            `json:"schema_mode,omitempty"`
 	Events               []json.RawMessage `json:"events"`
 }
 
@@ -102,6 +115,10 @@ func (g *generator) run() {
 		g.negTimestampRegression(),
 		g.negUnknownEventType(),
 		g.negMissingPolicySnapshotRef(),
+		// ── Strict payload schemas ────────────────────────────────────────────
+		g.strictPosFullLifecycle(),
+		g.strictNegLegacyAuthorization(),
+		g.strictNegIncompleteConsumption(),
 	}
 	for _, v := range vectors {
 		g.writeVector(v)
@@ -479,6 +496,133 @@ func (g *generator) negMissingPolicySnapshotRef() TestVector {
 	}
 }
 
+// ─── Strict Vectors ───────────────────────────────────────────────────────────
+
+// strictPosFullLifecycle: the lifecycle of POS-003 with every payload carrying
+// its full §5 schema; valid in strict mode.
+func (g *generator) strictPosFullLifecycle() TestVector {
+	l := g.newLedger()
+	l.SetSchemaMode(ledger.SchemaStrict)
+	ts := int64(1718921000)
+	g.append(l, ledger.EventAgentRegistered, ledger.AgentRegisteredPayload{
+		AgentID:         "did:example:agent-bob",
+		InstitutionID:   institutionID,
+		AutonomyLevel:   2,
+		AuthorityDomain: "hr",
+		Capabilities:    []string{"acp:cap:data.read", "acp:cap:report.write"},
+		RegisteredBy:    institutionID,
+	})
+	g.append(l, ledger.EventRiskEvaluation, ledger.RiskEvaluationPayload{
+		EvalID:            "eval-ledger-strict-pos-001",
+		RequestID:         "auth-ledger-strict-pos-001",
+		AgentID:           "did:example:agent-bob",
+		Capability:        "acp:cap:data.read",
+		Baseline:          10,
+		FRes:              5,
+		RSFinal:           15,
+		Decision:          "APPROVED",
+		AnomalyDetail:     map[string]interface{}{},
+		PolicySnapshotRef: policySnapshotRef,
+		PolicyHash:        policyHash,
+	})
+	g.append(l, ledger.EventAuthorization, ledger.AuthorizationPayload{
+		RequestID:         "auth-ledger-strict-pos-001",
+		AgentID:           "did:example:agent-bob",
+		Capability:        "acp:cap:data.read",
+		Resource:          "doc:hr:employees",
+		Decision:          "APPROVED",
+		RiskScore:         15,
+		PolicySnapshotRef: policySnapshotRef,
+		PolicyVersion:     policyVersion,
+	})
+	g.append(l, ledger.EventExecutionTokenIssued, ledger.ExecutionTokenIssuedPayload{
+		ETID:            "et-ledger-strict-pos-001",
+		AuthorizationID: "auth-ledger-strict-pos-001",
+		AgentID:         "did:example:agent-bob",
+		Capability:      "acp:cap:data.read",
+		Resource:        "doc:hr:employees",
+		ExpiresAt:       ts + 300,
+	})
+	g.append(l, ledger.EventExecutionTokenConsumed, ledger.ExecutionTokenConsumedPayload{
+		ETID:             "et-ledger-strict-pos-001",
+		AuthorizationID:  "auth-ledger-strict-pos-001",
+		AgentID:          "did:example:agent-bob",
+		ConsumedAt:       ts + 10,
+		ConsumedBySystem: "system:hr-service",
+		ExecutionResult:  "success",
+	})
+	g.mustVerify(l, "STRICT-POS-001")
+	return TestVector{
+		Meta: VectorMeta{
+			ID:               "TS-LEDGER-STRICT-POS-001",
+			Layer:            "LEDGER",
+			Severity:         "mandatory",
+			ACPVersion:       "1.3",
+			ConformanceLevel: "L1",
+			Description:      "Valid 6-event lifecycle — every payload carries the required fields of its §5 schema; valid in strict mode",
+		},
+		Input:    VectorInput{InstitutionPublicKey: g.pubKeyB64, SchemaMode: "strict", Events: g.allEvents(l)},
+		Expected: VectorExpected{Decision: "VALID", ErrorCode: nil},
+	}
+}
+
+// strictNegLegacyAuthorization: AUTHORIZATION in the shape of POS-002 (with
+// policy_snapshot_ref but no request_id, capability, resource, risk_score or
+// policy_version) → LEDGER-009 in strict mode; valid in lenient mode.
+func (g *generator) strictNegLegacyAuthorization() TestVector {
+	l := g.newLedger()
+	ts := int64(1718921100)
+	g.append(l, ledger.EventAuthorization, map[string]interface{}{
+		"agent_id":            "did:example:agent-alice",
+		"authorization_id":    "auth-strict-neg-001",
+		"decision":            "APPROVED",
+		"policy_snapshot_ref": policySnapshotRef,
+		"authorized_at":       ts,
+	})
+	g.mustVerify(l, "STRICT-NEG-001")
+	errCode := "LEDGER-009"
+	return TestVector{
+		Meta: VectorMeta{
+			ID:               "TS-LEDGER-STRICT-NEG-001",
+			Layer:            "LEDGER",
+			Severity:         "mandatory",
+			ACPVersion:       "1.3",
+			ConformanceLevel: "L1",
+			Description:      "Invalid in strict mode — AUTHORIZATION payload lacks required §5.2 fields (request_id, risk_score, ...); strict verifier MUST report LEDGER-009",
+		},
+		Input:    VectorInput{InstitutionPublicKey: g.pubKeyB64, SchemaMode: "strict", Events: g.allEvents(l)},
+		Expected: VectorExpected{Decision: "INVALID", ErrorCode: &errCode},
+	}
+}
+
+// strictNegIncompleteConsumption: EXECUTION_TOKEN_CONSUMED in the shape of
+// POS-003 (no authorization_id or agent_id) → LEDGER-009 in strict mode;
+// valid in lenient mode.
+func (g *generator) strictNegIncompleteConsumption() TestVector {
+	l := g.newLedger()
+	ts := int64(1718921200)
+	g.append(l, ledger.EventExecutionTokenConsumed, map[string]interface{}{
+		"et_id":            "et-strict-neg-002",
+		"consumed_at":      ts,
+		"execution_result": "success",
+		"consumed_by":      "system:hr-service",
+	})
+	g.mustVerify(l, "STRICT-NEG-002")
+	errCode := "LEDGER-009"
+	return TestVector{
+		Meta: VectorMeta{
+			ID:               "TS-LEDGER-STRICT-NEG-002",
+			Layer:            "LEDGER",
+			Severity:         "mandatory",
+			ACPVersion:       "1.3",
+			ConformanceLevel: "L1",
+			Description:      "Invalid in strict mode — EXECUTION_TOKEN_CONSUMED payload lacks authorization_id and agent_id; strict verifier MUST report LEDGER-009",
+		},
+		Input:    VectorInput{InstitutionPublicKey: g.pubKeyB64, SchemaMode: "strict", Events: g.allEvents(l)},
+		Expected: VectorExpected{Decision: "INVALID", ErrorCode: &errCode},
+	}
+}
+
 // ─── Helpers ──────────────────────────────────────────────────────────────────
 
 func (g *generator) newLedger() *ledger.InMemoryLedger {
@@ -3,11 +3,9 @@ module github.com/chelof100/acp-framework/acp-go
 go 1.22.0
 
 require (
+	github.com/cloudflare/circl v1.6.3
 	github.com/gowebpki/jcs v1.0.1
 	github.com/mr-tron/base58 v1.2.0
 )
 
-require (
-	github.com/cloudflare/circl v1.6.3 // indirect
-	golang.org/x/sys v0.28.0 // indirect
-)
+require golang.org/x/sys v0.28.0 // indirect
@@ -0,0 +1,186 @@
+package acpd_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/acpd"
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
+	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
+)
+
+func claim(now time.Time) acpd.Claim {
+	return acpd.Claim{
+		Sub:   "did:acpd:agent-1",
+		Res:   "org.example/accounts/ACC-001",
+		Act:   []string{"read"},
+		Iat:   now.Unix(),
+		Exp:   now.Add(5 * time.Minute).Unix(),
+		Jti:   "jti-1",
+		Nonce: "nonce-1",
+	}
+}
+
+func newSim(t *testing.T, n, threshold int) *acpd.Simulation {
+	t.Helper()
+	sim, err := acpd.NewSimulation("set-1", n, threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sim
+}
+
+// ── Authority Set ────────────────────────────────────────────────────────────
+
+func TestNewAuthoritySet_Threshold(t *testing.T) {
+	var authorities []acpd.Authority
+	for i := byte(1); i <= 4; i++ {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = i
+		pk := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+		authorities = append(authorities, acpd.Authority{ID: did.EncodeKey(pk), PublicKey: pk})
+	}
+	// n=4 → f=1 → t ∈ [3, 4].
+	for _, th := range []int{0, 2, 5} {
+		if _, err := acpd.NewAuthoritySet("s", authorities, th); !errors.Is(err, acpd.ErrInvalidSet) {
+			t.Errorf("threshold %d: err = %v, want ErrInvalidSet", th, err)
+		}
+	}
+	if _, err := acpd.NewAuthoritySet("s", authorities, 3); err != nil {
+		t.Errorf("threshold 3: %v", err)
+	}
+	if _, err := acpd.NewAuthoritySet("s", append(authorities, authorities[0]), 4); !errors.Is(err, acpd.ErrInvalidSet) {
+		t.Errorf("duplicate member: err = %v", err)
+	}
+
+	ids := make([]string, len(authorities))
+	for i, a := range authorities {
+		ids[i] = a.ID
+	}
+	set, err := acpd.NewAuthoritySetFromDIDs("s", ids, 3, did.KeyResolver{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := set.Member(ids[2]); !ok || !a.PublicKey.Equal(authorities[2].PublicKey) {
+		t.Errorf("Member(%s) = %v, %v", ids[2], a, ok)
+	}
+}
+
+// ── Issuance ─────────────────────────────────────────────────────────────────
+
+func TestSimulation_ToleratesFaultyNodes(t *testing.T) {
+	sim := newSim(t, 4, 3)
+	sim.Nodes[0].Behavior = acpd.Offline
+	now := time.Now()
+
+	tok, err := sim.Issue(claim(now))
+	if err != nil {
+		t.Fatalf("one offline node: %v", err)
+	}
+	if len(tok.MultiSignature) != 3 {
+		t.Errorf("signatures = %d, want 3", len(tok.MultiSignature))
+	}
+	if err := acpd.Verify(tok, sim.Set, acpd.VerifyOptions{Now: now}); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+
+	sim.Nodes[1].Behavior = acpd.Byzantine
+	if _, err := sim.Issue(claim(now)); !errors.Is(err, acpd.ErrQuorumNotMet) || !errors.Is(err, acpd.ErrInvalidPartialSig) {
+		t.Errorf("offline + byzantine: err = %v, want ErrQuorumNotMet with ErrInvalidPartialSig", err)
+	}
+}
+
+func TestSimulation_PolicyRefusal(t *testing.T) {
+	sim := newSim(t, 7, 5)
+	refuse := func(acpd.Claim) error { return errors.New("resource not allowed") }
+	sim.Nodes[0].Policy, sim.Nodes[1].Policy = refuse, refuse
+	if _, err := sim.Issue(claim(time.Now())); err != nil {
+		t.Errorf("two refusals of seven: %v", err)
+	}
+	sim.Nodes[2].Policy = refuse
+	if _, err := sim.Issue(claim(time.Now())); !errors.Is(err, acpd.ErrQuorumNotMet) {
+		t.Errorf("three refusals of seven: err = %v", err)
+	}
+}
+
+func TestCollector_RejectsForeignAndDuplicate(t *testing.T) {
+	sim := newSim(t, 4, 3)
+	other := newSim(t, 4, 3)
+	tok, _ := acpd.NewToken(sim.Set, claim(time.Now()))
+	c, err := acpd.NewCollector(sim.Set, tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, _ := other.Nodes[0].Sign(tok)
+	if _, err := c.Add(ps); !errors.Is(err, acpd.ErrUnknownAuthority) {
+		t.Errorf("foreign signer: err = %v", err)
+	}
+	ps, _ = sim.Nodes[0].Sign(tok)
+	c.Add(ps)
+	c.Add(ps)
+	if c.Count() != 1 {
+		t.Errorf("Count = %d after duplicate, want 1", c.Count())
+	}
+	if _, err := c.Token(); !errors.Is(err, acpd.ErrQuorumNotMet) {
+		t.Errorf("Token below quorum: err = %v", err)
+	}
+}
+
+// ── Verification ─────────────────────────────────────────────────────────────
+
+func TestVerify(t *testing.T) {
+	sim := newSim(t, 4, 3)
+	now := time.Now()
+	tok, err := sim.Issue(claim(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Round-trip through JSON: the wire format is what gets verified.
+	raw, _ := json.Marshal(tok)
+	var wire acpd.Token
+	if err := json.Unmarshal(raw, &wire); err != nil {
+		t.Fatal(err)
+	}
+
+	store := tokens.NewInMemoryNonceStore()
+	if err := acpd.Verify(&wire, sim.Set, acpd.VerifyOptions{Now: now, NonceStore: store}); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if err := acpd.Verify(&wire, sim.Set, acpd.VerifyOptions{Now: now, NonceStore: store}); !errors.Is(err, acpd.ErrTokenReplay) {
+		t.Errorf("replay: err = %v", err)
+	}
+
+	tampered := wire
+	tampered.Claim.Act = []string{"read", "write"}
+	if err := acpd.Verify(&tampered, sim.Set, acpd.VerifyOptions{Now: now}); !errors.Is(err, acpd.ErrQuorumNotMet) {
+		t.Errorf("tampered claim: err = %v", err)
+	}
+
+	padded := wire
+	padded.MultiSignature = append([]acpd.PartialSig{}, wire.MultiSignature[:2]...)
+	padded.MultiSignature = append(padded.MultiSignature, wire.MultiSignature[0])
+	if err := acpd.Verify(&padded, sim.Set, acpd.VerifyOptions{Now: now}); !errors.Is(err, acpd.ErrQuorumNotMet) {
+		t.Errorf("duplicated signature counted twice: err = %v", err)
+	}
+
+	other := newSim(t, 4, 3)
+	if err := acpd.Verify(&wire, other.Set, acpd.VerifyOptions{Now: now}); !errors.Is(err, acpd.ErrQuorumNotMet) {
+		t.Errorf("same set_id, other members: err = %v", err)
+	}
+	other.Set.SetID = "set-2"
+	if err := acpd.Verify(&wire, other.Set, acpd.VerifyOptions{Now: now}); !errors.Is(err, acpd.ErrSetMismatch) {
+		t.Errorf("other set_id: err = %v", err)
+	}
+
+	if err := acpd.Verify(&wire, sim.Set, acpd.VerifyOptions{Now: now.Add(time.Hour)}); !errors.Is(err, acpd.ErrTokenExpired) {
+		t.Errorf("expired: err = %v", err)
+	}
+	revoked := func(jti string) bool { return jti == "jti-1" }
+	if err := acpd.Verify(&wire, sim.Set, acpd.VerifyOptions{Now: now, IsRevoked: revoked}); !errors.Is(err, acpd.ErrTokenRevoked) {
+		t.Errorf("revoked: err = %v", err)
+	}
+}
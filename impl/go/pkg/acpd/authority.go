@@ -0,0 +1,118 @@
+// Package acpd implements ACP-D decentralized capabilities: tokens issued by
+// a quorum of an Authority Set instead of a single institutional key.
+//
+// An Authority Set of n authorities tolerates f = ⌊(n−1)/3⌋ Byzantine members
+// (n ≥ 3f + 1, ACP-D §7.1); a token is valid when at least t ≥ 2f + 1 distinct
+// members signed it. This implementation uses M-of-N Ed25519 multi-signatures
+// (the "Multi-Ed25519" option of ACP-D §7.2): each authority signs
+// SHA-256(JCS({header, capability_claim})) and the token carries the list of
+// partial signatures. BLS12-381 threshold signatures and the zk_proof of
+// ACP-D §8 are not implemented; zk_proof is carried but not verified.
+package acpd
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
+)
+
+// ─── Errors (ACPD-xxx) ────────────────────────────────────────────────────────
+
+var (
+	ErrUnsupportedToken  = errors.New("ACPD-001: unsupported token header")
+	ErrQuorumNotMet      = errors.New("ACPD-002: quorum not met")
+	ErrTokenExpired      = errors.New("ACPD-003: token expired")
+	ErrTokenNotYetValid  = errors.New("ACPD-004: token not yet valid (iat in future)")
+	ErrTokenRevoked      = errors.New("ACPD-005: token revoked")
+	ErrTokenReplay       = errors.New("ACPD-006: token nonce reused")
+	ErrInvalidSet        = errors.New("ACPD-007: invalid authority set")
+	ErrSetMismatch       = errors.New("ACPD-008: token issued for another authority set")
+	ErrMalformedClaim    = errors.New("ACPD-009: malformed capability claim")
+	ErrUnknownAuthority  = errors.New("ACPD-010: signer is not a member of the authority set")
+	ErrInvalidPartialSig = errors.New("ACPD-011: invalid partial signature")
+)
+
+// ─── Authority Set ────────────────────────────────────────────────────────────
+
+// Authority is one member of an Authority Set, identified by its DID.
+type Authority struct {
+	ID        string            `json:"id"` // DID
+	PublicKey ed25519.PublicKey `json:"-"`
+}
+
+// AuthoritySet is the quorum that issues ACP-D tokens (ACP-D §3, §7.1).
+type AuthoritySet struct {
+	SetID       string      `json:"set_id"`
+	Authorities []Authority `json:"authorities"`
+	Threshold   int         `json:"threshold"` // t: signatures required
+}
+
+// NewAuthoritySet validates and returns an Authority Set.
+//
+// threshold must satisfy 2f + 1 ≤ t ≤ n with f = MaxFaulty(n); member IDs
+// and keys must be unique.
+func NewAuthoritySet(setID string, authorities []Authority, threshold int) (*AuthoritySet, error) {
+	if setID == "" {
+		return nil, fmt.Errorf("%w: set_id must not be empty", ErrInvalidSet)
+	}
+	n := len(authorities)
+	if n == 0 {
+		return nil, fmt.Errorf("%w: no authorities", ErrInvalidSet)
+	}
+	if min := 2*MaxFaulty(n) + 1; threshold < min || threshold > n {
+		return nil, fmt.Errorf("%w: threshold %d not in [%d, %d] for n=%d", ErrInvalidSet, threshold, min, n, n)
+	}
+	ids := make(map[string]bool, n)
+	keys := make(map[string]bool, n)
+	members := make([]Authority, n)
+	for i, a := range authorities {
+		if a.ID == "" || len(a.PublicKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: authority %d needs an id and a 32-byte key", ErrInvalidSet, i)
+		}
+		if ids[a.ID] || keys[string(a.PublicKey)] {
+			return nil, fmt.Errorf("%w: duplicate authority %s", ErrInvalidSet, a.ID)
+		}
+		ids[a.ID], keys[string(a.PublicKey)] = true, true
+		members[i] = a
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+	return &AuthoritySet{SetID: setID, Authorities: members, Threshold: threshold}, nil
+}
+
+// NewAuthoritySetFromDIDs resolves each authority DID through r and builds
+// the set from the first Ed25519 key of each document.
+func NewAuthoritySetFromDIDs(setID string, dids []string, threshold int, r did.Resolver) (*AuthoritySet, error) {
+	authorities := make([]Authority, 0, len(dids))
+	for _, id := range dids {
+		keys, err := did.ResolveKeys(r, id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSet, err)
+		}
+		authorities = append(authorities, Authority{ID: id, PublicKey: keys[0]})
+	}
+	return NewAuthoritySet(setID, authorities, threshold)
+}
+
+// MaxFaulty returns f, the number of Byzantine authorities a set of n
+// tolerates: the largest f with n ≥ 3f + 1.
+func MaxFaulty(n int) int {
+	if n < 1 {
+		return 0
+	}
+	return (n - 1) / 3
+}
+
+// Size returns n.
+func (s *AuthoritySet) Size() int { return len(s.Authorities) }
+
+// Member returns the authority with the given ID.
+func (s *AuthoritySet) Member(id string) (Authority, bool) {
+	i := sort.Search(len(s.Authorities), func(i int) bool { return s.Authorities[i].ID >= id })
+	if i < len(s.Authorities) && s.Authorities[i].ID == id {
+		return s.Authorities[i], true
+	}
+	return Authority{}, false
+}
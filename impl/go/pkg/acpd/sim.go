@@ -0,0 +1,134 @@
+package acpd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
+)
+
+// ─── Local simulation ─────────────────────────────────────────────────────────
+
+// Behavior is how a simulated authority responds to a signing request.
+type Behavior int
+
+const (
+	// Honest authorities sign every well-formed claim their Policy accepts.
+	Honest Behavior = iota
+	// Offline authorities never answer.
+	Offline
+	// Byzantine authorities answer with a signature over a different message.
+	Byzantine
+)
+
+// errOffline is returned by an Offline node.
+var errOffline = errors.New("authority offline")
+
+// Node is one in-process authority: a did:key identity and its private key.
+type Node struct {
+	Authority Authority
+	Behavior  Behavior
+	// Policy, if set, is consulted by honest nodes before signing; a non-nil
+	// error is a refusal.
+	Policy func(Claim) error
+
+	priv ed25519.PrivateKey
+}
+
+// Sign answers a signing request for t according to the node's Behavior.
+func (n *Node) Sign(t *Token) (PartialSig, error) {
+	switch n.Behavior {
+	case Offline:
+		return PartialSig{}, errOffline
+	case Byzantine:
+		forged := *t
+		forged.Claim.Res += "#forged"
+		return SignPartial(&forged, n.Authority.ID, n.priv)
+	}
+	if n.Policy != nil {
+		if err := n.Policy(t.Claim); err != nil {
+			return PartialSig{}, err
+		}
+	}
+	return SignPartial(t, n.Authority.ID, n.priv)
+}
+
+// Simulation is a local Authority Set of n in-process authorities, used to
+// exercise quorum issuance without a network.
+type Simulation struct {
+	Set   *AuthoritySet
+	Nodes []*Node // in Set.Authorities order
+}
+
+// NewSimulation creates n honest authorities with fresh did:key identities
+// and an Authority Set requiring threshold signatures.
+func NewSimulation(setID string, n, threshold int) (*Simulation, error) {
+	nodes := make([]*Node, n)
+	authorities := make([]Authority, n)
+	for i := range nodes {
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		a := Authority{ID: did.EncodeKey(pub), PublicKey: pub}
+		nodes[i] = &Node{Authority: a, priv: priv}
+		authorities[i] = a
+	}
+	set, err := NewAuthoritySet(setID, authorities, threshold)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*Node, n)
+	for _, node := range nodes {
+		byID[node.Authority.ID] = node
+	}
+	for i, a := range set.Authorities {
+		nodes[i] = byID[a.ID]
+	}
+	return &Simulation{Set: set, Nodes: nodes}, nil
+}
+
+// Issue sends claim to every node concurrently and assembles the token once
+// the quorum is reached. Refusals and invalid partial signatures are
+// collected into the returned error when the quorum is not met.
+func (s *Simulation) Issue(claim Claim) (*Token, error) {
+	t, err := NewToken(s.Set, claim)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewCollector(s.Set, t)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, node := range s.Nodes {
+		wg.Add(1)
+		go func(node *Node) {
+			defer wg.Done()
+			ps, err := node.Sign(t)
+			if err == nil {
+				_, err = c.Add(ps)
+			}
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", node.Authority.ID, err))
+				mu.Unlock()
+			}
+		}(node)
+	}
+	wg.Wait()
+
+	tok, err := c.Token()
+	if err != nil {
+		return nil, errors.Join(append([]error{err}, errs...)...)
+	}
+	return tok, nil
+}
@@ -0,0 +1,247 @@
+package acpd
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gowebpki/jcs"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
+)
+
+// Header values of an ACP-D token (ACP-D §6.1).
+const (
+	AlgMultiEd25519 = "Multi-Ed25519"
+	TokenType       = "ACP-D-CAP"
+	TokenVersion    = "1.0"
+
+	// MaxClockSkew is the tolerated iat drift in seconds (as ACP-CT-1.0).
+	MaxClockSkew = 300
+)
+
+// Header is the token header, bound to the issuing Authority Set.
+type Header struct {
+	Alg   string `json:"alg"`
+	Typ   string `json:"typ"`
+	Ver   string `json:"ver"`
+	SetID string `json:"set_id"`
+}
+
+// Claim is the capability claim (ACP-D §6.2).
+type Claim struct {
+	Sub   string                 `json:"sub"` // DID of the subject
+	Res   string                 `json:"res"`
+	Act   []string               `json:"act"`
+	Ctx   map[string]interface{} `json:"ctx,omitempty"`
+	Iat   int64                  `json:"iat"`
+	Exp   int64                  `json:"exp"`
+	Jti   string                 `json:"jti"`
+	Nonce string                 `json:"nonce"`
+}
+
+// PartialSig is one authority's signature over the token digest.
+type PartialSig struct {
+	AuthorityID string `json:"authority_id"`
+	Sig         string `json:"sig"` // base64url Ed25519
+}
+
+// Token is an ACP-D token (ACP-D §6).
+type Token struct {
+	Header         Header       `json:"header"`
+	Claim          Claim        `json:"capability_claim"`
+	ZKProof        string       `json:"zk_proof,omitempty"` // reserved (ACP-D §8); not verified
+	MultiSignature []PartialSig `json:"multi_signature"`
+}
+
+// NewToken returns an unsigned token for claim, issued by set.
+func NewToken(set *AuthoritySet, claim Claim) (*Token, error) {
+	if err := validateClaim(claim); err != nil {
+		return nil, err
+	}
+	return &Token{
+		Header: Header{Alg: AlgMultiEd25519, Typ: TokenType, Ver: TokenVersion, SetID: set.SetID},
+		Claim:  claim,
+	}, nil
+}
+
+func validateClaim(c Claim) error {
+	switch {
+	case c.Sub == "" || c.Res == "" || c.Jti == "" || c.Nonce == "":
+		return fmt.Errorf("%w: sub, res, jti and nonce are required", ErrMalformedClaim)
+	case len(c.Act) == 0:
+		return fmt.Errorf("%w: act must not be empty", ErrMalformedClaim)
+	case c.Iat == 0 || c.Exp <= c.Iat:
+		return fmt.Errorf("%w: need 0 < iat < exp", ErrMalformedClaim)
+	}
+	return nil
+}
+
+// Digest returns SHA-256(JCS({header, capability_claim})), the message every
+// authority signs.
+func (t *Token) Digest() ([]byte, error) {
+	raw, err := json.Marshal(struct {
+		Header Header `json:"header"`
+		Claim  Claim  `json:"capability_claim"`
+	}{t.Header, t.Claim})
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(canonical)
+	return sum[:], nil
+}
+
+// SignPartial produces authorityID's partial signature over t.
+func SignPartial(t *Token, authorityID string, priv ed25519.PrivateKey) (PartialSig, error) {
+	h, err := t.Digest()
+	if err != nil {
+		return PartialSig{}, err
+	}
+	return PartialSig{AuthorityID: authorityID, Sig: base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, h))}, nil
+}
+
+// verifyPartial checks ps against digest h and the set.
+func verifyPartial(set *AuthoritySet, h []byte, ps PartialSig) error {
+	a, ok := set.Member(ps.AuthorityID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownAuthority, ps.AuthorityID)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(ps.Sig)
+	if err != nil || !ed25519.Verify(a.PublicKey, h, sig) {
+		return fmt.Errorf("%w: %s", ErrInvalidPartialSig, ps.AuthorityID)
+	}
+	return nil
+}
+
+// ─── Collection ───────────────────────────────────────────────────────────────
+
+// Collector gathers partial signatures for one token until the quorum is
+// reached. Invalid, foreign and duplicate signatures are rejected. It is safe
+// for concurrent use.
+type Collector struct {
+	mu    sync.Mutex
+	set   *AuthoritySet
+	token Token
+	h     []byte
+	sigs  map[string]PartialSig
+}
+
+// NewCollector starts collecting signatures for t.
+func NewCollector(set *AuthoritySet, t *Token) (*Collector, error) {
+	if t.Header.SetID != set.SetID {
+		return nil, ErrSetMismatch
+	}
+	h, err := t.Digest()
+	if err != nil {
+		return nil, err
+	}
+	return &Collector{set: set, token: *t, h: h, sigs: make(map[string]PartialSig)}, nil
+}
+
+// Add verifies and records ps. It reports whether the quorum is now met.
+func (c *Collector) Add(ps PartialSig) (bool, error) {
+	if err := verifyPartial(c.set, c.h, ps); err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sigs[ps.AuthorityID] = ps
+	return len(c.sigs) >= c.set.Threshold, nil
+}
+
+// Count returns the number of distinct valid signatures collected.
+func (c *Collector) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.sigs)
+}
+
+// Token returns the token with the collected multi-signature, ordered by
+// authority ID, or ErrQuorumNotMet.
+func (c *Collector) Token() (*Token, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.sigs) < c.set.Threshold {
+		return nil, fmt.Errorf("%w: %d of %d signatures", ErrQuorumNotMet, len(c.sigs), c.set.Threshold)
+	}
+	t := c.token
+	t.MultiSignature = make([]PartialSig, 0, len(c.sigs))
+	for _, ps := range c.sigs {
+		t.MultiSignature = append(t.MultiSignature, ps)
+	}
+	sort.Slice(t.MultiSignature, func(i, j int) bool {
+		return t.MultiSignature[i].AuthorityID < t.MultiSignature[j].AuthorityID
+	})
+	return &t, nil
+}
+
+// ─── Verification ─────────────────────────────────────────────────────────────
+
+// VerifyOptions is the runtime context of Verify.
+type VerifyOptions struct {
+	Now        time.Time             // zero = time.Now()
+	IsRevoked  func(jti string) bool // nil = skip
+	NonceStore tokens.NonceStore     // nil = skip replay check
+}
+
+// Verify checks t against the quorum policy of set (ACP-D §10 step 5):
+// header, at least set.Threshold distinct valid member signatures, iat/exp,
+// revocation and nonce replay. Signatures from non-members or that fail to
+// verify do not count towards the quorum.
+func Verify(t *Token, set *AuthoritySet, opts VerifyOptions) error {
+	if t.Header.Alg != AlgMultiEd25519 || t.Header.Typ != TokenType || t.Header.Ver != TokenVersion {
+		return fmt.Errorf("%w: %s/%s/%s", ErrUnsupportedToken, t.Header.Alg, t.Header.Typ, t.Header.Ver)
+	}
+	if t.Header.SetID != set.SetID {
+		return fmt.Errorf("%w: %s", ErrSetMismatch, t.Header.SetID)
+	}
+	if err := validateClaim(t.Claim); err != nil {
+		return err
+	}
+	h, err := t.Digest()
+	if err != nil {
+		return err
+	}
+	valid := make(map[string]bool, len(t.MultiSignature))
+	for _, ps := range t.MultiSignature {
+		if verifyPartial(set, h, ps) == nil {
+			valid[ps.AuthorityID] = true
+		}
+	}
+	if len(valid) < set.Threshold {
+		return fmt.Errorf("%w: %d of %d valid signatures", ErrQuorumNotMet, len(valid), set.Threshold)
+	}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if now.Unix() > t.Claim.Exp {
+		return ErrTokenExpired
+	}
+	if now.Unix() < t.Claim.Iat-MaxClockSkew {
+		return ErrTokenNotYetValid
+	}
+	if opts.IsRevoked != nil && opts.IsRevoked(t.Claim.Jti) {
+		return ErrTokenRevoked
+	}
+	if opts.NonceStore != nil {
+		if err := opts.NonceStore.Claim(t.Claim.Nonce, t.Claim.Exp); err != nil {
+			if errors.Is(err, tokens.ErrNonceReplay) {
+				return ErrTokenReplay
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"crypto/ed25519"
 	"crypto/rand"
@@ -49,6 +50,12 @@ const (
 	ErrAGENT003 = "AGENT-003" // authority_domain not registered
 	ErrAGENT004 = "AGENT-004" // agent_id already registered
 	ErrAGENT005 = "AGENT-005" // agent_id not found
+	ErrAGENT006 = "AGENT-006" // key rotation not endorsed by the current key or new key
+	ErrAGENT007 = "AGENT-007" // public key already used by this agent
+
+	// DID errors
+	ErrDID001 = "DID-001" // Malformed DID or unsupported DID method
+	ErrDID002 = "DID-002" // DID could not be resolved
 
 	// State transition errors
 	ErrSTATE001 = "STATE-001" // Invalid state transition
@@ -68,13 +75,14 @@ const (
 // ─── Response Envelopes (ACP-API-1.0 §3) ──────────────────────────────────────────────
 
 // Response is the standard ACP-API-1.0 success envelope.
-// The Sig field covers: acp_version, request_id, timestamp, data
+// The Sig field covers: acp_version, request_id, timestamp, data (and kid)
 // via Ed25519 over SHA-256(JCS(signable)). Omitted when no private key.
 type Response struct {
 	ACPVersion string      `json:"acp_version"`
 	RequestID  string      `json:"request_id"`
 	Timestamp  int64       `json:"timestamp"`
 	Data       interface{} `json:"data"`
+	KID        string      `json:"kid,omitempty"` // signing key ID (institution keyring)
 	Sig        string      `json:"sig,omitempty"`
 }
 
@@ -140,6 +148,19 @@ func GetRequestID(r *http.Request) string {
 //   - The request ID is read from the context (set by Middleware).
 //   - Callers must NOT call w.WriteHeader before this function.
 func WriteSuccess(w http.ResponseWriter, r *http.Request, status int, data interface{}, privKey ed25519.PrivateKey) {
+	WriteSignedSuccess(w, r, status, data, "", privKey)
+}
+
+// WriteSignedSuccess is WriteSuccess with the signing key identified by kid
+// (its ID in the institution keyring). The kid is covered by the signature.
+// An empty kid produces the same envelope as WriteSuccess.
+func WriteSignedSuccess(w http.ResponseWriter, r *http.Request, status int, data interface{}, kid string, privKey ed25519.PrivateKey) {
+	WriteRaw(w, status, SignedSuccessBody(r, data, kid, privKey))
+}
+
+// SignedSuccessBody returns the encoded envelope WriteSignedSuccess writes,
+// for callers that keep the response to send it again (idempotent replay).
+func SignedSuccessBody(r *http.Request, data interface{}, kid string, privKey ed25519.PrivateKey) []byte {
 	reqID := GetRequestID(r)
 	resp := Response{
 		ACPVersion: "1.0",
@@ -147,16 +168,24 @@ func WriteSuccess(w http.ResponseWriter, r *http.Request, status int, data inter
 		Timestamp:  time.Now().Unix(),
 		Data:       data,
 	}
-
 	if privKey != nil {
+		resp.KID = kid
 		if sig, err := signResponse(resp, privKey); err == nil {
 			resp.Sig = sig
 		}
 	}
 
+	var buf bytes.Buffer
+	_ = json.NewEncoder(&buf).Encode(resp)
+	return buf.Bytes()
+}
+
+// WriteRaw writes an already encoded JSON response body, e.g. one built by
+// SignedSuccessBody.
+func WriteRaw(w http.ResponseWriter, status int, body []byte) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(resp)
+	_, _ = w.Write(body)
 }
 
 // WriteError writes an ACP-API-1.0 error response (no sig, per §3).
@@ -202,12 +231,14 @@ func WriteErrorDetail(w http.ResponseWriter, r *http.Request, status int, code,
 // ─── Response Signing ─────────────────────────────────────────────────────────────────
 
 // signableResponse is the struct whose JCS serialization is signed.
-// Per ACP-API-1.0 §3: covers acp_version, request_id, timestamp, data.
+// Per ACP-API-1.0 §3: covers acp_version, request_id, timestamp, data
+// (and kid when the response is signed by a keyring key).
 type signableResponse struct {
 	ACPVersion string      `json:"acp_version"`
 	RequestID  string      `json:"request_id"`
 	Timestamp  int64       `json:"timestamp"`
 	Data       interface{} `json:"data"`
+	KID        string      `json:"kid,omitempty"`
 }
 
 // signResponse computes Ed25519(SHA-256(JCS(signable_fields))) and returns
@@ -218,6 +249,7 @@ func signResponse(resp Response, privKey ed25519.PrivateKey) (string, error) {
 		RequestID:  resp.RequestID,
 		Timestamp:  resp.Timestamp,
 		Data:       resp.Data,
+		KID:        resp.KID,
 	}
 
 	// Marshal to JSON first, then canonicalize with JCS.
@@ -243,6 +275,7 @@ func VerifyResponseSig(resp Response, pubKey ed25519.PublicKey) error {
 		RequestID:  resp.RequestID,
 		Timestamp:  resp.Timestamp,
 		Data:       resp.Data,
+		KID:        resp.KID,
 	}
 	raw, err := json.Marshal(s)
 	if err != nil {
@@ -0,0 +1,342 @@
+// Package budget tracks cumulative spend against policy budgets.
+//
+// ACP-RISK scores each request on its own amount, so an agent can stay under
+// a per-request limit while moving an unbounded total in many small
+// requests. A Budget caps the total amount approved for one subject — an
+// agent, the root delegator of its delegation chain, or a resource — over a
+// rolling window. Budgets are part of the active policy snapshot (ACP-PSN-1.0).
+//
+// The Tracker records every approved (or escalated) amount as a Spend. A
+// spend counts against its budgets from the moment it is reserved until it
+// leaves the window; spends whose execution failed, or whose escalation was
+// denied, are released and stop counting. Consumed spends keep counting but
+// are reported separately so operators can tell authorized from executed.
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+)
+
+// ─── Errors ───────────────────────────────────────────────────────────────────
+
+var (
+	// ErrBudgetExceeded is returned when a spend would take a subject over a
+	// budget. Its code is the reason_code of the resulting DENIED decision.
+	ErrBudgetExceeded = errors.New("RISK-010: cumulative spend budget exceeded")
+
+	// ErrCurrencyMismatch is returned when every budget of a scope covering a
+	// spend's subject is in another currency, so the spend could not be
+	// accounted against them. It is a budget denial (RISK-010).
+	ErrCurrencyMismatch = fmt.Errorf("%w: currency not covered by the subject's budgets", ErrBudgetExceeded)
+
+	// ErrInvalidSpend is returned for a spend whose amount is not a positive,
+	// finite number.
+	ErrInvalidSpend = errors.New("budget: amount must be a positive finite number")
+
+	// ErrInvalidBudget is returned by Validate for a malformed budget.
+	ErrInvalidBudget = errors.New("budget: invalid budget")
+
+	// ErrSpendNotFound is returned when settling an unknown spend.
+	ErrSpendNotFound = errors.New("budget: spend not found")
+)
+
+// ─── Budgets ──────────────────────────────────────────────────────────────────
+
+// Scope is what a budget is accounted per.
+type Scope string
+
+const (
+	ScopeAgent     Scope = "agent"     // the requesting agent
+	ScopeDelegator Scope = "delegator" // the root delegator of the agent's delegation chain
+	ScopeResource  Scope = "resource"  // the target resource
+)
+
+// DefaultNearLimit is the fraction of a budget above which a spend is flagged
+// as near the limit (F_hist AmountNearLimit) when the budget sets none.
+const DefaultNearLimit = 0.8
+
+// Budget caps the total amount per subject of Scope over a rolling window.
+type Budget struct {
+	ID            string  `json:"budget_id"`
+	Scope         Scope   `json:"scope"`
+	Subject       string  `json:"subject,omitempty"`  // "" = every subject of Scope, each separately
+	Currency      string  `json:"currency,omitempty"` // "" = any currency
+	Limit         float64 `json:"limit"`
+	WindowSeconds int64   `json:"window_seconds"`
+	NearLimit     float64 `json:"near_limit,omitempty"` // fraction in (0,1]; 0 = DefaultNearLimit
+}
+
+// Validate checks the fields of b.
+func (b Budget) Validate() error {
+	switch {
+	case b.ID == "":
+		return fmt.Errorf("%w: budget_id is required", ErrInvalidBudget)
+	case b.Scope != ScopeAgent && b.Scope != ScopeDelegator && b.Scope != ScopeResource:
+		return fmt.Errorf("%w: %s: scope must be agent, delegator or resource", ErrInvalidBudget, b.ID)
+	case b.Limit <= 0:
+		return fmt.Errorf("%w: %s: limit must be positive", ErrInvalidBudget, b.ID)
+	case b.WindowSeconds <= 0:
+		return fmt.Errorf("%w: %s: window_seconds must be positive", ErrInvalidBudget, b.ID)
+	case b.NearLimit < 0 || b.NearLimit > 1:
+		return fmt.Errorf("%w: %s: near_limit must be in [0, 1]", ErrInvalidBudget, b.ID)
+	}
+	return nil
+}
+
+// ValidateAll validates every budget and rejects duplicate IDs.
+func ValidateAll(budgets []Budget) error {
+	seen := make(map[string]bool, len(budgets))
+	for _, b := range budgets {
+		if err := b.Validate(); err != nil {
+			return err
+		}
+		if seen[b.ID] {
+			return fmt.Errorf("%w: duplicate budget_id %s", ErrInvalidBudget, b.ID)
+		}
+		seen[b.ID] = true
+	}
+	return nil
+}
+
+// subject returns the value of s that b is accounted per.
+func (b Budget) subject(s Spend) string {
+	switch b.Scope {
+	case ScopeAgent:
+		return s.AgentID
+	case ScopeDelegator:
+		return s.Delegator
+	default:
+		return s.Resource
+	}
+}
+
+// covers reports whether b is accounted for the subject of s, whatever the
+// currency.
+func (b Budget) covers(s Spend) bool {
+	subj := b.subject(s)
+	return subj != "" && (b.Subject == "" || b.Subject == subj)
+}
+
+// inCurrency reports whether b counts amounts in currency.
+func (b Budget) inCurrency(currency string) bool {
+	return b.Currency == "" || NormalizeCurrency(b.Currency) == NormalizeCurrency(currency)
+}
+
+// NormalizeCurrency returns the canonical form of a currency code, so that
+// "usd" and " USD" name the same currency.
+func NormalizeCurrency(c string) string {
+	return strings.ToUpper(strings.TrimSpace(c))
+}
+
+func (b Budget) nearLimit() float64 {
+	if b.NearLimit == 0 {
+		return DefaultNearLimit
+	}
+	return b.NearLimit
+}
+
+// ─── Spends ───────────────────────────────────────────────────────────────────
+
+// Spend states.
+const (
+	StateApproved = "approved" // authorized, not yet executed
+	StateConsumed = "consumed" // execution reported
+	StateReleased = "released" // execution failed or escalation denied; no longer counts
+)
+
+// Spend is one authorized amount.
+type Spend struct {
+	ID        string  `json:"spend_id"` // ET ID, or escalation ID while pending
+	AgentID   string  `json:"agent_id"`
+	Delegator string  `json:"delegator,omitempty"`
+	Resource  string  `json:"resource"`
+	Amount    float64 `json:"amount"`
+	Currency  string  `json:"currency,omitempty"`
+	At        int64   `json:"at"` // Unix seconds
+	State     string  `json:"state"`
+}
+
+// Usage is the state of one budget for one subject.
+type Usage struct {
+	BudgetID  string  `json:"budget_id"`
+	Scope     Scope   `json:"scope"`
+	Subject   string  `json:"subject"`
+	Limit     float64 `json:"limit"`
+	Approved  float64 `json:"approved"` // reserved, not yet executed
+	Consumed  float64 `json:"consumed"`
+	Remaining float64 `json:"remaining"`
+}
+
+// Used returns the amount counting against the budget.
+func (u Usage) Used() float64 { return u.Approved + u.Consumed }
+
+// Check is the outcome of evaluating a spend against the budgets.
+type Check struct {
+	Usage     []Usage `json:"usage"`              // applicable budgets, before the spend
+	NearLimit bool    `json:"amount_near_limit"`  // some budget would pass its near-limit fraction
+	Exceeded  *Usage  `json:"exceeded,omitempty"` // first budget the spend would exceed
+}
+
+// ─── Tracker ──────────────────────────────────────────────────────────────────
+
+// Tracker records spends and evaluates them against budgets. It is safe for
+// concurrent use; Reserve checks and records atomically.
+type Tracker struct {
+	mu     sync.Mutex
+	spends map[string]*Spend
+	order  []*Spend // reservation order
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{spends: make(map[string]*Spend)}
+}
+
+// Check evaluates s against budgets as of s.At without recording it. It
+// returns ErrInvalidSpend for a non-positive or non-finite amount,
+// ErrCurrencyMismatch when a scope covering the subject has budgets only in
+// other currencies, and ErrBudgetExceeded when s would exceed a budget
+// (c.Exceeded).
+func (t *Tracker) Check(budgets []Budget, s Spend) (Check, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.check(budgets, s)
+}
+
+func (t *Tracker) check(budgets []Budget, s Spend) (Check, error) {
+	c := Check{Usage: []Usage{}}
+	if !(s.Amount > 0) || math.IsInf(s.Amount, 0) {
+		return c, fmt.Errorf("%w: %v", ErrInvalidSpend, s.Amount)
+	}
+	// Per scope, the currencies of the covering budgets and whether one of
+	// them counts s: a scope capped only in other currencies must not be
+	// bypassed by paying in a new one.
+	scoped := map[Scope][]string{}
+	inCurrency := map[Scope]bool{}
+	for _, b := range budgets {
+		if !b.covers(s) {
+			continue
+		}
+		if b.Currency != "" {
+			scoped[b.Scope] = append(scoped[b.Scope], NormalizeCurrency(b.Currency))
+		}
+		if !b.inCurrency(s.Currency) {
+			continue
+		}
+		inCurrency[b.Scope] = true
+		u := t.usage(b, b.subject(s), s.At)
+		c.Usage = append(c.Usage, u)
+		after := u.Used() + s.Amount
+		if after > b.Limit && c.Exceeded == nil {
+			exceeded := u
+			c.Exceeded = &exceeded
+		}
+		if after >= b.nearLimit()*b.Limit {
+			c.NearLimit = true
+		}
+	}
+	for _, scope := range []Scope{ScopeAgent, ScopeDelegator, ScopeResource} {
+		if currencies := scoped[scope]; len(currencies) > 0 && !inCurrency[scope] {
+			return c, fmt.Errorf("%w: %q, %s budgets in %s",
+				ErrCurrencyMismatch, s.Currency, scope, strings.Join(currencies, ", "))
+		}
+	}
+	if e := c.Exceeded; e != nil {
+		return c, fmt.Errorf("%w: %s for %s %s: %.2f used of %.2f, requested %.2f",
+			ErrBudgetExceeded, e.BudgetID, e.Scope, e.Subject, e.Used(), e.Limit, s.Amount)
+	}
+	return c, nil
+}
+
+// usage sums the live spends of subject under b in the window ending at now.
+func (t *Tracker) usage(b Budget, subject string, now int64) Usage {
+	u := Usage{BudgetID: b.ID, Scope: b.Scope, Subject: subject, Limit: b.Limit}
+	since := now - b.WindowSeconds
+	for _, s := range t.order {
+		if s.At <= since || s.State == StateReleased || b.subject(*s) != subject || !b.inCurrency(s.Currency) {
+			continue
+		}
+		if s.State == StateConsumed {
+			u.Consumed += s.Amount
+		} else {
+			u.Approved += s.Amount
+		}
+	}
+	u.Remaining = b.Limit - u.Used()
+	if u.Remaining < 0 {
+		u.Remaining = 0
+	}
+	return u
+}
+
+// Reserve records s in state approved unless Check rejects it, in which case
+// nothing is recorded and Check's error is returned. s.ID must be unique.
+func (t *Tracker) Reserve(budgets []Budget, s Spend) (Check, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, err := t.check(budgets, s)
+	if err != nil {
+		return c, err
+	}
+	if _, dup := t.spends[s.ID]; dup {
+		return c, fmt.Errorf("budget: spend %s already recorded", s.ID)
+	}
+	s.State = StateApproved
+	s.Currency = NormalizeCurrency(s.Currency)
+	t.spends[s.ID] = &s
+	t.order = append(t.order, &s)
+	return c, nil
+}
+
+// Consume marks an approved spend as executed.
+func (t *Tracker) Consume(id string) error {
+	return t.settle(id, StateConsumed)
+}
+
+// Release stops an approved spend from counting against its budgets.
+func (t *Tracker) Release(id string) error {
+	return t.settle(id, StateReleased)
+}
+
+func (t *Tracker) settle(id, state string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.spends[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSpendNotFound, id)
+	}
+	if s.State == StateApproved {
+		s.State = state
+	}
+	return nil
+}
+
+// Get returns the spend with the given ID.
+func (t *Tracker) Get(id string) (Spend, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.spends[id]
+	if !ok {
+		return Spend{}, false
+	}
+	return *s, true
+}
+
+// Usage returns the state of every budget of scope that applies to subject
+// as of now. Budgets with a currency filter are reported per that currency.
+func (t *Tracker) Usage(budgets []Budget, scope Scope, subject string, now int64) []Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := []Usage{}
+	for _, b := range budgets {
+		if b.Scope != scope || (b.Subject != "" && b.Subject != subject) {
+			continue
+		}
+		out = append(out, t.usage(b, subject, now))
+	}
+	return out
+}
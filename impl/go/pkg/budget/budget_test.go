@@ -0,0 +1,140 @@
+package budget_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/budget"
+)
+
+var budgets = []budget.Budget{
+	{ID: "per-agent-daily", Scope: budget.ScopeAgent, Currency: "USD", Limit: 5000, WindowSeconds: 86400},
+	{ID: "root-hourly", Scope: budget.ScopeDelegator, Limit: 8000, WindowSeconds: 3600},
+}
+
+func spend(id, agent string, amount float64, at int64) budget.Spend {
+	return budget.Spend{ID: id, AgentID: agent, Delegator: "root-1", Resource: "org.bank/accounts", Amount: amount, Currency: "USD", At: at}
+}
+
+func TestTracker_ManySmallSpendsHitAgentBudget(t *testing.T) {
+	tr := budget.NewTracker()
+	const now = 1_000_000
+	for i := 0; i < 5; i++ {
+		c, err := tr.Reserve(budgets, spend(string(rune('a'+i)), "agent-1", 900, now+int64(i)))
+		if err != nil {
+			t.Fatalf("spend %d: %v", i, err)
+		}
+		// 900×4 = 3600 < 4000 (80 %); the fifth brings the total to 4500.
+		if want := i == 4; c.NearLimit != want {
+			t.Errorf("spend %d: NearLimit = %v, want %v", i, c.NearLimit, want)
+		}
+	}
+	_, err := tr.Reserve(budgets, spend("f", "agent-1", 900, now+5))
+	if !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Fatalf("sixth spend: err = %v, want ErrBudgetExceeded", err)
+	}
+	if _, ok := tr.Get("f"); ok {
+		t.Error("rejected spend was recorded")
+	}
+
+	// A released spend stops counting; a consumed one keeps counting.
+	if err := tr.Release("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Consume("b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Reserve(budgets, spend("f", "agent-1", 900, now+6)); err != nil {
+		t.Errorf("after release: %v", err)
+	}
+	u := tr.Usage(budgets, budget.ScopeAgent, "agent-1", now+6)
+	if len(u) != 1 || u[0].Consumed != 900 || u[0].Approved != 3600 || u[0].Remaining != 500 {
+		t.Errorf("usage = %+v", u)
+	}
+
+	// The window rolls: a day later the agent budget is free again.
+	if _, err := tr.Reserve(budgets, spend("g", "agent-1", 4000, now+86400+10)); err != nil {
+		t.Errorf("next day: %v", err)
+	}
+}
+
+func TestTracker_DelegatorBudgetSpansAgents(t *testing.T) {
+	tr := budget.NewTracker()
+	const now = 2_000_000
+	if _, err := tr.Reserve(budgets, spend("a", "agent-1", 4500, now)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := tr.Reserve(budgets, spend("b", "agent-2", 4000, now+1))
+	if !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Fatalf("second agent under same root: err = %v", err)
+	}
+
+	// A different currency is outside the USD agent budget: with no EUR
+	// agent budget it is denied; with one it counts against that budget and
+	// the currency-agnostic delegator budget.
+	other := spend("c", "agent-2", 3000, now+2)
+	other.Currency = "EUR"
+	if _, err := tr.Check(budgets, other); !errors.Is(err, budget.ErrCurrencyMismatch) {
+		t.Errorf("EUR spend without EUR budget: err = %v", err)
+	}
+	withEUR := append([]budget.Budget{{ID: "per-agent-eur", Scope: budget.ScopeAgent, Currency: "EUR", Limit: 5000, WindowSeconds: 86400}}, budgets...)
+	c, err := tr.Check(withEUR, other)
+	if err != nil || len(c.Usage) != 2 || c.Usage[0].BudgetID != "per-agent-eur" || c.Usage[1].BudgetID != "root-hourly" {
+		t.Errorf("EUR spend check = %+v, %v", c, err)
+	}
+}
+
+func TestTracker_InvalidSpendAndCurrency(t *testing.T) {
+	tr := budget.NewTracker()
+	const now = 3_000_000
+	for _, amount := range []float64{0, -100, math.NaN(), math.Inf(1)} {
+		if _, err := tr.Reserve(budgets, spend("x", "agent-1", amount, now)); !errors.Is(err, budget.ErrInvalidSpend) {
+			t.Errorf("amount %v: err = %v", amount, err)
+		}
+	}
+
+	// Currency codes compare case-insensitively: "usd" counts against USD.
+	lower := spend("a", "agent-1", 4000, now)
+	lower.Currency = "usd"
+	if _, err := tr.Reserve(budgets, lower); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.Check(budgets, spend("b", "agent-1", 2000, now+1)); !errors.Is(err, budget.ErrBudgetExceeded) {
+		t.Errorf("USD after usd: err = %v", err)
+	}
+
+	// A subject whose budgets are all scoped to other currencies is denied
+	// rather than left uncapped.
+	usdOnly := budgets[:1]
+	for _, cur := range []string{"EUR", ""} {
+		s := spend("c", "agent-1", 1, now+2)
+		s.Currency = cur
+		_, err := tr.Check(usdOnly, s)
+		if !errors.Is(err, budget.ErrCurrencyMismatch) || !errors.Is(err, budget.ErrBudgetExceeded) {
+			t.Errorf("currency %q: err = %v", cur, err)
+		}
+	}
+	if u := tr.Usage(usdOnly, budget.ScopeAgent, "agent-1", now+2); len(u) != 1 || u[0].Used() != 4000 {
+		t.Errorf("usage after rejected spends = %+v", u)
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	bad := [][]budget.Budget{
+		{{ID: "", Scope: budget.ScopeAgent, Limit: 1, WindowSeconds: 1}},
+		{{ID: "x", Scope: "tenant", Limit: 1, WindowSeconds: 1}},
+		{{ID: "x", Scope: budget.ScopeAgent, Limit: 0, WindowSeconds: 1}},
+		{{ID: "x", Scope: budget.ScopeAgent, Limit: 1, WindowSeconds: 0}},
+		{{ID: "x", Scope: budget.ScopeAgent, Limit: 1, WindowSeconds: 1, NearLimit: 2}},
+		{budgets[0], budgets[0]},
+	}
+	for i, bs := range bad {
+		if err := budget.ValidateAll(bs); !errors.Is(err, budget.ErrInvalidBudget) {
+			t.Errorf("case %d: err = %v", i, err)
+		}
+	}
+	if err := budget.ValidateAll(budgets); err != nil {
+		t.Error(err)
+	}
+}
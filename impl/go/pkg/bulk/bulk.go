@@ -1,11 +1,21 @@
 // Package bulk implements ACP-BULK-1.0 (batch authorization + bulk liability query).
 //
 // Provides request validation for batch authorization operations and bulk
-// liability queries. This is a pass-through layer — no in-memory store.
+// liability queries, and opaque query cursors (§7). This is a pass-through
+// layer — no in-memory store. The per-institution bulk rate limit (§4) is
+// enforced by pkg/ratelimit as ratelimit.BulkLimit.
 package bulk
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 )
 
 // ─── Constants ────────────────────────────────────────────────────────────────
@@ -16,6 +26,12 @@ const (
 
 	// MaxLiabilityPageSize is the maximum Limit value for a liability query.
 	MaxLiabilityPageSize = 1000
+
+	// MaxRequestsPerSecond is the bulk request rate limit per institution (§4).
+	MaxRequestsPerSecond = 10
+
+	// CursorTTL is how long a liability query cursor stays valid (§7.1).
+	CursorTTL = 10 * time.Minute
 )
 
 // ─── Error Sentinels (ACP-BULK-1.0) ──────────────────────────────────────────
@@ -26,6 +42,10 @@ var (
 	ErrPartialFailure     = errors.New("BULK-003: one or more requests in batch failed")
 	ErrQueryTooLarge      = errors.New("BULK-004: query result set too large, reduce limit")
 	ErrEmptyBatch         = errors.New("BULK-005: batch must contain at least one item")
+
+	// ErrInvalidCursor is returned for malformed or expired cursors, or cursors
+	// presented with filters different from the original query (§7.2).
+	ErrInvalidCursor = errors.New("BULK-006: invalid or expired cursor")
 )
 
 // ─── Types ────────────────────────────────────────────────────────────────────
@@ -51,6 +71,10 @@ type ItemResult struct {
 	Decision   string   `json:"decision"`    // "APPROVED" | "DENIED" | "ESCALATED"
 	RiskScore  *float64 `json:"risk_score"`  // nil if not applicable
 	ReasonCode string   `json:"reason_code"`
+	// ExecutionToken is the ET issued for an APPROVED item (ACP-EXEC-1.0).
+	ExecutionToken interface{} `json:"execution_token,omitempty"`
+	// EscalationID identifies the escalation created for an ESCALATED item.
+	EscalationID string `json:"escalation_id,omitempty"`
 }
 
 // BatchResponse summarizes the outcome of a BatchRequest.
@@ -130,3 +154,55 @@ func ValidateLiabilityQuery(req LiabilityQueryRequest) error {
 	}
 	return nil
 }
+
+// ─── Query Cursors (§7) ───────────────────────────────────────────────────────
+
+// cursorState is the decoded content of an opaque cursor.
+type cursorState struct {
+	Filter   string `json:"f"` // QueryFingerprint of the original request
+	AfterSeq int64  `json:"s"` // last ledger_sequence returned
+	Expires  int64  `json:"e"` // unix seconds
+}
+
+// QueryFingerprint identifies the filters of a liability query (agent_ids,
+// from_ts, to_ts) independently of agent ordering, limit and cursor.
+func QueryFingerprint(req LiabilityQueryRequest) string {
+	ids := append([]string(nil), req.AgentIDs...)
+	sort.Strings(ids)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d", strings.Join(ids, ","), req.FromTS, req.ToTS)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// EncodeCursor returns an opaque cursor resuming req after ledger sequence afterSeq.
+func EncodeCursor(req LiabilityQueryRequest, afterSeq int64, now time.Time) string {
+	raw, _ := json.Marshal(cursorState{
+		Filter:   QueryFingerprint(req),
+		AfterSeq: afterSeq,
+		Expires:  now.Add(CursorTTL).Unix(),
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor returns the ledger sequence to resume after. An empty cursor
+// yields 0 (first page). Returns ErrInvalidCursor if the cursor is malformed,
+// expired, or was issued for different filters.
+func DecodeCursor(req LiabilityQueryRequest, now time.Time) (int64, error) {
+	if req.Cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+	var c cursorState
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return 0, fmt.Errorf("%w: malformed", ErrInvalidCursor)
+	}
+	if now.Unix() > c.Expires {
+		return 0, fmt.Errorf("%w: expired", ErrInvalidCursor)
+	}
+	if c.Filter != QueryFingerprint(req) {
+		return 0, fmt.Errorf("%w: filters differ from original query", ErrInvalidCursor)
+	}
+	return c.AfterSeq, nil
+}
@@ -0,0 +1,49 @@
+package bulk_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/bulk"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	now := time.Unix(5000, 0)
+	req := bulk.LiabilityQueryRequest{AgentIDs: []string{"b", "a"}, FromTS: 10, ToTS: 20}
+	req.Cursor = bulk.EncodeCursor(req, 42, now)
+
+	seq, err := bulk.DecodeCursor(req, now.Add(time.Minute))
+	if err != nil || seq != 42 {
+		t.Fatalf("DecodeCursor = %d, %v; want 42", seq, err)
+	}
+	// Agent order does not matter.
+	reordered := req
+	reordered.AgentIDs = []string{"a", "b"}
+	if _, err := bulk.DecodeCursor(reordered, now); err != nil {
+		t.Errorf("reordered agent_ids rejected: %v", err)
+	}
+}
+
+func TestCursor_Rejections(t *testing.T) {
+	now := time.Unix(5000, 0)
+	req := bulk.LiabilityQueryRequest{AgentIDs: []string{"a"}}
+	req.Cursor = bulk.EncodeCursor(req, 7, now)
+
+	if _, err := bulk.DecodeCursor(req, now.Add(bulk.CursorTTL+time.Second)); !errors.Is(err, bulk.ErrInvalidCursor) {
+		t.Errorf("expired cursor: err = %v", err)
+	}
+	changed := req
+	changed.AgentIDs = []string{"a", "z"}
+	if _, err := bulk.DecodeCursor(changed, now); !errors.Is(err, bulk.ErrInvalidCursor) {
+		t.Errorf("changed filters: err = %v", err)
+	}
+	garbage := req
+	garbage.Cursor = "!!not-base64!!"
+	if _, err := bulk.DecodeCursor(garbage, now); !errors.Is(err, bulk.ErrInvalidCursor) {
+		t.Errorf("malformed cursor: err = %v", err)
+	}
+	if seq, err := bulk.DecodeCursor(bulk.LiabilityQueryRequest{}, now); err != nil || seq != 0 {
+		t.Errorf("empty cursor = %d, %v; want 0, nil", seq, err)
+	}
+}
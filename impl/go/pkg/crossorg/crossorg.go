@@ -12,6 +12,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -45,6 +47,13 @@ const (
 	ActionReputationQuery     = "reputation_query"
 )
 
+// ACK status values (ACP-CROSS-ORG-1.1 §7.4).
+const (
+	AckAccepted      = "accepted"
+	AckRejected      = "rejected"
+	AckPendingReview = "pending_review"
+)
+
 var validActionTypes = map[string]struct{}{
 	ActionDataShare:           {},
 	ActionServiceInvocation:   {},
@@ -116,6 +125,7 @@ type signableBundle struct {
 // CrossOrgAck is a signed acknowledgement for a cross-organizational interaction.
 type CrossOrgAck struct {
 	AckID               string `json:"ack_id"`
+	BundleID            string `json:"bundle_id"`
 	OriginalEventID     string `json:"original_event_id"`
 	TargetInstitutionID string `json:"target_institution_id"`
 	SourceInstitutionID string `json:"source_institution_id"`
@@ -128,6 +138,7 @@ type CrossOrgAck struct {
 // signableAck excludes Sig for signing.
 type signableAck struct {
 	AckID               string `json:"ack_id"`
+	BundleID            string `json:"bundle_id"`
 	OriginalEventID     string `json:"original_event_id"`
 	TargetInstitutionID string `json:"target_institution_id"`
 	SourceInstitutionID string `json:"source_institution_id"`
@@ -144,12 +155,42 @@ type ReceiveBundleRequest struct {
 
 // ─── Core Functions ───────────────────────────────────────────────────────────
 
-// IsValidActionType returns true if t is a recognised cross-org action type.
+// IsValidActionType returns true if t is a recognised cross-org action type
+// or an institution-defined "x:" extension (ACP-CROSS-ORG-1.1 §4.4).
 func IsValidActionType(t string) bool {
+	if strings.HasPrefix(t, "x:") && len(t) > 2 {
+		return true
+	}
 	_, ok := validActionTypes[t]
 	return ok
 }
 
+// SignInteraction signs a single interaction and sets ev.Sig.
+func SignInteraction(ev *CrossOrgInteraction, privKey ed25519.PrivateKey) error {
+	digest, err := interactionDigest(*ev)
+	if err != nil {
+		return err
+	}
+	ev.Sig = base64.RawURLEncoding.EncodeToString(ed25519.Sign(privKey, digest[:]))
+	return nil
+}
+
+// VerifyInteraction verifies the Ed25519 signature on a single interaction.
+func VerifyInteraction(ev CrossOrgInteraction, pubKey ed25519.PublicKey) error {
+	sigBytes, err := base64.RawURLEncoding.DecodeString(ev.Sig)
+	if err != nil {
+		return fmt.Errorf("%w: decode sig: %v", ErrEventSigInvalid, err)
+	}
+	digest, err := interactionDigest(ev)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pubKey, digest[:], sigBytes) {
+		return fmt.Errorf("%w: event %s", ErrEventSigInvalid, ev.EventID)
+	}
+	return nil
+}
+
 // SignBundle signs the bundle and sets bundle.Sig.
 func SignBundle(bundle *CrossOrgBundle, privKey ed25519.PrivateKey) error {
 	sig, err := signBundle(*bundle, privKey)
@@ -192,9 +233,9 @@ func VerifyBundle(bundle CrossOrgBundle, pubKey ed25519.PublicKey) error {
 	return nil
 }
 
-// BuildAck creates a signed CrossOrgAck.
+// BuildAck creates a signed CrossOrgAck for one event of bundleID.
 func BuildAck(
-	eventID, targetInstitutionID, sourceInstitutionID, status string,
+	bundleID, eventID, targetInstitutionID, sourceInstitutionID, status string,
 	ledgerSeq int64,
 	privKey ed25519.PrivateKey,
 ) (CrossOrgAck, error) {
@@ -205,6 +246,7 @@ func BuildAck(
 
 	ack := CrossOrgAck{
 		AckID:               ackID,
+		BundleID:            bundleID,
 		OriginalEventID:     eventID,
 		TargetInstitutionID: targetInstitutionID,
 		SourceInstitutionID: sourceInstitutionID,
@@ -230,6 +272,7 @@ func VerifyAck(ack CrossOrgAck, pubKey ed25519.PublicKey) error {
 
 	s := signableAck{
 		AckID:               ack.AckID,
+		BundleID:            ack.BundleID,
 		OriginalEventID:     ack.OriginalEventID,
 		TargetInstitutionID: ack.TargetInstitutionID,
 		SourceInstitutionID: ack.SourceInstitutionID,
@@ -255,6 +298,32 @@ func VerifyAck(ack CrossOrgAck, pubKey ed25519.PublicKey) error {
 
 // ─── Signing Helpers ─────────────────────────────────────────────────────────
 
+func interactionDigest(ev CrossOrgInteraction) ([32]byte, error) {
+	s := signableInteraction{
+		EventID:             ev.EventID,
+		Timestamp:           ev.Timestamp,
+		SourceInstitutionID: ev.SourceInstitutionID,
+		TargetInstitutionID: ev.TargetInstitutionID,
+		ActionType:          ev.ActionType,
+		PayloadHash:         ev.PayloadHash,
+		DelegationChain:     ev.DelegationChain,
+		AuthorizationID:     ev.AuthorizationID,
+		LiabilityRecordID:   ev.LiabilityRecordID,
+		AckRequired:         ev.AckRequired,
+		Metadata:            ev.Metadata,
+		Sig:                 "",
+	}
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("crossorg: marshal event: %w", err)
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("crossorg: jcs event: %w", err)
+	}
+	return sha256.Sum256(canonical), nil
+}
+
 func signBundle(bundle CrossOrgBundle, privKey ed25519.PrivateKey) (string, error) {
 	s := signableBundle{
 		BundleID:            bundle.BundleID,
@@ -282,6 +351,7 @@ func signBundle(bundle CrossOrgBundle, privKey ed25519.PrivateKey) (string, erro
 func signAck(ack CrossOrgAck, privKey ed25519.PrivateKey) (string, error) {
 	s := signableAck{
 		AckID:               ack.AckID,
+		BundleID:            ack.BundleID,
 		OriginalEventID:     ack.OriginalEventID,
 		TargetInstitutionID: ack.TargetInstitutionID,
 		SourceInstitutionID: ack.SourceInstitutionID,
@@ -373,6 +443,20 @@ func (s *InMemoryCrossOrgStore) StoreAck(ack CrossOrgAck) error {
 	return nil
 }
 
+// AcksForBundle returns the ACKs stored for bundleID, ordered by ledger_sequence.
+func (s *InMemoryCrossOrgStore) AcksForBundle(bundleID string) []CrossOrgAck {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []CrossOrgAck
+	for _, a := range s.acks {
+		if a.BundleID == bundleID {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].LedgerSequence < result[j].LedgerSequence })
+	return result
+}
+
 // GetAck retrieves an ACK by AckID.
 func (s *InMemoryCrossOrgStore) GetAck(ackID string) (CrossOrgAck, bool) {
 	s.mu.RLock()
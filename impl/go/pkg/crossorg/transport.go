@@ -0,0 +1,761 @@
+package crossorg
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	acpapi "github.com/chelof100/acp-framework/acp-go/pkg/api"
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+)
+
+// ─── Transport (ACP-CROSS-ORG-1.1 §7–§8) ─────────────────────────────────────
+//
+// Bundles travel source → target over HTTP:
+//
+//	POST {peer.Endpoint}/acp/v1/crossorg/bundles   body: ReceiveBundleRequest
+//
+// The target verifies the bundle, records each interaction in its own ledger
+// and answers with one signed CrossOrgAck per event, each anchored to the
+// ledger sequence of the recorded interaction. The source stores the ACKs in
+// its own ledger as CROSS_ORG_ACK events. Delivery is asynchronous: an Outbox
+// retries failed deliveries with backoff and escalates once attempts run out.
+
+// BundlesPath is the receive endpoint path on every ACP node.
+const BundlesPath = "/acp/v1/crossorg/bundles"
+
+// BundleVersion is the version stamped on bundles built by this package.
+const BundleVersion = "1.0"
+
+var (
+	// ErrUnknownPeer is returned when an institution is not a registered peer.
+	ErrUnknownPeer = fmt.Errorf("%w: unknown peer institution", ErrNoActiveFederation)
+
+	// ErrWrongTarget is returned when a bundle is addressed to another institution.
+	ErrWrongTarget = fmt.Errorf("%w: bundle addressed to another institution", ErrMalformedEvent)
+
+	// ErrRetryExhausted is recorded on an outbox entry whose attempts ran out.
+	ErrRetryExhausted = errors.New("CROSS-012: retry limit exceeded")
+
+	// ErrPeerExists is returned by Register when the institution is already a
+	// registered peer and the registration does not replace it.
+	ErrPeerExists = errors.New("crossorg: peer already registered")
+)
+
+// Ledger is the subset of the institution ledger used for the audit trail.
+type Ledger interface {
+	Append(eventType string, payload interface{}) (ledger.Event, error)
+	AppendSequenced(build func(first int64) ([]ledger.Entry, error)) ([]ledger.Event, error)
+}
+
+// ─── Peers ────────────────────────────────────────────────────────────────────
+
+// Peer is a federated institution this node exchanges bundles with.
+type Peer struct {
+	InstitutionID string            `json:"institution_id"`
+	Endpoint      string            `json:"endpoint"` // base URL of the peer's ACP node
+	PublicKey     ed25519.PublicKey `json:"-"`
+	DID           string            `json:"did,omitempty"` // if set, keys are resolved by DID on verification
+}
+
+// PeerRegistry is a thread-safe in-memory set of peers.
+type PeerRegistry struct {
+	mu       sync.RWMutex
+	peers    map[string]Peer
+	resolver did.Resolver
+}
+
+// NewPeerRegistry creates an empty peer registry.
+func NewPeerRegistry() *PeerRegistry {
+	return &PeerRegistry{peers: make(map[string]Peer)}
+}
+
+// Put adds or replaces a peer.
+func (r *PeerRegistry) Put(p Peer) error {
+	return r.Register(p, true, nil)
+}
+
+// Register adds p. If p.InstitutionID is already registered it returns
+// ErrPeerExists, unless replace is set. commit, if not nil, records the
+// registration (e.g. in the audit ledger) before it takes effect, with
+// replaced telling whether it replaces a peer; if it fails, the registry is
+// unchanged.
+func (r *PeerRegistry) Register(p Peer, replace bool, commit func(p Peer, replaced bool) error) error {
+	if p.InstitutionID == "" || len(p.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: institution_id and 32-byte public_key are required", ErrMalformedEvent)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, replaced := r.peers[p.InstitutionID]
+	if replaced && !replace {
+		return fmt.Errorf("%w: %s", ErrPeerExists, p.InstitutionID)
+	}
+	if commit != nil {
+		if err := commit(p, replaced); err != nil {
+			return err
+		}
+	}
+	r.peers[p.InstitutionID] = p
+	return nil
+}
+
+// SetResolver sets the DID resolver used by ResolveDID and Keys.
+func (r *PeerRegistry) SetResolver(res did.Resolver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolver = res
+}
+
+// PutDID adds or replaces a peer identified by a DID (see ResolveDID).
+func (r *PeerRegistry) PutDID(institutionID, endpoint, peerDID string) (Peer, error) {
+	p, err := r.ResolveDID(institutionID, endpoint, peerDID)
+	if err != nil {
+		return Peer{}, err
+	}
+	return p, r.Put(p)
+}
+
+// ResolveDID returns the peer identified by a DID, without registering it.
+// The DID is resolved now and its first Ed25519 key recorded as the peer's
+// PublicKey.
+func (r *PeerRegistry) ResolveDID(institutionID, endpoint, peerDID string) (Peer, error) {
+	r.mu.RLock()
+	res := r.resolver
+	r.mu.RUnlock()
+	if res == nil {
+		return Peer{}, fmt.Errorf("%w: no DID resolver configured", ErrMalformedEvent)
+	}
+	keys, err := did.ResolveKeys(res, peerDID)
+	if err != nil {
+		return Peer{}, fmt.Errorf("%w: %v", ErrMalformedEvent, err)
+	}
+	return Peer{InstitutionID: institutionID, Endpoint: endpoint, PublicKey: keys[0], DID: peerDID}, nil
+}
+
+// Keys returns the keys p's signatures are verified with: the keys its DID
+// resolves to now, or the registered PublicKey if p has no DID or the DID
+// cannot be resolved.
+func (r *PeerRegistry) Keys(p Peer) []ed25519.PublicKey {
+	r.mu.RLock()
+	res := r.resolver
+	r.mu.RUnlock()
+	if p.DID != "" && res != nil {
+		if keys, err := did.ResolveKeys(res, p.DID); err == nil {
+			return keys
+		}
+	}
+	return []ed25519.PublicKey{p.PublicKey}
+}
+
+// verifyAny returns nil if verify succeeds with any of keys, else the last error.
+func verifyAny(keys []ed25519.PublicKey, verify func(ed25519.PublicKey) error) error {
+	var err error
+	for _, pk := range keys {
+		if err = verify(pk); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// Get returns the peer registered under institutionID.
+func (r *PeerRegistry) Get(institutionID string) (Peer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.peers[institutionID]
+	if !ok {
+		return Peer{}, fmt.Errorf("%w: %s", ErrUnknownPeer, institutionID)
+	}
+	return p, nil
+}
+
+// List returns all peers ordered by institution ID.
+func (r *PeerRegistry) List() []Peer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Peer, 0, len(r.peers))
+	for _, p := range r.peers {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].InstitutionID < out[j].InstitutionID })
+	return out
+}
+
+// Size returns the number of registered peers.
+func (r *PeerRegistry) Size() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.peers)
+}
+
+// ─── Bundles ──────────────────────────────────────────────────────────────────
+
+// NewBundle builds an unsigned bundle from source to target. Each event gets
+// the bundle's institution IDs, a timestamp and, if empty, a fresh event_id.
+func NewBundle(source, target string, events []CrossOrgInteraction, evidence map[string]interface{}) (CrossOrgBundle, error) {
+	bundleID, err := newUUID()
+	if err != nil {
+		return CrossOrgBundle{}, fmt.Errorf("crossorg: generate bundle_id: %w", err)
+	}
+	now := time.Now().Unix()
+	evs := make([]CrossOrgInteraction, len(events))
+	for i, ev := range events {
+		if ev.EventID == "" {
+			if ev.EventID, err = newUUID(); err != nil {
+				return CrossOrgBundle{}, fmt.Errorf("crossorg: generate event_id: %w", err)
+			}
+		}
+		ev.Timestamp = now
+		ev.SourceInstitutionID = source
+		ev.TargetInstitutionID = target
+		evs[i] = ev
+	}
+	return CrossOrgBundle{
+		BundleID:            bundleID,
+		BundleVersion:       BundleVersion,
+		SourceInstitutionID: source,
+		TargetInstitutionID: target,
+		CreatedAt:           now,
+		Events:              evs,
+		Evidence:            evidence,
+	}, nil
+}
+
+// ─── Receiver (inbox) ─────────────────────────────────────────────────────────
+
+// ReceiveBundleResponse is the data returned by the receive endpoint.
+type ReceiveBundleResponse struct {
+	BundleID  string        `json:"bundle_id"`
+	Duplicate bool          `json:"duplicate"`
+	Acks      []CrossOrgAck `json:"acks"`
+}
+
+// Receiver validates incoming bundles, records them and issues ACKs.
+// It implements http.Handler for BundlesPath.
+type Receiver struct {
+	institutionID string
+	signer        func() (string, ed25519.PrivateKey)
+	peers         *PeerRegistry
+	store         *InMemoryCrossOrgStore
+	ledger        Ledger
+
+	mu sync.Mutex // serialises receipt so duplicates are detected atomically
+}
+
+// NewReceiver creates a Receiver for institutionID. signer returns the
+// current institution key (kid, private key) used to sign ACKs and responses.
+func NewReceiver(institutionID string, signer func() (string, ed25519.PrivateKey),
+	peers *PeerRegistry, store *InMemoryCrossOrgStore, l Ledger) *Receiver {
+	return &Receiver{institutionID: institutionID, signer: signer, peers: peers, store: store, ledger: l}
+}
+
+// Receive runs the target-side validation of ACP-CROSS-ORG-1.1 §7.3 and
+// records the bundle. A bundle already received returns its original ACKs
+// with duplicate = true.
+func (rc *Receiver) Receive(b CrossOrgBundle) (ReceiveBundleResponse, error) {
+	if b.BundleVersion != BundleVersion {
+		return ReceiveBundleResponse{}, fmt.Errorf("%w: %q", ErrInvalidVersion, b.BundleVersion)
+	}
+	if b.BundleID == "" || len(b.Events) == 0 {
+		return ReceiveBundleResponse{}, fmt.Errorf("%w: bundle_id and at least one event are required", ErrMalformedEvent)
+	}
+	if b.TargetInstitutionID != rc.institutionID {
+		return ReceiveBundleResponse{}, fmt.Errorf("%w: %s", ErrWrongTarget, b.TargetInstitutionID)
+	}
+	// Steps 1–3: federation, bundle signature, event signatures.
+	peer, err := rc.peers.Get(b.SourceInstitutionID)
+	if err != nil {
+		return ReceiveBundleResponse{}, err
+	}
+	keys := rc.peers.Keys(peer)
+	if err := verifyAny(keys, func(pk ed25519.PublicKey) error { return VerifyBundle(b, pk) }); err != nil {
+		return ReceiveBundleResponse{}, err
+	}
+	for _, ev := range b.Events {
+		if err := validateInteraction(ev, b); err != nil {
+			return ReceiveBundleResponse{}, err
+		}
+		if ev.Sig != "" {
+			if err := verifyAny(keys, func(pk ed25519.PublicKey) error { return VerifyInteraction(ev, pk) }); err != nil {
+				return ReceiveBundleResponse{}, err
+			}
+		}
+	}
+
+	_, priv := rc.signer()
+	if len(priv) != ed25519.PrivateKeySize {
+		return ReceiveBundleResponse{}, errors.New("crossorg: no institution signing key to issue ACKs")
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	// Step 6: idempotency — redelivery returns the original ACKs.
+	if _, exists := rc.store.GetBundle(b.BundleID); exists {
+		return ReceiveBundleResponse{BundleID: b.BundleID, Duplicate: true, Acks: rc.store.AcksForBundle(b.BundleID)}, nil
+	}
+
+	// Step 7 + §7.4: record each interaction followed by its ACK at that
+	// sequence, in one atomic append: a failed receipt records nothing, so
+	// the redelivery is processed afresh rather than recorded twice.
+	var acks []CrossOrgAck
+	if _, err := rc.ledger.AppendSequenced(func(seq int64) ([]ledger.Entry, error) {
+		acks = make([]CrossOrgAck, 0, len(b.Events))
+		entries := make([]ledger.Entry, 0, 2*len(b.Events))
+		for _, ev := range b.Events {
+			ack, err := BuildAck(b.BundleID, ev.EventID, rc.institutionID, b.SourceInstitutionID, AckAccepted, seq, priv)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries,
+				ledger.Entry{EventType: ledger.EventCrossOrgInteraction, Payload: interactionPayload(ev, b.BundleID, "inbound")},
+				ledger.Entry{EventType: ledger.EventCrossOrgAck, Payload: ackPayload(ack)})
+			acks = append(acks, ack)
+			seq += 2
+		}
+		return entries, nil
+	}); err != nil {
+		return ReceiveBundleResponse{}, fmt.Errorf("crossorg: record bundle: %w", err)
+	}
+	if err := rc.store.Append(b); err != nil {
+		return ReceiveBundleResponse{}, err
+	}
+	for _, ack := range acks {
+		_ = rc.store.StoreAck(ack)
+	}
+	return ReceiveBundleResponse{BundleID: b.BundleID, Acks: acks}, nil
+}
+
+// ServeHTTP handles POST BundlesPath with a ReceiveBundleRequest body.
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req ReceiveBundleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	resp, err := rc.Receive(req.Bundle)
+	if err != nil {
+		status, code := ErrorStatus(err)
+		acpapi.WriteError(w, r, status, code, err.Error())
+		return
+	}
+	kid, priv := rc.signer()
+	acpapi.WriteSignedSuccess(w, r, http.StatusOK, resp, kid, priv)
+}
+
+// ErrorStatus maps a transport error to its HTTP status and error code
+// (ACP-CROSS-ORG-1.1 §13). Unrecognised errors map to 503 SYS-003, since
+// the only other failure is the local ledger.
+func ErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, ErrQuorumNotReached): // wraps the witnesses' errors
+		return http.StatusServiceUnavailable, "CROSS-015"
+	case errors.Is(err, ErrNoActiveFederation):
+		return http.StatusForbidden, "CROSS-004"
+	case errors.Is(err, ErrBundleSigInvalid):
+		return http.StatusUnprocessableEntity, "CROSS-008"
+	case errors.Is(err, ErrEventSigInvalid):
+		return http.StatusUnprocessableEntity, "CROSS-009"
+	case errors.Is(err, ErrInvalidVersion):
+		return http.StatusBadRequest, "CROSS-010"
+	case errors.Is(err, ErrInvalidPayloadHash):
+		return http.StatusBadRequest, "CROSS-002"
+	case errors.Is(err, ErrEmptyDelegationChain):
+		return http.StatusBadRequest, "CROSS-003"
+	case errors.Is(err, ErrMalformedEvent):
+		return http.StatusBadRequest, "CROSS-001"
+	case errors.Is(err, ErrCheckpointSigInvalid):
+		return http.StatusUnprocessableEntity, "CROSS-013"
+	case errors.Is(err, ErrCheckpointInconsistent):
+		return http.StatusConflict, "CROSS-014"
+	case errors.Is(err, ErrCosignatureInvalid):
+		return http.StatusUnprocessableEntity, "CROSS-016"
+	default:
+		return http.StatusServiceUnavailable, acpapi.ErrSYS003
+	}
+}
+
+// validateInteraction checks the required fields of one event against its bundle.
+func validateInteraction(ev CrossOrgInteraction, b CrossOrgBundle) error {
+	if ev.EventID == "" || ev.SourceInstitutionID != b.SourceInstitutionID ||
+		ev.TargetInstitutionID != b.TargetInstitutionID || !IsValidActionType(ev.ActionType) {
+		return fmt.Errorf("%w: event %q", ErrMalformedEvent, ev.EventID)
+	}
+	if len(ev.PayloadHash) != 64 || strings.Trim(ev.PayloadHash, "0123456789abcdef") != "" {
+		return fmt.Errorf("%w: event %q", ErrInvalidPayloadHash, ev.EventID)
+	}
+	if len(ev.DelegationChain) == 0 {
+		return fmt.Errorf("%w: event %q", ErrEmptyDelegationChain, ev.EventID)
+	}
+	return nil
+}
+
+func interactionPayload(ev CrossOrgInteraction, bundleID, direction string) map[string]interface{} {
+	return map[string]interface{}{
+		"bundle_id":             bundleID,
+		"direction":             direction,
+		"event_id":              ev.EventID,
+		"source_institution_id": ev.SourceInstitutionID,
+		"target_institution_id": ev.TargetInstitutionID,
+		"action_type":           ev.ActionType,
+		"payload_hash":          ev.PayloadHash,
+		"delegation_chain":      ev.DelegationChain,
+		"authorization_id":      ev.AuthorizationID,
+		"liability_record_id":   ev.LiabilityRecordID,
+		"ack_required":          ev.AckRequired,
+	}
+}
+
+func ackPayload(ack CrossOrgAck) map[string]interface{} {
+	return map[string]interface{}{
+		"ack_id":                ack.AckID,
+		"bundle_id":             ack.BundleID,
+		"original_event_id":     ack.OriginalEventID,
+		"source_institution_id": ack.SourceInstitutionID,
+		"target_institution_id": ack.TargetInstitutionID,
+		"validated_at":          ack.ValidatedAt,
+		"status":                ack.Status,
+		"ledger_sequence":       ack.LedgerSequence,
+	}
+}
+
+// ─── Outbox ───────────────────────────────────────────────────────────────────
+
+// Outbox entry states.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// DefaultBackoff is the wait before each retry per ACP-CROSS-ORG-1.1 §8.2:
+// the 300 s ACK window plus a 30 s, then 60 s backoff.
+var DefaultBackoff = []time.Duration{330 * time.Second, 360 * time.Second}
+
+// escalationTTL is how long the escalation of an undeliverable bundle stays
+// open for review, as for authorization escalations.
+const escalationTTL = time.Hour
+
+// OutboxConfig tunes delivery. Zero values take the spec defaults.
+type OutboxConfig struct {
+	MaxAttempts int             // total attempts per bundle (default 3)
+	Backoff     []time.Duration // wait before retry n; the last value repeats (default DefaultBackoff)
+	Timeout     time.Duration   // per-attempt HTTP timeout (default 30 s)
+	Client      *http.Client    // optional; Timeout is ignored when set
+}
+
+// OutboxEntry is the delivery state of one outbound bundle.
+type OutboxEntry struct {
+	BundleID            string        `json:"bundle_id"`
+	TargetInstitutionID string        `json:"target_institution_id"`
+	Status              string        `json:"status"`
+	Attempts            int           `json:"attempts"`
+	LastError           string        `json:"last_error,omitempty"`
+	CreatedAt           int64         `json:"created_at"`
+	NextAttemptAt       int64         `json:"next_attempt_at,omitempty"`
+	DeliveredAt         int64         `json:"delivered_at,omitempty"`
+	Acks                []CrossOrgAck `json:"acks"`
+
+	next     time.Time
+	inFlight bool
+}
+
+// Outbox delivers bundles to peers with retries.
+type Outbox struct {
+	institutionID string
+	peers         *PeerRegistry
+	store         *InMemoryCrossOrgStore
+	ledger        Ledger
+	client        *http.Client
+	cfg           OutboxConfig
+
+	mu      sync.Mutex
+	entries map[string]*OutboxEntry
+	order   []string
+	wake    chan struct{}
+}
+
+// NewOutbox creates an Outbox for institutionID.
+func NewOutbox(institutionID string, peers *PeerRegistry, store *InMemoryCrossOrgStore, l Ledger, cfg OutboxConfig) *Outbox {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if len(cfg.Backoff) == 0 {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &Outbox{
+		institutionID: institutionID,
+		peers:         peers,
+		store:         store,
+		ledger:        l,
+		client:        client,
+		cfg:           cfg,
+		entries:       make(map[string]*OutboxEntry),
+		wake:          make(chan struct{}, 1),
+	}
+}
+
+// Enqueue records a signed bundle in the local ledger (one
+// CROSS_ORG_INTERACTION per event, CROSS-RULE-1) and schedules delivery.
+func (o *Outbox) Enqueue(b CrossOrgBundle) (OutboxEntry, error) {
+	if b.SourceInstitutionID != o.institutionID || b.BundleID == "" || len(b.Events) == 0 {
+		return OutboxEntry{}, fmt.Errorf("%w: bundle must originate here and carry at least one event", ErrMalformedEvent)
+	}
+	if _, err := o.peers.Get(b.TargetInstitutionID); err != nil {
+		return OutboxEntry{}, err
+	}
+	for _, ev := range b.Events {
+		if err := validateInteraction(ev, b); err != nil {
+			return OutboxEntry{}, err
+		}
+	}
+	if err := o.store.Append(b); err != nil {
+		return OutboxEntry{}, err
+	}
+	for _, ev := range b.Events {
+		if _, err := o.ledger.Append(ledger.EventCrossOrgInteraction, interactionPayload(ev, b.BundleID, "outbound")); err != nil {
+			return OutboxEntry{}, fmt.Errorf("crossorg: record interaction: %w", err)
+		}
+	}
+
+	now := time.Now()
+	e := &OutboxEntry{
+		BundleID:            b.BundleID,
+		TargetInstitutionID: b.TargetInstitutionID,
+		Status:              DeliveryPending,
+		CreatedAt:           now.Unix(),
+		NextAttemptAt:       now.Unix(),
+		Acks:                []CrossOrgAck{},
+		next:                now,
+	}
+	o.mu.Lock()
+	o.entries[b.BundleID] = e
+	o.order = append(o.order, b.BundleID)
+	snapshot := *e
+	o.mu.Unlock()
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return snapshot, nil
+}
+
+// Get returns the delivery state of bundleID.
+func (o *Outbox) Get(bundleID string) (OutboxEntry, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	e, ok := o.entries[bundleID]
+	if !ok {
+		return OutboxEntry{}, false
+	}
+	return *e, true
+}
+
+// ListByPeer returns the entries addressed to institutionID in enqueue order.
+func (o *Outbox) ListByPeer(institutionID string) []OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := []OutboxEntry{}
+	for _, id := range o.order {
+		if e := o.entries[id]; e.TargetInstitutionID == institutionID {
+			out = append(out, *e)
+		}
+	}
+	return out
+}
+
+// Pending returns the number of entries awaiting delivery.
+func (o *Outbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	for _, e := range o.entries {
+		if e.Status == DeliveryPending {
+			n++
+		}
+	}
+	return n
+}
+
+// Run delivers due entries until ctx is cancelled.
+func (o *Outbox) Run(ctx context.Context) {
+	for {
+		o.DeliverDue(ctx)
+		wait := time.Minute
+		o.mu.Lock()
+		for _, e := range o.entries {
+			if e.Status == DeliveryPending && !e.inFlight {
+				if d := time.Until(e.next); d < wait {
+					wait = d
+				}
+			}
+		}
+		o.mu.Unlock()
+		if wait < 0 {
+			wait = 0
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-o.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// DeliverDue makes one delivery attempt for every entry that is due and
+// returns the number of attempts made.
+func (o *Outbox) DeliverDue(ctx context.Context) int {
+	now := time.Now()
+	var due []string
+	o.mu.Lock()
+	for _, id := range o.order {
+		e := o.entries[id]
+		if e.Status == DeliveryPending && !e.inFlight && !now.Before(e.next) {
+			e.inFlight = true
+			due = append(due, id)
+		}
+	}
+	o.mu.Unlock()
+
+	for _, id := range due {
+		o.attempt(ctx, id)
+	}
+	return len(due)
+}
+
+// attempt delivers one bundle and updates its entry.
+func (o *Outbox) attempt(ctx context.Context, bundleID string) {
+	b, _ := o.store.GetBundle(bundleID)
+	acks, retryable, err := o.deliver(ctx, b)
+
+	o.mu.Lock()
+	e := o.entries[bundleID]
+	e.inFlight = false
+	e.Attempts++
+	if err == nil {
+		e.Status = DeliveryDelivered
+		e.DeliveredAt = time.Now().Unix()
+		e.NextAttemptAt = 0
+		e.LastError = ""
+		e.Acks = acks
+		o.mu.Unlock()
+		return
+	}
+	reason := "rejected_by_peer"
+	if retryable && e.Attempts >= o.cfg.MaxAttempts {
+		err = fmt.Errorf("%w after %d attempts: %v", ErrRetryExhausted, e.Attempts, err)
+		reason = "CROSS-012"
+	}
+	e.LastError = err.Error()
+	if !retryable || e.Attempts >= o.cfg.MaxAttempts {
+		e.Status = DeliveryFailed
+		e.NextAttemptAt = 0
+		attempts := e.Attempts
+		o.mu.Unlock()
+		// CROSS-RULE-11: a bundle that will never be acknowledged is escalated.
+		_, _ = o.ledger.Append(ledger.EventEscalationCreated, map[string]interface{}{
+			"escalation_id":         bundleID,
+			"escalated_to":          "review_queue",
+			"expires_at":            time.Now().Add(escalationTTL).Unix(),
+			"reason_code":           reason,
+			"bundle_id":             bundleID,
+			"target_institution_id": b.TargetInstitutionID,
+			"attempts":              attempts,
+			"last_error":            err.Error(),
+		})
+		return
+	}
+	wait := o.cfg.Backoff[len(o.cfg.Backoff)-1]
+	if e.Attempts-1 < len(o.cfg.Backoff) {
+		wait = o.cfg.Backoff[e.Attempts-1]
+	}
+	e.next = time.Now().Add(wait)
+	e.NextAttemptAt = e.next.Unix()
+	o.mu.Unlock()
+}
+
+// deliver POSTs the bundle to its peer, verifies the returned ACKs and
+// records them in the local ledger. retryable is false when the peer
+// rejected the bundle itself (4xx), since resending cannot succeed.
+func (o *Outbox) deliver(ctx context.Context, b CrossOrgBundle) (acks []CrossOrgAck, retryable bool, err error) {
+	peer, err := o.peers.Get(b.TargetInstitutionID)
+	if err != nil {
+		return nil, false, err
+	}
+	body, err := json.Marshal(ReceiveBundleRequest{Bundle: b})
+	if err != nil {
+		return nil, false, fmt.Errorf("crossorg: marshal bundle: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(peer.Endpoint, "/")+BundlesPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, fmt.Errorf("crossorg: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("crossorg: deliver to %s: %w", peer.InstitutionID, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, true, fmt.Errorf("crossorg: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var er acpapi.ErrorResponse
+		_ = json.Unmarshal(raw, &er)
+		err := fmt.Errorf("crossorg: %s answered %d %s: %s", peer.InstitutionID, resp.StatusCode, er.Error.Code, er.Error.Message)
+		retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+		return nil, retryable, err
+	}
+
+	var env struct {
+		Data ReceiveBundleResponse `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, true, fmt.Errorf("crossorg: decode response: %w", err)
+	}
+	if len(env.Data.Acks) != len(b.Events) {
+		return nil, true, fmt.Errorf("%w: expected %d acks, got %d", ErrMalformedEvent, len(b.Events), len(env.Data.Acks))
+	}
+	keys := o.peers.Keys(peer)
+	for _, ack := range env.Data.Acks {
+		if ack.BundleID != b.BundleID || ack.SourceInstitutionID != o.institutionID || ack.TargetInstitutionID != peer.InstitutionID {
+			return nil, true, fmt.Errorf("%w: ack %s does not match bundle %s", ErrMalformedEvent, ack.AckID, b.BundleID)
+		}
+		if err := verifyAny(keys, func(pk ed25519.PublicKey) error { return VerifyAck(ack, pk) }); err != nil {
+			return nil, true, err
+		}
+	}
+	for _, ack := range env.Data.Acks {
+		if _, exists := o.store.GetAck(ack.AckID); exists {
+			continue
+		}
+		if _, err := o.ledger.Append(ledger.EventCrossOrgAck, ackPayload(ack)); err != nil {
+			return nil, true, fmt.Errorf("crossorg: record ack: %w", err)
+		}
+		_ = o.store.StoreAck(ack)
+	}
+	return env.Data.Acks, false, nil
+}
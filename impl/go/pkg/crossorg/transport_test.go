@@ -0,0 +1,430 @@
+package crossorg_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	acpapi "github.com/chelof100/acp-framework/acp-go/pkg/api"
+	"github.com/chelof100/acp-framework/acp-go/pkg/crossorg"
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+)
+
+// ─── Helpers ──────────────────────────────────────────────────────────────────
+
+// org is one in-process institution: ledger, inbox, outbox, witness and
+// HTTP endpoint.
+type org struct {
+	id      string
+	pub     ed25519.PublicKey
+	priv    ed25519.PrivateKey
+	ledger  *ledger.InMemoryLedger
+	peers   *crossorg.PeerRegistry
+	store   *crossorg.InMemoryCrossOrgStore
+	inbox   *crossorg.Receiver
+	outbox  *crossorg.Outbox
+	witness *crossorg.WitnessService
+	srv     *httptest.Server
+}
+
+func newOrg(t *testing.T, id string, seed byte, wrap func(http.Handler) http.Handler) *org {
+	t.Helper()
+	s := make([]byte, 32)
+	s[0] = seed
+	priv := ed25519.NewKeyFromSeed(s)
+	l, err := ledger.NewInMemoryLedger(id, priv)
+	if err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	o := &org{id: id, pub: priv.Public().(ed25519.PublicKey), priv: priv, ledger: l,
+		peers: crossorg.NewPeerRegistry(), store: crossorg.NewInMemoryCrossOrgStore()}
+	o.inbox = crossorg.NewReceiver(id, func() (string, ed25519.PrivateKey) { return "", priv }, o.peers, o.store, l)
+	o.outbox = crossorg.NewOutbox(id, o.peers, o.store, l, crossorg.OutboxConfig{
+		Backoff: []time.Duration{5 * time.Millisecond},
+		Timeout: 2 * time.Second,
+	})
+	o.witness = crossorg.NewWitnessService(id, func() (string, ed25519.PrivateKey) { return "", priv }, o.peers)
+	mux := http.NewServeMux()
+	mux.Handle("POST "+crossorg.BundlesPath, o.inbox)
+	mux.HandleFunc("POST "+crossorg.WitnessCosignPath, o.witness.ServeCosign)
+	mux.HandleFunc("GET "+crossorg.WitnessCheckpointsPath+"{institution_id}", o.witness.ServeLatest)
+	var h http.Handler = acpapi.Middleware(mux)
+	if wrap != nil {
+		h = wrap(h)
+	}
+	o.srv = httptest.NewServer(h)
+	t.Cleanup(o.srv.Close)
+	return o
+}
+
+// federate registers a and b as peers of each other.
+func federate(t *testing.T, a, b *org) {
+	t.Helper()
+	if err := a.peers.Put(crossorg.Peer{InstitutionID: b.id, Endpoint: b.srv.URL, PublicKey: b.pub}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.peers.Put(crossorg.Peer{InstitutionID: a.id, Endpoint: a.srv.URL, PublicKey: a.pub}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func signedBundle(t *testing.T, from *org, to string, signer ed25519.PrivateKey) crossorg.CrossOrgBundle {
+	t.Helper()
+	b, err := crossorg.NewBundle(from.id, to, []crossorg.CrossOrgInteraction{{
+		ActionType:        crossorg.ActionDataShare,
+		PayloadHash:       strings.Repeat("ab", 32),
+		DelegationChain:   []string{"agent-1"},
+		AuthorizationID:   "auth-1",
+		LiabilityRecordID: "lia-1",
+		AckRequired:       true,
+	}}, nil)
+	if err != nil {
+		t.Fatalf("NewBundle: %v", err)
+	}
+	for i := range b.Events {
+		if err := crossorg.SignInteraction(&b.Events[i], signer); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := crossorg.SignBundle(&b, signer); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func countType(l *ledger.InMemoryLedger, eventType string) int {
+	n := 0
+	for _, ev := range l.List(0, 0) {
+		if ev.EventType == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+// ─── Delivery ─────────────────────────────────────────────────────────────────
+
+// TestOutbox_DeliverAndAck runs the full exchange between two institutions:
+// the ACK is signed by the target, anchored to the target's ledger sequence
+// of the recorded interaction, and stored in both ledgers.
+func TestOutbox_DeliverAndAck(t *testing.T) {
+	a := newOrg(t, "org.a", 0x01, nil)
+	b := newOrg(t, "org.b", 0x02, nil)
+	federate(t, a, b)
+
+	bundle := signedBundle(t, a, b.id, a.priv)
+	if _, err := a.outbox.Enqueue(bundle); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if n := a.outbox.DeliverDue(context.Background()); n != 1 {
+		t.Fatalf("DeliverDue attempted %d, want 1", n)
+	}
+
+	entry, _ := a.outbox.Get(bundle.BundleID)
+	if entry.Status != crossorg.DeliveryDelivered || entry.Attempts != 1 || len(entry.Acks) != 1 {
+		t.Fatalf("entry = %+v", entry)
+	}
+	ack := entry.Acks[0]
+	if err := crossorg.VerifyAck(ack, b.pub); err != nil {
+		t.Errorf("VerifyAck: %v", err)
+	}
+	anchored, ok := b.ledger.GetBySequence(ack.LedgerSequence)
+	if !ok || anchored.EventType != ledger.EventCrossOrgInteraction {
+		t.Fatalf("ack ledger_sequence %d → %+v, want CROSS_ORG_INTERACTION", ack.LedgerSequence, anchored)
+	}
+	if p := anchored.Payload.(map[string]interface{}); p["bundle_id"] != bundle.BundleID || p["event_id"] != bundle.Events[0].EventID {
+		t.Errorf("anchored payload = %v", p)
+	}
+
+	// Audit trail on both sides.
+	if countType(a.ledger, ledger.EventCrossOrgInteraction) != 1 || countType(a.ledger, ledger.EventCrossOrgAck) != 1 {
+		t.Error("source ledger missing CROSS_ORG_INTERACTION or CROSS_ORG_ACK")
+	}
+	if countType(b.ledger, ledger.EventCrossOrgAck) != 1 {
+		t.Error("target ledger missing CROSS_ORG_ACK")
+	}
+	for _, l := range []*ledger.InMemoryLedger{a.ledger, b.ledger} {
+		if errs := l.Verify(); len(errs) != 0 {
+			t.Errorf("ledger verify: %v", errs)
+		}
+	}
+	if got := a.outbox.ListByPeer(b.id); len(got) != 1 || got[0].BundleID != bundle.BundleID {
+		t.Errorf("ListByPeer = %+v", got)
+	}
+}
+
+func TestReceiver_DuplicateReturnsOriginalAck(t *testing.T) {
+	a := newOrg(t, "org.a", 0x01, nil)
+	b := newOrg(t, "org.b", 0x02, nil)
+	federate(t, a, b)
+	bundle := signedBundle(t, a, b.id, a.priv)
+
+	first, err := b.inbox.Receive(bundle)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	size := b.ledger.Size()
+	again, err := b.inbox.Receive(bundle)
+	if err != nil {
+		t.Fatalf("Receive (duplicate): %v", err)
+	}
+	if !again.Duplicate || len(again.Acks) != 1 || again.Acks[0].AckID != first.Acks[0].AckID {
+		t.Errorf("duplicate response = %+v", again)
+	}
+	if b.ledger.Size() != size {
+		t.Errorf("duplicate receipt appended to ledger (%d → %d)", size, b.ledger.Size())
+	}
+}
+
+// ackFailingBackend fails the commits that record a CROSS_ORG_ACK while down.
+type ackFailingBackend struct{ down bool }
+
+func (f *ackFailingBackend) Commit(events []ledger.Event) error {
+	for _, ev := range events {
+		if f.down && ev.EventType == ledger.EventCrossOrgAck {
+			return errors.New("disk unavailable")
+		}
+	}
+	return nil
+}
+
+// TestReceiver_FailedReceiptRecordsNothing checks that a receipt failing in
+// the ledger leaves no interaction behind, so the redelivery records each
+// interaction once.
+func TestReceiver_FailedReceiptRecordsNothing(t *testing.T) {
+	a := newOrg(t, "org.a", 0x01, nil)
+	b := newOrg(t, "org.b", 0x02, nil)
+	federate(t, a, b)
+	backend := &ackFailingBackend{down: true}
+	if err := b.ledger.SetBackend(backend); err != nil {
+		t.Fatal(err)
+	}
+	bundle := signedBundle(t, a, b.id, a.priv)
+
+	if _, err := b.inbox.Receive(bundle); !errors.Is(err, ledger.ErrBackendUnavailable) {
+		t.Fatalf("Receive with ledger down: err = %v", err)
+	}
+	if n := countType(b.ledger, ledger.EventCrossOrgInteraction); n != 0 {
+		t.Fatalf("failed receipt recorded %d interactions", n)
+	}
+	backend.down = false
+	resp, err := b.inbox.Receive(bundle)
+	if err != nil || resp.Duplicate {
+		t.Fatalf("redelivery = %+v, %v", resp, err)
+	}
+	if countType(b.ledger, ledger.EventCrossOrgInteraction) != 1 || countType(b.ledger, ledger.EventCrossOrgAck) != 1 {
+		t.Error("redelivery did not record one interaction and one ACK")
+	}
+	if anchored, _ := b.ledger.GetBySequence(resp.Acks[0].LedgerSequence); anchored.EventType != ledger.EventCrossOrgInteraction {
+		t.Errorf("ack ledger_sequence %d → %s", resp.Acks[0].LedgerSequence, anchored.EventType)
+	}
+}
+
+func TestReceiver_Rejections(t *testing.T) {
+	a := newOrg(t, "org.a", 0x01, nil)
+	b := newOrg(t, "org.b", 0x02, nil)
+	c := newOrg(t, "org.c", 0x03, nil)
+	federate(t, a, b)
+
+	if _, err := b.inbox.Receive(signedBundle(t, a, b.id, c.priv)); !errors.Is(err, crossorg.ErrBundleSigInvalid) {
+		t.Errorf("forged bundle: err = %v, want ErrBundleSigInvalid", err)
+	}
+	if _, err := b.inbox.Receive(signedBundle(t, c, b.id, c.priv)); !errors.Is(err, crossorg.ErrNoActiveFederation) {
+		t.Errorf("unknown source: err = %v, want ErrNoActiveFederation", err)
+	}
+	if _, err := b.inbox.Receive(signedBundle(t, a, "org.other", a.priv)); !errors.Is(err, crossorg.ErrMalformedEvent) {
+		t.Errorf("wrong target: err = %v, want ErrMalformedEvent", err)
+	}
+	tampered := signedBundle(t, a, b.id, a.priv)
+	tampered.Events[0].PayloadHash = strings.Repeat("cd", 32)
+	if err := crossorg.SignBundle(&tampered, a.priv); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.inbox.Receive(tampered); !errors.Is(err, crossorg.ErrEventSigInvalid) {
+		t.Errorf("tampered event: err = %v, want ErrEventSigInvalid", err)
+	}
+	if countType(b.ledger, ledger.EventCrossOrgInteraction) != 0 {
+		t.Error("rejected bundles were recorded")
+	}
+}
+
+func TestReceiver_PeerKeysResolvedByDID(t *testing.T) {
+	a := newOrg(t, "org.a", 0x01, nil)
+	b := newOrg(t, "org.b", 0x02, nil)
+	c := newOrg(t, "org.c", 0x03, nil)
+
+	// org.a's DID document currently lists a.pub; swapping current simulates
+	// a key rotation published by org.a.
+	current := a.pub
+	b.peers.SetResolver(did.Multi{"acpd": &did.ACPDResolver{Sources: []did.KeySource{
+		did.KeySourceFunc(func(id string, ts int64) ([]ed25519.PublicKey, error) {
+			if id != "org.a" {
+				return nil, did.ErrNotFound
+			}
+			return []ed25519.PublicKey{current}, nil
+		}),
+	}}})
+	peer, err := b.peers.PutDID(a.id, a.srv.URL, "did:acpd:org.a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !peer.PublicKey.Equal(a.pub) {
+		t.Error("PutDID did not record the resolved key")
+	}
+	if _, err := b.inbox.Receive(signedBundle(t, a, b.id, a.priv)); err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	current = c.pub
+	if _, err := b.inbox.Receive(signedBundle(t, a, b.id, c.priv)); err != nil {
+		t.Errorf("bundle signed with the rotated key: %v", err)
+	}
+	if _, err := b.inbox.Receive(signedBundle(t, a, b.id, a.priv)); !errors.Is(err, crossorg.ErrBundleSigInvalid) {
+		t.Errorf("bundle signed with the retired key: err = %v", err)
+	}
+}
+
+// ─── Retries ──────────────────────────────────────────────────────────────────
+
+// TestOutbox_RetriesUntilDelivered fails the first two deliveries with 503.
+func TestOutbox_RetriesUntilDelivered(t *testing.T) {
+	var calls int32
+	flaky := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) <= 2 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	a := newOrg(t, "org.a", 0x01, nil)
+	b := newOrg(t, "org.b", 0x02, flaky)
+	federate(t, a, b)
+
+	bundle := signedBundle(t, a, b.id, a.priv)
+	if _, err := a.outbox.Enqueue(bundle); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go a.outbox.Run(ctx)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		e, _ := a.outbox.Get(bundle.BundleID)
+		if e.Status == crossorg.DeliveryDelivered {
+			if e.Attempts != 3 {
+				t.Errorf("attempts = %d, want 3", e.Attempts)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("not delivered: %+v", e)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestOutbox_RetryExhausted escalates after MaxAttempts failed deliveries.
+func TestOutbox_RetryExhausted(t *testing.T) {
+	down := func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		})
+	}
+	a := newOrg(t, "org.a", 0x01, nil)
+	b := newOrg(t, "org.b", 0x02, down)
+	federate(t, a, b)
+
+	bundle := signedBundle(t, a, b.id, a.priv)
+	if _, err := a.outbox.Enqueue(bundle); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		time.Sleep(10 * time.Millisecond)
+		a.outbox.DeliverDue(context.Background())
+	}
+	e, _ := a.outbox.Get(bundle.BundleID)
+	if e.Status != crossorg.DeliveryFailed || e.Attempts != 3 || !strings.Contains(e.LastError, "CROSS-012") {
+		t.Fatalf("entry = %+v, want failed after 3 attempts with CROSS-012", e)
+	}
+	if countType(a.ledger, ledger.EventEscalationCreated) != 1 {
+		t.Error("exhausted delivery not escalated")
+	}
+	if a.outbox.DeliverDue(context.Background()) != 0 {
+		t.Error("failed entry was attempted again")
+	}
+}
+
+// TestOutbox_RejectedNotRetried stops at the first 4xx answer.
+func TestOutbox_RejectedNotRetried(t *testing.T) {
+	a := newOrg(t, "org.a", 0x01, nil)
+	b := newOrg(t, "org.b", 0x02, nil)
+	federate(t, a, b)
+	// b knows org.a under a different key → bundle signature fails (422).
+	other := newOrg(t, "org.x", 0x09, nil)
+	if err := b.peers.Put(crossorg.Peer{InstitutionID: a.id, Endpoint: a.srv.URL, PublicKey: other.pub}); err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := signedBundle(t, a, b.id, a.priv)
+	if _, err := a.outbox.Enqueue(bundle); err != nil {
+		t.Fatal(err)
+	}
+	a.outbox.DeliverDue(context.Background())
+	e, _ := a.outbox.Get(bundle.BundleID)
+	if e.Status != crossorg.DeliveryFailed || e.Attempts != 1 || !strings.Contains(e.LastError, "CROSS-008") {
+		t.Errorf("entry = %+v, want failed after 1 attempt with CROSS-008", e)
+	}
+}
+
+func TestOutbox_UnknownPeer(t *testing.T) {
+	a := newOrg(t, "org.a", 0x01, nil)
+	if _, err := a.outbox.Enqueue(signedBundle(t, a, "org.nowhere", a.priv)); !errors.Is(err, crossorg.ErrNoActiveFederation) {
+		t.Errorf("err = %v, want ErrNoActiveFederation", err)
+	}
+}
+
+func TestPeerRegistry_Register(t *testing.T) {
+	a := newOrg(t, "org.a", 0x01, nil)
+	b := newOrg(t, "org.b", 0x02, nil)
+	peers := crossorg.NewPeerRegistry()
+	var commits []bool
+	commit := func(p crossorg.Peer, replaced bool) error {
+		commits = append(commits, replaced)
+		return nil
+	}
+
+	if err := peers.Register(crossorg.Peer{InstitutionID: "org.x", Endpoint: "http://x", PublicKey: a.pub}, false, commit); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	// A registered peer is not re-keyed without replace.
+	moved := crossorg.Peer{InstitutionID: "org.x", Endpoint: "http://elsewhere", PublicKey: b.pub}
+	if err := peers.Register(moved, false, commit); !errors.Is(err, crossorg.ErrPeerExists) {
+		t.Errorf("re-key without replace err = %v, want ErrPeerExists", err)
+	}
+	// A failed commit leaves the registry unchanged.
+	if err := peers.Register(moved, true, func(crossorg.Peer, bool) error { return errors.New("ledger down") }); err == nil {
+		t.Error("Register with failing commit succeeded")
+	}
+	if p, _ := peers.Get("org.x"); p.Endpoint != "http://x" || !p.PublicKey.Equal(a.pub) {
+		t.Errorf("peer after rejected re-keys = %+v", p)
+	}
+	if err := peers.Register(moved, true, commit); err != nil {
+		t.Fatalf("Register with replace: %v", err)
+	}
+	if p, _ := peers.Get("org.x"); p.Endpoint != "http://elsewhere" || !p.PublicKey.Equal(b.pub) {
+		t.Errorf("peer after replace = %+v", p)
+	}
+	if len(commits) != 2 || commits[0] || !commits[1] {
+		t.Errorf("commits (replaced) = %v, want [false true]", commits)
+	}
+}
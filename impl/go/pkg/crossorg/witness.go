@@ -0,0 +1,640 @@
+package crossorg
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gowebpki/jcs"
+
+	acpapi "github.com/chelof100/acp-framework/acp-go/pkg/api"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/chelof100/acp-framework/acp-go/pkg/merkle"
+)
+
+// ─── Witness Co-signing ───────────────────────────────────────────────────────
+//
+// An institution signs its own ledger, so on its own it could rewrite history
+// and re-sign every event. Witnesses bind it to a single history: the
+// institution periodically signs a Checkpoint (tree size, Merkle root and the
+// hash-chain head at that size) and asks federated peers to co-sign it:
+//
+//	GET  {witness}/acp/v1/crossorg/witness/checkpoints/{institution_id}
+//	POST {witness}/acp/v1/crossorg/witness/cosign   body: CosignRequest
+//
+// A witness remembers the last checkpoint it co-signed for each institution
+// and co-signs a new one only if a consistency proof shows that it extends
+// that checkpoint, so two diverging histories cannot both be co-signed by
+// the same witness. A checkpoint co-signed by at least the configured quorum
+// of witnesses is recorded in the institution ledger as CHECKPOINT_COSIGNED.
+
+// Witness endpoint paths. WitnessCheckpointsPath is followed by the
+// institution ID.
+const (
+	WitnessCosignPath      = "/acp/v1/crossorg/witness/cosign"
+	WitnessCheckpointsPath = "/acp/v1/crossorg/witness/checkpoints/"
+)
+
+// CheckpointVersion is the version stamped on checkpoints and co-signatures.
+const CheckpointVersion = "1.0"
+
+// DefaultCheckpointInterval is how often Checkpointer.Run publishes.
+const DefaultCheckpointInterval = 10 * time.Minute
+
+var (
+	// ErrCheckpointSigInvalid is returned when a checkpoint signature does
+	// not verify against the institution's keys.
+	ErrCheckpointSigInvalid = errors.New("CROSS-013: checkpoint signature verification failed")
+
+	// ErrCheckpointInconsistent is returned by a witness when a checkpoint
+	// does not extend the last checkpoint it co-signed for the institution.
+	ErrCheckpointInconsistent = errors.New("CROSS-014: checkpoint inconsistent with last witnessed checkpoint")
+
+	// ErrQuorumNotReached is returned when fewer witnesses than the quorum
+	// returned a valid co-signature.
+	ErrQuorumNotReached = errors.New("CROSS-015: witness quorum not reached")
+
+	// ErrCosignatureInvalid is returned when a co-signature does not verify
+	// against the witness's keys or does not match its checkpoint.
+	ErrCosignatureInvalid = errors.New("CROSS-016: cosignature verification failed")
+)
+
+// ─── Types ────────────────────────────────────────────────────────────────────
+
+// Checkpoint is an institution's signed commitment to the first TreeSize
+// events of its ledger.
+type Checkpoint struct {
+	Ver           string      `json:"ver"`
+	InstitutionID string      `json:"institution_id"`
+	TreeSize      int64       `json:"tree_size"`
+	RootHash      merkle.Hash `json:"root_hash"`
+	HeadHash      string      `json:"head_hash"` // hash of the ledger event at sequence TreeSize
+	Timestamp     int64       `json:"timestamp"`
+	KID           string      `json:"kid,omitempty"` // institution keyring key that signed the checkpoint
+	Sig           string      `json:"sig"`
+}
+
+// Cosignature is a witness's signature over the tree state of a checkpoint.
+type Cosignature struct {
+	Ver           string      `json:"ver"`
+	WitnessID     string      `json:"witness_id"`
+	InstitutionID string      `json:"institution_id"`
+	TreeSize      int64       `json:"tree_size"`
+	RootHash      merkle.Hash `json:"root_hash"`
+	HeadHash      string      `json:"head_hash"`
+	Timestamp     int64       `json:"timestamp"` // when the witness co-signed
+	KID           string      `json:"kid,omitempty"`
+	Sig           string      `json:"sig"`
+}
+
+// CosignRequest asks a witness to co-sign Checkpoint.
+type CosignRequest struct {
+	Checkpoint Checkpoint `json:"checkpoint"`
+	// HeadProof is the audit path of HeadHash as the last leaf of the tree.
+	HeadProof []merkle.Hash `json:"head_proof"`
+	// FromSize is the size of the last checkpoint the witness co-signed
+	// (0 if none) and ConsistencyProof proves FromSize → TreeSize.
+	FromSize         int64         `json:"from_size"`
+	ConsistencyProof []merkle.Hash `json:"consistency_proof"`
+}
+
+// CosignedCheckpoint is a checkpoint with the co-signatures that reached
+// quorum, as recorded in the CHECKPOINT_COSIGNED event.
+type CosignedCheckpoint struct {
+	Checkpoint     Checkpoint    `json:"checkpoint"`
+	Cosignatures   []Cosignature `json:"cosignatures"`
+	Quorum         int           `json:"quorum"`
+	LedgerSequence int64         `json:"ledger_sequence"` // sequence of the CHECKPOINT_COSIGNED event
+}
+
+// ─── Signing ──────────────────────────────────────────────────────────────────
+
+// SignCheckpoint sets cp.Ver and cp.KID and signs cp with privKey.
+func SignCheckpoint(cp Checkpoint, kid string, privKey ed25519.PrivateKey) (Checkpoint, error) {
+	cp.Ver, cp.KID, cp.Sig = CheckpointVersion, kid, ""
+	digest, err := witnessDigest(cp)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	cp.Sig = base64.RawURLEncoding.EncodeToString(ed25519.Sign(privKey, digest[:]))
+	return cp, nil
+}
+
+// VerifyCheckpoint checks the signature of cp against pubKey.
+func VerifyCheckpoint(cp Checkpoint, pubKey ed25519.PublicKey) error {
+	sig := cp.Sig
+	cp.Sig = ""
+	return verifyWitnessSig(cp, sig, pubKey, ErrCheckpointSigInvalid)
+}
+
+// SignCosignature builds witnessID's co-signature of cp, signed with privKey.
+func SignCosignature(cp Checkpoint, witnessID, kid string, privKey ed25519.PrivateKey) (Cosignature, error) {
+	cs := Cosignature{
+		Ver:           CheckpointVersion,
+		WitnessID:     witnessID,
+		InstitutionID: cp.InstitutionID,
+		TreeSize:      cp.TreeSize,
+		RootHash:      cp.RootHash,
+		HeadHash:      cp.HeadHash,
+		Timestamp:     time.Now().Unix(),
+		KID:           kid,
+	}
+	digest, err := witnessDigest(cs)
+	if err != nil {
+		return Cosignature{}, err
+	}
+	cs.Sig = base64.RawURLEncoding.EncodeToString(ed25519.Sign(privKey, digest[:]))
+	return cs, nil
+}
+
+// VerifyCosignature checks that cs covers the tree state of cp and that its
+// signature verifies against pubKey.
+func VerifyCosignature(cs Cosignature, cp Checkpoint, pubKey ed25519.PublicKey) error {
+	if cs.InstitutionID != cp.InstitutionID || cs.TreeSize != cp.TreeSize ||
+		cs.RootHash != cp.RootHash || cs.HeadHash != cp.HeadHash {
+		return fmt.Errorf("%w: cosignature of %s does not match the checkpoint", ErrCosignatureInvalid, cs.WitnessID)
+	}
+	sig := cs.Sig
+	cs.Sig = ""
+	return verifyWitnessSig(cs, sig, pubKey, ErrCosignatureInvalid)
+}
+
+// VerifyCosignedCheckpoint checks offline that cc is signed by the
+// institution (any of institutionKeys) and co-signed by at least quorum
+// distinct witnesses registered in peers. The quorum is the verifier's
+// policy: cc.Quorum, set by the publisher, is not trusted, and quorum < 1
+// fails with ErrQuorumNotReached.
+func VerifyCosignedCheckpoint(cc CosignedCheckpoint, institutionKeys []ed25519.PublicKey, peers *PeerRegistry, quorum int) error {
+	if quorum < 1 {
+		return fmt.Errorf("%w: quorum must be at least 1, got %d", ErrQuorumNotReached, quorum)
+	}
+	if err := verifyAny(institutionKeys, func(pk ed25519.PublicKey) error { return VerifyCheckpoint(cc.Checkpoint, pk) }); err != nil {
+		return err
+	}
+	valid := make(map[string]bool, len(cc.Cosignatures))
+	for _, cs := range cc.Cosignatures {
+		p, err := peers.Get(cs.WitnessID)
+		if err != nil || valid[cs.WitnessID] {
+			continue
+		}
+		if verifyAny(peers.Keys(p), func(pk ed25519.PublicKey) error { return VerifyCosignature(cs, cc.Checkpoint, pk) }) == nil {
+			valid[cs.WitnessID] = true
+		}
+	}
+	if len(valid) < quorum {
+		return fmt.Errorf("%w: %d valid of %d required", ErrQuorumNotReached, len(valid), quorum)
+	}
+	return nil
+}
+
+// witnessDigest returns SHA-256(JCS(v)); v carries an empty Sig.
+func witnessDigest(v interface{}) ([32]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("crossorg: marshal checkpoint: %w", err)
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("crossorg: jcs checkpoint: %w", err)
+	}
+	return sha256.Sum256(canonical), nil
+}
+
+func verifyWitnessSig(v interface{}, sig string, pubKey ed25519.PublicKey, sentinel error) error {
+	sigBytes, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || len(sigBytes) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: malformed sig", sentinel)
+	}
+	digest, err := witnessDigest(v)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pubKey, digest[:], sigBytes) {
+		return sentinel
+	}
+	return nil
+}
+
+// ─── Witness ──────────────────────────────────────────────────────────────────
+
+// Witness co-signs checkpoints. WitnessService is the in-process witness;
+// PeerWitness reaches a peer's witness over HTTP.
+type Witness interface {
+	// ID returns the witness's institution ID.
+	ID() string
+	// Latest returns the last checkpoint the witness co-signed for
+	// institutionID; ok is false if it has none.
+	Latest(ctx context.Context, institutionID string) (cp Checkpoint, ok bool, err error)
+	// Cosign verifies req and returns the witness's co-signature.
+	Cosign(ctx context.Context, req CosignRequest) (Cosignature, error)
+}
+
+// WitnessService witnesses the checkpoints of federated peers. It keeps the
+// last co-signed checkpoint per institution in memory.
+type WitnessService struct {
+	witnessID string
+	signer    func() (string, ed25519.PrivateKey)
+	peers     *PeerRegistry
+
+	mu   sync.Mutex // serialises co-signing so each checkpoint extends the last
+	last map[string]Checkpoint
+}
+
+// NewWitnessService creates a witness for witnessID. signer returns the
+// current institution key (kid, private key) used to co-sign.
+func NewWitnessService(witnessID string, signer func() (string, ed25519.PrivateKey), peers *PeerRegistry) *WitnessService {
+	return &WitnessService{witnessID: witnessID, signer: signer, peers: peers, last: make(map[string]Checkpoint)}
+}
+
+// ID returns the witness's institution ID.
+func (ws *WitnessService) ID() string { return ws.witnessID }
+
+// Latest returns the last checkpoint co-signed for institutionID.
+func (ws *WitnessService) Latest(_ context.Context, institutionID string) (Checkpoint, bool, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	cp, ok := ws.last[institutionID]
+	return cp, ok, nil
+}
+
+// Cosign verifies that the checkpoint is signed by a federated peer, that
+// its head hash is the last leaf of its tree and that it extends the last
+// checkpoint co-signed for that peer, then co-signs it. The first checkpoint
+// seen from a peer is trusted as is.
+func (ws *WitnessService) Cosign(_ context.Context, req CosignRequest) (Cosignature, error) {
+	cp := req.Checkpoint
+	if cp.Ver != CheckpointVersion {
+		return Cosignature{}, fmt.Errorf("%w: %q", ErrInvalidVersion, cp.Ver)
+	}
+	if cp.TreeSize < 1 || cp.HeadHash == "" {
+		return Cosignature{}, fmt.Errorf("%w: checkpoint needs tree_size ≥ 1 and head_hash", ErrMalformedEvent)
+	}
+	peer, err := ws.peers.Get(cp.InstitutionID)
+	if err != nil {
+		return Cosignature{}, err
+	}
+	if err := verifyAny(ws.peers.Keys(peer), func(pk ed25519.PublicKey) error { return VerifyCheckpoint(cp, pk) }); err != nil {
+		return Cosignature{}, err
+	}
+	if err := merkle.VerifyInclusion(ledger.LeafFromHash(cp.HeadHash), cp.TreeSize-1, cp.TreeSize, req.HeadProof, cp.RootHash); err != nil {
+		return Cosignature{}, fmt.Errorf("%w: head_hash is not the last leaf: %v", ErrCheckpointInconsistent, err)
+	}
+
+	kid, priv := ws.signer()
+	if len(priv) != ed25519.PrivateKeySize {
+		return Cosignature{}, errors.New("crossorg: no institution signing key to co-sign")
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if last, seen := ws.last[cp.InstitutionID]; seen {
+		if req.FromSize != last.TreeSize {
+			return Cosignature{}, fmt.Errorf("%w: proof from size %d, last witnessed size is %d",
+				ErrCheckpointInconsistent, req.FromSize, last.TreeSize)
+		}
+		if cp.TreeSize < last.TreeSize {
+			return Cosignature{}, fmt.Errorf("%w: tree size %d rolls back %d", ErrCheckpointInconsistent, cp.TreeSize, last.TreeSize)
+		}
+		if err := merkle.VerifyConsistency(last.TreeSize, cp.TreeSize, last.RootHash, cp.RootHash, req.ConsistencyProof); err != nil {
+			return Cosignature{}, fmt.Errorf("%w: %v", ErrCheckpointInconsistent, err)
+		}
+	}
+	cs, err := SignCosignature(cp, ws.witnessID, kid, priv)
+	if err != nil {
+		return Cosignature{}, err
+	}
+	ws.last[cp.InstitutionID] = cp
+	return cs, nil
+}
+
+// ServeCosign handles POST WitnessCosignPath with a CosignRequest body.
+func (ws *WitnessService) ServeCosign(w http.ResponseWriter, r *http.Request) {
+	var req CosignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, "malformed JSON body")
+		return
+	}
+	cs, err := ws.Cosign(r.Context(), req)
+	if err != nil {
+		status, code := ErrorStatus(err)
+		acpapi.WriteError(w, r, status, code, err.Error())
+		return
+	}
+	kid, priv := ws.signer()
+	acpapi.WriteSignedSuccess(w, r, http.StatusOK, cs, kid, priv)
+}
+
+// ServeLatest handles GET WitnessCheckpointsPath{institution_id}.
+func (ws *WitnessService) ServeLatest(w http.ResponseWriter, r *http.Request) {
+	cp, ok, _ := ws.Latest(r.Context(), r.PathValue("institution_id"))
+	if !ok {
+		acpapi.WriteError(w, r, http.StatusNotFound, acpapi.ErrSYS004, "no checkpoint witnessed for institution")
+		return
+	}
+	kid, priv := ws.signer()
+	acpapi.WriteSignedSuccess(w, r, http.StatusOK, cp, kid, priv)
+}
+
+// PeerWitness is the Witness of a registered peer, reached at its endpoint.
+type PeerWitness struct {
+	institutionID string
+	peers         *PeerRegistry
+	client        *http.Client
+}
+
+// NewPeerWitness returns the witness of peer institutionID. The peer is
+// looked up on every call, so it may be registered later. A nil client uses
+// a 30 s timeout.
+func NewPeerWitness(institutionID string, peers *PeerRegistry, client *http.Client) *PeerWitness {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &PeerWitness{institutionID: institutionID, peers: peers, client: client}
+}
+
+// ID returns the peer's institution ID.
+func (pw *PeerWitness) ID() string { return pw.institutionID }
+
+// Latest fetches the last checkpoint the peer co-signed for institutionID.
+func (pw *PeerWitness) Latest(ctx context.Context, institutionID string) (Checkpoint, bool, error) {
+	var cp Checkpoint
+	status, err := pw.call(ctx, http.MethodGet, WitnessCheckpointsPath+url.PathEscape(institutionID), nil, &cp)
+	if status == http.StatusNotFound {
+		return Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	return cp, true, nil
+}
+
+// Cosign sends req to the peer's witness.
+func (pw *PeerWitness) Cosign(ctx context.Context, req CosignRequest) (Cosignature, error) {
+	var cs Cosignature
+	if _, err := pw.call(ctx, http.MethodPost, WitnessCosignPath, req, &cs); err != nil {
+		return Cosignature{}, err
+	}
+	return cs, nil
+}
+
+// call sends one request to the peer and decodes the data of a 200 response
+// into out.
+func (pw *PeerWitness) call(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	peer, err := pw.peers.Get(pw.institutionID)
+	if err != nil {
+		return 0, err
+	}
+	var rd io.Reader
+	if body != nil {
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("crossorg: marshal request: %w", err)
+		}
+		rd = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(peer.Endpoint, "/")+path, rd)
+	if err != nil {
+		return 0, fmt.Errorf("crossorg: build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := pw.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("crossorg: witness %s: %w", pw.institutionID, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("crossorg: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var er acpapi.ErrorResponse
+		_ = json.Unmarshal(raw, &er)
+		return resp.StatusCode, fmt.Errorf("crossorg: witness %s answered %d %s: %s",
+			pw.institutionID, resp.StatusCode, er.Error.Code, er.Error.Message)
+	}
+	env := struct {
+		Data interface{} `json:"data"`
+	}{Data: out}
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return resp.StatusCode, fmt.Errorf("crossorg: decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// ─── Checkpointer ─────────────────────────────────────────────────────────────
+
+// CheckpointLedger is the subset of the institution ledger checkpoints are
+// built from and recorded in.
+type CheckpointLedger interface {
+	Ledger
+	TreeHead(size int64) (merkle.TreeHead, error)
+	InclusionProof(sequence, size int64) ([]merkle.Hash, error)
+	ConsistencyProof(first, second int64) ([]merkle.Hash, error)
+	GetBySequence(seq int64) (ledger.Event, bool)
+}
+
+// CheckpointerConfig tunes publication. Zero values take the defaults.
+type CheckpointerConfig struct {
+	Quorum   int           // co-signatures required (default: every witness)
+	Interval time.Duration // Run publishes every Interval (default DefaultCheckpointInterval)
+	Timeout  time.Duration // per-witness timeout (default 30 s)
+}
+
+// Checkpointer publishes the institution's checkpoints to its witnesses and
+// records those that reach quorum.
+type Checkpointer struct {
+	institutionID string
+	signer        func() (string, ed25519.PrivateKey)
+	peers         *PeerRegistry
+	ledger        CheckpointLedger
+	witnesses     []Witness
+	cfg           CheckpointerConfig
+
+	mu     sync.Mutex // serialises publication
+	latest *CosignedCheckpoint
+}
+
+// NewCheckpointer creates a Checkpointer for institutionID. signer returns
+// the current institution key used to sign checkpoints; co-signatures are
+// verified against the keys of the witnesses registered in peers.
+func NewCheckpointer(institutionID string, signer func() (string, ed25519.PrivateKey), peers *PeerRegistry,
+	l CheckpointLedger, witnesses []Witness, cfg CheckpointerConfig) *Checkpointer {
+	if cfg.Quorum <= 0 {
+		cfg.Quorum = len(witnesses)
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultCheckpointInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	return &Checkpointer{institutionID: institutionID, signer: signer, peers: peers, ledger: l, witnesses: witnesses, cfg: cfg}
+}
+
+// Quorum returns the number of co-signatures a checkpoint needs.
+func (c *Checkpointer) Quorum() int { return c.cfg.Quorum }
+
+// WitnessIDs returns the IDs of the configured witnesses.
+func (c *Checkpointer) WitnessIDs() []string {
+	ids := make([]string, len(c.witnesses))
+	for i, w := range c.witnesses {
+		ids[i] = w.ID()
+	}
+	return ids
+}
+
+// Latest returns the last checkpoint recorded.
+func (c *Checkpointer) Latest() (CosignedCheckpoint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latest == nil {
+		return CosignedCheckpoint{}, false
+	}
+	return *c.latest, true
+}
+
+// Publish signs a checkpoint of the current ledger, collects co-signatures
+// from every witness and, if at least Quorum are valid, records the
+// checkpoint as a CHECKPOINT_COSIGNED event. If nothing but the last
+// checkpoint event was appended since, the last checkpoint is returned.
+func (c *Checkpointer) Publish(ctx context.Context) (CosignedCheckpoint, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	th, err := c.ledger.TreeHead(0)
+	if err != nil {
+		return CosignedCheckpoint{}, fmt.Errorf("crossorg: tree head: %w", err)
+	}
+	if c.latest != nil && th.TreeSize <= c.latest.LedgerSequence {
+		return *c.latest, nil
+	}
+	head, ok := c.ledger.GetBySequence(th.TreeSize)
+	if !ok {
+		return CosignedCheckpoint{}, fmt.Errorf("crossorg: no ledger event at sequence %d", th.TreeSize)
+	}
+	headProof, err := c.ledger.InclusionProof(th.TreeSize, th.TreeSize)
+	if err != nil {
+		return CosignedCheckpoint{}, fmt.Errorf("crossorg: head proof: %w", err)
+	}
+	kid, priv := c.signer()
+	if len(priv) != ed25519.PrivateKeySize {
+		return CosignedCheckpoint{}, errors.New("crossorg: no institution signing key to sign checkpoints")
+	}
+	cp, err := SignCheckpoint(Checkpoint{
+		InstitutionID: c.institutionID,
+		TreeSize:      th.TreeSize,
+		RootHash:      th.RootHash,
+		HeadHash:      head.Hash,
+		Timestamp:     time.Now().Unix(),
+	}, kid, priv)
+	if err != nil {
+		return CosignedCheckpoint{}, err
+	}
+
+	cosigs := make([]*Cosignature, len(c.witnesses))
+	errs := make([]error, len(c.witnesses))
+	var wg sync.WaitGroup
+	for i, w := range c.witnesses {
+		wg.Add(1)
+		go func(i int, w Witness) {
+			defer wg.Done()
+			wctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+			defer cancel()
+			cs, err := c.collect(wctx, w, cp, headProof)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", w.ID(), err)
+				return
+			}
+			cosigs[i] = &cs
+		}(i, w)
+	}
+	wg.Wait()
+
+	cc := CosignedCheckpoint{Checkpoint: cp, Cosignatures: []Cosignature{}, Quorum: c.cfg.Quorum}
+	for _, cs := range cosigs {
+		if cs != nil {
+			cc.Cosignatures = append(cc.Cosignatures, *cs)
+		}
+	}
+	if c.cfg.Quorum < 1 || len(cc.Cosignatures) < c.cfg.Quorum {
+		return CosignedCheckpoint{}, fmt.Errorf("%w: %d of %d required: %w",
+			ErrQuorumNotReached, len(cc.Cosignatures), c.cfg.Quorum, errors.Join(errs...))
+	}
+
+	ev, err := c.ledger.Append(ledger.EventCheckpointCosigned, map[string]interface{}{
+		"checkpoint":   cc.Checkpoint,
+		"cosignatures": cc.Cosignatures,
+		"quorum":       cc.Quorum,
+	})
+	if err != nil {
+		return CosignedCheckpoint{}, fmt.Errorf("crossorg: record checkpoint: %w", err)
+	}
+	cc.LedgerSequence = ev.Sequence
+	c.latest = &cc
+	return cc, nil
+}
+
+// collect asks w to co-sign cp, proving consistency with the last checkpoint
+// w co-signed, and verifies the returned co-signature.
+func (c *Checkpointer) collect(ctx context.Context, w Witness, cp Checkpoint, headProof []merkle.Hash) (Cosignature, error) {
+	peer, err := c.peers.Get(w.ID())
+	if err != nil {
+		return Cosignature{}, err
+	}
+	req := CosignRequest{Checkpoint: cp, HeadProof: headProof, ConsistencyProof: []merkle.Hash{}}
+	last, seen, err := w.Latest(ctx, c.institutionID)
+	if err != nil {
+		return Cosignature{}, err
+	}
+	if seen {
+		if last.TreeSize > cp.TreeSize {
+			return Cosignature{}, fmt.Errorf("%w: witness saw tree size %d, ledger has %d",
+				ErrCheckpointInconsistent, last.TreeSize, cp.TreeSize)
+		}
+		req.FromSize = last.TreeSize
+		if req.ConsistencyProof, err = c.ledger.ConsistencyProof(last.TreeSize, cp.TreeSize); err != nil {
+			return Cosignature{}, fmt.Errorf("crossorg: consistency proof: %w", err)
+		}
+	}
+	cs, err := w.Cosign(ctx, req)
+	if err != nil {
+		return Cosignature{}, err
+	}
+	if cs.WitnessID != w.ID() {
+		return Cosignature{}, fmt.Errorf("%w: signed by %s", ErrCosignatureInvalid, cs.WitnessID)
+	}
+	if err := verifyAny(c.peers.Keys(peer), func(pk ed25519.PublicKey) error { return VerifyCosignature(cs, cp, pk) }); err != nil {
+		return Cosignature{}, err
+	}
+	return cs, nil
+}
+
+// Run publishes a checkpoint every Interval until ctx is cancelled. onError,
+// if not nil, receives each failed publication.
+func (c *Checkpointer) Run(ctx context.Context, onError func(error)) {
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.Publish(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
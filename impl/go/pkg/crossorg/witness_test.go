@@ -0,0 +1,186 @@
+package crossorg_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/crossorg"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+)
+
+// appendEvents appends n governance events to l.
+func appendEvents(t *testing.T, l *ledger.InMemoryLedger, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := l.Append(ledger.EventGovernance, map[string]interface{}{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkpointer publishes inst's checkpoints to the witnesses' HTTP endpoints.
+func checkpointer(inst *org, quorum int, witnesses ...*org) *crossorg.Checkpointer {
+	ws := make([]crossorg.Witness, len(witnesses))
+	for i, w := range witnesses {
+		ws[i] = crossorg.NewPeerWitness(w.id, inst.peers, nil)
+	}
+	return crossorg.NewCheckpointer(inst.id, func() (string, ed25519.PrivateKey) { return "k1", inst.priv },
+		inst.peers, inst.ledger, ws, crossorg.CheckpointerConfig{Quorum: quorum})
+}
+
+func TestCheckpointer_QuorumCosignAndRecord(t *testing.T) {
+	inst := newOrg(t, "org.inst", 0x01, nil)
+	w1 := newOrg(t, "org.w1", 0x02, nil)
+	w2 := newOrg(t, "org.w2", 0x03, nil)
+	w3 := newOrg(t, "org.w3", 0x04, nil)
+	federate(t, inst, w1)
+	federate(t, inst, w2)
+	// w3 is a peer of inst, but does not know inst: it refuses to co-sign.
+	if err := inst.peers.Put(crossorg.Peer{InstitutionID: w3.id, Endpoint: w3.srv.URL, PublicKey: w3.pub}); err != nil {
+		t.Fatal(err)
+	}
+	cp := checkpointer(inst, 2, w1, w2, w3)
+	appendEvents(t, inst.ledger, 4)
+
+	cc, err := cp.Publish(context.Background())
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if cc.Checkpoint.TreeSize != 5 || len(cc.Cosignatures) != 2 || cc.Quorum != 2 {
+		t.Fatalf("checkpoint = %+v", cc)
+	}
+	head, _ := inst.ledger.GetBySequence(5)
+	if cc.Checkpoint.HeadHash != head.Hash {
+		t.Errorf("head_hash = %s, want %s", cc.Checkpoint.HeadHash, head.Hash)
+	}
+	if err := crossorg.VerifyCosignedCheckpoint(cc, []ed25519.PublicKey{inst.pub}, inst.peers, 2); err != nil {
+		t.Errorf("VerifyCosignedCheckpoint: %v", err)
+	}
+	if err := crossorg.VerifyCosignedCheckpoint(cc, []ed25519.PublicKey{inst.pub}, inst.peers, 3); !errors.Is(err, crossorg.ErrQuorumNotReached) {
+		t.Errorf("quorum 3: err = %v, want ErrQuorumNotReached", err)
+	}
+	// The quorum claimed by the bundle is not a fallback.
+	lowered := cc
+	lowered.Quorum = 0
+	for _, quorum := range []int{0, -1} {
+		if err := crossorg.VerifyCosignedCheckpoint(lowered, []ed25519.PublicKey{inst.pub}, inst.peers, quorum); !errors.Is(err, crossorg.ErrQuorumNotReached) {
+			t.Errorf("quorum %d: err = %v, want ErrQuorumNotReached", quorum, err)
+		}
+	}
+	if err := crossorg.VerifyCosignedCheckpoint(cc, []ed25519.PublicKey{w1.pub}, inst.peers, 2); !errors.Is(err, crossorg.ErrCheckpointSigInvalid) {
+		t.Errorf("wrong institution key: err = %v, want ErrCheckpointSigInvalid", err)
+	}
+
+	// The co-signed checkpoint is recorded with a conformant payload.
+	ev, ok := inst.ledger.GetBySequence(cc.LedgerSequence)
+	if !ok || ev.EventType != ledger.EventCheckpointCosigned {
+		t.Fatalf("ledger_sequence %d → %+v, want CHECKPOINT_COSIGNED", cc.LedgerSequence, ev)
+	}
+	if err := ledger.CheckPayload(ev.EventType, ev.Payload); err != nil {
+		t.Errorf("CheckPayload: %v", err)
+	}
+	if errs := inst.ledger.Verify(); len(errs) != 0 {
+		t.Errorf("ledger verify: %v", errs)
+	}
+
+	// Nothing new since: the last checkpoint is returned, nothing appended.
+	size := inst.ledger.Size()
+	again, err := cp.Publish(context.Background())
+	if err != nil || again.LedgerSequence != cc.LedgerSequence || inst.ledger.Size() != size {
+		t.Fatalf("idle Publish = %+v, %v (ledger %d → %d)", again, err, size, inst.ledger.Size())
+	}
+
+	// The next checkpoint is proven consistent with the one each witness saw.
+	appendEvents(t, inst.ledger, 3)
+	next, err := cp.Publish(context.Background())
+	if err != nil {
+		t.Fatalf("Publish (2nd): %v", err)
+	}
+	if next.Checkpoint.TreeSize != int64(size)+3 || len(next.Cosignatures) != 2 {
+		t.Fatalf("2nd checkpoint = %+v", next)
+	}
+	for _, w := range []*org{w1, w2} {
+		seen, ok, _ := w.witness.Latest(context.Background(), inst.id)
+		if !ok || seen.TreeSize != next.Checkpoint.TreeSize {
+			t.Errorf("%s last witnessed = %+v", w.id, seen)
+		}
+	}
+	if latest, ok := cp.Latest(); !ok || latest.LedgerSequence != next.LedgerSequence {
+		t.Errorf("Latest = %+v", latest)
+	}
+}
+
+func TestCheckpointer_QuorumNotReached(t *testing.T) {
+	inst := newOrg(t, "org.inst", 0x01, nil)
+	w1 := newOrg(t, "org.w1", 0x02, nil)
+	w2 := newOrg(t, "org.w2", 0x03, nil)
+	federate(t, inst, w1)
+	federate(t, inst, w2)
+	w2.srv.Close() // unreachable witness
+
+	size := inst.ledger.Size()
+	_, err := checkpointer(inst, 2, w1, w2).Publish(context.Background())
+	if !errors.Is(err, crossorg.ErrQuorumNotReached) {
+		t.Fatalf("err = %v, want ErrQuorumNotReached", err)
+	}
+	if status, code := crossorg.ErrorStatus(err); status != http.StatusServiceUnavailable || code != "CROSS-015" {
+		t.Errorf("ErrorStatus = %d %s", status, code)
+	}
+	if inst.ledger.Size() != size {
+		t.Error("checkpoint without quorum was recorded")
+	}
+	if _, err := checkpointer(inst, 0).Publish(context.Background()); !errors.Is(err, crossorg.ErrQuorumNotReached) {
+		t.Errorf("no witnesses: err = %v, want ErrQuorumNotReached", err)
+	}
+}
+
+func TestWitness_RejectsRewrittenHistory(t *testing.T) {
+	inst := newOrg(t, "org.inst", 0x01, nil)
+	w := newOrg(t, "org.w", 0x02, nil)
+	federate(t, inst, w)
+	signer := func() (string, ed25519.PrivateKey) { return "k1", inst.priv }
+	inProcess := []crossorg.Witness{w.witness}
+
+	appendEvents(t, inst.ledger, 5)
+	honest := crossorg.NewCheckpointer(inst.id, signer, inst.peers, inst.ledger, inProcess, crossorg.CheckpointerConfig{})
+	if _, err := honest.Publish(context.Background()); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	// The institution rebuilds its ledger with other events and re-signs it:
+	// the witness refuses to co-sign a history that forks from the one it saw.
+	forked, err := ledger.NewInMemoryLedger(inst.id, inst.priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, forked, 20)
+	rewriter := crossorg.NewCheckpointer(inst.id, signer, inst.peers, forked, inProcess, crossorg.CheckpointerConfig{})
+	if _, err := rewriter.Publish(context.Background()); !errors.Is(err, crossorg.ErrCheckpointInconsistent) {
+		t.Errorf("forked history: err = %v, want ErrCheckpointInconsistent", err)
+	}
+
+	// Rolling back to a shorter tree is refused as well.
+	short, _ := ledger.NewInMemoryLedger(inst.id, inst.priv)
+	rollback := crossorg.NewCheckpointer(inst.id, signer, inst.peers, short, inProcess, crossorg.CheckpointerConfig{})
+	if _, err := rollback.Publish(context.Background()); !errors.Is(err, crossorg.ErrCheckpointInconsistent) {
+		t.Errorf("rollback: err = %v, want ErrCheckpointInconsistent", err)
+	}
+
+	// Checkpoints signed by another key or from unknown institutions are refused.
+	stranger := newOrg(t, "org.stranger", 0x09, nil)
+	th, _ := inst.ledger.TreeHead(0)
+	head, _ := inst.ledger.GetBySequence(th.TreeSize)
+	forged, _ := crossorg.SignCheckpoint(crossorg.Checkpoint{
+		InstitutionID: inst.id, TreeSize: th.TreeSize, RootHash: th.RootHash, HeadHash: head.Hash,
+	}, "", stranger.priv)
+	if _, err := w.witness.Cosign(context.Background(), crossorg.CosignRequest{Checkpoint: forged}); !errors.Is(err, crossorg.ErrCheckpointSigInvalid) {
+		t.Errorf("forged signature: err = %v, want ErrCheckpointSigInvalid", err)
+	}
+	forged.InstitutionID = stranger.id
+	if _, err := w.witness.Cosign(context.Background(), crossorg.CosignRequest{Checkpoint: forged}); !errors.Is(err, crossorg.ErrNoActiveFederation) {
+		t.Errorf("unknown institution: err = %v, want ErrNoActiveFederation", err)
+	}
+}
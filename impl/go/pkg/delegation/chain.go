@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
 	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
 )
 
@@ -23,6 +24,7 @@ var (
 	ErrParentHashMismatch      = errors.New("acp/delegation: parent_hash does not match parent token")
 	ErrMissingParentHash       = errors.New("acp/delegation: delegated token missing parent_hash")
 	ErrChainTooLong            = errors.New("acp/delegation: delegation chain exceeds absolute depth limit")
+	ErrIssuerNotParentSubject  = errors.New("acp/delegation: delegated token not issued by the parent's subject")
 )
 
 // Chain represents an ordered sequence of capability tokens forming a
@@ -140,6 +142,31 @@ func ValidateChainJSON(rawTokens [][]byte, issuerKeys map[string]ed25519.PublicK
 	return chain, nil
 }
 
+// ValidateChainDID parses a chain whose issuers are DIDs, verifies every
+// token's signature against its issuer's keys resolved through r (as of the
+// token's iat), requires each delegated token to be issued by its parent's
+// subject, and then enforces the §7 link constraints.
+func ValidateChainDID(rawTokens [][]byte, r did.Resolver) (Chain, error) {
+	if len(rawTokens) > tokens.MaxDelegationDepth+1 {
+		return nil, ErrChainTooLong
+	}
+	chain := make(Chain, len(rawTokens))
+	for i, raw := range rawTokens {
+		t, err := tokens.ParseAndVerifyDID(raw, r, tokens.VerificationRequest{})
+		if err != nil {
+			return nil, fmt.Errorf("acp/delegation: token %d: %w", i, err)
+		}
+		if i > 0 && t.Issuer != chain[i-1].Subject {
+			return nil, fmt.Errorf("acp/delegation: link %d→%d: %w", i-1, i, ErrIssuerNotParentSubject)
+		}
+		chain[i] = t
+	}
+	if err := Validate(chain, nil); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
 // ─── Helpers ──────────────────────────────────────────────────────────────────
 
 func capInSlice(slice []string, cap string) bool {
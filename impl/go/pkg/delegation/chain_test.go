@@ -1,11 +1,16 @@
 package delegation_test
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/gowebpki/jcs"
+
 	acpcrypto "github.com/chelof100/acp-framework/acp-go/pkg/crypto"
 	"github.com/chelof100/acp-framework/acp-go/pkg/delegation"
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
 	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
 )
 
@@ -155,3 +160,50 @@ func TestNewChain_EmptyChain_Rejected(t *testing.T) {
 		t.Error("empty chain should be rejected")
 	}
 }
+
+// signDID signs tok as its issuer and returns the token JSON.
+func signDID(t *testing.T, issuer *acpcrypto.AgentIdentity, tok *tokens.CapabilityToken) []byte {
+	t.Helper()
+	tok.Signature = ""
+	data, _ := json.Marshal(tok)
+	var m map[string]interface{}
+	_ = json.Unmarshal(data, &m)
+	delete(m, "sig")
+	mBytes, _ := json.Marshal(m)
+	canonical, err := jcs.Transform(mBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok.Signature = issuer.Sign(canonical)
+	raw, _ := json.Marshal(tok)
+	return raw
+}
+
+func TestValidateChainDID(t *testing.T) {
+	inst, _ := acpcrypto.GenerateIdentity()
+	agent1, _ := acpcrypto.GenerateIdentity()
+	agent2, _ := acpcrypto.GenerateIdentity()
+	instDID, a1DID, a2DID := did.EncodeKey(inst.PublicKey), did.EncodeKey(agent1.PublicKey), did.EncodeKey(agent2.PublicKey)
+
+	root := makeToken(inst, "root", []string{"acp:cap:financial.payment"}, "org.bank/accounts", 3600, true, 2, nil)
+	root.Issuer, root.Subject = instDID, a1DID
+	rootRaw := signDID(t, inst, root)
+	rootHash := hashToken(root)
+	child := makeToken(agent1, "child", []string{"acp:cap:financial.payment"}, "org.bank/accounts/ACC-001", 1800, false, 0, &rootHash)
+	child.Issuer, child.Subject = a1DID, a2DID
+
+	if _, err := delegation.ValidateChainDID([][]byte{rootRaw, signDID(t, agent1, child)}, did.KeyResolver{}); err != nil {
+		t.Fatalf("valid DID chain rejected: %v", err)
+	}
+
+	// Signed by a key other than the issuer DID's.
+	if _, err := delegation.ValidateChainDID([][]byte{rootRaw, signDID(t, agent2, child)}, did.KeyResolver{}); !errors.Is(err, tokens.ErrCT002InvalidSignature) {
+		t.Errorf("forged child: err = %v", err)
+	}
+
+	// Issued by someone other than the parent's subject.
+	child.Issuer = a2DID
+	if _, err := delegation.ValidateChainDID([][]byte{rootRaw, signDID(t, agent2, child)}, did.KeyResolver{}); !errors.Is(err, delegation.ErrIssuerNotParentSubject) {
+		t.Errorf("foreign issuer: err = %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"time"
+)
+
+// KeySource returns the Ed25519 keys of a participant valid at Unix time ts.
+// registry.InMemoryRegistry implements it for agents.
+type KeySource interface {
+	KeysAt(id string, ts int64) ([]ed25519.PublicKey, error)
+}
+
+// KeySourceFunc adapts a function to KeySource.
+type KeySourceFunc func(id string, ts int64) ([]ed25519.PublicKey, error)
+
+// KeysAt calls f(id, ts).
+func (f KeySourceFunc) KeysAt(id string, ts int64) ([]ed25519.PublicKey, error) {
+	return f(id, ts)
+}
+
+// ACPDResolver resolves did:acpd:<id> (ACP-D §4.1), where id is an AgentID or
+// an institution ID known to one of Sources. Sources are tried in order; the
+// first that has keys for id valid at the resolution time wins.
+type ACPDResolver struct {
+	Sources []KeySource
+	Now     func() time.Time // nil = time.Now
+}
+
+// Resolve returns the document of did with the keys valid now.
+func (a *ACPDResolver) Resolve(did string) (*Document, error) {
+	now := time.Now
+	if a.Now != nil {
+		now = a.Now
+	}
+	return a.ResolveAt(did, now().Unix())
+}
+
+// ResolveAt returns the document of did with the keys valid at ts, newest
+// first. During a key-rotation overlap both keys are listed.
+func (a *ACPDResolver) ResolveAt(did string, ts int64) (*Document, error) {
+	method, id, err := Parse(did)
+	if err != nil {
+		return nil, err
+	}
+	if method != "acpd" {
+		return nil, fmt.Errorf("%w: %q is not did:acpd", ErrUnsupportedMethod, did)
+	}
+	var lastErr error
+	for _, src := range a.Sources {
+		keys, err := src.KeysAt(id, ts)
+		if err == nil && len(keys) > 0 {
+			return newDocument(did, keys), nil
+		}
+		lastErr = err
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrNotFound, did, lastErr)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, did)
+}
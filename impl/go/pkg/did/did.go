@@ -0,0 +1,222 @@
+// Package did resolves W3C DID Core identifiers to Ed25519 verification keys.
+//
+// ACP-D §4.1 requires every participant to hold a DID. Three methods are
+// supported:
+//
+//	did:key   self-certifying; the key is encoded in the identifier
+//	did:web   document fetched from https://<host>/.well-known/did.json
+//	          (or /<path>/did.json); the fetch function is pluggable so
+//	          documents can be served from local fixtures, and network
+//	          fetching is opt-in (AllowHosts, CachedFetch)
+//	did:acpd  ACP-D participant; the identifier is an AgentID or an
+//	          institution ID resolved against the local key stores,
+//	          including keys retired by a rotation
+//
+// Token verification (tokens.ParseAndVerifyDID), delegation chains
+// (delegation.ValidateChainDID) and cross-org peers (crossorg.PeerRegistry.PutDID)
+// resolve issuers through a Resolver.
+package did
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// ─── Errors ───────────────────────────────────────────────────────────────────
+
+var (
+	// ErrInvalidDID is returned for identifiers that are not did:<method>:<id>.
+	ErrInvalidDID = errors.New("acp/did: malformed DID")
+
+	// ErrUnsupportedMethod is returned when no resolver handles the DID method.
+	ErrUnsupportedMethod = errors.New("acp/did: unsupported DID method")
+
+	// ErrNotFound is returned when a DID cannot be resolved to a document.
+	ErrNotFound = errors.New("acp/did: DID not found")
+
+	// ErrNoEd25519Key is returned when a document has no usable Ed25519 key.
+	ErrNoEd25519Key = errors.New("acp/did: no Ed25519 verification method")
+)
+
+// Verification method types understood by this package.
+const (
+	TypeEd25519VerificationKey2020 = "Ed25519VerificationKey2020" // publicKeyMultibase
+	TypeEd25519VerificationKey2018 = "Ed25519VerificationKey2018" // publicKeyBase58
+	TypeJsonWebKey2020             = "JsonWebKey2020"             // publicKeyJwk (OKP / Ed25519)
+)
+
+// ─── Document ─────────────────────────────────────────────────────────────────
+
+// JWK is the subset of an RFC 8037 OKP key used in publicKeyJwk.
+type JWK struct {
+	KTY string `json:"kty"` // "OKP"
+	CRV string `json:"crv"` // "Ed25519"
+	X   string `json:"x"`   // base64url public key
+}
+
+// VerificationMethod is one entry of a DID document's verificationMethod.
+type VerificationMethod struct {
+	ID                 string `json:"id"`
+	Type               string `json:"type"`
+	Controller         string `json:"controller"`
+	PublicKeyMultibase string `json:"publicKeyMultibase,omitempty"`
+	PublicKeyBase58    string `json:"publicKeyBase58,omitempty"`
+	PublicKeyJwk       *JWK   `json:"publicKeyJwk,omitempty"`
+}
+
+// Ed25519 decodes the method's public key.
+func (vm VerificationMethod) Ed25519() (ed25519.PublicKey, error) {
+	var raw []byte
+	var err error
+	switch vm.Type {
+	case TypeEd25519VerificationKey2020:
+		return decodeMultibaseKey(vm.PublicKeyMultibase)
+	case TypeEd25519VerificationKey2018:
+		raw, err = base58.Decode(vm.PublicKeyBase58)
+	case TypeJsonWebKey2020:
+		if vm.PublicKeyJwk == nil || vm.PublicKeyJwk.KTY != "OKP" || vm.PublicKeyJwk.CRV != "Ed25519" {
+			return nil, fmt.Errorf("%w: %s is not an OKP/Ed25519 JWK", ErrNoEd25519Key, vm.ID)
+		}
+		raw, err = base64.RawURLEncoding.DecodeString(vm.PublicKeyJwk.X)
+	default:
+		return nil, fmt.Errorf("%w: %s has type %q", ErrNoEd25519Key, vm.ID, vm.Type)
+	}
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: %s has a malformed key", ErrNoEd25519Key, vm.ID)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// Document is a DID document (W3C DID Core §5), reduced to what ACP uses.
+type Document struct {
+	Context            []string             `json:"@context,omitempty"`
+	ID                 string               `json:"id"`
+	Controller         string               `json:"controller,omitempty"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod"`
+	AssertionMethod    []string             `json:"assertionMethod,omitempty"`
+}
+
+// Ed25519Keys returns the document's Ed25519 keys in document order. Methods
+// of other types are skipped; ErrNoEd25519Key is returned if none remain.
+func (d *Document) Ed25519Keys() ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	for _, vm := range d.VerificationMethod {
+		if pk, err := vm.Ed25519(); err == nil {
+			keys = append(keys, pk)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoEd25519Key, d.ID)
+	}
+	return keys, nil
+}
+
+// newDocument builds a document whose verification methods are keys, with
+// fragment IDs #key-1, #key-2, … (did:key uses the multibase value instead).
+func newDocument(did string, keys []ed25519.PublicKey) *Document {
+	doc := &Document{Context: []string{"https://www.w3.org/ns/did/v1"}, ID: did}
+	for i, pk := range keys {
+		id := fmt.Sprintf("%s#key-%d", did, i+1)
+		doc.VerificationMethod = append(doc.VerificationMethod, VerificationMethod{
+			ID:                 id,
+			Type:               TypeEd25519VerificationKey2020,
+			Controller:         did,
+			PublicKeyMultibase: encodeMultibaseKey(pk),
+		})
+		doc.AssertionMethod = append(doc.AssertionMethod, id)
+	}
+	return doc
+}
+
+// ─── Resolution ───────────────────────────────────────────────────────────────
+
+// Resolver resolves a DID to its document.
+type Resolver interface {
+	Resolve(did string) (*Document, error)
+}
+
+// TimeResolver is implemented by resolvers that know key history: ResolveAt
+// returns the document as of Unix time ts, listing only keys valid then.
+type TimeResolver interface {
+	ResolveAt(did string, ts int64) (*Document, error)
+}
+
+// Parse splits did:<method>:<method-specific-id>.
+func Parse(did string) (method, id string, err error) {
+	parts := strings.SplitN(did, ":", 3)
+	if len(parts) != 3 || parts[0] != "did" || parts[1] == "" || parts[2] == "" {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidDID, did)
+	}
+	return parts[1], parts[2], nil
+}
+
+// IsDID reports whether s has the did:<method>:<id> shape.
+func IsDID(s string) bool {
+	_, _, err := Parse(s)
+	return err == nil
+}
+
+// Multi dispatches on the DID method: "key", "web", "acpd", …
+type Multi map[string]Resolver
+
+// Resolve resolves did with the resolver registered for its method.
+func (m Multi) Resolve(did string) (*Document, error) {
+	r, err := m.resolver(did)
+	if err != nil {
+		return nil, err
+	}
+	return r.Resolve(did)
+}
+
+// ResolveAt resolves did as of ts when the method's resolver is a
+// TimeResolver, and falls back to Resolve otherwise.
+func (m Multi) ResolveAt(did string, ts int64) (*Document, error) {
+	r, err := m.resolver(did)
+	if err != nil {
+		return nil, err
+	}
+	if tr, ok := r.(TimeResolver); ok {
+		return tr.ResolveAt(did, ts)
+	}
+	return r.Resolve(did)
+}
+
+func (m Multi) resolver(did string) (Resolver, error) {
+	method, _, err := Parse(did)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := m[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedMethod, method)
+	}
+	return r, nil
+}
+
+// ResolveKeys resolves did and returns its Ed25519 keys.
+func ResolveKeys(r Resolver, did string) ([]ed25519.PublicKey, error) {
+	doc, err := r.Resolve(did)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Ed25519Keys()
+}
+
+// ResolveKeysAt is ResolveKeys as of Unix time ts. Resolvers without key
+// history (did:key, did:web) return their current keys.
+func ResolveKeysAt(r Resolver, did string, ts int64) ([]ed25519.PublicKey, error) {
+	tr, ok := r.(TimeResolver)
+	if !ok {
+		return ResolveKeys(r, did)
+	}
+	doc, err := tr.ResolveAt(did, ts)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Ed25519Keys()
+}
@@ -0,0 +1,186 @@
+package did_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
+	"github.com/chelof100/acp-framework/acp-go/pkg/keyring"
+	"github.com/chelof100/acp-framework/acp-go/pkg/registry"
+)
+
+func seedKey(b byte) ed25519.PrivateKey {
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = b
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func pub(priv ed25519.PrivateKey) ed25519.PublicKey { return priv.Public().(ed25519.PublicKey) }
+
+// ── did:key ──────────────────────────────────────────────────────────────────
+
+func TestKeyResolver_RoundTrip(t *testing.T) {
+	pk := pub(seedKey(1))
+	id := did.EncodeKey(pk)
+	if !strings.HasPrefix(id, "did:key:z6Mk") {
+		t.Fatalf("EncodeKey = %s, want did:key:z6Mk…", id)
+	}
+	keys, err := did.ResolveKeys(did.KeyResolver{}, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || !keys[0].Equal(pk) {
+		t.Errorf("resolved keys = %v", keys)
+	}
+}
+
+func TestKeyResolver_Rejections(t *testing.T) {
+	for _, id := range []string{"did:key:", "did:key:abc", "did:key:z6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc", "did:web:example.com"} {
+		if _, err := did.DecodeKey(id); err == nil {
+			t.Errorf("DecodeKey(%q) succeeded", id)
+		}
+	}
+}
+
+// ── did:web ──────────────────────────────────────────────────────────────────
+
+func TestWebURL(t *testing.T) {
+	cases := map[string]string{
+		"did:web:example.com":            "https://example.com/.well-known/did.json",
+		"did:web:example.com:org:agents": "https://example.com/org/agents/did.json",
+		"did:web:localhost%3A8443":       "https://localhost:8443/.well-known/did.json",
+	}
+	for in, want := range cases {
+		if got, err := did.WebURL(in); err != nil || got != want {
+			t.Errorf("WebURL(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"did:web:example.com:..", "did:web:a%2Fb", "did:key:z6Mk"} {
+		if _, err := did.WebURL(bad); err == nil {
+			t.Errorf("WebURL(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestWebResolver_LocalFixture(t *testing.T) {
+	r := did.NewWebResolver(did.DirFetch("testdata"))
+	keys, err := did.ResolveKeys(r, "did:web:example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The X25519 method is skipped; the JWK is the fixture key.
+	if len(keys) != 1 || !keys[0].Equal(pub(seedKey(0x57))) {
+		t.Errorf("keys = %v", keys)
+	}
+
+	if _, err := r.Resolve("did:web:example.com:agents:payer"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("mismatched document id: err = %v", err)
+	}
+	if _, err := r.Resolve("did:web:unknown.example"); !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("missing document: err = %v", err)
+	}
+}
+
+func TestWebResolver_HTTPFetch(t *testing.T) {
+	pk := pub(seedKey(2))
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/did.json" {
+			http.NotFound(w, r)
+			return
+		}
+		id := "did:web:" + strings.ReplaceAll(strings.TrimPrefix(srv.URL, "https://"), ":", "%3A")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id": id,
+			"verificationMethod": []map[string]interface{}{{
+				"id": id + "#k", "type": did.TypeJsonWebKey2020, "controller": id,
+				"publicKeyJwk": map[string]string{"kty": "OKP", "crv": "Ed25519", "x": base64.RawURLEncoding.EncodeToString(pk)},
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "https://")
+	r := did.NewWebResolver(did.HTTPFetch(srv.Client()))
+	keys, err := did.ResolveKeys(r, "did:web:"+strings.ReplaceAll(host, ":", "%3A"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !keys[0].Equal(pk) {
+		t.Error("resolved key mismatch")
+	}
+	if _, err := r.Resolve("did:web:" + strings.ReplaceAll(host, ":", "%3A") + ":missing"); !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("404: err = %v", err)
+	}
+}
+
+func TestWebResolver_AllowHostsAndCache(t *testing.T) {
+	fetches := 0
+	fetch := func(u string) ([]byte, error) {
+		fetches++
+		return []byte(`{"id":"did:web:example.com"}`), nil
+	}
+	r := did.NewWebResolver(did.CachedFetch(did.AllowHosts(fetch, "Example.com"), time.Minute))
+	for i := 0; i < 2; i++ {
+		if _, err := r.Resolve("did:web:example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1 (cached)", fetches)
+	}
+	if _, err := r.Resolve("did:web:169.254.169.254"); !errors.Is(err, did.ErrHostNotAllowed) {
+		t.Errorf("host outside allowlist: err = %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("fetched a host outside the allowlist")
+	}
+
+	// Without a fetch function nothing is fetched.
+	if _, err := did.NewWebResolver(nil).Resolve("did:web:example.com"); !errors.Is(err, did.ErrHostNotAllowed) {
+		t.Errorf("default resolver: err = %v", err)
+	}
+}
+
+// ── did:acpd ─────────────────────────────────────────────────────────────────
+
+func TestACPDResolver_KeyHistory(t *testing.T) {
+	k1, k2 := seedKey(3), seedKey(4)
+	reg := registry.NewInMemoryRegistry()
+	if err := reg.RegisterFull(registry.AgentRecord{AgentID: "agent-1", PublicKey: pub(k1), RegisteredAt: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	st := registry.RotationStatement{
+		Ver: registry.RotationStatementVersion, AgentID: "agent-1", OldKID: keyring.DeriveKID(pub(k1)),
+		NewPublicKey: base64.RawURLEncoding.EncodeToString(pub(k2)), RotatedAt: 2000,
+	}
+	s1, _ := registry.SignRotation(st, k1)
+	s2, _ := registry.SignRotation(st, k2)
+	if _, err := reg.RotateKey(st, s1, s2); err != nil {
+		t.Fatal(err)
+	}
+
+	r := did.Multi{"acpd": &did.ACPDResolver{
+		Sources: []did.KeySource{reg},
+		Now:     func() time.Time { return time.Unix(3000, 0) },
+	}}
+	if keys, err := did.ResolveKeys(r, "did:acpd:agent-1"); err != nil || len(keys) != 1 || !keys[0].Equal(pub(k2)) {
+		t.Errorf("current keys = %v, %v", keys, err)
+	}
+	if keys, err := did.ResolveKeysAt(r, "did:acpd:agent-1", 1500); err != nil || len(keys) != 1 || !keys[0].Equal(pub(k1)) {
+		t.Errorf("keys at 1500 = %v, %v", keys, err)
+	}
+	if _, err := r.Resolve("did:acpd:unknown"); !errors.Is(err, did.ErrNotFound) {
+		t.Errorf("unknown agent: err = %v", err)
+	}
+	if _, err := r.Resolve("did:example:123"); !errors.Is(err, did.ErrUnsupportedMethod) {
+		t.Errorf("unsupported method: err = %v", err)
+	}
+}
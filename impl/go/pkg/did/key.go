@@ -0,0 +1,78 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"strings"
+
+	"github.com/mr-tron/base58"
+)
+
+// multicodecEd25519 is the multicodec prefix of an Ed25519 public key.
+var multicodecEd25519 = []byte{0xed, 0x01}
+
+// KeyResolver resolves did:key identifiers (Ed25519 only).
+// Format: did:key:z<base58btc(0xed 0x01 || 32-byte public key)>
+type KeyResolver struct{}
+
+// Resolve returns the single-key document encoded in did.
+func (KeyResolver) Resolve(did string) (*Document, error) {
+	pk, err := DecodeKey(did)
+	if err != nil {
+		return nil, err
+	}
+	mb := encodeMultibaseKey(pk)
+	id := did + "#" + mb
+	return &Document{
+		Context: []string{"https://www.w3.org/ns/did/v1"},
+		ID:      did,
+		VerificationMethod: []VerificationMethod{{
+			ID:                 id,
+			Type:               TypeEd25519VerificationKey2020,
+			Controller:         did,
+			PublicKeyMultibase: mb,
+		}},
+		AssertionMethod: []string{id},
+	}, nil
+}
+
+// EncodeKey returns the did:key identifier of pk.
+func EncodeKey(pk ed25519.PublicKey) string {
+	return "did:key:" + encodeMultibaseKey(pk)
+}
+
+// DecodeKey extracts the Ed25519 public key from a did:key identifier.
+func DecodeKey(did string) (ed25519.PublicKey, error) {
+	method, id, err := Parse(did)
+	if err != nil {
+		return nil, err
+	}
+	if method != "key" {
+		return nil, fmt.Errorf("%w: %q is not did:key", ErrUnsupportedMethod, did)
+	}
+	pk, err := decodeMultibaseKey(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDID, err)
+	}
+	return pk, nil
+}
+
+// encodeMultibaseKey returns z<base58btc(multicodec || pk)>.
+func encodeMultibaseKey(pk ed25519.PublicKey) string {
+	return "z" + base58.Encode(append(append([]byte(nil), multicodecEd25519...), pk...))
+}
+
+// decodeMultibaseKey parses a base58btc multibase Ed25519 key.
+func decodeMultibaseKey(s string) (ed25519.PublicKey, error) {
+	if !strings.HasPrefix(s, "z") {
+		return nil, fmt.Errorf("%w: multibase key must be base58btc ('z')", ErrNoEd25519Key)
+	}
+	decoded, err := base58.Decode(s[1:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: base58: %v", ErrNoEd25519Key, err)
+	}
+	if len(decoded) != 2+ed25519.PublicKeySize || decoded[0] != multicodecEd25519[0] || decoded[1] != multicodecEd25519[1] {
+		return nil, fmt.Errorf("%w: not an Ed25519 multikey", ErrNoEd25519Key)
+	}
+	return ed25519.PublicKey(decoded[2:]), nil
+}
@@ -0,0 +1,200 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDocumentSize bounds a fetched did:web document.
+const maxDocumentSize = 64 << 10
+
+// ErrHostNotAllowed is returned when a did:web document would be fetched from
+// a host outside the allowlist, or when fetching is disabled.
+var ErrHostNotAllowed = errors.New("acp/did: did:web host not allowed")
+
+// FetchFunc retrieves the DID document served at url.
+// It returns ErrNotFound if there is no document.
+type FetchFunc func(url string) ([]byte, error)
+
+// HTTPFetch returns a FetchFunc that GETs url with client (a client with a
+// 10 s timeout if nil).
+func HTTPFetch(client *http.Client) FetchFunc {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return func(u string) ([]byte, error) {
+		resp, err := client.Get(u)
+		if err != nil {
+			return nil, fmt.Errorf("acp/did: fetch %s: %w", u, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, u)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("acp/did: fetch %s: status %d", u, resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	}
+}
+
+// DirFetch returns a FetchFunc that serves https://<host>/<path> from
+// root/<host>/<path>, e.g. root/example.com/.well-known/did.json. A port in
+// the host is kept as "host:port" (or "host%3Aport" on file systems that
+// reject ':').
+func DirFetch(root string) FetchFunc {
+	return func(u string) ([]byte, error) {
+		parsed, err := url.Parse(u)
+		if err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidDID, u)
+		}
+		for _, host := range []string{parsed.Host, strings.ReplaceAll(parsed.Host, ":", "%3A")} {
+			p := filepath.Join(root, host, filepath.FromSlash(parsed.Path))
+			if !strings.HasPrefix(p, filepath.Clean(root)+string(filepath.Separator)) {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidDID, u)
+			}
+			b, err := os.ReadFile(p)
+			if err == nil {
+				return b, nil
+			}
+			if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, u)
+	}
+}
+
+// AllowHosts wraps fetch so that only documents on the given hosts (as in
+// the URL, "host" or "host:port") are fetched; other URLs fail with
+// ErrHostNotAllowed. With no hosts nothing is fetched.
+func AllowHosts(fetch FetchFunc, hosts ...string) FetchFunc {
+	allowed := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		allowed[strings.ToLower(h)] = true
+	}
+	return func(u string) ([]byte, error) {
+		parsed, err := url.Parse(u)
+		if err != nil || !allowed[strings.ToLower(parsed.Host)] {
+			return nil, fmt.Errorf("%w: %s", ErrHostNotAllowed, u)
+		}
+		return fetch(u)
+	}
+}
+
+// maxCachedDocuments bounds the entries held by CachedFetch.
+const maxCachedDocuments = 1024
+
+// CachedFetch wraps fetch with a cache of the documents fetched in the last
+// ttl. Errors are not cached.
+func CachedFetch(fetch FetchFunc, ttl time.Duration) FetchFunc {
+	type cached struct {
+		doc     []byte
+		expires time.Time
+	}
+	var mu sync.Mutex
+	cache := make(map[string]cached)
+	return func(u string) ([]byte, error) {
+		now := time.Now()
+		mu.Lock()
+		c, ok := cache[u]
+		mu.Unlock()
+		if ok && now.Before(c.expires) {
+			return c.doc, nil
+		}
+		doc, err := fetch(u)
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(cache) >= maxCachedDocuments {
+			for k, c := range cache {
+				if !now.Before(c.expires) {
+					delete(cache, k)
+				}
+			}
+		}
+		if len(cache) < maxCachedDocuments {
+			cache[u] = cached{doc: doc, expires: now.Add(ttl)}
+		}
+		return doc, nil
+	}
+}
+
+// WebResolver resolves did:web identifiers by fetching their document.
+type WebResolver struct {
+	Fetch FetchFunc
+}
+
+// NewWebResolver creates a did:web resolver. A nil fetch resolves nothing:
+// network fetching is opt-in (HTTPFetch, preferably under AllowHosts).
+func NewWebResolver(fetch FetchFunc) *WebResolver {
+	if fetch == nil {
+		fetch = AllowHosts(nil)
+	}
+	return &WebResolver{Fetch: fetch}
+}
+
+// Resolve fetches and parses the document of did. The document id MUST
+// equal did (did:web method §3.2).
+func (w *WebResolver) Resolve(did string) (*Document, error) {
+	u, err := WebURL(did)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := w.Fetch(u)
+	if err != nil {
+		return nil, err
+	}
+	var doc Document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("acp/did: %s: malformed document: %w", did, err)
+	}
+	if doc.ID != did {
+		return nil, fmt.Errorf("acp/did: document id %q does not match %q", doc.ID, did)
+	}
+	return &doc, nil
+}
+
+// WebURL maps a did:web identifier to the HTTPS URL of its document:
+//
+//	did:web:example.com            → https://example.com/.well-known/did.json
+//	did:web:example.com:org:agents → https://example.com/org/agents/did.json
+//	did:web:localhost%3A8443       → https://localhost:8443/.well-known/did.json
+func WebURL(did string) (string, error) {
+	method, id, err := Parse(did)
+	if err != nil {
+		return "", err
+	}
+	if method != "web" {
+		return "", fmt.Errorf("%w: %q is not did:web", ErrUnsupportedMethod, did)
+	}
+	segments := strings.Split(id, ":")
+	host, err := url.PathUnescape(segments[0])
+	if err != nil || host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", fmt.Errorf("%w: bad did:web host in %q", ErrInvalidDID, did)
+	}
+	path := "/.well-known"
+	if len(segments) > 1 {
+		parts := make([]string, 0, len(segments)-1)
+		for _, s := range segments[1:] {
+			p, err := url.PathUnescape(s)
+			if err != nil || p == "" || p == "." || p == ".." || strings.Contains(p, "/") {
+				return "", fmt.Errorf("%w: bad did:web path in %q", ErrInvalidDID, did)
+			}
+			parts = append(parts, p)
+		}
+		path = "/" + strings.Join(parts, "/")
+	}
+	return "https://" + host + path + "/did.json", nil
+}
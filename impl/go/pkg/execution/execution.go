@@ -85,21 +85,25 @@ type Token struct {
 	IssuedAt             int64  `json:"issued_at"`
 	ExpiresAt            int64  `json:"expires_at"`
 	Used                 bool   `json:"used"`
+	TargetSystem         string `json:"target_system,omitempty"` // bound consumer (system_id)
+	KID                  string `json:"kid,omitempty"`           // signing key ID (institution keyring)
 	Sig                  string `json:"sig,omitempty"`
 }
 
 // RegistryEntry is the server-side record in the ET Registry (ACP-EXEC-1.0 §9).
 type RegistryEntry struct {
-	ETID             string  `json:"et_id"`
-	AuthorizationID  string  `json:"authorization_id"`
-	AgentID          string  `json:"agent_id"`
-	Capability       string  `json:"capability"`
-	Resource         string  `json:"resource"`
-	IssuedAt         int64   `json:"issued_at"`
-	ExpiresAt        int64   `json:"expires_at"`
-	State            ETState `json:"state"`
-	ConsumedAt       *int64  `json:"consumed_at"`
-	ConsumedBySystem *string `json:"consumed_by_system"`
+	ETID                 string  `json:"et_id"`
+	AuthorizationID      string  `json:"authorization_id"`
+	AgentID              string  `json:"agent_id"`
+	Capability           string  `json:"capability"`
+	Resource             string  `json:"resource"`
+	ActionParametersHash string  `json:"action_parameters_hash"`
+	TargetSystem         string  `json:"target_system,omitempty"`
+	IssuedAt             int64   `json:"issued_at"`
+	ExpiresAt            int64   `json:"expires_at"`
+	State                ETState `json:"state"`
+	ConsumedAt           *int64  `json:"consumed_at"`
+	ConsumedBySystem     *string `json:"consumed_by_system"`
 }
 
 // IssueRequest holds the inputs for ET issuance from an APPROVED authorization.
@@ -109,15 +113,23 @@ type IssueRequest struct {
 	Capability       string
 	Resource         string
 	ActionParameters map[string]interface{}
+	// TargetSystem binds the ET to the one system allowed to consume it (optional).
+	TargetSystem string
+	// KeyID is the institution keyring kid of the signing key (optional).
+	KeyID string
 }
 
 // ConsumeRequest is the body for POST /acp/v1/exec-tokens/{et_id}/consume.
 // Sent by the target system to report consumption (ACP-EXEC-1.0 §9).
+// Sig is the system's signature over all other fields (SignConsumeRequest);
+// ActionParameters are the parameters actually executed.
 type ConsumeRequest struct {
-	ETID            string `json:"et_id"`
-	ConsumedAt      int64  `json:"consumed_at"`
-	ExecutionResult string `json:"execution_result"` // "success" | "failure" | "unknown"
-	Sig             string `json:"sig"`
+	ETID             string                 `json:"et_id"`
+	SystemID         string                 `json:"system_id"`
+	ConsumedAt       int64                  `json:"consumed_at"`
+	ExecutionResult  string                 `json:"execution_result"` // "success" | "failure" | "unknown"
+	ActionParameters map[string]interface{} `json:"action_parameters,omitempty"`
+	Sig              string                 `json:"sig"`
 }
 
 // ─── Issuance ─────────────────────────────────────────────────────────────────
@@ -156,6 +168,8 @@ func Issue(req IssueRequest, privKey ed25519.PrivateKey) (Token, error) {
 		IssuedAt:             now,
 		ExpiresAt:            now + int64(window.Seconds()),
 		Used:                 false,
+		TargetSystem:         req.TargetSystem,
+		KID:                  req.KeyID,
 	}
 
 	if privKey != nil {
@@ -193,14 +207,16 @@ func (r *InMemoryETRegistry) Register(tok Token) error {
 		return fmt.Errorf("%w: %s", ErrTokenAlreadyConsumed, tok.ETID)
 	}
 	r.entries[tok.ETID] = &RegistryEntry{
-		ETID:            tok.ETID,
-		AuthorizationID: tok.AuthorizationID,
-		AgentID:         tok.AgentID,
-		Capability:      tok.Capability,
-		Resource:        tok.Resource,
-		IssuedAt:        tok.IssuedAt,
-		ExpiresAt:       tok.ExpiresAt,
-		State:           StateIssued,
+		ETID:                 tok.ETID,
+		AuthorizationID:      tok.AuthorizationID,
+		AgentID:              tok.AgentID,
+		Capability:           tok.Capability,
+		Resource:             tok.Resource,
+		ActionParametersHash: tok.ActionParametersHash,
+		TargetSystem:         tok.TargetSystem,
+		IssuedAt:             tok.IssuedAt,
+		ExpiresAt:            tok.ExpiresAt,
+		State:                StateIssued,
 	}
 	return nil
 }
@@ -225,6 +241,7 @@ func (r *InMemoryETRegistry) Get(etID string) (RegistryEntry, error) {
 // Returns ErrTokenAlreadyConsumed if already USED.
 // Returns ErrTokenExpired if expires_at has passed.
 // Returns ErrTokenNotFound if et_id is unknown.
+// Returns ErrUnauthorizedConsumer if the ET is bound to another system.
 func (r *InMemoryETRegistry) Consume(etID, consumedBySystem string, consumedAt int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -232,6 +249,9 @@ func (r *InMemoryETRegistry) Consume(etID, consumedBySystem string, consumedAt i
 	if !ok {
 		return fmt.Errorf("%w: %s", ErrTokenNotFound, etID)
 	}
+	if entry.TargetSystem != "" && entry.TargetSystem != consumedBySystem {
+		return fmt.Errorf("%w: ET %s is bound to %s", ErrUnauthorizedConsumer, etID, entry.TargetSystem)
+	}
 	if entry.State == StateUsed {
 		return fmt.Errorf("%w: %s", ErrTokenAlreadyConsumed, etID)
 	}
@@ -245,6 +265,36 @@ func (r *InMemoryETRegistry) Consume(etID, consumedBySystem string, consumedAt i
 	return nil
 }
 
+// Withdraw removes an ET that was registered but never released to the agent,
+// e.g. because the decision issuing it could not be recorded in the audit
+// ledger. A withdrawn ET is unknown to Consume.
+func (r *InMemoryETRegistry) Withdraw(etID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[etID]; !ok {
+		return fmt.Errorf("%w: %s", ErrTokenNotFound, etID)
+	}
+	delete(r.entries, etID)
+	return nil
+}
+
+// RevertConsume returns a USED ET to ISSUED, undoing a Consume whose
+// consumption could not be recorded in the audit ledger.
+func (r *InMemoryETRegistry) RevertConsume(etID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.entries[etID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTokenNotFound, etID)
+	}
+	if entry.State == StateUsed {
+		entry.State = StateIssued
+		entry.ConsumedAt = nil
+		entry.ConsumedBySystem = nil
+	}
+	return nil
+}
+
 // Size returns the total number of tracked ETs.
 func (r *InMemoryETRegistry) Size() int {
 	r.mu.RLock()
@@ -290,6 +340,8 @@ type signableToken struct {
 	IssuedAt             int64  `json:"issued_at"`
 	ExpiresAt            int64  `json:"expires_at"`
 	Used                 bool   `json:"used"`
+	TargetSystem         string `json:"target_system,omitempty"`
+	KID                  string `json:"kid,omitempty"`
 }
 
 // signToken computes Ed25519(SHA-256(JCS(signable_fields))) per ACP-SIGN-1.0.
@@ -305,6 +357,8 @@ func signToken(tok Token, privKey ed25519.PrivateKey) (string, error) {
 		IssuedAt:             tok.IssuedAt,
 		ExpiresAt:            tok.ExpiresAt,
 		Used:                 tok.Used,
+		TargetSystem:         tok.TargetSystem,
+		KID:                  tok.KID,
 	}
 	raw, err := json.Marshal(s)
 	if err != nil {
@@ -339,6 +393,8 @@ func VerifyToken(tok Token, pubKey ed25519.PublicKey) error {
 		IssuedAt:             tok.IssuedAt,
 		ExpiresAt:            tok.ExpiresAt,
 		Used:                 tok.Used,
+		TargetSystem:         tok.TargetSystem,
+		KID:                  tok.KID,
 	}
 	raw, err := json.Marshal(s)
 	if err != nil {
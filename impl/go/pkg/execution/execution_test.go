@@ -347,6 +347,31 @@ func TestRegistry_ConsumeNotFound(t *testing.T) {
 	}
 }
 
+func TestRegistry_RevertConsumeAndWithdraw(t *testing.T) {
+	reg := execution.NewInMemoryETRegistry()
+	tok, _ := execution.Issue(issueReq("a1", "z1", "acp:cap:data.read", "r://x"), nil)
+	_ = reg.Register(tok)
+	_ = reg.Consume(tok.ETID, "sys", time.Now().Unix())
+
+	if err := reg.RevertConsume(tok.ETID); err != nil {
+		t.Fatalf("RevertConsume: %v", err)
+	}
+	entry, _ := reg.Get(tok.ETID)
+	if entry.State != execution.StateIssued || entry.ConsumedAt != nil || entry.ConsumedBySystem != nil {
+		t.Errorf("after revert: %+v", entry)
+	}
+	if err := reg.Consume(tok.ETID, "sys", time.Now().Unix()); err != nil {
+		t.Errorf("consume after revert: %v", err)
+	}
+
+	if err := reg.Withdraw(tok.ETID); err != nil {
+		t.Fatalf("Withdraw: %v", err)
+	}
+	if _, err := reg.Get(tok.ETID); err == nil {
+		t.Error("withdrawn ET still registered")
+	}
+}
+
 func TestRegistry_GetExpired(t *testing.T) {
 	reg := execution.NewInMemoryETRegistry()
 	// Manually craft a token that is already expired.
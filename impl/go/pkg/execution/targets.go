@@ -0,0 +1,237 @@
+package execution
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gowebpki/jcs"
+)
+
+// ─── Target Systems (ACP-EXEC-1.0 §8–§9) ─────────────────────────────────────
+//
+// A target system is the external system that executes an authorized action
+// and reports consumption of its ET. Only registered target systems may
+// consume ETs: the consume report is signed with the system's key, and the
+// system must be the exact consumer the ET was bound to at issuance.
+
+var (
+	// ErrTargetNotFound is returned when a system_id is not registered.
+	ErrTargetNotFound = errors.New("execution: target system not found")
+
+	// ErrTargetExists is returned when registering a duplicate system_id.
+	ErrTargetExists = errors.New("execution: target system already registered")
+
+	// ErrInvalidTarget is returned when a registration is incomplete.
+	ErrInvalidTarget = errors.New("execution: invalid target system")
+
+	// ErrTargetAmbiguous is returned by Resolve when several registered
+	// systems are authorized, so the consumer must be named explicitly.
+	ErrTargetAmbiguous = errors.New("execution: several target systems authorized, target_system required")
+)
+
+// TargetSystem is a registered ET consumer.
+//
+// AllowedCapabilities entries match exactly or by trailing "*" prefix
+// ("acp:cap:financial.*"). AllowedResources entries match exactly or as a
+// path prefix ("org.example/accounts" covers "org.example/accounts/ACC-001").
+type TargetSystem struct {
+	SystemID            string            `json:"system_id"`
+	PublicKey           ed25519.PublicKey `json:"-"`
+	AllowedCapabilities []string          `json:"allowed_capabilities"`
+	AllowedResources    []string          `json:"allowed_resources"`
+	RegisteredAt        int64             `json:"registered_at"`
+}
+
+// Authorizes reports whether the system may consume ETs for capability on resource.
+func (t TargetSystem) Authorizes(capability, resource string) bool {
+	capOK := false
+	for _, c := range t.AllowedCapabilities {
+		if prefix := strings.TrimSuffix(c, "*"); prefix != c {
+			capOK = strings.HasPrefix(capability, prefix)
+		} else {
+			capOK = capability == c
+		}
+		if capOK {
+			break
+		}
+	}
+	if !capOK {
+		return false
+	}
+	for _, r := range t.AllowedResources {
+		if resource == r || r == "*" ||
+			(strings.HasPrefix(resource, r) && len(resource) > len(r) && resource[len(r)] == '/') {
+			return true
+		}
+	}
+	return false
+}
+
+// InMemoryTargetRegistry is a thread-safe in-memory registry of target systems.
+type InMemoryTargetRegistry struct {
+	mu      sync.RWMutex
+	systems map[string]TargetSystem
+}
+
+// NewInMemoryTargetRegistry creates an empty target system registry.
+func NewInMemoryTargetRegistry() *InMemoryTargetRegistry {
+	return &InMemoryTargetRegistry{systems: make(map[string]TargetSystem)}
+}
+
+// Register adds a target system. RegisteredAt is set if zero.
+// Returns ErrInvalidTarget if system_id, key or allow-lists are missing and
+// ErrTargetExists if system_id is already registered.
+func (r *InMemoryTargetRegistry) Register(t TargetSystem) error {
+	if t.SystemID == "" || len(t.PublicKey) != ed25519.PublicKeySize ||
+		len(t.AllowedCapabilities) == 0 || len(t.AllowedResources) == 0 {
+		return fmt.Errorf("%w: system_id, 32-byte public_key, allowed_capabilities and allowed_resources are required", ErrInvalidTarget)
+	}
+	if t.RegisteredAt == 0 {
+		t.RegisteredAt = time.Now().Unix()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.systems[t.SystemID]; exists {
+		return fmt.Errorf("%w: %s", ErrTargetExists, t.SystemID)
+	}
+	r.systems[t.SystemID] = t
+	return nil
+}
+
+// Get returns the target system registered under systemID.
+func (r *InMemoryTargetRegistry) Get(systemID string) (TargetSystem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.systems[systemID]
+	if !ok {
+		return TargetSystem{}, fmt.Errorf("%w: %s", ErrTargetNotFound, systemID)
+	}
+	return t, nil
+}
+
+// Resolve returns the only registered system authorized for capability on
+// resource, or "" when none is. It returns ErrTargetAmbiguous when several
+// are: the ET is never issued unbound for a consumer that exists.
+func (r *InMemoryTargetRegistry) Resolve(capability, resource string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var ids []string
+	for id, t := range r.systems {
+		if t.Authorizes(capability, resource) {
+			ids = append(ids, id)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", nil
+	case 1:
+		return ids[0], nil
+	}
+	sort.Strings(ids)
+	return "", fmt.Errorf("%w: %s", ErrTargetAmbiguous, strings.Join(ids, ", "))
+}
+
+// Size returns the number of registered target systems.
+func (r *InMemoryTargetRegistry) Size() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.systems)
+}
+
+// List returns all registered target systems ordered by system_id.
+func (r *InMemoryTargetRegistry) List() []TargetSystem {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]TargetSystem, 0, len(r.systems))
+	for _, t := range r.systems {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].SystemID < out[j].SystemID })
+	return out
+}
+
+// ─── Consumer Authorization ───────────────────────────────────────────────────
+
+// AuthorizeConsumer checks that target may consume the ET in entry: the ET
+// must be bound to target. An unbound ET was issued while no registered
+// system was authorized for it, and systems registered later cannot consume
+// it. Returns ErrUnauthorizedConsumer.
+func AuthorizeConsumer(entry RegistryEntry, target TargetSystem) error {
+	if entry.TargetSystem == "" {
+		return fmt.Errorf("%w: ET %s is not bound to a target system", ErrUnauthorizedConsumer, entry.ETID)
+	}
+	if entry.TargetSystem != target.SystemID {
+		return fmt.Errorf("%w: ET %s is bound to %s", ErrUnauthorizedConsumer, entry.ETID, entry.TargetSystem)
+	}
+	return nil
+}
+
+// CheckActionParameters verifies that the executed parameters hash to the
+// ET's action_parameters_hash (ACP-EXEC-1.0 §8 step 8).
+// Returns ErrParamsHashMismatch on mismatch.
+func CheckActionParameters(entry RegistryEntry, executed map[string]interface{}) error {
+	h, err := HashActionParameters(executed)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrParamsHashMismatch, err)
+	}
+	if h != entry.ActionParametersHash {
+		return fmt.Errorf("%w: %s", ErrParamsHashMismatch, entry.ETID)
+	}
+	return nil
+}
+
+// ─── Consume Request Signing ──────────────────────────────────────────────────
+
+// consumeSignable returns the JCS canonical form of req without sig.
+func consumeSignable(req ConsumeRequest) ([]byte, error) {
+	req.Sig = ""
+	raw, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("execution: marshal consume request: %w", err)
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return nil, fmt.Errorf("execution: jcs consume request: %w", err)
+	}
+	return canonical, nil
+}
+
+// SignConsumeRequest signs a consume report with the target system's key:
+// Ed25519(SHA-256(JCS(request without sig))) per ACP-SIGN-1.0.
+func SignConsumeRequest(req ConsumeRequest, privKey ed25519.PrivateKey) (string, error) {
+	canonical, err := consumeSignable(req)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(ed25519.Sign(privKey, digest[:])), nil
+}
+
+// VerifyConsumeRequest verifies req.Sig against the target system's key.
+// Returns ErrUnauthorizedConsumer if the signature is absent or invalid.
+func VerifyConsumeRequest(req ConsumeRequest, pubKey ed25519.PublicKey) error {
+	if req.Sig == "" {
+		return fmt.Errorf("%w: consume request is unsigned", ErrUnauthorizedConsumer)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(req.Sig)
+	if err != nil {
+		return fmt.Errorf("%w: decode sig: %v", ErrUnauthorizedConsumer, err)
+	}
+	canonical, err := consumeSignable(req)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(canonical)
+	if !ed25519.Verify(pubKey, digest[:], sig) {
+		return fmt.Errorf("%w: invalid consume request signature", ErrUnauthorizedConsumer)
+	}
+	return nil
+}
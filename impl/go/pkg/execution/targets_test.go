@@ -0,0 +1,141 @@
+package execution_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/execution"
+)
+
+func newTarget(t *testing.T, id string, caps, res []string) execution.TargetSystem {
+	t.Helper()
+	pub, _ := mustGenKey(t)
+	return execution.TargetSystem{SystemID: id, PublicKey: pub, AllowedCapabilities: caps, AllowedResources: res}
+}
+
+// ─── TargetSystem ─────────────────────────────────────────────────────────────
+
+func TestTargetSystem_Authorizes(t *testing.T) {
+	ts := execution.TargetSystem{
+		AllowedCapabilities: []string{"acp:cap:financial.*", "acp:cap:data.read"},
+		AllowedResources:    []string{"org.bank/accounts"},
+	}
+	cases := []struct {
+		cap, res string
+		want     bool
+	}{
+		{"acp:cap:financial.payment", "org.bank/accounts/ACC-1", true},
+		{"acp:cap:data.read", "org.bank/accounts", true},
+		{"acp:cap:data.write", "org.bank/accounts", false},
+		{"acp:cap:financial.payment", "org.bank/accountsX", false},
+		{"acp:cap:financial.payment", "org.other/accounts", false},
+	}
+	for _, c := range cases {
+		if got := ts.Authorizes(c.cap, c.res); got != c.want {
+			t.Errorf("Authorizes(%s, %s) = %v, want %v", c.cap, c.res, got, c.want)
+		}
+	}
+}
+
+// ─── InMemoryTargetRegistry ───────────────────────────────────────────────────
+
+func TestTargetRegistry_RegisterValidation(t *testing.T) {
+	reg := execution.NewInMemoryTargetRegistry()
+	if err := reg.Register(execution.TargetSystem{SystemID: "s"}); !errors.Is(err, execution.ErrInvalidTarget) {
+		t.Errorf("incomplete: err = %v, want ErrInvalidTarget", err)
+	}
+	ts := newTarget(t, "s", []string{"*"}, []string{"*"})
+	if err := reg.Register(ts); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := reg.Register(ts); !errors.Is(err, execution.ErrTargetExists) {
+		t.Errorf("duplicate: err = %v, want ErrTargetExists", err)
+	}
+	if _, err := reg.Get("missing"); !errors.Is(err, execution.ErrTargetNotFound) {
+		t.Errorf("Get(missing): err = %v, want ErrTargetNotFound", err)
+	}
+}
+
+func TestTargetRegistry_Resolve(t *testing.T) {
+	reg := execution.NewInMemoryTargetRegistry()
+	_ = reg.Register(newTarget(t, "pay", []string{"acp:cap:financial.*"}, []string{"org.bank"}))
+	if got, err := reg.Resolve("acp:cap:financial.payment", "org.bank/a"); got != "pay" || err != nil {
+		t.Errorf("Resolve = %q, %v, want pay", got, err)
+	}
+	if got, err := reg.Resolve("acp:cap:data.read", "org.bank/a"); got != "" || err != nil {
+		t.Errorf("Resolve(no match) = %q, %v, want empty", got, err)
+	}
+	_ = reg.Register(newTarget(t, "pay2", []string{"acp:cap:financial.payment"}, []string{"org.bank"}))
+	if got, err := reg.Resolve("acp:cap:financial.payment", "org.bank/a"); got != "" || !errors.Is(err, execution.ErrTargetAmbiguous) {
+		t.Errorf("Resolve(ambiguous) = %q, %v, want ErrTargetAmbiguous", got, err)
+	}
+}
+
+// ─── Consumer authorization ───────────────────────────────────────────────────
+
+func TestRegistry_ConsumeBoundToOtherSystem(t *testing.T) {
+	reg := execution.NewInMemoryETRegistry()
+	req := issueReq("a1", "z1", "acp:cap:data.read", "r://x")
+	req.TargetSystem = "system-A"
+	tok, _ := execution.Issue(req, nil)
+	_ = reg.Register(tok)
+
+	err := reg.Consume(tok.ETID, "system-B", time.Now().Unix())
+	if !errors.Is(err, execution.ErrUnauthorizedConsumer) {
+		t.Fatalf("err = %v, want ErrUnauthorizedConsumer", err)
+	}
+	if entry, _ := reg.Get(tok.ETID); entry.State != execution.StateIssued {
+		t.Errorf("state=%q after rejected consume, want issued", entry.State)
+	}
+	if err := reg.Consume(tok.ETID, "system-A", time.Now().Unix()); err != nil {
+		t.Errorf("bound system: %v", err)
+	}
+}
+
+func TestAuthorizeConsumer_Unbound(t *testing.T) {
+	entry := execution.RegistryEntry{ETID: "et", Capability: "acp:cap:data.read", Resource: "r/x"}
+	err := execution.AuthorizeConsumer(entry, newTarget(t, "ok", []string{"acp:cap:data.*"}, []string{"r"}))
+	if !errors.Is(err, execution.ErrUnauthorizedConsumer) {
+		t.Errorf("unbound ET: err = %v, want ErrUnauthorizedConsumer", err)
+	}
+	entry.TargetSystem = "ok"
+	if err := execution.AuthorizeConsumer(entry, newTarget(t, "ok", []string{"acp:cap:data.*"}, []string{"r"})); err != nil {
+		t.Errorf("bound system: %v", err)
+	}
+}
+
+func TestCheckActionParameters(t *testing.T) {
+	tok, _ := execution.Issue(issueReq("a1", "z1", "acp:cap:data.read", "r://x"), nil)
+	entry := execution.RegistryEntry{ETID: tok.ETID, ActionParametersHash: tok.ActionParametersHash}
+	if err := execution.CheckActionParameters(entry, map[string]interface{}{"amount": 100.0}); err != nil {
+		t.Errorf("same params: %v", err)
+	}
+	err := execution.CheckActionParameters(entry, map[string]interface{}{"amount": 101.0})
+	if !errors.Is(err, execution.ErrParamsHashMismatch) {
+		t.Errorf("changed params: err = %v, want ErrParamsHashMismatch", err)
+	}
+}
+
+// ─── Consume request signing ──────────────────────────────────────────────────
+
+func TestConsumeRequest_SignVerify(t *testing.T) {
+	pub, priv := mustGenKey(t)
+	otherPub, _ := mustGenKey(t)
+	req := execution.ConsumeRequest{ETID: "et-1", SystemID: "sys", ConsumedAt: 1000, ExecutionResult: "success"}
+	sig, err := execution.SignConsumeRequest(req, priv)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	req.Sig = sig
+	if err := execution.VerifyConsumeRequest(req, pub); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := execution.VerifyConsumeRequest(req, otherPub); !errors.Is(err, execution.ErrUnauthorizedConsumer) {
+		t.Errorf("wrong key: err = %v, want ErrUnauthorizedConsumer", err)
+	}
+	req.ExecutionResult = "failure"
+	if err := execution.VerifyConsumeRequest(req, pub); !errors.Is(err, execution.ErrUnauthorizedConsumer) {
+		t.Errorf("tampered: err = %v, want ErrUnauthorizedConsumer", err)
+	}
+}
@@ -38,6 +38,17 @@ func VerifyProofOfPossession(
 	r *http.Request,
 	store *ChallengeStore,
 	agentPubKey ed25519.PublicKey,
+) error {
+	return VerifyProofOfPossessionKeys(r, store, []ed25519.PublicKey{agentPubKey})
+}
+
+// VerifyProofOfPossessionKeys is VerifyProofOfPossession for an agent with
+// several valid keys (e.g. during a key-rotation overlap): the PoP is
+// accepted if any of agentPubKeys verifies it. The challenge is consumed once.
+func VerifyProofOfPossessionKeys(
+	r *http.Request,
+	store *ChallengeStore,
+	agentPubKeys []ed25519.PublicKey,
 ) error {
 	// Extract required headers.
 	challenge := r.Header.Get(HeaderChallenge)
@@ -72,10 +83,12 @@ func VerifyProofOfPossession(
 
 	// Verify Ed25519 over SHA-256(signed_payload).
 	hash := sha256.Sum256([]byte(signedPayload))
-	if !ed25519.Verify(agentPubKey, hash[:], sigBytes) {
-		return ErrPoPInvalidSignature
+	for _, pk := range agentPubKeys {
+		if ed25519.Verify(pk, hash[:], sigBytes) {
+			return nil
+		}
 	}
-	return nil
+	return ErrPoPInvalidSignature
 }
 
 // BuildPoPPayload constructs the string that must be signed by the agent.
@@ -0,0 +1,41 @@
+package handshake_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/handshake"
+)
+
+func TestVerifyProofOfPossessionKeys_AnyValidKey(t *testing.T) {
+	_, oldPriv, _ := ed25519.GenerateKey(nil)
+	newPub, newPriv, _ := ed25519.GenerateKey(nil)
+	oldPub := oldPriv.Public().(ed25519.PublicKey)
+	store := handshake.NewChallengeStore()
+
+	sign := func(priv ed25519.PrivateKey) error {
+		challenge, _ := store.GenerateChallenge()
+		body := []byte(`{"x":1}`)
+		h := sha256.Sum256([]byte(handshake.BuildPoPPayload("POST", "/acp/v1/verify", challenge, body)))
+		r := httptest.NewRequest("POST", "/acp/v1/verify", bytes.NewReader(body))
+		r.Header.Set(handshake.HeaderChallenge, challenge)
+		r.Header.Set(handshake.HeaderSignature, base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, h[:])))
+		return handshake.VerifyProofOfPossessionKeys(r, store, []ed25519.PublicKey{newPub, oldPub})
+	}
+
+	if err := sign(oldPriv); err != nil {
+		t.Errorf("old key during overlap: %v", err)
+	}
+	if err := sign(newPriv); err != nil {
+		t.Errorf("new key: %v", err)
+	}
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+	if err := sign(otherPriv); !errors.Is(err, handshake.ErrPoPInvalidSignature) {
+		t.Errorf("unrelated key: err = %v", err)
+	}
+}
@@ -41,6 +41,8 @@ var (
 	ErrExportTTLOutOfRange = errors.New("HIST-E021: ttl_seconds out of range")
 	ErrExportEmptyScope    = errors.New("HIST-E023: scope produces zero events — empty bundle not allowed")
 	ErrExportSignFailed    = errors.New("HIST-E024: error signing institutional bundle")
+	ErrBundleSigInvalid    = errors.New("HIST-E025: bundle signature verification failed")
+	ErrBundleEventInvalid  = errors.New("HIST-E026: bundle event failed verification")
 )
 
 // ─── Query Types (ACP-HIST-1.0 §4) ───────────────────────────────────────────
@@ -157,6 +159,7 @@ type ExportRequest struct {
 	Format        string // "full" | "hashes_only"
 	IncludeAnchor bool
 	TTLSeconds    int64
+	KeyID         string // institution keyring kid of privKey (optional)
 }
 
 // AnchorEvent is the anchor for chain verification without the full ledger (§7).
@@ -180,6 +183,7 @@ type ExportBundle struct {
 	Events      []ledger.Event `json:"events"`
 	EventCount  int            `json:"event_count"`
 	ChainValid  bool           `json:"chain_valid"`
+	KID         string         `json:"kid,omitempty"` // signing key ID (institution keyring)
 	BundleHash  string         `json:"bundle_hash"`
 	BundleSig   string         `json:"bundle_sig"`
 }
@@ -197,6 +201,7 @@ type signableBundle struct {
 	Events      []ledger.Event `json:"events"`
 	EventCount  int            `json:"event_count"`
 	ChainValid  bool           `json:"chain_valid"`
+	KID         string         `json:"kid,omitempty"`
 	BundleHash  string         `json:"bundle_hash"`
 	BundleSig   string         `json:"bundle_sig"` // always "" when signing
 }
@@ -235,8 +240,13 @@ func Query(l *ledger.InMemoryLedger, institutionID string, f QueryFilter) (Query
 		typeSet[t] = struct{}{}
 	}
 
-	// Pull all events and apply filters.
-	all := l.List(1, 0)
+	// Pull the events in range and apply filters. Narrowing the range first
+	// keeps archived segments the query cannot reach from being read.
+	from := max(startSeq+1, f.FromSeq)
+	if f.FromTS > 0 {
+		from = max(from, l.SequenceSince(f.FromTS))
+	}
+	all := revealAll(l, l.List(from, f.ToSeq))
 	var filtered []ledger.Event
 	for _, ev := range all {
 		if startSeq > 0 && ev.Sequence <= startSeq {
@@ -318,7 +328,7 @@ func GetEvent(l *ledger.InMemoryLedger, eventID string) (EventResponse, error) {
 	}
 	resp := EventResponse{
 		Ver:   "1.0",
-		Event: ev,
+		Event: l.Reveal(ev),
 	}
 	resp.Integrity.HashValid = hashValid
 	resp.Integrity.SigValid = sigValid
@@ -345,7 +355,7 @@ func AgentHistory(l *ledger.InMemoryLedger, institutionID, agentID string, f Age
 	}
 
 	startSeq, _ := decodeCursor(f.Cursor)
-	all := l.List(1, 0)
+	all := revealAll(l, l.List(1, 0))
 
 	var agentEvents []ledger.Event
 	var summary AgentSummary
@@ -433,7 +443,8 @@ func Export(l *ledger.InMemoryLedger, institutionID string, req ExportRequest, p
 		typeSet[t] = struct{}{}
 	}
 
-	all := l.List(1, 0)
+	// Start one event before the range, for the anchor.
+	all := l.List(l.SequenceSince(req.Scope.FromTS)-1, 0)
 	var events []ledger.Event
 	var anchorEvent *AnchorEvent
 
@@ -452,7 +463,9 @@ func Export(l *ledger.InMemoryLedger, institutionID string, req ExportRequest, p
 		if ev.Timestamp < req.Scope.FromTS || ev.Timestamp > req.Scope.ToTS {
 			continue
 		}
-		if req.Scope.AgentID != "" && !eventMatchesAgent(ev, req.Scope.AgentID) {
+		// The bundle carries the stored events, sealed fields included, so
+		// that it verifies offline; only the agent filter sees the cleartext.
+		if req.Scope.AgentID != "" && !eventMatchesAgent(l.Reveal(ev), req.Scope.AgentID) {
 			continue
 		}
 		if len(typeSet) > 0 {
@@ -501,6 +514,7 @@ func Export(l *ledger.InMemoryLedger, institutionID string, req ExportRequest, p
 	}
 
 	if privKey != nil {
+		bundle.KID = req.KeyID
 		bundleHash, sig, err := signBundle(bundle, privKey)
 		if err != nil {
 			return ExportBundle{}, ErrExportSignFailed
@@ -512,6 +526,50 @@ func Export(l *ledger.InMemoryLedger, institutionID string, req ExportRequest, p
 	return bundle, nil
 }
 
+// VerifyBundle verifies an ExportBundle offline (§7).
+//
+// keys resolves the institutional key by kid and timestamp, so bundles and
+// events signed before a key rotation verify against the key that was active
+// at the time (e.g. a keyring.PublishedKeyring built from /.well-known/acp-keys).
+//
+// Checks, in order:
+//  1. bundle_sig with the key for (bundle.kid, bundle.issued_at)
+//  2. for format "full": every event's hash and signature with the key for
+//     (event.kid, event.timestamp)
+//
+// Returns ErrBundleSigInvalid or ErrBundleEventInvalid on failure.
+func VerifyBundle(b ExportBundle, keys ledger.KeyResolver) error {
+	pub, err := keys.KeyAt(b.KID, b.IssuedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBundleSigInvalid, err)
+	}
+	digest, err := bundleDigest(b)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrBundleSigInvalid, err)
+	}
+	if b.BundleHash != base64.URLEncoding.EncodeToString(digest[:]) {
+		return fmt.Errorf("%w: bundle_hash mismatch", ErrBundleSigInvalid)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(b.BundleSig)
+	if err != nil || !ed25519.Verify(pub, digest[:], sig) {
+		return ErrBundleSigInvalid
+	}
+
+	if b.Format == "hashes_only" {
+		return nil
+	}
+	// Events in a bundle are a filtered subset, so chain linkage is not
+	// checked here — only each event's own hash and signature.
+	for _, ev := range b.Events {
+		for _, e := range ledger.CheckEvent(ev, nil, keys) {
+			if e.Code == "LEDGER-002" || e.Code == "LEDGER-003" || e.Code == "LEDGER-012" {
+				return fmt.Errorf("%w: %s", ErrBundleEventInvalid, e.Error())
+			}
+		}
+	}
+	return nil
+}
+
 // ─── Internal helpers ─────────────────────────────────────────────────────────
 
 // cursorPayload is the internal structure of the opaque cursor.
@@ -548,6 +606,16 @@ func decodeCursor(cursor string) (int64, error) {
 	return p.Seq, nil
 }
 
+// revealAll returns events with their sealed payload fields revealed
+// (ledger.Reveal): queries filter and return the cleartext, or
+// ledger.Redacted once the subject has been shredded.
+func revealAll(l *ledger.InMemoryLedger, events []ledger.Event) []ledger.Event {
+	for i := range events {
+		events[i] = l.Reveal(events[i])
+	}
+	return events
+}
+
 // buildIntegrity computes the Integrity struct for a query response.
 func buildIntegrity(events []ledger.Event, verifyChain bool, l *ledger.InMemoryLedger) Integrity {
 	integ := Integrity{ChainValid: nil}
@@ -651,6 +719,17 @@ func updateSummary(s *AgentSummary, ev ledger.Event) {
 // signBundle signs the bundle and returns (bundleHash, bundleSig).
 // bundle_sig = base64url(Ed25519(SHA-256(JCS(bundle without bundle_sig)))).
 func signBundle(b ExportBundle, privKey ed25519.PrivateKey) (string, string, error) {
+	digest, err := bundleDigest(b)
+	if err != nil {
+		return "", "", err
+	}
+	bundleHash := base64.URLEncoding.EncodeToString(digest[:])
+	sig := ed25519.Sign(privKey, digest[:])
+	return bundleHash, base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// bundleDigest computes SHA-256(JCS(bundle without bundle_hash and bundle_sig)).
+func bundleDigest(b ExportBundle) ([32]byte, error) {
 	s := signableBundle{
 		Ver:         b.Ver,
 		BundleID:    b.BundleID,
@@ -663,21 +742,19 @@ func signBundle(b ExportBundle, privKey ed25519.PrivateKey) (string, string, err
 		Events:      b.Events,
 		EventCount:  b.EventCount,
 		ChainValid:  b.ChainValid,
+		KID:         b.KID,
 		BundleHash:  "",
 		BundleSig:   "",
 	}
 	raw, err := json.Marshal(s)
 	if err != nil {
-		return "", "", fmt.Errorf("marshal: %w", err)
+		return [32]byte{}, fmt.Errorf("marshal: %w", err)
 	}
 	canonical, err := jcs.Transform(raw)
 	if err != nil {
-		return "", "", fmt.Errorf("jcs: %w", err)
+		return [32]byte{}, fmt.Errorf("jcs: %w", err)
 	}
-	digest := sha256.Sum256(canonical)
-	bundleHash := base64.URLEncoding.EncodeToString(digest[:])
-	sig := ed25519.Sign(privKey, digest[:])
-	return bundleHash, base64.RawURLEncoding.EncodeToString(sig), nil
+	return sha256.Sum256(canonical), nil
 }
 
 // ─── UUID helper ──────────────────────────────────────────────────────────────
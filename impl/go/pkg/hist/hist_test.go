@@ -0,0 +1,146 @@
+package hist
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/keyring"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+)
+
+func testKey(seed byte) ed25519.PrivateKey {
+	s := make([]byte, ed25519.SeedSize)
+	s[0] = seed
+	return ed25519.NewKeyFromSeed(s)
+}
+
+// rotatedLedger returns a ledger whose first events are signed by the initial
+// institution key and the rest by its successor.
+func rotatedLedger(t *testing.T) (*ledger.InMemoryLedger, *keyring.Keyring) {
+	t.Helper()
+	oldPriv, newPriv := testKey(1), testKey(2)
+	keys, err := keyring.New("org.test", "", oldPriv.Public().(ed25519.PublicKey), oldPriv, 0)
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	kid, _ := keys.Active()
+	l, err := ledger.NewInMemoryLedgerWithKeyID("org.test", kid, oldPriv)
+	if err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	l.SetKeyResolver(keys)
+
+	appendShred := func(keyID string) {
+		t.Helper()
+		if _, err := l.Append(ledger.EventSubjectShredded, ledger.SubjectShreddedPayload{
+			KeyID: keyID, SealedValues: 1, RequestedBy: "dpo@example.org",
+		}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	appendShred("before-rotation")
+	if err := l.RotateSigningKey(func() (string, ed25519.PrivateKey, error) {
+		nk, err := keys.Rotate(newPriv, time.Now().Unix(), 3600, "endorsement")
+		return nk.KID, newPriv, err
+	}); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	appendShred("after-rotation")
+	return l, keys
+}
+
+func exportAll(t *testing.T, l *ledger.InMemoryLedger, keys *keyring.Keyring) ExportBundle {
+	t.Helper()
+	kid, priv := keys.Active()
+	now := time.Now().Unix()
+	b, err := Export(l, "org.test", ExportRequest{
+		Scope:  ExportScope{FromTS: now - 60, ToTS: now + 60},
+		Format: "full",
+		KeyID:  kid,
+	}, priv)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	return b
+}
+
+func TestVerifyBundle_KeyIDs(t *testing.T) {
+	l, keys := rotatedLedger(t)
+	b := exportAll(t, l, keys)
+
+	kids := map[string]bool{}
+	for _, ev := range b.Events {
+		kids[ev.KID] = true
+	}
+	if len(kids) != 2 || b.KID == "" {
+		t.Fatalf("bundle kid %q, event kids %v: want events under both keys", b.KID, kids)
+	}
+
+	// Verifies with the institution keyring and with its published form.
+	if err := VerifyBundle(b, keys); err != nil {
+		t.Errorf("VerifyBundle(keyring): %v", err)
+	}
+	published, err := keyring.ParseDocument(keys.Document())
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if err := VerifyBundle(b, published); err != nil {
+		t.Errorf("VerifyBundle(published): %v", err)
+	}
+
+	// The bundle signature is checked with the key named by its kid.
+	forged := b
+	forged.KID = "unknown-kid"
+	if err := VerifyBundle(forged, keys); !errors.Is(err, ErrBundleSigInvalid) {
+		t.Errorf("unknown bundle kid err = %v", err)
+	}
+
+	// Each event is checked with the key named by its own kid.
+	swapped := b
+	swapped.Events = append([]ledger.Event(nil), b.Events...)
+	swapped.Events[0].KID = b.KID
+	if err := VerifyBundle(resign(t, swapped, keys), keys); !errors.Is(err, ErrBundleEventInvalid) {
+		t.Errorf("event kid swapped err = %v", err)
+	}
+}
+
+func TestVerifyBundle_Legacy(t *testing.T) {
+	priv := testKey(3)
+	keys, err := keyring.New("org.test", "", priv.Public().(ed25519.PublicKey), priv, 0)
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	// Pre-keyring ledger and bundle: no kids, resolved by timestamp.
+	l, err := ledger.NewInMemoryLedger("org.test", priv)
+	if err != nil {
+		t.Fatalf("ledger: %v", err)
+	}
+	now := time.Now().Unix()
+	b, err := Export(l, "org.test", ExportRequest{
+		Scope:  ExportScope{FromTS: now - 60, ToTS: now + 60},
+		Format: "full",
+	}, priv)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if b.KID != "" {
+		t.Fatalf("legacy bundle kid = %q", b.KID)
+	}
+	if err := VerifyBundle(b, keys); err != nil {
+		t.Errorf("VerifyBundle: %v", err)
+	}
+}
+
+// resign signs b again, so that only the event checks can fail.
+func resign(t *testing.T, b ExportBundle, keys *keyring.Keyring) ExportBundle {
+	t.Helper()
+	_, priv := keys.Active()
+	hash, sig, err := signBundle(b, priv)
+	if err != nil {
+		t.Fatalf("signBundle: %v", err)
+	}
+	b.BundleHash, b.BundleSig = hash, sig
+	return b
+}
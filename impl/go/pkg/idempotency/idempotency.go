@@ -0,0 +1,161 @@
+// Package idempotency de-duplicates requests by their client-chosen
+// request_id (ACP-API-1.0 AUTH-004).
+//
+// A Store remembers, for a TTL, the fingerprint of every request ID it has
+// seen and the response that request got. A retry carrying the same ID and
+// the same body is answered with the stored response instead of being
+// processed again, so a client retrying after a timeout cannot obtain a
+// second decision; the same ID with a different body is a conflict.
+//
+// Request IDs are chosen by clients, so the caller scopes them to the client
+// with ScopedID: a client cannot collide with, or replay, another client's
+// requests.
+//
+// The caller reserves an ID with Begin before processing the request, then
+// either stores the response with Complete or releases the ID with Abort
+// (e.g. on an internal error, so that the retry is processed normally).
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gowebpki/jcs"
+)
+
+// DefaultTTL is how long a request ID is remembered: the ACP-API-1.0
+// duplicate detection window.
+const DefaultTTL = 5 * time.Minute
+
+// ErrDuplicateRequest is returned by Begin when the request ID cannot be
+// used: it was seen with a different body, or its first request is still
+// being processed.
+var ErrDuplicateRequest = errors.New("AUTH-004: duplicate request_id")
+
+// Record is the outcome of a completed request.
+type Record struct {
+	Status int    // HTTP status of the response
+	Body   []byte // response body, replayed verbatim
+
+	// The ledger event that recorded the original decision, referenced by
+	// the event recording a replay.
+	Decision       string
+	LedgerEventID  string
+	LedgerSequence int64
+}
+
+type entry struct {
+	fingerprint string
+	expires     time.Time
+	done        bool
+	rec         Record
+}
+
+// Store remembers request IDs for a TTL. Thread-safe.
+type Store struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// NewStore returns an empty store; ttl ≤ 0 means DefaultTTL.
+func NewStore(ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Store{ttl: ttl, entries: make(map[string]*entry)}
+}
+
+// TTL returns how long request IDs are remembered.
+func (s *Store) TTL() time.Duration { return s.ttl }
+
+// Begin reserves id for a request with the given fingerprint. It returns
+// replay=true and the stored Record when the same request already completed,
+// ErrDuplicateRequest when id is taken by a different request or by one
+// still in progress, and otherwise reserves id until Complete or Abort.
+// An expired ID is free again.
+func (s *Store) Begin(id, fingerprint string, now time.Time) (rec Record, replay bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[id]; ok && now.Before(e.expires) {
+		switch {
+		case e.fingerprint != fingerprint:
+			return Record{}, false, fmt.Errorf("%w: used for a different request", ErrDuplicateRequest)
+		case !e.done:
+			return Record{}, false, fmt.Errorf("%w: still being processed", ErrDuplicateRequest)
+		}
+		return e.rec, true, nil
+	}
+	s.entries[id] = &entry{fingerprint: fingerprint, expires: now.Add(s.ttl)}
+	return Record{}, false, nil
+}
+
+// Complete stores the response of the request holding id. The TTL runs from
+// Begin.
+func (s *Store) Complete(id string, rec Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[id]; ok && !e.done {
+		e.done = true
+		e.rec = rec
+	}
+}
+
+// Abort releases id without storing a response.
+func (s *Store) Abort(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[id]; ok && !e.done {
+		delete(s.entries, id)
+	}
+}
+
+// Prune drops the expired IDs and returns how many it dropped.
+func (s *Store) Prune(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Size returns the number of IDs held.
+func (s *Store) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// ScopedID returns the store ID of requestID sent by client. The length
+// prefix keeps distinct (client, requestID) pairs distinct whatever they
+// contain.
+func ScopedID(client, requestID string) string {
+	return strconv.Itoa(len(client)) + ":" + client + ":" + requestID
+}
+
+// Fingerprint returns the hex SHA-256 of the JCS serialization of v, so that
+// requests differing only in key order or whitespace have equal fingerprints.
+func Fingerprint(v interface{}) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("idempotency: marshal: %w", err)
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return "", fmt.Errorf("idempotency: jcs transform: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
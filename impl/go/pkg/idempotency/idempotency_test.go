@@ -0,0 +1,73 @@
+package idempotency_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/idempotency"
+)
+
+func TestStore_ReplayConflictExpiry(t *testing.T) {
+	s := idempotency.NewStore(time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+
+	if _, replay, err := s.Begin("req-1", "fp-a", now); replay || err != nil {
+		t.Fatalf("first Begin = %v, %v", replay, err)
+	}
+	// A retry while the first request is in flight is rejected.
+	if _, _, err := s.Begin("req-1", "fp-a", now); !errors.Is(err, idempotency.ErrDuplicateRequest) {
+		t.Errorf("in-flight retry err = %v", err)
+	}
+	s.Complete("req-1", idempotency.Record{Status: 200, Body: []byte("resp"), LedgerEventID: "ev-1"})
+
+	rec, replay, err := s.Begin("req-1", "fp-a", now.Add(time.Second))
+	if err != nil || !replay || string(rec.Body) != "resp" || rec.LedgerEventID != "ev-1" {
+		t.Errorf("identical retry = %+v, %v, %v", rec, replay, err)
+	}
+	if _, _, err := s.Begin("req-1", "fp-b", now.Add(time.Second)); !errors.Is(err, idempotency.ErrDuplicateRequest) {
+		t.Errorf("conflicting retry err = %v", err)
+	}
+
+	// An aborted request leaves its ID free.
+	s.Begin("req-2", "fp-a", now)
+	s.Abort("req-2")
+	if _, replay, err := s.Begin("req-2", "fp-b", now); replay || err != nil {
+		t.Errorf("Begin after Abort = %v, %v", replay, err)
+	}
+
+	// IDs are forgotten after the TTL.
+	if _, replay, err := s.Begin("req-1", "fp-b", now.Add(time.Minute)); replay || err != nil {
+		t.Errorf("Begin after TTL = %v, %v", replay, err)
+	}
+	if n := s.Prune(now.Add(time.Minute)); n != 1 || s.Size() != 1 {
+		t.Errorf("Prune dropped %d, %d left", n, s.Size())
+	}
+}
+
+func TestScopedID(t *testing.T) {
+	if idempotency.ScopedID("agent-a", "req-1") == idempotency.ScopedID("agent-b", "req-1") {
+		t.Error("equal request IDs of different clients collide")
+	}
+	if idempotency.ScopedID("a:b", "c") == idempotency.ScopedID("a", "b:c") {
+		t.Error("separator in the client ID makes IDs collide")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a, err := idempotency.Fingerprint(map[string]interface{}{"agent_id": "a", "n": 1})
+	if err != nil {
+		t.Fatalf("Fingerprint: %v", err)
+	}
+	b, _ := idempotency.Fingerprint(struct {
+		N       int    `json:"n"`
+		AgentID string `json:"agent_id"`
+	}{1, "a"})
+	c, _ := idempotency.Fingerprint(map[string]interface{}{"agent_id": "b", "n": 1})
+	if a != b {
+		t.Error("key order changed the fingerprint")
+	}
+	if a == c {
+		t.Error("different requests have equal fingerprints")
+	}
+}
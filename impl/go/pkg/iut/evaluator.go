@@ -8,10 +8,10 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	"github.com/gowebpki/jcs"
-	"github.com/mr-tron/base58"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
 )
 
 // ─── Types ────────────────────────────────────────────────────────────────────
@@ -113,7 +113,7 @@ func Evaluate(vec TestVector) Response {
 	}
 
 	// 4. Signature
-	pubKey, err := resolveDIDKey(issuer)
+	pubKey, err := did.DecodeKey(issuer)
 	if err != nil {
 		return reject("INVALID_SIGNATURE")
 	}
@@ -170,29 +170,6 @@ func SignCapability(cap map[string]interface{}, sk ed25519.PrivateKey) (string,
 
 // ─── Internal helpers ─────────────────────────────────────────────────────────
 
-// resolveDIDKey resolves a did:key: DID to an Ed25519 public key.
-// Format: did:key:z<base58btc(multicodec[0xed,0x01] + 32-byte-pubkey)>
-func resolveDIDKey(did string) (ed25519.PublicKey, error) {
-	const prefix = "did:key:z"
-	if !strings.HasPrefix(did, prefix) {
-		return nil, fmt.Errorf("unsupported DID: %s", did)
-	}
-	encoded := did[len(prefix):]
-	decoded, err := base58.Decode(encoded)
-	if err != nil {
-		return nil, fmt.Errorf("base58: %w", err)
-	}
-	// Ed25519 multicodec prefix: [0xed, 0x01]
-	if len(decoded) < 2 || decoded[0] != 0xed || decoded[1] != 0x01 {
-		return nil, fmt.Errorf("not an Ed25519 did:key")
-	}
-	keyBytes := decoded[2:]
-	if len(keyBytes) != ed25519.PublicKeySize {
-		return nil, fmt.Errorf("wrong key length: %d", len(keyBytes))
-	}
-	return ed25519.PublicKey(keyBytes), nil
-}
-
 // verifyCapSig verifies the capability's "signature" field.
 // Computes: ed25519.Verify(pubKey, sha256(jcs(cap_without_signature)), sig)
 func verifyCapSig(cap map[string]interface{}, pubKey ed25519.PublicKey) error {
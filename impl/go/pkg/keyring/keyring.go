@@ -0,0 +1,397 @@
+// Package keyring implements the institutional signing keyring.
+//
+// An institution signs ledger events, Execution Tokens, export bundles and API
+// responses with its Ed25519 key. A keyring lets that key be rotated without
+// breaking verification of anything signed before the rotation:
+//
+//   - Every key is identified by a kid (key ID) carried on signed artifacts
+//   - Every key has a validity window [valid_from, valid_until]
+//   - Verifiers resolve the key by (kid, artifact timestamp) via KeyAt
+//   - Retired keys are kept forever so historical artifacts stay verifiable
+//
+// Rotation procedure (ACP-GOV-EVENTS-1.0 §5.9 trust_anchor_rotated):
+//  1. The currently active (old) key signs an event endorsing the new key
+//  2. The endorsement is appended to the audit ledger, still signed by the old key
+//  3. Rotate closes the old key's window and activates the new key
+//
+// The public view of the keyring is published at /.well-known/acp-keys.
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ─── Errors ───────────────────────────────────────────────────────────────────
+
+var (
+	// ErrUnknownKID is returned when no key with the requested kid exists.
+	ErrUnknownKID = errors.New("acp/keyring: unknown kid")
+
+	// ErrKeyNotValidAt is returned when the key exists but its validity window
+	// does not cover the artifact timestamp.
+	ErrKeyNotValidAt = errors.New("acp/keyring: key not valid at timestamp")
+
+	// ErrNoSigningKey is returned when the keyring holds no private key
+	// (dev mode) and an operation requires signing.
+	ErrNoSigningKey = errors.New("acp/keyring: no private key available for signing")
+
+	// ErrDuplicateKID is returned when a rotation would reuse an existing kid.
+	ErrDuplicateKID = errors.New("acp/keyring: kid already present in keyring")
+
+	// ErrInvalidKey is returned for malformed key material.
+	ErrInvalidKey = errors.New("acp/keyring: invalid key material")
+)
+
+// ─── Key Status ───────────────────────────────────────────────────────────────
+
+// Status is the lifecycle state of a key in the keyring.
+type Status string
+
+const (
+	// StatusActive is the key currently used to sign new artifacts.
+	StatusActive Status = "active"
+	// StatusRetired keys no longer sign but still verify artifacts inside their window.
+	StatusRetired Status = "retired"
+)
+
+// Rotation types per ACP-GOV-EVENTS-1.0 §5.9.
+const (
+	RotationScheduled = "scheduled"
+	RotationEmergency = "emergency"
+)
+
+// ─── Types ────────────────────────────────────────────────────────────────────
+
+// Key is the public record of one institutional key.
+type Key struct {
+	KID       string            `json:"kid"`
+	PublicKey ed25519.PublicKey `json:"-"`
+	// ValidFrom is the first UNIX timestamp at which artifacts signed by this key are valid.
+	ValidFrom int64 `json:"valid_from"`
+	// ValidUntil is the last UNIX timestamp at which artifacts signed by this key
+	// are valid. 0 means open-ended (the active key).
+	ValidUntil int64  `json:"valid_until,omitempty"`
+	Status     Status `json:"status"`
+	// EndorsementRef is the ledger event_id of the trust_anchor_rotated event in
+	// which the predecessor key endorsed this key. Empty for the initial key.
+	EndorsementRef string `json:"endorsement_ref,omitempty"`
+}
+
+// coversTimestamp reports whether ts falls inside the key's validity window.
+func (k Key) coversTimestamp(ts int64) bool {
+	if ts < k.ValidFrom {
+		return false
+	}
+	return k.ValidUntil == 0 || ts <= k.ValidUntil
+}
+
+// Keyring is a thread-safe, append-only set of institutional keys.
+//
+// Exactly one key is active at any time. Only the active key's private half
+// is retained; retired private keys are discarded on rotation.
+type Keyring struct {
+	mu            sync.RWMutex
+	institutionID string
+	keys          []Key // ordered by ValidFrom (rotation order)
+	byKID         map[string]int
+	activePriv    ed25519.PrivateKey // nil → dev mode (verify only)
+}
+
+// DeriveKID computes the key ID for an Ed25519 public key:
+// the first 16 characters of base64url(SHA-256(pk_bytes)).
+//
+// The kid is deterministic so that independent verifiers agree on it without
+// coordination.
+func DeriveKID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return base64.RawURLEncoding.EncodeToString(sum[:])[:16]
+}
+
+// New creates a keyring whose initial active key is pub.
+//
+// priv may be nil (dev mode: the keyring can verify but not sign or rotate).
+// kid may be empty, in which case it is derived with DeriveKID.
+// validFrom is the start of the initial key's window (typically 0, so that
+// artifacts created before the keyring existed remain verifiable).
+func New(institutionID, kid string, pub ed25519.PublicKey, priv ed25519.PrivateKey, validFrom int64) (*Keyring, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: public key must be %d bytes", ErrInvalidKey, ed25519.PublicKeySize)
+	}
+	if priv != nil && !pub.Equal(priv.Public()) {
+		return nil, fmt.Errorf("%w: private key does not match public key", ErrInvalidKey)
+	}
+	if kid == "" {
+		kid = DeriveKID(pub)
+	}
+	return &Keyring{
+		institutionID: institutionID,
+		keys: []Key{{
+			KID:       kid,
+			PublicKey: pub,
+			ValidFrom: validFrom,
+			Status:    StatusActive,
+		}},
+		byKID:      map[string]int{kid: 0},
+		activePriv: priv,
+	}, nil
+}
+
+// ─── Signing side ─────────────────────────────────────────────────────────────
+
+// Active returns the kid and private key that MUST be used to sign new artifacts.
+// priv is nil in dev mode.
+func (k *Keyring) Active() (kid string, priv ed25519.PrivateKey) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[len(k.keys)-1].KID, k.activePriv
+}
+
+// ActiveKey returns the public record of the active key.
+func (k *Keyring) ActiveKey() Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[len(k.keys)-1]
+}
+
+// Rotate retires the active key and activates newPriv.
+//
+// rotatedAt is the rotation instant; the new key is valid from rotatedAt and
+// the old key stays valid until rotatedAt+overlapSeconds so that artifacts
+// signed in flight around the rotation still verify. Emergency rotations
+// SHOULD pass overlapSeconds = 0.
+//
+// endorsementRef is the ledger event_id of the trust_anchor_rotated event
+// signed by the old key. Callers MUST append that event before calling Rotate.
+//
+// Returns the new key's public record.
+func (k *Keyring) Rotate(newPriv ed25519.PrivateKey, rotatedAt, overlapSeconds int64, endorsementRef string) (Key, error) {
+	if len(newPriv) != ed25519.PrivateKeySize {
+		return Key{}, fmt.Errorf("%w: private key must be %d bytes", ErrInvalidKey, ed25519.PrivateKeySize)
+	}
+	newPub := newPriv.Public().(ed25519.PublicKey)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.activePriv == nil {
+		return Key{}, ErrNoSigningKey
+	}
+	nk, err := k.rotate(newPub, rotatedAt, overlapSeconds, endorsementRef)
+	if err != nil {
+		return Key{}, err
+	}
+	k.activePriv = newPriv
+	return nk, nil
+}
+
+// RotatePublic records on a verify-only keyring (no private key) a rotation
+// performed by the institution, e.g. by replaying the trust_anchor_rotated
+// event from its ledger. The arguments are those of Rotate.
+//
+// Returns ErrInvalidKey if the keyring holds a private key: it would no
+// longer match the active key.
+func (k *Keyring) RotatePublic(newPub ed25519.PublicKey, rotatedAt, overlapSeconds int64, endorsementRef string) (Key, error) {
+	if len(newPub) != ed25519.PublicKeySize {
+		return Key{}, fmt.Errorf("%w: public key must be %d bytes", ErrInvalidKey, ed25519.PublicKeySize)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.activePriv != nil {
+		return Key{}, fmt.Errorf("%w: keyring holds a private key, use Rotate", ErrInvalidKey)
+	}
+	return k.rotate(newPub, rotatedAt, overlapSeconds, endorsementRef)
+}
+
+// rotate retires the active key and appends newPub as the active key.
+// Caller must hold k.mu.
+func (k *Keyring) rotate(newPub ed25519.PublicKey, rotatedAt, overlapSeconds int64, endorsementRef string) (Key, error) {
+	if overlapSeconds < 0 {
+		overlapSeconds = 0
+	}
+	newKID := DeriveKID(newPub)
+	if _, exists := k.byKID[newKID]; exists {
+		return Key{}, fmt.Errorf("%w: %s", ErrDuplicateKID, newKID)
+	}
+
+	old := &k.keys[len(k.keys)-1]
+	old.Status = StatusRetired
+	old.ValidUntil = rotatedAt + overlapSeconds
+
+	nk := Key{
+		KID:            newKID,
+		PublicKey:      newPub,
+		ValidFrom:      rotatedAt,
+		Status:         StatusActive,
+		EndorsementRef: endorsementRef,
+	}
+	k.keys = append(k.keys, nk)
+	k.byKID[newKID] = len(k.keys) - 1
+	return nk, nil
+}
+
+// ─── Verification side ────────────────────────────────────────────────────────
+
+// KeyAt resolves the public key that was valid for kid at timestamp ts.
+//
+// An empty kid resolves legacy artifacts signed before kids existed: the
+// earliest key whose window covers ts is returned.
+//
+// Returns ErrUnknownKID or ErrKeyNotValidAt on failure.
+func (k *Keyring) KeyAt(kid string, ts int64) (ed25519.PublicKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return keyAt(k.keys, k.byKID, kid, ts)
+}
+
+// KeysAt returns the public keys whose window covers ts, newest first.
+func (k *Keyring) KeysAt(ts int64) []ed25519.PublicKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	var out []ed25519.PublicKey
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		if k.keys[i].coversTimestamp(ts) {
+			out = append(out, k.keys[i].PublicKey)
+		}
+	}
+	return out
+}
+
+// Lookup returns the public record for kid.
+func (k *Keyring) Lookup(kid string) (Key, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	idx, ok := k.byKID[kid]
+	if !ok {
+		return Key{}, false
+	}
+	return k.keys[idx], true
+}
+
+// Keys returns a copy of every key in rotation order.
+func (k *Keyring) Keys() []Key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	out := make([]Key, len(k.keys))
+	copy(out, k.keys)
+	return out
+}
+
+// Size returns the number of keys (active + retired).
+func (k *Keyring) Size() int {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return len(k.keys)
+}
+
+func keyAt(keys []Key, byKID map[string]int, kid string, ts int64) (ed25519.PublicKey, error) {
+	if kid == "" {
+		for _, key := range keys {
+			if key.coversTimestamp(ts) {
+				return key.PublicKey, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: no key covers ts=%d", ErrKeyNotValidAt, ts)
+	}
+	idx, ok := byKID[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKID, kid)
+	}
+	key := keys[idx]
+	if !key.coversTimestamp(ts) {
+		return nil, fmt.Errorf("%w: kid=%s ts=%d window=[%d,%d]",
+			ErrKeyNotValidAt, kid, ts, key.ValidFrom, key.ValidUntil)
+	}
+	return key.PublicKey, nil
+}
+
+// ─── Published keyring (/.well-known/acp-keys) ───────────────────────────────
+
+// JWK is the published form of one key (RFC 8037 OKP / Ed25519) extended with
+// the ACP validity window.
+type JWK struct {
+	KTY            string `json:"kty"` // "OKP"
+	CRV            string `json:"crv"` // "Ed25519"
+	KID            string `json:"kid"`
+	X              string `json:"x"` // base64url public key
+	Use            string `json:"use"`
+	ValidFrom      int64  `json:"valid_from"`
+	ValidUntil     int64  `json:"valid_until,omitempty"`
+	Status         Status `json:"status"`
+	EndorsementRef string `json:"endorsement_ref,omitempty"`
+}
+
+// Document is the JSON served at GET /.well-known/acp-keys.
+type Document struct {
+	InstitutionID string `json:"institution_id"`
+	ActiveKID     string `json:"active_kid"`
+	Keys          []JWK  `json:"keys"`
+}
+
+// Document returns the public keyring document.
+func (k *Keyring) Document() Document {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	doc := Document{
+		InstitutionID: k.institutionID,
+		ActiveKID:     k.keys[len(k.keys)-1].KID,
+		Keys:          make([]JWK, 0, len(k.keys)),
+	}
+	for _, key := range k.keys {
+		doc.Keys = append(doc.Keys, JWK{
+			KTY:            "OKP",
+			CRV:            "Ed25519",
+			KID:            key.KID,
+			X:              base64.RawURLEncoding.EncodeToString(key.PublicKey),
+			Use:            "sig",
+			ValidFrom:      key.ValidFrom,
+			ValidUntil:     key.ValidUntil,
+			Status:         key.Status,
+			EndorsementRef: key.EndorsementRef,
+		})
+	}
+	return doc
+}
+
+// PublishedKeyring is a verify-only keyring reconstructed from a published
+// Document. Regulators and peers use it to verify ledgers and bundles offline.
+type PublishedKeyring struct {
+	keys  []Key
+	byKID map[string]int
+}
+
+// ParseDocument builds a PublishedKeyring from a /.well-known/acp-keys document.
+func ParseDocument(doc Document) (*PublishedKeyring, error) {
+	p := &PublishedKeyring{byKID: make(map[string]int, len(doc.Keys))}
+	for _, jwk := range doc.Keys {
+		if jwk.KTY != "OKP" || jwk.CRV != "Ed25519" {
+			return nil, fmt.Errorf("%w: kid=%s unsupported kty/crv %s/%s", ErrInvalidKey, jwk.KID, jwk.KTY, jwk.CRV)
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: kid=%s malformed x", ErrInvalidKey, jwk.KID)
+		}
+		if _, dup := p.byKID[jwk.KID]; dup {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateKID, jwk.KID)
+		}
+		p.byKID[jwk.KID] = len(p.keys)
+		p.keys = append(p.keys, Key{
+			KID:            jwk.KID,
+			PublicKey:      ed25519.PublicKey(raw),
+			ValidFrom:      jwk.ValidFrom,
+			ValidUntil:     jwk.ValidUntil,
+			Status:         jwk.Status,
+			EndorsementRef: jwk.EndorsementRef,
+		})
+	}
+	return p, nil
+}
+
+// KeyAt resolves the public key valid for kid at ts (see Keyring.KeyAt).
+func (p *PublishedKeyring) KeyAt(kid string, ts int64) (ed25519.PublicKey, error) {
+	return keyAt(p.keys, p.byKID, kid, ts)
+}
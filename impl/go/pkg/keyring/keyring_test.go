@@ -0,0 +1,240 @@
+package keyring_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/keyring"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+)
+
+// ─── Helpers ──────────────────────────────────────────────────────────────────
+
+func seededKey(b byte) (ed25519.PublicKey, ed25519.PrivateKey) {
+	seed := make([]byte, 32)
+	seed[0] = b
+	sk := ed25519.NewKeyFromSeed(seed)
+	return sk.Public().(ed25519.PublicKey), sk
+}
+
+func newKeyring(t *testing.T) (*keyring.Keyring, ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv := seededKey(0x01)
+	kr, err := keyring.New("org.test", "", pub, priv, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return kr, pub, priv
+}
+
+// ─── New / DeriveKID ──────────────────────────────────────────────────────────
+
+func TestDeriveKID_Deterministic(t *testing.T) {
+	pub, _ := seededKey(0x01)
+	a, b := keyring.DeriveKID(pub), keyring.DeriveKID(pub)
+	if a != b {
+		t.Errorf("DeriveKID not deterministic: %q vs %q", a, b)
+	}
+	if len(a) != 16 {
+		t.Errorf("kid length = %d, want 16", len(a))
+	}
+	other, _ := seededKey(0x02)
+	if keyring.DeriveKID(other) == a {
+		t.Error("different keys produced the same kid")
+	}
+}
+
+func TestNew_ExplicitKID(t *testing.T) {
+	pub, priv := seededKey(0x01)
+	kr, err := keyring.New("org.test", "inst-key-1", pub, priv, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	kid, got := kr.Active()
+	if kid != "inst-key-1" {
+		t.Errorf("active kid = %q, want inst-key-1", kid)
+	}
+	if !got.Equal(priv) {
+		t.Error("active private key mismatch")
+	}
+}
+
+func TestNew_MismatchedKeys(t *testing.T) {
+	pub, _ := seededKey(0x01)
+	_, otherPriv := seededKey(0x02)
+	if _, err := keyring.New("org.test", "", pub, otherPriv, 0); !errors.Is(err, keyring.ErrInvalidKey) {
+		t.Errorf("err = %v, want ErrInvalidKey", err)
+	}
+}
+
+// ─── Rotate / KeyAt ───────────────────────────────────────────────────────────
+
+func TestRotate_WindowsAndStatus(t *testing.T) {
+	kr, oldPub, _ := newKeyring(t)
+	oldKID, _ := kr.Active()
+	newPub, newPriv := seededKey(0x02)
+
+	nk, err := kr.Rotate(newPriv, 1000, 60, "ev-endorse")
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if nk.KID != keyring.DeriveKID(newPub) || nk.ValidFrom != 1000 || nk.EndorsementRef != "ev-endorse" {
+		t.Errorf("unexpected new key record: %+v", nk)
+	}
+	if kid, _ := kr.Active(); kid != nk.KID {
+		t.Errorf("active kid = %q, want %q", kid, nk.KID)
+	}
+	old, _ := kr.Lookup(oldKID)
+	if old.Status != keyring.StatusRetired || old.ValidUntil != 1060 {
+		t.Errorf("old key = %+v, want retired until 1060", old)
+	}
+
+	// Old key verifies inside its window (including the overlap), not after.
+	if k, err := kr.KeyAt(oldKID, 1059); err != nil || !k.Equal(oldPub) {
+		t.Errorf("KeyAt(old, 1059) = %v, %v", k, err)
+	}
+	if _, err := kr.KeyAt(oldKID, 1061); !errors.Is(err, keyring.ErrKeyNotValidAt) {
+		t.Errorf("KeyAt(old, 1061) err = %v, want ErrKeyNotValidAt", err)
+	}
+	// New key does not verify before its window.
+	if _, err := kr.KeyAt(nk.KID, 999); !errors.Is(err, keyring.ErrKeyNotValidAt) {
+		t.Errorf("KeyAt(new, 999) err = %v, want ErrKeyNotValidAt", err)
+	}
+	if _, err := kr.KeyAt("nope", 1000); !errors.Is(err, keyring.ErrUnknownKID) {
+		t.Errorf("KeyAt(unknown) err = %v, want ErrUnknownKID", err)
+	}
+}
+
+func TestKeyAt_LegacyEmptyKID(t *testing.T) {
+	kr, oldPub, _ := newKeyring(t)
+	newPub, newPriv := seededKey(0x02)
+	if _, err := kr.Rotate(newPriv, 1000, 0, ""); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if k, _ := kr.KeyAt("", 500); !k.Equal(oldPub) {
+		t.Error("empty kid at ts=500 should resolve to the initial key")
+	}
+	if k, _ := kr.KeyAt("", 2000); !k.Equal(newPub) {
+		t.Error("empty kid at ts=2000 should resolve to the rotated key")
+	}
+}
+
+func TestRotate_DevModeRejected(t *testing.T) {
+	pub, _ := seededKey(0x01)
+	kr, _ := keyring.New("org.test", "", pub, nil, 0)
+	_, newPriv := seededKey(0x02)
+	if _, err := kr.Rotate(newPriv, 1000, 0, ""); !errors.Is(err, keyring.ErrNoSigningKey) {
+		t.Errorf("err = %v, want ErrNoSigningKey", err)
+	}
+}
+
+func TestRotatePublic_VerifyOnly(t *testing.T) {
+	pub, _ := seededKey(0x01)
+	kr, _ := keyring.New("org.test", "", pub, nil, 0)
+	newPub, _ := seededKey(0x02)
+
+	nk, err := kr.RotatePublic(newPub, 1000, 60, "ev-endorse")
+	if err != nil {
+		t.Fatalf("RotatePublic: %v", err)
+	}
+	if kid, priv := kr.Active(); kid != nk.KID || priv != nil {
+		t.Errorf("active = %q, %v; want %q without private key", kid, priv, nk.KID)
+	}
+	if k, err := kr.KeyAt(nk.KID, 1000); err != nil || !k.Equal(newPub) {
+		t.Errorf("KeyAt(new, 1000) = %v, %v", k, err)
+	}
+
+	// A signing keyring must rotate with Rotate.
+	signing, _, _ := newKeyring(t)
+	if _, err := signing.RotatePublic(newPub, 1000, 0, ""); !errors.Is(err, keyring.ErrInvalidKey) {
+		t.Errorf("signing keyring: err = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestRotate_DuplicateKID(t *testing.T) {
+	kr, _, priv := newKeyring(t)
+	if _, err := kr.Rotate(priv, 1000, 0, ""); !errors.Is(err, keyring.ErrDuplicateKID) {
+		t.Errorf("err = %v, want ErrDuplicateKID", err)
+	}
+}
+
+// ─── Published document ───────────────────────────────────────────────────────
+
+func TestDocument_RoundTrip(t *testing.T) {
+	kr, _, _ := newKeyring(t)
+	_, newPriv := seededKey(0x02)
+	nk, _ := kr.Rotate(newPriv, 1000, 30, "ev-1")
+
+	raw, err := json.Marshal(kr.Document())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var doc keyring.Document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if doc.ActiveKID != nk.KID || len(doc.Keys) != 2 {
+		t.Fatalf("doc = %+v", doc)
+	}
+	pk, err := keyring.ParseDocument(doc)
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	for _, key := range kr.Keys() {
+		got, err := pk.KeyAt(key.KID, key.ValidFrom)
+		if err != nil || !got.Equal(key.PublicKey) {
+			t.Errorf("published KeyAt(%s) = %v, %v", key.KID, got, err)
+		}
+	}
+}
+
+// ─── Ledger integration ───────────────────────────────────────────────────────
+
+// A ledger whose signing key is rotated mid-chain must verify end-to-end
+// against the keyring, and offline against the published document.
+func TestLedger_VerifiesAcrossRotation(t *testing.T) {
+	kr, _, priv := newKeyring(t)
+	oldKID, _ := kr.Active()
+	l, err := ledger.NewInMemoryLedgerWithKeyID("org.test", oldKID, priv)
+	if err != nil {
+		t.Fatalf("NewInMemoryLedgerWithKeyID: %v", err)
+	}
+	l.SetKeyResolver(kr)
+
+	if _, err := l.Append(ledger.EventAgentRegistered, map[string]string{"agent_id": "a1"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	endorse, err := l.Append(ledger.EventGovernance, map[string]string{"event_type": "trust_anchor_rotated"})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	_, newPriv := seededKey(0x02)
+	nk, err := kr.Rotate(newPriv, endorse.Timestamp, 0, endorse.EventID)
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	l.SetSigningKey(nk.KID, newPriv)
+
+	after, err := l.Append(ledger.EventAgentRegistered, map[string]string{"agent_id": "a2"})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if after.KID != nk.KID || endorse.KID != oldKID {
+		t.Errorf("kids: endorse=%q after=%q", endorse.KID, after.KID)
+	}
+
+	if errs := l.Verify(); len(errs) != 0 {
+		t.Errorf("Verify after rotation: %v", errs)
+	}
+
+	published, err := keyring.ParseDocument(kr.Document())
+	if err != nil {
+		t.Fatalf("ParseDocument: %v", err)
+	}
+	if errs := ledger.VerifyEvents(l.List(0, 0), published); len(errs) != 0 {
+		t.Errorf("offline VerifyEvents: %v", errs)
+	}
+}
@@ -0,0 +1,489 @@
+package ledger
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gowebpki/jcs"
+)
+
+// ─── Segment Archival ─────────────────────────────────────────────────────────
+//
+// A long-running ledger cannot keep every event in memory, nor re-verify its
+// whole history on every Verify. ArchiveBefore seals the oldest live events
+// into an immutable segment, written to an ArchiveStore, and replaces them in
+// memory by a signed SegmentCheckpoint: the last sequence, hash and timestamp
+// of the segment, its event count and the hash of the segment file.
+//
+// The ledger then holds the checkpoints and the live events only. Verify
+// starts from the latest checkpoint, which it trusts once its signature
+// verifies; VerifyArchive re-verifies the archived segments against their
+// checkpoints. Readers do not see the boundary: List, Get, GetBySequence and
+// VerifyEvent read the archived segments they need, and the Merkle tree keeps
+// every leaf, so proofs still cover archived events.
+
+// SegmentCheckpointVersion is the version of the SegmentCheckpoint format.
+const SegmentCheckpointVersion = "1.0"
+
+var (
+	// ErrNoArchive is returned by ArchiveBefore when no ArchiveStore is set.
+	ErrNoArchive = errors.New("ledger: no archive store configured")
+
+	// ErrNothingToArchive is returned by ArchiveBefore when no live event
+	// precedes the boundary. The newest event always stays live.
+	ErrNothingToArchive = errors.New("ledger: nothing to archive")
+)
+
+// SegmentCheckpoint seals one archived segment: the events FirstSequence to
+// LastSequence, whose file hashes to SegmentHash. It is signed like an event:
+// Ed25519 over SHA-256(JCS(checkpoint without sig)).
+type SegmentCheckpoint struct {
+	Ver           string `json:"ver"`
+	InstitutionID string `json:"institution_id"`
+	FirstSequence int64  `json:"first_sequence"`
+	LastSequence  int64  `json:"last_sequence"`
+	LastHash      string `json:"last_hash"`
+	LastTimestamp int64  `json:"last_timestamp"`
+	Count         int64  `json:"count"`
+	SegmentHash   string `json:"segment_hash"` // base64url SHA-256 of the segment file
+	SealedAt      int64  `json:"sealed_at"`
+	KID           string `json:"kid,omitempty"`
+	Sig           string `json:"sig,omitempty"`
+}
+
+// ArchiveStore holds sealed segments. A segment file is the JSON-lines
+// encoding of its events; the ledger checks it against SegmentHash on every
+// read, so a store needs no integrity checks of its own.
+type ArchiveStore interface {
+	WriteSegment(cp SegmentCheckpoint, data []byte) error
+	ReadSegment(cp SegmentCheckpoint) ([]byte, error)
+}
+
+// SetArchive attaches the store ArchiveBefore writes segments to.
+func (l *InMemoryLedger) SetArchive(a ArchiveStore) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.archive = a
+}
+
+// Segments returns the checkpoints of the archived segments, oldest first.
+func (l *InMemoryLedger) Segments() []SegmentCheckpoint {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]SegmentCheckpoint(nil), l.segments...)
+}
+
+// ArchiveBefore seals the live events with a timestamp before the given
+// unix time into one segment and drops them from memory. The newest event
+// always stays live, so the chain keeps a head to append to.
+//
+// The segment is written before the events are dropped: if the store fails,
+// nothing changes. Appends proceed while the segment is written.
+func (l *InMemoryLedger) ArchiveBefore(before int64) (SegmentCheckpoint, error) {
+	if l.replica {
+		return SegmentCheckpoint{}, fmt.Errorf("%w: replica ledgers are read-only", ErrModificationRejected)
+	}
+	l.archiveMu.Lock()
+	defer l.archiveMu.Unlock()
+
+	l.mu.RLock()
+	store, kid, privKey := l.archive, l.kid, l.privKey
+	var seg []Event
+	for _, ev := range l.events[:len(l.events)-1] {
+		if ev.Timestamp >= before {
+			break
+		}
+		seg = append(seg, ev)
+	}
+	l.mu.RUnlock()
+	if store == nil {
+		return SegmentCheckpoint{}, ErrNoArchive
+	}
+	if len(seg) == 0 {
+		return SegmentCheckpoint{}, ErrNothingToArchive
+	}
+
+	data, err := encodeSegment(seg)
+	if err != nil {
+		return SegmentCheckpoint{}, err
+	}
+	first, last := seg[0], seg[len(seg)-1]
+	cp := SegmentCheckpoint{
+		Ver:           SegmentCheckpointVersion,
+		InstitutionID: l.institutionID,
+		FirstSequence: first.Sequence,
+		LastSequence:  last.Sequence,
+		LastHash:      last.Hash,
+		LastTimestamp: last.Timestamp,
+		Count:         int64(len(seg)),
+		SegmentHash:   segmentHash(data),
+		SealedAt:      time.Now().Unix(),
+	}
+	if privKey != nil {
+		cp.KID = kid
+		if cp.Sig, err = signCheckpoint(cp, privKey); err != nil {
+			return SegmentCheckpoint{}, err
+		}
+	}
+	if err := store.WriteSegment(cp, data); err != nil {
+		return SegmentCheckpoint{}, fmt.Errorf("%w: write segment %d-%d: %v", ErrSegmentUnavailable, cp.FirstSequence, cp.LastSequence, err)
+	}
+
+	// Only ArchiveBefore removes events, so the archived ones are still the
+	// first live events.
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, ev := range l.events[:len(seg)] {
+		delete(l.byID, ev.EventID)
+	}
+	l.events = append([]Event(nil), l.events[len(seg):]...)
+	l.base += int64(len(seg))
+	l.segments = append(l.segments, cp)
+	return cp, nil
+}
+
+// SequenceSince returns the lowest sequence that may hold an event with a
+// timestamp ≥ ts: the archived segments ending before ts are skipped. Readers
+// bounded in time use it so as not to load segments they cannot need.
+func (l *InMemoryLedger) SequenceSince(ts int64) int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	from := int64(1)
+	for _, cp := range l.segments {
+		if cp.LastTimestamp >= ts {
+			break
+		}
+		from = cp.LastSequence + 1
+	}
+	return from
+}
+
+// VerifyArchive re-verifies every archived segment: its checkpoint signature,
+// its file hash and event count, and its events as a chain starting where the
+// previous segment's checkpoint ends. Verify checks only the live events.
+func (l *InMemoryLedger) VerifyArchive() []VerificationError {
+	l.mu.RLock()
+	segs := append([]SegmentCheckpoint(nil), l.segments...)
+	store := l.archive
+	keys := l.keyResolver()
+	mode := l.schema
+	l.mu.RUnlock()
+
+	var errs []VerificationError
+	for i, cp := range segs {
+		var prev *SegmentCheckpoint
+		if i > 0 {
+			prev = &segs[i-1]
+		}
+		if store == nil {
+			errs = append(errs, segmentError("LEDGER-014", cp, ErrNoArchive.Error()))
+			continue
+		}
+		data, err := store.ReadSegment(cp)
+		if err != nil {
+			errs = append(errs, segmentError("LEDGER-014", cp, fmt.Sprintf("segment unreadable: %v", err)))
+			continue
+		}
+		errs = append(errs, verifySegment(cp, data, prev, keys, mode)...)
+	}
+	return errs
+}
+
+// VerifySegment verifies an archived segment offline, as VerifyArchive does
+// (payloads in SchemaLenient mode). prev is the checkpoint of the preceding
+// segment, nil for the first one, which must start with the genesis event.
+// keys == nil skips signature checks.
+func VerifySegment(cp SegmentCheckpoint, data []byte, prev *SegmentCheckpoint, keys KeyResolver) []VerificationError {
+	return verifySegment(cp, data, prev, keys, SchemaLenient)
+}
+
+func verifySegment(cp SegmentCheckpoint, data []byte, prev *SegmentCheckpoint, keys KeyResolver, mode SchemaMode) []VerificationError {
+	errs := verifyCheckpointSig(cp, keys)
+	if h := segmentHash(data); h != cp.SegmentHash {
+		return append(errs, segmentError("LEDGER-003", cp, fmt.Sprintf("segment hash %q != checkpoint %q", h, cp.SegmentHash)))
+	}
+	events, err := decodeSegment(data)
+	if err != nil || len(events) == 0 {
+		return append(errs, segmentError("LEDGER-014", cp, fmt.Sprintf("segment undecodable: %v", err)))
+	}
+	if int64(len(events)) != cp.Count || cp.Count != cp.LastSequence-cp.FirstSequence+1 {
+		errs = append(errs, segmentError("LEDGER-005", cp, fmt.Sprintf("segment holds %d events, checkpoint counts %d", len(events), cp.Count)))
+	}
+
+	var anchor *Event
+	if prev != nil {
+		a := anchorEvent(*prev)
+		anchor = &a
+	}
+	errs = append(errs, verifyChainFrom(events, anchor, keys, mode)...)
+
+	first, last := events[0], events[len(events)-1]
+	if first.Sequence != cp.FirstSequence || last.Sequence != cp.LastSequence ||
+		last.Hash != cp.LastHash || last.Timestamp != cp.LastTimestamp {
+		errs = append(errs, segmentError("LEDGER-003", cp, "segment does not match its checkpoint"))
+	}
+	return errs
+}
+
+// verifyCheckpointSig checks the signature of cp against the key valid for
+// its kid when it was sealed. keys == nil skips the check.
+func verifyCheckpointSig(cp SegmentCheckpoint, keys KeyResolver) []VerificationError {
+	if keys == nil {
+		return nil
+	}
+	if cp.Sig == "" {
+		return []VerificationError{segmentError("LEDGER-012", cp, "segment checkpoint unsigned")}
+	}
+	pub, err := keys.KeyAt(cp.KID, cp.SealedAt)
+	if err != nil {
+		return []VerificationError{segmentError("LEDGER-002", cp, fmt.Sprintf("checkpoint signing key not resolvable: %v", err))}
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(cp.Sig)
+	digest, derr := checkpointDigest(cp)
+	if err != nil || derr != nil || !ed25519.Verify(pub, digest[:], sig) {
+		return []VerificationError{segmentError("LEDGER-002", cp, "segment checkpoint signature verification failed")}
+	}
+	return nil
+}
+
+func segmentError(code string, cp SegmentCheckpoint, msg string) VerificationError {
+	return VerificationError{
+		Code:     code,
+		Sequence: cp.LastSequence,
+		Message:  fmt.Sprintf("segment %d-%d: %s", cp.FirstSequence, cp.LastSequence, msg),
+	}
+}
+
+// anchorEvent stands for the last event of the segment sealed by cp, so that
+// the next event can be checked against it (prev_hash, sequence, timestamp).
+func anchorEvent(cp SegmentCheckpoint) Event {
+	return Event{Sequence: cp.LastSequence, Hash: cp.LastHash, Timestamp: cp.LastTimestamp}
+}
+
+// ─── Reading Archived Events ──────────────────────────────────────────────────
+
+// readArchived returns the archived events with sequence in [from, to].
+// Segments that cannot be read or do not match their checkpoint are skipped;
+// VerifyArchive reports them.
+func readArchived(store ArchiveStore, segs []SegmentCheckpoint, from, to int64) []Event {
+	var out []Event
+	for _, cp := range segs {
+		if cp.LastSequence < from || cp.FirstSequence > to {
+			continue
+		}
+		events, err := loadSegment(store, cp)
+		if err != nil {
+			continue
+		}
+		for _, ev := range events {
+			if ev.Sequence >= from && ev.Sequence <= to {
+				out = append(out, ev)
+			}
+		}
+	}
+	return out
+}
+
+// findArchived looks eventID up in the archived segments, newest first, and
+// returns it with its predecessor (nil for the genesis event).
+func findArchived(store ArchiveStore, segs []SegmentCheckpoint, eventID string) (Event, *Event, bool) {
+	for i := len(segs) - 1; i >= 0; i-- {
+		events, err := loadSegment(store, segs[i])
+		if err != nil {
+			continue
+		}
+		for j, ev := range events {
+			if ev.EventID != eventID {
+				continue
+			}
+			var prev *Event
+			switch {
+			case j > 0:
+				p := events[j-1]
+				prev = &p
+			case i > 0:
+				p := anchorEvent(segs[i-1])
+				prev = &p
+			}
+			return ev, prev, true
+		}
+	}
+	return Event{}, nil, false
+}
+
+// loadSegment reads the segment of cp and checks it against the checkpoint.
+func loadSegment(store ArchiveStore, cp SegmentCheckpoint) ([]Event, error) {
+	if store == nil {
+		return nil, ErrNoArchive
+	}
+	data, err := store.ReadSegment(cp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSegmentUnavailable, err)
+	}
+	if segmentHash(data) != cp.SegmentHash {
+		return nil, fmt.Errorf("%w: segment %d-%d", ErrHashMismatch, cp.FirstSequence, cp.LastSequence)
+	}
+	events, err := decodeSegment(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSegmentUnavailable, err)
+	}
+	if int64(len(events)) != cp.Count {
+		return nil, fmt.Errorf("%w: segment %d-%d holds %d events", ErrSequenceGap, cp.FirstSequence, cp.LastSequence, len(events))
+	}
+	return events, nil
+}
+
+// ─── Encoding ─────────────────────────────────────────────────────────────────
+
+func encodeSegment(events []Event) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return nil, fmt.Errorf("encode event %s: %w", ev.EventID, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSegment(data []byte) ([]Event, error) {
+	var events []Event
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var ev Event
+		if err := dec.Decode(&ev); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+func segmentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return base64.URLEncoding.EncodeToString(sum[:])
+}
+
+func checkpointDigest(cp SegmentCheckpoint) ([32]byte, error) {
+	cp.Sig = ""
+	raw, err := json.Marshal(cp)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("marshal: %w", err)
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("jcs: %w", err)
+	}
+	return sha256.Sum256(canonical), nil
+}
+
+func signCheckpoint(cp SegmentCheckpoint, privKey ed25519.PrivateKey) (string, error) {
+	digest, err := checkpointDigest(cp)
+	if err != nil {
+		return "", fmt.Errorf("checkpoint signing: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(ed25519.Sign(privKey, digest[:])), nil
+}
+
+// ─── DirArchive ───────────────────────────────────────────────────────────────
+
+// DirArchive is an ArchiveStore writing each segment to a directory as
+//
+//	segment-<first>-<last>-<hash prefix>.jsonl
+//	segment-<first>-<last>-<hash prefix>.checkpoint.json
+//
+// Both files are written to a temporary file, fsynced and renamed, so a
+// crash never leaves a partial segment. The checkpoint file lets the
+// directory be verified offline (VerifySegment); the hash prefix keeps
+// segments of different ledgers apart.
+type DirArchive struct {
+	dir string
+}
+
+// NewDirArchive returns a store writing to dir, which is created if needed.
+func NewDirArchive(dir string) (*DirArchive, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("ledger: archive: %w", err)
+	}
+	return &DirArchive{dir: dir}, nil
+}
+
+// WriteSegment implements ArchiveStore.
+func (a *DirArchive) WriteSegment(cp SegmentCheckpoint, data []byte) error {
+	raw, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := a.name(cp)
+	if err := writeFileAtomic(filepath.Join(a.dir, name+".jsonl"), data); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(a.dir, name+".checkpoint.json"), raw)
+}
+
+// ReadSegment implements ArchiveStore.
+func (a *DirArchive) ReadSegment(cp SegmentCheckpoint) ([]byte, error) {
+	return os.ReadFile(filepath.Join(a.dir, a.name(cp)+".jsonl"))
+}
+
+// Checkpoints returns the checkpoints of the segments in the directory,
+// ordered by first sequence.
+func (a *DirArchive) Checkpoints() ([]SegmentCheckpoint, error) {
+	paths, err := filepath.Glob(filepath.Join(a.dir, "segment-*.checkpoint.json"))
+	if err != nil {
+		return nil, err
+	}
+	var cps []SegmentCheckpoint
+	for _, p := range paths {
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		var cp SegmentCheckpoint
+		if err := json.Unmarshal(raw, &cp); err != nil {
+			return nil, fmt.Errorf("ledger: archive: %s: %w", filepath.Base(p), err)
+		}
+		cps = append(cps, cp)
+	}
+	sort.Slice(cps, func(i, j int) bool { return cps[i].FirstSequence < cps[j].FirstSequence })
+	return cps, nil
+}
+
+func (a *DirArchive) name(cp SegmentCheckpoint) string {
+	prefix := strings.TrimRight(cp.SegmentHash, "=")
+	if len(prefix) > 16 {
+		prefix = prefix[:16]
+	}
+	return fmt.Sprintf("segment-%012d-%012d-%s", cp.FirstSequence, cp.LastSequence, prefix)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
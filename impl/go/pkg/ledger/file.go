@@ -0,0 +1,87 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileBackend commits events to a JSON-lines file, one event per line.
+//
+// Each commit opens the file in append mode, writes the whole batch in a
+// single write and fsyncs before returning, so an event is durable before
+// the ledger makes it visible. Opening per commit lets operators move the
+// file away for rotation; the next commit recreates it.
+//
+// The file outlives the process: after a restart it holds one chain per run,
+// each starting with its own LEDGER_GENESIS.
+type FileBackend struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileBackend returns a backend writing to path. The file is created on
+// the first commit.
+func NewFileBackend(path string) *FileBackend {
+	return &FileBackend{path: path}
+}
+
+// Path returns the file the backend writes to.
+func (b *FileBackend) Path() string { return b.path }
+
+// Commit implements Backend.
+func (b *FileBackend) Commit(events []Event) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return fmt.Errorf("encode event %s: %w", ev.EventID, err)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f, err := os.OpenFile(b.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadFile returns the events of a FileBackend file in file order: the chains
+// of every run that wrote to it. A missing file holds no events. On a
+// malformed line (e.g. a write cut short by a crash) it returns the events
+// before it together with the error.
+func ReadFile(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var events []Event
+	dec := json.NewDecoder(f)
+	for {
+		var ev Event
+		if err := dec.Decode(&ev); err == io.EOF {
+			return events, nil
+		} else if err != nil {
+			return events, fmt.Errorf("read %s: event %d: %w", path, len(events)+1, err)
+		}
+		events = append(events, ev)
+	}
+}
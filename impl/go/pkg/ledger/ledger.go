@@ -10,6 +10,8 @@
 //   - JCS (RFC 8785): deterministic canonicalization for cross-platform reproducibility
 //   - Ed25519: institutional signature over hash (transitively covering all fields)
 //   - chain_valid: every query response MUST include this field
+//   - Merkle tree (RFC 6962) over the event hashes, for inclusion and
+//     consistency proofs against signed tree heads
 package ledger
 
 import (
@@ -24,6 +26,8 @@ import (
 	"time"
 
 	"github.com/gowebpki/jcs"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/merkle"
 )
 
 // ─── Constants ─────────────────────────────────────────────────────────────────
@@ -67,13 +71,30 @@ var (
 	// ErrIncompletePayload is returned when payload is missing required fields.
 	ErrIncompletePayload = errors.New("LEDGER-009: incomplete payload")
 
-	// ErrMissingPolicySnapshotRef is returned when an AUTHORIZATION or RISK_EVALUATION
-	// event is missing the required policy_snapshot_ref field (ACP-LEDGER-1.3 §5.2, §5.3).
+	// ErrMissingPolicySnapshotRef is returned when an AUTHORIZATION event is
+	// missing the required policy_snapshot_ref field (ACP-LEDGER-1.3 §5.2).
 	ErrMissingPolicySnapshotRef = errors.New("LEDGER-010: missing policy_snapshot_ref")
 
+	// ErrMissingRiskPolicySnapshotRef is returned when a RISK_EVALUATION event
+	// is missing the required policy_snapshot_ref field (ACP-LEDGER-1.3 §5.3).
+	ErrMissingRiskPolicySnapshotRef = errors.New("LEDGER-011: missing policy_snapshot_ref in risk evaluation")
+
 	// ErrSigMissing is returned when sig is absent or empty on a production event.
 	// Per ACP-LEDGER-1.3 §4.4, sig MUST be present and non-empty.
 	ErrSigMissing = errors.New("LEDGER-012: sig missing or empty")
+
+	// ErrDivergentHistory is returned by Apply when a replicated event
+	// conflicts with an event the replica already holds.
+	ErrDivergentHistory = errors.New("LEDGER-013: divergent history")
+
+	// ErrSegmentUnavailable is returned when an archived segment cannot be
+	// written or read back (see ArchiveBefore).
+	ErrSegmentUnavailable = errors.New("LEDGER-014: archived segment unavailable")
+
+	// ErrBackendUnavailable is returned when the backend fails to commit an
+	// append. Nothing is appended; callers MUST NOT release the operation
+	// the events record (fail closed).
+	ErrBackendUnavailable = errors.New("ledger: backend unavailable")
 )
 
 // ─── Event Types (ACP-LEDGER-1.3 §5) ─────────────────────────────────────────
@@ -101,27 +122,57 @@ const (
 	EventProvenance      = "PROVENANCE"
 	EventPolicySnapshot  = "POLICY_SNAPSHOT"
 	EventGovernance      = "GOVERNANCE"
+
+	// Cross-organizational event types (v1.3 — ACP-CROSS-ORG-1.1)
+	EventCrossOrgInteraction    = "CROSS_ORG_INTERACTION"
+	EventCrossOrgAck            = "CROSS_ORG_ACK"
+	EventCrossOrgPeerRegistered = "CROSS_ORG_PEER_REGISTERED"
+
+	// Agent identity event types
+	EventAgentKeyRotated = "AGENT_KEY_ROTATED"
+
+	// Payment event types (ACP-PAY-1.0 §8)
+	EventPaymentVerified           = "PAYMENT_VERIFIED"
+	EventPaymentProviderRegistered = "PAYMENT_PROVIDER_REGISTERED"
+
+	// Transparency event types (witness co-signed checkpoints)
+	EventCheckpointCosigned = "CHECKPOINT_COSIGNED"
+
+	// Redaction event types (crypto-shredding of sensitive payload fields)
+	EventSubjectShredded = "SUBJECT_SHREDDED"
+
+	// Idempotency event types (request_id de-duplication, ACP-API-1.0 AUTH-004)
+	EventAuthorizationReplayed = "AUTHORIZATION_REPLAYED"
 )
 
 // validEventTypes is the canonical set of recognized event types.
 var validEventTypes = map[string]struct{}{
-	EventLedgerGenesis:          {},
-	EventAuthorization:          {},
-	EventRiskEvaluation:         {},
-	EventRevocation:             {},
-	EventTokenIssued:            {},
-	EventExecutionTokenIssued:   {},
-	EventExecutionTokenConsumed: {},
-	EventAgentRegistered:        {},
-	EventAgentStateChange:       {},
-	EventEscalationCreated:      {},
-	EventEscalationResolved:     {},
-	EventLiabilityRecord:        {},
-	EventPolicySnapshotCreated:  {},
-	EventReputationUpdated:      {},
-	EventProvenance:             {},
-	EventPolicySnapshot:         {},
-	EventGovernance:             {},
+	EventLedgerGenesis:             {},
+	EventAuthorization:             {},
+	EventRiskEvaluation:            {},
+	EventRevocation:                {},
+	EventTokenIssued:               {},
+	EventExecutionTokenIssued:      {},
+	EventExecutionTokenConsumed:    {},
+	EventAgentRegistered:           {},
+	EventAgentStateChange:          {},
+	EventEscalationCreated:         {},
+	EventEscalationResolved:        {},
+	EventLiabilityRecord:           {},
+	EventPolicySnapshotCreated:     {},
+	EventReputationUpdated:         {},
+	EventProvenance:                {},
+	EventPolicySnapshot:            {},
+	EventGovernance:                {},
+	EventCrossOrgInteraction:       {},
+	EventCrossOrgAck:               {},
+	EventCrossOrgPeerRegistered:    {},
+	EventAgentKeyRotated:           {},
+	EventPaymentVerified:           {},
+	EventPaymentProviderRegistered: {},
+	EventCheckpointCosigned:        {},
+	EventSubjectShredded:           {},
+	EventAuthorizationReplayed:     {},
 }
 
 // ─── Structures ───────────────────────────────────────────────────────────────
@@ -139,6 +190,7 @@ type Event struct {
 	PrevHash      string      `json:"prev_hash"`
 	Payload       interface{} `json:"payload"`
 	Hash          string      `json:"hash"`
+	KID           string      `json:"kid,omitempty"` // signing key ID (institution keyring)
 	Sig           string      `json:"sig,omitempty"`
 }
 
@@ -156,7 +208,9 @@ type hashableEvent struct {
 }
 
 // signableEvent is the subset of Event fields covered by the institutional sig (§4.4).
-// Includes hash (so sig transitively covers all fields); excludes sig itself.
+// Includes hash (so sig transitively covers all fields) and kid (so the signing
+// key cannot be substituted); excludes sig itself. kid is omitted when empty,
+// keeping pre-keyring signatures byte-identical.
 type signableEvent struct {
 	Ver           string      `json:"ver"`
 	EventID       string      `json:"event_id"`
@@ -167,8 +221,24 @@ type signableEvent struct {
 	PrevHash      string      `json:"prev_hash"`
 	Payload       interface{} `json:"payload"`
 	Hash          string      `json:"hash"`
+	KID           string      `json:"kid,omitempty"`
 }
 
+// KeyResolver resolves the institutional public key that was valid for a
+// given kid at a given event timestamp. Implemented by keyring.Keyring and
+// keyring.PublishedKeyring.
+//
+// An empty kid identifies events signed before kids were introduced; the
+// resolver returns the key whose validity window covers ts.
+type KeyResolver interface {
+	KeyAt(kid string, ts int64) (ed25519.PublicKey, error)
+}
+
+// staticKey is a KeyResolver that always returns the same key (single-key ledgers).
+type staticKey struct{ pub ed25519.PublicKey }
+
+func (s staticKey) KeyAt(string, int64) (ed25519.PublicKey, error) { return s.pub, nil }
+
 // VerificationError reports a specific problem found during chain verification (§7, §8).
 type VerificationError struct {
 	Code     string `json:"code"`
@@ -182,18 +252,47 @@ func (e VerificationError) Error() string {
 	return fmt.Sprintf("%s [event_id=%s seq=%d]: %s", e.Code, e.EventID, e.Sequence, e.Message)
 }
 
+// Entry is one event to append with AppendAll.
+type Entry struct {
+	EventType string
+	Payload   interface{}
+}
+
+// Backend durably persists ledger events. Commit receives the events of one
+// append, already hashed and signed, before they become visible; if it
+// returns an error the append is aborted and none of them is stored.
+type Backend interface {
+	Commit(events []Event) error
+}
+
 // ─── InMemoryLedger ────────────────────────────────────────────────────────────
 
 // InMemoryLedger is an ACP-LEDGER-1.0 conformant, thread-safe, append-only ledger.
 //
-// Events are stored in a slice ordered by sequence number (1-indexed).
-// An additional map provides O(1) lookup by event_id.
+// Live events are stored in a slice ordered by sequence number (1-indexed);
+// older events may be archived into segments (archive.go). An additional map
+// provides O(1) lookup of live events by event_id.
 type InMemoryLedger struct {
 	mu            sync.RWMutex
-	events        []Event        // ordered by sequence (index 0 = sequence 1)
-	byID          map[string]int // event_id → index in events slice
+	events        []Event          // live events, ordered by sequence (index 0 = sequence base+1)
+	byID          map[string]int64 // event_id → sequence of the live events
+	base          int64            // number of archived events
+	segments      []SegmentCheckpoint
+	archive       ArchiveStore // nil → events are never archived
+	archiveMu     sync.Mutex   // serialises ArchiveBefore
+	verified      int64        // Verify watermark: sequences ≤ verified checked clean
+	verifyGen     uint64       // bumped when the watermark is reset
 	institutionID string
 	privKey       ed25519.PrivateKey // nil → dev mode (events stored unsigned)
+	kid           string             // key ID stamped on signed events ("" = none)
+	resolver      KeyResolver        // nil → verify with privKey's public half
+	backend       Backend            // nil → memory only
+	backendErr    error              // last failed commit; nil once a commit succeeds
+	schema        SchemaMode         // payload schema enforcement (default lenient)
+	tree          *merkle.Tree       // leaf sequence-1 = event at sequence
+	changed       chan struct{}      // closed and replaced on every append
+	replica       bool               // read-only: grows only through Apply
+	redactor      Redactor           // seals sensitive payload fields; nil = cleartext
 }
 
 // NewInMemoryLedger creates a new ledger and emits the mandatory LEDGER_GENESIS event.
@@ -201,10 +300,19 @@ type InMemoryLedger struct {
 // If privKey is non-nil, all events (including genesis) will be signed.
 // If privKey is nil, events are stored unsigned (dev/test mode).
 func NewInMemoryLedger(institutionID string, privKey ed25519.PrivateKey) (*InMemoryLedger, error) {
+	return NewInMemoryLedgerWithKeyID(institutionID, "", privKey)
+}
+
+// NewInMemoryLedgerWithKeyID is like NewInMemoryLedger but stamps every signed
+// event (including genesis) with kid, the ID of privKey in the institution keyring.
+func NewInMemoryLedgerWithKeyID(institutionID, kid string, privKey ed25519.PrivateKey) (*InMemoryLedger, error) {
 	l := &InMemoryLedger{
 		institutionID: institutionID,
 		privKey:       privKey,
-		byID:          make(map[string]int),
+		kid:           kid,
+		byID:          make(map[string]int64),
+		tree:          merkle.NewTree(),
+		changed:       make(chan struct{}),
 	}
 	genesisPayload := map[string]interface{}{
 		"institution_id": institutionID,
@@ -212,7 +320,7 @@ func NewInMemoryLedger(institutionID string, privKey ed25519.PrivateKey) (*InMem
 		"created_at":     time.Now().Unix(),
 		"created_by":     "system",
 	}
-	if _, err := l.appendInternal(EventLedgerGenesis, genesisPayload); err != nil {
+	if _, err := l.appendInternal([]Entry{{EventLedgerGenesis, genesisPayload}}); err != nil {
 		return nil, fmt.Errorf("ledger genesis: %w", err)
 	}
 	return l, nil
@@ -222,35 +330,145 @@ func NewInMemoryLedger(institutionID string, privKey ed25519.PrivateKey) (*InMem
 //
 // Returns ErrUnknownEventType for unrecognized event types.
 // Returns ErrModificationRejected if caller attempts to append LEDGER_GENESIS
-// (genesis is emitted automatically by NewInMemoryLedger).
+// (genesis is emitted automatically by NewInMemoryLedger), or appends to a
+// replica (NewReplica).
+// Returns ErrBackendUnavailable if the backend fails to commit the event.
+// In SchemaStrict mode, returns the CheckPayload error for a non-conformant payload.
 func (l *InMemoryLedger) Append(eventType string, payload interface{}) (Event, error) {
-	if _, ok := validEventTypes[eventType]; !ok {
-		return Event{}, fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+	evs, err := l.AppendAll(Entry{EventType: eventType, Payload: payload})
+	if err != nil {
+		return Event{}, err
 	}
-	if eventType == EventLedgerGenesis {
-		return Event{}, fmt.Errorf("%w: LEDGER_GENESIS may only be emitted at ledger creation", ErrModificationRejected)
+	return evs[0], nil
+}
+
+// AppendAll appends entries atomically and consecutively: either every
+// entry is committed, in order, or none is. Use it for events that together
+// record one operation, so the ledger never holds only part of it.
+func (l *InMemoryLedger) AppendAll(entries ...Entry) ([]Event, error) {
+	if err := l.checkEntries(entries); err != nil {
+		return nil, err
 	}
-	return l.appendInternal(eventType, payload)
+	return l.appendInternal(entries)
 }
 
-// appendInternal is the unsynchronised append used internally (genesis + public Append).
-func (l *InMemoryLedger) appendInternal(eventType string, payload interface{}) (Event, error) {
+// AppendSequenced is AppendAll for entries that refer to the sequences they
+// are recorded at: build receives the sequence the first entry will get and
+// returns the entries. build runs with the ledger locked and must not use it.
+func (l *InMemoryLedger) AppendSequenced(build func(first int64) ([]Entry, error)) ([]Event, error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	_, first := l.head()
+	entries, err := build(first)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.checkEntries(entries); err != nil {
+		return nil, err
+	}
+	return l.appendLocked(entries)
+}
 
-	// Determine prev_hash and sequence.
-	var prevHash string
-	var sequence int64
+// checkEntries rejects appends to replicas, unknown event types and genesis.
+func (l *InMemoryLedger) checkEntries(entries []Entry) error {
+	if l.replica {
+		return fmt.Errorf("%w: replica ledgers are read-only", ErrModificationRejected)
+	}
+	for _, e := range entries {
+		if _, ok := validEventTypes[e.EventType]; !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownEventType, e.EventType)
+		}
+		if e.EventType == EventLedgerGenesis {
+			return fmt.Errorf("%w: LEDGER_GENESIS may only be emitted at ledger creation", ErrModificationRejected)
+		}
+	}
+	return nil
+}
+
+// head returns the prev_hash and sequence of the next event. Caller must hold l.mu.
+func (l *InMemoryLedger) head() (prevHash string, sequence int64) {
 	if len(l.events) == 0 {
 		// Genesis: use the spec-defined constant.
-		prevHash = GenesisHash
-		sequence = 1
-	} else {
-		last := l.events[len(l.events)-1]
-		prevHash = last.Hash
-		sequence = last.Sequence + 1
+		return GenesisHash, 1
+	}
+	last := l.events[len(l.events)-1]
+	return last.Hash, last.Sequence + 1
+}
+
+// appendInternal is the unsynchronised append used internally (genesis + public Append).
+func (l *InMemoryLedger) appendInternal(entries []Entry) ([]Event, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.appendLocked(entries)
+}
+
+// appendLocked appends entries. Caller must hold l.mu.
+func (l *InMemoryLedger) appendLocked(entries []Entry) ([]Event, error) {
+	prevHash, sequence := l.head()
+
+	evs := make([]Event, 0, len(entries))
+	for _, e := range entries {
+		if l.schema == SchemaStrict {
+			if err := CheckPayload(e.EventType, e.Payload); err != nil {
+				return nil, err
+			}
+		}
+		if l.redactor != nil {
+			sealed, err := sealPayload(l.redactor, e.EventType, e.Payload)
+			if err != nil {
+				return nil, err
+			}
+			e.Payload = sealed
+		}
+		ev, err := l.newEvent(e, prevHash, sequence)
+		if err != nil {
+			return nil, err
+		}
+		evs = append(evs, ev)
+		prevHash, sequence = ev.Hash, sequence+1
 	}
 
+	// Commit durably before the events become visible.
+	if l.backend != nil {
+		if err := l.backend.Commit(evs); err != nil {
+			l.backendErr = err
+			return nil, fmt.Errorf("%w: %v", ErrBackendUnavailable, err)
+		}
+		l.backendErr = nil
+	}
+
+	// Store immutably.
+	for _, ev := range evs {
+		l.store(ev)
+	}
+	l.notify()
+	return evs, nil
+}
+
+// store makes ev visible. Caller must hold l.mu.
+func (l *InMemoryLedger) store(ev Event) {
+	l.byID[ev.EventID] = ev.Sequence
+	l.events = append(l.events, ev)
+	l.tree.Append(LeafFromHash(ev.Hash))
+}
+
+// notify wakes the readers waiting on Changed. Caller must hold l.mu.
+func (l *InMemoryLedger) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Changed returns a channel that is closed when the next event is stored.
+// Readers that follow the ledger take the channel before reading new events,
+// then wait on it.
+func (l *InMemoryLedger) Changed() <-chan struct{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.changed
+}
+
+// newEvent builds, hashes and signs the event at sequence following prevHash.
+func (l *InMemoryLedger) newEvent(e Entry, prevHash string, sequence int64) (Event, error) {
 	// Generate a UUID v4 for event_id.
 	eventID, err := newUUID()
 	if err != nil {
@@ -261,12 +479,12 @@ func (l *InMemoryLedger) appendInternal(eventType string, payload interface{}) (
 	h := hashableEvent{
 		Ver:           Version,
 		EventID:       eventID,
-		EventType:     eventType,
+		EventType:     e.EventType,
 		Sequence:      sequence,
 		Timestamp:     time.Now().Unix(),
 		InstitutionID: l.institutionID,
 		PrevHash:      prevHash,
-		Payload:       payload,
+		Payload:       e.Payload,
 	}
 	hash, err := computeHashFromHashable(h)
 	if err != nil {
@@ -288,56 +506,127 @@ func (l *InMemoryLedger) appendInternal(eventType string, payload interface{}) (
 
 	// Sign if private key is available (§4.4).
 	if l.privKey != nil {
+		ev.KID = l.kid
 		sig, err := signEventFields(ev, l.privKey)
 		if err != nil {
 			return Event{}, fmt.Errorf("event signing: %w", err)
 		}
 		ev.Sig = sig
 	}
+	return ev, nil
+}
+
+// ─── Backend ──────────────────────────────────────────────────────────────────
+
+// SetBackend attaches b, first committing to it the live events already in
+// the ledger (at least the genesis, unless archived). If that commit fails, b
+// is not attached.
+func (l *InMemoryLedger) SetBackend(b Backend) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := b.Commit(append([]Event(nil), l.events...)); err != nil {
+		return fmt.Errorf("%w: %v", ErrBackendUnavailable, err)
+	}
+	l.backend = b
+	l.backendErr = nil
+	return nil
+}
 
-	// Store immutably.
-	idx := len(l.events)
-	l.events = append(l.events, ev)
-	l.byID[eventID] = idx
+// BackendErr returns the error of the last failed backend commit, or nil if
+// the last commit succeeded (or no backend is attached).
+func (l *InMemoryLedger) BackendErr() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.backendErr
+}
 
-	return ev, nil
+// SetSchemaMode selects how payload schemas are enforced on subsequent
+// appends and by Verify. The default, SchemaLenient, accepts any payload.
+func (l *InMemoryLedger) SetSchemaMode(m SchemaMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.schema = m
+	l.resetVerificationLocked()
+}
+
+// ─── Key Management ───────────────────────────────────────────────────────────
+
+// SetSigningKey switches the key used to sign subsequently appended events.
+// Called after an institutional key rotation; events already in the ledger
+// keep their original kid and signature.
+//
+// A KeyResolver MUST be configured (SetKeyResolver) before rotating, otherwise
+// Verify would check old events against the new key.
+func (l *InMemoryLedger) SetSigningKey(kid string, privKey ed25519.PrivateKey) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.kid = kid
+	l.privKey = privKey
+}
+
+// RotateSigningKey switches to the key returned by rotate, which is called
+// with appends blocked: typically keyring.Keyring.Rotate, which closes the
+// window of the old key. No event can then be signed by the old key after
+// its window, even with no overlap. The key is kept if rotate fails.
+func (l *InMemoryLedger) RotateSigningKey(rotate func() (kid string, privKey ed25519.PrivateKey, err error)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	kid, privKey, err := rotate()
+	if err != nil {
+		return err
+	}
+	l.kid = kid
+	l.privKey = privKey
+	return nil
+}
+
+// SetKeyResolver configures how Verify and VerifyEvent select the public key
+// for each event: by the event's kid and timestamp.
+func (l *InMemoryLedger) SetKeyResolver(r KeyResolver) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resolver = r
+	l.resetVerificationLocked()
 }
 
 // ─── Query Methods ────────────────────────────────────────────────────────────
 
-// Get returns the event with the given event_id.
-// Returns false if not found.
+// Get returns the event with the given event_id, reading the archived
+// segments if it is not live. Returns false if not found.
 func (l *InMemoryLedger) Get(eventID string) (Event, bool) {
 	l.mu.RLock()
-	defer l.mu.RUnlock()
-	idx, ok := l.byID[eventID]
-	if !ok {
-		return Event{}, false
+	if seq, ok := l.byID[eventID]; ok {
+		defer l.mu.RUnlock()
+		return l.events[seq-1-l.base], true
 	}
-	return l.events[idx], true
+	store, segs := l.archive, l.segments
+	l.mu.RUnlock()
+
+	ev, _, ok := findArchived(store, segs, eventID)
+	return ev, ok
 }
 
 // GetBySequence returns the event at the given sequence number (1-based).
 // Returns false if out of range.
 func (l *InMemoryLedger) GetBySequence(seq int64) (Event, bool) {
-	l.mu.RLock()
-	defer l.mu.RUnlock()
-	if seq < 1 || int(seq) > len(l.events) {
+	events := l.List(seq, seq)
+	if seq < 1 || len(events) != 1 {
 		return Event{}, false
 	}
-	return l.events[seq-1], true
+	return events[0], true
 }
 
-// List returns events with sequence in [fromSeq, toSeq] (inclusive, 1-based).
+// List returns events with sequence in [fromSeq, toSeq] (inclusive, 1-based),
+// reading the archived segments the range reaches into.
 //
 // fromSeq ≤ 0 is treated as 1 (beginning of ledger).
 // toSeq ≤ 0 is treated as the last sequence in the ledger.
 // Returns nil if the ledger is empty or fromSeq > toSeq.
 func (l *InMemoryLedger) List(fromSeq, toSeq int64) []Event {
 	l.mu.RLock()
-	defer l.mu.RUnlock()
-	n := int64(len(l.events))
+	n := l.base + int64(len(l.events))
 	if n == 0 {
+		l.mu.RUnlock()
 		return nil
 	}
 	if fromSeq <= 0 {
@@ -347,78 +636,165 @@ func (l *InMemoryLedger) List(fromSeq, toSeq int64) []Event {
 		toSeq = n
 	}
 	if fromSeq > toSeq {
+		l.mu.RUnlock()
 		return nil
 	}
-	// Sequences are 1-based; slice is 0-based.
-	start := int(fromSeq - 1)
-	end := int(toSeq)
-	result := make([]Event, end-start)
-	copy(result, l.events[start:end])
-	return result
+	// Sequences are 1-based; the live slice starts at base+1.
+	var live []Event
+	if toSeq > l.base {
+		start := max(fromSeq, l.base+1) - 1 - l.base
+		end := toSeq - l.base
+		live = make([]Event, end-start)
+		copy(live, l.events[start:end])
+	}
+	if fromSeq > l.base {
+		l.mu.RUnlock()
+		return live
+	}
+	store, segs, base := l.archive, l.segments, l.base
+	l.mu.RUnlock()
+
+	return append(readArchived(store, segs, fromSeq, min(toSeq, base)), live...)
 }
 
-// Size returns the total number of events currently in the ledger.
+// Size returns the total number of events in the ledger, archived included.
 func (l *InMemoryLedger) Size() int {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
-	return len(l.events)
+	return int(l.base) + len(l.events)
 }
 
 // ─── Verification ─────────────────────────────────────────────────────────────
 
 // Verify performs a full chain verification (ACP-LEDGER-1.0 §7).
 //
+// Verification is incremental (verify.go): only the events after
+// VerifiedThrough are checked, as the continuation of the last verified
+// event. Once events have been archived, verification starts at the latest
+// segment checkpoint: its signature is checked, and the live events must
+// extend it. VerifyArchive verifies the archived segments.
+//
 // Returns a slice of VerificationErrors; an empty slice means the chain is valid.
 // Per §8, verification continues past the first error to identify full corruption scope.
 func (l *InMemoryLedger) Verify() []VerificationError {
 	l.mu.RLock()
-	events := make([]Event, len(l.events))
-	copy(events, l.events)
-	pubKey := l.derivePubKey()
+	keys := l.keyResolver()
+	mode := l.schema
+	gen := l.verifyGen
+	start := max(l.verified-l.base, 0) // index of the first unverified live event
+	events := make([]Event, int64(len(l.events))-start)
+	copy(events, l.events[start:])
+	var anchor *Event
+	var cp *SegmentCheckpoint
+	switch {
+	case start > 0:
+		a := l.events[start-1]
+		anchor = &a
+	case len(l.segments) > 0:
+		c := l.segments[len(l.segments)-1]
+		a := anchorEvent(c)
+		cp, anchor = &c, &a
+	}
 	l.mu.RUnlock()
 
-	return verifyChain(events, pubKey)
+	errs := verifyChainFrom(events, anchor, keys, mode)
+	if cp != nil {
+		errs = append(verifyCheckpointSig(*cp, keys), errs...)
+	}
+	l.advanceVerified(gen, events, errs)
+	return errs
+}
+
+// VerifyEvents verifies an externally supplied, ordered event chain (e.g. an
+// imported ledger or a replicated stream) with the same checks as Verify on
+// a SchemaLenient ledger.
+//
+// keys selects the public key per event by kid and timestamp; nil skips
+// signature checks (unsigned dev ledgers).
+func VerifyEvents(events []Event, keys KeyResolver) []VerificationError {
+	return verifyChain(events, keys, SchemaLenient)
+}
+
+// VerifyEventsWithSchema is like VerifyEvents but checks payloads in the given
+// mode; SchemaStrict reports every payload that fails CheckPayload.
+func VerifyEventsWithSchema(events []Event, keys KeyResolver, mode SchemaMode) []VerificationError {
+	return verifyChain(events, keys, mode)
+}
+
+// CheckEvent verifies a single event outside of a full chain walk: type,
+// payload, signature and hash, plus linkage to prev when prev is non-nil.
+// Used for exported subsets and incremental verification of new events.
+// Payloads are checked in SchemaLenient mode.
+func CheckEvent(ev Event, prev *Event, keys KeyResolver) []VerificationError {
+	return verifySingleEvent(ev, prev, keys, SchemaLenient)
 }
 
 // VerifyEvent verifies a single event by event_id and returns any errors found.
 //
-// The predecessor event is loaded automatically to check prev_hash and sequence.
-// Returns LEDGER-007 if the event_id is not found.
+// The predecessor event is loaded automatically to check prev_hash and sequence;
+// for the first event of a segment, or the first live event, it is the end
+// of the previous segment checkpoint. Archived events are read back from
+// their segment. Returns LEDGER-007 if the event_id is not found.
 func (l *InMemoryLedger) VerifyEvent(eventID string) (Event, []VerificationError) {
 	l.mu.RLock()
-	idx, ok := l.byID[eventID]
-	if !ok {
-		l.mu.RUnlock()
-		return Event{}, []VerificationError{{
-			Code:    "LEDGER-007",
-			EventID: eventID,
-			Message: "event not found",
-		}}
-	}
-	ev := l.events[idx]
+	keys := l.keyResolver()
+	mode := l.schema
+	seq, live := l.byID[eventID]
+	var ev Event
 	var prev *Event
-	if idx > 0 {
-		p := l.events[idx-1]
-		prev = &p
+	if live {
+		idx := seq - 1 - l.base
+		ev = l.events[idx]
+		switch {
+		case idx > 0:
+			p := l.events[idx-1]
+			prev = &p
+		case len(l.segments) > 0:
+			p := anchorEvent(l.segments[len(l.segments)-1])
+			prev = &p
+		}
 	}
-	pubKey := l.derivePubKey()
+	store, segs := l.archive, l.segments
 	l.mu.RUnlock()
 
-	return ev, verifySingleEvent(ev, prev, pubKey)
+	if !live {
+		var ok bool
+		if ev, prev, ok = findArchived(store, segs, eventID); !ok {
+			return Event{}, []VerificationError{{
+				Code:    "LEDGER-007",
+				EventID: eventID,
+				Message: "event not found",
+			}}
+		}
+	}
+	return ev, verifySingleEvent(ev, prev, keys, mode)
 }
 
-// derivePubKey extracts the Ed25519 public key from the stored private key, or returns nil.
-func (l *InMemoryLedger) derivePubKey() ed25519.PublicKey {
+// keyResolver returns the configured KeyResolver, or a static resolver over the
+// public half of the signing key, or nil in dev mode. Caller must hold l.mu.
+func (l *InMemoryLedger) keyResolver() KeyResolver {
+	if l.resolver != nil {
+		return l.resolver
+	}
 	if l.privKey == nil {
 		return nil
 	}
-	return l.privKey.Public().(ed25519.PublicKey)
+	return staticKey{pub: l.privKey.Public().(ed25519.PublicKey)}
 }
 
 // ─── Chain Verification Helpers ───────────────────────────────────────────────
 
+// verifyChainFrom verifies events as the continuation of anchor, the last
+// event of an archived segment; anchor == nil verifies a full chain.
+func verifyChainFrom(events []Event, anchor *Event, keys KeyResolver, mode SchemaMode) []VerificationError {
+	if anchor == nil {
+		return verifyChain(events, keys, mode)
+	}
+	return verifyRun(events, anchor, keys, mode)
+}
+
 // verifyChain verifies the full ordered event chain (§7 "Verificación completa").
-func verifyChain(events []Event, pubKey ed25519.PublicKey) []VerificationError {
+func verifyChain(events []Event, keys KeyResolver, mode SchemaMode) []VerificationError {
 	var errs []VerificationError
 
 	// Empty ledger → missing genesis.
@@ -449,27 +825,24 @@ func verifyChain(events []Event, pubKey ed25519.PublicKey) []VerificationError {
 	}
 
 	// Per-event checks (§7 steps 1–6).
-	for i, ev := range events {
-		prev := prevEventOrNil(events, i)
-		evErrs := verifySingleEvent(ev, prev, pubKey)
-		errs = append(errs, evErrs...)
-	}
-
-	return errs
-}
-
-func prevEventOrNil(events []Event, i int) *Event {
-	if i == 0 {
-		return nil
-	}
-	p := events[i-1]
-	return &p
+	return append(errs, verifyRun(events, nil, keys, mode)...)
 }
 
 // verifySingleEvent verifies one event according to §7 steps 1–6.
 //
 // Steps 3–6 (chain linkage) are only checked when prev is non-nil.
-func verifySingleEvent(ev Event, prev *Event, pubKey ed25519.PublicKey) []VerificationError {
+// keys == nil skips signature checks (dev mode). mode selects the payload checks.
+func verifySingleEvent(ev Event, prev *Event, keys KeyResolver, mode SchemaMode) []VerificationError {
+	errs := checkEvent(ev, keys, mode)
+	if prev != nil {
+		errs = append(errs, checkLink(ev, *prev)...)
+	}
+	return errs
+}
+
+// checkEvent runs the checks of one event that need no predecessor (§7 steps
+// 0–2: type, payload, signature, hash); verifyRun runs them in parallel.
+func checkEvent(ev Event, keys KeyResolver, mode SchemaMode) []VerificationError {
 	var errs []VerificationError
 
 	// Step 0a: Verify event_type is in the registered set (LEDGER-008).
@@ -480,8 +853,19 @@ func verifySingleEvent(ev Event, prev *Event, pubKey ed25519.PublicKey) []Verifi
 		})
 	}
 
-	// Step 0b: Verify payload completeness for typed events (LEDGER-010, LEDGER-011).
-	if ev.EventType == EventAuthorization || ev.EventType == EventRiskEvaluation {
+	// Step 0b: Verify payload completeness (LEDGER-009, LEDGER-010, LEDGER-011).
+	// Strict mode checks the full §5 schema; lenient mode only reports the
+	// v1.1 policy_snapshot_ref, whose absence marks a legacy v1.0 event (§14).
+	if mode == SchemaStrict {
+		if _, known := validEventTypes[ev.EventType]; known {
+			if err := CheckPayload(ev.EventType, ev.Payload); err != nil {
+				errs = append(errs, VerificationError{
+					Code: errorCode(err), EventID: ev.EventID, Sequence: ev.Sequence,
+					Message: err.Error(),
+				})
+			}
+		}
+	} else if ev.EventType == EventAuthorization || ev.EventType == EventRiskEvaluation {
 		raw, _ := json.Marshal(ev.Payload)
 		var m map[string]interface{}
 		if json.Unmarshal(raw, &m) == nil {
@@ -499,16 +883,23 @@ func verifySingleEvent(ev Event, prev *Event, pubKey ed25519.PublicKey) []Verifi
 	}
 
 	// Step 1a: Check sig presence (LEDGER-012). Per §4.4, sig MUST be non-empty in production.
-	if pubKey != nil && ev.Sig == "" {
+	if keys != nil && ev.Sig == "" {
 		errs = append(errs, VerificationError{
 			Code: "LEDGER-012", EventID: ev.EventID, Sequence: ev.Sequence,
 			Message: "sig field missing or empty (MUST per ACP-LEDGER-1.3 §4.4)",
 		})
 	}
 
-	// Step 1b: Verify institutional signature value (LEDGER-002).
-	if pubKey != nil && ev.Sig != "" {
-		if err := verifyEventSig(ev, pubKey); err != nil {
+	// Step 1b: Resolve the signing key by (kid, timestamp) and verify the
+	// institutional signature value (LEDGER-002).
+	if keys != nil && ev.Sig != "" {
+		pubKey, err := keys.KeyAt(ev.KID, ev.Timestamp)
+		if err != nil {
+			errs = append(errs, VerificationError{
+				Code: "LEDGER-002", EventID: ev.EventID, Sequence: ev.Sequence,
+				Message: fmt.Sprintf("signing key not resolvable: %v", err),
+			})
+		} else if err := verifyEventSig(ev, pubKey); err != nil {
 			errs = append(errs, VerificationError{
 				Code: "LEDGER-002", EventID: ev.EventID, Sequence: ev.Sequence,
 				Message: "institutional signature verification failed",
@@ -530,31 +921,35 @@ func verifySingleEvent(ev Event, prev *Event, pubKey ed25519.PublicKey) []Verifi
 		})
 	}
 
-	// Steps 3–6: chain linkage (requires predecessor).
-	if prev != nil {
-		// Step 3: prev_hash must equal predecessor's hash.
-		if ev.PrevHash != prev.Hash {
-			errs = append(errs, VerificationError{
-				Code: "LEDGER-004", EventID: ev.EventID, Sequence: ev.Sequence,
-				Message: fmt.Sprintf("prev_hash %q != predecessor hash %q", ev.PrevHash, prev.Hash),
-			})
-		}
-		// Step 4: sequence must be predecessor + 1.
-		if ev.Sequence != prev.Sequence+1 {
-			errs = append(errs, VerificationError{
-				Code: "LEDGER-005", EventID: ev.EventID, Sequence: ev.Sequence,
-				Message: fmt.Sprintf("sequence %d != prev_sequence %d +1", ev.Sequence, prev.Sequence),
-			})
-		}
-		// Step 5: timestamp must be non-regressing.
-		if ev.Timestamp < prev.Timestamp {
-			errs = append(errs, VerificationError{
-				Code: "LEDGER-006", EventID: ev.EventID, Sequence: ev.Sequence,
-				Message: fmt.Sprintf("timestamp %d < prev_timestamp %d", ev.Timestamp, prev.Timestamp),
-			})
-		}
-	}
+	return errs
+}
 
+// checkLink runs the chain linkage checks of ev against its predecessor
+// (§7 steps 3–6).
+func checkLink(ev, prev Event) []VerificationError {
+	var errs []VerificationError
+
+	// Step 3: prev_hash must equal predecessor's hash.
+	if ev.PrevHash != prev.Hash {
+		errs = append(errs, VerificationError{
+			Code: "LEDGER-004", EventID: ev.EventID, Sequence: ev.Sequence,
+			Message: fmt.Sprintf("prev_hash %q != predecessor hash %q", ev.PrevHash, prev.Hash),
+		})
+	}
+	// Step 4: sequence must be predecessor + 1.
+	if ev.Sequence != prev.Sequence+1 {
+		errs = append(errs, VerificationError{
+			Code: "LEDGER-005", EventID: ev.EventID, Sequence: ev.Sequence,
+			Message: fmt.Sprintf("sequence %d != prev_sequence %d +1", ev.Sequence, prev.Sequence),
+		})
+	}
+	// Step 5: timestamp must be non-regressing.
+	if ev.Timestamp < prev.Timestamp {
+		errs = append(errs, VerificationError{
+			Code: "LEDGER-006", EventID: ev.EventID, Sequence: ev.Sequence,
+			Message: fmt.Sprintf("timestamp %d < prev_timestamp %d", ev.Timestamp, prev.Timestamp),
+		})
+	}
 	return errs
 }
 
@@ -602,6 +997,7 @@ func signEventFields(ev Event, privKey ed25519.PrivateKey) (string, error) {
 		PrevHash:      ev.PrevHash,
 		Payload:       ev.Payload,
 		Hash:          ev.Hash,
+		KID:           ev.KID,
 	}
 	raw, err := json.Marshal(se)
 	if err != nil {
@@ -632,6 +1028,7 @@ func verifyEventSig(ev Event, pubKey ed25519.PublicKey) error {
 		PrevHash:      ev.PrevHash,
 		Payload:       ev.Payload,
 		Hash:          ev.Hash,
+		KID:           ev.KID,
 	}
 	raw, err := json.Marshal(se)
 	if err != nil {
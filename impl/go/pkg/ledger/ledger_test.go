@@ -3,10 +3,19 @@ package ledger_test
 import (
 	"crypto/ed25519"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/chelof100/acp-framework/acp-go/pkg/merkle"
+	"github.com/chelof100/acp-framework/acp-go/pkg/shred"
 )
 
 // ─── Helpers ──────────────────────────────────────────────────────────────────
@@ -509,3 +518,652 @@ func TestSize_IncrementsOnAppend(t *testing.T) {
 		}
 	}
 }
+
+// ─── Backend ──────────────────────────────────────────────────────────────────
+
+// faultyBackend records committed events and fails while down is set.
+type faultyBackend struct {
+	down      bool
+	committed []ledger.Event
+}
+
+func (b *faultyBackend) Commit(events []ledger.Event) error {
+	if b.down {
+		return errors.New("disk unavailable")
+	}
+	b.committed = append(b.committed, events...)
+	return nil
+}
+
+func TestBackend_FailedCommitAppendsNothing(t *testing.T) {
+	l := newUnsignedLedger(t)
+	b := &faultyBackend{}
+	if err := l.SetBackend(b); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.committed) != 1 || b.committed[0].EventType != ledger.EventLedgerGenesis {
+		t.Fatalf("SetBackend committed %d events, want genesis", len(b.committed))
+	}
+
+	b.down = true
+	_, err := l.AppendAll(
+		ledger.Entry{EventType: ledger.EventAuthorization, Payload: map[string]interface{}{"req": "1"}},
+		ledger.Entry{EventType: ledger.EventExecutionTokenIssued, Payload: map[string]interface{}{"et_id": "et-1"}},
+	)
+	if !errors.Is(err, ledger.ErrBackendUnavailable) {
+		t.Fatalf("err = %v, want ErrBackendUnavailable", err)
+	}
+	if l.Size() != 1 || l.BackendErr() == nil {
+		t.Errorf("after failed commit: Size = %d, BackendErr = %v", l.Size(), l.BackendErr())
+	}
+
+	b.down = false
+	evs, err := l.AppendAll(
+		ledger.Entry{EventType: ledger.EventAuthorization, Payload: map[string]interface{}{"req": "2"}},
+		ledger.Entry{EventType: ledger.EventExecutionTokenIssued, Payload: map[string]interface{}{"et_id": "et-2"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evs[0].Sequence != 2 || evs[1].PrevHash != evs[0].Hash || l.BackendErr() != nil {
+		t.Errorf("recovered append: seq %d, chained %v, BackendErr %v",
+			evs[0].Sequence, evs[1].PrevHash == evs[0].Hash, l.BackendErr())
+	}
+	if len(b.committed) != 3 {
+		t.Errorf("committed %d events, want 3", len(b.committed))
+	}
+}
+
+func TestAppendAll_RejectsWholeBatch(t *testing.T) {
+	l := newUnsignedLedger(t)
+	_, err := l.AppendAll(
+		ledger.Entry{EventType: ledger.EventAuthorization},
+		ledger.Entry{EventType: "NOT_A_REAL_TYPE"},
+	)
+	if !errors.Is(err, ledger.ErrUnknownEventType) || l.Size() != 1 {
+		t.Errorf("err = %v, Size = %d", err, l.Size())
+	}
+}
+
+func TestFileBackend_WritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	l := newUnsignedLedger(t)
+	if err := l.SetBackend(ledger.NewFileBackend(path)); err != nil {
+		t.Fatal(err)
+	}
+	ev, err := l.Append(ledger.EventAuthorization, map[string]interface{}{"req": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var last ledger.Event
+	if len(lines) != 2 || json.Unmarshal([]byte(lines[1]), &last) != nil || last.Hash != ev.Hash {
+		t.Fatalf("file holds %d lines, last = %+v", len(lines), last)
+	}
+
+	// A directory in place of the file makes commits fail until it is removed.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(ledger.EventAuthorization, nil); !errors.Is(err, ledger.ErrBackendUnavailable) {
+		t.Errorf("err = %v, want ErrBackendUnavailable", err)
+	}
+	os.Remove(path)
+	if _, err := l.Append(ledger.EventAuthorization, nil); err != nil {
+		t.Errorf("after recovery: %v", err)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	if evs, err := ledger.ReadFile(path); evs != nil || err != nil {
+		t.Fatalf("missing file = %v, %v", evs, err)
+	}
+	l := newUnsignedLedger(t)
+	if err := l.SetBackend(ledger.NewFileBackend(path)); err != nil {
+		t.Fatal(err)
+	}
+	ev, _ := l.Append(ledger.EventAuthorization, map[string]interface{}{"req": "1"})
+	evs, err := ledger.ReadFile(path)
+	if err != nil || len(evs) != 2 || evs[1].Hash != ev.Hash {
+		t.Fatalf("ReadFile = %d events, %v", len(evs), err)
+	}
+
+	// A cut-short last line keeps the events before it.
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	f.WriteString(`{"event_id":"trunc`)
+	f.Close()
+	if evs, err := ledger.ReadFile(path); err == nil || len(evs) != 2 {
+		t.Errorf("truncated file = %d events, %v", len(evs), err)
+	}
+}
+
+// ─── Payload schemas (§5) ─────────────────────────────────────────────────────
+
+func TestCheckPayload(t *testing.T) {
+	authz := ledger.AuthorizationPayload{
+		RequestID: "req-1", AgentID: "agent-1", Capability: "acp:cap:data.read",
+		Resource: "metrics/public", Decision: "APPROVED", RiskScore: 10,
+		PolicySnapshotRef: "snap-1", PolicyVersion: "v1",
+	}
+	if err := ledger.CheckPayload(ledger.EventAuthorization, authz); err != nil {
+		t.Errorf("typed payload: %v", err)
+	}
+
+	cases := []struct {
+		eventType string
+		payload   interface{}
+		want      error
+	}{
+		{ledger.EventAuthorization, map[string]interface{}{"request_id": "req-1"}, ledger.ErrMissingPolicySnapshotRef},
+		{ledger.EventRiskEvaluation, map[string]interface{}{"eval_id": "e"}, ledger.ErrMissingRiskPolicySnapshotRef},
+		{ledger.EventAuthorization, map[string]interface{}{"policy_snapshot_ref": "snap-1"}, ledger.ErrIncompletePayload},
+		{ledger.EventRevocation, nil, ledger.ErrIncompletePayload},
+		{ledger.EventRevocation, "token-1", ledger.ErrIncompletePayload},
+		{"UNKNOWN", map[string]interface{}{}, ledger.ErrUnknownEventType},
+	}
+	for _, c := range cases {
+		if err := ledger.CheckPayload(c.eventType, c.payload); !errors.Is(err, c.want) {
+			t.Errorf("%s %v: err = %v, want %v", c.eventType, c.payload, err, c.want)
+		}
+	}
+
+	// null satisfies a required field; absence does not.
+	snap := map[string]interface{}{
+		"snapshot_id": "s", "policy_version": "v", "effective_from": 1,
+		"previous_snapshot_id": nil, "created_by": "admin",
+	}
+	if err := ledger.CheckPayload(ledger.EventPolicySnapshotCreated, snap); err != nil {
+		t.Errorf("null previous_snapshot_id: %v", err)
+	}
+	delete(snap, "previous_snapshot_id")
+	err := ledger.CheckPayload(ledger.EventPolicySnapshotCreated, snap)
+	if !errors.Is(err, ledger.ErrIncompletePayload) || !strings.Contains(err.Error(), "previous_snapshot_id") {
+		t.Errorf("missing previous_snapshot_id: err = %v", err)
+	}
+}
+
+func TestSchemaMode_StrictRejectsAtAppend(t *testing.T) {
+	l := newUnsignedLedger(t)
+	l.SetSchemaMode(ledger.SchemaStrict)
+	_, err := l.AppendAll(
+		ledger.Entry{EventType: ledger.EventRevocation, Payload: ledger.RevocationPayload{RevocationID: "r"}},
+		ledger.Entry{EventType: ledger.EventAuthorization, Payload: map[string]interface{}{"request_id": "req-1"}},
+	)
+	if !errors.Is(err, ledger.ErrMissingPolicySnapshotRef) || l.Size() != 1 {
+		t.Errorf("err = %v, Size = %d; want LEDGER-010 and nothing appended", err, l.Size())
+	}
+	if _, err := l.Append(ledger.EventRevocation, ledger.RevocationPayload{RevocationID: "r"}); err != nil {
+		t.Errorf("conformant payload: %v", err)
+	}
+}
+
+func TestSchemaMode_VerifyImportedLedger(t *testing.T) {
+	// A legacy ledger written without schema enforcement.
+	l := newUnsignedLedger(t)
+	if _, err := l.Append(ledger.EventRevocation, map[string]interface{}{"target_id": "tok-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(ledger.EventAuthorization, map[string]interface{}{"request_id": "req-1"}); err != nil {
+		t.Fatal(err)
+	}
+	events := l.List(1, 0)
+
+	// Lenient: only the legacy v1.0 AUTHORIZATION is reported.
+	errs := ledger.VerifyEvents(events, nil)
+	if len(errs) != 1 || errs[0].Code != "LEDGER-010" {
+		t.Errorf("lenient: %v", errs)
+	}
+
+	// Strict: the incomplete REVOCATION is reported too.
+	errs = ledger.VerifyEventsWithSchema(events, nil, ledger.SchemaStrict)
+	codes := map[string]int64{}
+	for _, e := range errs {
+		codes[e.Code] = e.Sequence
+	}
+	if len(errs) != 2 || codes["LEDGER-009"] != 2 || codes["LEDGER-010"] != 3 {
+		t.Errorf("strict: %v", errs)
+	}
+	l.SetSchemaMode(ledger.SchemaStrict)
+	if got := l.Verify(); len(got) != 2 {
+		t.Errorf("Verify in strict mode: %v", got)
+	}
+}
+
+// pubKeyResolver resolves every kid to the same public key.
+type pubKeyResolver ed25519.PublicKey
+
+func (k pubKeyResolver) KeyAt(string, int64) (ed25519.PublicKey, error) {
+	return ed25519.PublicKey(k), nil
+}
+
+// TestComplianceVectors verifies the TS-LEDGER-* vectors: the original ones
+// with lenient payload checks, the TS-LEDGER-STRICT-* ones in strict mode.
+func TestComplianceVectors(t *testing.T) {
+	paths, _ := filepath.Glob("../../../../compliance/test-vectors/TS-LEDGER-*.json")
+	if len(paths) == 0 {
+		t.Skip("compliance test vectors not found")
+	}
+	for _, path := range paths {
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".json"), func(t *testing.T) {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var v struct {
+				Input struct {
+					InstitutionPublicKey string         `json:"institution_public_key"`
+					SchemaMode           string         `json:"schema_mode"`
+					Events               []ledger.Event `json:"events"`
+				} `json:"input"`
+				Expected struct {
+					Decision  string `json:"decision"`
+					ErrorCode string `json:"error_code"`
+				} `json:"expected"`
+			}
+			if err := json.Unmarshal(raw, &v); err != nil {
+				t.Fatal(err)
+			}
+			pub, err := base64.RawURLEncoding.DecodeString(v.Input.InstitutionPublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mode := ledger.SchemaLenient
+			if v.Input.SchemaMode == "strict" {
+				mode = ledger.SchemaStrict
+			}
+			errs := ledger.VerifyEventsWithSchema(v.Input.Events, pubKeyResolver(pub), mode)
+			if v.Expected.Decision == "VALID" {
+				if len(errs) != 0 {
+					t.Errorf("want VALID, got %v", errs)
+				}
+				return
+			}
+			for _, e := range errs {
+				if e.Code == v.Expected.ErrorCode {
+					return
+				}
+			}
+			t.Errorf("want %s, got %v", v.Expected.ErrorCode, errs)
+		})
+	}
+}
+
+func TestRequiredFields(t *testing.T) {
+	got := ledger.RequiredFields(ledger.EventExecutionTokenConsumed)
+	want := "agent_id,authorization_id,consumed_at,consumed_by_system,et_id,execution_result"
+	if strings.Join(got, ",") != want {
+		t.Errorf("RequiredFields = %v, want %s", got, want)
+	}
+	if ledger.RequiredFields("UNKNOWN") != nil {
+		t.Error("unknown event type has required fields")
+	}
+}
+
+// ─── Merkle transparency log ──────────────────────────────────────────────────
+
+func TestTree_InclusionAndConsistency(t *testing.T) {
+	pub, priv := newTestKey(t)
+	l := newSignedLedger(t, priv)
+	for i := 0; i < 4; i++ {
+		if _, err := l.Append(ledger.EventAuthorization, map[string]interface{}{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old, err := l.TreeHead(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := l.TreeHead(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.TreeSize != 5 || merkle.VerifyTreeHead(head, pub) != nil || merkle.VerifyTreeHead(old, pub) != nil {
+		t.Fatalf("tree heads: %+v %+v", head, old)
+	}
+
+	ev, _ := l.GetBySequence(2)
+	proof, err := l.InclusionProof(2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.VerifyInclusion(ev, proof, head); err != nil {
+		t.Errorf("inclusion: %v", err)
+	}
+	tampered := ev
+	tampered.Payload = map[string]interface{}{"n": 99}
+	if err := ledger.VerifyInclusion(tampered, proof, head); !errors.Is(err, ledger.ErrHashMismatch) {
+		t.Errorf("tampered event: err = %v", err)
+	}
+
+	cproof, err := l.ConsistencyProof(3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := merkle.VerifyConsistency(old.TreeSize, head.TreeSize, old.RootHash, head.RootHash, cproof); err != nil {
+		t.Errorf("consistency: %v", err)
+	}
+	if _, err := l.InclusionProof(6, 0); !errors.Is(err, merkle.ErrTreeSize) {
+		t.Errorf("sequence past the end: err = %v", err)
+	}
+}
+
+// ─── Replicas ─────────────────────────────────────────────────────────────────
+
+// publicKey resolves every kid to one public key.
+type publicKey struct{ pub ed25519.PublicKey }
+
+func (k publicKey) KeyAt(string, int64) (ed25519.PublicKey, error) { return k.pub, nil }
+
+func TestReplica_Apply(t *testing.T) {
+	pub, priv := newTestKey(t)
+	primary := newSignedLedger(t, priv)
+	for i := 0; i < 4; i++ {
+		if _, err := primary.Append(ledger.EventGovernance, map[string]interface{}{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events := primary.List(1, 0)
+
+	r := ledger.NewReplica("org.test", publicKey{pub})
+	if !r.IsReplica() || r.Size() != 0 {
+		t.Fatalf("new replica: size %d", r.Size())
+	}
+	if err := r.Apply(events[1:]...); !errors.Is(err, ledger.ErrSequenceGap) {
+		t.Errorf("missing genesis: err = %v, want ErrSequenceGap", err)
+	}
+	changed := r.Changed()
+	if err := r.Apply(events[:3]...); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	select {
+	case <-changed:
+	default:
+		t.Error("Changed not signalled by Apply")
+	}
+	// A resumed stream overlaps with the events already held.
+	if err := r.Apply(events[1:]...); err != nil {
+		t.Fatalf("Apply (overlap): %v", err)
+	}
+	if r.Size() != len(events) || len(r.Verify()) != 0 {
+		t.Fatalf("replica: size %d, verify %v", r.Size(), r.Verify())
+	}
+	if root, _ := r.TreeHead(0); root.RootHash != mustTreeHead(t, primary).RootHash {
+		t.Error("replica tree root differs from the primary's")
+	}
+
+	// Replicas are read-only.
+	if _, err := r.Append(ledger.EventGovernance, map[string]interface{}{}); !errors.Is(err, ledger.ErrModificationRejected) {
+		t.Errorf("Append: err = %v, want ErrModificationRejected", err)
+	}
+	if err := primary.Apply(events[0]); !errors.Is(err, ledger.ErrModificationRejected) {
+		t.Errorf("Apply on a primary: err = %v, want ErrModificationRejected", err)
+	}
+
+	// A rewritten history is refused, whether it changes a held event or
+	// continues from another head.
+	forked := newSignedLedger(t, priv)
+	if err := r.Apply(forked.List(1, 0)...); !errors.Is(err, ledger.ErrDivergentHistory) {
+		t.Errorf("forked genesis: err = %v, want ErrDivergentHistory", err)
+	}
+	for i := 0; i < 5; i++ {
+		_, _ = forked.Append(ledger.EventGovernance, map[string]interface{}{"n": i})
+	}
+	next, _ := forked.GetBySequence(6)
+	if err := r.Apply(next); !errors.Is(err, ledger.ErrDivergentHistory) {
+		t.Errorf("forked continuation: err = %v, want ErrDivergentHistory", err)
+	}
+
+	// Events not signed by the institution are refused.
+	_, other := newTestKey(t)
+	r2 := ledger.NewReplica("org.test", publicKey{pub})
+	if err := r2.Apply(newSignedLedger(t, other).List(1, 0)...); !errors.Is(err, ledger.ErrInvalidSignature) {
+		t.Errorf("foreign signature: err = %v, want ErrInvalidSignature", err)
+	}
+	tampered := events[0]
+	tampered.Payload = map[string]interface{}{"x": 1}
+	if err := r2.Apply(tampered); !errors.Is(err, ledger.ErrInvalidSignature) {
+		t.Errorf("tampered event: err = %v, want ErrInvalidSignature", err)
+	}
+	if r2.Size() != 0 {
+		t.Errorf("replica holds %d unverified events", r2.Size())
+	}
+}
+
+func mustTreeHead(t *testing.T, l *ledger.InMemoryLedger) merkle.TreeHead {
+	t.Helper()
+	th, err := l.TreeHead(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return th
+}
+
+// ─── Redaction (crypto-shredding) ─────────────────────────────────────────────
+
+func TestRedaction_ShreddedLedgerStillVerifies(t *testing.T) {
+	_, priv := newTestKey(t)
+	l := newSignedLedger(t, priv)
+	l.SetSchemaMode(ledger.SchemaStrict)
+	store := shred.NewStore()
+	l.SetRedactor(store)
+
+	if got := strings.Join(ledger.SensitiveFields(ledger.EventExecutionTokenIssued), ","); got != "agent_id,resource" {
+		t.Errorf("SensitiveFields = %s", got)
+	}
+	ev, err := l.Append(ledger.EventExecutionTokenIssued, ledger.ExecutionTokenIssuedPayload{
+		ETID: "et-1", AuthorizationID: "req-1", AgentID: "agent-1",
+		Capability: "acp:cap:data.read", Resource: "patients/42", ExpiresAt: 1,
+	})
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	// Only commitments are stored; Reveal shows the cleartext.
+	raw, _ := json.Marshal(ev.Payload)
+	if strings.Contains(string(raw), "agent-1") || strings.Contains(string(raw), "patients/42") {
+		t.Errorf("stored payload leaks cleartext: %s", raw)
+	}
+	p := l.Reveal(ev).Payload.(map[string]interface{})
+	if p["agent_id"] != "agent-1" || p["resource"] != "patients/42" || p["capability"] != "acp:cap:data.read" {
+		t.Errorf("revealed payload = %v", p)
+	}
+
+	if _, _, err := store.Shred("agent-1"); err != nil {
+		t.Fatalf("Shred: %v", err)
+	}
+	p = l.Reveal(ev).Payload.(map[string]interface{})
+	if p["agent_id"] != ledger.Redacted || p["resource"] != ledger.Redacted {
+		t.Errorf("payload after shred = %v", p)
+	}
+	if errs := l.Verify(); len(errs) != 0 {
+		t.Errorf("Verify after shred: %v", errs)
+	}
+}
+
+// ─── Segment Archival ─────────────────────────────────────────────────────────
+
+func TestArchive_SegmentsStayReadableAndVerifiable(t *testing.T) {
+	_, priv := newTestKey(t)
+	l := newSignedLedger(t, priv)
+	for i := 0; i < 4; i++ {
+		if _, err := l.Append(ledger.EventGovernance, map[string]interface{}{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := l.List(1, 0)
+	head := mustTreeHead(t, l)
+	future := before[len(before)-1].Timestamp + 1
+
+	if _, err := l.ArchiveBefore(future); !errors.Is(err, ledger.ErrNoArchive) {
+		t.Errorf("no store: err = %v, want ErrNoArchive", err)
+	}
+	dir := t.TempDir()
+	archive, err := ledger.NewDirArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.SetArchive(archive)
+	if _, err := l.ArchiveBefore(before[0].Timestamp); !errors.Is(err, ledger.ErrNothingToArchive) {
+		t.Errorf("empty range: err = %v, want ErrNothingToArchive", err)
+	}
+
+	// Everything but the head is archived; readers do not see the boundary.
+	cp, err := l.ArchiveBefore(future)
+	if err != nil {
+		t.Fatalf("ArchiveBefore: %v", err)
+	}
+	if cp.FirstSequence != 1 || cp.LastSequence != 4 || cp.Count != 4 || cp.Sig == "" {
+		t.Fatalf("checkpoint = %+v", cp)
+	}
+	if cps, err := archive.Checkpoints(); err != nil || len(cps) != 1 || cps[0] != cp {
+		t.Errorf("Checkpoints = %v, %v", cps, err)
+	}
+	if l.Size() != len(before) || len(l.Segments()) != 1 {
+		t.Fatalf("size %d, %d segments", l.Size(), len(l.Segments()))
+	}
+	if got := l.List(1, 0); len(got) != len(before) || got[0].Hash != before[0].Hash || got[4].Hash != before[4].Hash {
+		t.Errorf("List after archival: %d events", len(got))
+	}
+	if got := l.List(3, 4); len(got) != 2 || got[0].Sequence != 3 {
+		t.Errorf("List(3, 4) = %v", got)
+	}
+	if ev, ok := l.Get(before[2].EventID); !ok || ev.Hash != before[2].Hash {
+		t.Error("archived event not found by ID")
+	}
+	if ev, ok := l.GetBySequence(1); !ok || ev.EventType != ledger.EventLedgerGenesis {
+		t.Error("archived genesis not found by sequence")
+	}
+	if th := mustTreeHead(t, l); th.RootHash != head.RootHash {
+		t.Error("archival changed the tree root")
+	}
+	if got := l.SequenceSince(future); got != 5 {
+		t.Errorf("SequenceSince = %d, want 5", got)
+	}
+
+	// Verify extends the checkpoint; VerifyArchive checks the segment.
+	if _, err := l.Append(ledger.EventGovernance, map[string]interface{}{"n": 4}); err != nil {
+		t.Fatal(err)
+	}
+	if errs := l.Verify(); len(errs) != 0 {
+		t.Errorf("Verify: %v", errs)
+	}
+	if errs := l.VerifyArchive(); len(errs) != 0 {
+		t.Errorf("VerifyArchive: %v", errs)
+	}
+	for _, id := range []string{before[0].EventID, before[3].EventID, before[4].EventID} {
+		if _, errs := l.VerifyEvent(id); len(errs) != 0 {
+			t.Errorf("VerifyEvent(%s): %v", id, errs)
+		}
+	}
+
+	// A tampered segment is reported and no longer served.
+	paths, _ := filepath.Glob(filepath.Join(dir, "segment-*.jsonl"))
+	if len(paths) != 1 {
+		t.Fatalf("segment files: %v", paths)
+	}
+	data, _ := os.ReadFile(paths[0])
+	if err := os.WriteFile(paths[0], []byte(strings.Replace(string(data), `"n":1`, `"n":9`, 1)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	errs := l.VerifyArchive()
+	if len(errs) == 0 || errs[0].Code != "LEDGER-003" {
+		t.Errorf("tampered segment: %v", errs)
+	}
+	if _, ok := l.Get(before[2].EventID); ok {
+		t.Error("event served from a tampered segment")
+	}
+}
+
+// ─── Verification Engine ──────────────────────────────────────────────────────
+
+// countingKey is a KeyResolver that counts its lookups: one per signature
+// checked.
+type countingKey struct {
+	pub ed25519.PublicKey
+	n   *atomic.Int64
+}
+
+func (k countingKey) KeyAt(string, int64) (ed25519.PublicKey, error) {
+	k.n.Add(1)
+	return k.pub, nil
+}
+
+func TestVerify_ParallelMatchesSerial(t *testing.T) {
+	pub, priv := newTestKey(t)
+	l := newSignedLedger(t, priv)
+	for i := 0; i < 1000; i++ {
+		if _, err := l.Append(ledger.EventGovernance, map[string]interface{}{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events := l.List(1, 0)
+	events[300].Payload = map[string]interface{}{"n": -1}
+	events[700].Sequence = 9999
+	events[900].PrevHash = events[10].Hash
+
+	defer func(w int) { ledger.VerifyWorkers = w }(ledger.VerifyWorkers)
+	ledger.VerifyWorkers = 1
+	serial := ledger.VerifyEvents(events, publicKey{pub})
+	ledger.VerifyWorkers = 8
+	parallel := ledger.VerifyEvents(events, publicKey{pub})
+	if len(serial) == 0 || !reflect.DeepEqual(serial, parallel) {
+		t.Errorf("parallel errors differ from serial:\n%v\n%v", parallel, serial)
+	}
+}
+
+func TestVerify_Incremental(t *testing.T) {
+	pub, priv := newTestKey(t)
+	l := newSignedLedger(t, priv)
+	var lookups atomic.Int64
+	l.SetKeyResolver(countingKey{pub, &lookups})
+	for i := 0; i < 9; i++ {
+		_, _ = l.Append(ledger.EventGovernance, map[string]interface{}{"n": i})
+	}
+
+	if errs := l.Verify(); len(errs) != 0 || l.VerifiedThrough() != 10 || lookups.Load() != 10 {
+		t.Fatalf("first Verify: %v, through %d, %d signatures", errs, l.VerifiedThrough(), lookups.Load())
+	}
+	_, _ = l.Append(ledger.EventGovernance, map[string]interface{}{"n": 9})
+	_, _ = l.Append(ledger.EventGovernance, map[string]interface{}{"n": 10})
+	if errs := l.Verify(); len(errs) != 0 || l.VerifiedThrough() != 12 || lookups.Load() != 12 {
+		t.Fatalf("second Verify: %v, through %d, %d signatures", errs, l.VerifiedThrough(), lookups.Load())
+	}
+	if errs := l.Verify(); len(errs) != 0 || lookups.Load() != 12 {
+		t.Errorf("Verify with nothing new checked %d signatures", lookups.Load()-12)
+	}
+
+	// A new key resolver, or a reset, starts over.
+	lookups.Store(0)
+	l.SetKeyResolver(countingKey{pub, &lookups})
+	if l.VerifiedThrough() != 0 {
+		t.Errorf("watermark kept across SetKeyResolver: %d", l.VerifiedThrough())
+	}
+	l.Verify()
+	l.ResetVerification()
+	l.Verify()
+	if lookups.Load() != 24 {
+		t.Errorf("%d signatures checked, want 24", lookups.Load())
+	}
+
+	// The watermark never passes an invalid event.
+	_, other := newTestKey(t)
+	l.SetSigningKey("other", other)
+	_, _ = l.Append(ledger.EventGovernance, map[string]interface{}{"n": 11})
+	_, _ = l.Append(ledger.EventGovernance, map[string]interface{}{"n": 12})
+	for i := 0; i < 2; i++ {
+		if errs := l.Verify(); len(errs) != 2 || errs[0].Sequence != 13 {
+			t.Errorf("Verify #%d with foreign signatures: %v", i, errs)
+		}
+	}
+	if l.VerifiedThrough() != 12 {
+		t.Errorf("watermark = %d, want 12", l.VerifiedThrough())
+	}
+}
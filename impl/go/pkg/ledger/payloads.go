@@ -0,0 +1,497 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─── Payload Schemas (ACP-LEDGER-1.3 §5) ─────────────────────────────────────
+//
+// Each event type has a typed payload struct. A field without omitempty is
+// required: it MUST be present in the payload (null is allowed where the
+// spec allows it). Producers may append either the struct or an equivalent
+// map; CheckPayload validates the JSON form.
+//
+// Payloads owned by another specification mirror that package's type
+// (lia.LiabilityRecord, provenance.AuthorityProvenance, ...), which the
+// ledger cannot import.
+//
+// Fields carrying personal data are tagged ledger:"sensitive", and the field
+// naming the data subject ledger:"subject" (see redact.go). Mirrored signed
+// payloads are not tagged: sealing a field would break their signature.
+
+// SchemaMode selects how payload schemas are enforced.
+type SchemaMode int
+
+const (
+	// SchemaLenient accepts any payload. Use it to read ledgers written
+	// before the §5 schemas were enforced: per §14, events without the
+	// v1.1+ fields are legacy, not invalid.
+	SchemaLenient SchemaMode = iota
+
+	// SchemaStrict rejects, at append time and in Verify, any payload that
+	// is not a JSON object carrying every required field of its event type.
+	SchemaStrict
+)
+
+// GenesisPayload is the LEDGER_GENESIS payload (§5.1).
+type GenesisPayload struct {
+	InstitutionID string `json:"institution_id"`
+	ACPVersion    string `json:"acp_version"`
+	CreatedAt     int64  `json:"created_at"`
+	CreatedBy     string `json:"created_by"`
+}
+
+// AuthorizationPayload is the AUTHORIZATION payload (§5.2). Every decision
+// is recorded, DENIED and ESCALATED included.
+type AuthorizationPayload struct {
+	RequestID          string      `json:"request_id"`
+	AgentID            string      `json:"agent_id" ledger:"subject"`
+	Capability         string      `json:"capability"`
+	Resource           string      `json:"resource" ledger:"sensitive"`
+	Decision           string      `json:"decision"` // APPROVED | DENIED | ESCALATED
+	RiskEvalID         string      `json:"risk_eval_id,omitempty"`
+	RiskScore          int         `json:"risk_score"`
+	TokenNonce         string      `json:"token_nonce,omitempty"`
+	ContextFingerprint string      `json:"context_fingerprint,omitempty"`
+	PolicySnapshotRef  string      `json:"policy_snapshot_ref"`
+	PolicyVersion      string      `json:"policy_version"`
+	ReasonCode         string      `json:"reason_code,omitempty"` // denial without a risk evaluation
+	Budget             interface{} `json:"budget,omitempty"`      // budget a RISK-010 denial would exceed
+	BatchID            string      `json:"batch_id,omitempty"`    // ACP-BULK-1.0 §8
+}
+
+// RiskEvaluationPayload is the RISK_EVALUATION payload (§5.3). f_anom,
+// anomaly_detail and policy_hash are required of ACP-RISK-2.0 evaluations.
+type RiskEvaluationPayload struct {
+	EvalID            string      `json:"eval_id"`
+	RequestID         string      `json:"request_id"`
+	AgentID           string      `json:"agent_id" ledger:"subject"`
+	Capability        string      `json:"capability"`
+	Baseline          int         `json:"baseline,omitempty"`
+	FCtx              int         `json:"f_ctx,omitempty"`
+	FHist             int         `json:"f_hist,omitempty"`
+	FRes              int         `json:"f_res,omitempty"`
+	FAnom             int         `json:"f_anom"`
+	FRep              int         `json:"f_rep,omitempty"`
+	RSFinal           int         `json:"rs_final"`
+	Decision          string      `json:"decision"`
+	DeniedReason      *string     `json:"denied_reason,omitempty"`
+	AnomalyDetail     interface{} `json:"anomaly_detail"`
+	ThresholdConfig   interface{} `json:"threshold_config,omitempty"`
+	FactorsApplied    []string    `json:"factors_applied,omitempty"`
+	History           interface{} `json:"history,omitempty"`
+	RiskFactors       interface{} `json:"risk_factors,omitempty"`
+	PolicySnapshotRef string      `json:"policy_snapshot_ref"`
+	PolicyHash        string      `json:"policy_hash"`
+}
+
+// RevocationPayload is the REVOCATION payload (§5.4).
+type RevocationPayload struct {
+	RevocationID       string `json:"revocation_id"`
+	TargetType         string `json:"target_type"` // token | agent
+	TargetID           string `json:"target_id"`
+	ReasonCode         string `json:"reason_code"`
+	RevokedBy          string `json:"revoked_by"`
+	DescendantsRevoked bool   `json:"descendants_revoked"`
+	DescendantCount    int    `json:"descendant_count"`
+}
+
+// TokenIssuedPayload is the TOKEN_ISSUED payload (§5.5). Field names follow
+// the ACP-API-1.0 §6 token response; ACP-LIA-1.0 reconstructs delegation
+// chains from them.
+type TokenIssuedPayload struct {
+	TokenID        string   `json:"token_id"`
+	TokenType      string   `json:"token_type,omitempty"`
+	IssuerID       string   `json:"issuer_id"`
+	SubjectAgentID string   `json:"subject_agent_id" ledger:"subject"`
+	Capabilities   []string `json:"capabilities"`
+	Resource       string   `json:"resource" ledger:"sensitive"`
+	ExpiresAt      int64    `json:"expires_at"`
+}
+
+// ExecutionTokenIssuedPayload is the EXECUTION_TOKEN_ISSUED payload (§5.6).
+type ExecutionTokenIssuedPayload struct {
+	ETID            string `json:"et_id"`
+	AuthorizationID string `json:"authorization_id"`
+	AgentID         string `json:"agent_id" ledger:"subject"`
+	Capability      string `json:"capability"`
+	Resource        string `json:"resource" ledger:"sensitive"`
+	ExpiresAt       int64  `json:"expires_at"`
+	TargetSystem    string `json:"target_system,omitempty"`
+	ProvenanceID    string `json:"provenance_id,omitempty"`
+}
+
+// ExecutionTokenConsumedPayload is the EXECUTION_TOKEN_CONSUMED payload (§5.7).
+type ExecutionTokenConsumedPayload struct {
+	ETID             string `json:"et_id"`
+	AuthorizationID  string `json:"authorization_id"`
+	AgentID          string `json:"agent_id" ledger:"subject"`
+	ConsumedAt       int64  `json:"consumed_at"`
+	ConsumedBySystem string `json:"consumed_by_system"`
+	ExecutionResult  string `json:"execution_result"` // success | failure | unknown
+}
+
+// AgentRegisteredPayload is the AGENT_REGISTERED payload (§5.8).
+type AgentRegisteredPayload struct {
+	AgentID         string   `json:"agent_id" ledger:"subject"`
+	InstitutionID   string   `json:"institution_id"`
+	AutonomyLevel   int      `json:"autonomy_level"`
+	AuthorityDomain string   `json:"authority_domain"`
+	Capabilities    []string `json:"capabilities,omitempty"`
+	RegisteredBy    string   `json:"registered_by"`
+}
+
+// AgentStateChangePayload is the AGENT_STATE_CHANGE payload (§5.9).
+type AgentStateChangePayload struct {
+	AgentID          string `json:"agent_id" ledger:"subject"`
+	PreviousState    string `json:"previous_state"`
+	NewState         string `json:"new_state"`
+	ReasonCode       string `json:"reason_code"`
+	AuthorizedBy     string `json:"authorized_by"`
+	AuthorizationRef string `json:"authorization_ref"`
+	ReputationState  string `json:"reputation_state,omitempty"`
+	Source           string `json:"source,omitempty"`
+}
+
+// EscalationCreatedPayload is the ESCALATION_CREATED payload (§5.10).
+// Authorization escalations carry request_id, agent_id, capability and
+// risk_score; cross-org delivery escalations (ACP-CROSS-ORG-1.1
+// CROSS-RULE-11) carry the bundle and the failure instead.
+type EscalationCreatedPayload struct {
+	EscalationID        string `json:"escalation_id"`
+	RequestID           string `json:"request_id,omitempty"`
+	AgentID             string `json:"agent_id,omitempty" ledger:"subject"`
+	Capability          string `json:"capability,omitempty"`
+	RiskScore           *int   `json:"risk_score,omitempty"`
+	EscalatedTo         string `json:"escalated_to"`
+	ExpiresAt           int64  `json:"expires_at"`
+	ReasonCode          string `json:"reason_code,omitempty"`
+	BundleID            string `json:"bundle_id,omitempty"`
+	TargetInstitutionID string `json:"target_institution_id,omitempty"`
+	Attempts            int    `json:"attempts,omitempty"`
+	LastError           string `json:"last_error,omitempty"`
+}
+
+// EscalationResolvedPayload is the ESCALATION_RESOLVED payload (§5.11).
+type EscalationResolvedPayload struct {
+	EscalationID      string `json:"escalation_id"`
+	AgentID           string `json:"agent_id,omitempty" ledger:"subject"` // owner of original_request_id
+	OriginalRequestID string `json:"original_request_id"`
+	Resolution        string `json:"resolution"`    // APPROVED | DENIED
+	ResolverType      string `json:"resolver_type"` // human | agent | system
+	ResolvedBy        string `json:"resolved_by"`
+	ResolvedAt        int64  `json:"resolved_at"`
+}
+
+// LiabilityRecordPayload is the LIABILITY_RECORD payload (§5.12); it
+// mirrors lia.LiabilityRecord.
+type LiabilityRecordPayload struct {
+	LiabilityID       string      `json:"liability_id"`
+	ETID              string      `json:"et_id"`
+	AuthorizationID   string      `json:"authorization_id"`
+	AgentID           string      `json:"agent_id" ledger:"subject"`
+	Capability        string      `json:"capability"`
+	Resource          string      `json:"resource" ledger:"sensitive"`
+	DelegationChain   interface{} `json:"delegation_chain"`
+	DelegationDepth   int         `json:"delegation_depth"`
+	LiabilityAssignee string      `json:"liability_assignee"`
+	PolicySnapshotRef string      `json:"policy_snapshot_ref"`
+	ExecutionResult   string      `json:"execution_result"`
+	ExecutedAt        int64       `json:"executed_at"`
+	ConsumedBySystem  string      `json:"consumed_by_system"`
+	ChainIncomplete   bool        `json:"chain_incomplete"`
+}
+
+// PolicySnapshotCreatedPayload is the POLICY_SNAPSHOT_CREATED payload
+// (§5.13). previous_snapshot_id is null for the institution's first snapshot.
+type PolicySnapshotCreatedPayload struct {
+	SnapshotID         string      `json:"snapshot_id"`
+	PolicyVersion      string      `json:"policy_version"`
+	EffectiveFrom      int64       `json:"effective_from"`
+	PreviousSnapshotID *string     `json:"previous_snapshot_id"`
+	CreatedBy          string      `json:"created_by"`
+	ChangeSummary      string      `json:"change_summary,omitempty"`
+	Budgets            interface{} `json:"budgets,omitempty"`
+}
+
+// ReputationUpdatedPayload is the REPUTATION_UPDATED payload (§5.14).
+// Scores are floats in [0, 1].
+type ReputationUpdatedPayload struct {
+	UpdateID         string      `json:"update_id"`
+	AgentID          string      `json:"agent_id" ledger:"subject"`
+	PreviousScore    *float64    `json:"previous_score"` // null when the agent had no local score
+	NewScore         float64     `json:"new_score"`
+	TriggerEventID   string      `json:"trigger_event_id"`
+	TriggerEventType string      `json:"trigger_event_type"`
+	DeltaReason      string      `json:"delta_reason"`
+	Issuer           string      `json:"issuer,omitempty"`
+	Warnings         interface{} `json:"warnings,omitempty"`
+}
+
+// CrossOrgInteractionPayload is the CROSS_ORG_INTERACTION payload
+// (ACP-CROSS-ORG-1.1): one event of a bundle, inbound or outbound.
+type CrossOrgInteractionPayload struct {
+	BundleID            string      `json:"bundle_id"`
+	Direction           string      `json:"direction"` // inbound | outbound
+	EventID             string      `json:"event_id"`
+	SourceInstitutionID string      `json:"source_institution_id"`
+	TargetInstitutionID string      `json:"target_institution_id"`
+	ActionType          string      `json:"action_type"`
+	PayloadHash         string      `json:"payload_hash"`
+	DelegationChain     interface{} `json:"delegation_chain,omitempty"`
+	AuthorizationID     string      `json:"authorization_id,omitempty"`
+	LiabilityRecordID   string      `json:"liability_record_id,omitempty"`
+	AckRequired         bool        `json:"ack_required,omitempty"`
+}
+
+// CrossOrgAckPayload is the CROSS_ORG_ACK payload (§5.15).
+type CrossOrgAckPayload struct {
+	AckID               string  `json:"ack_id"`
+	InteractionID       string  `json:"interaction_id,omitempty"`
+	BundleID            string  `json:"bundle_id,omitempty"`
+	OriginalEventID     string  `json:"original_event_id"`
+	SourceInstitutionID string  `json:"source_institution_id"`
+	TargetInstitutionID string  `json:"target_institution_id"`
+	ValidatedAt         int64   `json:"validated_at"`
+	Status              string  `json:"status"` // accepted | rejected | pending_review
+	ReviewDeadline      *int64  `json:"review_deadline,omitempty"`
+	RejectionReason     *string `json:"rejection_reason,omitempty"`
+	LedgerSequence      int64   `json:"ledger_sequence"`
+}
+
+// ProvenancePayload is the PROVENANCE payload; it mirrors
+// provenance.AuthorityProvenance (ACP-PROVENANCE-1.0).
+type ProvenancePayload struct {
+	Ver            string      `json:"ver"`
+	ProvenanceID   string      `json:"provenance_id"`
+	ExecutionID    string      `json:"execution_id"`
+	CapturedAt     int64       `json:"captured_at"`
+	Principal      string      `json:"principal"`
+	Executor       string      `json:"executor"`
+	AuthorityScope string      `json:"authority_scope"`
+	Chain          interface{} `json:"chain"`
+	PolicyRef      string      `json:"policy_ref"`
+	PolicyHash     string      `json:"policy_hash"`
+	Sig            string      `json:"sig"`
+}
+
+// PolicySnapshotPayload is the POLICY_SNAPSHOT payload; it mirrors
+// policyctx.PolicyContextSnapshot (ACP-POLICY-CTX-1.1 §7).
+type PolicySnapshotPayload struct {
+	Ver               string      `json:"ver"`
+	SnapshotID        string      `json:"snapshot_id"`
+	ExecutionID       string      `json:"execution_id"`
+	ProvenanceID      string      `json:"provenance_id,omitempty"`
+	SnapshotAt        int64       `json:"snapshot_at"`
+	Policy            interface{} `json:"policy"`
+	EvaluationContext interface{} `json:"evaluation_context"`
+	EvaluationResult  interface{} `json:"evaluation_result"`
+	Sig               string      `json:"sig"`
+}
+
+// GovernancePayload is the GOVERNANCE payload; it mirrors
+// govevents.GovernanceEvent (ACP-GOV-EVENTS-1.0).
+type GovernancePayload struct {
+	Ver           string      `json:"ver"`
+	EventID       string      `json:"event_id"`
+	EventType     string      `json:"event_type"`
+	InstitutionID string      `json:"institution_id"`
+	AgentID       *string     `json:"agent_id"`
+	TriggeredBy   string      `json:"triggered_by"`
+	Timestamp     int64       `json:"timestamp"`
+	EffectiveAt   int64       `json:"effective_at"`
+	Reason        string      `json:"reason"`
+	EvidenceRef   *string     `json:"evidence_ref"`
+	Payload       interface{} `json:"payload"`
+	Sig           string      `json:"sig"`
+}
+
+// AgentKeyRotatedPayload is the AGENT_KEY_ROTATED payload: an agent key
+// rotation signed by the old key and by the new key (proof of possession).
+type AgentKeyRotatedPayload struct {
+	AgentID          string `json:"agent_id"`
+	OldKID           string `json:"old_kid"`
+	NewKID           string `json:"new_kid"`
+	NewPublicKey     string `json:"new_public_key"`
+	RotatedAt        int64  `json:"rotated_at"`
+	OverlapPeriod    int64  `json:"overlap_period,omitempty"`
+	OldKeyValidUntil int64  `json:"old_key_valid_until,omitempty"`
+	Sig              string `json:"sig"`
+	NewKeySig        string `json:"new_key_sig"`
+}
+
+// PaymentVerifiedPayload is the PAYMENT_VERIFIED payload; it mirrors
+// pay.PaymentVerifiedEvent (ACP-PAY-1.0 §8).
+type PaymentVerifiedPayload struct {
+	Ver           string  `json:"ver"`
+	EventID       string  `json:"event_id"`
+	EventType     string  `json:"event_type"`
+	Timestamp     int64   `json:"timestamp"`
+	AgentID       string  `json:"agent_id"`
+	InstitutionID string  `json:"institution_id"`
+	ProofID       string  `json:"proof_id"`
+	Resource      string  `json:"resource"`
+	CapabilityID  string  `json:"capability_id"`
+	Amount        float64 `json:"amount"`
+	Currency      string  `json:"currency"`
+	PrevHash      string  `json:"prev_hash"`
+	Sig           string  `json:"sig"`
+}
+
+// CrossOrgPeerRegisteredPayload is the CROSS_ORG_PEER_REGISTERED payload: a
+// federated peer institution, its endpoint and the key its bundles are
+// verified with (ACP-CROSS-ORG-1.1). Replaced is set when it re-keys or
+// moves an already registered peer.
+type CrossOrgPeerRegisteredPayload struct {
+	InstitutionID string `json:"institution_id"`
+	Endpoint      string `json:"endpoint"`
+	PublicKey     string `json:"public_key"`
+	DID           string `json:"did,omitempty"`
+	Replaced      bool   `json:"replaced,omitempty"`
+	RegisteredBy  string `json:"registered_by"`
+}
+
+// PaymentProviderRegisteredPayload is the PAYMENT_PROVIDER_REGISTERED
+// payload: a settlement provider key trusted for a proof type (ACP-PAY-1.0 §4.2).
+type PaymentProviderRegisteredPayload struct {
+	ProviderID   string `json:"provider_id"`
+	Type         string `json:"type"`
+	PublicKey    string `json:"public_key"`
+	RegisteredBy string `json:"registered_by"`
+}
+
+// CheckpointCosignedPayload is the CHECKPOINT_COSIGNED payload; it mirrors
+// crossorg.CosignedCheckpoint.
+type CheckpointCosignedPayload struct {
+	Checkpoint   interface{}   `json:"checkpoint"`
+	Cosignatures []interface{} `json:"cosignatures"`
+	Quorum       int           `json:"quorum"`
+}
+
+// SubjectShreddedPayload is the SUBJECT_SHREDDED payload: the key sealing a
+// subject's sensitive fields was destroyed. The subject is named only by the
+// key ID, never in cleartext.
+type SubjectShreddedPayload struct {
+	KeyID        string `json:"key_id"`
+	SealedValues int    `json:"sealed_values"`
+	RequestedBy  string `json:"requested_by"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// AuthorizationReplayedPayload is the AUTHORIZATION_REPLAYED payload: an
+// identical retry of an authorization request got the original response
+// again, without a new decision. The original decision is the AUTHORIZATION
+// event referenced by OriginalEventID.
+type AuthorizationReplayedPayload struct {
+	RequestID        string `json:"request_id"`
+	AgentID          string `json:"agent_id" ledger:"subject"`
+	Decision         string `json:"decision"`
+	OriginalEventID  string `json:"original_event_id"`
+	OriginalSequence int64  `json:"original_sequence"`
+}
+
+// payloadSchemas maps every event type to its payload struct.
+var payloadSchemas = map[string]interface{}{
+	EventLedgerGenesis:             GenesisPayload{},
+	EventAuthorization:             AuthorizationPayload{},
+	EventRiskEvaluation:            RiskEvaluationPayload{},
+	EventRevocation:                RevocationPayload{},
+	EventTokenIssued:               TokenIssuedPayload{},
+	EventExecutionTokenIssued:      ExecutionTokenIssuedPayload{},
+	EventExecutionTokenConsumed:    ExecutionTokenConsumedPayload{},
+	EventAgentRegistered:           AgentRegisteredPayload{},
+	EventAgentStateChange:          AgentStateChangePayload{},
+	EventEscalationCreated:         EscalationCreatedPayload{},
+	EventEscalationResolved:        EscalationResolvedPayload{},
+	EventLiabilityRecord:           LiabilityRecordPayload{},
+	EventPolicySnapshotCreated:     PolicySnapshotCreatedPayload{},
+	EventReputationUpdated:         ReputationUpdatedPayload{},
+	EventProvenance:                ProvenancePayload{},
+	EventPolicySnapshot:            PolicySnapshotPayload{},
+	EventGovernance:                GovernancePayload{},
+	EventCrossOrgInteraction:       CrossOrgInteractionPayload{},
+	EventCrossOrgAck:               CrossOrgAckPayload{},
+	EventCrossOrgPeerRegistered:    CrossOrgPeerRegisteredPayload{},
+	EventAgentKeyRotated:           AgentKeyRotatedPayload{},
+	EventPaymentVerified:           PaymentVerifiedPayload{},
+	EventPaymentProviderRegistered: PaymentProviderRegisteredPayload{},
+	EventCheckpointCosigned:        CheckpointCosignedPayload{},
+	EventSubjectShredded:           SubjectShreddedPayload{},
+	EventAuthorizationReplayed:     AuthorizationReplayedPayload{},
+}
+
+// requiredFields holds the sorted required fields per event type: the keys
+// of the zero payload's JSON encoding, i.e. the fields without omitempty.
+var requiredFields = func() map[string][]string {
+	out := make(map[string][]string, len(payloadSchemas))
+	for eventType, zero := range payloadSchemas {
+		raw, err := json.Marshal(zero)
+		if err != nil {
+			panic(fmt.Sprintf("ledger: schema %s: %v", eventType, err))
+		}
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &m); err != nil {
+			panic(fmt.Sprintf("ledger: schema %s: %v", eventType, err))
+		}
+		fields := make([]string, 0, len(m))
+		for k := range m {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+		out[eventType] = fields
+	}
+	return out
+}()
+
+// RequiredFields returns the required payload fields of eventType, sorted,
+// or nil for an unknown event type.
+func RequiredFields(eventType string) []string {
+	return append([]string(nil), requiredFields[eventType]...)
+}
+
+// CheckPayload validates payload against the schema of eventType: its JSON
+// form must be an object carrying every required field. A missing
+// policy_snapshot_ref is reported as ErrMissingPolicySnapshotRef
+// (AUTHORIZATION) or ErrMissingRiskPolicySnapshotRef (RISK_EVALUATION); any
+// other gap as ErrIncompletePayload.
+func CheckPayload(eventType string, payload interface{}) error {
+	required, ok := requiredFields[eventType]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownEventType, eventType)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %s payload: %v", ErrIncompletePayload, eventType, err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil || m == nil {
+		return fmt.Errorf("%w: %s payload is not a JSON object", ErrIncompletePayload, eventType)
+	}
+	if _, ok := m["policy_snapshot_ref"]; !ok {
+		switch eventType {
+		case EventAuthorization:
+			return fmt.Errorf("%w: %s payload", ErrMissingPolicySnapshotRef, eventType)
+		case EventRiskEvaluation:
+			return fmt.Errorf("%w: %s payload", ErrMissingRiskPolicySnapshotRef, eventType)
+		}
+	}
+	var missing []string
+	for _, f := range required {
+		if _, ok := m[f]; !ok {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s payload missing %s", ErrIncompletePayload, eventType, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// errorCode returns the LEDGER-NNN code an error wraps.
+func errorCode(err error) string {
+	code, _, _ := strings.Cut(err.Error(), ":")
+	return code
+}
@@ -0,0 +1,155 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ─── Redaction (crypto-shredding) ─────────────────────────────────────────────
+//
+// Payload fields tagged ledger:"sensitive" carry personal data (agent IDs,
+// resources). The ledger is append-only, so it cannot erase them; instead,
+// with a Redactor attached (SetRedactor), each sensitive field is replaced
+// before hashing by a commitment to its value, and the cleartext is sealed
+// under a key of the event's subject — the field tagged ledger:"subject" —
+// kept outside the ledger. Destroying the subject's key makes the cleartext
+// unrecoverable, while hashes and signatures, which cover only the
+// commitments, still verify.
+//
+// Readers that need the cleartext call Reveal; shredded fields read as
+// Redacted.
+
+// Redacted is the value Reveal shows for a shredded field.
+const Redacted = "[redacted]"
+
+// Redactor seals and opens sensitive payload values (see pkg/shred).
+type Redactor interface {
+	// Seal returns the commitment stored in place of value, sealing the
+	// cleartext under the key of subject.
+	Seal(subject string, value interface{}) (interface{}, error)
+
+	// Open returns the cleartext committed to by v, or Redacted if it has
+	// been shredded. ok is false if v is not a commitment.
+	Open(v interface{}) (cleartext interface{}, ok bool)
+}
+
+// redactionField is a sensitive field of a payload schema.
+type redactionField struct {
+	name    string // JSON name
+	subject bool   // the field whose value names the event's subject
+}
+
+// sensitiveFields holds, per event type, the schema fields tagged
+// ledger:"sensitive" or ledger:"subject".
+var sensitiveFields = func() map[string][]redactionField {
+	out := make(map[string][]redactionField)
+	for eventType, zero := range payloadSchemas {
+		t := reflect.TypeOf(zero)
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("ledger")
+			if tag != "sensitive" && tag != "subject" {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			out[eventType] = append(out[eventType], redactionField{name: name, subject: tag == "subject"})
+		}
+	}
+	return out
+}()
+
+// SensitiveFields returns the sensitive payload fields of eventType, sorted,
+// or nil if it has none. The subject field is included.
+func SensitiveFields(eventType string) []string {
+	var out []string
+	for _, f := range sensitiveFields[eventType] {
+		out = append(out, f.name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// SetRedactor seals the sensitive fields of subsequently appended events with
+// r. Events already in the ledger are not rewritten.
+func (l *InMemoryLedger) SetRedactor(r Redactor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.redactor = r
+}
+
+// Reveal returns ev with every sealed payload field replaced by its cleartext,
+// or by Redacted once shredded. The revealed event no longer matches its hash:
+// verify the stored event, reveal it for display. Without a Redactor, ev is
+// returned unchanged.
+func (l *InMemoryLedger) Reveal(ev Event) Event {
+	l.mu.RLock()
+	r := l.redactor
+	l.mu.RUnlock()
+	if r == nil {
+		return ev
+	}
+	m, ok := ev.Payload.(map[string]interface{})
+	if !ok {
+		return ev
+	}
+	var out map[string]interface{}
+	for k, v := range m {
+		clear, ok := r.Open(v)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{}, len(m))
+			for k2, v2 := range m {
+				out[k2] = v2
+			}
+		}
+		out[k] = clear
+	}
+	if out != nil {
+		ev.Payload = out
+	}
+	return ev
+}
+
+// sealPayload replaces the sensitive fields of payload with commitments under
+// the key of its subject. Payloads without a subject, and empty fields, are
+// left as they are.
+func sealPayload(r Redactor, eventType string, payload interface{}) (interface{}, error) {
+	fields := sensitiveFields[eventType]
+	if len(fields) == 0 {
+		return payload, nil
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("redact %s payload: %w", eventType, err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil || m == nil {
+		return payload, nil // not an object: nothing to seal
+	}
+	var subject string
+	for _, f := range fields {
+		if f.subject {
+			subject, _ = m[f.name].(string)
+		}
+	}
+	if subject == "" {
+		return payload, nil
+	}
+	for _, f := range fields {
+		v, ok := m[f.name]
+		if !ok || v == nil || v == "" {
+			continue
+		}
+		sealed, err := r.Seal(subject, v)
+		if err != nil {
+			return nil, fmt.Errorf("redact %s.%s: %w", eventType, f.name, err)
+		}
+		m[f.name] = sealed
+	}
+	return m, nil
+}
@@ -0,0 +1,106 @@
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/merkle"
+)
+
+// ─── Replicas ─────────────────────────────────────────────────────────────────
+//
+// A replica is a read-only copy of another node's ledger. It starts empty and
+// grows only through Apply, which stores an event only if it passes the same
+// checks as Verify (type, payload, signature, hash) and extends the replica's
+// chain. A replica therefore never holds an event its primary did not sign,
+// and a primary that rewrites an event the replica already holds is detected
+// as a divergent history. Replicas are memory-only.
+
+// NewReplica creates an empty, read-only ledger for institutionID whose
+// events are verified against keys. keys == nil accepts unsigned events
+// (dev-mode primaries).
+func NewReplica(institutionID string, keys KeyResolver) *InMemoryLedger {
+	return &InMemoryLedger{
+		institutionID: institutionID,
+		resolver:      keys,
+		byID:          make(map[string]int64),
+		tree:          merkle.NewTree(),
+		changed:       make(chan struct{}),
+		replica:       true,
+	}
+}
+
+// IsReplica reports whether l was created by NewReplica.
+func (l *InMemoryLedger) IsReplica() bool { return l.replica }
+
+// Apply verifies and stores replicated events in order. Events the replica
+// already holds are skipped if identical, so a resumed stream may overlap.
+// Each event is stored as soon as it verifies; on error, the events before
+// the failing one remain applied.
+//
+// Returns ErrDivergentHistory if an event differs from the one held at its
+// sequence, does not link to the replica's last event or belongs to another
+// institution; ErrSequenceGap if events are missing; otherwise the sentinel
+// of the first verification failure (ErrInvalidSignature, ErrHashMismatch, ...).
+func (l *InMemoryLedger) Apply(events ...Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.replica {
+		return fmt.Errorf("%w: Apply is only allowed on replicas", ErrModificationRejected)
+	}
+	keys := l.keyResolver()
+	applied := 0
+	defer func() {
+		if applied > 0 {
+			l.notify()
+		}
+	}()
+
+	for _, ev := range events {
+		n := int64(len(l.events))
+		if ev.Sequence >= 1 && ev.Sequence <= n {
+			if held := l.events[ev.Sequence-1]; held.Hash != ev.Hash {
+				return fmt.Errorf("%w: sequence %d is %s, replica holds %s", ErrDivergentHistory, ev.Sequence, ev.Hash, held.Hash)
+			}
+			continue
+		}
+		if ev.Sequence != n+1 {
+			return fmt.Errorf("%w: got sequence %d, replica holds %d events", ErrSequenceGap, ev.Sequence, n)
+		}
+		if ev.InstitutionID != l.institutionID {
+			return fmt.Errorf("%w: event %d belongs to %q, replica follows %q", ErrDivergentHistory, ev.Sequence, ev.InstitutionID, l.institutionID)
+		}
+		var prev *Event
+		if n == 0 {
+			if ev.EventType != EventLedgerGenesis || ev.PrevHash != GenesisHash {
+				return fmt.Errorf("%w: first replicated event is %s", ErrGenesisMissing, ev.EventType)
+			}
+		} else {
+			p := l.events[n-1]
+			if ev.PrevHash != p.Hash {
+				return fmt.Errorf("%w: event %d does not link to %s", ErrDivergentHistory, ev.Sequence, p.Hash)
+			}
+			prev = &p
+		}
+		if errs := verifySingleEvent(ev, prev, keys, l.schema); len(errs) > 0 {
+			return fmt.Errorf("%w: event %d: %s", errorForCode(errs[0].Code), ev.Sequence, errs[0].Message)
+		}
+		l.store(ev)
+		applied++
+	}
+	return nil
+}
+
+// errorForCode returns the sentinel of a VerificationError code.
+func errorForCode(code string) error {
+	for _, err := range []error{
+		ErrModificationRejected, ErrInvalidSignature, ErrHashMismatch, ErrPrevHashBroken,
+		ErrSequenceGap, ErrTimestampRegression, ErrGenesisMissing, ErrUnknownEventType,
+		ErrIncompletePayload, ErrMissingPolicySnapshotRef, ErrMissingRiskPolicySnapshotRef,
+		ErrSigMissing, ErrDivergentHistory, ErrSegmentUnavailable,
+	} {
+		if errorCode(err) == code {
+			return err
+		}
+	}
+	return fmt.Errorf("ledger: verification failed (%s)", code)
+}
@@ -0,0 +1,98 @@
+package ledger
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/merkle"
+)
+
+// ─── Merkle Transparency Log ──────────────────────────────────────────────────
+//
+// The ledger maintains an RFC 6962 Merkle tree alongside the hash chain: leaf
+// i is the leaf hash of the 32-byte hash of the event at sequence i+1. A
+// signed tree head commits to every event up to its size, so a verifier can
+// check that one event is included (InclusionProof) or that a later head
+// extends an earlier one (ConsistencyProof) without the rest of the ledger.
+
+// LeafHash returns the Merkle leaf hash of ev, recomputing its event hash
+// from the fields so the proof binds the event content, not just its hash
+// field. Returns ErrHashMismatch if the stored hash does not match.
+func LeafHash(ev Event) (merkle.Hash, error) {
+	h, err := computeHashFromEvent(ev)
+	if err != nil {
+		return merkle.Hash{}, fmt.Errorf("ledger: leaf hash: %w", err)
+	}
+	if h != ev.Hash {
+		return merkle.Hash{}, fmt.Errorf("%w: event %s", ErrHashMismatch, ev.EventID)
+	}
+	return LeafFromHash(h), nil
+}
+
+// LeafFromHash returns the Merkle leaf hash of an encoded event hash, e.g.
+// the head hash of a checkpoint.
+func LeafFromHash(h string) merkle.Hash {
+	raw, err := base64.URLEncoding.DecodeString(h)
+	if err != nil {
+		raw = []byte(h)
+	}
+	return merkle.LeafHash(raw)
+}
+
+// TreeHead returns the head of the tree of the first size events (size ≤ 0 =
+// all), timestamped now and signed with the ledger's signing key. In dev mode
+// (no key) the head is unsigned.
+func (l *InMemoryLedger) TreeHead(size int64) (merkle.TreeHead, error) {
+	l.mu.RLock()
+	kid, privKey := l.kid, l.privKey
+	l.mu.RUnlock()
+
+	if size <= 0 {
+		size = l.tree.Size()
+	}
+	root, err := l.tree.Root(size)
+	if err != nil {
+		return merkle.TreeHead{}, err
+	}
+	th := merkle.TreeHead{
+		Ver:           merkle.TreeHeadVersion,
+		InstitutionID: l.institutionID,
+		TreeSize:      size,
+		RootHash:      root,
+		Timestamp:     time.Now().Unix(),
+	}
+	if privKey == nil {
+		return th, nil
+	}
+	return merkle.SignTreeHead(th, kid, privKey)
+}
+
+// InclusionProof returns the audit path of the event at sequence in the tree
+// of the first size events (size ≤ 0 = all).
+func (l *InMemoryLedger) InclusionProof(sequence, size int64) ([]merkle.Hash, error) {
+	if size <= 0 {
+		size = l.tree.Size()
+	}
+	return l.tree.InclusionProof(sequence-1, size)
+}
+
+// ConsistencyProof returns the proof that the tree of the first second events
+// extends the tree of the first first events (second ≤ 0 = all).
+func (l *InMemoryLedger) ConsistencyProof(first, second int64) ([]merkle.Hash, error) {
+	if second <= 0 {
+		second = l.tree.Size()
+	}
+	return l.tree.ConsistencyProof(first, second)
+}
+
+// VerifyInclusion checks offline that ev is included in the tree committed to
+// by head, given its audit path. The caller verifies head's signature
+// (merkle.VerifyTreeHead) against the institution key.
+func VerifyInclusion(ev Event, proof []merkle.Hash, head merkle.TreeHead) error {
+	leaf, err := LeafHash(ev)
+	if err != nil {
+		return err
+	}
+	return merkle.VerifyInclusion(leaf, ev.Sequence-1, head.TreeSize, proof, head.RootHash)
+}
@@ -0,0 +1,133 @@
+package ledger
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ─── Verification Engine ──────────────────────────────────────────────────────
+//
+// Most of the cost of verifying a chain is per event and independent of the
+// other events: recomputing the JCS hash and checking the Ed25519 signature.
+// verifyRun spreads those checks over worker goroutines in batches and then
+// walks the chain once, sequentially, for the linkage checks (prev_hash,
+// sequence, timestamp). Errors are reported in the same order as a serial
+// walk.
+//
+// On top of that, InMemoryLedger.Verify is incremental: it remembers the
+// sequence up to which the chain verified clean (VerifiedThrough) and later
+// calls only check the events appended since. Stored events are immutable,
+// so what verified once stays verified; changing the key resolver or the
+// schema mode, or ResetVerification, makes the next call start over.
+
+// verifyBatch is the number of events a worker checks per batch.
+const verifyBatch = 256
+
+// VerifyWorkers is the number of goroutines verifyRun uses; ≤ 0 means
+// runtime.GOMAXPROCS. 1 verifies serially.
+var VerifyWorkers = 0
+
+// verifyRun verifies events as a chain whose first event extends anchor; a
+// nil anchor leaves the first event unlinked.
+func verifyRun(events []Event, anchor *Event, keys KeyResolver, mode SchemaMode) []VerificationError {
+	perEvent := checkEvents(events, keys, mode)
+
+	var errs []VerificationError
+	for i, ev := range events {
+		errs = append(errs, perEvent[i]...)
+		prev := anchor
+		if i > 0 {
+			prev = &events[i-1]
+		}
+		if prev != nil {
+			errs = append(errs, checkLink(ev, *prev)...)
+		}
+	}
+	return errs
+}
+
+// checkEvents runs checkEvent on every event, in parallel batches when there
+// is more than one batch. The result is indexed like events.
+func checkEvents(events []Event, keys KeyResolver, mode SchemaMode) [][]VerificationError {
+	out := make([][]VerificationError, len(events))
+	batches := (len(events) + verifyBatch - 1) / verifyBatch
+	workers := VerifyWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, batches)
+
+	if workers <= 1 {
+		for i, ev := range events {
+			out[i] = checkEvent(ev, keys, mode)
+		}
+		return out
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				b := int(next.Add(1) - 1)
+				if b >= batches {
+					return
+				}
+				end := min((b+1)*verifyBatch, len(events))
+				for i := b * verifyBatch; i < end; i++ {
+					out[i] = checkEvent(events[i], keys, mode)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return out
+}
+
+// VerifiedThrough returns the sequence up to which Verify has found the
+// chain valid; the next Verify checks only the events after it. 0 means
+// nothing has been verified yet.
+func (l *InMemoryLedger) VerifiedThrough() int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.verified
+}
+
+// ResetVerification makes the next Verify re-check the whole live chain.
+func (l *InMemoryLedger) ResetVerification() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resetVerificationLocked()
+}
+
+// resetVerificationLocked drops the watermark and invalidates the Verify
+// calls in flight. Caller must hold l.mu.
+func (l *InMemoryLedger) resetVerificationLocked() {
+	l.verified = 0
+	l.verifyGen++
+}
+
+// advanceVerified moves the watermark past the events Verify checked, up to
+// the last one before the first error. A call that started before a reset
+// (gen is stale) leaves the watermark alone.
+func (l *InMemoryLedger) advanceVerified(gen uint64, checked []Event, errs []VerificationError) {
+	if len(checked) == 0 {
+		return
+	}
+	through := checked[len(checked)-1].Sequence
+	for _, e := range errs {
+		if e.Sequence == 0 {
+			return // not attributable to an event
+		}
+		through = min(through, e.Sequence-1)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if gen == l.verifyGen && through > l.verified {
+		l.verified = through
+	}
+}
@@ -19,7 +19,10 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
 )
 
 // ─── Error Sentinels (ACP-LIA-1.0 §12) ───────────────────────────────────────
@@ -35,6 +38,23 @@ var (
 	ErrDuplicate            = errors.New("LIA-008: LIABILITY_RECORD already exists for this et_id")
 )
 
+// ─── Execution Results (ACP-LIA-1.0 §5.11) ────────────────────────────────────
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultUnknown = "unknown" // result not determined before the ET timeout
+)
+
+// ValidExecutionResult reports whether r is one of the §5.11 enum values.
+func ValidExecutionResult(r string) bool {
+	switch r {
+	case ResultSuccess, ResultFailure, ResultUnknown:
+		return true
+	}
+	return false
+}
+
 // ─── Types (ACP-LIA-1.0 §4 + §5) ─────────────────────────────────────────────
 
 // ChainEntry is one step in the delegation chain (§5.7).
@@ -151,6 +171,123 @@ func resolveAssignee(req EmitRequest) string {
 	return req.AgentID
 }
 
+// ─── Delegation Chain Reconstruction (ACP-LIA-1.0 §7) ─────────────────────────
+
+// IssuedToken is the subset of a TOKEN_ISSUED ledger event needed to
+// reconstruct a delegation chain (§7.1: the ledger is the only data source).
+type IssuedToken struct {
+	TokenID        string
+	IssuerID       string
+	SubjectAgentID string
+	IssuedAt       int64 // ledger event timestamp
+}
+
+// ReconstructChain rebuilds the delegation chain that ends at executorID.
+//
+// Starting from the executor, it walks backwards through issued tokens: at
+// each step it takes the most recent token whose subject is the current agent
+// and that was issued no later than its child (§7.5), then moves to that
+// token's issuer. The walk stops successfully when a token issued by
+// institutionID is reached (§7.3).
+//
+// notAfter bounds the executor's own token (typically the ET issuance time).
+//
+// Returns the chain ordered by depth ASC and chainIncomplete=true when the
+// institutional root could not be reached (missing token, cycle, or depth
+// beyond tokens.MaxDelegationDepth). The returned slice is never nil.
+func ReconstructChain(issued []IssuedToken, executorID, institutionID string, notAfter int64) (chain []ChainEntry, chainIncomplete bool) {
+	var reversed []ChainEntry
+	visited := make(map[string]bool)
+	current := executorID
+	bound := notAfter
+	complete := false
+
+	for len(reversed) <= tokens.MaxDelegationDepth {
+		if visited[current] {
+			break // cycle — cannot reach the root
+		}
+		visited[current] = true
+
+		var best *IssuedToken
+		for i := range issued {
+			t := &issued[i]
+			if t.SubjectAgentID != current || (bound > 0 && t.IssuedAt > bound) {
+				continue
+			}
+			if best == nil || t.IssuedAt >= best.IssuedAt {
+				best = t
+			}
+		}
+		if best == nil {
+			break
+		}
+		reversed = append(reversed, ChainEntry{
+			TokenNonce: best.TokenID,
+			AgentID:    current,
+			IssuedAt:   best.IssuedAt,
+		})
+		if best.IssuerID == institutionID {
+			complete = true
+			break
+		}
+		current = best.IssuerID
+		bound = best.IssuedAt
+	}
+
+	chain = make([]ChainEntry, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		e := reversed[i]
+		e.Depth = len(chain)
+		chain = append(chain, e)
+	}
+	return chain, !complete
+}
+
+// TokenIndex indexes issued tokens by subject, so that a chain is
+// reconstructed from the tokens that can be on it instead of every token
+// ever issued. Thread-safe.
+type TokenIndex struct {
+	mu        sync.RWMutex
+	bySubject map[string][]IssuedToken
+}
+
+// NewTokenIndex creates an empty index.
+func NewTokenIndex() *TokenIndex {
+	return &TokenIndex{bySubject: make(map[string][]IssuedToken)}
+}
+
+// Add indexes an issued token.
+func (x *TokenIndex) Add(t IssuedToken) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.bySubject[t.SubjectAgentID] = append(x.bySubject[t.SubjectAgentID], t)
+}
+
+// ReconstructChain is ReconstructChain over the indexed tokens. Only the
+// tokens issued to executorID and, transitively, to their issuers (up to
+// tokens.MaxDelegationDepth hops) are considered.
+func (x *TokenIndex) ReconstructChain(executorID, institutionID string, notAfter int64) ([]ChainEntry, bool) {
+	x.mu.RLock()
+	var candidates []IssuedToken
+	seen := map[string]bool{executorID: true}
+	frontier := []string{executorID}
+	for hop := 0; hop <= tokens.MaxDelegationDepth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, subject := range frontier {
+			for _, t := range x.bySubject[subject] {
+				candidates = append(candidates, t)
+				if !seen[t.IssuerID] {
+					seen[t.IssuerID] = true
+					next = append(next, t.IssuerID)
+				}
+			}
+		}
+		frontier = next
+	}
+	x.mu.RUnlock()
+	return ReconstructChain(candidates, executorID, institutionID, notAfter)
+}
+
 // ─── In-memory Store ──────────────────────────────────────────────────────────
 
 // InMemoryLiabilityStore is a thread-safe store for LedgerRecords.
@@ -281,6 +418,50 @@ func (s *InMemoryLiabilityStore) GetByAgentID(agentID string, f AgentQueryFilter
 	}
 }
 
+// QueryAgents returns records where agent_id or liability_assignee is one of
+// agentIDs and executed_at falls in [fromTS, toTS] (0 = unbounded), ordered by
+// ledger_sequence ASC. Only records with ledger_sequence > afterSeq are
+// returned, at most limit of them; total counts every match regardless of
+// afterSeq and limit. Used for cursor-paged bulk queries (ACP-BULK-1.0 §3).
+func (s *InMemoryLiabilityStore) QueryAgents(agentIDs []string, fromTS, toTS, afterSeq int64, limit int) (items []LedgerRecord, total int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var matched []LedgerRecord
+	for _, agentID := range agentIDs {
+		for _, id := range s.byAgent[agentID] {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			r, ok := s.records[id]
+			if !ok {
+				continue
+			}
+			if fromTS > 0 && r.ExecutedAt < fromTS {
+				continue
+			}
+			if toTS > 0 && r.ExecutedAt > toTS {
+				continue
+			}
+			matched = append(matched, *r)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].LedgerSequence < matched[j].LedgerSequence })
+
+	for _, r := range matched {
+		if r.LedgerSequence <= afterSeq {
+			continue
+		}
+		if limit > 0 && len(items) >= limit {
+			break
+		}
+		items = append(items, r)
+	}
+	return items, len(matched)
+}
+
 // Size returns the number of stored records.
 func (s *InMemoryLiabilityStore) Size() int {
 	s.mu.RLock()
@@ -0,0 +1,124 @@
+package lia_test
+
+import (
+	"testing"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/lia"
+)
+
+const inst = "org.test"
+
+func TestReconstructChain_Direct(t *testing.T) {
+	issued := []lia.IssuedToken{
+		{TokenID: "t-root", IssuerID: inst, SubjectAgentID: "agent-a", IssuedAt: 100},
+	}
+	chain, incomplete := lia.ReconstructChain(issued, "agent-a", inst, 200)
+	if incomplete {
+		t.Fatal("chain_incomplete=true, want false")
+	}
+	if len(chain) != 1 || chain[0].Depth != 0 || chain[0].TokenNonce != "t-root" || chain[0].AgentID != "agent-a" {
+		t.Fatalf("chain = %+v", chain)
+	}
+}
+
+func TestReconstructChain_Delegated(t *testing.T) {
+	issued := []lia.IssuedToken{
+		{TokenID: "t-0", IssuerID: inst, SubjectAgentID: "supervisor", IssuedAt: 100},
+		{TokenID: "t-1", IssuerID: "supervisor", SubjectAgentID: "worker", IssuedAt: 150},
+	}
+	chain, incomplete := lia.ReconstructChain(issued, "worker", inst, 200)
+	if incomplete {
+		t.Fatal("chain_incomplete=true, want false")
+	}
+	if len(chain) != 2 {
+		t.Fatalf("len(chain) = %d, want 2", len(chain))
+	}
+	if chain[0].AgentID != "supervisor" || chain[1].AgentID != "worker" || chain[1].Depth != 1 {
+		t.Fatalf("chain = %+v", chain)
+	}
+
+	rec, err := lia.Emit(lia.EmitRequest{
+		AgentID:            "worker",
+		DelegationChain:    chain,
+		SupervisorAgentID:  chain[0].AgentID,
+		SupervisorAutonomy: 1,
+	})
+	if err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if rec.DelegationDepth != 1 || rec.LiabilityAssignee != "supervisor" {
+		t.Errorf("depth=%d assignee=%q, want 1 / supervisor", rec.DelegationDepth, rec.LiabilityAssignee)
+	}
+}
+
+// A parent issued after its child violates §7.5 and is not followed.
+func TestReconstructChain_NonMonotonicParentIgnored(t *testing.T) {
+	issued := []lia.IssuedToken{
+		{TokenID: "t-0", IssuerID: inst, SubjectAgentID: "supervisor", IssuedAt: 180},
+		{TokenID: "t-1", IssuerID: "supervisor", SubjectAgentID: "worker", IssuedAt: 150},
+	}
+	chain, incomplete := lia.ReconstructChain(issued, "worker", inst, 200)
+	if !incomplete {
+		t.Fatal("chain_incomplete=false, want true")
+	}
+	if len(chain) != 1 || chain[0].TokenNonce != "t-1" {
+		t.Fatalf("partial chain = %+v", chain)
+	}
+}
+
+func TestReconstructChain_NoTokens(t *testing.T) {
+	chain, incomplete := lia.ReconstructChain(nil, "agent-a", inst, 200)
+	if !incomplete {
+		t.Fatal("chain_incomplete=false, want true")
+	}
+	if chain == nil || len(chain) != 0 {
+		t.Fatalf("chain = %#v, want empty non-nil slice", chain)
+	}
+}
+
+func TestReconstructChain_Cycle(t *testing.T) {
+	issued := []lia.IssuedToken{
+		{TokenID: "t-1", IssuerID: "b", SubjectAgentID: "a", IssuedAt: 100},
+		{TokenID: "t-2", IssuerID: "a", SubjectAgentID: "b", IssuedAt: 90},
+	}
+	if _, incomplete := lia.ReconstructChain(issued, "a", inst, 200); !incomplete {
+		t.Fatal("cycle must yield chain_incomplete=true")
+	}
+}
+
+func TestValidExecutionResult(t *testing.T) {
+	for _, r := range []string{lia.ResultSuccess, lia.ResultFailure, lia.ResultUnknown} {
+		if !lia.ValidExecutionResult(r) {
+			t.Errorf("%q should be valid", r)
+		}
+	}
+	if lia.ValidExecutionResult("partial") {
+		t.Error(`"partial" should be invalid`)
+	}
+}
+
+func TestTokenIndex_MatchesReconstructChain(t *testing.T) {
+	issued := []lia.IssuedToken{
+		{TokenID: "t-0", IssuerID: inst, SubjectAgentID: "supervisor", IssuedAt: 100},
+		{TokenID: "t-1", IssuerID: "supervisor", SubjectAgentID: "worker", IssuedAt: 150},
+		{TokenID: "t-2", IssuerID: inst, SubjectAgentID: "bystander", IssuedAt: 120},
+		{TokenID: "t-3", IssuerID: "worker", SubjectAgentID: "worker", IssuedAt: 160},
+	}
+	idx := lia.NewTokenIndex()
+	for _, tok := range issued {
+		idx.Add(tok)
+	}
+	for _, executor := range []string{"worker", "supervisor", "bystander", "unknown"} {
+		want, wantIncomplete := lia.ReconstructChain(issued, executor, inst, 200)
+		got, incomplete := idx.ReconstructChain(executor, inst, 200)
+		if incomplete != wantIncomplete || len(got) != len(want) {
+			t.Errorf("%s: chain %+v (incomplete=%v), want %+v (incomplete=%v)", executor, got, incomplete, want, wantIncomplete)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: chain[%d] = %+v, want %+v", executor, i, got[i], want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,340 @@
+// Package lifecycle keeps an agent's registry status (ACP-API-1.0 §4) and its
+// reputation state (ACP-REP-1.1) in a single state machine.
+//
+// Both subsystems describe the same lifecycle with different names:
+//
+//	registry     reputation
+//	active       ACTIVE
+//	restricted   PROBATION
+//	suspended    SUSPENDED
+//	revoked      BANNED      (terminal)
+//
+// Every transition — an admin change on either API, a revocation, or an
+// automatic transition of the reputation engine — goes through Service, which
+// updates both stores, records AGENT_STATE_CHANGE in the audit ledger and
+// emits agent_suspended / agent_reinstated governance events
+// (ACP-GOV-EVENTS-1.0 §5.2, §5.3).
+package lifecycle
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/govevents"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/chelof100/acp-framework/acp-go/pkg/registry"
+	"github.com/chelof100/acp-framework/acp-go/pkg/reputation"
+)
+
+// Source identifies what triggered a transition.
+type Source string
+
+const (
+	SourceRegistryAdmin   Source = "registry_admin"    // POST /agents/{id}/state
+	SourceReputationAdmin Source = "reputation_admin"  // POST /rep/{id}/state
+	SourceReputation      Source = "reputation_engine" // score-driven transition
+	SourceRevocation      Source = "revocation"
+)
+
+// Registry is the part of the agent registry the service drives.
+type Registry interface {
+	GetRecord(agentID string) (registry.AgentRecord, error)
+	UpdateStatus(agentID string, status registry.AgentStatus) error
+}
+
+// Ledger is the audit ledger the service records transitions in.
+type Ledger interface {
+	Append(eventType string, payload interface{}) (ledger.Event, error)
+}
+
+// SignerFunc returns the institution key governance events are signed with.
+// A nil private key emits unsigned events (dev mode).
+type SignerFunc func() (kid string, priv ed25519.PrivateKey)
+
+// Request is a requested transition.
+type Request struct {
+	AgentID          string
+	Status           registry.AgentStatus
+	Reason           string
+	AuthorizedBy     string
+	Source           Source
+	AuthorizationRef string // e.g. the API request ID
+}
+
+// Change describes an applied transition.
+type Change struct {
+	AgentID           string                `json:"agent_id"`
+	Previous          registry.AgentStatus  `json:"previous_state"`
+	New               registry.AgentStatus  `json:"new_state"`
+	ReputationState   reputation.AgentState `json:"reputation_state"`
+	Source            Source                `json:"source"`
+	LedgerEventID     string                `json:"ledger_event_id"`
+	GovernanceEventID string                `json:"governance_event_id,omitempty"`
+}
+
+// ToReputation maps a registry status to its reputation state.
+func ToReputation(s registry.AgentStatus) reputation.AgentState {
+	switch s {
+	case registry.StatusRestricted:
+		return reputation.StateProbation
+	case registry.StatusSuspended:
+		return reputation.StateSuspended
+	case registry.StatusRevoked:
+		return reputation.StateBanned
+	default:
+		return reputation.StateActive
+	}
+}
+
+// FromReputation maps a reputation state to its registry status.
+func FromReputation(s reputation.AgentState) registry.AgentStatus {
+	switch s {
+	case reputation.StateProbation:
+		return registry.StatusRestricted
+	case reputation.StateSuspended:
+		return registry.StatusSuspended
+	case reputation.StateBanned:
+		return registry.StatusRevoked
+	default:
+		return registry.StatusActive
+	}
+}
+
+// severity orders statuses from least to most restrictive.
+var severity = map[registry.AgentStatus]int{
+	registry.StatusActive:     0,
+	registry.StatusRestricted: 1,
+	registry.StatusSuspended:  2,
+	registry.StatusRevoked:    3,
+}
+
+// Service applies agent state transitions to the registry and the reputation
+// engine and records them. It is safe for concurrent use.
+type Service struct {
+	mu            sync.Mutex
+	institutionID string
+	reg           Registry
+	rep           *reputation.Engine
+	ledger        Ledger
+	signer        SignerFunc
+	suspensions   map[string]string // agentID → suspension_id of the open suspension
+
+	// OnRecordError, if set, receives errors applying or recording a
+	// score-driven transition, which has no caller to return them to.
+	OnRecordError func(error)
+}
+
+// NewService creates the lifecycle service and registers it as the
+// reputation engine's transition observer.
+func NewService(institutionID string, reg Registry, rep *reputation.Engine, l Ledger, signer SignerFunc) *Service {
+	s := &Service{
+		institutionID: institutionID,
+		reg:           reg,
+		rep:           rep,
+		ledger:        l,
+		signer:        signer,
+		suspensions:   make(map[string]string),
+	}
+	rep.SetTransitionObserver(s.onReputationTransition)
+	return s
+}
+
+// Status returns the effective status of an agent: the more restrictive of
+// its registry status and its reputation state. Agents known only to the
+// reputation engine (no registry record) take the reputation state.
+func (s *Service) Status(agentID string) (registry.AgentStatus, error) {
+	status, _, err := s.current(agentID)
+	return status, err
+}
+
+// Transition moves an agent to req.Status.
+//
+// Returns registry.ErrAgentRevoked if the agent is revoked (terminal) and
+// registry.ErrInvalidTransition for transitions outside the ACP-API-1.0 §4
+// table. The returned error wraps the ledger error if the transition was
+// applied but could not be recorded.
+func (s *Service) Transition(req Request) (Change, error) {
+	if req.AgentID == "" {
+		return Change{}, errors.New("acp/lifecycle: agent_id must not be empty")
+	}
+	if _, ok := severity[req.Status]; !ok {
+		return Change{}, fmt.Errorf("%w: unknown state %q", registry.ErrInvalidTransition, req.Status)
+	}
+	if req.Source == "" {
+		req.Source = SourceRegistryAdmin
+	}
+	if req.Reason == "" {
+		req.Reason = string(req.Source)
+	}
+	if req.AuthorizedBy == "" {
+		req.AuthorizedBy = s.institutionID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, registered, err := s.current(req.AgentID)
+	if err != nil {
+		return Change{}, err
+	}
+	if prev == registry.StatusRevoked {
+		return Change{}, fmt.Errorf("%w: %q", registry.ErrAgentRevoked, req.AgentID)
+	}
+	if !registry.CanTransition(prev, req.Status) {
+		return Change{}, fmt.Errorf("%w: %s → %s", registry.ErrInvalidTransition, prev, req.Status)
+	}
+
+	if registered != "" && registered != req.Status {
+		if err := s.reg.UpdateStatus(req.AgentID, req.Status); err != nil {
+			return Change{}, err
+		}
+	}
+	if err := s.rep.SetState(req.AgentID, ToReputation(req.Status), req.Reason, req.AuthorizedBy); err != nil {
+		return Change{}, err
+	}
+	return s.record(req, prev)
+}
+
+// onReputationTransition mirrors an automatic reputation transition into the
+// registry and records it. The reputation store is already updated.
+func (s *Service) onReputationTransition(t reputation.StateTransition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := FromReputation(t.ToState)
+	prev := FromReputation(t.FromState)
+	if rec, err := s.reg.GetRecord(t.AgentID); err == nil && rec.Status != next {
+		// A stricter registry status set outside the service is never
+		// relaxed by a score-driven transition.
+		if severity[rec.Status] > severity[next] && rec.Status != prev {
+			return
+		}
+		if severity[rec.Status] > severity[prev] {
+			prev = rec.Status
+		}
+		if registry.CanTransition(rec.Status, next) {
+			if err := s.reg.UpdateStatus(t.AgentID, next); err != nil {
+				// The registry still holds the old status: recording the
+				// change would leave the ledger ahead of it.
+				if s.OnRecordError != nil {
+					s.OnRecordError(fmt.Errorf("acp/lifecycle: registry status of %q: %w", t.AgentID, err))
+				}
+				return
+			}
+		}
+	}
+	_, err := s.record(Request{
+		AgentID:      t.AgentID,
+		Status:       next,
+		Reason:       t.Reason,
+		AuthorizedBy: t.AuthorizedBy,
+		Source:       SourceReputation,
+	}, prev)
+	if err != nil && s.OnRecordError != nil {
+		s.OnRecordError(err)
+	}
+}
+
+// current returns the effective status and, if the agent has a registry
+// record, its registry status ("" otherwise). Caller holds s.mu or accepts a
+// racy read.
+func (s *Service) current(agentID string) (effective, registered registry.AgentStatus, err error) {
+	repRec, err := s.rep.GetRecord(agentID)
+	if err != nil {
+		return "", "", fmt.Errorf("acp/lifecycle: reputation record: %w", err)
+	}
+	effective = FromReputation(repRec.State)
+	if rec, err := s.reg.GetRecord(agentID); err == nil {
+		registered = rec.Status
+		if severity[registered] > severity[effective] {
+			effective = registered
+		}
+	}
+	return effective, registered, nil
+}
+
+// record appends AGENT_STATE_CHANGE and, for suspensions and reinstatements,
+// the governance event. Caller holds s.mu.
+func (s *Service) record(req Request, prev registry.AgentStatus) (Change, error) {
+	ch := Change{
+		AgentID:         req.AgentID,
+		Previous:        prev,
+		New:             req.Status,
+		ReputationState: ToReputation(req.Status),
+		Source:          req.Source,
+	}
+	ev, err := s.ledger.Append(ledger.EventAgentStateChange, map[string]interface{}{
+		"agent_id":          req.AgentID,
+		"previous_state":    string(prev),
+		"new_state":         string(req.Status),
+		"reputation_state":  string(ch.ReputationState),
+		"source":            string(req.Source),
+		"reason_code":       req.Reason,
+		"authorized_by":     req.AuthorizedBy,
+		"authorization_ref": req.AuthorizationRef,
+	})
+	if err != nil {
+		return ch, fmt.Errorf("acp/lifecycle: record state change: %w", err)
+	}
+	ch.LedgerEventID = ev.EventID
+
+	var gov *govevents.EmitRequest
+	switch {
+	case req.Status == registry.StatusSuspended && prev != registry.StatusSuspended:
+		id := newID()
+		s.suspensions[req.AgentID] = id
+		gov = &govevents.EmitRequest{
+			EventType: govevents.TypeAgentSuspended,
+			Payload: govevents.AgentSuspendedPayload{
+				SuspensionID:            id,
+				CapabilitiesFrozen:      []string{},
+				ActiveDelegationsFrozen: []string{},
+			},
+		}
+	case prev == registry.StatusSuspended && req.Status != registry.StatusRevoked:
+		id := s.suspensions[req.AgentID]
+		delete(s.suspensions, req.AgentID)
+		gov = &govevents.EmitRequest{
+			EventType: govevents.TypeAgentReinstated,
+			Payload: govevents.AgentReinstatedPayload{
+				SuspensionID:         id,
+				ReinstatedBy:         req.AuthorizedBy,
+				CapabilitiesRestored: []string{},
+			},
+		}
+	case req.Status == registry.StatusRevoked:
+		delete(s.suspensions, req.AgentID)
+	}
+	if gov == nil {
+		return ch, nil
+	}
+
+	agentID := req.AgentID
+	gov.InstitutionID = s.institutionID
+	gov.AgentID = &agentID
+	gov.TriggeredBy = req.AuthorizedBy
+	gov.Reason = req.Reason
+	gov.EvidenceRef = &ch.LedgerEventID
+	_, priv := s.signer()
+	gev, err := govevents.Emit(*gov, priv)
+	if err != nil {
+		return ch, fmt.Errorf("acp/lifecycle: emit %s: %w", gov.EventType, err)
+	}
+	if _, err := s.ledger.Append(ledger.EventGovernance, gev); err != nil {
+		return ch, fmt.Errorf("acp/lifecycle: record %s: %w", gov.EventType, err)
+	}
+	ch.GovernanceEventID = gev.EventID
+	return ch, nil
+}
+
+// newID returns a random UUID v4 string.
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
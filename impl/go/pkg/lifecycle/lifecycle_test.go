@@ -0,0 +1,192 @@
+package lifecycle_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/govevents"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/chelof100/acp-framework/acp-go/pkg/lifecycle"
+	"github.com/chelof100/acp-framework/acp-go/pkg/registry"
+	"github.com/chelof100/acp-framework/acp-go/pkg/reputation"
+)
+
+type fixture struct {
+	reg *registry.InMemoryRegistry
+	rep *reputation.Engine
+	led *ledger.InMemoryLedger
+	svc *lifecycle.Service
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	seed := make([]byte, ed25519.SeedSize)
+	priv := ed25519.NewKeyFromSeed(seed)
+	led, err := ledger.NewInMemoryLedger("org.test", priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fixture{
+		reg: registry.NewInMemoryRegistry(),
+		rep: reputation.NewDefaultEngine(reputation.NewInMemoryReputationStore()),
+		led: led,
+	}
+	f.svc = lifecycle.NewService("org.test", f.reg, f.rep, led, func() (string, ed25519.PrivateKey) { return "k1", priv })
+	if err := f.reg.RegisterFull(registry.AgentRecord{
+		AgentID: "agent-1", PublicKey: priv.Public().(ed25519.PublicKey), AutonomyLevel: 2,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// events returns the ledger events of eventType, in order.
+func (f *fixture) events(eventType string) []ledger.Event {
+	var out []ledger.Event
+	for _, ev := range f.led.List(0, 0) {
+		if ev.EventType == eventType {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+func (f *fixture) states(t *testing.T, agentID string) (registry.AgentStatus, reputation.AgentState) {
+	t.Helper()
+	rec, err := f.reg.GetRecord(agentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := f.rep.GetRecord(agentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rec.Status, rep.State
+}
+
+func TestTransition_SuspendAndReinstate(t *testing.T) {
+	f := newFixture(t)
+
+	ch, err := f.svc.Transition(lifecycle.Request{AgentID: "agent-1", Status: registry.StatusSuspended, Reason: "audit", AuthorizedBy: "admin"})
+	if err != nil {
+		t.Fatalf("suspend: %v", err)
+	}
+	if reg, rep := f.states(t, "agent-1"); reg != registry.StatusSuspended || rep != reputation.StateSuspended {
+		t.Fatalf("after suspend: registry=%s reputation=%s", reg, rep)
+	}
+	if ch.Previous != registry.StatusActive || ch.LedgerEventID == "" || ch.GovernanceEventID == "" {
+		t.Errorf("change = %+v", ch)
+	}
+
+	if _, err := f.svc.Transition(lifecycle.Request{AgentID: "agent-1", Status: registry.StatusActive, Reason: "cleared", AuthorizedBy: "admin"}); err != nil {
+		t.Fatalf("reinstate: %v", err)
+	}
+	if reg, rep := f.states(t, "agent-1"); reg != registry.StatusActive || rep != reputation.StateActive {
+		t.Fatalf("after reinstate: registry=%s reputation=%s", reg, rep)
+	}
+
+	if n := len(f.events(ledger.EventAgentStateChange)); n != 2 {
+		t.Errorf("AGENT_STATE_CHANGE events = %d, want 2", n)
+	}
+	gov := f.events(ledger.EventGovernance)
+	if len(gov) != 2 {
+		t.Fatalf("GOVERNANCE events = %d, want 2", len(gov))
+	}
+	suspended := gov[0].Payload.(govevents.GovernanceEvent)
+	reinstated := gov[1].Payload.(govevents.GovernanceEvent)
+	if suspended.EventType != govevents.TypeAgentSuspended || reinstated.EventType != govevents.TypeAgentReinstated {
+		t.Fatalf("governance types = %s, %s", suspended.EventType, reinstated.EventType)
+	}
+	sid := suspended.Payload.(govevents.AgentSuspendedPayload).SuspensionID
+	if rid := reinstated.Payload.(govevents.AgentReinstatedPayload).SuspensionID; sid == "" || rid != sid {
+		t.Errorf("suspension_id %q reinstated as %q", sid, rid)
+	}
+	if *suspended.EvidenceRef != ch.LedgerEventID {
+		t.Errorf("evidence_ref = %s, want %s", *suspended.EvidenceRef, ch.LedgerEventID)
+	}
+}
+
+func TestTransition_ScoreDrivenSuspensionReachesRegistry(t *testing.T) {
+	f := newFixture(t)
+	for i := 0; i < 6; i++ {
+		if err := f.rep.RecordEvent("agent-1", reputation.EvtPolicyViolation); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reg, rep := f.states(t, "agent-1")
+	if rep != reputation.StateSuspended || reg != registry.StatusSuspended {
+		t.Fatalf("registry=%s reputation=%s, want both suspended", reg, rep)
+	}
+	if st, _ := f.svc.Status("agent-1"); st != registry.StatusSuspended {
+		t.Errorf("Status = %s", st)
+	}
+	changes := f.events(ledger.EventAgentStateChange)
+	if len(changes) == 0 {
+		t.Fatal("no AGENT_STATE_CHANGE recorded")
+	}
+	last := changes[len(changes)-1].Payload.(map[string]interface{})
+	if last["source"] != string(lifecycle.SourceReputation) || last["new_state"] != "suspended" {
+		t.Errorf("last change = %v", last)
+	}
+	if len(f.events(ledger.EventGovernance)) != 1 {
+		t.Errorf("want one agent_suspended governance event")
+	}
+}
+
+// failingRegistry refuses every status update.
+type failingRegistry struct{ *registry.InMemoryRegistry }
+
+func (failingRegistry) UpdateStatus(string, registry.AgentStatus) error {
+	return errors.New("registry unavailable")
+}
+
+func TestTransition_ScoreDrivenRegistryFailureIsNotRecorded(t *testing.T) {
+	f := newFixture(t)
+	seed := make([]byte, ed25519.SeedSize)
+	priv := ed25519.NewKeyFromSeed(seed)
+	svc := lifecycle.NewService("org.test", failingRegistry{f.reg}, f.rep, f.led, func() (string, ed25519.PrivateKey) { return "k1", priv })
+	var errs []error
+	svc.OnRecordError = func(err error) { errs = append(errs, err) }
+
+	for i := 0; i < 6; i++ {
+		if err := f.rep.RecordEvent("agent-1", reputation.EvtPolicyViolation); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(errs) == 0 {
+		t.Fatal("registry failure not reported")
+	}
+	if n := len(f.events(ledger.EventAgentStateChange)); n != 0 {
+		t.Errorf("%d AGENT_STATE_CHANGE recorded for transitions the registry refused", n)
+	}
+}
+
+func TestTransition_UnregisteredAgentUsesReputationState(t *testing.T) {
+	f := newFixture(t)
+	if _, err := f.svc.Transition(lifecycle.Request{AgentID: "legacy", Status: registry.StatusSuspended, Source: lifecycle.SourceReputationAdmin}); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := f.svc.Status("legacy"); st != registry.StatusSuspended {
+		t.Errorf("Status = %s, want suspended", st)
+	}
+}
+
+func TestTransition_Rejections(t *testing.T) {
+	f := newFixture(t)
+	if _, err := f.svc.Transition(lifecycle.Request{AgentID: "agent-1", Status: registry.StatusSuspended}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.svc.Transition(lifecycle.Request{AgentID: "agent-1", Status: registry.StatusRestricted}); !errors.Is(err, registry.ErrInvalidTransition) {
+		t.Errorf("suspended → restricted: err = %v", err)
+	}
+	if _, err := f.svc.Transition(lifecycle.Request{AgentID: "agent-1", Status: registry.StatusRevoked, Source: lifecycle.SourceRevocation}); err != nil {
+		t.Fatal(err)
+	}
+	if _, rep := f.states(t, "agent-1"); rep != reputation.StateBanned {
+		t.Errorf("reputation = %s, want BANNED", rep)
+	}
+	if _, err := f.svc.Transition(lifecycle.Request{AgentID: "agent-1", Status: registry.StatusActive}); !errors.Is(err, registry.ErrAgentRevoked) {
+		t.Errorf("revoked → active: err = %v", err)
+	}
+}
@@ -0,0 +1,367 @@
+// Package merkle implements an RFC 6962 Merkle tree for the audit ledger.
+//
+// The ledger hash chain proves integrity only to someone holding the whole
+// prefix. The Merkle tree over the same events adds two compact proofs,
+// verifiable offline against a signed tree head:
+//
+//   - inclusion: event N is leaf N-1 of the tree of size S (O(log S) hashes);
+//   - consistency: the tree of size S2 extends the tree of size S1, i.e. no
+//     event of the first was rewritten or dropped (O(log S2) hashes).
+//
+// Hashing follows RFC 6962 §2.1 with SHA-256: leaves are hashed as
+// SHA-256(0x00 || data) and interior nodes as SHA-256(0x01 || left || right),
+// so a leaf can never be passed off as a node. Proof verification follows
+// RFC 9162 §2.1.3.2 and §2.1.4.2.
+package merkle
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/gowebpki/jcs"
+)
+
+// ─── Errors ───────────────────────────────────────────────────────────────────
+
+var (
+	// ErrTreeSize is returned for a tree size or leaf index outside the tree.
+	ErrTreeSize = errors.New("merkle: tree size or index out of range")
+
+	// ErrProofInvalid is returned when a proof does not lead to the expected root.
+	ErrProofInvalid = errors.New("merkle: proof invalid")
+
+	// ErrTreeHeadSig is returned when a tree head signature does not verify.
+	ErrTreeHeadSig = errors.New("merkle: tree head signature invalid")
+)
+
+// ─── Hashes ───────────────────────────────────────────────────────────────────
+
+// Hash is a SHA-256 tree hash. It encodes as base64url with padding, like
+// ledger event hashes.
+type Hash [sha256.Size]byte
+
+// EmptyRoot is the root of the empty tree: SHA-256 of the empty string.
+var EmptyRoot = Hash(sha256.Sum256(nil))
+
+// String returns the base64url encoding of h.
+func (h Hash) String() string { return base64.URLEncoding.EncodeToString(h[:]) }
+
+// MarshalText implements encoding.TextMarshaler.
+func (h Hash) MarshalText() ([]byte, error) { return []byte(h.String()), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (h *Hash) UnmarshalText(text []byte) error {
+	b, err := base64.URLEncoding.DecodeString(string(text))
+	if err != nil {
+		return fmt.Errorf("merkle: hash: %w", err)
+	}
+	if len(b) != len(h) {
+		return fmt.Errorf("merkle: hash: %d bytes, want %d", len(b), len(h))
+	}
+	copy(h[:], b)
+	return nil
+}
+
+// LeafHash returns the hash of a leaf holding data (RFC 6962 §2.1).
+func LeafHash(data []byte) Hash {
+	return sha256.Sum256(append([]byte{0x00}, data...))
+}
+
+// NodeHash returns the hash of the interior node with children left, right.
+func NodeHash(left, right Hash) Hash {
+	buf := make([]byte, 0, 1+2*sha256.Size)
+	buf = append(buf, 0x01)
+	buf = append(buf, left[:]...)
+	buf = append(buf, right[:]...)
+	return sha256.Sum256(buf)
+}
+
+// split returns the largest power of two smaller than n (n ≥ 2).
+func split(n int64) int64 {
+	return int64(1) << (bits.Len64(uint64(n-1)) - 1)
+}
+
+// ─── Tree ─────────────────────────────────────────────────────────────────────
+
+// Tree is an append-only Merkle tree that can produce the root of, and
+// proofs for, any of its past sizes. It is safe for concurrent use.
+//
+// levels[k][i] is the hash of the complete subtree of 2^k leaves starting at
+// leaf i·2^k, so every complete subtree is computed once, on append.
+type Tree struct {
+	mu     sync.RWMutex
+	levels [][]Hash
+}
+
+// NewTree returns an empty tree.
+func NewTree() *Tree {
+	return &Tree{levels: [][]Hash{{}}}
+}
+
+// Append adds a leaf, given by its leaf hash.
+func (t *Tree) Append(leaf Hash) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.levels[0] = append(t.levels[0], leaf)
+	for k := 0; len(t.levels[k])%2 == 0; k++ {
+		if k+1 == len(t.levels) {
+			t.levels = append(t.levels, nil)
+		}
+		n := len(t.levels[k])
+		t.levels[k+1] = append(t.levels[k+1], NodeHash(t.levels[k][n-2], t.levels[k][n-1]))
+	}
+}
+
+// Size returns the number of leaves.
+func (t *Tree) Size() int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return int64(len(t.levels[0]))
+}
+
+// Leaf returns the leaf hash at index.
+func (t *Tree) Leaf(index int64) (Hash, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if index < 0 || index >= int64(len(t.levels[0])) {
+		return Hash{}, fmt.Errorf("%w: leaf %d of %d", ErrTreeSize, index, len(t.levels[0]))
+	}
+	return t.levels[0][index], nil
+}
+
+// Root returns the root of the tree as it was at size leaves.
+func (t *Tree) Root(size int64) (Hash, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if err := t.checkSize(size); err != nil {
+		return Hash{}, err
+	}
+	if size == 0 {
+		return EmptyRoot, nil
+	}
+	return t.subtree(0, size), nil
+}
+
+// InclusionProof returns the audit path of leaf index in the tree of size
+// leaves (RFC 6962 §2.1.1).
+func (t *Tree) InclusionProof(index, size int64) ([]Hash, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if err := t.checkSize(size); err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= size {
+		return nil, fmt.Errorf("%w: leaf %d of %d", ErrTreeSize, index, size)
+	}
+	return t.path(index, 0, size), nil
+}
+
+// ConsistencyProof returns the proof that the tree of second leaves extends
+// the tree of first leaves (RFC 6962 §2.1.2). 0 < first ≤ second.
+func (t *Tree) ConsistencyProof(first, second int64) ([]Hash, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if err := t.checkSize(second); err != nil {
+		return nil, err
+	}
+	if first < 1 || first > second {
+		return nil, fmt.Errorf("%w: consistency %d → %d", ErrTreeSize, first, second)
+	}
+	return t.subproof(first, 0, second, true), nil
+}
+
+func (t *Tree) checkSize(size int64) error {
+	if size < 0 || size > int64(len(t.levels[0])) {
+		return fmt.Errorf("%w: size %d of %d", ErrTreeSize, size, len(t.levels[0]))
+	}
+	return nil
+}
+
+// subtree returns MTH(D[lo:hi]). Caller holds t.mu.
+func (t *Tree) subtree(lo, hi int64) Hash {
+	n := hi - lo
+	if n&(n-1) == 0 && lo%n == 0 {
+		return t.levels[bits.TrailingZeros64(uint64(n))][lo/n]
+	}
+	k := split(n)
+	return NodeHash(t.subtree(lo, lo+k), t.subtree(lo+k, hi))
+}
+
+// path returns PATH(m, D[lo:hi]) for the leaf at offset m. Caller holds t.mu.
+func (t *Tree) path(m, lo, hi int64) []Hash {
+	n := hi - lo
+	if n == 1 {
+		return nil
+	}
+	k := split(n)
+	if m < k {
+		return append(t.path(m, lo, lo+k), t.subtree(lo+k, hi))
+	}
+	return append(t.path(m-k, lo+k, hi), t.subtree(lo, lo+k))
+}
+
+// subproof returns SUBPROOF(m, D[lo:hi], complete). Caller holds t.mu.
+func (t *Tree) subproof(m, lo, hi int64, complete bool) []Hash {
+	n := hi - lo
+	if m == n {
+		if complete {
+			return nil
+		}
+		return []Hash{t.subtree(lo, hi)}
+	}
+	k := split(n)
+	if m <= k {
+		return append(t.subproof(m, lo, lo+k, complete), t.subtree(lo+k, hi))
+	}
+	return append(t.subproof(m-k, lo+k, hi, false), t.subtree(lo, lo+k))
+}
+
+// ─── Offline Verification ─────────────────────────────────────────────────────
+
+// VerifyInclusion checks that leaf is at index in the tree of size leaves
+// whose root is root, given its audit path (RFC 9162 §2.1.3.2).
+func VerifyInclusion(leaf Hash, index, size int64, proof []Hash, root Hash) error {
+	if index < 0 || index >= size {
+		return fmt.Errorf("%w: leaf %d of %d", ErrTreeSize, index, size)
+	}
+	fn, sn, r := index, size-1, leaf
+	for _, p := range proof {
+		if sn == 0 {
+			return fmt.Errorf("%w: audit path too long", ErrProofInvalid)
+		}
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return fmt.Errorf("%w: audit path too short", ErrProofInvalid)
+	}
+	if r != root {
+		return fmt.Errorf("%w: computed root %s != %s", ErrProofInvalid, r, root)
+	}
+	return nil
+}
+
+// VerifyConsistency checks that the tree of second leaves with root
+// secondRoot extends the tree of first leaves with root firstRoot
+// (RFC 9162 §2.1.4.2).
+func VerifyConsistency(first, second int64, firstRoot, secondRoot Hash, proof []Hash) error {
+	switch {
+	case first < 1 || first > second:
+		return fmt.Errorf("%w: consistency %d → %d", ErrTreeSize, first, second)
+	case first == second:
+		if len(proof) != 0 || firstRoot != secondRoot {
+			return fmt.Errorf("%w: equal sizes with different roots or a non-empty proof", ErrProofInvalid)
+		}
+		return nil
+	case len(proof) == 0:
+		return fmt.Errorf("%w: empty proof", ErrProofInvalid)
+	}
+	if first&(first-1) == 0 {
+		proof = append([]Hash{firstRoot}, proof...)
+	}
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return fmt.Errorf("%w: proof too long", ErrProofInvalid)
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return fmt.Errorf("%w: proof too short", ErrProofInvalid)
+	}
+	if fr != firstRoot || sr != secondRoot {
+		return fmt.Errorf("%w: computed roots do not match", ErrProofInvalid)
+	}
+	return nil
+}
+
+// ─── Signed Tree Heads ────────────────────────────────────────────────────────
+
+// TreeHeadVersion is the version of signed tree heads.
+const TreeHeadVersion = "1.0"
+
+// TreeHead is a signed commitment to the tree of one size. The Sig field
+// covers all other fields via Ed25519(SHA-256(JCS(signable))).
+type TreeHead struct {
+	Ver           string `json:"ver"`
+	InstitutionID string `json:"institution_id"`
+	TreeSize      int64  `json:"tree_size"`
+	RootHash      Hash   `json:"root_hash"`
+	Timestamp     int64  `json:"timestamp"`
+	KID           string `json:"kid,omitempty"` // institution keyring key that signed the head
+	Sig           string `json:"sig"`
+}
+
+// SignTreeHead sets th.Ver and th.KID and signs th with privKey.
+func SignTreeHead(th TreeHead, kid string, privKey ed25519.PrivateKey) (TreeHead, error) {
+	th.Ver, th.KID, th.Sig = TreeHeadVersion, kid, ""
+	digest, err := treeHeadDigest(th)
+	if err != nil {
+		return TreeHead{}, err
+	}
+	th.Sig = base64.RawURLEncoding.EncodeToString(ed25519.Sign(privKey, digest[:]))
+	return th, nil
+}
+
+// VerifyTreeHead checks the signature of th against pubKey.
+func VerifyTreeHead(th TreeHead, pubKey ed25519.PublicKey) error {
+	if th.Ver != TreeHeadVersion {
+		return fmt.Errorf("%w: unsupported version %q", ErrTreeHeadSig, th.Ver)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(th.Sig)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: malformed sig", ErrTreeHeadSig)
+	}
+	digest, err := treeHeadDigest(th)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pubKey, digest[:], sig) {
+		return ErrTreeHeadSig
+	}
+	return nil
+}
+
+func treeHeadDigest(th TreeHead) ([32]byte, error) {
+	th.Sig = ""
+	raw, err := json.Marshal(th)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("merkle: marshal tree head: %w", err)
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("merkle: jcs: %w", err)
+	}
+	return sha256.Sum256(canonical), nil
+}
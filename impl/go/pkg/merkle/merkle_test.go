@@ -0,0 +1,143 @@
+package merkle_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/merkle"
+)
+
+// mth is the reference RFC 6962 §2.1 tree hash over leaves.
+func mth(leaves []merkle.Hash) merkle.Hash {
+	switch len(leaves) {
+	case 0:
+		return merkle.EmptyRoot
+	case 1:
+		return leaves[0]
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	return merkle.NodeHash(mth(leaves[:k]), mth(leaves[k:]))
+}
+
+func buildTree(n int) (*merkle.Tree, []merkle.Hash) {
+	tr := merkle.NewTree()
+	leaves := make([]merkle.Hash, n)
+	for i := range leaves {
+		leaves[i] = merkle.LeafHash([]byte(fmt.Sprintf("event-%d", i)))
+		tr.Append(leaves[i])
+	}
+	return tr, leaves
+}
+
+func TestTree_RootMatchesReference(t *testing.T) {
+	tr, leaves := buildTree(70)
+	for size := 0; size <= len(leaves); size++ {
+		root, err := tr.Root(int64(size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root != mth(leaves[:size]) {
+			t.Fatalf("size %d: root mismatch", size)
+		}
+	}
+	if _, err := tr.Root(71); !errors.Is(err, merkle.ErrTreeSize) {
+		t.Errorf("Root(71): err = %v", err)
+	}
+}
+
+func TestTree_InclusionProofs(t *testing.T) {
+	tr, leaves := buildTree(33)
+	for size := int64(1); size <= 33; size++ {
+		root, _ := tr.Root(size)
+		for i := int64(0); i < size; i++ {
+			proof, err := tr.InclusionProof(i, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := merkle.VerifyInclusion(leaves[i], i, size, proof, root); err != nil {
+				t.Fatalf("leaf %d of %d: %v", i, size, err)
+			}
+			// The same path does not prove another leaf or position.
+			other := leaves[(i+1)%size]
+			if size > 1 && merkle.VerifyInclusion(other, i, size, proof, root) == nil {
+				t.Fatalf("leaf %d of %d: wrong leaf accepted", i, size)
+			}
+		}
+	}
+	if _, err := tr.InclusionProof(5, 5); !errors.Is(err, merkle.ErrTreeSize) {
+		t.Errorf("index = size: err = %v", err)
+	}
+}
+
+func TestTree_ConsistencyProofs(t *testing.T) {
+	tr, _ := buildTree(33)
+	for second := int64(1); second <= 33; second++ {
+		secondRoot, _ := tr.Root(second)
+		for first := int64(1); first <= second; first++ {
+			firstRoot, _ := tr.Root(first)
+			proof, err := tr.ConsistencyProof(first, second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := merkle.VerifyConsistency(first, second, firstRoot, secondRoot, proof); err != nil {
+				t.Fatalf("%d → %d: %v", first, second, err)
+			}
+			if first < second {
+				forged := merkle.LeafHash([]byte("forged"))
+				if merkle.VerifyConsistency(first, second, forged, secondRoot, proof) == nil {
+					t.Fatalf("%d → %d: forged first root accepted", first, second)
+				}
+			}
+		}
+	}
+
+	// A rewritten history is not consistent with the original.
+	origRoot, _ := tr.Root(10)
+	rewritten := merkle.NewTree()
+	_, leaves := buildTree(11)
+	leaves[3] = merkle.LeafHash([]byte("rewritten"))
+	for _, l := range leaves {
+		rewritten.Append(l)
+	}
+	proof, _ := rewritten.ConsistencyProof(10, 11)
+	newRoot, _ := rewritten.Root(11)
+	if err := merkle.VerifyConsistency(10, 11, origRoot, newRoot, proof); !errors.Is(err, merkle.ErrProofInvalid) {
+		t.Errorf("rewritten history: err = %v, want ErrProofInvalid", err)
+	}
+}
+
+func TestTreeHead_SignAndVerify(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	tr, _ := buildTree(5)
+	root, _ := tr.Root(5)
+	th, err := merkle.SignTreeHead(merkle.TreeHead{
+		InstitutionID: "org.test", TreeSize: 5, RootHash: root, Timestamp: 1_700_000_000,
+	}, "k1", priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := merkle.VerifyTreeHead(th, pub); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// The head survives a JSON round trip.
+	raw, _ := json.Marshal(th)
+	var decoded merkle.TreeHead
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := merkle.VerifyTreeHead(decoded, pub); err != nil || decoded.RootHash != root {
+		t.Fatalf("round trip: err = %v", err)
+	}
+
+	decoded.TreeSize = 6
+	if err := merkle.VerifyTreeHead(decoded, pub); !errors.Is(err, merkle.ErrTreeHeadSig) {
+		t.Errorf("tampered head: err = %v", err)
+	}
+}
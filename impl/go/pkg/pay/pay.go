@@ -1,7 +1,8 @@
 // Package pay implements ACP-PAY-1.0 (payment extension).
 //
-// Provides payment token verification, signed payment-verified event emission,
-// and an in-memory store for payment events with double-spend detection.
+// Provides settlement proof verification against a registry of settlement
+// providers, signed payment-verified event emission, and an in-memory store
+// that rejects reused proof_ids and hash-chains the events it records.
 package pay
 
 import (
@@ -27,22 +28,36 @@ var (
 	ErrPaymentConditionExpired    = errors.New("PAY-004: payment condition expired")
 	ErrDoubleSpend                = errors.New("PAY-005: double-spend detected — proof_id already recorded")
 	ErrPaymentSystemUnavailable   = errors.New("PAY-006: payment verification system unavailable")
+	ErrProofNotFound              = errors.New("PAY-007: proof_id not found")
+	ErrEventChainBroken           = errors.New("PAY-008: payment event chain broken")
 	ErrInvalidVersion             = errors.New("PAY-010: unsupported version, expected 1.0")
 )
 
+const (
+	// GenesisHash is the prev_hash of the first PAYMENT_VERIFIED event of an
+	// institution: 32 zero bytes, base64url with padding (as ACP-LEDGER-1.0 §4.2).
+	GenesisHash = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+
+	// MaxClockSkew is the tolerance, in seconds, for proof timestamps ahead
+	// of the verifier's clock.
+	MaxClockSkew = 300
+)
+
 // ─── Types ────────────────────────────────────────────────────────────────────
 
 // PaymentCondition describes the required payment terms for a capability.
 type PaymentCondition struct {
 	Amount    float64 `json:"amount"`
 	Currency  string  `json:"currency"`
+	Recipient string  `json:"recipient"` // AgentID or wallet address the proof must pay
 	ExpiresAt int64   `json:"expires_at"`
 }
 
 // SettlementProof is evidence that a payment has been settled.
 type SettlementProof struct {
 	ProofID          string                 `json:"proof_id"`
-	Type             string                 `json:"type"` // "on-chain" | "off-chain-channel" | "corporate-ledger"
+	Type             string                 `json:"type"`        // "on-chain" | "off-chain-channel" | "corporate-ledger"
+	ProviderID       string                 `json:"provider_id"` // the settlement provider that signed it
 	Amount           float64                `json:"amount"`
 	Currency         string                 `json:"currency"`
 	Recipient        string                 `json:"recipient"`
@@ -103,68 +118,150 @@ type VerifyRequest struct {
 
 // ─── Core Functions ───────────────────────────────────────────────────────────
 
-// VerifyToken validates an ACPPayToken and, on success, creates and signs a
-// PaymentVerifiedEvent (ver="1.0", EventType="PAYMENT_VERIFIED").
+// VerifyToken validates an ACPPayToken against the settlement providers and,
+// on success, records a signed PaymentVerifiedEvent (ver="1.0",
+// EventType="PAYMENT_VERIFIED") chained to the previous event in store.
+//
+// Validation order (ACP-PAY-1.0 §7):
+//  1. condition and proof fields present, positive amounts → ErrMalformedPaymentCondition
+//  2. PaymentCondition.ExpiresAt >= now                    → ErrPaymentConditionExpired
+//  3. a provider is registered for the proof type          → ErrPaymentSystemUnavailable
+//  4. proof signed by its provider_id, registered for its type,
+//     not future-dated, currency and recipient match the
+//     condition                                            → ErrInvalidSettlementProof
+//  5. proof amount >= condition amount                     → ErrInsufficientAmount
+//  6. proof_id not already recorded                        → ErrDoubleSpend
 //
-// Validation order:
-//  1. req.Token.Proof.ProofID not empty → ErrMalformedPaymentCondition
-//  2. PaymentCondition.ExpiresAt >= now → ErrPaymentConditionExpired
-//  3. PaymentCondition.Amount > 0       → ErrInsufficientAmount
-func VerifyToken(req VerifyRequest, now int64, privKey ed25519.PrivateKey) (PaymentVerifiedEvent, error) {
-	if req.Token.Proof.ProofID == "" {
-		return PaymentVerifiedEvent{}, ErrMalformedPaymentCondition
+// commit, if not nil, records the event elsewhere (e.g. the audit ledger)
+// before the store does; if it fails, its error is returned and the proof is
+// not marked spent, so it can be presented again.
+func VerifyToken(req VerifyRequest, now int64, providers *ProviderRegistry, store *InMemoryPayStore, privKey ed25519.PrivateKey, commit func(PaymentVerifiedEvent) error) (PaymentVerifiedEvent, error) {
+	cond, proof := req.Token.PaymentCondition, req.Token.Proof
+	switch {
+	case proof.ProofID == "":
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: proof.proof_id is required", ErrMalformedPaymentCondition)
+	case cond.Currency == "" || cond.Recipient == "" || cond.ExpiresAt == 0:
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: currency, recipient and expires_at are required", ErrMalformedPaymentCondition)
+	case cond.Amount <= 0:
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: amount must be positive", ErrInsufficientAmount)
 	}
-	if req.Token.PaymentCondition.ExpiresAt < now {
+	if cond.ExpiresAt < now {
 		return PaymentVerifiedEvent{}, ErrPaymentConditionExpired
 	}
-	if req.Token.PaymentCondition.Amount <= 0 {
-		return PaymentVerifiedEvent{}, ErrInsufficientAmount
+	if err := providers.VerifyProof(proof); err != nil {
+		return PaymentVerifiedEvent{}, err
+	}
+	switch {
+	case proof.Timestamp > now+MaxClockSkew:
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: proof timestamp is in the future", ErrInvalidSettlementProof)
+	case proof.Currency != cond.Currency:
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: proof currency %q, condition requires %q", ErrInvalidSettlementProof, proof.Currency, cond.Currency)
+	case proof.Recipient != cond.Recipient:
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: proof recipient %q, condition requires %q", ErrInvalidSettlementProof, proof.Recipient, cond.Recipient)
+	case proof.Amount < cond.Amount:
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: paid %.2f %s, required %.2f %s", ErrInsufficientAmount, proof.Amount, proof.Currency, cond.Amount, cond.Currency)
+	}
+	if len(privKey) != ed25519.PrivateKeySize {
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: no signing key", ErrPaymentSystemUnavailable)
 	}
 
 	eventID, err := newUUID()
 	if err != nil {
 		return PaymentVerifiedEvent{}, fmt.Errorf("pay: generate event_id: %w", err)
 	}
+	return store.Record(proof, func(prevHash string) (PaymentVerifiedEvent, error) {
+		ev := PaymentVerifiedEvent{
+			Ver:          "1.0",
+			EventID:      eventID,
+			EventType:    "PAYMENT_VERIFIED",
+			Timestamp:    time.Now().Unix(),
+			AgentID:      req.AgentID,
+			InstitutionID: req.InstitutionID,
+			ProofID:      proof.ProofID,
+			Resource:     req.Resource,
+			CapabilityID: req.CapabilityID,
+			Amount:       proof.Amount,
+			Currency:     proof.Currency,
+			PrevHash:     prevHash,
+		}
+		sig, err := signEvent(ev, privKey)
+		if err != nil {
+			return PaymentVerifiedEvent{}, fmt.Errorf("pay: sign event: %w", err)
+		}
+		ev.Sig = sig
+		return ev, nil
+	}, commit)
+}
 
-	ev := PaymentVerifiedEvent{
-		Ver:          "1.0",
-		EventID:      eventID,
-		EventType:    "PAYMENT_VERIFIED",
-		Timestamp:    time.Now().Unix(),
-		AgentID:      req.AgentID,
-		InstitutionID: req.InstitutionID,
-		ProofID:      req.Token.Proof.ProofID,
-		Resource:     req.Resource,
-		CapabilityID: req.CapabilityID,
-		Amount:       req.Token.PaymentCondition.Amount,
-		Currency:     req.Token.PaymentCondition.Currency,
-		PrevHash:     "",
+// GetProof retrieves the SettlementProof recorded for proofID.
+func GetProof(store *InMemoryPayStore, proofID string) (SettlementProof, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	p, ok := store.proofs[proofID]
+	return p, ok
+}
+
+// VerifyChain checks that events form a single chain starting at GenesisHash
+// and that every event is signed by pub.
+func VerifyChain(events []PaymentVerifiedEvent, pub ed25519.PublicKey) error {
+	prev := GenesisHash
+	for i, ev := range events {
+		if ev.PrevHash != prev {
+			return fmt.Errorf("%w: event %d (%s) prev_hash mismatch", ErrEventChainBroken, i, ev.EventID)
+		}
+		if err := VerifyEventSig(ev, pub); err != nil {
+			return fmt.Errorf("%w: event %d (%s): %v", ErrEventChainBroken, i, ev.EventID, err)
+		}
+		h, err := EventHash(ev)
+		if err != nil {
+			return err
+		}
+		prev = h
 	}
+	return nil
+}
 
-	sig, err := signEvent(ev, privKey)
+// EventHash returns base64url(SHA-256(JCS(ev))), the prev_hash of the event
+// that follows ev.
+func EventHash(ev PaymentVerifiedEvent) (string, error) {
+	raw, err := json.Marshal(ev)
 	if err != nil {
-		return PaymentVerifiedEvent{}, fmt.Errorf("pay: sign event: %w", err)
+		return "", fmt.Errorf("marshal: %w", err)
 	}
-	ev.Sig = sig
-	return ev, nil
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return "", fmt.Errorf("jcs: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return base64.URLEncoding.EncodeToString(sum[:]), nil
 }
 
-// GetProof retrieves the SettlementProof from a stored PaymentVerifiedEvent by ProofID.
-func GetProof(store *InMemoryPayStore, proofID string) (SettlementProof, bool) {
-	ev, ok := store.GetEvent(proofID)
-	if !ok {
-		return SettlementProof{}, false
+// VerifyEventSig verifies ev.Sig against pub.
+func VerifyEventSig(ev PaymentVerifiedEvent, pub ed25519.PublicKey) error {
+	digest, err := eventDigest(ev)
+	if err != nil {
+		return err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(ev.Sig)
+	if err != nil || !ed25519.Verify(pub, digest, sig) {
+		return errors.New("invalid event signature")
 	}
-	return SettlementProof{
-		ProofID: ev.ProofID,
-		Amount:  ev.Amount,
-		Currency: ev.Currency,
-	}, true
+	return nil
 }
 
 // ─── Signing Helper ───────────────────────────────────────────────────────────
 
 func signEvent(ev PaymentVerifiedEvent, privKey ed25519.PrivateKey) (string, error) {
+	digest, err := eventDigest(ev)
+	if err != nil {
+		return "", err
+	}
+	sig := ed25519.Sign(privKey, digest)
+	return base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// eventDigest is SHA-256(JCS(ev with sig="")).
+func eventDigest(ev PaymentVerifiedEvent) ([]byte, error) {
 	s := signableEvent{
 		Ver:          ev.Ver,
 		EventID:      ev.EventID,
@@ -182,46 +279,72 @@ func signEvent(ev PaymentVerifiedEvent, privKey ed25519.PrivateKey) (string, err
 	}
 	raw, err := json.Marshal(s)
 	if err != nil {
-		return "", fmt.Errorf("marshal: %w", err)
+		return nil, fmt.Errorf("marshal: %w", err)
 	}
 	canonical, err := jcs.Transform(raw)
 	if err != nil {
-		return "", fmt.Errorf("jcs: %w", err)
+		return nil, fmt.Errorf("jcs: %w", err)
 	}
 	digest := sha256.Sum256(canonical)
-	sig := ed25519.Sign(privKey, digest[:])
-	return base64.RawURLEncoding.EncodeToString(sig), nil
+	return digest[:], nil
 }
 
 // ─── InMemoryPayStore ─────────────────────────────────────────────────────────
 
 // InMemoryPayStore is a thread-safe in-memory store for PaymentVerifiedEvents.
-// Keyed by ProofID to enable double-spend detection.
+// Keyed by ProofID to enable double-spend detection; events are kept in
+// chain order.
 type InMemoryPayStore struct {
-	mu     sync.RWMutex
+	mu      sync.RWMutex
 	byProof map[string]PaymentVerifiedEvent // proof_id → event
+	proofs  map[string]SettlementProof      // proof_id → verified proof
 	byAgent map[string][]string             // agent_id → []proof_id
+	events  []PaymentVerifiedEvent          // chain order
+	head    string                          // hash of the last event
 }
 
 // NewInMemoryPayStore creates an empty payment store.
 func NewInMemoryPayStore() *InMemoryPayStore {
 	return &InMemoryPayStore{
 		byProof: make(map[string]PaymentVerifiedEvent),
+		proofs:  make(map[string]SettlementProof),
 		byAgent: make(map[string][]string),
+		head:    GenesisHash,
 	}
 }
 
-// StoreEvent stores a PaymentVerifiedEvent.
+// Record atomically checks proof for double-spend, builds the event with the
+// current chain head as prev_hash, passes it to commit (if not nil) and
+// appends it. Nothing is stored if build or commit fails.
 // Returns ErrDoubleSpend if the ProofID is already present.
-func (s *InMemoryPayStore) StoreEvent(ev PaymentVerifiedEvent) error {
+func (s *InMemoryPayStore) Record(proof SettlementProof, build func(prevHash string) (PaymentVerifiedEvent, error), commit func(PaymentVerifiedEvent) error) (PaymentVerifiedEvent, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, exists := s.byProof[ev.ProofID]; exists {
-		return fmt.Errorf("%w: %s", ErrDoubleSpend, ev.ProofID)
+	if _, exists := s.byProof[proof.ProofID]; exists {
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: %s", ErrDoubleSpend, proof.ProofID)
+	}
+	ev, err := build(s.head)
+	if err != nil {
+		return PaymentVerifiedEvent{}, err
+	}
+	if ev.ProofID != proof.ProofID || ev.PrevHash != s.head {
+		return PaymentVerifiedEvent{}, fmt.Errorf("%w: event does not extend the chain", ErrEventChainBroken)
+	}
+	h, err := EventHash(ev)
+	if err != nil {
+		return PaymentVerifiedEvent{}, err
+	}
+	if commit != nil {
+		if err := commit(ev); err != nil {
+			return PaymentVerifiedEvent{}, err
+		}
 	}
 	s.byProof[ev.ProofID] = ev
+	s.proofs[ev.ProofID] = proof
 	s.byAgent[ev.AgentID] = append(s.byAgent[ev.AgentID], ev.ProofID)
-	return nil
+	s.events = append(s.events, ev)
+	s.head = h
+	return ev, nil
 }
 
 // GetEvent retrieves a PaymentVerifiedEvent by ProofID.
@@ -249,6 +372,20 @@ func (s *InMemoryPayStore) ListByAgent(agentID string) []PaymentVerifiedEvent {
 	return result
 }
 
+// Events returns all events in chain order.
+func (s *InMemoryPayStore) Events() []PaymentVerifiedEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]PaymentVerifiedEvent(nil), s.events...)
+}
+
+// Head returns the hash of the last recorded event (GenesisHash if none).
+func (s *InMemoryPayStore) Head() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.head
+}
+
 // Size returns the total number of stored events.
 func (s *InMemoryPayStore) Size() int {
 	s.mu.RLock()
@@ -0,0 +1,207 @@
+package pay_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/pay"
+)
+
+func key(b byte) ed25519.PrivateKey {
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = b
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+var (
+	providerKey    = key(1)
+	institutionKey = key(2)
+)
+
+func providers(t *testing.T) *pay.ProviderRegistry {
+	t.Helper()
+	r := pay.NewProviderRegistry()
+	for id, k := range map[string]ed25519.PrivateKey{"ledger-bank": providerKey, "other-bank": key(3)} {
+		if err := r.Register(pay.Provider{
+			ProviderID: id, Type: pay.ProofCorporateLedger,
+			PublicKey: k.Public().(ed25519.PublicKey),
+		}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+// request builds a request whose proof pays exactly the condition, signed by priv.
+func request(t *testing.T, proofID string, now int64, priv ed25519.PrivateKey) pay.VerifyRequest {
+	t.Helper()
+	proof := pay.SettlementProof{
+		ProofID: proofID, Type: pay.ProofCorporateLedger, ProviderID: "ledger-bank",
+		Amount: 100, Currency: "USD", Recipient: "agent-receiver", Timestamp: now - 10,
+	}
+	sig, err := pay.SignProof(proof, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.Sig = sig
+	return pay.VerifyRequest{
+		Token: pay.ACPPayToken{
+			CapabilityClaim:  "cap-1",
+			PaymentCondition: pay.PaymentCondition{Amount: 100, Currency: "USD", Recipient: "agent-receiver", ExpiresAt: now + 3600},
+			Proof:            proof,
+		},
+		AgentID: "agent-payer", InstitutionID: "org.example", Resource: "org.example/report", CapabilityID: "cap-1",
+	}
+}
+
+// resign re-signs the proof of req after a modification.
+func resign(t *testing.T, req *pay.VerifyRequest) {
+	t.Helper()
+	sig, err := pay.SignProof(req.Token.Proof, providerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Token.Proof.Sig = sig
+}
+
+func TestVerifyToken_ChainAndLookup(t *testing.T) {
+	now := time.Now().Unix()
+	reg, store := providers(t), pay.NewInMemoryPayStore()
+
+	var events []pay.PaymentVerifiedEvent
+	for _, id := range []string{"proof-1", "proof-2", "proof-3"} {
+		ev, err := pay.VerifyToken(request(t, id, now, providerKey), now, reg, store, institutionKey, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		events = append(events, ev)
+	}
+	if events[0].PrevHash != pay.GenesisHash {
+		t.Errorf("first prev_hash = %q, want GenesisHash", events[0].PrevHash)
+	}
+	if h, _ := pay.EventHash(events[1]); events[2].PrevHash != h {
+		t.Error("third event does not chain to second")
+	}
+	pub := institutionKey.Public().(ed25519.PublicKey)
+	if err := pay.VerifyChain(store.Events(), pub); err != nil {
+		t.Errorf("VerifyChain: %v", err)
+	}
+
+	tampered := store.Events()
+	tampered[1].Amount = 1
+	if err := pay.VerifyChain(tampered, pub); !errors.Is(err, pay.ErrEventChainBroken) {
+		t.Errorf("tampered chain: err = %v", err)
+	}
+
+	proof, ok := pay.GetProof(store, "proof-2")
+	if !ok || proof.Recipient != "agent-receiver" || proof.Type != pay.ProofCorporateLedger {
+		t.Errorf("GetProof = %+v, %v", proof, ok)
+	}
+	if len(store.ListByAgent("agent-payer")) != 3 {
+		t.Errorf("ListByAgent = %d events, want 3", len(store.ListByAgent("agent-payer")))
+	}
+}
+
+func TestVerifyToken_Rejections(t *testing.T) {
+	now := time.Now().Unix()
+	reg := providers(t)
+
+	cases := []struct {
+		name   string
+		mutate func(*pay.VerifyRequest)
+		want   error
+	}{
+		{"missing recipient", func(r *pay.VerifyRequest) { r.Token.PaymentCondition.Recipient = "" }, pay.ErrMalformedPaymentCondition},
+		{"expired condition", func(r *pay.VerifyRequest) { r.Token.PaymentCondition.ExpiresAt = now - 1 }, pay.ErrPaymentConditionExpired},
+		{"unsupported type", func(r *pay.VerifyRequest) { r.Token.Proof.Type = pay.ProofOnChain; resign(t, r) }, pay.ErrPaymentSystemUnavailable},
+		{"tampered amount", func(r *pay.VerifyRequest) { r.Token.Proof.Amount = 1000 }, pay.ErrInvalidSettlementProof},
+		{"wrong signer", func(r *pay.VerifyRequest) { *r = request(t, "p", now, key(9)) }, pay.ErrInvalidSettlementProof},
+		{"signed by another provider", func(r *pay.VerifyRequest) { *r = request(t, "p", now, key(3)) }, pay.ErrInvalidSettlementProof},
+		{"unknown provider", func(r *pay.VerifyRequest) { r.Token.Proof.ProviderID = "nobody"; resign(t, r) }, pay.ErrInvalidSettlementProof},
+		{"wrong recipient", func(r *pay.VerifyRequest) { r.Token.Proof.Recipient = "agent-other"; resign(t, r) }, pay.ErrInvalidSettlementProof},
+		{"wrong currency", func(r *pay.VerifyRequest) { r.Token.Proof.Currency = "EUR"; resign(t, r) }, pay.ErrInvalidSettlementProof},
+		{"future proof", func(r *pay.VerifyRequest) { r.Token.Proof.Timestamp = now + 3600; resign(t, r) }, pay.ErrInvalidSettlementProof},
+		{"underpaid", func(r *pay.VerifyRequest) { r.Token.Proof.Amount = 50; resign(t, r) }, pay.ErrInsufficientAmount},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := request(t, "p", now, providerKey)
+			tc.mutate(&req)
+			store := pay.NewInMemoryPayStore()
+			if _, err := pay.VerifyToken(req, now, reg, store, institutionKey, nil); !errors.Is(err, tc.want) {
+				t.Errorf("err = %v, want %v", err, tc.want)
+			}
+			if store.Size() != 0 {
+				t.Error("rejected payment was recorded")
+			}
+		})
+	}
+}
+
+func TestVerifyToken_DoubleSpend(t *testing.T) {
+	now := time.Now().Unix()
+	reg, store := providers(t), pay.NewInMemoryPayStore()
+	req := request(t, "proof-once", now, providerKey)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = pay.VerifyToken(req, now, reg, store, institutionKey, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	ok := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case !errors.Is(err, pay.ErrDoubleSpend):
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if ok != 1 || store.Size() != 1 {
+		t.Errorf("accepted %d times, stored %d; want exactly one", ok, store.Size())
+	}
+}
+
+func TestVerifyToken_CommitFailureLeavesProofUnspent(t *testing.T) {
+	now := time.Now().Unix()
+	reg, store := providers(t), pay.NewInMemoryPayStore()
+	req := request(t, "proof-retry", now, providerKey)
+
+	errLedger := errors.New("ledger unavailable")
+	if _, err := pay.VerifyToken(req, now, reg, store, institutionKey, func(pay.PaymentVerifiedEvent) error { return errLedger }); !errors.Is(err, errLedger) {
+		t.Fatalf("err = %v, want the commit error", err)
+	}
+	if store.Size() != 0 {
+		t.Fatal("proof marked spent although the commit failed")
+	}
+	var committed pay.PaymentVerifiedEvent
+	ev, err := pay.VerifyToken(req, now, reg, store, institutionKey, func(ev pay.PaymentVerifiedEvent) error { committed = ev; return nil })
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if committed.EventID != ev.EventID || ev.PrevHash != pay.GenesisHash {
+		t.Errorf("committed %+v, returned %+v", committed, ev)
+	}
+}
+
+func TestProviderRegistry_NoReplacement(t *testing.T) {
+	reg := providers(t)
+	for _, typ := range []string{pay.ProofCorporateLedger, pay.ProofOnChain} {
+		err := reg.Register(pay.Provider{ProviderID: "ledger-bank", Type: typ, PublicKey: key(9).Public().(ed25519.PublicKey)}, nil)
+		if !errors.Is(err, pay.ErrProviderExists) {
+			t.Errorf("re-register as %s: err = %v", typ, err)
+		}
+	}
+	if err := reg.VerifyProof(request(t, "p", time.Now().Unix(), providerKey).Token.Proof); err != nil {
+		t.Errorf("original key no longer verifies: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package pay
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gowebpki/jcs"
+)
+
+// ─── Settlement Providers (ACP-PAY-1.0 §4) ───────────────────────────────────
+//
+// A settlement provider is the party that attests a payment was settled: a
+// chain oracle, a payment-channel node or a corporate ledger. Each provider
+// is registered once, with the proof type it attests and its Ed25519 key; a
+// SettlementProof is valid only if its sig verifies under the key of the
+// provider it names, registered for its type.
+
+// Settlement proof types (ACP-PAY-1.0 §4.2).
+const (
+	ProofOnChain         = "on-chain"
+	ProofOffChainChannel = "off-chain-channel"
+	ProofCorporateLedger = "corporate-ledger"
+)
+
+var (
+	// ErrInvalidProvider is returned when a provider registration is incomplete.
+	ErrInvalidProvider = errors.New("pay: invalid settlement provider")
+
+	// ErrProviderExists is returned when a provider_id is registered again:
+	// a provider key is never replaced.
+	ErrProviderExists = errors.New("pay: settlement provider already registered")
+)
+
+// ValidProofType reports whether t is a settlement proof type of §4.2.
+func ValidProofType(t string) bool {
+	return t == ProofOnChain || t == ProofOffChainChannel || t == ProofCorporateLedger
+}
+
+// Provider is a registered settlement provider.
+type Provider struct {
+	ProviderID string            `json:"provider_id"`
+	Type       string            `json:"type"`
+	PublicKey  ed25519.PublicKey `json:"-"`
+}
+
+// ProviderRegistry holds the settlement providers trusted for each proof
+// type. It is safe for concurrent use.
+type ProviderRegistry struct {
+	mu     sync.RWMutex
+	byType map[string]map[string]Provider // type → provider_id → provider
+}
+
+// NewProviderRegistry returns an empty registry.
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{byType: make(map[string]map[string]Provider)}
+}
+
+// Register adds p. Returns ErrProviderExists if p.ProviderID is registered,
+// for any type. commit, if not nil, records the registration (e.g. in the
+// audit ledger) before it takes effect; if it fails, p is not added.
+func (r *ProviderRegistry) Register(p Provider, commit func(Provider) error) error {
+	if p.ProviderID == "" || !ValidProofType(p.Type) || len(p.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: need provider_id, a type of %s|%s|%s and a 32-byte key",
+			ErrInvalidProvider, ProofOnChain, ProofOffChainChannel, ProofCorporateLedger)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for t, byID := range r.byType {
+		if _, ok := byID[p.ProviderID]; ok {
+			return fmt.Errorf("%w: %s (%s)", ErrProviderExists, p.ProviderID, t)
+		}
+	}
+	if commit != nil {
+		if err := commit(p); err != nil {
+			return err
+		}
+	}
+	if r.byType[p.Type] == nil {
+		r.byType[p.Type] = make(map[string]Provider)
+	}
+	r.byType[p.Type][p.ProviderID] = p
+	return nil
+}
+
+// Providers returns the providers registered for proofType, ordered by ID.
+func (r *ProviderRegistry) Providers(proofType string) []Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Provider, 0, len(r.byType[proofType]))
+	for _, p := range r.byType[proofType] {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ProviderID < out[j].ProviderID })
+	return out
+}
+
+// VerifyProof checks that proof is signed by the provider it names, which
+// must be registered for its type. Returns ErrPaymentSystemUnavailable when
+// no provider handles the type and ErrInvalidSettlementProof when the
+// provider is not registered for it or the signature does not verify.
+func (r *ProviderRegistry) VerifyProof(proof SettlementProof) error {
+	r.mu.RLock()
+	byID := r.byType[proof.Type]
+	p, ok := byID[proof.ProviderID]
+	r.mu.RUnlock()
+	if len(byID) == 0 {
+		return fmt.Errorf("%w: no settlement provider for proof type %q", ErrPaymentSystemUnavailable, proof.Type)
+	}
+	if !ok {
+		return fmt.Errorf("%w: provider_id %q is not a registered %s provider", ErrInvalidSettlementProof, proof.ProviderID, proof.Type)
+	}
+	digest, err := proofDigest(proof)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSettlementProof, err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(proof.Sig)
+	if err != nil {
+		return fmt.Errorf("%w: sig must be base64url", ErrInvalidSettlementProof)
+	}
+	if !ed25519.Verify(p.PublicKey, digest, sig) {
+		return fmt.Errorf("%w: sig does not verify under provider %s", ErrInvalidSettlementProof, p.ProviderID)
+	}
+	return nil
+}
+
+// SignProof signs proof as a settlement provider: Ed25519 over
+// SHA-256(JCS(proof with sig="")), base64url without padding.
+func SignProof(proof SettlementProof, privKey ed25519.PrivateKey) (string, error) {
+	digest, err := proofDigest(proof)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(ed25519.Sign(privKey, digest)), nil
+}
+
+func proofDigest(proof SettlementProof) ([]byte, error) {
+	proof.Sig = ""
+	raw, err := json.Marshal(proof)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return nil, fmt.Errorf("jcs: %w", err)
+	}
+	digest := sha256.Sum256(canonical)
+	return digest[:], nil
+}
@@ -18,10 +18,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gowebpki/jcs"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
 )
 
 // ─── Error Sentinels (ACP-PROVENANCE-1.0 §10) ────────────────────────────────
@@ -137,6 +140,29 @@ func Issue(req IssueRequest, privKey ed25519.PrivateKey) (AuthorityProvenance, e
 	return ap, nil
 }
 
+// StepsFromTokens maps a verified ACP-CT-1.0 delegation chain (root first) to
+// delegation steps (§4.3). Each token is one step: its issuer delegates to its
+// subject. delegation_id is the token nonce (its token ID),
+// capability_subset the comma-separated cap list, and delegation_sig the
+// issuer's signature over the token. An empty chain yields an empty,
+// non-nil slice (minimal provenance, §9).
+func StepsFromTokens(chain []*tokens.CapabilityToken) []DelegationStep {
+	steps := make([]DelegationStep, len(chain))
+	for i, t := range chain {
+		steps[i] = DelegationStep{
+			Step:             i + 1,
+			Delegator:        t.Issuer,
+			Executor:         t.Subject,
+			DelegationID:     t.Nonce,
+			CapabilitySubset: strings.Join(t.Cap, ","),
+			DelegatedAt:      t.IssuedAt,
+			ValidUntil:       t.Expiration,
+			DelegationSig:    t.Signature,
+		}
+	}
+	return steps
+}
+
 // ─── Validation ────────────────────────────────────────────────────────────────
 
 // VerifySig verifies the institutional signature on an AuthorityProvenance object (§5).
@@ -27,6 +27,8 @@ import (
 	"time"
 
 	"github.com/gowebpki/jcs"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/budget"
 )
 
 // ─── Error Sentinels (ACP-PSN-1.0 §12) ───────────────────────────────────────
@@ -39,6 +41,7 @@ var (
 	ErrNoActiveSnapshot      = errors.New("PSN-005: no active snapshot exists — invalid system state")
 	ErrInvalidThresholds     = errors.New("PSN-006: invalid thresholds — values out of range or incorrect structure")
 	ErrRequiredField         = errors.New("PSN-007: required field missing")
+	ErrInvalidBudgets        = errors.New("PSN-008: invalid budgets")
 )
 
 // ─── Types (ACP-PSN-1.0 §4) ───────────────────────────────────────────────────
@@ -70,6 +73,7 @@ type PolicySnapshot struct {
 	ContextFactors     map[string]int         `json:"context_factors,omitempty"`
 	ResourceFactors    map[string]int         `json:"resource_factors,omitempty"`
 	CustomFactors      map[string]int         `json:"custom_factors,omitempty"`
+	Budgets            []budget.Budget        `json:"budgets,omitempty"` // cumulative spend limits
 	CreatedAt          int64                  `json:"created_at"`
 	CreatedBy          string                 `json:"created_by"`
 	Sig                string                 `json:"sig"`
@@ -103,6 +107,7 @@ type CreateRequest struct {
 	ContextFactors      map[string]int
 	ResourceFactors     map[string]int
 	CustomFactors       map[string]int
+	Budgets             []budget.Budget
 	CreatedBy           string
 }
 
@@ -114,6 +119,7 @@ type TransitionRequest struct {
 	ContextFactors      map[string]int
 	ResourceFactors     map[string]int
 	CustomFactors       map[string]int
+	Budgets             []budget.Budget
 	CreatedBy           string
 }
 
@@ -136,6 +142,7 @@ type signableSnapshot struct {
 	ContextFactors      map[string]int         `json:"context_factors,omitempty"`
 	ResourceFactors     map[string]int         `json:"resource_factors,omitempty"`
 	CustomFactors       map[string]int         `json:"custom_factors,omitempty"`
+	Budgets             []budget.Budget        `json:"budgets,omitempty"`
 	CreatedAt           int64                  `json:"created_at"`
 	CreatedBy           string                 `json:"created_by"`
 	Sig                 string                 `json:"sig"` // always "" when signing
@@ -157,6 +164,9 @@ func Create(req CreateRequest, privKey ed25519.PrivateKey) (PolicySnapshot, erro
 	if err := validateThresholds(req.Thresholds); err != nil {
 		return PolicySnapshot{}, err
 	}
+	if err := budget.ValidateAll(req.Budgets); err != nil {
+		return PolicySnapshot{}, fmt.Errorf("%w: %v", ErrInvalidBudgets, err)
+	}
 
 	now := time.Now().Unix()
 	id, err := newUUID()
@@ -176,6 +186,7 @@ func Create(req CreateRequest, privKey ed25519.PrivateKey) (PolicySnapshot, erro
 		ContextFactors:      req.ContextFactors,
 		ResourceFactors:     req.ResourceFactors,
 		CustomFactors:       req.CustomFactors,
+		Budgets:             req.Budgets,
 		CreatedAt:           now,
 		CreatedBy:           req.CreatedBy,
 	}
@@ -201,6 +212,9 @@ func Transition(store *InMemorySnapshotStore, req TransitionRequest, privKey ed2
 	if err := validateThresholds(req.Thresholds); err != nil {
 		return TransitionResult{}, err
 	}
+	if err := budget.ValidateAll(req.Budgets); err != nil {
+		return TransitionResult{}, fmt.Errorf("%w: %v", ErrInvalidBudgets, err)
+	}
 
 	store.mu.Lock()
 	defer store.mu.Unlock()
@@ -229,6 +243,7 @@ func Transition(store *InMemorySnapshotStore, req TransitionRequest, privKey ed2
 		ContextFactors:      req.ContextFactors,
 		ResourceFactors:     req.ResourceFactors,
 		CustomFactors:       req.CustomFactors,
+		Budgets:             req.Budgets,
 		CreatedAt:           now,
 		CreatedBy:           req.CreatedBy,
 	}
@@ -282,6 +297,7 @@ func VerifySig(snap PolicySnapshot, pubKey ed25519.PublicKey) error {
 		ContextFactors:      snap.ContextFactors,
 		ResourceFactors:     snap.ResourceFactors,
 		CustomFactors:       snap.CustomFactors,
+		Budgets:             snap.Budgets,
 		CreatedAt:           snap.CreatedAt,
 		CreatedBy:           snap.CreatedBy,
 		Sig:                 "",
@@ -305,6 +321,39 @@ func VerifySig(snap PolicySnapshot, pubKey ed25519.PublicKey) error {
 	return nil
 }
 
+// Hash returns the SHA-256 hex digest of the policy document of snap: its
+// canonical JSON (JCS) with effective_until and sig cleared, so the digest is
+// the same before and after the snapshot is superseded. Used as policy_hash
+// by ACP-PROVENANCE-1.0 §4.2.
+func Hash(snap PolicySnapshot) (string, error) {
+	s := signableSnapshot{
+		Ver:                 snap.Ver,
+		SnapshotID:          snap.SnapshotID,
+		InstitutionID:       snap.InstitutionID,
+		PolicyVersion:       snap.PolicyVersion,
+		EffectiveFrom:       snap.EffectiveFrom,
+		EffectiveUntil:      nil,
+		Thresholds:          snap.Thresholds,
+		CapabilityBaselines: snap.CapabilityBaselines,
+		ContextFactors:      snap.ContextFactors,
+		ResourceFactors:     snap.ResourceFactors,
+		CustomFactors:       snap.CustomFactors,
+		Budgets:             snap.Budgets,
+		CreatedAt:           snap.CreatedAt,
+		CreatedBy:           snap.CreatedBy,
+		Sig:                 "",
+	}
+	raw, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("psn: marshal policy: %w", err)
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return "", fmt.Errorf("psn: jcs: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(canonical)), nil
+}
+
 // ─── In-memory Store ──────────────────────────────────────────────────────────
 
 // InMemorySnapshotStore is a thread-safe store for PolicySnapshot objects.
@@ -408,6 +457,7 @@ func signSnapshot(snap PolicySnapshot, privKey ed25519.PrivateKey) (string, erro
 		ContextFactors:      snap.ContextFactors,
 		ResourceFactors:     snap.ResourceFactors,
 		CustomFactors:       snap.CustomFactors,
+		Budgets:             snap.Budgets,
 		CreatedAt:           snap.CreatedAt,
 		CreatedBy:           snap.CreatedBy,
 		Sig:                 "",
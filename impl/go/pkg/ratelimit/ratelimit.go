@@ -0,0 +1,305 @@
+// Package ratelimit enforces per-agent request quotas with token buckets.
+//
+// Every (institution, agent, client, route) has its own bucket; a Key without
+// an agent is a bucket of the whole institution, as for the ACP-BULK-1.0 §4
+// limit on bulk requests (BulkLimit), and Client scopes the bucket of a caller
+// that did not authenticate the agent to the caller's address. A bucket holds up to
+// Burst tokens and refills at Rate tokens per second; each request takes one
+// token, and a request finding the bucket empty is rejected with the time
+// until the next token (the Retry-After of the 429 response). The Limit of a
+// bucket depends on the autonomy level of the agent, so that the agents
+// trusted with more autonomy get more throughput.
+//
+// Bucket state lives behind the Store interface so that replicas can share
+// it; MemoryStore keeps it in process. The Limiter also tells the caller
+// when a rejection should count against the agent's reputation (Penalize):
+// once per PenaltyInterval, not once per rejected request, so that a client
+// bursting past its quota is flagged without being suspended outright.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/bulk"
+)
+
+// ─── Errors ───────────────────────────────────────────────────────────────────
+
+var (
+	// ErrRateLimitExceeded is returned by Allow when the bucket is empty.
+	// It is the ACP-BULK-1.0 BULK-002 error.
+	ErrRateLimitExceeded = bulk.ErrRateLimitExceeded
+
+	// ErrInvalidLimits is returned by ParseLimits for a malformed spec.
+	ErrInvalidLimits = errors.New("ratelimit: invalid limits")
+)
+
+// ─── Limits ───────────────────────────────────────────────────────────────────
+
+// Limit is the refill rate and capacity of a bucket. A Limit with Rate ≤ 0
+// does not limit.
+type Limit struct {
+	Rate  float64 `json:"rate"`  // tokens per second
+	Burst int     `json:"burst"` // bucket capacity
+}
+
+// Unlimited reports whether l lets every request through.
+func (l Limit) Unlimited() bool { return l.Rate <= 0 }
+
+// String formats l as in ParseLimits: "rate/burst".
+func (l Limit) String() string {
+	return strconv.FormatFloat(l.Rate, 'f', -1, 64) + "/" + strconv.Itoa(l.Burst)
+}
+
+// BulkLimit is the ACP-BULK-1.0 §4 limit on bulk requests per institution.
+var BulkLimit = Limit{Rate: bulk.MaxRequestsPerSecond, Burst: bulk.MaxRequestsPerSecond}
+
+// DefaultLimits are the limits per autonomy level (ACP-API-1.0 §4, levels
+// 0–4). Level 0 agents cannot execute, so they get the lowest quota.
+var DefaultLimits = map[int]Limit{
+	0: {Rate: 1, Burst: 5},
+	1: {Rate: 5, Burst: 10},
+	2: {Rate: 10, Burst: 20},
+	3: {Rate: 20, Burst: 40},
+	4: {Rate: 50, Burst: 100},
+}
+
+// ParseLimits parses limits per autonomy level, written as comma-separated
+// "level=rate/burst" items, e.g. "0=1/5,4=100/200". A rate of 0 removes the
+// limit of that level.
+func ParseLimits(spec string) (map[int]Limit, error) {
+	out := make(map[int]Limit)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		lvl, lim, ok := strings.Cut(item, "=")
+		rate, burst, ok2 := strings.Cut(lim, "/")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("%w: %q: want level=rate/burst", ErrInvalidLimits, item)
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(lvl))
+		if err != nil || level < 0 || level > 4 {
+			return nil, fmt.Errorf("%w: %q: level must be 0-4", ErrInvalidLimits, item)
+		}
+		r, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil || r < 0 || math.IsInf(r, 0) {
+			return nil, fmt.Errorf("%w: %q: rate must be a non-negative number", ErrInvalidLimits, item)
+		}
+		b, err := strconv.Atoi(strings.TrimSpace(burst))
+		if err != nil || (r > 0 && b < 1) {
+			return nil, fmt.Errorf("%w: %q: burst must be at least 1", ErrInvalidLimits, item)
+		}
+		out[level] = Limit{Rate: r, Burst: b}
+	}
+	return out, nil
+}
+
+// FormatLimits formats limits as ParseLimits reads them, by level.
+func FormatLimits(limits map[int]Limit) string {
+	levels := make([]int, 0, len(limits))
+	for lvl := range limits {
+		levels = append(levels, lvl)
+	}
+	sort.Ints(levels)
+	items := make([]string, len(levels))
+	for i, lvl := range levels {
+		items[i] = strconv.Itoa(lvl) + "=" + limits[lvl].String()
+	}
+	return strings.Join(items, ",")
+}
+
+// ─── Store ────────────────────────────────────────────────────────────────────
+
+// Key identifies a bucket.
+type Key struct {
+	Institution string
+	Agent       string
+	Client      string // caller address, if the caller did not authenticate Agent
+	Route       string // e.g. "authorize", "verify"
+}
+
+// String returns the store key of k. Every field is length-prefixed, so
+// distinct keys never share a store key whatever their fields contain.
+func (k Key) String() string {
+	var b strings.Builder
+	for _, f := range []string{k.Institution, k.Agent, k.Client, k.Route} {
+		b.WriteString(strconv.Itoa(len(f)))
+		b.WriteByte(':')
+		b.WriteString(f)
+	}
+	return b.String()
+}
+
+// Store holds bucket state. Implementations must be safe for concurrent use;
+// a shared Store (e.g. backed by a database) lets replicas enforce one quota.
+type Store interface {
+	// Take refills the bucket of key at limit up to now, then takes one
+	// token from it. If the bucket is empty it takes nothing and returns
+	// false with the time until a token is available.
+	Take(key string, limit Limit, now time.Time) (ok bool, retryAfter time.Duration, err error)
+}
+
+// MemoryStore is an in-process Store. Thread-safe.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time // last refill
+	limit  Limit
+}
+
+// NewMemoryStore returns an empty store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take implements Store. A new bucket starts full; a bucket whose limit
+// changed keeps its tokens, capped at the new burst.
+func (s *MemoryStore) Take(key string, limit Limit, now time.Time) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * limit.Rate
+		b.last = now
+	}
+	b.tokens = math.Min(b.tokens, float64(limit.Burst))
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return false, wait, nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
+
+// Prune drops the buckets that have refilled completely by now: a new
+// bucket would start in the same state.
+func (s *MemoryStore) Prune(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+			n++
+		}
+	}
+	return n
+}
+
+// Size returns the number of buckets held.
+func (s *MemoryStore) Size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+// ─── Limiter ──────────────────────────────────────────────────────────────────
+
+// PenaltyInterval is the minimum time between two rejections of an agent
+// that Penalize reports.
+const PenaltyInterval = time.Minute
+
+// Limiter applies the limits per autonomy level to the buckets of a Store.
+// Thread-safe.
+type Limiter struct {
+	store  Store
+	limits map[int]Limit
+
+	mu        sync.Mutex
+	penalized map[string]time.Time // institution|agent → last penalty
+}
+
+// New returns a limiter over store. Autonomy levels missing from limits are
+// not limited.
+func New(store Store, limits map[int]Limit) *Limiter {
+	copied := make(map[int]Limit, len(limits))
+	for lvl, lim := range limits {
+		copied[lvl] = lim
+	}
+	return &Limiter{store: store, limits: copied, penalized: make(map[string]time.Time)}
+}
+
+// Limits returns the limits per autonomy level.
+func (l *Limiter) Limits() map[int]Limit {
+	out := make(map[int]Limit, len(l.limits))
+	for lvl, lim := range l.limits {
+		out[lvl] = lim
+	}
+	return out
+}
+
+// Allow takes a token for a request of an agent of the given autonomy level.
+// It returns ErrRateLimitExceeded, together with how long the caller should
+// wait, when the bucket is empty; other errors come from the Store.
+func (l *Limiter) Allow(k Key, level int, now time.Time) (retryAfter time.Duration, err error) {
+	return l.AllowLimit(k, l.limits[level], now)
+}
+
+// AllowLimit is like Allow with an explicit limit instead of the limit of an
+// autonomy level, e.g. BulkLimit.
+func (l *Limiter) AllowLimit(k Key, limit Limit, now time.Time) (retryAfter time.Duration, err error) {
+	if limit.Unlimited() {
+		return 0, nil
+	}
+	ok, retryAfter, err := l.store.Take(k.String(), limit, now)
+	if err != nil {
+		return 0, fmt.Errorf("ratelimit: %w", err)
+	}
+	if !ok {
+		who := "agent " + k.Agent
+		if k.Agent == "" {
+			who = "institution " + k.Institution
+		}
+		return retryAfter, fmt.Errorf("%w: %s on %s (%g/s, burst %d)", ErrRateLimitExceeded, who, k.Route, limit.Rate, limit.Burst)
+	}
+	return 0, nil
+}
+
+// Penalize reports whether a rejection of k's agent at now should be recorded
+// against its reputation: true at most once per PenaltyInterval per agent,
+// across routes.
+func (l *Limiter) Penalize(k Key, now time.Time) bool {
+	key := k.Institution + "|" + k.Agent
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if last, ok := l.penalized[key]; ok && now.Sub(last) < PenaltyInterval {
+		return false
+	}
+	l.penalized[key] = now
+	return true
+}
+
+// Prune forgets the penalties older than PenaltyInterval and, if the Store
+// supports it, the idle buckets. It returns the number of buckets dropped.
+func (l *Limiter) Prune(now time.Time) int {
+	l.mu.Lock()
+	for key, last := range l.penalized {
+		if now.Sub(last) >= PenaltyInterval {
+			delete(l.penalized, key)
+		}
+	}
+	l.mu.Unlock()
+	if p, ok := l.store.(interface{ Prune(time.Time) int }); ok {
+		return p.Prune(now)
+	}
+	return 0
+}
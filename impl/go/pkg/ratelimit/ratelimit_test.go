@@ -0,0 +1,124 @@
+package ratelimit_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/ratelimit"
+)
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	l := ratelimit.New(store, map[int]ratelimit.Limit{2: {Rate: 2, Burst: 3}})
+	k := ratelimit.Key{Institution: "org.a", Agent: "agent-1", Route: "authorize"}
+	now := time.Unix(1_700_000_000, 0)
+
+	// A full bucket absorbs a burst, then refills at Rate.
+	for i := 0; i < 3; i++ {
+		if _, err := l.Allow(k, 2, now); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	retry, err := l.Allow(k, 2, now)
+	if !errors.Is(err, ratelimit.ErrRateLimitExceeded) || retry != 500*time.Millisecond {
+		t.Fatalf("over burst: retry %v, err %v", retry, err)
+	}
+	if _, err := l.Allow(k, 2, now.Add(500*time.Millisecond)); err != nil {
+		t.Errorf("after refill: %v", err)
+	}
+
+	// Buckets are per agent and route; unlisted levels are not limited.
+	other := k
+	other.Route = "verify"
+	if _, err := l.Allow(other, 2, now); err != nil {
+		t.Errorf("other route: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := l.Allow(k, 4, now); err != nil {
+			t.Fatalf("unlimited level: %v", err)
+		}
+	}
+
+	// Idle buckets are pruned once they have refilled.
+	if n := l.Prune(now.Add(time.Second)); n != 1 || store.Size() != 1 {
+		t.Errorf("Prune dropped %d, %d left", n, store.Size())
+	}
+	if n := l.Prune(now.Add(2 * time.Second)); n != 1 || store.Size() != 0 {
+		t.Errorf("Prune dropped %d, %d left", n, store.Size())
+	}
+}
+
+func TestLimiter_BulkLimit(t *testing.T) {
+	// The bulk limit applies with every autonomy level unlimited.
+	l := ratelimit.New(ratelimit.NewMemoryStore(), nil)
+	k := ratelimit.Key{Institution: "org.a", Route: "bulk"}
+	now := time.Unix(1_700_000_000, 0)
+	for i := 0; i < ratelimit.BulkLimit.Burst; i++ {
+		if _, err := l.AllowLimit(k, ratelimit.BulkLimit, now); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	retry, err := l.AllowLimit(k, ratelimit.BulkLimit, now)
+	if !errors.Is(err, ratelimit.ErrRateLimitExceeded) || retry != 100*time.Millisecond {
+		t.Fatalf("over limit: retry %v, err %v", retry, err)
+	}
+	if _, err := l.Allow(ratelimit.Key{Institution: "org.a", Agent: "agent-1", Route: "bulk"}, 2, now); err != nil {
+		t.Errorf("agent bucket: %v", err)
+	}
+}
+
+func TestKey_String(t *testing.T) {
+	// Fields are length-prefixed: separators inside a field cannot make two
+	// keys share a bucket.
+	a := ratelimit.Key{Institution: "org.a|agent-1", Route: "authorize"}
+	b := ratelimit.Key{Institution: "org.a", Agent: "agent-1", Route: "authorize"}
+	c := ratelimit.Key{Institution: "org.a", Agent: "agent-1", Client: "127.0.0.1", Route: "authorize"}
+	if a.String() == b.String() || b.String() == c.String() || a.String() == c.String() {
+		t.Fatalf("keys collide: %q %q %q", a, b, c)
+	}
+
+	l := ratelimit.New(ratelimit.NewMemoryStore(), map[int]ratelimit.Limit{2: {Rate: 1, Burst: 1}})
+	now := time.Unix(1_700_000_000, 0)
+	if _, err := l.Allow(a, 2, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Allow(b, 2, now); err != nil {
+		t.Errorf("bucket of %q drained by %q: %v", b, a, err)
+	}
+}
+
+func TestLimiter_Penalize(t *testing.T) {
+	l := ratelimit.New(ratelimit.NewMemoryStore(), nil)
+	k := ratelimit.Key{Institution: "org.a", Agent: "agent-1", Route: "authorize"}
+	now := time.Unix(1_700_000_000, 0)
+
+	if !l.Penalize(k, now) {
+		t.Fatal("first rejection not penalized")
+	}
+	k.Route = "verify"
+	if l.Penalize(k, now.Add(time.Second)) {
+		t.Error("penalized twice within PenaltyInterval")
+	}
+	if !l.Penalize(k, now.Add(ratelimit.PenaltyInterval)) {
+		t.Error("not penalized after PenaltyInterval")
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ratelimit.ParseLimits(" 0=1/5, 4=0.5/2,2=0/0")
+	if err != nil {
+		t.Fatalf("ParseLimits: %v", err)
+	}
+	if got := ratelimit.FormatLimits(limits); got != "0=1/5,2=0/0,4=0.5/2" {
+		t.Errorf("FormatLimits = %s", got)
+	}
+	if !limits[2].Unlimited() {
+		t.Error("rate 0 should not limit")
+	}
+	for _, bad := range []string{"5=1/1", "1=1", "x=1/1", "1=-1/2", "1=2/0"} {
+		if _, err := ratelimit.ParseLimits(bad); !errors.Is(err, ratelimit.ErrInvalidLimits) {
+			t.Errorf("ParseLimits(%q) err = %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// keys.go — agent key history and key rotation.
+package registry
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gowebpki/jcs"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/keyring"
+)
+
+// An agent keeps its AgentID across key rotations: the ID is the one derived
+// from the key it registered with (crypto.DeriveAgentID), and every later key
+// is endorsed by its predecessor through a signed RotationStatement. The
+// registry keeps every key with its validity window so that signatures made
+// before a rotation still verify against the key that was valid at the time.
+
+var (
+	// ErrRotationNotEndorsed is returned when a rotation is not signed by the
+	// agent's current key, or names a key that is no longer current (replay).
+	ErrRotationNotEndorsed = errors.New("acp/registry: key rotation not endorsed by the current key")
+
+	// ErrRotationPossession is returned when the new key's signature over the
+	// rotation statement is missing or invalid.
+	ErrRotationPossession = errors.New("acp/registry: key rotation lacks proof of possession of the new key")
+
+	// ErrKeyReused is returned when the new key was already used by the agent.
+	ErrKeyReused = errors.New("acp/registry: public key already used by this agent")
+)
+
+const (
+	// RotationStatementVersion is the only supported RotationStatement version.
+	RotationStatementVersion = "1.0"
+
+	// MaxRotationOverlap bounds how long (seconds) a rotated-out key stays valid.
+	MaxRotationOverlap = 7 * 24 * 3600
+)
+
+// AgentKey is one entry of an agent's key history.
+type AgentKey struct {
+	KID          string            `json:"kid"`
+	PublicKey    ed25519.PublicKey `json:"-"`
+	PublicKeyB64 string            `json:"public_key"` // base64url
+	ValidFrom    int64             `json:"valid_from"`
+	ValidUntil   int64             `json:"valid_until,omitempty"` // 0 while current
+}
+
+// ValidAt reports whether ts falls inside the key's validity window.
+func (k AgentKey) ValidAt(ts int64) bool {
+	return ts >= k.ValidFrom && (k.ValidUntil == 0 || ts <= k.ValidUntil)
+}
+
+// newAgentKey builds the history entry for pub, valid from validFrom.
+func newAgentKey(pub ed25519.PublicKey, validFrom int64) AgentKey {
+	return AgentKey{
+		KID:          keyring.DeriveKID(pub),
+		PublicKey:    pub,
+		PublicKeyB64: base64.RawURLEncoding.EncodeToString(pub),
+		ValidFrom:    validFrom,
+	}
+}
+
+// RotationStatement is the document an agent's current key signs to endorse
+// its next key. OverlapPeriod (seconds) keeps the old key valid after
+// RotatedAt for signatures in flight.
+type RotationStatement struct {
+	Ver           string `json:"ver"`
+	AgentID       string `json:"agent_id"`
+	OldKID        string `json:"old_kid"`
+	NewPublicKey  string `json:"new_public_key"` // base64url
+	RotatedAt     int64  `json:"rotated_at"`
+	OverlapPeriod int64  `json:"overlap_period"`
+}
+
+// digest returns SHA-256(JCS(st)).
+func (st RotationStatement) digest() ([]byte, error) {
+	raw, err := json.Marshal(st)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := jcs.Transform(raw)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(canonical)
+	return sum[:], nil
+}
+
+// SignRotation signs st with priv: base64url(Ed25519(SHA-256(JCS(st)))).
+// The agent signs once with its current key and once with the new key.
+func SignRotation(st RotationStatement, priv ed25519.PrivateKey) (string, error) {
+	h, err := st.digest()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, h)), nil
+}
+
+// verifyRotationSig checks sig over st with pub.
+func verifyRotationSig(st RotationStatement, sig string, pub ed25519.PublicKey) bool {
+	h, err := st.digest()
+	if err != nil {
+		return false
+	}
+	b, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(pub, h, b)
+}
+
+// RotateKey applies a signed key rotation to a fully registered agent.
+//
+// oldSig must be the current key's signature over st and newSig the new key's.
+// The current key stays valid until st.RotatedAt + st.OverlapPeriod; the new
+// key is valid from st.RotatedAt and becomes the agent's PublicKey.
+func (r *InMemoryRegistry) RotateKey(st RotationStatement, oldSig, newSig string) (AgentKey, error) {
+	if st.Ver != RotationStatementVersion {
+		return AgentKey{}, fmt.Errorf("acp/registry: unsupported rotation statement version %q", st.Ver)
+	}
+	if st.OverlapPeriod < 0 || st.OverlapPeriod > MaxRotationOverlap {
+		return AgentKey{}, fmt.Errorf("acp/registry: overlap_period %d not in [0, %d]", st.OverlapPeriod, MaxRotationOverlap)
+	}
+	newPubBytes, err := base64.RawURLEncoding.DecodeString(st.NewPublicKey)
+	if err != nil || len(newPubBytes) != ed25519.PublicKeySize {
+		return AgentKey{}, fmt.Errorf("acp/registry: new_public_key must be a base64url %d-byte key", ed25519.PublicKeySize)
+	}
+	newPub := ed25519.PublicKey(newPubBytes)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.records[st.AgentID]
+	if !ok {
+		return AgentKey{}, fmt.Errorf("%w: %q", ErrAgentNotFound, st.AgentID)
+	}
+	if rec.Status == StatusRevoked {
+		return AgentKey{}, fmt.Errorf("%w: %q", ErrAgentRevoked, st.AgentID)
+	}
+	current := &rec.Keys[len(rec.Keys)-1]
+	if st.OldKID != current.KID || !verifyRotationSig(st, oldSig, current.PublicKey) {
+		return AgentKey{}, ErrRotationNotEndorsed
+	}
+	if !verifyRotationSig(st, newSig, newPub) {
+		return AgentKey{}, ErrRotationPossession
+	}
+	if st.RotatedAt < current.ValidFrom {
+		return AgentKey{}, fmt.Errorf("acp/registry: rotated_at %d precedes the current key (valid from %d)", st.RotatedAt, current.ValidFrom)
+	}
+	kid := keyring.DeriveKID(newPub)
+	for _, k := range rec.Keys {
+		if k.KID == kid {
+			return AgentKey{}, fmt.Errorf("%w: %s", ErrKeyReused, kid)
+		}
+	}
+
+	current.ValidUntil = st.RotatedAt + st.OverlapPeriod
+	next := newAgentKey(newPub, st.RotatedAt)
+	rec.Keys = append(rec.Keys, next)
+	rec.PublicKey = newPub
+	rec.PublicKeyB64 = next.PublicKeyB64
+	return next, nil
+}
+
+// KeyHistory returns the agent's keys, oldest first.
+func (r *InMemoryRegistry) KeyHistory(agentID string) ([]AgentKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rec, ok := r.records[agentID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrAgentNotFound, agentID)
+	}
+	return append([]AgentKey(nil), rec.Keys...), nil
+}
+
+// KeysAt returns the agent's public keys valid at ts, newest first. During a
+// rotation overlap both the old and the new key are returned. Agents
+// registered through the legacy Register path have a single, always-valid key.
+func (r *InMemoryRegistry) KeysAt(agentID string, ts int64) ([]ed25519.PublicKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if rec, ok := r.records[agentID]; ok {
+		var keys []ed25519.PublicKey
+		for i := len(rec.Keys) - 1; i >= 0; i-- {
+			if rec.Keys[i].ValidAt(ts) {
+				keys = append(keys, rec.Keys[i].PublicKey)
+			}
+		}
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("acp/registry: agent %q has no key valid at %d", agentID, ts)
+		}
+		return keys, nil
+	}
+	pk, ok := r.keys[agentID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrAgentNotFound, agentID)
+	}
+	return []ed25519.PublicKey{pk}, nil
+}
@@ -0,0 +1,148 @@
+package registry_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/keyring"
+	"github.com/chelof100/acp-framework/acp-go/pkg/registry"
+)
+
+func key(b byte) ed25519.PrivateKey {
+	seed := make([]byte, ed25519.SeedSize)
+	seed[0] = b
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func pubOf(priv ed25519.PrivateKey) ed25519.PublicKey { return priv.Public().(ed25519.PublicKey) }
+
+func newAgent(t *testing.T, priv ed25519.PrivateKey) *registry.InMemoryRegistry {
+	t.Helper()
+	r := registry.NewInMemoryRegistry()
+	if err := r.RegisterFull(registry.AgentRecord{AgentID: "agent-1", PublicKey: pubOf(priv), RegisteredAt: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// rotate signs a rotation from oldPriv to newPriv at ts and applies it.
+func rotate(r *registry.InMemoryRegistry, oldPriv, newPriv ed25519.PrivateKey, ts, overlap int64) (registry.AgentKey, error) {
+	st := registry.RotationStatement{
+		Ver:           registry.RotationStatementVersion,
+		AgentID:       "agent-1",
+		OldKID:        keyring.DeriveKID(pubOf(oldPriv)),
+		NewPublicKey:  base64.RawURLEncoding.EncodeToString(pubOf(newPriv)),
+		RotatedAt:     ts,
+		OverlapPeriod: overlap,
+	}
+	oldSig, _ := registry.SignRotation(st, oldPriv)
+	newSig, _ := registry.SignRotation(st, newPriv)
+	return r.RotateKey(st, oldSig, newSig)
+}
+
+func TestRotateKey_OverlapWindow(t *testing.T) {
+	k1, k2 := key(1), key(2)
+	r := newAgent(t, k1)
+
+	next, err := rotate(r, k1, k2, 2000, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.KID != keyring.DeriveKID(pubOf(k2)) || next.ValidFrom != 2000 {
+		t.Errorf("new key = %+v", next)
+	}
+	if pk, _ := r.GetPublicKey("agent-1"); !pk.Equal(pubOf(k2)) {
+		t.Error("current public key not updated")
+	}
+
+	cases := []struct {
+		ts   int64
+		want []ed25519.PublicKey
+	}{
+		{1500, []ed25519.PublicKey{pubOf(k1)}},
+		{2030, []ed25519.PublicKey{pubOf(k2), pubOf(k1)}},
+		{2061, []ed25519.PublicKey{pubOf(k2)}},
+	}
+	for _, c := range cases {
+		got, err := r.KeysAt("agent-1", c.ts)
+		if err != nil {
+			t.Fatalf("KeysAt(%d): %v", c.ts, err)
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("KeysAt(%d) = %d keys, want %d", c.ts, len(got), len(c.want))
+		}
+		for i := range got {
+			if !got[i].Equal(c.want[i]) {
+				t.Errorf("KeysAt(%d)[%d] mismatch", c.ts, i)
+			}
+		}
+	}
+
+	hist, _ := r.KeyHistory("agent-1")
+	if len(hist) != 2 || hist[0].ValidUntil != 2060 || hist[1].ValidUntil != 0 {
+		t.Errorf("history = %+v", hist)
+	}
+}
+
+func TestRotateKey_Rejections(t *testing.T) {
+	k1, k2, k3 := key(1), key(2), key(3)
+	r := newAgent(t, k1)
+
+	// Signed by a key that is not the agent's.
+	if _, err := rotate(r, k3, k2, 2000, 0); !errors.Is(err, registry.ErrRotationNotEndorsed) {
+		t.Errorf("foreign endorsement: err = %v", err)
+	}
+
+	// New key signature missing.
+	st := registry.RotationStatement{
+		Ver: registry.RotationStatementVersion, AgentID: "agent-1",
+		OldKID: keyring.DeriveKID(pubOf(k1)), NewPublicKey: base64.RawURLEncoding.EncodeToString(pubOf(k2)),
+		RotatedAt: 2000,
+	}
+	oldSig, _ := registry.SignRotation(st, k1)
+	if _, err := r.RotateKey(st, oldSig, ""); !errors.Is(err, registry.ErrRotationPossession) {
+		t.Errorf("no possession proof: err = %v", err)
+	}
+
+	if _, err := rotate(r, k1, k2, 2000, 0); err != nil {
+		t.Fatal(err)
+	}
+	// Replaying the k1 → k2 statement names a retired key.
+	if _, err := rotate(r, k1, k3, 2100, 0); !errors.Is(err, registry.ErrRotationNotEndorsed) {
+		t.Errorf("replay with retired key: err = %v", err)
+	}
+	// Rotating back to a previous key.
+	if _, err := rotate(r, k2, k1, 2100, 0); !errors.Is(err, registry.ErrKeyReused) {
+		t.Errorf("key reuse: err = %v", err)
+	}
+	// Back-dated before the current key.
+	if _, err := rotate(r, k2, k3, 1999, 0); err == nil {
+		t.Error("back-dated rotation accepted")
+	}
+	if _, err := rotate(r, k2, k3, 2100, registry.MaxRotationOverlap+1); err == nil {
+		t.Error("overlap above MaxRotationOverlap accepted")
+	}
+
+	if err := r.UpdateStatus("agent-1", registry.StatusRevoked); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotate(r, k2, k3, 2100, 0); !errors.Is(err, registry.ErrAgentRevoked) {
+		t.Errorf("revoked agent: err = %v", err)
+	}
+}
+
+func TestKeysAt_LegacyRegister(t *testing.T) {
+	r := registry.NewInMemoryRegistry()
+	if err := r.Register("legacy", pubOf(key(9))); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := r.KeysAt("legacy", 0)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("KeysAt = %v, %v", keys, err)
+	}
+	if _, err := r.KeysAt("missing", 0); !errors.Is(err, registry.ErrAgentNotFound) {
+		t.Errorf("unknown agent: err = %v", err)
+	}
+}
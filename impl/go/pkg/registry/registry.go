@@ -2,7 +2,8 @@
 // agent metadata management (ACP-API-1.0 §4).
 //
 // The ACP server uses the registry to:
-//   - Look up agent public keys for PoP and CT subject verification
+//   - Look up agent public keys for PoP and CT subject verification,
+//     including keys retired by a rotation (keys.go)
 //   - Store and retrieve full AgentRecord data (autonomy_level, status, etc.)
 package registry
 
@@ -61,13 +62,20 @@ var validTransitions = map[AgentStatus]map[AgentStatus]bool{
 	// StatusRevoked has no outgoing transitions (terminal).
 }
 
+// CanTransition reports whether from → to is allowed by the ACP-API-1.0 §4
+// transition table.
+func CanTransition(from, to AgentStatus) bool {
+	return validTransitions[from][to]
+}
+
 // ─── Agent Record ─────────────────────────────────────────────────────────────
 
 // AgentRecord holds the full metadata for a registered agent (ACP-API-1.0 §4).
 type AgentRecord struct {
 	AgentID         string            `json:"agent_id"`
-	PublicKey       ed25519.PublicKey `json:"-"` // not serialised directly
+	PublicKey       ed25519.PublicKey `json:"-"` // current key; not serialised directly
 	PublicKeyB64    string            `json:"public_key"` // base64url
+	Keys            []AgentKey        `json:"keys,omitempty"` // key history, oldest first (keys.go)
 	InstitutionID   string            `json:"institution_id"`
 	AutonomyLevel   int               `json:"autonomy_level"` // 0–4
 	AuthorityDomain string            `json:"authority_domain"`
@@ -171,6 +179,7 @@ func (r *InMemoryRegistry) RegisterFull(rec AgentRecord) error {
 	if rec.LastActiveAt == 0 {
 		rec.LastActiveAt = now
 	}
+	rec.Keys = []AgentKey{newAgentKey(rec.PublicKey, rec.RegisteredAt)}
 	cp := rec // copy
 	r.records[rec.AgentID] = &cp
 	return nil
@@ -185,7 +194,9 @@ func (r *InMemoryRegistry) GetRecord(agentID string) (AgentRecord, error) {
 	if !ok {
 		return AgentRecord{}, fmt.Errorf("%w: %q", ErrAgentNotFound, agentID)
 	}
-	return *rec, nil
+	cp := *rec
+	cp.Keys = append([]AgentKey(nil), rec.Keys...)
+	return cp, nil
 }
 
 // UpdateStatus transitions the agent to a new status.
@@ -202,8 +213,7 @@ func (r *InMemoryRegistry) UpdateStatus(agentID string, newStatus AgentStatus) e
 	if rec.Status == StatusRevoked {
 		return fmt.Errorf("%w: %q", ErrAgentRevoked, agentID)
 	}
-	allowed := validTransitions[rec.Status]
-	if !allowed[newStatus] {
+	if !CanTransition(rec.Status, newStatus) {
 		return fmt.Errorf("%w: %s → %s", ErrInvalidTransition, rec.Status, newStatus)
 	}
 	rec.Status = newStatus
@@ -0,0 +1,326 @@
+// Package replica implements read-only replication of an institution's audit
+// ledger (ACP-LEDGER-1.0).
+//
+// The primary publishes its ledger as a stream of raw events:
+//
+//	GET /acp/v1/audit/stream?from_seq=N
+//
+// served as Server-Sent Events: one "ledger_event" per event, with the event
+// sequence as the SSE id so that a client reconnecting with Last-Event-ID
+// resumes after the last event it received. The stream stays open and
+// delivers new events as they are appended.
+//
+// A Follower consumes the stream into a replica ledger (ledger.NewReplica).
+// Every event is verified — hash, prev_hash, sequence and institutional
+// signature — before it is applied, so a follower never serves an event the
+// primary did not sign. If the primary's history stops extending the one the
+// follower already holds, the follower stops replicating and reports the
+// divergence instead of following the rewritten history.
+package replica
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	acpapi "github.com/chelof100/acp-framework/acp-go/pkg/api"
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+)
+
+// StreamPath is the ledger stream endpoint.
+const StreamPath = "/acp/v1/audit/stream"
+
+// EventName is the SSE event name of a replicated ledger event.
+const EventName = "ledger_event"
+
+// DefaultHeartbeat is the interval of the keep-alive comments sent on an
+// idle stream, so that proxies do not close it.
+const DefaultHeartbeat = 15 * time.Second
+
+// DefaultBackoff is the wait before each reconnection; the last value repeats.
+var DefaultBackoff = []time.Duration{time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second}
+
+// Follower states.
+const (
+	StateConnecting = "connecting"
+	StateStreaming  = "streaming"
+	StateDiverged   = "diverged"
+)
+
+// ─── Primary side ─────────────────────────────────────────────────────────────
+
+// Source is the ledger a Streamer publishes.
+type Source interface {
+	List(fromSeq, toSeq int64) []ledger.Event
+	Changed() <-chan struct{}
+}
+
+// Streamer serves GET StreamPath.
+type Streamer struct {
+	src       Source
+	heartbeat time.Duration
+}
+
+// NewStreamer creates a Streamer over src. heartbeat ≤ 0 selects
+// DefaultHeartbeat.
+func NewStreamer(src Source, heartbeat time.Duration) *Streamer {
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	return &Streamer{src: src, heartbeat: heartbeat}
+}
+
+// ServeHTTP streams the events from from_seq (default 1), or after the
+// Last-Event-ID header when a client reconnects, until the client goes away.
+//
+// Response 400: SYS-004 (from_seq or Last-Event-ID is not a positive integer)
+func (s *Streamer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	next, err := startSequence(r)
+	if err != nil {
+		acpapi.WriteError(w, r, http.StatusBadRequest, acpapi.ErrSYS004, err.Error())
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		acpapi.WriteError(w, r, http.StatusInternalServerError, acpapi.ErrSYS001, "streaming not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+	for {
+		// Take the channel before reading, so an append between List and
+		// the wait below is not missed.
+		changed := s.src.Changed()
+		for _, ev := range s.src.List(next, 0) {
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+			next = ev.Sequence + 1
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// startSequence returns the first sequence to stream.
+func startSequence(r *http.Request) (int64, error) {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		seq, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || seq < 0 {
+			return 0, fmt.Errorf("Last-Event-ID must be a sequence number")
+		}
+		return seq + 1, nil
+	}
+	v := r.URL.Query().Get("from_seq")
+	if v == "" {
+		return 1, nil
+	}
+	seq, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || seq < 1 {
+		return 0, fmt.Errorf("from_seq must be a positive integer")
+	}
+	return seq, nil
+}
+
+// writeEvent writes ev as one SSE message.
+func writeEvent(w io.Writer, ev ledger.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Sequence, EventName, data)
+	return err
+}
+
+// ─── Follower side ────────────────────────────────────────────────────────────
+
+// Replica is the ledger a Follower applies the stream to
+// (*ledger.InMemoryLedger created by ledger.NewReplica).
+type Replica interface {
+	Apply(events ...ledger.Event) error
+	Size() int
+}
+
+// FollowerConfig tunes a Follower. Zero values take the defaults.
+type FollowerConfig struct {
+	Client  *http.Client    // default: no timeout, streams stay open
+	Backoff []time.Duration // wait before reconnection n (default DefaultBackoff)
+	// OnEvent, if set, is called after each event is applied, before the
+	// next one is verified. The server uses it to learn institutional key
+	// rotations so that events signed by the new key verify.
+	OnEvent func(ledger.Event)
+}
+
+// Status is the replication state reported by a Follower.
+type Status struct {
+	Primary   string `json:"primary"`
+	State     string `json:"state"`
+	Sequence  int64  `json:"sequence"` // last applied sequence
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Follower replicates the ledger of a primary into a Replica.
+type Follower struct {
+	primary string
+	replica Replica
+	client  *http.Client
+	cfg     FollowerConfig
+
+	mu        sync.Mutex
+	state     string
+	lastError string
+}
+
+// NewFollower creates a Follower of the primary at primaryURL (its base URL,
+// e.g. https://acp.example.org).
+func NewFollower(primaryURL string, r Replica, cfg FollowerConfig) *Follower {
+	if len(cfg.Backoff) == 0 {
+		cfg.Backoff = DefaultBackoff
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Follower{
+		primary: strings.TrimRight(primaryURL, "/"),
+		replica: r,
+		client:  client,
+		cfg:     cfg,
+		state:   StateConnecting,
+	}
+}
+
+// Run replicates until ctx is cancelled, reconnecting with backoff when the
+// stream breaks or an event fails verification. It returns ctx.Err(), or an
+// error wrapping ledger.ErrDivergentHistory once the primary's history
+// diverges from the replica's: replication then stops for good.
+func (f *Follower) Run(ctx context.Context) error {
+	failures := 0
+	for {
+		applied, err := f.follow(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, ledger.ErrDivergentHistory) {
+			f.setState(StateDiverged, err)
+			return err
+		}
+		if applied > 0 {
+			failures = 0
+		}
+		f.setState(StateConnecting, err)
+		wait := f.cfg.Backoff[len(f.cfg.Backoff)-1]
+		if failures < len(f.cfg.Backoff) {
+			wait = f.cfg.Backoff[failures]
+		}
+		failures++
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Status returns the current replication state.
+func (f *Follower) Status() Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return Status{
+		Primary:   f.primary,
+		State:     f.state,
+		Sequence:  int64(f.replica.Size()),
+		LastError: f.lastError,
+	}
+}
+
+func (f *Follower) setState(state string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = state
+	if err != nil {
+		f.lastError = err.Error()
+	}
+}
+
+// follow opens one stream after the replica's last event and applies events
+// until the stream ends. It returns the number of events applied.
+func (f *Follower) follow(ctx context.Context) (applied int, err error) {
+	from := int64(f.replica.Size()) + 1
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s?from_seq=%d", f.primary, StreamPath, from), nil)
+	if err != nil {
+		return 0, fmt.Errorf("replica: build request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("replica: connect to %s: %w", f.primary, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var er acpapi.ErrorResponse
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&er)
+		return 0, fmt.Errorf("replica: %s answered %d %s: %s", f.primary, resp.StatusCode, er.Error.Code, er.Error.Message)
+	}
+	f.setState(StateStreaming, nil)
+
+	rd := bufio.NewReader(resp.Body)
+	var event, data string
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return applied, fmt.Errorf("replica: stream from %s: %w", f.primary, err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "":
+			// End of message.
+			if event == EventName && data != "" {
+				var ev ledger.Event
+				if err := json.Unmarshal([]byte(data), &ev); err != nil {
+					return applied, fmt.Errorf("replica: decode event: %w", err)
+				}
+				if err := f.replica.Apply(ev); err != nil {
+					return applied, err
+				}
+				applied++
+				if f.cfg.OnEvent != nil {
+					f.cfg.OnEvent(ev)
+				}
+			}
+			event, data = "", ""
+		case strings.HasPrefix(line, ":"):
+			// Comment (keep-alive).
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+}
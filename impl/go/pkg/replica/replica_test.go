@@ -0,0 +1,124 @@
+package replica_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/chelof100/acp-framework/acp-go/pkg/replica"
+)
+
+// publicKey resolves every kid to one public key.
+type publicKey struct{ pub ed25519.PublicKey }
+
+func (k publicKey) KeyAt(string, int64) (ed25519.PublicKey, error) { return k.pub, nil }
+
+func newPrimary(t *testing.T, priv ed25519.PrivateKey, n int) *ledger.InMemoryLedger {
+	t.Helper()
+	l, err := ledger.NewInMemoryLedger("org.primary", priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	appendEvents(t, l, n)
+	return l
+}
+
+func appendEvents(t *testing.T, l *ledger.InMemoryLedger, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := l.Append(ledger.EventGovernance, map[string]interface{}{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// waitFor polls cond for up to 5 s.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFollower_ReplicatesAndRefusesDivergence(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	primary := newPrimary(t, priv, 3)
+
+	// The handler can be swapped to simulate a primary that rewrites history.
+	var current atomic.Pointer[replica.Streamer]
+	current.Store(replica.NewStreamer(primary, 50*time.Millisecond))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current.Load().ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	r := ledger.NewReplica("org.primary", publicKey{pub})
+	var seen atomic.Int64
+	f := replica.NewFollower(srv.URL, r, replica.FollowerConfig{
+		Backoff: []time.Duration{10 * time.Millisecond},
+		OnEvent: func(ledger.Event) { seen.Add(1) },
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- f.Run(ctx) }()
+
+	waitFor(t, "initial events", func() bool { return r.Size() == 4 })
+	if st := f.Status(); st.State != replica.StateStreaming || st.Sequence != 4 || st.Primary != srv.URL {
+		t.Errorf("status = %+v", st)
+	}
+
+	// New events are pushed to the open stream.
+	appendEvents(t, primary, 2)
+	waitFor(t, "live events", func() bool { return r.Size() == 6 })
+	if seen.Load() != 6 {
+		t.Errorf("OnEvent called %d times, want 6", seen.Load())
+	}
+	if errs := r.Verify(); len(errs) != 0 {
+		t.Errorf("replica verify: %v", errs)
+	}
+
+	// The primary is replaced by one with another history, signed with the
+	// same key: the follower keeps what it has and stops.
+	current.Store(replica.NewStreamer(newPrimary(t, priv, 8), 50*time.Millisecond))
+	srv.CloseClientConnections()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ledger.ErrDivergentHistory) {
+			t.Fatalf("Run = %v, want ErrDivergentHistory", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("follower did not stop on a divergent history")
+	}
+	if st := f.Status(); st.State != replica.StateDiverged || st.Sequence != 6 || st.LastError == "" {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestStreamer_RejectsBadStart(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	srv := httptest.NewServer(replica.NewStreamer(newPrimary(t, priv, 0), 0))
+	defer srv.Close()
+
+	for _, q := range []string{"?from_seq=0", "?from_seq=x"} {
+		resp, err := http.Get(srv.URL + replica.StreamPath + q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", q, resp.StatusCode)
+		}
+	}
+}
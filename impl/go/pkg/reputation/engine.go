@@ -9,8 +9,9 @@ import (
 // Engine applies the ACP-REP-1.1 reputation model to a ReputationStore.
 // It is the only component that should write to the store in production.
 type Engine struct {
-	store  ReputationStore
-	config Config
+	store    ReputationStore
+	config   Config
+	observer func(StateTransition)
 }
 
 // NewEngine creates a new reputation engine with the given store and config.
@@ -63,9 +64,18 @@ func (e *Engine) RecordEvent(agentID, eventType string) error {
 	var oldScore *float64
 
 	if record.Score == nil {
-		// Cold start: initialize from neutral baseline (0.5), then apply formula.
+		// Cold start: initialize from neutral baseline (0.5), or from the
+		// imported external prior if any, then apply formula.
 		// This ensures first events have meaningful impact without starting at 0.
-		newScore = clamp(0.5+e.config.Beta*metric, 0.0, 1.0)
+		baseline := coldStartBaseline
+		prior, err := e.priorScore(agentID, now)
+		if err != nil {
+			return err
+		}
+		if prior != nil {
+			baseline = *prior
+		}
+		newScore = clamp(baseline+e.config.Beta*metric, 0.0, 1.0)
 	} else {
 		oldScore = record.Score
 		newScore = clamp(e.config.Alpha*(*record.Score)+e.config.Beta*metric, 0.0, 1.0)
@@ -88,9 +98,17 @@ func (e *Engine) RecordEvent(agentID, eventType string) error {
 	return e.evaluateTransition(agentID, record.State, newScore)
 }
 
-// GetRecord returns the current reputation record for an agent.
+// GetRecord returns the current reputation record for an agent, with the
+// weighted external prior if snapshots were imported for it.
 func (e *Engine) GetRecord(agentID string) (*ReputationRecord, error) {
-	return e.store.GetRecord(agentID)
+	record, err := e.store.GetRecord(agentID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Prior, err = e.priorScore(agentID, time.Now().Unix()); err != nil {
+		return nil, err
+	}
+	return record, nil
 }
 
 // GetEvents returns paginated events for an agent (most-recent-first).
@@ -129,6 +147,15 @@ func (e *Engine) SetState(agentID string, state AgentState, reason, authorizedBy
 	return e.store.SetState(agentID, state, reason, authorizedBy)
 }
 
+// SetTransitionObserver registers fn to be called after every automatic
+// (score-driven) state transition. Manual SetState calls are not reported:
+// the caller already knows about them. fn runs synchronously inside
+// RecordEvent and MUST NOT call back into RecordEvent for the same agent.
+// It must be set before the engine is used concurrently.
+func (e *Engine) SetTransitionObserver(fn func(StateTransition)) {
+	e.observer = fn
+}
+
 // ─── Internal ─────────────────────────────────────────────────────────────────
 
 // evaluateTransition checks if newScore triggers an automatic state change.
@@ -166,7 +193,20 @@ func (e *Engine) evaluateTransition(agentID string, current AgentState, newScore
 		return nil
 	}
 
-	return e.store.SetState(agentID, next, reason, "system")
+	if err := e.store.SetState(agentID, next, reason, "system"); err != nil {
+		return err
+	}
+	if e.observer != nil {
+		e.observer(StateTransition{
+			AgentID:      agentID,
+			FromState:    current,
+			ToState:      next,
+			Reason:       reason,
+			AuthorizedBy: "system",
+			Timestamp:    time.Now().Unix(),
+		})
+	}
+	return nil
 }
 
 // validateConfig checks that all Config parameters are within allowed ranges.
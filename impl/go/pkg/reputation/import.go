@@ -0,0 +1,193 @@
+// import.go — ACP-REP-PORTABILITY-1.1 cross-org snapshot import (§8).
+package reputation
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// A snapshot imported from a peer institution does not become the agent's
+// local score. It is recorded as an external prior: the issuer's score,
+// normalized to 0–1 and shrunk towards the neutral cold-start baseline (0.5)
+// by the import weight. Local events always start from the prior instead of
+// the bare baseline, so local history takes over as it accumulates.
+
+const (
+	// WarnDivergence (REP-WARN-002) is reported when the imported score and
+	// the local score diverge beyond the import threshold (§7.3).
+	WarnDivergence = "REP-WARN-002"
+
+	// DefaultPriorWeight is the weight of an imported score against the
+	// neutral baseline when ImportOptions.Weight is zero.
+	DefaultPriorWeight = 0.5
+
+	// DefaultDivergenceThreshold is the recommended §7.3 threshold on the
+	// normalized 0–1 scale (equivalent to 30.0 on "0-100").
+	DefaultDivergenceThreshold = 0.30
+
+	// coldStartBaseline is the neutral score a new agent starts from.
+	coldStartBaseline = 0.5
+)
+
+var (
+	// ErrUntrustedIssuer is returned when the snapshot issuer has no trusted key.
+	ErrUntrustedIssuer = errors.New("acp/reputation: snapshot issuer not trusted")
+
+	// ErrSnapshotReplayed is returned when a rep_id was already imported (§13.3).
+	ErrSnapshotReplayed = errors.New("acp/reputation: snapshot rep_id already imported")
+)
+
+// IssuerKeyResolver resolves the public key of a trusted snapshot issuer.
+// Implementations return an error for issuers they do not trust.
+type IssuerKeyResolver interface {
+	IssuerKey(issuer string) (ed25519.PublicKey, error)
+}
+
+// IssuerKeyFunc adapts a function to IssuerKeyResolver.
+type IssuerKeyFunc func(issuer string) (ed25519.PublicKey, error)
+
+// IssuerKey calls f(issuer).
+func (f IssuerKeyFunc) IssuerKey(issuer string) (ed25519.PublicKey, error) { return f(issuer) }
+
+// ExternalPrior is a verified peer snapshot recorded against an agent.
+type ExternalPrior struct {
+	RepID      string  `json:"rep_id"`
+	Issuer     string  `json:"issuer"`
+	Score      float64 `json:"score"` // issuer's score normalized to 0–1
+	Weight     float64 `json:"weight"`
+	ModelID    string  `json:"model_id,omitempty"`
+	ImportedAt int64   `json:"imported_at"`
+	ValidUntil int64   `json:"valid_until,omitempty"` // 0 for v1.0 snapshots (no expiry)
+}
+
+// Value returns the prior's contribution: the baseline moved towards Score by Weight.
+func (p ExternalPrior) Value() float64 {
+	return coldStartBaseline + p.Weight*(p.Score-coldStartBaseline)
+}
+
+// ImportOptions tunes ImportSnapshot. Zero values take the defaults.
+type ImportOptions struct {
+	Weight              float64   // (0, 1]; default DefaultPriorWeight
+	DivergenceThreshold float64   // on the 0–1 scale; default DefaultDivergenceThreshold
+	Now                 time.Time // default time.Now()
+}
+
+// ImportResult describes an accepted import.
+type ImportResult struct {
+	AgentID    string        `json:"agent_id"`
+	Prior      ExternalPrior `json:"prior"`
+	LocalScore *float64      `json:"local_score"`          // null if the agent has no local history
+	Divergence *float64      `json:"divergence,omitempty"` // set when LocalScore is set
+	Warnings   []string      `json:"warnings"`
+}
+
+// ImportSnapshot verifies a peer's ReputationSnapshot and records it as an
+// external prior for rep.SubjectID (ACP-REP-PORTABILITY-1.1 §8).
+//
+// Verification order: Validate (freshness and invariants), issuer trust,
+// VerifySig, rep_id replay. Divergence from the local score is reported as
+// REP-WARN-002 and does not block the import.
+func (e *Engine) ImportSnapshot(rep *ReputationSnapshot, issuers IssuerKeyResolver, opts ImportOptions) (*ImportResult, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Weight == 0 {
+		opts.Weight = DefaultPriorWeight
+	}
+	if opts.DivergenceThreshold == 0 {
+		opts.DivergenceThreshold = DefaultDivergenceThreshold
+	}
+	if opts.Weight < 0 || opts.Weight > 1 {
+		return nil, fmt.Errorf("%w: import weight %.2f not in (0, 1]", ErrInvalidConfig, opts.Weight)
+	}
+	if rep.SubjectID == "" {
+		return nil, errors.New("acp/reputation: snapshot subject_id missing")
+	}
+
+	if err := Validate(rep, opts.Now); err != nil {
+		return nil, err
+	}
+	pub, err := issuers.IssuerKey(rep.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrUntrustedIssuer, rep.Issuer, err)
+	}
+	if err := VerifySig(rep, pub); err != nil {
+		return nil, err
+	}
+	// Normalization needs a known scale, also for v1.0 snapshots.
+	if err := checkScaleBounds(rep.Score, rep.Scale); err != nil {
+		return nil, err
+	}
+
+	record, err := e.store.GetRecord(rep.SubjectID)
+	if err != nil {
+		return nil, fmt.Errorf("acp/reputation: get record: %w", err)
+	}
+	if record.State == StateBanned {
+		return nil, ErrAgentBanned
+	}
+
+	prior := ExternalPrior{
+		RepID:      rep.RepID,
+		Issuer:     rep.Issuer,
+		Score:      normalizeScore(rep.Score, rep.Scale),
+		Weight:     opts.Weight,
+		ModelID:    rep.ModelID,
+		ImportedAt: opts.Now.Unix(),
+	}
+	if rep.Ver != "1.0" {
+		prior.ValidUntil = rep.ValidUntil
+	}
+	if err := e.store.RecordPrior(rep.SubjectID, prior); err != nil {
+		return nil, err
+	}
+
+	res := &ImportResult{AgentID: rep.SubjectID, Prior: prior, LocalScore: record.Score, Warnings: []string{}}
+	if record.Score != nil {
+		local := &ReputationSnapshot{Score: *record.Score, Scale: "0-1"}
+		imported := &ReputationSnapshot{Score: prior.Score, Scale: "0-1"}
+		exceeded, div := CheckDivergence(local, imported, opts.DivergenceThreshold)
+		res.Divergence = &div
+		if exceeded {
+			res.Warnings = append(res.Warnings, WarnDivergence)
+		}
+	}
+	return res, nil
+}
+
+// priorScore returns the mean value of the agent's unexpired priors, keeping
+// only the most recent import per issuer. Returns nil if there are none.
+func (e *Engine) priorScore(agentID string, now int64) (*float64, error) {
+	priors, err := e.store.GetPriors(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("acp/reputation: get priors: %w", err)
+	}
+	latest := make(map[string]ExternalPrior)
+	for _, p := range priors {
+		if p.ValidUntil != 0 && now > p.ValidUntil {
+			continue
+		}
+		if cur, ok := latest[p.Issuer]; !ok || p.ImportedAt >= cur.ImportedAt {
+			latest[p.Issuer] = p
+		}
+	}
+	if len(latest) == 0 {
+		return nil, nil
+	}
+	sum := 0.0
+	for _, p := range latest {
+		sum += p.Value()
+	}
+	v := sum / float64(len(latest))
+	return &v, nil
+}
+
+// normalizeScore maps a score on scale to 0–1. The scale is already validated.
+func normalizeScore(score float64, scale string) float64 {
+	if scale == "0-100" {
+		return score / 100.0
+	}
+	return score
+}
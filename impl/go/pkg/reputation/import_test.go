@@ -0,0 +1,111 @@
+package reputation_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/reputation"
+)
+
+func peerKey(seed byte) (ed25519.PublicKey, ed25519.PrivateKey) {
+	s := make([]byte, ed25519.SeedSize)
+	s[0] = seed
+	priv := ed25519.NewKeyFromSeed(s)
+	return priv.Public().(ed25519.PublicKey), priv
+}
+
+func trusted(issuer string, pub ed25519.PublicKey) reputation.IssuerKeyResolver {
+	return reputation.IssuerKeyFunc(func(id string) (ed25519.PublicKey, error) {
+		if id != issuer {
+			return nil, errors.New("unknown issuer")
+		}
+		return pub, nil
+	})
+}
+
+func peerSnapshot(t *testing.T, subject string, score float64, scale string, priv ed25519.PrivateKey) *reputation.ReputationSnapshot {
+	t.Helper()
+	rep, err := reputation.Capture(reputation.CaptureRequest{
+		SubjectID: subject, Issuer: "org.peer", Score: score, Scale: scale,
+		ModelID: "peer-model-1", ValidFor: 5 * time.Minute,
+	}, priv)
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	return rep
+}
+
+func TestImportSnapshot_ColdStartUsesPrior(t *testing.T) {
+	eng := newEngine(t)
+	pub, priv := peerKey(1)
+
+	res, err := eng.ImportSnapshot(peerSnapshot(t, "agent-x", 90, "0-100", priv), trusted("org.peer", pub), reputation.ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportSnapshot: %v", err)
+	}
+	if res.Prior.Score != 0.90 || res.LocalScore != nil || len(res.Warnings) != 0 {
+		t.Errorf("result = %+v", res)
+	}
+
+	rec, _ := eng.GetRecord("agent-x")
+	if rec.Score != nil {
+		t.Errorf("Score = %v, want nil (no local history)", *rec.Score)
+	}
+	// Default weight 0.5: 0.5 + 0.5*(0.90-0.5) = 0.70
+	if rec.Prior == nil || *rec.Prior < 0.6999 || *rec.Prior > 0.7001 {
+		t.Fatalf("Prior = %v, want 0.70", rec.Prior)
+	}
+
+	_ = eng.RecordEvent("agent-x", reputation.EvtVerifyOK)
+	rec, _ = eng.GetRecord("agent-x")
+	if want := 0.70 + 0.10*0.05; *rec.Score < want-0.0001 || *rec.Score > want+0.0001 {
+		t.Errorf("first local score = %.4f, want %.4f (prior baseline)", *rec.Score, want)
+	}
+}
+
+func TestImportSnapshot_DivergenceWarning(t *testing.T) {
+	eng := newEngine(t)
+	pub, priv := peerKey(1)
+	for i := 0; i < 3; i++ {
+		_ = eng.RecordEvent("agent-y", reputation.EvtVerifyOK) // local ≈ 0.51
+	}
+
+	res, err := eng.ImportSnapshot(peerSnapshot(t, "agent-y", 0.95, "0-1", priv), trusted("org.peer", pub), reputation.ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportSnapshot: %v", err)
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0] != reputation.WarnDivergence || res.Divergence == nil {
+		t.Errorf("result = %+v, want REP-WARN-002", res)
+	}
+
+	res, err = eng.ImportSnapshot(peerSnapshot(t, "agent-y", 0.55, "0-1", priv), trusted("org.peer", pub), reputation.ImportOptions{})
+	if err != nil || len(res.Warnings) != 0 {
+		t.Errorf("close score: err=%v warnings=%v", err, res.Warnings)
+	}
+}
+
+func TestImportSnapshot_Rejections(t *testing.T) {
+	eng := newEngine(t)
+	pub, priv := peerKey(1)
+	otherPub, _ := peerKey(2)
+	rep := peerSnapshot(t, "agent-z", 0.8, "0-1", priv)
+
+	if _, err := eng.ImportSnapshot(rep, trusted("org.other", pub), reputation.ImportOptions{}); !errors.Is(err, reputation.ErrUntrustedIssuer) {
+		t.Errorf("untrusted issuer: err = %v", err)
+	}
+	if _, err := eng.ImportSnapshot(rep, trusted("org.peer", otherPub), reputation.ImportOptions{}); !errors.Is(err, reputation.ErrInvalidSignature) {
+		t.Errorf("wrong key: err = %v", err)
+	}
+	late := reputation.ImportOptions{Now: time.Now().Add(10 * time.Minute)}
+	if _, err := eng.ImportSnapshot(rep, trusted("org.peer", pub), late); !errors.Is(err, reputation.ErrExpired) {
+		t.Errorf("expired: err = %v", err)
+	}
+	if _, err := eng.ImportSnapshot(rep, trusted("org.peer", pub), reputation.ImportOptions{}); err != nil {
+		t.Fatalf("first import: %v", err)
+	}
+	if _, err := eng.ImportSnapshot(rep, trusted("org.peer", pub), reputation.ImportOptions{}); !errors.Is(err, reputation.ErrSnapshotReplayed) {
+		t.Errorf("replay: err = %v", err)
+	}
+}
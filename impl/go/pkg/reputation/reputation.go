@@ -17,6 +17,9 @@
 //
 // A new agent has score=nil (not 0.0). nil means "no history", not "untrustworthy".
 // Each institution defines its own policy for agents with nil score.
+// A snapshot imported from a peer institution (ACP-REP-PORTABILITY-1.1 §8)
+// does not set the score; it is kept as a weighted prior that replaces the
+// neutral baseline for the first local event.
 //
 // # Normative values
 //
@@ -86,6 +89,7 @@ var EventMetrics = map[string]float64{
 type ReputationRecord struct {
 	AgentID    string     `json:"agent_id"`
 	Score      *float64   `json:"score"`       // null = cold start (no history)
+	Prior      *float64   `json:"prior,omitempty"` // weighted external prior from imported snapshots
 	State      AgentState `json:"state"`
 	EventCount int        `json:"event_count"`
 	UpdatedAt  int64      `json:"updated_at"`
@@ -182,6 +186,13 @@ type ReputationStore interface {
 	// Events are returned most-recent-first.
 	// Returns (events, totalCount, error).
 	GetEvents(agentID string, limit, offset int) ([]ReputationEvent, int, error)
+
+	// RecordPrior stores an external prior imported from a peer snapshot.
+	// MUST return ErrSnapshotReplayed if prior.RepID was already recorded.
+	RecordPrior(agentID string, prior ExternalPrior) error
+
+	// GetPriors returns the external priors recorded for an agent, oldest first.
+	GetPriors(agentID string) ([]ExternalPrior, error)
 }
 
 // ─── Errors ───────────────────────────────────────────────────────────────────
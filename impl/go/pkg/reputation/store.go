@@ -24,6 +24,7 @@ type InMemoryReputationStore struct {
 type repEntry struct {
 	record ReputationRecord
 	events []ReputationEvent
+	priors []ExternalPrior
 }
 
 // NewInMemoryReputationStore creates an empty in-memory reputation store.
@@ -141,6 +142,34 @@ func (s *InMemoryReputationStore) GetEvents(agentID string, limit, offset int) (
 	return result, total, nil
 }
 
+// RecordPrior stores an external prior for an agent.
+// Returns ErrSnapshotReplayed if the rep_id was already recorded for it.
+func (s *InMemoryReputationStore) RecordPrior(agentID string, prior ExternalPrior) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.getOrCreate(agentID)
+	for _, p := range e.priors {
+		if p.RepID == prior.RepID {
+			return fmt.Errorf("%w: %s", ErrSnapshotReplayed, prior.RepID)
+		}
+	}
+	e.priors = append(e.priors, prior)
+	return nil
+}
+
+// GetPriors returns the external priors recorded for an agent, oldest first.
+func (s *InMemoryReputationStore) GetPriors(agentID string) ([]ExternalPrior, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e, ok := s.entries[agentID]
+	if !ok {
+		return []ExternalPrior{}, nil
+	}
+	return append([]ExternalPrior(nil), e.priors...), nil
+}
+
 // AgentCount returns the number of agents with at least one event.
 func (s *InMemoryReputationStore) AgentCount() int {
 	s.mu.RLock()
@@ -6,6 +6,7 @@
 // ACP-RISK-2.0 specification.
 // v3.0 (ACP-RISK-3.0): Rule 1 redefined to use context-scoped CountPattern,
 // eliminating cross-context state-mixing vulnerability.
+// F_rep (ACP-REP-1.2 §9) and derived History flags live in history.go.
 package risk
 
 import (
@@ -221,11 +222,12 @@ func DefaultPolicyConfig() PolicyConfig {
 
 // Factors is the full factor breakdown for forensic reproducibility (ACP-RISK-2.0 §6).
 type Factors struct {
-	Base     int `json:"base"`
-	Context  int `json:"context"`
-	History  int `json:"history"`
-	Resource int `json:"resource"`
-	Anomaly  int `json:"anomaly"`
+	Base       int `json:"base"`
+	Context    int `json:"context"`
+	History    int `json:"history"`
+	Resource   int `json:"resource"`
+	Anomaly    int `json:"anomaly"`
+	Reputation int `json:"reputation"` // F_rep; may be negative
 }
 
 // AnomalyDetail records which F_anom rules triggered.
@@ -243,6 +245,7 @@ type EvalRequest struct {
 	ResourceClass ResourceClass
 	Context       Context
 	History       History
+	Reputation    ReputationIn
 	Anomaly       AnomalyIn
 	Policy        PolicyConfig
 	Now           time.Time
@@ -256,6 +259,7 @@ type EvalResult struct {
 	DeniedReason string
 	Factors      Factors
 	AnomalyDetail AnomalyDetail
+	Breakdown    []FactorItem // every non-zero contribution to RSRaw
 	PolicyHash   string
 }
 
@@ -305,50 +309,12 @@ func resourceScore(rc ResourceClass) int {
 
 // contextScore computes F_ctx from environment signals (ACP-RISK-2.0 §3.2).
 func contextScore(ctx Context) int {
-	score := 0
-	if ctx.ExternalIP {
-		score += 20
-	}
-	if ctx.OffHours {
-		score += 15
-	}
-	if ctx.NonBusinessDay {
-		score += 10
-	}
-	if ctx.GeoOutside {
-		score += 15
-	}
-	if ctx.TimestampDrift {
-		score += 10
-	}
-	if ctx.UntrustedDevice {
-		score += 10
-	}
-	return score
+	return SumItems(contextItems(ctx))
 }
 
 // historyScore computes F_hist from behavioural history (ACP-RISK-2.0 §3.3).
 func historyScore(h History) int {
-	score := 0
-	if h.RecentDenial {
-		score += 20
-	}
-	if h.DenialRateHigh {
-		score += 15
-	}
-	if h.FreqAnomaly {
-		score += 15
-	}
-	if h.UnresolvedEscalations {
-		score += 10
-	}
-	if h.AmountNearLimit {
-		score += 10
-	}
-	if h.NoHistory {
-		score += 5
-	}
-	return score
+	return SumItems(HistoryItems(h))
 }
 
 // PatternKey returns the 32-hex-character pattern key for F_anom Rule 3.
@@ -411,6 +377,19 @@ func anomalyScore(req EvalRequest, querier LedgerQuerier) (int, AnomalyDetail, e
 	return score, detail, nil
 }
 
+// ScoreAnomaly computes F_anom alone (ACP-RISK-2.0 §3.4). Only AgentID,
+// Capability, Resource, Policy and Now of req are used.
+//
+// It lets callers that keep the v1.0 base score add ledger-backed anomaly
+// detection. Callers MUST record the request (see InMemoryQuerier.Record)
+// only after scoring it, so a request never counts towards its own F_anom.
+func ScoreAnomaly(req EvalRequest, querier LedgerQuerier) (int, AnomalyDetail, error) {
+	if req.Now.IsZero() {
+		req.Now = time.Now()
+	}
+	return anomalyScore(req, querier)
+}
+
 // Evaluate runs the full ACP-RISK-2.0 evaluation pipeline.
 //
 // Evaluation order:
@@ -451,30 +430,47 @@ func Evaluate(req EvalRequest, querier LedgerQuerier) (*EvalResult, error) {
 		}, nil
 	}
 
-	// Step 3: Compute RS = min(100, B + F_ctx + F_hist + F_res + F_anom)
+	// Step 3: Compute RS = min(100, max(0, B + F_ctx + F_hist + F_res + F_anom + F_rep))
 	fBase := capabilityBase(req.Capability)
 	fCtx := contextScore(req.Context)
 	fHist := historyScore(req.History)
 	fRes := resourceScore(req.ResourceClass)
+	fRep := ReputationScore(req.Reputation)
 
 	fAnom, detail, err := anomalyScore(req, querier)
 	if err != nil {
 		return nil, err
 	}
 
-	rsRaw := fBase + fCtx + fHist + fRes + fAnom
+	rsRaw := fBase + fCtx + fHist + fRes + fAnom + fRep
 	rsFinal := rsRaw
 	if rsFinal > 100 {
 		rsFinal = 100
 	}
+	if rsFinal < 0 {
+		rsFinal = 0
+	}
 
 	factors := Factors{
-		Base:     fBase,
-		Context:  fCtx,
-		History:  fHist,
-		Resource: fRes,
-		Anomaly:  fAnom,
+		Base:       fBase,
+		Context:    fCtx,
+		History:    fHist,
+		Resource:   fRes,
+		Anomaly:    fAnom,
+		Reputation: fRep,
+	}
+
+	var breakdown []FactorItem
+	if fBase != 0 {
+		breakdown = append(breakdown, FactorItem{Factor: "base", Signal: "capability", Points: fBase})
 	}
+	breakdown = append(breakdown, contextItems(req.Context)...)
+	breakdown = append(breakdown, HistoryItems(req.History)...)
+	if fRes != 0 {
+		breakdown = append(breakdown, FactorItem{Factor: "resource", Signal: string(req.ResourceClass), Points: fRes})
+	}
+	breakdown = append(breakdown, AnomalyItems(detail)...)
+	breakdown = append(breakdown, ReputationItems(req.Reputation)...)
 
 	// Step 4: Apply thresholds per autonomy level.
 	decision := applyThresholds(rsFinal, req.Policy)
@@ -485,6 +481,7 @@ func Evaluate(req EvalRequest, querier LedgerQuerier) (*EvalResult, error) {
 		Decision:     decision,
 		Factors:      factors,
 		AnomalyDetail: detail,
+		Breakdown:    breakdown,
 		PolicyHash:   req.Policy.PolicyHash,
 	}, nil
 }
@@ -537,6 +534,13 @@ type InMemoryQuerier struct {
 	denials  map[string][]time.Time // agentID → denial timestamps
 	patterns map[string][]time.Time // patternKey → timestamps
 	cooldown map[string]time.Time   // agentID → cooldown expiry
+	pending  map[string]escalation  // escalationID → escalation, until resolved
+}
+
+// escalation is an unresolved escalation tracked by InMemoryQuerier.
+type escalation struct {
+	agentID   string
+	expiresAt time.Time
 }
 
 // NewInMemoryQuerier returns a fresh InMemoryQuerier.
@@ -546,6 +550,7 @@ func NewInMemoryQuerier() *InMemoryQuerier {
 		denials:  make(map[string][]time.Time),
 		patterns: make(map[string][]time.Time),
 		cooldown: make(map[string]time.Time),
+		pending:  make(map[string]escalation),
 	}
 }
 
@@ -570,6 +575,48 @@ func (q *InMemoryQuerier) AddPattern(patternKey string, t time.Time) {
 	q.patterns[patternKey] = append(q.patterns[patternKey], t)
 }
 
+// Record registers one evaluated request: the request and its pattern hit and,
+// when decision is DENIED, a denial. All three updates are applied atomically.
+func (q *InMemoryQuerier) Record(agentID, capability, resource string, decision Decision, t time.Time) {
+	patKey := PatternKey(agentID, capability, resource)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.requests[agentID] = append(q.requests[agentID], t)
+	q.patterns[patKey] = append(q.patterns[patKey], t)
+	if decision == DENIED {
+		q.denials[agentID] = append(q.denials[agentID], t)
+	}
+}
+
+// AddEscalation records an unresolved escalation of agentID that stops
+// counting as pending at expiresAt.
+func (q *InMemoryQuerier) AddEscalation(agentID, escalationID string, expiresAt time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending[escalationID] = escalation{agentID: agentID, expiresAt: expiresAt}
+}
+
+// ResolveEscalation marks escalationID as resolved. Unknown IDs are ignored.
+func (q *InMemoryQuerier) ResolveEscalation(escalationID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.pending, escalationID)
+}
+
+// CountPendingEscalations returns the number of unresolved escalations of
+// agentID that have not expired at now.
+func (q *InMemoryQuerier) CountPendingEscalations(agentID string, now time.Time) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, e := range q.pending {
+		if e.agentID == agentID && now.Before(e.expiresAt) {
+			n++
+		}
+	}
+	return n, nil
+}
+
 // SetCooldown sets agentID into cooldown until the given time.
 func (q *InMemoryQuerier) SetCooldown(agentID string, until time.Time) {
 	q.mu.Lock()
@@ -577,6 +624,50 @@ func (q *InMemoryQuerier) SetCooldown(agentID string, until time.Time) {
 	q.cooldown[agentID] = until
 }
 
+// Prune drops request, denial and pattern timestamps older than before, and
+// cooldowns and escalations that expired before it, so long-running servers do
+// not accumulate state no window reads anymore. It returns the number of
+// entries removed.
+func (q *InMemoryQuerier) Prune(before time.Time) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := pruneTimes(q.requests, before) + pruneTimes(q.denials, before) + pruneTimes(q.patterns, before)
+	for agentID, until := range q.cooldown {
+		if until.Before(before) {
+			delete(q.cooldown, agentID)
+			n++
+		}
+	}
+	for escalationID, e := range q.pending {
+		if e.expiresAt.Before(before) {
+			delete(q.pending, escalationID)
+			n++
+		}
+	}
+	return n
+}
+
+// pruneTimes filters every list in m down to the timestamps at or after
+// before, deleting keys left empty, and returns how many timestamps it dropped.
+func pruneTimes(m map[string][]time.Time, before time.Time) int {
+	n := 0
+	for key, ts := range m {
+		kept := ts[:0]
+		for _, t := range ts {
+			if !t.Before(before) {
+				kept = append(kept, t)
+			}
+		}
+		n += len(ts) - len(kept)
+		if len(kept) == 0 {
+			delete(m, key)
+		} else {
+			m[key] = kept
+		}
+	}
+	return n
+}
+
 // CountRequests returns the count of requests in the sliding window ending at now.
 func (q *InMemoryQuerier) CountRequests(agentID string, window time.Duration, now time.Time) (int, error) {
 	q.mu.Lock()
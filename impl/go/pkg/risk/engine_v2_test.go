@@ -463,3 +463,83 @@ func TestDeterminism(t *testing.T) {
 		t.Errorf("non-deterministic: (%d,%s) vs (%d,%s)", r1.RSFinal, r1.Decision, r2.RSFinal, r2.Decision)
 	}
 }
+
+// ── ScoreAnomaly + InMemoryQuerier.Record ─────────────────────────────────────
+
+func TestScoreAnomaly_RecordAfterScore(t *testing.T) {
+	q := NewInMemoryQuerier()
+	policy := DefaultPolicyConfig()
+	req := EvalRequest{
+		AgentID:    "agent-R",
+		Capability: "acp:cap:data.read",
+		Resource:   "org/reports/Q1",
+		Policy:     policy,
+		Now:        t0,
+	}
+	// Rule 3 triggers once Y prior requests share the pattern; the request
+	// being scored must not count towards itself.
+	for i := 0; i < policy.AnomalyRule3ThresholdY; i++ {
+		score, detail, err := ScoreAnomaly(req, q)
+		if err != nil {
+			t.Fatalf("ScoreAnomaly: %v", err)
+		}
+		if score != 0 || detail.Rule3Triggered {
+			t.Fatalf("request %d: F_anom=%d before threshold", i, score)
+		}
+		q.Record(req.AgentID, req.Capability, req.Resource, APPROVED, t0)
+	}
+	score, detail, _ := ScoreAnomaly(req, q)
+	if !detail.Rule3Triggered || score != 15 {
+		t.Fatalf("F_anom=%d rule3=%v, want 15/true", score, detail.Rule3Triggered)
+	}
+}
+
+func TestInMemoryQuerier_RecordDenial(t *testing.T) {
+	q := NewInMemoryQuerier()
+	q.Record("agent-D", "acp:cap:data.read", "r", APPROVED, t0)
+	q.Record("agent-D", "acp:cap:data.read", "r", DENIED, t0)
+	if n, _ := q.CountDenials("agent-D", t0.Add(-time.Minute)); n != 1 {
+		t.Errorf("denials = %d, want 1", n)
+	}
+	if n, _ := q.CountRequests("agent-D", time.Minute, t0); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
+
+func TestInMemoryQuerier_Prune(t *testing.T) {
+	q := NewInMemoryQuerier()
+	old := t0.Add(-HistoryWindow - time.Hour)
+	q.Record("agent-P", "acp:cap:data.read", "r", DENIED, old)
+	q.Record("agent-P", "acp:cap:data.read", "r", APPROVED, t0)
+	q.SetCooldown("agent-P", old.Add(10*time.Minute))
+	q.SetCooldown("agent-Q", t0.Add(10*time.Minute))
+	q.AddEscalation("agent-P", "esc-old", old.Add(time.Minute))
+	q.AddEscalation("agent-P", "esc-new", t0.Add(time.Hour))
+
+	// One request, one pattern hit, one denial, one cooldown and one
+	// escalation are stale.
+	if n := q.Prune(t0.Add(-HistoryWindow)); n != 5 {
+		t.Errorf("Prune removed %d entries, want 5", n)
+	}
+	if n, _ := q.CountPendingEscalations("agent-P", old); n != 1 {
+		t.Errorf("pending escalations = %d, want 1", n)
+	}
+	if n, _ := q.CountRequests("agent-P", 2*HistoryWindow, t0); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+	if n, _ := q.CountDenials("agent-P", old); n != 0 {
+		t.Errorf("denials = %d, want 0", n)
+	}
+	if n, _ := q.CountPattern(PatternKey("agent-P", "acp:cap:data.read", "r"), old); n != 1 {
+		t.Errorf("pattern hits = %d, want 1", n)
+	}
+	if !q.CooldownUntil("agent-P").IsZero() {
+		t.Error("expired cooldown not pruned")
+	}
+	if !q.CooldownActive("agent-Q", t0) {
+		t.Error("active cooldown pruned")
+	}
+	if n := q.Prune(t0.Add(-HistoryWindow)); n != 0 {
+		t.Errorf("second Prune removed %d entries, want 0", n)
+	}
+}
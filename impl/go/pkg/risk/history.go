@@ -0,0 +1,256 @@
+// history.go — derived F_hist signals, reputation factor and factor breakdown.
+//
+// History flags used to be supplied by the caller. DeriveHistory computes
+// them from ledger-backed state (the LedgerQuerier counters plus pending
+// escalations) and from the agent's ACP-REP record, so two evaluators that
+// share that state assign the same F_hist to the same request.
+
+package risk
+
+import (
+	"fmt"
+	"time"
+)
+
+// ── Derivation windows and thresholds ────────────────────────────────────────
+
+const (
+	recentDenialWindow  = time.Hour           // RecentDenial: ≥ 1 denial in the last hour
+	denialRateWindow    = 24 * time.Hour      // DenialRateHigh: denials/requests over 24h …
+	denialRateMin       = 0.5                 // … ≥ 50% …
+	denialRateMinSample = 4                   // … over at least 4 requests
+	freqWindow          = 60 * time.Second    // FreqAnomaly: requests in the last minute …
+	freqBaselineWindow  = time.Hour           // … vs the per-minute average of the last hour
+	freqFactor          = 3                   // … more than 3× the average …
+	freqMinRequests     = 5                   // … and at least 5 requests
+	noHistoryWindow     = 30 * 24 * time.Hour // NoHistory: no request in 30 days and no reputation
+)
+
+// HistoryWindow is the longest window a derived signal reads: state older
+// than this no longer affects F_hist or F_anom.
+const HistoryWindow = noHistoryWindow
+
+// Reputation states as reported by ACP-REP-1.1 (mirrors reputation.AgentState).
+const (
+	RepStateActive    = "ACTIVE"
+	RepStateProbation = "PROBATION"
+	RepStateSuspended = "SUSPENDED"
+	RepStateBanned    = "BANNED"
+)
+
+// ReputationIn carries the agent's ACP-REP record for F_rep.
+// Score is nil for a cold-start agent; callers MAY pass an imported prior
+// (ACP-REP-PORTABILITY-1.1) when the agent has no local score.
+type ReputationIn struct {
+	Score *float64 `json:"score"`
+	State string   `json:"state"`
+}
+
+// HistorySource is the state DeriveHistory reads: the anomaly counters plus
+// escalations still awaiting resolution. InMemoryQuerier implements it.
+type HistorySource interface {
+	LedgerQuerier
+	// CountPendingEscalations returns the number of unresolved escalations of
+	// agentID that have not expired at now.
+	CountPendingEscalations(agentID string, now time.Time) (int, error)
+}
+
+// HistoryInput is the input to DeriveHistory.
+type HistoryInput struct {
+	AgentID    string
+	Reputation ReputationIn
+	Now        time.Time
+}
+
+// DeriveHistory computes the History flags for an agent:
+//
+//	RecentDenial          ≥ 1 DENIED in the last hour
+//	DenialRateHigh        ≥ 50% DENIED over the last 24h (min. 4 requests)
+//	FreqAnomaly           requests in the last 60s > 3× the per-minute average
+//	                      of the last hour, and ≥ 5
+//	UnresolvedEscalations ≥ 1 pending, unexpired escalation
+//	NoHistory             no request in 30 days and no reputation score
+//
+// AmountNearLimit depends on per-agent limits outside this engine and is
+// never set. Returns an error if src is unavailable (fail-closed, RISK-008).
+func DeriveHistory(in HistoryInput, src HistorySource) (History, error) {
+	if src == nil {
+		return History{}, fmt.Errorf("RISK-008: HistorySource unavailable (nil) — fail-closed")
+	}
+	now := in.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	var h History
+
+	recentDenials, err := src.CountDenials(in.AgentID, now.Add(-recentDenialWindow))
+	if err != nil {
+		return History{}, fmt.Errorf("RISK-008: history query failed: %w", err)
+	}
+	h.RecentDenial = recentDenials > 0
+
+	requests24h, err := src.CountRequests(in.AgentID, denialRateWindow, now)
+	if err != nil {
+		return History{}, fmt.Errorf("RISK-008: history query failed: %w", err)
+	}
+	denials24h, err := src.CountDenials(in.AgentID, now.Add(-denialRateWindow))
+	if err != nil {
+		return History{}, fmt.Errorf("RISK-008: history query failed: %w", err)
+	}
+	h.DenialRateHigh = requests24h >= denialRateMinSample &&
+		float64(denials24h) >= denialRateMin*float64(requests24h)
+
+	lastMinute, err := src.CountRequests(in.AgentID, freqWindow, now)
+	if err != nil {
+		return History{}, fmt.Errorf("RISK-008: history query failed: %w", err)
+	}
+	lastHour, err := src.CountRequests(in.AgentID, freqBaselineWindow, now)
+	if err != nil {
+		return History{}, fmt.Errorf("RISK-008: history query failed: %w", err)
+	}
+	perMinute := float64(lastHour) / freqBaselineWindow.Minutes()
+	h.FreqAnomaly = lastMinute >= freqMinRequests && float64(lastMinute) > freqFactor*perMinute
+
+	pending, err := src.CountPendingEscalations(in.AgentID, now)
+	if err != nil {
+		return History{}, fmt.Errorf("RISK-008: history query failed: %w", err)
+	}
+	h.UnresolvedEscalations = pending > 0
+
+	if in.Reputation.Score == nil {
+		seen, err := src.CountRequests(in.AgentID, noHistoryWindow, now)
+		if err != nil {
+			return History{}, fmt.Errorf("RISK-008: history query failed: %w", err)
+		}
+		h.NoHistory = seen == 0
+	}
+	return h, nil
+}
+
+// DedupAnomaly drops the F_anom rules that read the same counters as an
+// F_hist signal set in h, so that one run of denials or one burst of
+// requests is scored once: Rule 1 (request rate) when FreqAnomaly is set,
+// Rule 2 (denials in 24h) when RecentDenial or DenialRateHigh is. The
+// F_anom of the result is SumItems(AnomalyItems(d)).
+func DedupAnomaly(d AnomalyDetail, h History) AnomalyDetail {
+	if h.FreqAnomaly {
+		d.Rule1Triggered = false
+	}
+	if h.RecentDenial || h.DenialRateHigh {
+		d.Rule2Triggered = false
+	}
+	return d
+}
+
+// ── F_rep (ACP-REP-1.2 §9) ───────────────────────────────────────────────────
+
+// ReputationItems returns the F_rep contributions of r, using the ACP-REP-1.2
+// §9 modifier mapping on the 0–100 RS scale:
+//
+//	score ≥ 0.80 → −5    score 0.50–0.79 → 0    score < 0.50 → +10
+//
+// plus +15 while the agent is in PROBATION. A cold-start agent (nil score)
+// contributes 0 here; it is covered by F_hist NoHistory.
+func ReputationItems(r ReputationIn) []FactorItem {
+	var items []FactorItem
+	if r.Score != nil {
+		switch s := *r.Score; {
+		case s >= 0.80:
+			items = append(items, FactorItem{Factor: "reputation", Signal: "score_high", Points: -5})
+		case s < 0.50:
+			items = append(items, FactorItem{Factor: "reputation", Signal: "score_low", Points: 10})
+		}
+	}
+	if r.State == RepStateProbation {
+		items = append(items, FactorItem{Factor: "reputation", Signal: "probation", Points: 15})
+	}
+	return items
+}
+
+// ReputationScore returns F_rep for r (see ReputationItems).
+func ReputationScore(r ReputationIn) int {
+	return SumItems(ReputationItems(r))
+}
+
+// ── Factor breakdown ─────────────────────────────────────────────────────────
+
+// FactorItem is one contribution to RS: the factor it belongs to, the signal
+// that triggered it and the points it added (negative points lower RS).
+type FactorItem struct {
+	Factor string `json:"factor"` // base | context | history | resource | anomaly | reputation | amount
+	Signal string `json:"signal"`
+	Points int    `json:"points"`
+}
+
+// HistoryItems returns the F_hist contributions of h.
+func HistoryItems(h History) []FactorItem {
+	var items []FactorItem
+	add := func(on bool, signal string, points int) {
+		if on {
+			items = append(items, FactorItem{Factor: "history", Signal: signal, Points: points})
+		}
+	}
+	add(h.RecentDenial, "recent_denial", 20)
+	add(h.DenialRateHigh, "denial_rate_high", 15)
+	add(h.FreqAnomaly, "freq_anomaly", 15)
+	add(h.UnresolvedEscalations, "unresolved_escalations", 10)
+	add(h.AmountNearLimit, "amount_near_limit", 10)
+	add(h.NoHistory, "no_history", 5)
+	return items
+}
+
+// contextItems returns the F_ctx contributions of ctx.
+func contextItems(ctx Context) []FactorItem {
+	var items []FactorItem
+	add := func(on bool, signal string, points int) {
+		if on {
+			items = append(items, FactorItem{Factor: "context", Signal: signal, Points: points})
+		}
+	}
+	add(ctx.ExternalIP, "external_ip", 20)
+	add(ctx.OffHours, "off_hours", 15)
+	add(ctx.NonBusinessDay, "non_business_day", 10)
+	add(ctx.GeoOutside, "geo_outside", 15)
+	add(ctx.TimestampDrift, "timestamp_drift", 10)
+	add(ctx.UntrustedDevice, "untrusted_device", 10)
+	return items
+}
+
+// AnomalyItems returns the F_anom contributions recorded in d.
+func AnomalyItems(d AnomalyDetail) []FactorItem {
+	var items []FactorItem
+	if d.Rule1Triggered {
+		items = append(items, FactorItem{Factor: "anomaly", Signal: "rule1_context_rate", Points: 20})
+	}
+	if d.Rule2Triggered {
+		items = append(items, FactorItem{Factor: "anomaly", Signal: "rule2_recent_denials", Points: 15})
+	}
+	if d.Rule3Triggered {
+		items = append(items, FactorItem{Factor: "anomaly", Signal: "rule3_repeated_pattern", Points: 15})
+	}
+	return items
+}
+
+// AssessItems returns the ACP-RISK-1.0 contributions behind Assess(req):
+// capability base, resource scope and, if present, amount.
+func AssessItems(req Request) []FactorItem {
+	items := []FactorItem{
+		{Factor: "base", Signal: "capability", Points: baseCapabilityScore(req.Capability)},
+		{Factor: "resource", Signal: "scope", Points: resourceScopeScore(req.Resource)},
+	}
+	if req.Amount != nil {
+		if p := amountScore(*req.Amount); p > 0 {
+			items = append(items, FactorItem{Factor: "amount", Signal: "amount", Points: p})
+		}
+	}
+	return items
+}
+
+// SumItems returns the total points of items.
+func SumItems(items []FactorItem) int {
+	n := 0
+	for _, it := range items {
+		n += it.Points
+	}
+	return n
+}
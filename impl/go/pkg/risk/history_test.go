@@ -0,0 +1,171 @@
+package risk
+
+import (
+	"testing"
+	"time"
+)
+
+func score(v float64) *float64 { return &v }
+
+// ── DeriveHistory ─────────────────────────────────────────────────────────────
+
+func TestDeriveHistory_ColdStart(t *testing.T) {
+	h, err := DeriveHistory(HistoryInput{AgentID: "agent-new", Now: t0}, NewInMemoryQuerier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != (History{NoHistory: true}) {
+		t.Errorf("History = %+v, want only NoHistory", h)
+	}
+
+	// A reputation score (local or imported prior) is history.
+	h, _ = DeriveHistory(HistoryInput{AgentID: "agent-new", Reputation: ReputationIn{Score: score(0.7)}, Now: t0}, NewInMemoryQuerier())
+	if h.NoHistory {
+		t.Error("NoHistory set for an agent with a reputation score")
+	}
+}
+
+func TestDeriveHistory_Denials(t *testing.T) {
+	q := NewInMemoryQuerier()
+	for i := 0; i < 4; i++ {
+		at := t0.Add(-time.Duration(2+i) * time.Hour)
+		q.AddRequest("agent-A", at)
+		if i < 2 {
+			q.AddDenial("agent-A", at)
+		}
+	}
+	h, err := DeriveHistory(HistoryInput{AgentID: "agent-A", Now: t0}, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.DenialRateHigh || h.RecentDenial || h.NoHistory {
+		t.Errorf("History = %+v, want DenialRateHigh only", h)
+	}
+
+	q.Record("agent-A", "acp:cap:data.read", "org/r", DENIED, t0.Add(-10*time.Minute))
+	h, _ = DeriveHistory(HistoryInput{AgentID: "agent-A", Now: t0}, q)
+	if !h.RecentDenial {
+		t.Error("RecentDenial not set after a denial in the last hour")
+	}
+}
+
+func TestDeriveHistory_FreqAnomaly(t *testing.T) {
+	q := NewInMemoryQuerier()
+	for i := 0; i < 4; i++ {
+		q.AddRequest("agent-A", t0.Add(-time.Duration(i)*time.Second))
+	}
+	if h, _ := DeriveHistory(HistoryInput{AgentID: "agent-A", Now: t0}, q); h.FreqAnomaly {
+		t.Error("FreqAnomaly set below the minimum of 5 requests")
+	}
+	q.AddRequest("agent-A", t0)
+	if h, _ := DeriveHistory(HistoryInput{AgentID: "agent-A", Now: t0}, q); !h.FreqAnomaly {
+		t.Error("FreqAnomaly not set for a burst of 5 requests")
+	}
+}
+
+func TestDeriveHistory_UnresolvedEscalations(t *testing.T) {
+	q := NewInMemoryQuerier()
+	q.AddEscalation("agent-A", "esc-1", t0.Add(time.Hour))
+	if h, _ := DeriveHistory(HistoryInput{AgentID: "agent-A", Now: t0}, q); !h.UnresolvedEscalations {
+		t.Error("UnresolvedEscalations not set with a pending escalation")
+	}
+	if h, _ := DeriveHistory(HistoryInput{AgentID: "agent-A", Now: t0.Add(time.Hour)}, q); h.UnresolvedEscalations {
+		t.Error("UnresolvedEscalations still set after the escalation expired")
+	}
+	q.ResolveEscalation("esc-1")
+	if h, _ := DeriveHistory(HistoryInput{AgentID: "agent-A", Now: t0}, q); h.UnresolvedEscalations {
+		t.Error("UnresolvedEscalations still set after resolution")
+	}
+}
+
+func TestDeriveHistory_NilSource(t *testing.T) {
+	if _, err := DeriveHistory(HistoryInput{AgentID: "agent-A"}, nil); err == nil {
+		t.Fatal("expected RISK-008 error for nil source")
+	}
+}
+
+func TestDedupAnomaly(t *testing.T) {
+	all := AnomalyDetail{Rule1Triggered: true, Rule2Triggered: true, Rule3Triggered: true}
+	if d := DedupAnomaly(all, History{}); d != all {
+		t.Errorf("no overlapping signal: %+v", d)
+	}
+	d := DedupAnomaly(all, History{FreqAnomaly: true, DenialRateHigh: true})
+	if d != (AnomalyDetail{Rule3Triggered: true}) {
+		t.Errorf("FreqAnomaly+DenialRateHigh: %+v, want only Rule 3", d)
+	}
+	if got := SumItems(AnomalyItems(DedupAnomaly(all, History{RecentDenial: true}))); got != 35 {
+		t.Errorf("RecentDenial: F_anom = %d, want 35 (Rule 1 + Rule 3)", got)
+	}
+}
+
+// ── F_rep and breakdown ───────────────────────────────────────────────────────
+
+func TestEvaluate_ProbationScoresHigher(t *testing.T) {
+	eval := func(rep ReputationIn) *EvalResult {
+		t.Helper()
+		res, err := Evaluate(EvalRequest{
+			AgentID: "agent-A", Capability: "acp:cap:data.read",
+			Resource: "org/r", ResourceClass: ResourceSensitive,
+			Reputation: rep, Policy: DefaultPolicyConfig(), Now: t0,
+		}, NewInMemoryQuerier())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	pristine := eval(ReputationIn{Score: score(0.9), State: RepStateActive})
+	probation := eval(ReputationIn{Score: score(0.25), State: RepStateProbation})
+
+	if pristine.Factors.Reputation != -5 || probation.Factors.Reputation != 25 {
+		t.Errorf("F_rep pristine=%d probation=%d, want -5 and 25",
+			pristine.Factors.Reputation, probation.Factors.Reputation)
+	}
+	if probation.RSFinal-pristine.RSFinal != 30 {
+		t.Errorf("RS pristine=%d probation=%d, want a 30-point gap", pristine.RSFinal, probation.RSFinal)
+	}
+}
+
+func TestEvaluate_BreakdownSumsToRSRaw(t *testing.T) {
+	q := NewInMemoryQuerier()
+	for i := 0; i < 3; i++ {
+		q.AddDenial("agent-A", t0.Add(-time.Duration(i+1)*time.Minute)) // Rule 2
+	}
+	res, err := Evaluate(EvalRequest{
+		AgentID: "agent-A", Capability: "acp:cap:financial.payment",
+		Resource: "org/acc", ResourceClass: ResourceRestricted,
+		Context:    Context{ExternalIP: true, OffHours: true},
+		History:    History{RecentDenial: true, NoHistory: true},
+		Reputation: ReputationIn{Score: score(0.4), State: RepStateProbation},
+		Policy:     DefaultPolicyConfig(), Now: t0,
+	}, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := SumItems(res.Breakdown); got != res.RSRaw {
+		t.Errorf("breakdown sums to %d, RSRaw = %d (%+v)", got, res.RSRaw, res.Breakdown)
+	}
+	factors := map[string]bool{}
+	for _, it := range res.Breakdown {
+		factors[it.Factor] = true
+	}
+	for _, f := range []string{"base", "context", "history", "resource", "anomaly", "reputation"} {
+		if !factors[f] {
+			t.Errorf("breakdown has no %q item: %+v", f, res.Breakdown)
+		}
+	}
+}
+
+func TestEvaluate_NegativeModifierFloorsAtZero(t *testing.T) {
+	res, err := Evaluate(EvalRequest{
+		AgentID: "agent-A", Capability: "acp:cap:data.read",
+		Resource: "org/r", ResourceClass: ResourcePublic,
+		Reputation: ReputationIn{Score: score(0.95), State: RepStateActive},
+		Policy:     DefaultPolicyConfig(), Now: t0,
+	}, NewInMemoryQuerier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.RSFinal < 0 {
+		t.Errorf("RSFinal = %d, want ≥ 0", res.RSFinal)
+	}
+}
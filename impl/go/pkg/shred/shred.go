@@ -0,0 +1,408 @@
+// Package shred implements crypto-shredding of the personal data recorded in
+// the audit ledger (ledger.Redactor).
+//
+// Sensitive payload fields are not written to the ledger. In their place the
+// ledger hashes a commitment
+//
+//	{"acp_commitment": base64url(SHA-256(salt || JCS(value)))}
+//
+// with a fresh 32-byte salt per value, so equal values do not produce equal
+// commitments. The salt and the value are sealed with AES-256-GCM under a key
+// of the data subject and kept in this Store, outside the ledger. Shredding a
+// subject destroys its key: every value sealed under it becomes unrecoverable
+// and reads as ledger.Redacted, while the ledger still verifies.
+//
+// A Store is in memory (NewStore) or persisted to a directory (OpenFileStore):
+// keys.json holds the live subject keys and is rewritten atomically when a key
+// is created or destroyed; sealed.jsonl is an append-only log of sealed
+// values. Back up and erase keys.json under the same policy as the personal
+// data itself: a copy of a shredded key restores the data.
+package shred
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/gowebpki/jcs"
+)
+
+// CommitmentField is the single key of a commitment object.
+const CommitmentField = "acp_commitment"
+
+// Error sentinels.
+var (
+	ErrUnknownSubject = errors.New("acp/shred: no key for subject")
+	ErrClosed         = errors.New("acp/shred: store closed")
+)
+
+// sealed is one sealed value, keyed by its commitment.
+type sealed struct {
+	Commitment string `json:"commitment"`
+	KID        string `json:"kid"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// opening is the plaintext of a sealed value.
+type opening struct {
+	Salt  string      `json:"salt"`
+	Value interface{} `json:"value"`
+}
+
+// keyFile is the content of keys.json.
+type keyFile struct {
+	Subjects map[string]string `json:"subjects"` // subject → kid
+	Keys     map[string]string `json:"keys"`     // kid → base64url key
+}
+
+// Store holds the subject keys and the sealed values. It implements
+// ledger.Redactor.
+type Store struct {
+	mu       sync.RWMutex
+	subjects map[string]string // subject → kid
+	keys     map[string][]byte // kid → AES-256 key; deleted when shredded
+	sealed   map[string]sealed // commitment → sealed value
+	counts   map[string]int    // kid → sealed values
+
+	dir string   // "" for an in-memory store
+	log *os.File // sealed.jsonl, opened for appending
+}
+
+var _ ledger.Redactor = (*Store)(nil)
+
+// NewStore creates an in-memory Store: keys are lost on restart, which
+// shreds every subject.
+func NewStore() *Store {
+	return &Store{
+		subjects: make(map[string]string),
+		keys:     make(map[string][]byte),
+		sealed:   make(map[string]sealed),
+		counts:   make(map[string]int),
+	}
+}
+
+// OpenFileStore opens (or creates) the Store persisted in dir.
+func OpenFileStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("acp/shred: %w", err)
+	}
+	s := NewStore()
+	s.dir = dir
+
+	raw, err := os.ReadFile(filepath.Join(dir, "keys.json"))
+	switch {
+	case err == nil:
+		var kf keyFile
+		if err := json.Unmarshal(raw, &kf); err != nil {
+			return nil, fmt.Errorf("acp/shred: keys.json: %w", err)
+		}
+		for kid, enc := range kf.Keys {
+			key, err := base64.RawURLEncoding.DecodeString(enc)
+			if err != nil || len(key) != 32 {
+				return nil, fmt.Errorf("acp/shred: keys.json: malformed key %s", kid)
+			}
+			s.keys[kid] = key
+		}
+		for subject, kid := range kf.Subjects {
+			s.subjects[subject] = kid
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("acp/shred: %w", err)
+	}
+
+	path := filepath.Join(dir, "sealed.jsonl")
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64*1024), 16<<20)
+		var good int64 // length of the well-formed prefix
+		torn := false
+		for line := 1; sc.Scan(); line++ {
+			var rec sealed
+			if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+				// A torn final line from a crash mid-append is the only
+				// expected corruption; anything else is refused.
+				if !sc.Scan() {
+					torn = true
+					break
+				}
+				f.Close()
+				return nil, fmt.Errorf("acp/shred: %s line %d: %w", path, line, err)
+			}
+			good += int64(len(sc.Bytes())) + 1
+			s.sealed[rec.Commitment] = rec
+			s.counts[rec.KID]++
+		}
+		err := sc.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("acp/shred: read %s: %w", path, err)
+		}
+		if torn {
+			// Drop the torn line so that the next append starts a new one.
+			if err := os.Truncate(path, good); err != nil {
+				return nil, fmt.Errorf("acp/shred: truncate %s: %w", path, err)
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("acp/shred: open %s: %w", path, err)
+	}
+
+	s.log, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("acp/shred: open %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Seal commits to value and seals it under the key of subject, creating the
+// key on first use (ledger.Redactor). A subject whose key was shredded gets a
+// new key: values sealed afterwards are readable until it is shredded again.
+func (s *Store) Seal(subject string, value interface{}) (interface{}, error) {
+	canon, err := canonical(value)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("acp/shred: salt: %w", err)
+	}
+	commitment := commit(salt, canon)
+	plain, err := json.Marshal(opening{Salt: base64.RawURLEncoding.EncodeToString(salt), Value: value})
+	if err != nil {
+		return nil, fmt.Errorf("acp/shred: encode: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dir != "" && s.log == nil {
+		return nil, ErrClosed
+	}
+	kid, key, err := s.subjectKey(subject)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("acp/shred: nonce: %w", err)
+	}
+	rec := sealed{
+		Commitment: commitment,
+		KID:        kid,
+		Nonce:      base64.RawURLEncoding.EncodeToString(nonce),
+		Ciphertext: base64.RawURLEncoding.EncodeToString(aead.Seal(nil, nonce, plain, []byte(commitment))),
+	}
+	if s.log != nil {
+		line, _ := json.Marshal(rec)
+		if _, err := s.log.Write(append(line, '\n')); err != nil {
+			return nil, fmt.Errorf("acp/shred: append: %w", err)
+		}
+		if err := s.log.Sync(); err != nil {
+			return nil, fmt.Errorf("acp/shred: sync: %w", err)
+		}
+	}
+	s.sealed[commitment] = rec
+	s.counts[kid]++
+	return map[string]interface{}{CommitmentField: commitment}, nil
+}
+
+// Open returns the value committed to by v, or ledger.Redacted if it cannot
+// be recovered — its key was shredded, or the store does not hold it
+// (ledger.Redactor). ok is false if v is not a commitment.
+func (s *Store) Open(v interface{}) (interface{}, bool) {
+	commitment, ok := IsCommitment(v)
+	if !ok {
+		return nil, false
+	}
+	s.mu.RLock()
+	rec, found := s.sealed[commitment]
+	key := s.keys[rec.KID]
+	s.mu.RUnlock()
+	if !found || key == nil {
+		return ledger.Redacted, true
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return ledger.Redacted, true
+	}
+	nonce, err1 := base64.RawURLEncoding.DecodeString(rec.Nonce)
+	ct, err2 := base64.RawURLEncoding.DecodeString(rec.Ciphertext)
+	if err1 != nil || err2 != nil || len(nonce) != aead.NonceSize() {
+		return ledger.Redacted, true
+	}
+	plain, err := aead.Open(nil, nonce, ct, []byte(commitment))
+	if err != nil {
+		return ledger.Redacted, true
+	}
+	var op opening
+	if err := json.Unmarshal(plain, &op); err != nil {
+		return ledger.Redacted, true
+	}
+	// The opening must match the hashed commitment, or it is not the value
+	// the ledger recorded.
+	salt, err := base64.RawURLEncoding.DecodeString(op.Salt)
+	canon, cerr := canonical(op.Value)
+	if err != nil || cerr != nil || commit(salt, canon) != commitment {
+		return ledger.Redacted, true
+	}
+	return op.Value, true
+}
+
+// Shred destroys the key of subject. It returns the key ID and the number of
+// values sealed under it, which are now unrecoverable. The subject is
+// forgotten too, so a later Seal creates a new key.
+func (s *Store) Shred(subject string) (kid string, sealedValues int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kid, ok := s.subjects[subject]
+	if !ok {
+		return "", 0, fmt.Errorf("%w: %s", ErrUnknownSubject, subject)
+	}
+	key := s.keys[kid]
+	delete(s.subjects, subject)
+	delete(s.keys, kid)
+	if err := s.persistKeys(); err != nil {
+		s.subjects[subject] = kid
+		s.keys[kid] = key
+		return "", 0, err
+	}
+	for i := range key {
+		key[i] = 0
+	}
+	return kid, s.counts[kid], nil
+}
+
+// Size returns the number of subjects with a live key.
+func (s *Store) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.subjects)
+}
+
+// Close closes the sealed-value log of a file store. Subsequent seals fail.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.log == nil {
+		return nil
+	}
+	err := s.log.Close()
+	s.log = nil
+	return err
+}
+
+// IsCommitment reports whether v is a commitment object and returns its
+// digest.
+func IsCommitment(v interface{}) (string, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return "", false
+	}
+	c, ok := m[CommitmentField].(string)
+	return c, ok
+}
+
+// subjectKey returns the key of subject, creating and persisting it if
+// needed. Caller holds s.mu.
+func (s *Store) subjectKey(subject string) (string, []byte, error) {
+	if kid, ok := s.subjects[subject]; ok {
+		return kid, s.keys[kid], nil
+	}
+	key := make([]byte, 32)
+	id := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, fmt.Errorf("acp/shred: key: %w", err)
+	}
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, fmt.Errorf("acp/shred: key: %w", err)
+	}
+	kid := "sk-" + hex.EncodeToString(id)
+	s.subjects[subject] = kid
+	s.keys[kid] = key
+	if err := s.persistKeys(); err != nil {
+		delete(s.subjects, subject)
+		delete(s.keys, kid)
+		return "", nil, err
+	}
+	return kid, key, nil
+}
+
+// persistKeys atomically rewrites keys.json. Caller holds s.mu.
+func (s *Store) persistKeys() error {
+	if s.dir == "" {
+		return nil
+	}
+	kf := keyFile{Subjects: s.subjects, Keys: make(map[string]string, len(s.keys))}
+	for kid, key := range s.keys {
+		kf.Keys[kid] = base64.RawURLEncoding.EncodeToString(key)
+	}
+	raw, err := json.Marshal(kf)
+	if err != nil {
+		return fmt.Errorf("acp/shred: encode keys: %w", err)
+	}
+	path := filepath.Join(s.dir, "keys.json")
+	tmp, err := os.CreateTemp(s.dir, "keys.json.tmp-*")
+	if err != nil {
+		return fmt.Errorf("acp/shred: write keys: %w", err)
+	}
+	_, err = tmp.Write(raw)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("acp/shred: write keys: %w", err)
+	}
+	return nil
+}
+
+// canonical returns the JCS form of value.
+func canonical(value interface{}) ([]byte, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("acp/shred: encode: %w", err)
+	}
+	canon, err := jcs.Transform(raw)
+	if err != nil {
+		return nil, fmt.Errorf("acp/shred: canonicalize: %w", err)
+	}
+	return canon, nil
+}
+
+// commit returns base64url(SHA-256(salt || canon)).
+func commit(salt, canon []byte) string {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write(canon)
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("acp/shred: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
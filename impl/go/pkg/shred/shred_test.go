@@ -0,0 +1,107 @@
+package shred_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/ledger"
+	"github.com/chelof100/acp-framework/acp-go/pkg/shred"
+)
+
+func TestStore_SealOpenShred(t *testing.T) {
+	s := shred.NewStore()
+	value := map[string]interface{}{"path": "/patients/42", "n": float64(3)}
+
+	c1, err := s.Seal("agent-1", value)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	c2, _ := s.Seal("agent-1", value)
+	other, _ := s.Seal("agent-2", "keep me")
+
+	d1, ok := shred.IsCommitment(c1)
+	if !ok {
+		t.Fatalf("Seal returned %v, not a commitment", c1)
+	}
+	if d2, _ := shred.IsCommitment(c2); d1 == d2 {
+		t.Error("equal values produced equal commitments")
+	}
+	if got, ok := s.Open(c1); !ok || !reflect.DeepEqual(got, value) {
+		t.Errorf("Open = %v, %v; want %v", got, ok, value)
+	}
+	if _, ok := s.Open("plain"); ok {
+		t.Error("Open accepted a non-commitment")
+	}
+
+	kid, n, err := s.Shred("agent-1")
+	if err != nil || kid == "" || n != 2 {
+		t.Fatalf("Shred = %q, %d, %v; want 2 sealed values", kid, n, err)
+	}
+	for _, c := range []interface{}{c1, c2} {
+		if got, _ := s.Open(c); got != ledger.Redacted {
+			t.Errorf("Open after shred = %v, want %q", got, ledger.Redacted)
+		}
+	}
+	if got, _ := s.Open(other); got != "keep me" {
+		t.Errorf("other subject = %v", got)
+	}
+	if _, _, err := s.Shred("agent-1"); !errors.Is(err, shred.ErrUnknownSubject) {
+		t.Errorf("second Shred err = %v, want ErrUnknownSubject", err)
+	}
+	if s.Size() != 1 {
+		t.Errorf("Size = %d, want 1", s.Size())
+	}
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	s, err := shred.OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("OpenFileStore: %v", err)
+	}
+	kept, _ := s.Seal("agent-1", "kept")
+	gone, _ := s.Seal("agent-2", "gone")
+	if _, _, err := s.Shred("agent-2"); err != nil {
+		t.Fatalf("Shred: %v", err)
+	}
+	s.Close()
+
+	// A torn final line from a crash mid-append is tolerated.
+	f, _ := os.OpenFile(filepath.Join(dir, "sealed.jsonl"), os.O_WRONLY|os.O_APPEND, 0o600)
+	f.WriteString(`{"commitment":"tor`)
+	f.Close()
+
+	s, err = shred.OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	if got, _ := s.Open(kept); got != "kept" {
+		t.Errorf("kept = %v", got)
+	}
+	if got, _ := s.Open(gone); got != ledger.Redacted {
+		t.Errorf("shredded = %v, want %q", got, ledger.Redacted)
+	}
+	if s.Size() != 1 {
+		t.Errorf("Size = %d, want 1", s.Size())
+	}
+
+	// The torn line was dropped: values sealed after the reopen survive the
+	// next one.
+	later, err := s.Seal("agent-1", "later")
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	s.Close()
+	s, err = shred.OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("second reopen: %v", err)
+	}
+	defer s.Close()
+	if got, _ := s.Open(later); got != "later" {
+		t.Errorf("later = %v", got)
+	}
+}
@@ -8,9 +8,12 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/gowebpki/jcs"
+
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
 )
 
 // ─── Error codes per ACP-CT-1.0 §8 ─────────────────────────────────────────
@@ -83,9 +86,12 @@ type RevocationChecker interface {
 }
 
 // NonceStore interface for token replay prevention.
+//
+// Claim MUST be atomic: of any number of concurrent claims of the same nonce,
+// exactly one succeeds. exp is the token's expiration; the store keeps the
+// nonce claimed at least until then and MAY forget it afterwards.
 type NonceStore interface {
-	HasSeen(nonce string) bool
-	MarkSeen(nonce string)
+	Claim(nonce string, exp int64) error
 }
 
 // ─── Parsing and Verification ────────────────────────────────────────────────
@@ -199,16 +205,47 @@ func ParseAndVerify(rawJSON []byte, issuerPubKey ed25519.PublicKey, req Verifica
 
 	// ── Nonce replay prevention ─────────────────────────────────────────
 	// Checked after structural validation to avoid oracle attacks.
+	// Claim is a single check-and-mark so concurrent verifies cannot both pass.
 	if req.NonceStore != nil {
-		if req.NonceStore.HasSeen(token.Nonce) {
-			return nil, ErrCT011ConstraintViolated // Replay detected
+		if err := req.NonceStore.Claim(token.Nonce, token.Expiration); err != nil {
+			if errors.Is(err, ErrNonceReplay) {
+				return nil, ErrCT011ConstraintViolated // Replay detected
+			}
+			return nil, err // store failure: fail closed
 		}
-		req.NonceStore.MarkSeen(token.Nonce)
 	}
 
 	return &token, nil
 }
 
+// ParseAndVerifyDID is ParseAndVerify for a token whose iss is a DID: the
+// issuer is resolved through r with the keys valid at the token's iat, and
+// the token is accepted if any of them verifies the signature. An issuer that
+// cannot be resolved fails with CT-002.
+func ParseAndVerifyDID(rawJSON []byte, r did.Resolver, req VerificationRequest) (*CapabilityToken, error) {
+	var hdr struct {
+		ISS string `json:"iss"`
+		IAT int64  `json:"iat"`
+	}
+	if err := json.Unmarshal(rawJSON, &hdr); err != nil {
+		return nil, err
+	}
+	keys, err := did.ResolveKeysAt(r, hdr.ISS, hdr.IAT)
+	if err != nil {
+		return nil, fmt.Errorf("%w: issuer %q: %v", ErrCT002InvalidSignature, hdr.ISS, err)
+	}
+	var tok *CapabilityToken
+	for _, pk := range keys {
+		// A signature mismatch fails before the nonce is claimed, so the
+		// next key can still be tried.
+		tok, err = ParseAndVerify(rawJSON, pk, req)
+		if !errors.Is(err, ErrCT002InvalidSignature) {
+			break
+		}
+	}
+	return tok, err
+}
+
 // ─── Helpers ─────────────────────────────────────────────────────────────────
 
 // containsCapability returns true if cap is present in the token's cap array.
@@ -9,6 +9,7 @@ import (
 	"github.com/gowebpki/jcs"
 
 	acpcrypto "github.com/chelof100/acp-framework/acp-go/pkg/crypto"
+	"github.com/chelof100/acp-framework/acp-go/pkg/did"
 	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
 )
 
@@ -230,6 +231,28 @@ func TestComputeTokenHash_Deterministic(t *testing.T) {
 	}
 }
 
+func TestParseAndVerifyDID_DIDKeyIssuer(t *testing.T) {
+	issuer, _ := acpcrypto.GenerateIdentity()
+	tok := validTok(issuer, base64.RawURLEncoding.EncodeToString([]byte("didkeynonce12345")))
+	tok.Issuer = did.EncodeKey(issuer.PublicKey)
+	tokJSON := signToken(t, issuer, tok)
+
+	if _, err := tokens.ParseAndVerifyDID([]byte(tokJSON), did.KeyResolver{}, tokens.VerificationRequest{}); err != nil {
+		t.Fatalf("ParseAndVerifyDID() unexpected error: %v", err)
+	}
+
+	// A token whose iss names another key does not verify.
+	other, _ := acpcrypto.GenerateIdentity()
+	tok.Issuer = did.EncodeKey(other.PublicKey)
+	_, err := tokens.ParseAndVerifyDID([]byte(signToken(t, issuer, tok)), did.KeyResolver{}, tokens.VerificationRequest{})
+	assertCode(t, err, "CT-002")
+
+	// Unresolvable issuer → CT-002.
+	tok.Issuer = "did:web:example.invalid"
+	_, err = tokens.ParseAndVerifyDID([]byte(signToken(t, issuer, tok)), did.Multi{"key": did.KeyResolver{}}, tokens.VerificationRequest{})
+	assertCode(t, err, "CT-002")
+}
+
 // ─── Assertion helpers ────────────────────────────────────────────────────────
 
 // assertCode checks that err contains the given ACP error code (e.g., "CT-001").
@@ -1,103 +1,130 @@
-// Package tokens — InMemoryNonceStore implements the NonceStore interface
+// Package tokens — nonce stores implementing the NonceStore interface
 // defined in capability.go for replay prevention of capability tokens.
 package tokens
 
 import (
-	"fmt"
+	"errors"
+	"hash/fnv"
 	"sync"
 	"time"
 )
 
-// nonceEntry stores metadata about a seen nonce for TTL-based expiry.
-type nonceEntry struct {
-	seenAt    time.Time
-	expiresAt time.Time // mirrors the token's exp field
+// ErrNonceReplay is returned by NonceStore.Claim when the nonce has already
+// been claimed and its token has not yet expired.
+var ErrNonceReplay = errors.New("acp/nonce: nonce already used (replay attack)")
+
+// DefaultNonceShards is the shard count used by NewInMemoryNonceStore.
+const DefaultNonceShards = 32
+
+// nonceShard is one independently locked partition of an InMemoryNonceStore.
+type nonceShard struct {
+	mu      sync.Mutex
+	entries map[string]int64 // nonce → token exp (Unix seconds)
+}
+
+// claim records nonce until exp unless a live entry already exists.
+// An entry whose exp has passed no longer blocks the nonce: the token it
+// belonged to is rejected as expired (CT-003) before reaching the store.
+func (sh *nonceShard) claim(nonce string, exp int64, now int64) error {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if prev, exists := sh.entries[nonce]; exists && now <= prev {
+		return ErrNonceReplay
+	}
+	sh.entries[nonce] = exp
+	return nil
 }
 
-// InMemoryNonceStore is a thread-safe in-memory NonceStore.
-// It prevents replay attacks by rejecting tokens whose nonces have
-// already been consumed. Entries are pruned once the token's exp passes.
+// InMemoryNonceStore is a thread-safe, sharded in-memory NonceStore.
+//
+// Nonces are spread over independently locked shards by FNV-1a hash, so
+// concurrent claims of different nonces rarely contend while claims of the
+// same nonce always serialise on one lock. Entries are pruned once the
+// token's exp passes.
 //
 // For production deployments with multiple server instances, replace
-// this with a distributed store (Redis, Memcached, PostgreSQL).
+// this with a shared store (Redis SETNX, PostgreSQL unique index).
+// FileNonceStore persists claims across restarts of a single instance.
 type InMemoryNonceStore struct {
-	mu      sync.Mutex
-	entries map[string]nonceEntry
+	shards []nonceShard
 }
 
-// NewInMemoryNonceStore creates an empty nonce store.
+// NewInMemoryNonceStore creates an empty nonce store with DefaultNonceShards shards.
 func NewInMemoryNonceStore() *InMemoryNonceStore {
-	return &InMemoryNonceStore{
-		entries: make(map[string]nonceEntry),
-	}
+	return NewShardedNonceStore(DefaultNonceShards)
 }
 
-// MarkUsed records that a nonce has been used.
-// Returns an error if the nonce was already seen (replay detected).
-// tokenExp is the token's expiration Unix timestamp — used to determine
-// when the nonce entry can be safely pruned.
-func (s *InMemoryNonceStore) MarkUsed(nonce string, tokenExp int64) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if _, exists := s.entries[nonce]; exists {
-		return fmt.Errorf("acp/nonce: nonce %q already used (replay attack)", nonce)
+// NewShardedNonceStore creates an empty nonce store with n shards (minimum 1).
+func NewShardedNonceStore(n int) *InMemoryNonceStore {
+	if n < 1 {
+		n = 1
 	}
-
-	s.entries[nonce] = nonceEntry{
-		seenAt:    time.Now(),
-		expiresAt: time.Unix(tokenExp, 0),
+	s := &InMemoryNonceStore{shards: make([]nonceShard, n)}
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]int64)
 	}
-	return nil
-}
-
-// WasSeen returns true if the nonce has already been used.
-func (s *InMemoryNonceStore) WasSeen(nonce string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	_, exists := s.entries[nonce]
-	return exists
+	return s
 }
 
-// HasSeen implements the NonceStore interface (alias for WasSeen).
-func (s *InMemoryNonceStore) HasSeen(nonce string) bool {
-	return s.WasSeen(nonce)
+func (s *InMemoryNonceStore) shard(nonce string) *nonceShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(nonce))
+	return &s.shards[h.Sum32()%uint32(len(s.shards))]
 }
 
-// MarkSeen implements the NonceStore interface.
-// Records a nonce as used with a default 24-hour expiry.
-// Use MarkUsed when the token's actual exp timestamp is available.
-func (s *InMemoryNonceStore) MarkSeen(nonce string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if _, exists := s.entries[nonce]; !exists {
-		s.entries[nonce] = nonceEntry{
-			seenAt:    time.Now(),
-			expiresAt: time.Now().Add(24 * time.Hour),
-		}
-	}
+// Claim atomically checks and records a nonce (NonceStore).
+// tokenExp is the token's expiration Unix timestamp; the nonce stays claimed
+// until then. Returns ErrNonceReplay if the nonce is already claimed.
+func (s *InMemoryNonceStore) Claim(nonce string, tokenExp int64) error {
+	return s.shard(nonce).claim(nonce, tokenExp, time.Now().Unix())
 }
 
 // Prune removes entries for tokens that have already expired.
 // Call periodically (e.g., every 5 minutes) to prevent unbounded growth.
 func (s *InMemoryNonceStore) Prune() int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	return s.pruneAt(time.Now().Unix())
+}
 
-	now := time.Now()
+func (s *InMemoryNonceStore) pruneAt(now int64) int {
 	removed := 0
-	for nonce, entry := range s.entries {
-		if now.After(entry.expiresAt) {
-			delete(s.entries, nonce)
-			removed++
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		for nonce, exp := range sh.entries {
+			if now > exp {
+				delete(sh.entries, nonce)
+				removed++
+			}
 		}
+		sh.mu.Unlock()
 	}
 	return removed
 }
 
 // Size returns the number of stored nonce entries.
 func (s *InMemoryNonceStore) Size() int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return len(s.entries)
+	n := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		n += len(sh.entries)
+		sh.mu.Unlock()
+	}
+	return n
+}
+
+// snapshot returns a copy of all live entries at now.
+func (s *InMemoryNonceStore) snapshot(now int64) map[string]int64 {
+	out := make(map[string]int64)
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.Lock()
+		for nonce, exp := range sh.entries {
+			if now <= exp {
+				out[nonce] = exp
+			}
+		}
+		sh.mu.Unlock()
+	}
+	return out
 }
@@ -0,0 +1,167 @@
+package tokens
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileNonceStore is a NonceStore persisted to an append-only JSON-lines file,
+// so that claimed nonces survive a server restart.
+//
+// Each successful Claim appends {"nonce","exp"} and fsyncs before returning;
+// a write failure rejects the claim (fail closed). Open replays the file,
+// skipping expired entries; Prune compacts it by rewriting only live entries.
+// Claims are serialised on one lock, trading throughput for durability.
+type FileNonceStore struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	mem  *InMemoryNonceStore
+}
+
+type nonceRecord struct {
+	Nonce string `json:"nonce"`
+	Exp   int64  `json:"exp"`
+}
+
+// OpenFileNonceStore opens (or creates) the nonce log at path and loads the
+// nonces that have not yet expired.
+func OpenFileNonceStore(path string) (*FileNonceStore, error) {
+	mem := NewInMemoryNonceStore()
+	now := time.Now().Unix()
+
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		for line := 1; sc.Scan(); line++ {
+			var rec nonceRecord
+			if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+				// A torn final line from a crash mid-append is the only
+				// expected corruption; anything else is refused.
+				if !sc.Scan() {
+					break
+				}
+				f.Close()
+				return nil, fmt.Errorf("acp/nonce: %s line %d: %w", path, line, err)
+			}
+			if rec.Nonce != "" && now <= rec.Exp {
+				_ = mem.shard(rec.Nonce).claim(rec.Nonce, rec.Exp, now)
+			}
+		}
+		err := sc.Err()
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("acp/nonce: read %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("acp/nonce: open %s: %w", path, err)
+	}
+
+	s := &FileNonceStore{path: path, mem: mem}
+	if err := s.rewrite(now); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Claim atomically checks, records and persists a nonce (NonceStore).
+// Returns ErrNonceReplay if the nonce is already claimed.
+func (s *FileNonceStore) Claim(nonce string, tokenExp int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return fmt.Errorf("acp/nonce: store closed")
+	}
+	sh := s.mem.shard(nonce)
+	now := time.Now().Unix()
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if prev, exists := sh.entries[nonce]; exists && now <= prev {
+		return ErrNonceReplay
+	}
+	line, err := json.Marshal(nonceRecord{Nonce: nonce, Exp: tokenExp})
+	if err != nil {
+		return fmt.Errorf("acp/nonce: encode: %w", err)
+	}
+	if _, err := s.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("acp/nonce: append: %w", err)
+	}
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("acp/nonce: sync: %w", err)
+	}
+	sh.entries[nonce] = tokenExp
+	return nil
+}
+
+// Prune drops expired nonces from memory and compacts the log file.
+func (s *FileNonceStore) Prune() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().Unix()
+	removed := s.mem.pruneAt(now)
+	if removed > 0 && s.f != nil {
+		// On failure the old log is kept; it still holds every live nonce.
+		_ = s.rewrite(now)
+	}
+	return removed
+}
+
+// Size returns the number of stored nonce entries.
+func (s *FileNonceStore) Size() int {
+	return s.mem.Size()
+}
+
+// Close closes the underlying log file. Subsequent claims fail.
+func (s *FileNonceStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+// rewrite atomically replaces the log with the live entries at now and
+// reopens it for appending. Caller holds s.mu (or has exclusive access).
+func (s *FileNonceStore) rewrite(now int64) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("acp/nonce: compact: %w", err)
+	}
+	w := bufio.NewWriter(tmp)
+	for nonce, exp := range s.mem.snapshot(now) {
+		line, _ := json.Marshal(nonceRecord{Nonce: nonce, Exp: exp})
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("acp/nonce: compact: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("acp/nonce: reopen: %w", err)
+	}
+	if s.f != nil {
+		s.f.Close()
+	}
+	s.f = f
+	return nil
+}
@@ -0,0 +1,193 @@
+package tokens_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	acpcrypto "github.com/chelof100/acp-framework/acp-go/pkg/crypto"
+	"github.com/chelof100/acp-framework/acp-go/pkg/tokens"
+)
+
+// ─── Claim ────────────────────────────────────────────────────────────────────
+
+func TestInMemoryNonceStore_Claim(t *testing.T) {
+	store := tokens.NewInMemoryNonceStore()
+	exp := time.Now().Unix() + 60
+
+	if err := store.Claim("n1", exp); err != nil {
+		t.Fatalf("first claim: %v", err)
+	}
+	if err := store.Claim("n1", exp); !errors.Is(err, tokens.ErrNonceReplay) {
+		t.Errorf("second claim err = %v, want ErrNonceReplay", err)
+	}
+	if err := store.Claim("n2", exp); err != nil {
+		t.Errorf("distinct nonce: %v", err)
+	}
+	if store.Size() != 2 {
+		t.Errorf("Size = %d, want 2", store.Size())
+	}
+}
+
+func TestInMemoryNonceStore_PruneExpired(t *testing.T) {
+	store := tokens.NewShardedNonceStore(4)
+	now := time.Now().Unix()
+	_ = store.Claim("expired", now-10)
+	_ = store.Claim("live", now+60)
+
+	if n := store.Prune(); n != 1 {
+		t.Errorf("Prune removed %d, want 1", n)
+	}
+	if store.Size() != 1 {
+		t.Errorf("Size after prune = %d, want 1", store.Size())
+	}
+	if err := store.Claim("live", now+60); !errors.Is(err, tokens.ErrNonceReplay) {
+		t.Errorf("live nonce reclaimable after prune: %v", err)
+	}
+}
+
+// An entry whose token has expired no longer blocks the nonce, even before
+// pruning; the expired token itself is rejected earlier with CT-003.
+func TestInMemoryNonceStore_ExpiredEntryReclaimable(t *testing.T) {
+	store := tokens.NewInMemoryNonceStore()
+	now := time.Now().Unix()
+	_ = store.Claim("n", now-1)
+	if err := store.Claim("n", now+60); err != nil {
+		t.Errorf("claim over expired entry: %v", err)
+	}
+}
+
+// ─── Concurrency ──────────────────────────────────────────────────────────────
+
+// Thousands of concurrent verifies of one token: exactly one may pass.
+func TestParseAndVerify_ConcurrentReplay(t *testing.T) {
+	issuer, _ := acpcrypto.GenerateIdentity()
+	tok := validTok(issuer, base64.RawURLEncoding.EncodeToString([]byte("racenonce123456a")))
+	tokJSON := signToken(t, issuer, tok)
+
+	for _, tc := range []struct {
+		name  string
+		store tokens.NonceStore
+	}{
+		{"sharded", tokens.NewInMemoryNonceStore()},
+		{"single-shard", tokens.NewShardedNonceStore(1)},
+		{"file", openFileStore(t, filepath.Join(t.TempDir(), "nonces.jsonl"))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			const workers = 2000
+			var ok, replay int64
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					_, err := verify(t, tokJSON, issuer.PublicKey, tc.store)
+					switch {
+					case err == nil:
+						atomic.AddInt64(&ok, 1)
+					case errors.Is(err, tokens.ErrCT011ConstraintViolated):
+						atomic.AddInt64(&replay, 1)
+					default:
+						t.Errorf("unexpected error: %v", err)
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+			if ok != 1 || replay != workers-1 {
+				t.Errorf("ok=%d replay=%d, want 1 and %d", ok, replay, workers-1)
+			}
+		})
+	}
+}
+
+// ─── FileNonceStore ───────────────────────────────────────────────────────────
+
+func openFileStore(t *testing.T, path string) *tokens.FileNonceStore {
+	t.Helper()
+	s, err := tokens.OpenFileNonceStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileNonceStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestFileNonceStore_SurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.jsonl")
+	now := time.Now().Unix()
+
+	s := openFileStore(t, path)
+	if err := s.Claim("persisted", now+60); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	_ = s.Claim("expired", now-10)
+	s.Close()
+
+	s2 := openFileStore(t, path)
+	if err := s2.Claim("persisted", now+60); !errors.Is(err, tokens.ErrNonceReplay) {
+		t.Errorf("claim after reopen err = %v, want ErrNonceReplay", err)
+	}
+	if s2.Size() != 1 {
+		t.Errorf("Size after reopen = %d, want 1 (expired entry dropped)", s2.Size())
+	}
+}
+
+func TestFileNonceStore_PruneCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.jsonl")
+	now := time.Now().Unix()
+	s := openFileStore(t, path)
+	for i := 0; i < 10; i++ {
+		_ = s.Claim(fmt.Sprintf("old-%d", i), now-10)
+	}
+	_ = s.Claim("live", now+60)
+
+	if n := s.Prune(); n != 10 {
+		t.Errorf("Prune removed %d, want 10", n)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if want := `{"nonce":"live","exp":` + fmt.Sprint(now+60) + "}\n"; string(raw) != want {
+		t.Errorf("compacted log = %q, want %q", raw, want)
+	}
+	// The compacted file is still appended to.
+	if err := s.Claim("after", now+60); err != nil {
+		t.Fatalf("Claim after compaction: %v", err)
+	}
+	s.Close()
+	if s2 := openFileStore(t, path); s2.Size() != 2 {
+		t.Errorf("Size after reopen = %d, want 2", s2.Size())
+	}
+}
+
+func TestFileNonceStore_TornTailIgnored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonces.jsonl")
+	exp := time.Now().Unix() + 60
+	content := fmt.Sprintf("{\"nonce\":\"a\",\"exp\":%d}\n{\"nonce\":\"b\",\"ex", exp)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := openFileStore(t, path)
+	if s.Size() != 1 {
+		t.Errorf("Size = %d, want 1", s.Size())
+	}
+}
+
+func TestFileNonceStore_ClosedFailsClosed(t *testing.T) {
+	s := openFileStore(t, filepath.Join(t.TempDir(), "nonces.jsonl"))
+	s.Close()
+	err := s.Claim("n", time.Now().Unix()+60)
+	if err == nil || errors.Is(err, tokens.ErrNonceReplay) {
+		t.Errorf("claim on closed store err = %v, want store error", err)
+	}
+}
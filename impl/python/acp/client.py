@@ -442,6 +442,9 @@ class ACPClient:
         et_id: str,
         consumed_at: int,
         execution_result: str,
+        system_id: str,
+        system_signer: Optional[ACPSigner] = None,
+        action_parameters: Optional[Dict[str, Any]] = None,
         sig: str = "",
     ) -> Dict[str, Any]:
         """
@@ -449,26 +452,40 @@ class ACPClient:
 
         POST /acp/v1/exec-tokens/{et_id}/consume
 
-        Los ET son de un solo uso. El sistema destino confirma la ejecución
-        llamando a este endpoint.
+        Los ET son de un solo uso. Solo el sistema destino registrado al que
+        está ligado el ET puede consumirlo; el reporte va firmado con su clave:
+        sig = Ed25519(SHA-256(JCS(body con "sig": ""))).
 
         Args:
-            et_id:            ID del Execution Token.
-            consumed_at:      Timestamp Unix de consumo.
-            execution_result: "success" | "failure" | "unknown".
-            sig:              Firma Ed25519 del sistema destino (opcional en dev).
+            et_id:             ID del Execution Token.
+            consumed_at:       Timestamp Unix de consumo.
+            execution_result:  "success" | "failure" | "unknown".
+            system_id:         ID del sistema destino registrado.
+            system_signer:     ACPSigner con la clave del sistema destino; si se
+                               indica, calcula sig.
+            action_parameters: Parámetros realmente ejecutados (deben coincidir
+                               con action_parameters_hash del ET).
+            sig:               Firma ya calculada (si no se indica system_signer).
 
         Returns:
             {"et_id": "...", "state": "consumed", "consumed_at": <int>, ...}
         """
+        body: Dict[str, Any] = {
+            "et_id":            et_id,
+            "system_id":        system_id,
+            "consumed_at":      consumed_at,
+            "execution_result": execution_result,
+            "sig":              "",
+        }
+        if action_parameters:
+            body["action_parameters"] = action_parameters
+        if system_signer is not None:
+            digest = hashlib.sha256(ACPSigner.canonicalize(body)).digest()
+            sig = base64.urlsafe_b64encode(system_signer.sign_bytes(digest)).rstrip(b"=").decode()
+        body["sig"] = sig
         return _post_json(
             f"{self._server}/acp/v1/exec-tokens/{et_id}/consume",
-            {
-                "et_id":            et_id,
-                "consumed_at":      consumed_at,
-                "execution_result": execution_result,
-                "sig":              sig,
-            },
+            body,
             timeout=self._timeout,
         )
 
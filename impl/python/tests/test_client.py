@@ -13,6 +13,7 @@ Cobertura:
 """
 import json
 import base64
+import hashlib
 import pytest
 from unittest.mock import patch, MagicMock
 from urllib.error import HTTPError, URLError
@@ -366,18 +367,35 @@ class TestExecTokenConsume:
     def test_exec_token_consume_url(self, client):
         with patch("acp.client._post_json") as mock_post:
             mock_post.return_value = {"state": "consumed"}
-            client.exec_token_consume("et-1", 1700000000, "success")
+            client.exec_token_consume("et-1", 1700000000, "success", "sys-1")
         assert mock_post.call_args[0][0] == "http://localhost:8080/acp/v1/exec-tokens/et-1/consume"
 
     def test_exec_token_consume_body(self, client):
         with patch("acp.client._post_json") as mock_post:
             mock_post.return_value = {"state": "consumed"}
-            client.exec_token_consume("et-1", 1700000000, "success", sig="sig123")
+            client.exec_token_consume("et-1", 1700000000, "success", "sys-1", sig="sig123")
         body = mock_post.call_args[0][1]
         assert body["et_id"] == "et-1"
+        assert body["system_id"] == "sys-1"
         assert body["consumed_at"] == 1700000000
         assert body["execution_result"] == "success"
         assert body["sig"] == "sig123"
+        assert "action_parameters" not in body
+
+    def test_exec_token_consume_signed(self, client, identity, signer):
+        with patch("acp.client._post_json") as mock_post:
+            mock_post.return_value = {"state": "consumed"}
+            client.exec_token_consume(
+                "et-1", 1700000000, "success", "sys-1",
+                system_signer=signer, action_parameters={"amount": 100},
+            )
+        body = mock_post.call_args[0][1]
+        assert body["action_parameters"] == {"amount": 100}
+        # Firmado como execution.SignConsumeRequest: body con "sig" vacío.
+        unsigned = dict(body, sig="")
+        digest = hashlib.sha256(ACPSigner.canonicalize(unsigned)).digest()
+        sig = base64.urlsafe_b64decode(body["sig"] + "=" * (-len(body["sig"]) % 4))
+        assert identity.verify(sig, digest)
 
 
 class TestExecTokenStatus: